@@ -8,12 +8,20 @@ import (
 
 	"scholarship-system/internal/config"
 	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
 )
 
 func main() {
 	var (
-		action        = flag.String("action", "migrate", "Action to perform: migrate, status, rollback")
+		action        = flag.String("action", "migrate", "Action to perform: migrate, status, init, storage, encrypt, reconcile")
 		migrationsDir = flag.String("migrations", "./migrations", "Directory containing migration files")
+		storageFrom   = flag.String("from", "local", "Storage backend to move files from (action storage)")
+		storageTo     = flag.String("to", "s3", "Storage backend to move files to (action storage)")
+		deleteSource  = flag.Bool("delete-source", false, "Delete each file from the old backend once moved (action storage)")
+		dryRun        = flag.Bool("dry-run", false, "List the files that would be moved or encrypted (actions storage and encrypt)")
+		skipHashes    = flag.Bool("skip-hashes", false, "Do not read files to compare them with their recorded SHA-256 (action reconcile)")
+		quarantine    = flag.Bool("quarantine", false, "Move files no record points at under orphaned/ (action reconcile)")
+		repair        = flag.Bool("repair", false, "Mark documents whose file is missing or damaged for upload again (action reconcile)")
 	)
 	flag.Parse()
 
@@ -26,6 +34,30 @@ func main() {
 	}
 	defer database.Close()
 
+	// Moving files between storage backends does not touch the schema
+	if *action == "storage" {
+		if err := migrateStorage(cfg, *storageFrom, *storageTo, *deleteSource, *dryRun); err != nil {
+			log.Fatal("Storage migration failed:", err)
+		}
+		return
+	}
+
+	if *action == "encrypt" {
+		if err := encryptStorage(cfg, *dryRun); err != nil {
+			log.Fatal("Encryption failed:", err)
+		}
+		return
+	}
+
+	if *action == "reconcile" {
+		verifyHashes := !*skipHashes
+		req := models.StorageReconcileRequest{VerifyHashes: &verifyHashes, Quarantine: *quarantine, Repair: *repair}
+		if err := reconcileStorage(cfg, req); err != nil {
+			log.Fatal("Reconciliation failed:", err)
+		}
+		return
+	}
+
 	// Ensure migrations directory exists and is absolute
 	absDir, err := filepath.Abs(*migrationsDir)
 	if err != nil {
@@ -61,6 +93,6 @@ func main() {
 		log.Println("Migration table initialized successfully")
 
 	default:
-		log.Fatalf("Unknown action: %s. Available actions: migrate, status, init", *action)
+		log.Fatalf("Unknown action: %s. Available actions: migrate, status, init, storage, encrypt, reconcile", *action)
 	}
 }
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/jobs"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/storage"
+)
+
+// migrateStorage moves every stored file from one backend to another and
+// updates the records that point at them, e.g.
+//
+//	go run ./cmd/migrate -action storage -from local -to s3 -delete-source
+func migrateStorage(cfg *config.Config, fromName, toName string, deleteSource, dryRun bool) error {
+	if fromName == toName {
+		return fmt.Errorf("source and target backend are both %q", fromName)
+	}
+	from, err := storage.New(cfg, fromName)
+	if err != nil {
+		return err
+	}
+	to, err := storage.New(cfg, toName)
+	if err != nil {
+		return err
+	}
+
+	storageRepo := repository.NewStorageRepository()
+	objects, err := storageRepo.ListObjects(fromName)
+	if err != nil {
+		return err
+	}
+	log.Printf("%d files stored in %s", len(objects), fromName)
+
+	if dryRun {
+		for _, obj := range objects {
+			log.Printf("%s %s: %s", obj.Table, obj.ID, obj.Key)
+		}
+		return nil
+	}
+
+	result := storage.Migrate(context.Background(), from, to, objects, storageRepo.MoveObject, deleteSource)
+	for _, message := range result.Errors {
+		log.Printf("Warning: %s", message)
+	}
+	log.Printf("Moved %d of %d files from %s to %s (%d missing, %d failed)",
+		result.Moved, result.Total, result.From, result.To, result.Missing, result.Failed)
+	if result.Failed > 0 {
+		return fmt.Errorf("%d files could not be moved; run again to retry", result.Failed)
+	}
+	return nil
+}
+
+// encryptStorage puts every stored file under the active master key of
+// FILE_ENCRYPTION_KEYS: plain files are encrypted and files under an older
+// key get their data key wrapped again. The server keeps running meanwhile,
+// e.g. to rotate keys
+//
+//	FILE_ENCRYPTION_KEYS=k2:<new>,k1:<old>   (restart the server with both)
+//	go run ./cmd/migrate -action encrypt
+//	FILE_ENCRYPTION_KEYS=k2:<new>            (once no file uses k1)
+func encryptStorage(cfg *config.Config, dryRun bool) error {
+	if cfg.FileEncryptionKeys == "" {
+		return fmt.Errorf("FILE_ENCRYPTION_KEYS is not set")
+	}
+	backends := []string{storage.BackendLocal}
+	if cfg.S3Bucket != "" {
+		backends = append(backends, storage.BackendS3)
+	}
+
+	storageRepo := repository.NewStorageRepository()
+	failed := 0
+	for _, name := range backends {
+		store, err := storage.New(cfg, name)
+		if err != nil {
+			return err
+		}
+		encrypted, ok := store.(*storage.Encrypted)
+		if !ok {
+			return fmt.Errorf("storage backend %q is not encrypted", name)
+		}
+		objects, err := storageRepo.ListObjects(name)
+		if err != nil {
+			return err
+		}
+		log.Printf("%d files stored in %s", len(objects), name)
+		if dryRun {
+			continue
+		}
+
+		result := storage.Reencrypt(context.Background(), encrypted, objects)
+		for _, message := range result.Errors {
+			log.Printf("Warning: %s", message)
+		}
+		log.Printf("%s: %d files under key %s (%d encrypted, %d rewrapped, %d already current, %d missing, %d failed)",
+			result.Backend, result.Total, result.KeyID, result.Encrypted, result.Rewrapped, result.Current, result.Missing, result.Failed)
+		failed += result.Failed
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d files could not be encrypted; run again to retry", failed)
+	}
+	return nil
+}
+
+// reconcileStorage checks the files of every configured backend against
+// the records pointing at them and stores the result where the admin
+// dashboard shows it, e.g.
+//
+//	go run ./cmd/migrate -action reconcile                     (report only)
+//	go run ./cmd/migrate -action reconcile -quarantine -repair
+func reconcileStorage(cfg *config.Config, req models.StorageReconcileRequest) error {
+	backends := []string{storage.BackendLocal}
+	if cfg.S3Bucket != "" {
+		backends = append(backends, storage.BackendS3)
+	}
+	stores := make([]storage.Storage, 0, len(backends))
+	for _, name := range backends {
+		store, err := storage.New(cfg, name)
+		if err != nil {
+			return err
+		}
+		stores = append(stores, store)
+	}
+
+	result, err := jobs.ReconcileStorage(context.Background(), stores, req, models.ReconcileSourceCLI, nil)
+	if err != nil {
+		return err
+	}
+	for _, issue := range result.Issues {
+		line := fmt.Sprintf("%s %s:%s", issue.Kind, issue.Backend, issue.Key)
+		if issue.Table != "" {
+			line += fmt.Sprintf(" (%s %s)", issue.Table, issue.ID)
+		}
+		if issue.Action != "" {
+			line += " " + issue.Action
+		}
+		log.Print(line)
+	}
+	for _, message := range result.Errors {
+		log.Printf("Warning: %s", message)
+	}
+	log.Printf("Reconciliation %d: %d files, %d records (%d orphaned files, %d missing files, %d hash mismatches; %d quarantined, %d repaired, %d failed)",
+		result.ReconciliationID, result.FilesScanned, result.RecordsChecked, result.OrphanFiles, result.MissingFiles,
+		result.HashMismatches, result.Quarantined, result.Repaired, result.Failed)
+	if result.Failed > 0 {
+		return fmt.Errorf("%d files or records could not be fixed; run again to retry", result.Failed)
+	}
+	return nil
+}
@@ -24,6 +24,63 @@ type Config struct {
 	EmailUsername    string
 	EmailPassword    string
 	EmailFrom        string
+	AwardDeclineDays int64
+
+	// Background jobs
+	JobPollSeconds         int64
+	FraudScanIntervalHours int64 // 0 disables the scheduled scan
+	FraudAssetIncomeYears  int64
+	FraudMinAssetValue     int64
+
+	// Reapplication prefill
+	PrefillStaleMonths int64
+
+	// Application search
+	SearchIndexIntervalMinutes int64 // 0 disables the scheduled refresh
+
+	// File storage
+	StorageBackend string // "local" or "s3"
+	S3Endpoint     string
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3UsePathStyle bool
+
+	// Comma-separated id:base64key master keys for encrypting stored files;
+	// the first encrypts new files, the rest read files from before a
+	// rotation. Empty stores files unencrypted.
+	FileEncryptionKeys string
+
+	// Upload scanning
+	ClamdAddress                string // required unless DocumentScanRequired is false
+	ClamdTimeoutSeconds         int64
+	DocumentScanRequired        bool  // false releases uploads unscanned when there is no clamd
+	DocumentScanIntervalMinutes int64 // 0 disables the scheduled scan
+
+	// Previews and page counts of uploaded documents
+	DocumentPreviewIntervalMinutes int64 // 0 disables preview generation
+
+	// Signed document download links
+	DownloadURLSecret     string
+	DownloadURLTTLSeconds int64
+
+	// Resumable uploads
+	UploadSessionTTLHours        int64 // idle time before an unfinished upload is discarded
+	UploadCleanupIntervalMinutes int64 // 0 disables the scheduled cleanup
+
+	// Largest request body, e.g. a bulk upload or a ZIP of documents
+	MaxRequestBodySize int64
+
+	// TrueType font for generated PDFs such as document bundles; without it
+	// text is set in Courier, which has no Thai
+	PDFFontPath string
+
+	// Document retention
+	RetentionPurgeIntervalHours int64 // 0 disables the scheduled purge
+
+	// Checks of stored files against their records
+	StorageReconcileIntervalHours int64 // 0 disables the scheduled check
 }
 
 func Load() *Config {
@@ -48,6 +105,47 @@ func Load() *Config {
 		EmailUsername:    getEnv("EMAIL_USERNAME", ""),
 		EmailPassword:    getEnv("EMAIL_PASSWORD", ""),
 		EmailFrom:        getEnv("EMAIL_FROM", "noreply@university.ac.th"),
+		AwardDeclineDays: getEnvInt64("AWARD_DECLINE_DAYS", 14),
+
+		JobPollSeconds:         getEnvInt64("JOB_POLL_SECONDS", 5),
+		FraudScanIntervalHours: getEnvInt64("FRAUD_SCAN_INTERVAL_HOURS", 24),
+		FraudAssetIncomeYears:  getEnvInt64("FRAUD_ASSET_INCOME_YEARS", 10),
+		FraudMinAssetValue:     getEnvInt64("FRAUD_MIN_ASSET_VALUE", 1000000),
+
+		PrefillStaleMonths: getEnvInt64("PREFILL_STALE_MONTHS", 12),
+
+		SearchIndexIntervalMinutes: getEnvInt64("SEARCH_INDEX_INTERVAL_MINUTES", 5),
+
+		StorageBackend: getEnv("STORAGE_BACKEND", "local"),
+		S3Endpoint:     getEnv("S3_ENDPOINT", ""),
+		S3Region:       getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:       getEnv("S3_BUCKET", ""),
+		S3AccessKey:    getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:    getEnv("S3_SECRET_KEY", ""),
+		S3UsePathStyle: getEnv("S3_USE_PATH_STYLE", "true") == "true",
+
+		FileEncryptionKeys: getEnv("FILE_ENCRYPTION_KEYS", ""),
+
+		ClamdAddress:                getEnv("CLAMD_ADDRESS", ""),
+		ClamdTimeoutSeconds:         getEnvInt64("CLAMD_TIMEOUT_SECONDS", 60),
+		DocumentScanRequired:        getEnv("DOCUMENT_SCAN_REQUIRED", "true") == "true",
+		DocumentScanIntervalMinutes: getEnvInt64("DOCUMENT_SCAN_INTERVAL_MINUTES", 1),
+
+		DocumentPreviewIntervalMinutes: getEnvInt64("DOCUMENT_PREVIEW_INTERVAL_MINUTES", 1),
+
+		DownloadURLSecret:     getEnv("DOWNLOAD_URL_SECRET", ""),
+		DownloadURLTTLSeconds: getEnvInt64("DOWNLOAD_URL_TTL_SECONDS", 300),
+
+		UploadSessionTTLHours:        getEnvInt64("UPLOAD_SESSION_TTL_HOURS", 24),
+		UploadCleanupIntervalMinutes: getEnvInt64("UPLOAD_CLEANUP_INTERVAL_MINUTES", 60),
+
+		MaxRequestBodySize: getEnvInt64("MAX_REQUEST_BODY_SIZE", 104857600), // 100MB
+
+		PDFFontPath: getEnv("PDF_FONT_PATH", ""),
+
+		RetentionPurgeIntervalHours: getEnvInt64("RETENTION_PURGE_INTERVAL_HOURS", 24),
+
+		StorageReconcileIntervalHours: getEnvInt64("STORAGE_RECONCILE_INTERVAL_HOURS", 24),
 	}
 }
 
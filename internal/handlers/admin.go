@@ -9,6 +9,7 @@ import (
 	"golang.org/x/crypto/bcrypt"
 	"scholarship-system/internal/config"
 	"scholarship-system/internal/database"
+	"scholarship-system/internal/repository"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -556,7 +557,7 @@ func (h *AdminHandler) GetDashboardActivities(c *fiber.Ctx) error {
 
 // GetDashboardResources retrieves system resource usage
 // @Summary Get dashboard resources
-// @Description Get system resource usage information
+// @Description Get system resource usage information, including the files found out of step with their records by the latest storage reconciliation
 // @Tags Admin Dashboard
 // @Accept json
 // @Produce json
@@ -599,6 +600,34 @@ func (h *AdminHandler) GetDashboardResources(c *fiber.Ctx) error {
 		},
 	}
 
+	// Stored files against their records, as of the last reconciliation
+	files := map[string]interface{}{
+		"name":   "Document Files",
+		"usage":  0,
+		"total":  0,
+		"unit":   "files",
+		"status": "unknown",
+	}
+	latest, err := repository.NewStorageRepository().LatestReconciliation()
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	} else if latest != nil {
+		problems := latest.OrphanFiles + latest.MissingFiles + latest.HashMismatches
+		files["usage"] = problems
+		files["total"] = latest.RecordsChecked
+		files["status"] = "healthy"
+		if problems > 0 {
+			files["status"] = "warning"
+		}
+		files["orphan_files"] = latest.OrphanFiles
+		files["missing_files"] = latest.MissingFiles
+		files["hash_mismatches"] = latest.HashMismatches
+		files["files_scanned"] = latest.FilesScanned
+		files["reconciliation_id"] = latest.ReconciliationID
+		files["checked_at"] = latest.FinishedAt
+	}
+	resources = append(resources, files)
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data":    resources,
@@ -13,6 +13,7 @@ import (
 	"scholarship-system/internal/database"
 	"scholarship-system/internal/models"
 	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
 )
 
 type ApplicationHandler struct {
@@ -20,6 +21,9 @@ type ApplicationHandler struct {
 	applicationRepo *repository.ApplicationRepository
 	scholarshipRepo *repository.ScholarshipRepository
 	userRepo        *repository.UserRepository
+	eligibility     *services.EligibilityService
+	detailsRepo     *repository.ApplicationDetailsRepository
+	requirementRepo *repository.DocumentRequirementRepository
 }
 
 func NewApplicationHandler(cfg *config.Config) *ApplicationHandler {
@@ -28,6 +32,9 @@ func NewApplicationHandler(cfg *config.Config) *ApplicationHandler {
 		applicationRepo: repository.NewApplicationRepository(),
 		scholarshipRepo: repository.NewScholarshipRepository(),
 		userRepo:        repository.NewUserRepository(),
+		eligibility:     services.NewEligibilityService(),
+		detailsRepo:     repository.NewApplicationDetailsRepository(),
+		requirementRepo: repository.NewDocumentRequirementRepository(),
 	}
 }
 
@@ -71,9 +78,23 @@ func (h *ApplicationHandler) CreateApplication(c *fiber.Ctx) error {
 		})
 	}
 
-	// For now, use email as student_id if no student record exists
-	// TODO: Implement proper student repository and validation
-	studentID := user.Email
+	// Get student_id from students table
+	var studentID string
+	err = database.DB.QueryRow(
+		"SELECT student_id FROM students WHERE user_id = $1",
+		userID,
+	).Scan(&studentID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// Fallback to email if no student record
+			studentID = user.Email
+		} else {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get student information",
+			})
+		}
+	}
 
 	// Check if scholarship exists and is available
 	scholarship, err := h.scholarshipRepo.GetByID(req.ScholarshipID)
@@ -115,6 +136,20 @@ func (h *ApplicationHandler) CreateApplication(c *fiber.Ctx) error {
 		})
 	}
 
+	// Check eligibility rules
+	eligibility, err := h.eligibility.Check(scholarship, studentID, req.FamilyIncome)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check eligibility",
+		})
+	}
+	if !eligibility.IsEligible {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":       "You do not meet the eligibility criteria for this scholarship",
+			"eligibility": eligibility,
+		})
+	}
+
 	application := &models.ScholarshipApplication{
 		StudentID:               studentID,
 		ScholarshipID:           req.ScholarshipID,
@@ -302,12 +337,16 @@ func calculateProgress(status string) int {
 		return 60
 	case "document_pending":
 		return 50
+	case "needs_revision":
+		return 50
 	case "interview_scheduled":
 		return 80
 	case "approved":
 		return 100
 	case "rejected":
 		return 100
+	case "withdrawn", "declined":
+		return 100
 	default:
 		return 0
 	}
@@ -324,12 +363,18 @@ func getApplicationNotes(status string) string {
 		return "อยู่ระหว่างการพิจารณา"
 	case "document_pending":
 		return "รอเอกสารเพิ่มเติม"
+	case "needs_revision":
+		return "ถูกส่งกลับให้แก้ไข กรุณาแก้ไขตามความเห็นของเจ้าหน้าที่และส่งกลับภายในกำหนด"
 	case "interview_scheduled":
 		return "ผ่านการตรวจสอบเอกสารแล้ว มีนัดสัมภาษณ์"
 	case "approved":
 		return "ได้รับการอนุมัติแล้ว จะได้รับเงินทุนภายใน 30 วัน"
 	case "rejected":
 		return "ไม่ได้รับการอนุมัติ"
+	case "withdrawn":
+		return "ถอนใบสมัครแล้ว"
+	case "declined":
+		return "สละสิทธิ์ทุนแล้ว"
 	default:
 		return ""
 	}
@@ -536,6 +581,33 @@ func (h *ApplicationHandler) SubmitApplication(c *fiber.Ctx) error {
 		})
 	}
 
+	// Check the documents against the scholarship's requirements
+	form, err := h.detailsRepo.GetCompleteForm(uint(applicationID))
+	if err != nil && err != sql.ErrNoRows {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to validate application",
+		})
+	}
+	documents, err := h.applicationRepo.GetDocuments(uint(applicationID))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to validate documents",
+		})
+	}
+	checklist, err := documentChecklist(h.requirementRepo, uint(applicationID), form, documents)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to validate application documents",
+		})
+	}
+	if !checklist.Complete {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":     "Please upload all required documents before submitting",
+			"errors":    checklist.Problems,
+			"checklist": checklist,
+		})
+	}
+
 	// Submit application
 	if err := h.applicationRepo.Submit(uint(applicationID)); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -543,6 +615,11 @@ func (h *ApplicationHandler) SubmitApplication(c *fiber.Ctx) error {
 		})
 	}
 
+	// Assess financial need and compute the priority score for reviewers
+	if _, _, err := refreshApplicationScores(uint(applicationID)); err != nil {
+		fmt.Printf("Warning: Failed to score application %d: %v\n", applicationID, err)
+	}
+
 	return c.JSON(fiber.Map{
 		"message": "Application submitted successfully",
 	})
@@ -643,10 +720,11 @@ func (h *ApplicationHandler) DeleteApplication(c *fiber.Ctx) error {
 		})
 	}
 
-	// Check if application can be deleted
-	if application.ApplicationStatus == "submitted" && isStudent {
+	// Students can only delete drafts; submitted applications are withdrawn
+	// so the record is kept for statistics
+	if application.ApplicationStatus != "draft" && isStudent {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Cannot delete submitted application",
+			"error": "Cannot delete submitted application, withdraw it instead",
 		})
 	}
 
@@ -667,7 +745,7 @@ func (h *ApplicationHandler) DeleteApplication(c *fiber.Ctx) error {
 // @Tags Applications
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} object{total=int,pending=int,approved=int,rejected=int,interview=int,overdue=int}
+// @Success 200 {object} object{total=int,pending=int,approved=int,rejected=int,interview=int,overdue=int,withdrawn=int,declined=int}
 // @Failure 401 {object} object{error=string}
 // @Failure 403 {object} object{error=string}
 // @Router /admin/applications/stats [get]
@@ -679,6 +757,8 @@ func (h *ApplicationHandler) GetApplicationStats(c *fiber.Ctx) error {
 		Rejected  int `json:"rejected"`
 		Interview int `json:"interview"`
 		Overdue   int `json:"overdue"`
+		Withdrawn int `json:"withdrawn"`
+		Declined  int `json:"declined"`
 	}
 
 	// Get total applications
@@ -727,6 +807,20 @@ func (h *ApplicationHandler) GetApplicationStats(c *fiber.Ctx) error {
 		})
 	}
 
+	// Get withdrawn applications
+	if err := database.DB.QueryRow("SELECT COUNT(*) FROM scholarship_applications WHERE application_status = 'withdrawn'").Scan(&stats.Withdrawn); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch withdrawn applications",
+		})
+	}
+
+	// Get declined awards
+	if err := database.DB.QueryRow("SELECT COUNT(*) FROM scholarship_applications WHERE application_status = 'declined'").Scan(&stats.Declined); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch declined applications",
+		})
+	}
+
 	return c.JSON(stats)
 }
 
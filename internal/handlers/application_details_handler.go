@@ -18,6 +18,9 @@ type ApplicationDetailsHandler struct {
 	cfg                     *config.Config
 	applicationDetailsRepo  *repository.ApplicationDetailsRepository
 	applicationRepo         *repository.ApplicationRepository
+	revisionRepo            *repository.RevisionRepository
+	snapshotRepo            *repository.SnapshotRepository
+	requirementRepo         *repository.DocumentRequirementRepository
 }
 
 func NewApplicationDetailsHandler(cfg *config.Config) *ApplicationDetailsHandler {
@@ -25,6 +28,9 @@ func NewApplicationDetailsHandler(cfg *config.Config) *ApplicationDetailsHandler
 		cfg:                    cfg,
 		applicationDetailsRepo: repository.NewApplicationDetailsRepository(),
 		applicationRepo:        repository.NewApplicationRepository(),
+		revisionRepo:           repository.NewRevisionRepository(),
+		snapshotRepo:           repository.NewSnapshotRepository(),
+		requirementRepo:        repository.NewDocumentRequirementRepository(),
 	}
 }
 
@@ -37,10 +43,12 @@ func NewApplicationDetailsHandler(cfg *config.Config) *ApplicationDetailsHandler
 // @Security BearerAuth
 // @Param id path int true "Application ID"
 // @Param info body models.ApplicationPersonalInfo true "Personal information"
+// @Param If-Match header string false "Version the edit is based on"
 // @Success 200 {object} object{success=bool,message=string,data=models.ApplicationPersonalInfo}
 // @Failure 400 {object} object{error=string}
 // @Failure 403 {object} object{error=string}
 // @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string,section=string,current_version=int,current=object}
 // @Router /api/v1/applications/{id}/personal-info [post]
 func (h *ApplicationDetailsHandler) SavePersonalInfo(c *fiber.Ctx) error {
 	// Get user_id from context - handle both UUID and string formats
@@ -83,6 +91,17 @@ func (h *ApplicationDetailsHandler) SavePersonalInfo(c *fiber.Ctx) error {
 		})
 	}
 
+	// Check the section can still be edited
+	if reason, err := editLockReason(h.revisionRepo, uint(applicationID), "section", models.SectionPersonalInfo); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify application status",
+		})
+	} else if reason != "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": reason,
+		})
+	}
+
 	var info models.ApplicationPersonalInfo
 	if err := c.BodyParser(&info); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -93,17 +112,25 @@ func (h *ApplicationDetailsHandler) SavePersonalInfo(c *fiber.Ctx) error {
 	info.ApplicationID = applicationID
 
 	// Save or update personal info
-	savedInfo, err := h.applicationDetailsRepo.SavePersonalInfo(&info)
+	// Reject saves based on an outdated version of the section
+	expectedVersion, err := ifMatchVersion(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save personal information",
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
 
+	savedInfo, version, err := h.applicationDetailsRepo.SavePersonalInfo(&info, expectedVersion)
+	if err != nil {
+		return formSaveError(c, h.applicationDetailsRepo, uint(applicationID), err, "Failed to save personal information")
+	}
+	setVersionETag(c, version)
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "บันทึกข้อมูลส่วนตัวเรียบร้อยแล้ว",
 		"data":    savedInfo,
+		"version": version,
 	})
 }
 
@@ -116,10 +143,12 @@ func (h *ApplicationDetailsHandler) SavePersonalInfo(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path int true "Application ID"
 // @Param addresses body []models.ApplicationAddress true "Addresses"
+// @Param If-Match header string false "Version the edit is based on"
 // @Success 200 {object} object{success=bool,message=string,data=[]models.ApplicationAddress}
 // @Failure 400 {object} object{error=string}
 // @Failure 403 {object} object{error=string}
 // @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string,section=string,current_version=int,current=object}
 // @Router /api/v1/applications/{id}/addresses [post]
 func (h *ApplicationDetailsHandler) SaveAddresses(c *fiber.Ctx) error {
 	// Get user_id from context - handle both UUID and string formats
@@ -162,6 +191,17 @@ func (h *ApplicationDetailsHandler) SaveAddresses(c *fiber.Ctx) error {
 		})
 	}
 
+	// Check the section can still be edited
+	if reason, err := editLockReason(h.revisionRepo, uint(applicationID), "section", models.SectionAddressInfo); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify application status",
+		})
+	} else if reason != "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": reason,
+		})
+	}
+
 	var addresses []models.ApplicationAddress
 	if err := c.BodyParser(&addresses); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -175,17 +215,25 @@ func (h *ApplicationDetailsHandler) SaveAddresses(c *fiber.Ctx) error {
 	}
 
 	// Save addresses
-	savedAddresses, err := h.applicationDetailsRepo.SaveAddresses(uint(applicationID), addresses)
+	// Reject saves based on an outdated version of the section
+	expectedVersion, err := ifMatchVersion(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save addresses",
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
 
+	savedAddresses, version, err := h.applicationDetailsRepo.SaveAddresses(uint(applicationID), addresses, expectedVersion)
+	if err != nil {
+		return formSaveError(c, h.applicationDetailsRepo, uint(applicationID), err, "Failed to save addresses")
+	}
+	setVersionETag(c, version)
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "บันทึกข้อมูลที่อยู่เรียบร้อยแล้ว",
 		"data":    savedAddresses,
+		"version": version,
 	})
 }
 
@@ -198,10 +246,12 @@ func (h *ApplicationDetailsHandler) SaveAddresses(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path int true "Application ID"
 // @Param education body []models.ApplicationEducationHistory true "Education history"
+// @Param If-Match header string false "Version the edit is based on"
 // @Success 200 {object} object{success=bool,message=string,data=[]models.ApplicationEducationHistory}
 // @Failure 400 {object} object{error=string}
 // @Failure 403 {object} object{error=string}
 // @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string,section=string,current_version=int,current=object}
 // @Router /api/v1/applications/{id}/education [post]
 func (h *ApplicationDetailsHandler) SaveEducation(c *fiber.Ctx) error {
 	// Get user_id from context - handle both UUID and string formats
@@ -244,6 +294,17 @@ func (h *ApplicationDetailsHandler) SaveEducation(c *fiber.Ctx) error {
 		})
 	}
 
+	// Check the section can still be edited
+	if reason, err := editLockReason(h.revisionRepo, uint(applicationID), "section", models.SectionEducationHistory); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify application status",
+		})
+	} else if reason != "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": reason,
+		})
+	}
+
 	var education []models.ApplicationEducationHistory
 	if err := c.BodyParser(&education); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -257,17 +318,25 @@ func (h *ApplicationDetailsHandler) SaveEducation(c *fiber.Ctx) error {
 	}
 
 	// Save education history
-	savedEducation, err := h.applicationDetailsRepo.SaveEducation(uint(applicationID), education)
+	// Reject saves based on an outdated version of the section
+	expectedVersion, err := ifMatchVersion(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save education history",
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
 
+	savedEducation, version, err := h.applicationDetailsRepo.SaveEducation(uint(applicationID), education, expectedVersion)
+	if err != nil {
+		return formSaveError(c, h.applicationDetailsRepo, uint(applicationID), err, "Failed to save education history")
+	}
+	setVersionETag(c, version)
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "บันทึกข้อมูลการศึกษาเรียบร้อยแล้ว",
 		"data":    savedEducation,
+		"version": version,
 	})
 }
 
@@ -280,10 +349,12 @@ func (h *ApplicationDetailsHandler) SaveEducation(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path int true "Application ID"
 // @Param family body object{members=[]models.ApplicationFamilyMember,guardians=[]models.ApplicationGuardian,siblings=[]models.ApplicationSibling,living_situation=models.ApplicationLivingSituation} true "Family information"
+// @Param If-Match header string false "Version the edit is based on"
 // @Success 200 {object} object{success=bool,message=string,data=object}
 // @Failure 400 {object} object{error=string}
 // @Failure 403 {object} object{error=string}
 // @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string,section=string,current_version=int,current=object}
 // @Router /api/v1/applications/{id}/family [post]
 func (h *ApplicationDetailsHandler) SaveFamily(c *fiber.Ctx) error {
 	// Get user_id from context - handle both UUID and string formats
@@ -326,6 +397,17 @@ func (h *ApplicationDetailsHandler) SaveFamily(c *fiber.Ctx) error {
 		})
 	}
 
+	// Check the section can still be edited
+	if reason, err := editLockReason(h.revisionRepo, uint(applicationID), "section", models.SectionFamilyInfo); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify application status",
+		})
+	} else if reason != "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": reason,
+		})
+	}
+
 	var req struct {
 		Members         []models.ApplicationFamilyMember    `json:"members"`
 		Guardians       []models.ApplicationGuardian        `json:"guardians"`
@@ -354,17 +436,25 @@ func (h *ApplicationDetailsHandler) SaveFamily(c *fiber.Ctx) error {
 	}
 
 	// Save family information
-	result, err := h.applicationDetailsRepo.SaveFamily(uint(applicationID), req.Members, req.Guardians, req.Siblings, req.LivingSituation)
+	// Reject saves based on an outdated version of the section
+	expectedVersion, err := ifMatchVersion(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save family information",
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
 
+	result, version, err := h.applicationDetailsRepo.SaveFamily(uint(applicationID), req.Members, req.Guardians, req.Siblings, req.LivingSituation, expectedVersion)
+	if err != nil {
+		return formSaveError(c, h.applicationDetailsRepo, uint(applicationID), err, "Failed to save family information")
+	}
+	setVersionETag(c, version)
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "บันทึกข้อมูลครอบครัวเรียบร้อยแล้ว",
 		"data":    result,
+		"version": version,
 	})
 }
 
@@ -377,10 +467,12 @@ func (h *ApplicationDetailsHandler) SaveFamily(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path int true "Application ID"
 // @Param financial body object{financial_info=models.ApplicationFinancialInfo,assets=[]models.ApplicationAsset,scholarship_history=[]models.ApplicationScholarshipHistory,health_info=models.ApplicationHealthInfo,funding_needs=models.ApplicationFundingNeeds} true "Financial information"
+// @Param If-Match header string false "Version the edit is based on"
 // @Success 200 {object} object{success=bool,message=string,data=object}
 // @Failure 400 {object} object{error=string}
 // @Failure 403 {object} object{error=string}
 // @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string,section=string,current_version=int,current=object}
 // @Router /api/v1/applications/{id}/financial [post]
 func (h *ApplicationDetailsHandler) SaveFinancial(c *fiber.Ctx) error {
 	// Get user_id from context - handle both UUID and string formats
@@ -423,6 +515,17 @@ func (h *ApplicationDetailsHandler) SaveFinancial(c *fiber.Ctx) error {
 		})
 	}
 
+	// Check the section can still be edited
+	if reason, err := editLockReason(h.revisionRepo, uint(applicationID), "section", models.SectionFinancialInfo); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify application status",
+		})
+	} else if reason != "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": reason,
+		})
+	}
+
 	var req struct {
 		FinancialInfo      *models.ApplicationFinancialInfo      `json:"financial_info"`
 		Assets             []models.ApplicationAsset             `json:"assets"`
@@ -455,24 +558,33 @@ func (h *ApplicationDetailsHandler) SaveFinancial(c *fiber.Ctx) error {
 	}
 
 	// Save financial information
-	result, err := h.applicationDetailsRepo.SaveFinancial(
+	// Reject saves based on an outdated version of the section
+	expectedVersion, err := ifMatchVersion(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	result, version, err := h.applicationDetailsRepo.SaveFinancial(
 		uint(applicationID),
 		req.FinancialInfo,
 		req.Assets,
 		req.ScholarshipHistory,
 		req.HealthInfo,
 		req.FundingNeeds,
+		expectedVersion,
 	)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save financial information",
-		})
+		return formSaveError(c, h.applicationDetailsRepo, uint(applicationID), err, "Failed to save financial information")
 	}
+	setVersionETag(c, version)
 
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "บันทึกข้อมูลการเงินเรียบร้อยแล้ว",
 		"data":    result,
+		"version": version,
 	})
 }
 
@@ -485,10 +597,12 @@ func (h *ApplicationDetailsHandler) SaveFinancial(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path int true "Application ID"
 // @Param activities body object{activities=[]models.ApplicationActivity,references=[]models.ApplicationReference} true "Activities and references"
+// @Param If-Match header string false "Version the edit is based on"
 // @Success 200 {object} object{success=bool,message=string,data=object}
 // @Failure 400 {object} object{error=string}
 // @Failure 403 {object} object{error=string}
 // @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string,section=string,current_version=int,current=object}
 // @Router /api/v1/applications/{id}/activities [post]
 func (h *ApplicationDetailsHandler) SaveActivities(c *fiber.Ctx) error {
 	// Get user_id from context - handle both UUID and string formats
@@ -531,6 +645,17 @@ func (h *ApplicationDetailsHandler) SaveActivities(c *fiber.Ctx) error {
 		})
 	}
 
+	// Check the section can still be edited
+	if reason, err := editLockReason(h.revisionRepo, uint(applicationID), "section", models.SectionActivitiesSkills); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify application status",
+		})
+	} else if reason != "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": reason,
+		})
+	}
+
 	var req struct {
 		Activities []models.ApplicationActivity  `json:"activities"`
 		References []models.ApplicationReference `json:"references"`
@@ -551,17 +676,25 @@ func (h *ApplicationDetailsHandler) SaveActivities(c *fiber.Ctx) error {
 	}
 
 	// Save activities
-	result, err := h.applicationDetailsRepo.SaveActivities(uint(applicationID), req.Activities, req.References)
+	// Reject saves based on an outdated version of the section
+	expectedVersion, err := ifMatchVersion(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save activities",
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
 
+	result, version, err := h.applicationDetailsRepo.SaveActivities(uint(applicationID), req.Activities, req.References, expectedVersion)
+	if err != nil {
+		return formSaveError(c, h.applicationDetailsRepo, uint(applicationID), err, "Failed to save activities")
+	}
+	setVersionETag(c, version)
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "บันทึกข้อมูลกิจกรรมเรียบร้อยแล้ว",
 		"data":    result,
+		"version": version,
 	})
 }
 
@@ -574,10 +707,12 @@ func (h *ApplicationDetailsHandler) SaveActivities(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path int true "Application ID"
 // @Param form body models.CompleteApplicationForm true "Complete application form"
+// @Param If-Match header string false "Version the edit is based on"
 // @Success 200 {object} object{success=bool,message=string,data=models.CompleteApplicationForm}
 // @Failure 400 {object} object{error=string}
 // @Failure 403 {object} object{error=string}
 // @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string,section=string,current_version=int,current=object}
 // @Router /api/v1/applications/{id}/complete-form [post]
 func (h *ApplicationDetailsHandler) SaveCompleteForm(c *fiber.Ctx) error {
 	// Get user_id from context - handle both UUID and string formats
@@ -620,6 +755,17 @@ func (h *ApplicationDetailsHandler) SaveCompleteForm(c *fiber.Ctx) error {
 		})
 	}
 
+	// Check the section can still be edited
+	if reason, err := editLockReason(h.revisionRepo, uint(applicationID), "", ""); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify application status",
+		})
+	} else if reason != "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": reason,
+		})
+	}
+
 	var form models.CompleteApplicationForm
 	if err := c.BodyParser(&form); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -678,17 +824,25 @@ func (h *ApplicationDetailsHandler) SaveCompleteForm(c *fiber.Ctx) error {
 	}
 
 	// Save complete form
-	savedForm, err := h.applicationDetailsRepo.SaveCompleteForm(uint(applicationID), &form)
+	// Reject saves based on an outdated version of the section
+	expectedVersion, err := ifMatchVersion(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save application form",
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
 
+	savedForm, version, err := h.applicationDetailsRepo.SaveCompleteForm(uint(applicationID), &form, expectedVersion)
+	if err != nil {
+		return formSaveError(c, h.applicationDetailsRepo, uint(applicationID), err, "Failed to save application form")
+	}
+	setVersionETag(c, version)
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "บันทึกข้อมูลใบสมัครทั้งหมดเรียบร้อยแล้ว",
 		"data":    savedForm,
+		"version": version,
 	})
 }
 
@@ -757,6 +911,23 @@ func (h *ApplicationDetailsHandler) GetCompleteForm(c *fiber.Ctx) error {
 		}
 	}
 
+	// Reviewers see the form as it was submitted unless they ask for live data
+	if isAdmin && c.Query("source") != "live" {
+		snapshot, err := h.snapshotRepo.GetLatest(uint(applicationID))
+		if err != nil && err != sql.ErrNoRows {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to retrieve application details",
+			})
+		}
+		if snapshot != nil {
+			return c.JSON(fiber.Map{
+				"success":  true,
+				"data":     snapshot.FormData,
+				"snapshot": snapshotSummary(snapshot),
+			})
+		}
+	}
+
 	// Get complete form
 	form, err := h.applicationDetailsRepo.GetCompleteForm(uint(applicationID))
 	if err != nil {
@@ -770,15 +941,24 @@ func (h *ApplicationDetailsHandler) GetCompleteForm(c *fiber.Ctx) error {
 		})
 	}
 
+	versions, err := h.applicationDetailsRepo.GetFormVersions(uint(applicationID))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve application details",
+		})
+	}
+	setVersionETag(c, versions.FormVersion)
+
 	return c.JSON(fiber.Map{
-		"success": true,
-		"data":    form,
+		"success":  true,
+		"data":     form,
+		"versions": versions,
 	})
 }
 
 // SubmitApplication submits the application for review
 // @Summary Submit application
-// @Description Submit the application for review (Student only)
+// @Description Submit the application for review. Submission is blocked until the documents meet every requirement of the scholarship (Student only)
 // @Tags Application Details
 // @Produce json
 // @Security BearerAuth
@@ -865,16 +1045,53 @@ func (h *ApplicationDetailsHandler) SubmitApplication(c *fiber.Ctx) error {
 		})
 	}
 
+	// Validate required documents
+	documents, err := h.applicationRepo.GetDocuments(uint(applicationID))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to validate documents",
+		})
+	}
+	checklist, err := documentChecklist(h.requirementRepo, uint(applicationID), form, documents)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to validate application documents",
+		})
+	}
+	if !checklist.Complete {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":     "Please upload all required documents before submitting",
+			"errors":    checklist.Problems,
+			"checklist": checklist,
+		})
+	}
+
+	// Snapshot the form and documents exactly as submitted
+	snapshot, err := buildApplicationSnapshot(h.applicationRepo, h.applicationDetailsRepo, uint(applicationID))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to submit application",
+		})
+	}
+	snapshot.Reason = models.SnapshotReasonSubmit
+	snapshot.CreatedBy = &userID
+
 	// Submit application
-	if err := h.applicationRepo.Submit(uint(applicationID)); err != nil {
+	if err := h.snapshotRepo.SubmitWithSnapshot(snapshot); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to submit application",
 		})
 	}
 
+	// Assess financial need and compute the priority score for reviewers
+	if _, _, err := refreshApplicationScores(uint(applicationID)); err != nil {
+		fmt.Printf("Warning: Failed to score application %d: %v\n", applicationID, err)
+	}
+
 	return c.JSON(fiber.Map{
-		"success": true,
-		"message": "ส่งใบสมัครเรียบร้อยแล้ว รอการตรวจสอบจากเจ้าหน้าที่",
+		"success":  true,
+		"message":  "ส่งใบสมัครเรียบร้อยแล้ว รอการตรวจสอบจากเจ้าหน้าที่",
+		"snapshot": snapshotSummary(snapshot),
 	})
 }
 
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -13,6 +12,7 @@ import (
 	"scholarship-system/internal/database"
 	"scholarship-system/internal/models"
 	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
 )
 
 type ApplicationDraftHandler struct {
@@ -20,6 +20,7 @@ type ApplicationDraftHandler struct {
 	applicationRepo *repository.ApplicationRepository
 	scholarshipRepo *repository.ScholarshipRepository
 	userRepo        *repository.UserRepository
+	eligibility     *services.EligibilityService
 }
 
 func NewApplicationDraftHandler(cfg *config.Config) *ApplicationDraftHandler {
@@ -28,6 +29,7 @@ func NewApplicationDraftHandler(cfg *config.Config) *ApplicationDraftHandler {
 		applicationRepo: repository.NewApplicationRepository(),
 		scholarshipRepo: repository.NewScholarshipRepository(),
 		userRepo:        repository.NewUserRepository(),
+		eligibility:     services.NewEligibilityService(),
 	}
 }
 
@@ -296,6 +298,14 @@ func (h *ApplicationDraftHandler) GetDraft(c *fiber.Ctx) error {
 		}
 	}
 
+	// Stale fields to check when the draft was copied from a previous application
+	var prefill *models.ApplicationPrefill
+	if p, err := loadPrefill(repository.NewPrefillRepository(), detailsRepo, uint(application.ApplicationID)); err == nil {
+		prefill = p
+	} else if err != sql.ErrNoRows {
+		fmt.Printf("Warning: Failed to load prefill for application %d: %v\n", application.ApplicationID, err)
+	}
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data": fiber.Map{
@@ -303,6 +313,7 @@ func (h *ApplicationDraftHandler) GetDraft(c *fiber.Ctx) error {
 			"scholarship_id": application.ScholarshipID,
 			"current_step":   currentStep,
 			"draft_data":     completeForm,
+			"prefill":        prefill,
 			"updated_at":     application.UpdatedAt,
 		},
 	})
@@ -316,8 +327,8 @@ func (h *ApplicationDraftHandler) GetDraft(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Scholarship ID"
-// @Param data body object{student_data=object} true "Student data"
-// @Success 200 {object} object{success=bool,data=object}
+// @Param data body object{student_data=object} false "Student data used where the student record is incomplete"
+// @Success 200 {object} object{success=bool,data=models.EligibilityResult}
 // @Failure 400 {object} object{error=string}
 // @Failure 404 {object} object{error=string}
 // @Router /api/v1/scholarships/{id}/check-eligibility [post]
@@ -329,17 +340,26 @@ func (h *ApplicationDraftHandler) CheckEligibility(c *fiber.Ctx) error {
 		})
 	}
 
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
 	var req struct {
 		StudentData map[string]interface{} `json:"student_data"`
 	}
 
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
 	}
 
-	// Get scholarship with eligibility criteria
+	// Get scholarship with eligibility rules
 	scholarship, err := h.scholarshipRepo.GetByID(uint(scholarshipID))
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -352,117 +372,58 @@ func (h *ApplicationDraftHandler) CheckEligibility(c *fiber.Ctx) error {
 		})
 	}
 
-	// Parse eligibility criteria
-	var criteria map[string]interface{}
-	if scholarship.EligibilityCriteria != nil && *scholarship.EligibilityCriteria != "" {
-		if err := json.Unmarshal([]byte(*scholarship.EligibilityCriteria), &criteria); err != nil {
-			// If not JSON, treat as simple criteria
-			criteria = make(map[string]interface{})
-		}
-	} else {
-		criteria = make(map[string]interface{})
+	user, err := h.userRepo.GetByID(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get user information",
+		})
 	}
 
-	// Check eligibility
-	isEligible := true
-	criteriaResults := []map[string]interface{}{}
-	missingRequirements := []string{}
-	eligibilityScore := 100.0
-
-	// Check min GPA
-	if minGPA, ok := criteria["min_gpa"].(float64); ok {
-		if gpa, exists := req.StudentData["gpa"].(float64); exists {
-			passed := gpa >= minGPA
-			if !passed {
-				isEligible = false
-				eligibilityScore -= 20
-			}
-			criteriaResults = append(criteriaResults, map[string]interface{}{
-				"criteria": "min_gpa",
-				"required": minGPA,
-				"actual":   gpa,
-				"passed":   passed,
-			})
-		} else {
-			missingRequirements = append(missingRequirements, "GPA information")
-		}
-	}
+	// Get student_id from students table
+	var studentID string
+	err = database.DB.QueryRow(
+		"SELECT student_id FROM students WHERE user_id = $1",
+		userID,
+	).Scan(&studentID)
 
-	// Check max family income
-	if maxIncome, ok := criteria["max_family_income"].(float64); ok {
-		if income, exists := req.StudentData["family_income"].(float64); exists {
-			passed := income <= maxIncome
-			if !passed {
-				isEligible = false
-				eligibilityScore -= 30
-			}
-			criteriaResults = append(criteriaResults, map[string]interface{}{
-				"criteria": "max_family_income",
-				"required": maxIncome,
-				"actual":   income,
-				"passed":   passed,
-			})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// Fallback to email if no student record
+			studentID = user.Email
 		} else {
-			missingRequirements = append(missingRequirements, "Family income information")
-		}
-	}
-
-	// Check allowed faculties
-	if allowedFaculties, ok := criteria["allowed_faculties"].([]interface{}); ok {
-		if faculty, exists := req.StudentData["faculty"].(string); exists {
-			passed := false
-			for _, f := range allowedFaculties {
-				if fStr, ok := f.(string); ok && fStr == faculty {
-					passed = true
-					break
-				}
-			}
-			if !passed {
-				isEligible = false
-				eligibilityScore -= 25
-			}
-			criteriaResults = append(criteriaResults, map[string]interface{}{
-				"criteria": "allowed_faculties",
-				"required": allowedFaculties,
-				"actual":   faculty,
-				"passed":   passed,
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get student information",
 			})
-		} else {
-			missingRequirements = append(missingRequirements, "Faculty information")
 		}
 	}
 
-	// Check year level
-	if minYear, ok := criteria["min_year_level"].(float64); ok {
-		if year, exists := req.StudentData["year_level"].(float64); exists {
-			passed := year >= minYear
-			if !passed {
-				isEligible = false
-				eligibilityScore -= 15
-			}
-			criteriaResults = append(criteriaResults, map[string]interface{}{
-				"criteria": "min_year_level",
-				"required": minYear,
-				"actual":   year,
-				"passed":   passed,
-			})
-		} else {
-			missingRequirements = append(missingRequirements, "Year level information")
-		}
+	profile, err := h.eligibility.LoadProfile(studentID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load student profile",
+		})
 	}
 
-	// Ensure score doesn't go below 0
-	if eligibilityScore < 0 {
-		eligibilityScore = 0
+	// Submitted data only fills in what the student record does not have,
+	// except family income which the student declares on the application
+	if income, ok := req.StudentData["family_income"].(float64); ok {
+		profile.FamilyIncome = &income
+	}
+	if gpa, ok := req.StudentData["gpa"].(float64); ok && profile.GPA == nil {
+		profile.GPA = &gpa
+	}
+	if year, ok := req.StudentData["year_level"].(float64); ok && profile.YearLevel == nil {
+		yearLevel := int(year)
+		profile.YearLevel = &yearLevel
+	}
+	if faculty, ok := req.StudentData["faculty"].(string); ok && profile.FacultyCode == nil {
+		profile.FacultyCode = &faculty
 	}
 
+	result := services.EvaluateEligibility(scholarship.ScholarshipID, scholarship.EligibilityRules, profile)
+
 	return c.JSON(fiber.Map{
 		"success": true,
-		"data": fiber.Map{
-			"is_eligible":          isEligible,
-			"eligibility_score":    eligibilityScore,
-			"criteria_results":     criteriaResults,
-			"missing_requirements": missingRequirements,
-		},
+		"data":    result,
 	})
 }
@@ -181,154 +181,6 @@ func (h *ApplicationEnhancedHandler) DeleteDraft(c *fiber.Ctx) error {
 	})
 }
 
-// @Summary Start Bulk Document Upload
-// @Description Initialize bulk document upload session
-// @Tags Documents
-// @Accept json
-// @Produce json
-// @Security ApiKeyAuth
-// @Param request body models.BulkUploadRequest true "Bulk upload request"
-// @Success 200 {object} models.BulkUploadResponse
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
-// @Router /api/v1/documents/bulk-upload [post]
-func (h *ApplicationEnhancedHandler) StartBulkUpload(c *fiber.Ctx) error {
-	userClaims := c.Locals("user").(*jwt.Token).Claims.(jwt.MapClaims)
-	_ = userClaims["user_id"].(string)
-
-	var req models.BulkUploadRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error":   "Invalid request body",
-		})
-	}
-
-	if len(req.DocumentTypes) == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error":   "At least one document type is required",
-		})
-	}
-
-	// TODO: Create bulk upload session
-	// TODO: Generate session token
-	// TODO: Set expiration time
-
-	response := models.BulkUploadResponse{
-		Success:      true,
-		SessionToken: "bulk_session_" + strconv.FormatInt(time.Now().Unix(), 10),
-		UploadURL:    "/api/v1/documents/bulk-upload/files",
-		ExpiresAt:    time.Now().Add(2 * time.Hour),
-	}
-
-	return c.JSON(response)
-}
-
-// @Summary Upload Files in Bulk Session
-// @Description Upload multiple files in a bulk upload session
-// @Tags Documents
-// @Accept multipart/form-data
-// @Produce json
-// @Security ApiKeyAuth
-// @Param session_token query string true "Bulk upload session token"
-// @Param files formData file true "Files to upload" multiple
-// @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
-// @Router /api/v1/documents/bulk-upload/files [post]
-func (h *ApplicationEnhancedHandler) UploadBulkFiles(c *fiber.Ctx) error {
-	sessionToken := c.Query("session_token")
-	if sessionToken == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error":   "Session token is required",
-		})
-	}
-
-	// Parse multipart form
-	form, err := c.MultipartForm()
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error":   "Failed to parse multipart form",
-		})
-	}
-
-	files := form.File["files"]
-	if len(files) == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error":   "No files provided",
-		})
-	}
-
-	// TODO: Validate session token
-	// TODO: Process each file
-	// TODO: Update upload progress
-	// TODO: Validate file types and sizes
-
-	uploadedFiles := 0
-	failedFiles := 0
-	errors := []string{}
-
-	for _, file := range files {
-		// Mock file processing
-		if file.Size > 10*1024*1024 { // 10MB limit
-			failedFiles++
-			errors = append(errors, file.Filename+": File size exceeds 10MB limit")
-		} else {
-			uploadedFiles++
-		}
-	}
-
-	return c.JSON(fiber.Map{
-		"success":        true,
-		"message":        "Bulk upload completed",
-		"uploaded_files": uploadedFiles,
-		"failed_files":   failedFiles,
-		"total_files":    len(files),
-		"errors":         errors,
-	})
-}
-
-// @Summary Get Bulk Upload Progress
-// @Description Get progress of bulk upload session
-// @Tags Documents
-// @Produce json
-// @Security ApiKeyAuth
-// @Param session_token query string true "Bulk upload session token"
-// @Success 200 {object} models.UploadProgressResponse
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
-// @Router /api/v1/documents/bulk-upload/progress [get]
-func (h *ApplicationEnhancedHandler) GetUploadProgress(c *fiber.Ctx) error {
-	sessionToken := c.Query("session_token")
-	if sessionToken == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error":   "Session token is required",
-		})
-	}
-
-	// TODO: Get upload progress from database
-
-	response := models.UploadProgressResponse{
-		SessionToken:  sessionToken,
-		TotalFiles:    5,
-		UploadedFiles: 3,
-		FailedFiles:   1,
-		Progress:      60.0,
-		Status:        "in_progress",
-		Errors:        []string{"file1.pdf: Invalid file format"},
-	}
-
-	return c.JSON(response)
-}
-
 // @Summary Validate Application
 // @Description Validate application data against rules
 // @Tags Application
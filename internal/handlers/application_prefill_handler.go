@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+)
+
+type ApplicationPrefillHandler struct {
+	cfg             *config.Config
+	applicationRepo *repository.ApplicationRepository
+	scholarshipRepo *repository.ScholarshipRepository
+	userRepo        *repository.UserRepository
+	detailsRepo     *repository.ApplicationDetailsRepository
+	eligibilityRepo *repository.EligibilityRepository
+	prefillRepo     *repository.PrefillRepository
+}
+
+func NewApplicationPrefillHandler(cfg *config.Config) *ApplicationPrefillHandler {
+	return &ApplicationPrefillHandler{
+		cfg:             cfg,
+		applicationRepo: repository.NewApplicationRepository(),
+		scholarshipRepo: repository.NewScholarshipRepository(),
+		userRepo:        repository.NewUserRepository(),
+		detailsRepo:     repository.NewApplicationDetailsRepository(),
+		eligibilityRepo: repository.NewEligibilityRepository(),
+		prefillRepo:     repository.NewPrefillRepository(),
+	}
+}
+
+// ListSources lists the previous applications a new draft can start from
+// @Summary List previous applications for prefill
+// @Description List the student's submitted applications, newest first, that a new draft can be copied from
+// @Tags Applications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} object{success=bool,data=[]repository.PrefillCandidate}
+// @Failure 401 {object} object{error=string}
+// @Router /api/v1/applications/draft/sources [get]
+func (h *ApplicationPrefillHandler) ListSources(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	studentID, err := h.studentIDForUser(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get student information",
+		})
+	}
+
+	candidates, err := h.prefillRepo.ListCandidates(studentID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve previous applications",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    candidates,
+	})
+}
+
+// CreateFromPrevious starts a draft from a previous application
+// @Summary Start draft from previous application
+// @Description Create a draft for a scholarship with personal info, addresses, education, family and activities copied from a previous application. Awards received through this system are added to the scholarship history, recent income certificates are reused, and values that may be out of date (incomes and certificates past the age limit, year level and GPA from another academic year) are returned as stale fields to check
+// @Tags Applications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.PrefillDraftRequest true "Scholarship and optional source application"
+// @Success 201 {object} object{success=bool,message=string,data=object{application_id=int,prefill=models.ApplicationPrefill,draft_data=models.CompleteApplicationForm}}
+// @Failure 400 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
+// @Router /api/v1/applications/draft/from-previous [post]
+func (h *ApplicationPrefillHandler) CreateFromPrevious(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	var req models.PrefillDraftRequest
+	if err := c.BodyParser(&req); err != nil || req.ScholarshipID == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "scholarship_id is required",
+		})
+	}
+
+	studentID, err := h.studentIDForUser(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get student information",
+		})
+	}
+
+	scholarship, err := h.scholarshipRepo.GetByID(req.ScholarshipID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Scholarship not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch scholarship",
+		})
+	}
+
+	// Pick the source: the requested application or the latest one
+	candidates, err := h.prefillRepo.ListCandidates(studentID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve previous applications",
+		})
+	}
+	var source *repository.PrefillCandidate
+	for i := range candidates {
+		if req.SourceApplicationID == nil || candidates[i].ApplicationID == *req.SourceApplicationID {
+			source = &candidates[i]
+			break
+		}
+	}
+	if source == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "No previous application to start from",
+		})
+	}
+
+	// Reuse an untouched draft; anything else for this scholarship is a conflict
+	var application *models.ScholarshipApplication
+	existing, err := h.applicationRepo.GetByStudentAndScholarship(studentID, req.ScholarshipID)
+	if err == nil && existing != nil {
+		if existing.ApplicationStatus != "draft" {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": fmt.Sprintf("You already have a %s application for this scholarship", existing.ApplicationStatus),
+			})
+		}
+		versions, err := h.detailsRepo.GetFormVersions(existing.ApplicationID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check draft application",
+			})
+		}
+		if versions.FormVersion > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Your draft for this scholarship already has data; continue editing it instead",
+			})
+		}
+		application = existing
+	}
+
+	sourceForm, err := h.detailsRepo.GetCompleteForm(source.ApplicationID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load previous application",
+		})
+	}
+	awards, err := h.eligibilityRepo.GetPriorAwards(studentID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load prior awards",
+		})
+	}
+
+	result := services.BuildPrefill(models.PrefillSource{
+		Form:         sourceForm,
+		AcademicYear: source.AcademicYear,
+		RecordedAt:   source.RecordedAt(),
+	}, scholarship.AcademicYear, awards, time.Now(), int(h.cfg.PrefillStaleMonths))
+
+	if application == nil {
+		application = &models.ScholarshipApplication{
+			StudentID:         studentID,
+			ScholarshipID:     req.ScholarshipID,
+			ApplicationStatus: "draft",
+			CreatedAt:         time.Now(),
+			UpdatedAt:         time.Now(),
+		}
+		if err := h.applicationRepo.Create(application); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to create draft application",
+			})
+		}
+	}
+
+	savedForm, _, err := h.detailsRepo.SaveCompleteForm(application.ApplicationID, result.Form, nil)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to copy previous application",
+		})
+	}
+
+	for i := range result.IncomeCertificates {
+		cert := result.IncomeCertificates[i]
+		cert.ApplicationID = int(application.ApplicationID)
+		if err := h.detailsRepo.CreateIncomeCertificate(&cert); err != nil {
+			fmt.Printf("Warning: Failed to copy income certificate to application %d: %v\n", application.ApplicationID, err)
+			continue
+		}
+		savedForm.IncomeCertificates = append(savedForm.IncomeCertificates, cert)
+	}
+
+	versions, err := h.detailsRepo.GetFormVersions(application.ApplicationID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to read form versions",
+		})
+	}
+
+	prefill := &models.ApplicationPrefill{
+		ApplicationID:       application.ApplicationID,
+		SourceApplicationID: &source.ApplicationID,
+		CopiedSections:      result.CopiedSections,
+		StaleFields:         result.StaleFields,
+		SectionVersions:     versions.Sections,
+		AwardsAdded:         result.AwardsAdded,
+		CreatedBy:           &userID,
+	}
+	if err := h.prefillRepo.Save(prefill); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save prefill details",
+		})
+	}
+
+	setVersionETag(c, versions.FormVersion)
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": fmt.Sprintf("Draft started from application #%d", source.ApplicationID),
+		"data": fiber.Map{
+			"application_id": application.ApplicationID,
+			"scholarship_id": application.ScholarshipID,
+			"prefill":        prefill,
+			"draft_data":     savedForm,
+			"versions":       versions,
+		},
+	})
+}
+
+// GetPrefill returns what was copied into a draft and which fields still
+// need checking
+// @Summary Get draft prefill status
+// @Description Get the source application, copied sections and stale fields of a draft started from a previous application. A stale field is resolved once its section has been saved again
+// @Tags Applications
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Success 200 {object} object{success=bool,data=models.ApplicationPrefill}
+// @Failure 400 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/applications/{id}/prefill [get]
+func (h *ApplicationPrefillHandler) GetPrefill(c *fiber.Ctx) error {
+	applicationID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid application ID",
+		})
+	}
+
+	prefill, err := loadPrefill(h.prefillRepo, h.detailsRepo, uint(applicationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Application was not started from a previous application",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve prefill details",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    prefill,
+	})
+}
+
+// loadPrefill returns the prefill record of an application with the stale
+// fields of sections saved since marked as resolved
+func loadPrefill(prefillRepo *repository.PrefillRepository, detailsRepo *repository.ApplicationDetailsRepository, applicationID uint) (*models.ApplicationPrefill, error) {
+	prefill, err := prefillRepo.Get(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := detailsRepo.GetFormVersions(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	services.ResolveStaleFields(prefill, versions.Sections)
+	return prefill, nil
+}
+
+// studentIDForUser returns the student ID of a user, or their email when
+// they have no student record
+func (h *ApplicationPrefillHandler) studentIDForUser(userID uuid.UUID) (string, error) {
+	var studentID string
+	err := database.DB.QueryRow("SELECT student_id FROM students WHERE user_id = $1", userID).Scan(&studentID)
+	if err == nil {
+		return studentID, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", err
+	}
+
+	user, err := h.userRepo.GetByID(userID)
+	if err != nil {
+		return "", err
+	}
+	return user.Email, nil
+}
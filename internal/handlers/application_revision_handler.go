@@ -0,0 +1,571 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+)
+
+type ApplicationRevisionHandler struct {
+	cfg                    *config.Config
+	applicationRepo        *repository.ApplicationRepository
+	applicationDetailsRepo *repository.ApplicationDetailsRepository
+	revisionRepo           *repository.RevisionRepository
+}
+
+func NewApplicationRevisionHandler(cfg *config.Config) *ApplicationRevisionHandler {
+	return &ApplicationRevisionHandler{
+		cfg:                    cfg,
+		applicationRepo:        repository.NewApplicationRepository(),
+		applicationDetailsRepo: repository.NewApplicationDetailsRepository(),
+		revisionRepo:           repository.NewRevisionRepository(),
+	}
+}
+
+// RequestRevision sends a submitted application back to the student
+// @Summary Send application back for revision
+// @Description Return a submitted application to the student with per-section and per-document comments. Only the flagged items become editable until the resubmission deadline (Officer/Admin only)
+// @Tags Application Revisions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Param request body models.RequestRevisionRequest true "Revision request"
+// @Success 201 {object} object{success=bool,message=string,data=models.ApplicationRevisionRequest}
+// @Failure 400 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
+// @Router /api/v1/admin/applications/{id}/request-revision [post]
+func (h *ApplicationRevisionHandler) RequestRevision(c *fiber.Ctx) error {
+	officerID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	applicationID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid application ID",
+		})
+	}
+
+	var req models.RequestRevisionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if len(req.Items) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "At least one section or document must be flagged",
+		})
+	}
+	if !req.ResubmissionDeadline.After(time.Now()) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Resubmission deadline must be in the future",
+		})
+	}
+
+	items := make([]models.ApplicationRevisionItem, 0, len(req.Items))
+	for i, item := range req.Items {
+		if item.Comment == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("items[%d]: comment is required", i),
+			})
+		}
+		revisionItem := models.ApplicationRevisionItem{
+			ItemType: item.ItemType,
+			Comment:  item.Comment,
+		}
+		switch item.ItemType {
+		case "section":
+			if !isApplicationSection(item.SectionName) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": fmt.Sprintf("items[%d]: invalid section name %q", i, item.SectionName),
+				})
+			}
+			sectionName := item.SectionName
+			revisionItem.SectionName = &sectionName
+		case "document":
+			if item.DocumentType == "" {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": fmt.Sprintf("items[%d]: document_type is required", i),
+				})
+			}
+			documentType := item.DocumentType
+			revisionItem.DocumentType = &documentType
+			revisionItem.DocumentID = item.DocumentID
+		default:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("items[%d]: item_type must be section or document", i),
+			})
+		}
+		items = append(items, revisionItem)
+	}
+
+	application, err := h.applicationRepo.GetByID(uint(applicationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Application not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch application",
+		})
+	}
+
+	switch application.ApplicationStatus {
+	case "draft", "approved", "rejected", "withdrawn", "declined":
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": fmt.Sprintf("Cannot request revision for an application with status %s", application.ApplicationStatus),
+		})
+	case "needs_revision":
+		// Only a revision still within its deadline holds the application; an
+		// overdue one is closed so the officer can send it back again
+		open, err := h.revisionRepo.GetOpenRevision(uint(applicationID))
+		if err != nil && err != sql.ErrNoRows {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to fetch revision request",
+			})
+		}
+		if err == nil {
+			if !open.IsOverdue() {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": "Application already has an open revision request",
+				})
+			}
+			if err := h.revisionRepo.MarkExpired(open); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to expire revision request",
+				})
+			}
+		}
+	}
+
+	// Snapshot the form and documents so the resubmission can be diffed
+	form, err := h.applicationDetailsRepo.GetCompleteForm(uint(applicationID))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load application form",
+		})
+	}
+	formSnapshot, err := json.Marshal(form)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to snapshot application form",
+		})
+	}
+	docs, err := h.revisionRepo.GetDocumentRefs(uint(applicationID))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load application documents",
+		})
+	}
+	documentSnapshot, _ := json.Marshal(docs)
+
+	revision := &models.ApplicationRevisionRequest{
+		ApplicationID:        uint(applicationID),
+		RequestedBy:          officerID,
+		ResubmissionDeadline: req.ResubmissionDeadline,
+		FormSnapshot:         formSnapshot,
+		DocumentSnapshot:     documentSnapshot,
+		Items:                items,
+	}
+	if req.Comments != "" {
+		revision.Comments = &req.Comments
+	}
+
+	if err := h.revisionRepo.CreateRevision(revision); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create revision request",
+		})
+	}
+
+	if studentUserID, err := h.applicationRepo.GetApplicantUserID(uint(applicationID)); err == nil {
+		message := fmt.Sprintf("เจ้าหน้าที่ขอให้แก้ไขใบสมัครจำนวน %d รายการ กรุณาแก้ไขและส่งกลับภายในวันที่ %s",
+			len(items), req.ResubmissionDeadline.Format("02/01/2006 15:04"))
+		CreateNotification(studentUserID.String(), "application_revision", "ใบสมัครถูกส่งกลับให้แก้ไข", message,
+			strconv.Itoa(applicationID), "application", "high")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Application sent back for revision",
+		"data":    revision,
+	})
+}
+
+// ListRevisions returns the revision history of an application
+// @Summary List revision history
+// @Description Get every revision request of an application with flagged items and the changes made on resubmission (Officer/Admin only)
+// @Tags Application Revisions
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Success 200 {object} object{success=bool,data=[]models.ApplicationRevisionRequest}
+// @Failure 400 {object} object{error=string}
+// @Router /api/v1/admin/applications/{id}/revisions [get]
+func (h *ApplicationRevisionHandler) ListRevisions(c *fiber.Ctx) error {
+	applicationID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid application ID",
+		})
+	}
+
+	revisions, err := h.revisionRepo.ListRevisions(uint(applicationID))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch revision history",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    revisions,
+	})
+}
+
+// GetCurrentRevision returns the open revision request for the student
+// @Summary Get open revision request
+// @Description Get the officer's comments and the sections and documents the student may edit (Student only)
+// @Tags Application Revisions
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Success 200 {object} object{success=bool,data=object}
+// @Failure 403 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/applications/{id}/revision [get]
+func (h *ApplicationRevisionHandler) GetCurrentRevision(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	applicationID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid application ID",
+		})
+	}
+
+	if err := h.verifyApplicationOwnership(uint(applicationID), userID); err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Application not found",
+			})
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You can only view your own applications",
+		})
+	}
+
+	revision, err := h.revisionRepo.GetOpenRevision(uint(applicationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "No open revision request for this application",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch revision request",
+		})
+	}
+
+	editableSections := []string{}
+	editableDocuments := []string{}
+	if !revision.IsOverdue() {
+		for _, item := range revision.Items {
+			if item.SectionName != nil {
+				editableSections = append(editableSections, *item.SectionName)
+			}
+			if item.DocumentType != nil {
+				editableDocuments = append(editableDocuments, *item.DocumentType)
+			}
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"revision":           revision,
+			"is_overdue":         revision.IsOverdue(),
+			"editable_sections":  editableSections,
+			"editable_documents": editableDocuments,
+		},
+	})
+}
+
+// Resubmit returns a revised application to the officers
+// @Summary Resubmit revised application
+// @Description Resubmit an application that was sent back for revision. Every flagged document type must have been re-uploaded (Student only)
+// @Tags Application Revisions
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Success 200 {object} object{success=bool,message=string,data=models.RevisionDiff}
+// @Failure 400 {object} object{error=string}
+// @Failure 403 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/applications/{id}/resubmit [post]
+func (h *ApplicationRevisionHandler) Resubmit(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	applicationID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid application ID",
+		})
+	}
+
+	if err := h.verifyApplicationOwnership(uint(applicationID), userID); err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Application not found",
+			})
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You can only resubmit your own applications",
+		})
+	}
+
+	revision, err := h.revisionRepo.GetOpenRevision(uint(applicationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "No open revision request for this application",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch revision request",
+		})
+	}
+
+	if revision.IsOverdue() {
+		if err := h.revisionRepo.MarkExpired(revision); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to expire revision request",
+			})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "The resubmission deadline has passed",
+		})
+	}
+
+	docs, err := h.revisionRepo.GetDocumentRefs(uint(applicationID))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load application documents",
+		})
+	}
+
+	// Every flagged document type needs a new upload
+	missing := []string{}
+	for _, item := range revision.Items {
+		if item.ItemType != "document" || item.DocumentType == nil {
+			continue
+		}
+		uploaded := false
+		for _, doc := range docs {
+			if doc.DocumentType == *item.DocumentType && doc.UploadedAt.After(revision.CreatedAt) {
+				uploaded = true
+				break
+			}
+		}
+		if !uploaded {
+			missing = append(missing, *item.DocumentType)
+		}
+	}
+	if len(missing) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":             "Flagged documents have not been re-uploaded",
+			"missing_documents": missing,
+		})
+	}
+
+	form, err := h.applicationDetailsRepo.GetCompleteForm(uint(applicationID))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load application form",
+		})
+	}
+	current, err := json.Marshal(form)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to encode application form",
+		})
+	}
+
+	fields, err := services.DiffApplicationForms(revision.FormSnapshot, current)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to compare application versions",
+		})
+	}
+	var previousDocs []models.RevisionDocumentRef
+	if len(revision.DocumentSnapshot) > 0 {
+		if err := json.Unmarshal(revision.DocumentSnapshot, &previousDocs); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to read document snapshot",
+			})
+		}
+	}
+	added, removed := services.DiffDocuments(previousDocs, docs)
+
+	changes := &models.RevisionDiff{
+		Fields:           fields,
+		DocumentsAdded:   added,
+		DocumentsRemoved: removed,
+	}
+
+	if err := h.revisionRepo.CompleteResubmission(revision, changes); err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Revision request is no longer open",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to resubmit application",
+		})
+	}
+
+	// Keep the revised version alongside the original submission
+	if _, err := saveResubmissionSnapshot(h.applicationRepo, h.applicationDetailsRepo, uint(applicationID), userID); err != nil {
+		fmt.Printf("Warning: Failed to snapshot resubmitted application %d: %v\n", applicationID, err)
+	}
+
+	// Assess financial need and compute the priority score for reviewers
+	if _, _, err := refreshApplicationScores(uint(applicationID)); err != nil {
+		fmt.Printf("Warning: Failed to score application %d: %v\n", applicationID, err)
+	}
+
+	message := fmt.Sprintf("ใบสมัคร #%d ถูกแก้ไขและส่งกลับแล้ว (แก้ไขข้อมูล %d รายการ, เอกสารใหม่ %d ไฟล์)",
+		applicationID, len(fields), len(added))
+	CreateNotification(revision.RequestedBy.String(), "application_resubmitted", "นักศึกษาส่งใบสมัครที่แก้ไขแล้ว", message,
+		strconv.Itoa(applicationID), "application", "normal")
+	CreateNotification(userID.String(), "application_resubmitted", "ส่งใบสมัครที่แก้ไขเรียบร้อยแล้ว",
+		"ใบสมัครของคุณถูกส่งกลับให้เจ้าหน้าที่พิจารณาอีกครั้งแล้ว", strconv.Itoa(applicationID), "application", "normal")
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Application resubmitted successfully",
+		"data":    changes,
+	})
+}
+
+// verifyApplicationOwnership checks if the user owns the application
+func (h *ApplicationRevisionHandler) verifyApplicationOwnership(applicationID uint, userID uuid.UUID) error {
+	application, err := h.applicationRepo.GetByID(applicationID)
+	if err != nil {
+		return err
+	}
+
+	var studentID string
+	err = database.DB.QueryRow(
+		"SELECT student_id FROM students WHERE user_id = $1",
+		userID,
+	).Scan(&studentID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// If no student record exists, try matching with email
+			user, userErr := repository.NewUserRepository().GetByID(userID)
+			if userErr != nil {
+				return userErr
+			}
+			if application.StudentID != user.Email {
+				return fiber.NewError(fiber.StatusForbidden, "Unauthorized")
+			}
+			return nil
+		}
+		return err
+	}
+
+	if application.StudentID != studentID {
+		return fiber.NewError(fiber.StatusForbidden, "Unauthorized")
+	}
+
+	return nil
+}
+
+// editLockReason reports why a student may not change part of an
+// application. Drafts are fully editable and documents (itemType "document")
+// can be added in any other status, such as document_pending; an application
+// sent back for revision only allows the flagged sections (itemType
+// "section") or document types until the resubmission deadline. An empty
+// itemType asks about the whole form. An empty reason means editing is allowed.
+func editLockReason(revisionRepo *repository.RevisionRepository, applicationID uint, itemType, name string) (string, error) {
+	status, err := revisionRepo.GetApplicationStatus(applicationID)
+	if err != nil {
+		return "", err
+	}
+	if status == "draft" {
+		return "", nil
+	}
+	if status != "needs_revision" {
+		if itemType == "document" {
+			return "", nil
+		}
+		return "Cannot modify application that is not in draft status", nil
+	}
+
+	revision, err := revisionRepo.GetOpenRevision(applicationID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "Application has no open revision request", nil
+		}
+		return "", err
+	}
+	if revision.IsOverdue() {
+		if err := revisionRepo.MarkExpired(revision); err != nil {
+			return "", err
+		}
+		return "The resubmission deadline has passed", nil
+	}
+
+	switch itemType {
+	case "section":
+		if !revision.AllowsSection(name) {
+			return fmt.Sprintf("Section %s was not flagged for revision", name), nil
+		}
+	case "document":
+		if !revision.AllowsDocument(name) {
+			return fmt.Sprintf("Document %s was not flagged for revision", name), nil
+		}
+	default:
+		return "Only the sections flagged for revision can be edited", nil
+	}
+	return "", nil
+}
+
+func isApplicationSection(name string) bool {
+	for _, section := range models.ApplicationSections {
+		if section == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+)
+
+type ApplicationSearchHandler struct {
+	cfg        *config.Config
+	searchRepo *repository.SearchRepository
+	jobRepo    *repository.JobRepository
+}
+
+func NewApplicationSearchHandler(cfg *config.Config) *ApplicationSearchHandler {
+	return &ApplicationSearchHandler{
+		cfg:        cfg,
+		searchRepo: repository.NewSearchRepository(),
+		jobRepo:    repository.NewJobRepository(),
+	}
+}
+
+// SearchApplications searches applications by free text
+// @Summary Search applications
+// @Description Full-text search over student names, student and national IDs, faculty, addresses, schools, family members and activities. Thai text is matched without needing word breaks, and a national ID can be found by a fragment of 4 or more digits. Results are ranked with the matching parts highlighted. Admins and officers see all applications; other staff only see applicants from their faculty. Drafts are excluded unless status=draft
+// @Tags Applications
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search text"
+// @Param status query string false "Filter by application status"
+// @Param scholarship_id query int false "Filter by scholarship"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(20)
+// @Success 200 {object} object{success=bool,data=[]models.ApplicationSearchResult,pagination=object}
+// @Failure 400 {object} object{error=string}
+// @Router /api/v1/applications/search [get]
+func (h *ApplicationSearchHandler) SearchApplications(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if len([]rune(query)) < 2 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Search text must be at least 2 characters",
+		})
+	}
+	tsQuery := services.BuildSearchQuery(query)
+	if tsQuery == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Search text has no letters or digits",
+		})
+	}
+
+	limit := c.QueryInt("limit", 20)
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	params := models.ApplicationSearchParams{
+		Query:  query,
+		Status: c.Query("status"),
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	}
+	if id := c.QueryInt("scholarship_id", 0); id > 0 {
+		scholarshipID := uint(id)
+		params.ScholarshipID = &scholarshipID
+	}
+
+	roles, _ := c.Locals("roles").([]string)
+	scope := services.SearchScopeFor(roles, nil)
+	if !scope.All {
+		faculties, err := h.searchRepo.GetStaffFaculties(userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check search permissions",
+			})
+		}
+		scope = services.SearchScopeFor(roles, faculties)
+	}
+
+	results, total, err := h.searchRepo.Search(tsQuery, params, scope)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to search applications",
+		})
+	}
+	for i := range results {
+		results[i].Highlights = services.HighlightMatches(results[i].Fields, query)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    results,
+		"pagination": fiber.Map{
+			"page":       page,
+			"limit":      limit,
+			"total":      total,
+			"totalPages": (total + limit - 1) / limit,
+		},
+	})
+}
+
+// Reindex queues a rebuild of the application search index
+// @Summary Rebuild application search index
+// @Description Queue a job that reindexes every application. The index is also refreshed on a schedule for applications changed since they were indexed (Admin/Officer only)
+// @Tags Applications
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} object{success=bool,message=string,data=models.JobQueue}
+// @Failure 409 {object} object{error=string}
+// @Router /api/v1/admin/search/reindex [post]
+func (h *ApplicationSearchHandler) Reindex(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	active, err := h.jobRepo.HasActive(models.JobTypeSearchIndex)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check running index jobs",
+		})
+	}
+	if active {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "An index job is already queued or running",
+		})
+	}
+
+	job, err := h.jobRepo.Enqueue(models.JobTypeSearchIndex, fiber.Map{"full": true}, &userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to queue index rebuild",
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"message": "Search index rebuild queued",
+		"data":    job,
+	})
+}
@@ -16,6 +16,7 @@ type ApplicationSectionHandler struct {
 	cfg                    *config.Config
 	applicationDetailsRepo *repository.ApplicationDetailsRepository
 	applicationRepo        *repository.ApplicationRepository
+	revisionRepo           *repository.RevisionRepository
 }
 
 func NewApplicationSectionHandler(cfg *config.Config) *ApplicationSectionHandler {
@@ -23,6 +24,7 @@ func NewApplicationSectionHandler(cfg *config.Config) *ApplicationSectionHandler
 		cfg:                    cfg,
 		applicationDetailsRepo: repository.NewApplicationDetailsRepository(),
 		applicationRepo:        repository.NewApplicationRepository(),
+		revisionRepo:           repository.NewRevisionRepository(),
 	}
 }
 
@@ -36,10 +38,12 @@ func NewApplicationSectionHandler(cfg *config.Config) *ApplicationSectionHandler
 // @Param id path int true "Application ID"
 // @Param section_name path string true "Section name" Enums(personal_info, address_info, education_history, family_info, financial_info, activities_skills)
 // @Param data body object true "Section data"
+// @Param If-Match header string false "Version the edit is based on"
 // @Success 200 {object} object{success=bool,message=string,data=object}
 // @Failure 400 {object} object{error=string}
 // @Failure 403 {object} object{error=string}
 // @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string,section=string,current_version=int,current=object}
 // @Router /api/v1/applications/{id}/sections/{section_name} [post]
 func (h *ApplicationSectionHandler) SaveSection(c *fiber.Ctx) error {
 	// Get user_id from context
@@ -89,34 +93,43 @@ func (h *ApplicationSectionHandler) SaveSection(c *fiber.Ctx) error {
 		})
 	}
 
-	// Verify application is still in draft status
-	application, err := h.applicationRepo.GetByID(uint(applicationID))
+	// Drafts are fully editable; applications sent back for revision only
+	// in the sections the officer flagged
+	reason, err := editLockReason(h.revisionRepo, uint(applicationID), "section", sectionName)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch application",
 		})
 	}
 
-	if application.ApplicationStatus != "draft" {
+	if reason != "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Cannot modify application that is not in draft status",
+			"error": reason,
+		})
+	}
+
+	// Reject saves based on an outdated version of the section
+	expectedVersion, err := ifMatchVersion(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
 
 	// Route to appropriate save method based on section name
 	switch sectionName {
 	case "personal_info":
-		return h.savePersonalInfo(c, applicationID)
+		return h.savePersonalInfo(c, applicationID, expectedVersion)
 	case "address_info":
-		return h.saveAddressInfo(c, applicationID)
+		return h.saveAddressInfo(c, applicationID, expectedVersion)
 	case "education_history":
-		return h.saveEducationHistory(c, applicationID)
+		return h.saveEducationHistory(c, applicationID, expectedVersion)
 	case "family_info":
-		return h.saveFamilyInfo(c, applicationID)
+		return h.saveFamilyInfo(c, applicationID, expectedVersion)
 	case "financial_info":
-		return h.saveFinancialInfo(c, applicationID)
+		return h.saveFinancialInfo(c, applicationID, expectedVersion)
 	case "activities_skills":
-		return h.saveActivitiesSkills(c, applicationID)
+		return h.saveActivitiesSkills(c, applicationID, expectedVersion)
 	default:
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid section name",
@@ -147,7 +160,7 @@ type PersonalInfoDTO struct {
 	Gender           *string `json:"gender,omitempty"`
 }
 
-func (h *ApplicationSectionHandler) savePersonalInfo(c *fiber.Ctx, applicationID int) error {
+func (h *ApplicationSectionHandler) savePersonalInfo(c *fiber.Ctx, applicationID int, expectedVersion *int) error {
 	var dto PersonalInfoDTO
 	if err := c.BodyParser(&dto); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -206,18 +219,18 @@ func (h *ApplicationSectionHandler) savePersonalInfo(c *fiber.Ctx, applicationID
 		info.AdmissionDetails = sql.NullString{String: *dto.AdmissionDetails, Valid: true}
 	}
 
-	savedInfo, err := h.applicationDetailsRepo.SavePersonalInfo(&info)
+	savedInfo, version, err := h.applicationDetailsRepo.SavePersonalInfo(&info, expectedVersion)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save personal information",
-		})
+		return formSaveError(c, h.applicationDetailsRepo, uint(applicationID), err, "Failed to save personal information")
 	}
+	setVersionETag(c, version)
 
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Section saved successfully",
 		"data": fiber.Map{
 			"section":  "personal_info",
+			"version":  version,
 			"saved_at": savedInfo.UpdatedAt,
 		},
 	})
@@ -241,7 +254,7 @@ type AddressDTO struct {
 	MapImageURL   *string  `json:"map_image_url,omitempty"`
 }
 
-func (h *ApplicationSectionHandler) saveAddressInfo(c *fiber.Ctx, applicationID int) error {
+func (h *ApplicationSectionHandler) saveAddressInfo(c *fiber.Ctx, applicationID int, expectedVersion *int) error {
 	var addressDTOs []AddressDTO
 	if err := c.BodyParser(&addressDTOs); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -298,24 +311,24 @@ func (h *ApplicationSectionHandler) saveAddressInfo(c *fiber.Ctx, applicationID
 		}
 	}
 
-	savedAddresses, err := h.applicationDetailsRepo.SaveAddresses(uint(applicationID), addresses)
+	savedAddresses, version, err := h.applicationDetailsRepo.SaveAddresses(uint(applicationID), addresses, expectedVersion)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save address information",
-		})
+		return formSaveError(c, h.applicationDetailsRepo, uint(applicationID), err, "Failed to save address information")
 	}
+	setVersionETag(c, version)
 
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Section saved successfully",
 		"data": fiber.Map{
 			"section":  "address_info",
+			"version":  version,
 			"saved_at": savedAddresses[0].UpdatedAt,
 		},
 	})
 }
 
-func (h *ApplicationSectionHandler) saveEducationHistory(c *fiber.Ctx, applicationID int) error {
+func (h *ApplicationSectionHandler) saveEducationHistory(c *fiber.Ctx, applicationID int, expectedVersion *int) error {
 	var education []models.ApplicationEducationHistory
 	if err := c.BodyParser(&education); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -327,24 +340,24 @@ func (h *ApplicationSectionHandler) saveEducationHistory(c *fiber.Ctx, applicati
 		education[i].ApplicationID = applicationID
 	}
 
-	savedEducation, err := h.applicationDetailsRepo.SaveEducation(uint(applicationID), education)
+	savedEducation, version, err := h.applicationDetailsRepo.SaveEducation(uint(applicationID), education, expectedVersion)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save education history",
-		})
+		return formSaveError(c, h.applicationDetailsRepo, uint(applicationID), err, "Failed to save education history")
 	}
+	setVersionETag(c, version)
 
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Section saved successfully",
 		"data": fiber.Map{
 			"section":  "education_history",
+			"version":  version,
 			"saved_at": savedEducation[0].UpdatedAt,
 		},
 	})
 }
 
-func (h *ApplicationSectionHandler) saveFamilyInfo(c *fiber.Ctx, applicationID int) error {
+func (h *ApplicationSectionHandler) saveFamilyInfo(c *fiber.Ctx, applicationID int, expectedVersion *int) error {
 	var req struct {
 		Members         []models.ApplicationFamilyMember   `json:"members"`
 		Guardians       []models.ApplicationGuardian       `json:"guardians"`
@@ -371,24 +384,24 @@ func (h *ApplicationSectionHandler) saveFamilyInfo(c *fiber.Ctx, applicationID i
 		req.LivingSituation.ApplicationID = applicationID
 	}
 
-	result, err := h.applicationDetailsRepo.SaveFamily(uint(applicationID), req.Members, req.Guardians, req.Siblings, req.LivingSituation)
+	result, version, err := h.applicationDetailsRepo.SaveFamily(uint(applicationID), req.Members, req.Guardians, req.Siblings, req.LivingSituation, expectedVersion)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save family information",
-		})
+		return formSaveError(c, h.applicationDetailsRepo, uint(applicationID), err, "Failed to save family information")
 	}
+	setVersionETag(c, version)
 
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Section saved successfully",
 		"data": fiber.Map{
 			"section":  "family_info",
+			"version":  version,
 			"saved_at": result["updated_at"],
 		},
 	})
 }
 
-func (h *ApplicationSectionHandler) saveFinancialInfo(c *fiber.Ctx, applicationID int) error {
+func (h *ApplicationSectionHandler) saveFinancialInfo(c *fiber.Ctx, applicationID int, expectedVersion *int) error {
 	var req struct {
 		FinancialInfo      *models.ApplicationFinancialInfo       `json:"financial_info"`
 		Assets             []models.ApplicationAsset              `json:"assets"`
@@ -419,31 +432,32 @@ func (h *ApplicationSectionHandler) saveFinancialInfo(c *fiber.Ctx, applicationI
 		req.FundingNeeds.ApplicationID = applicationID
 	}
 
-	result, err := h.applicationDetailsRepo.SaveFinancial(
+	result, version, err := h.applicationDetailsRepo.SaveFinancial(
 		uint(applicationID),
 		req.FinancialInfo,
 		req.Assets,
 		req.ScholarshipHistory,
 		req.HealthInfo,
 		req.FundingNeeds,
+		expectedVersion,
 	)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save financial information",
-		})
+		return formSaveError(c, h.applicationDetailsRepo, uint(applicationID), err, "Failed to save financial information")
 	}
+	setVersionETag(c, version)
 
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Section saved successfully",
 		"data": fiber.Map{
 			"section":  "financial_info",
+			"version":  version,
 			"saved_at": result["updated_at"],
 		},
 	})
 }
 
-func (h *ApplicationSectionHandler) saveActivitiesSkills(c *fiber.Ctx, applicationID int) error {
+func (h *ApplicationSectionHandler) saveActivitiesSkills(c *fiber.Ctx, applicationID int, expectedVersion *int) error {
 	var req struct {
 		Activities []models.ApplicationActivity  `json:"activities"`
 		References []models.ApplicationReference `json:"references"`
@@ -462,18 +476,18 @@ func (h *ApplicationSectionHandler) saveActivitiesSkills(c *fiber.Ctx, applicati
 		req.References[i].ApplicationID = applicationID
 	}
 
-	result, err := h.applicationDetailsRepo.SaveActivities(uint(applicationID), req.Activities, req.References)
+	result, version, err := h.applicationDetailsRepo.SaveActivities(uint(applicationID), req.Activities, req.References, expectedVersion)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save activities",
-		})
+		return formSaveError(c, h.applicationDetailsRepo, uint(applicationID), err, "Failed to save activities")
 	}
+	setVersionETag(c, version)
 
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Section saved successfully",
 		"data": fiber.Map{
 			"section":  "activities_skills",
+			"version":  version,
 			"saved_at": result["updated_at"],
 		},
 	})
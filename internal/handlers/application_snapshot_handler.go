@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"database/sql"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+	"scholarship-system/internal/storage"
+)
+
+type ApplicationSnapshotHandler struct {
+	cfg                    *config.Config
+	applicationRepo        *repository.ApplicationRepository
+	applicationDetailsRepo *repository.ApplicationDetailsRepository
+	snapshotRepo           *repository.SnapshotRepository
+}
+
+func NewApplicationSnapshotHandler(cfg *config.Config) *ApplicationSnapshotHandler {
+	return &ApplicationSnapshotHandler{
+		cfg:                    cfg,
+		applicationRepo:        repository.NewApplicationRepository(),
+		applicationDetailsRepo: repository.NewApplicationDetailsRepository(),
+		snapshotRepo:           repository.NewSnapshotRepository(),
+	}
+}
+
+// GetSubmittedForm returns the application form reviewers should score
+// @Summary Get submitted application form
+// @Description Get the form as it was submitted (latest snapshot by default). Use version to pick an older snapshot or source=live for the current data (Admin/Officer only)
+// @Tags Application Snapshots
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Param version query int false "Snapshot version"
+// @Param source query string false "snapshot (default) or live"
+// @Success 200 {object} object{success=bool,data=object}
+// @Failure 400 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/applications/{id}/form [get]
+func (h *ApplicationSnapshotHandler) GetSubmittedForm(c *fiber.Ctx) error {
+	applicationID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid application ID",
+		})
+	}
+
+	var snapshot *models.ApplicationSnapshot
+	if c.Query("source") != "live" {
+		if version := c.QueryInt("version", 0); version > 0 {
+			snapshot, err = h.snapshotRepo.GetVersion(uint(applicationID), version)
+		} else {
+			snapshot, err = h.snapshotRepo.GetLatest(uint(applicationID))
+		}
+		if err != nil && err != sql.ErrNoRows {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to retrieve application snapshot",
+			})
+		}
+		if err == sql.ErrNoRows && c.QueryInt("version", 0) > 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Snapshot version not found",
+			})
+		}
+	}
+
+	if snapshot != nil {
+		return c.JSON(fiber.Map{
+			"success": true,
+			"data": fiber.Map{
+				"source":     "snapshot",
+				"version":    snapshot.Version,
+				"reason":     snapshot.Reason,
+				"checksum":   snapshot.Checksum,
+				"verified":   services.VerifySnapshot(snapshot),
+				"created_at": snapshot.CreatedAt,
+				"form":       snapshot.FormData,
+				"documents":  snapshot.Documents,
+			},
+		})
+	}
+
+	// Not submitted yet (or submitted before snapshots existed): show live data
+	live, err := h.liveSnapshot(uint(applicationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Application not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve application details",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"source":    "live",
+			"form":      live.FormData,
+			"documents": live.Documents,
+		},
+	})
+}
+
+// ListSnapshots lists the submission snapshots of an application
+// @Summary List application snapshots
+// @Description List the submission snapshots of an application with their checksums (Admin/Officer only)
+// @Tags Application Snapshots
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Success 200 {object} object{success=bool,data=[]models.ApplicationSnapshot}
+// @Failure 400 {object} object{error=string}
+// @Router /api/v1/admin/applications/{id}/snapshots [get]
+func (h *ApplicationSnapshotHandler) ListSnapshots(c *fiber.Ctx) error {
+	applicationID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid application ID",
+		})
+	}
+
+	snapshots, err := h.snapshotRepo.List(uint(applicationID))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve application snapshots",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    snapshots,
+	})
+}
+
+// DiffSnapshots compares two snapshots, or a snapshot with the live form
+// @Summary Diff application snapshots
+// @Description Compare snapshot version "from" (default 1) with version "to" (default latest). Use to=live to compare against the current data (Admin/Officer only)
+// @Tags Application Snapshots
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Param from query int false "Base snapshot version"
+// @Param to query string false "Snapshot version or live"
+// @Success 200 {object} object{success=bool,data=models.SnapshotDiff}
+// @Failure 400 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/applications/{id}/snapshots/diff [get]
+func (h *ApplicationSnapshotHandler) DiffSnapshots(c *fiber.Ctx) error {
+	applicationID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid application ID",
+		})
+	}
+
+	from, err := h.snapshotRepo.GetVersion(uint(applicationID), c.QueryInt("from", 1))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Snapshot version not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve application snapshot",
+		})
+	}
+
+	var to *models.ApplicationSnapshot
+	switch toParam := c.Query("to"); toParam {
+	case "live":
+		to, err = h.liveSnapshot(uint(applicationID))
+	case "":
+		to, err = h.snapshotRepo.GetLatest(uint(applicationID))
+	default:
+		version, convErr := strconv.Atoi(toParam)
+		if convErr != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid snapshot version",
+			})
+		}
+		to, err = h.snapshotRepo.GetVersion(uint(applicationID), version)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Snapshot version not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve application snapshot",
+		})
+	}
+
+	diff, err := services.DiffSnapshots(from, to)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to compare snapshots",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    diff,
+	})
+}
+
+// liveSnapshot builds an unsaved snapshot of the current form data. Its
+// version is 0.
+func (h *ApplicationSnapshotHandler) liveSnapshot(applicationID uint) (*models.ApplicationSnapshot, error) {
+	return buildApplicationSnapshot(h.applicationRepo, h.applicationDetailsRepo, applicationID)
+}
+
+// buildApplicationSnapshot captures the complete form and documents of an
+// application as they are now
+func buildApplicationSnapshot(applicationRepo *repository.ApplicationRepository,
+	applicationDetailsRepo *repository.ApplicationDetailsRepository, applicationID uint) (*models.ApplicationSnapshot, error) {
+	form, err := applicationDetailsRepo.GetCompleteForm(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	documents, err := applicationRepo.GetDocuments(applicationID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	snapshot, err := services.BuildSnapshot(form, documents, storage.HashDocument)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.ApplicationID = applicationID
+	return snapshot, nil
+}
+
+// saveResubmissionSnapshot stores a new snapshot version after a revised
+// application has been sent back to the officers
+func saveResubmissionSnapshot(applicationRepo *repository.ApplicationRepository,
+	applicationDetailsRepo *repository.ApplicationDetailsRepository, applicationID uint, userID uuid.UUID) (*models.ApplicationSnapshot, error) {
+	snapshot, err := buildApplicationSnapshot(applicationRepo, applicationDetailsRepo, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Reason = models.SnapshotReasonResubmit
+	snapshot.CreatedBy = &userID
+	if err := repository.NewSnapshotRepository().Create(snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// snapshotSummary is the part of a snapshot returned to the student on submit
+func snapshotSummary(snapshot *models.ApplicationSnapshot) fiber.Map {
+	return fiber.Map{
+		"version":        snapshot.Version,
+		"checksum":       snapshot.Checksum,
+		"document_count": len(snapshot.Documents),
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"scholarship-system/internal/database"
 	"scholarship-system/internal/models"
 	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+	"scholarship-system/internal/storage"
 )
 
 type ApplicationSubmitHandler struct {
@@ -19,6 +21,8 @@ type ApplicationSubmitHandler struct {
 	applicationRepo        *repository.ApplicationRepository
 	applicationDetailsRepo *repository.ApplicationDetailsRepository
 	userRepo               *repository.UserRepository
+	snapshotRepo           *repository.SnapshotRepository
+	requirementRepo        *repository.DocumentRequirementRepository
 }
 
 func NewApplicationSubmitHandler(cfg *config.Config) *ApplicationSubmitHandler {
@@ -27,6 +31,8 @@ func NewApplicationSubmitHandler(cfg *config.Config) *ApplicationSubmitHandler {
 		applicationRepo:        repository.NewApplicationRepository(),
 		applicationDetailsRepo: repository.NewApplicationDetailsRepository(),
 		userRepo:               repository.NewUserRepository(),
+		snapshotRepo:           repository.NewSnapshotRepository(),
+		requirementRepo:        repository.NewDocumentRequirementRepository(),
 	}
 }
 
@@ -38,7 +44,7 @@ type SubmitApplicationRequest struct {
 
 // SubmitApplication submits the draft application for review
 // @Summary Submit application
-// @Description Submit the draft application for review
+// @Description Submit the draft application for review. Submission is blocked until the documents meet every requirement of the scholarship (see the document checklist)
 // @Tags Applications
 // @Accept json
 // @Produce json
@@ -150,11 +156,17 @@ func (h *ApplicationSubmitHandler) SubmitApplication(c *fiber.Ctx) error {
 		})
 	}
 
-	docValidationErrors := h.validateDocuments(documents)
-	if len(docValidationErrors) > 0 {
+	checklist, err := documentChecklist(h.requirementRepo, uint(applicationID), form, documents)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to validate application documents",
+		})
+	}
+	if !checklist.Complete {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":  "Please upload all required documents before submitting",
-			"errors": docValidationErrors,
+			"error":     "Please upload all required documents before submitting",
+			"errors":    checklist.Problems,
+			"checklist": checklist,
 		})
 	}
 
@@ -166,12 +178,32 @@ func (h *ApplicationSubmitHandler) SubmitApplication(c *fiber.Ctx) error {
 	application.ApplicationStatus = "submitted"
 	application.SubmittedAt = &now
 	application.UpdatedAt = now
+	if form.Application != nil {
+		form.Application.ApplicationStatus = application.ApplicationStatus
+		form.Application.SubmittedAt = application.SubmittedAt
+	}
 
-	if err := h.applicationRepo.Update(application); err != nil {
+	// Snapshot the form and documents exactly as submitted
+	snapshot, err := services.BuildSnapshot(form, documents, storage.HashDocument)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to submit application",
 		})
 	}
+	snapshot.ApplicationID = uint(applicationID)
+	snapshot.Reason = models.SnapshotReasonSubmit
+	snapshot.CreatedBy = &userID
+
+	if err := h.snapshotRepo.SubmitWithSnapshot(snapshot); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to submit application",
+		})
+	}
+
+	// Assess financial need and compute the priority score for reviewers
+	if _, _, err := refreshApplicationScores(uint(applicationID)); err != nil {
+		fmt.Printf("Warning: Failed to score application %d: %v\n", applicationID, err)
+	}
 
 	// Create workflow record (step 1: submission)
 	if err := h.createWorkflowRecord(uint(applicationID), "submitted"); err != nil {
@@ -190,6 +222,7 @@ func (h *ApplicationSubmitHandler) SubmitApplication(c *fiber.Ctx) error {
 			"application_status": application.ApplicationStatus,
 			"submitted_at":       application.SubmittedAt,
 			"reference_number":   referenceNumber,
+			"snapshot":           snapshotSummary(snapshot),
 		},
 	})
 }
@@ -241,29 +274,6 @@ func (h *ApplicationSubmitHandler) validateApplication(form *models.CompleteAppl
 	return errors
 }
 
-// validateDocuments checks if all required documents are uploaded
-func (h *ApplicationSubmitHandler) validateDocuments(documents []models.ApplicationDocument) []string {
-	errors := []string{}
-	requiredDocs := map[string]bool{
-		"id_card":    false,
-		"transcript": false,
-	}
-
-	for _, doc := range documents {
-		if _, required := requiredDocs[doc.DocumentType]; required {
-			requiredDocs[doc.DocumentType] = true
-		}
-	}
-
-	for docType, uploaded := range requiredDocs {
-		if !uploaded {
-			errors = append(errors, fmt.Sprintf("Required document '%s' is missing", docType))
-		}
-	}
-
-	return errors
-}
-
 // generateReferenceNumber generates a unique reference number for the application
 func (h *ApplicationSubmitHandler) generateReferenceNumber(application *models.ScholarshipApplication) string {
 	year := time.Now().Year()
@@ -0,0 +1,524 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/jobs"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+)
+
+type ApplicationViewHandler struct {
+	cfg      *config.Config
+	viewRepo *repository.ApplicationViewRepository
+	jobRepo  *repository.JobRepository
+}
+
+func NewApplicationViewHandler(cfg *config.Config) *ApplicationViewHandler {
+	return &ApplicationViewHandler{
+		cfg:      cfg,
+		viewRepo: repository.NewApplicationViewRepository(),
+		jobRepo:  repository.NewJobRepository(),
+	}
+}
+
+// ListViews lists the current user's saved views
+// @Summary List saved application views
+// @Description List the filters the current officer saved for the application list, the default view first (Admin/Officer only)
+// @Tags Application Views
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} object{success=bool,data=[]models.ApplicationView}
+// @Router /api/v1/admin/application-views [get]
+func (h *ApplicationViewHandler) ListViews(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	views, err := h.viewRepo.ListViews(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve views",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    views,
+	})
+}
+
+// CreateView saves a filter as a view
+// @Summary Save application view
+// @Description Save a filter and sort order of the application list under a name for the current officer (Admin/Officer only)
+// @Tags Application Views
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ApplicationViewRequest true "View"
+// @Success 201 {object} object{success=bool,data=models.ApplicationView}
+// @Failure 400 {object} object{error=string}
+// @Router /api/v1/admin/application-views [post]
+func (h *ApplicationViewHandler) CreateView(c *fiber.Ctx) error {
+	return h.saveView(c, 0)
+}
+
+// UpdateView replaces a saved view
+// @Summary Update application view
+// @Description Replace the name, filter and sort order of one of the current officer's views (Admin/Officer only)
+// @Tags Application Views
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "View ID"
+// @Param request body models.ApplicationViewRequest true "View"
+// @Success 200 {object} object{success=bool,data=models.ApplicationView}
+// @Failure 400 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/application-views/{id} [put]
+func (h *ApplicationViewHandler) UpdateView(c *fiber.Ctx) error {
+	viewID, err := c.ParamsInt("id")
+	if err != nil || viewID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid view ID",
+		})
+	}
+	return h.saveView(c, uint(viewID))
+}
+
+func (h *ApplicationViewHandler) saveView(c *fiber.Ctx, viewID uint) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	var req models.ApplicationViewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	req.ViewName = strings.TrimSpace(req.ViewName)
+	if req.ViewName == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "view_name is required",
+		})
+	}
+	if err := services.ValidateApplicationFilter(&req.Filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err := services.ValidateApplicationSort(req.SortBy); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	view := &models.ApplicationView{
+		ViewID:      viewID,
+		UserID:      userID,
+		ViewName:    req.ViewName,
+		Description: req.Description,
+		Filter:      req.Filter,
+		SortBy:      req.SortBy,
+		SortDesc:    req.SortDesc == nil || *req.SortDesc,
+		IsDefault:   req.IsDefault,
+	}
+	if view.SortBy == "" {
+		view.SortBy = "submitted_at"
+	}
+
+	if err := h.viewRepo.SaveView(view); err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "View not found",
+			})
+		}
+		if strings.Contains(err.Error(), "already exists") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save view",
+		})
+	}
+
+	status := fiber.StatusOK
+	if viewID == 0 {
+		status = fiber.StatusCreated
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"success": true,
+		"data":    view,
+	})
+}
+
+// DeleteView deletes a saved view
+// @Summary Delete application view
+// @Description Delete one of the current officer's saved views (Admin/Officer only)
+// @Tags Application Views
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "View ID"
+// @Success 200 {object} object{success=bool,message=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/application-views/{id} [delete]
+func (h *ApplicationViewHandler) DeleteView(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+	viewID, err := c.ParamsInt("id")
+	if err != nil || viewID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid view ID",
+		})
+	}
+
+	if err := h.viewRepo.DeleteView(uint(viewID), userID); err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "View not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete view",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "View deleted",
+	})
+}
+
+// QueryApplications lists applications matching a filter or a saved view
+// @Summary Filter applications
+// @Description List applications by status, scholarship, faculty, year level, GPA and income range, document verification, review stage, reviewer, red flags and submission date, using a filter or a saved view. Drafts are left out unless asked for by status (Admin/Officer only)
+// @Tags Application Views
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ApplicationQueryRequest true "Filter or view, sort and page"
+// @Success 200 {object} object{success=bool,data=[]models.ApplicationListItem,pagination=object}
+// @Failure 400 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/applications/query [post]
+func (h *ApplicationViewHandler) QueryApplications(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	var req models.ApplicationQueryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	filter := models.ApplicationFilter{}
+	sortBy, sortDesc := "submitted_at", true
+	if req.ViewID != nil {
+		view, err := h.viewRepo.GetView(*req.ViewID, userID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": "View not found",
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to load view",
+			})
+		}
+		filter, sortBy, sortDesc = view.Filter, view.SortBy, view.SortDesc
+	} else if req.Filter != nil {
+		filter = *req.Filter
+	}
+	if req.SortBy != "" {
+		sortBy = req.SortBy
+	}
+	if req.SortDesc != nil {
+		sortDesc = *req.SortDesc
+	}
+
+	if err := services.ValidateApplicationFilter(&filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err := services.ValidateApplicationSort(sortBy); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+
+	items, total, err := h.viewRepo.QueryApplications(filter, sortBy, sortDesc, limit, (page-1)*limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch applications",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    items,
+		"filter":  filter,
+		"pagination": fiber.Map{
+			"page":       page,
+			"limit":      limit,
+			"total":      total,
+			"totalPages": (total + limit - 1) / limit,
+		},
+	})
+}
+
+// StartBulkAction queues an action over a selection of applications
+// @Summary Run bulk action on applications
+// @Description Queue a background job that assigns a reviewer, changes status (following the allowed status transitions), notifies the students or exports a CSV for the selected applications. The selection is application_ids, or the applications matching view_id or filter at the time of the request. Progress is reported on the job (Admin/Officer only)
+// @Tags Application Views
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.BulkActionRequest true "Action and selection"
+// @Success 202 {object} object{success=bool,message=string,data=models.JobQueue}
+// @Failure 400 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/applications/bulk [post]
+func (h *ApplicationViewHandler) StartBulkAction(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	var req models.BulkActionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if err := services.ValidateBulkAction(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if req.Action == models.BulkAssignReviewer {
+		staff, err := h.viewRepo.IsStaff(*req.ReviewerID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check reviewer",
+			})
+		}
+		if !staff {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Reviewer must be an active officer, interviewer or committee member",
+			})
+		}
+	}
+
+	// Resolve the selection now so the job works on what the officer saw
+	ids := req.ApplicationIDs
+	if len(ids) == 0 {
+		filter := models.ApplicationFilter{}
+		if req.ViewID != nil {
+			view, err := h.viewRepo.GetView(*req.ViewID, userID)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+						"error": "View not found",
+					})
+				}
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to load view",
+				})
+			}
+			filter = view.Filter
+		} else {
+			filter = *req.Filter
+		}
+
+		var err error
+		ids, err = h.viewRepo.ListApplicationIDs(filter)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to select applications",
+			})
+		}
+	}
+	if err := services.CheckBulkSelection(ids); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	job, err := h.jobRepo.Enqueue(models.JobTypeApplicationBulk, models.BulkActionJob{
+		Action:         req.Action,
+		ApplicationIDs: ids,
+		ReviewerID:     req.ReviewerID,
+		ReviewStage:    req.ReviewStage,
+		Status:         req.Status,
+		Notes:          req.Notes,
+		Title:          strings.TrimSpace(req.Title),
+		Message:        strings.TrimSpace(req.Message),
+	}, &userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to queue bulk action",
+		})
+	}
+	job.ProgressTotal = len(ids)
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"message": "Bulk action queued",
+		"data":    job,
+	})
+}
+
+// ListBulkJobs lists the current user's bulk actions
+// @Summary List bulk actions
+// @Description List the latest bulk actions queued by the current officer with their progress and results (Admin/Officer only)
+// @Tags Application Views
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} object{success=bool,data=[]models.JobQueue}
+// @Router /api/v1/admin/bulk-jobs [get]
+func (h *ApplicationViewHandler) ListBulkJobs(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	jobs, err := h.jobRepo.ListByCreator(models.JobTypeApplicationBulk, userID, 50)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve bulk actions",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    jobs,
+	})
+}
+
+// GetBulkJob returns the progress of a bulk action
+// @Summary Get bulk action progress
+// @Description Get the status, progress and per-application errors of a bulk action (Admin/Officer only)
+// @Tags Application Views
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID"
+// @Success 200 {object} object{success=bool,data=models.JobQueue}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/bulk-jobs/{id} [get]
+func (h *ApplicationViewHandler) GetBulkJob(c *fiber.Ctx) error {
+	job, err := h.loadBulkJob(c)
+	if job == nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    job,
+	})
+}
+
+// DownloadExport downloads the CSV of an export bulk action
+// @Summary Download bulk export
+// @Description Download the CSV written by a completed export bulk action (Admin/Officer only)
+// @Tags Application Views
+// @Produce text/csv
+// @Security BearerAuth
+// @Param id path string true "Job ID"
+// @Success 200 {file} file
+// @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
+// @Router /api/v1/admin/bulk-jobs/{id}/export [get]
+func (h *ApplicationViewHandler) DownloadExport(c *fiber.Ctx) error {
+	job, err := h.loadBulkJob(c)
+	if job == nil {
+		return err
+	}
+
+	var result models.BulkActionResult
+	if job.Status != "completed" || json.Unmarshal(job.Result, &result) != nil || result.ExportFile == "" {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Export is not ready",
+		})
+	}
+
+	return sendStoredFile(c, result.ExportBackend, jobs.ExportKey(result.ExportFile), result.ExportFile, "text/csv; charset=utf-8")
+}
+
+// loadBulkJob returns the bulk job of the :id param if the user queued it or
+// is an admin. Otherwise it writes the error response itself and returns a
+// nil job.
+func (h *ApplicationViewHandler) loadBulkJob(c *fiber.Ctx) (*models.JobQueue, error) {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return nil, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid job ID",
+		})
+	}
+
+	job, err := h.jobRepo.GetByID(jobID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve bulk action",
+		})
+	}
+	isAdmin := false
+	roles, _ := c.Locals("roles").([]string)
+	for _, role := range roles {
+		if role == "admin" {
+			isAdmin = true
+			break
+		}
+	}
+	if err == sql.ErrNoRows || job.JobType != models.JobTypeApplicationBulk ||
+		((job.CreatedBy == nil || *job.CreatedBy != userID) && !isAdmin) {
+		return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Bulk action not found",
+		})
+	}
+	return job, nil
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+)
+
+type ApplicationWithdrawalHandler struct {
+	cfg             *config.Config
+	applicationRepo *repository.ApplicationRepository
+	withdrawalRepo  *repository.WithdrawalRepository
+}
+
+func NewApplicationWithdrawalHandler(cfg *config.Config) *ApplicationWithdrawalHandler {
+	return &ApplicationWithdrawalHandler{
+		cfg:             cfg,
+		applicationRepo: repository.NewApplicationRepository(),
+		withdrawalRepo:  repository.NewWithdrawalRepository(),
+	}
+}
+
+// WithdrawApplication withdraws an application before a final decision
+// @Summary Withdraw application
+// @Description Withdraw an application with a reason. Not allowed once the ranking is final or a decision has been made; awarded students should decline instead (Student only)
+// @Tags Applications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Param request body models.WithdrawalRequest true "Withdrawal reason"
+// @Success 200 {object} object{success=bool,message=string,data=models.ApplicationWithdrawal}
+// @Failure 400 {object} object{error=string}
+// @Failure 403 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
+// @Router /api/v1/applications/{id}/withdraw [post]
+func (h *ApplicationWithdrawalHandler) WithdrawApplication(c *fiber.Ctx) error {
+	return h.close(c, models.WithdrawalTypeWithdraw)
+}
+
+// DeclineAward declines an awarded scholarship
+// @Summary Decline scholarship award
+// @Description Decline an approved award with a reason within the decline window. Releases the quota and budget back to the scholarship (Student only)
+// @Tags Applications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Param request body models.WithdrawalRequest true "Decline reason"
+// @Success 200 {object} object{success=bool,message=string,data=models.ApplicationWithdrawal}
+// @Failure 400 {object} object{error=string}
+// @Failure 403 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
+// @Router /api/v1/applications/{id}/decline [post]
+func (h *ApplicationWithdrawalHandler) DeclineAward(c *fiber.Ctx) error {
+	return h.close(c, models.WithdrawalTypeDecline)
+}
+
+func (h *ApplicationWithdrawalHandler) close(c *fiber.Ctx, withdrawalType string) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	applicationID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid application ID",
+		})
+	}
+
+	var req models.WithdrawalRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Reason is required",
+		})
+	}
+
+	if err := h.verifyApplicationOwnership(uint(applicationID), userID); err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Application not found",
+			})
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You can only withdraw your own applications",
+		})
+	}
+
+	application, err := h.applicationRepo.GetByID(uint(applicationID))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch application",
+		})
+	}
+
+	if reason, err := h.closeBlockedReason(application, withdrawalType); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check application status",
+		})
+	} else if reason != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": reason,
+		})
+	}
+
+	withdrawal := &models.ApplicationWithdrawal{
+		ApplicationID:  uint(applicationID),
+		WithdrawalType: withdrawalType,
+		PreviousStatus: application.ApplicationStatus,
+		Reason:         req.Reason,
+		RequestedBy:    &userID,
+	}
+	if err := h.withdrawalRepo.Process(withdrawal); err != nil {
+		if err == repository.ErrApplicationStatusChanged {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Application status has changed, please reload and try again",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to withdraw application",
+		})
+	}
+
+	h.notifyClosed(withdrawal, application, userID)
+
+	message := "Application withdrawn successfully"
+	if withdrawalType == models.WithdrawalTypeDecline {
+		message = "Scholarship award declined successfully"
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data":    withdrawal,
+	})
+}
+
+// closeBlockedReason reports why an application cannot be withdrawn or its
+// award declined. An empty reason means the action is allowed.
+func (h *ApplicationWithdrawalHandler) closeBlockedReason(application *models.ScholarshipApplication, withdrawalType string) (string, error) {
+	if withdrawalType == models.WithdrawalTypeDecline {
+		if application.ApplicationStatus != "approved" {
+			return "Only approved awards can be declined", nil
+		}
+		if application.ReviewedAt != nil && h.cfg.AwardDeclineDays > 0 {
+			deadline := application.ReviewedAt.AddDate(0, 0, int(h.cfg.AwardDeclineDays))
+			if time.Now().After(deadline) {
+				return fmt.Sprintf("The decline period ended on %s", deadline.Format("02/01/2006")), nil
+			}
+		}
+		disbursed, err := h.withdrawalRepo.IsDisbursed(application.ApplicationID)
+		if err != nil {
+			return "", err
+		}
+		if disbursed {
+			return "The award has already been disbursed and can no longer be declined", nil
+		}
+		return "", nil
+	}
+
+	switch application.ApplicationStatus {
+	case "approved":
+		return "The application has been approved; decline the award instead", nil
+	case "rejected", "withdrawn", "declined":
+		return fmt.Sprintf("Cannot withdraw an application with status '%s'", application.ApplicationStatus), nil
+	case "draft":
+		return "Draft applications can be deleted instead of withdrawn", nil
+	}
+	final, err := h.withdrawalRepo.HasFinalRanking(application.ApplicationID)
+	if err != nil {
+		return "", err
+	}
+	if final {
+		return "The ranking for this scholarship is final; applications can no longer be withdrawn", nil
+	}
+	return "", nil
+}
+
+// notifyClosed tells the student, the assigned reviewers and the interviewers
+// that the application has been withdrawn or declined
+func (h *ApplicationWithdrawalHandler) notifyClosed(withdrawal *models.ApplicationWithdrawal, application *models.ScholarshipApplication, studentUserID uuid.UUID) {
+	applicationID := strconv.Itoa(int(withdrawal.ApplicationID))
+	scholarshipName := ""
+	if application.Scholarship != nil {
+		scholarshipName = application.Scholarship.ScholarshipName
+	}
+
+	studentTitle, staffTitle := "ถอนใบสมัครเรียบร้อยแล้ว", "นักศึกษาถอนใบสมัคร"
+	studentMessage := fmt.Sprintf("ใบสมัครทุน %s ของคุณถูกถอนแล้ว", scholarshipName)
+	staffMessage := fmt.Sprintf("ใบสมัครเลขที่ %s (ทุน %s) ถูกถอนโดยนักศึกษา เหตุผล: %s", applicationID, scholarshipName, withdrawal.Reason)
+	if withdrawal.WithdrawalType == models.WithdrawalTypeDecline {
+		studentTitle, staffTitle = "สละสิทธิ์ทุนเรียบร้อยแล้ว", "นักศึกษาสละสิทธิ์ทุน"
+		studentMessage = fmt.Sprintf("คุณได้สละสิทธิ์ทุน %s แล้ว", scholarshipName)
+		staffMessage = fmt.Sprintf("ใบสมัครเลขที่ %s (ทุน %s) สละสิทธิ์ทุน เหตุผล: %s", applicationID, scholarshipName, withdrawal.Reason)
+	}
+
+	CreateNotification(studentUserID.String(), "application_withdrawn", studentTitle, studentMessage,
+		applicationID, "application", "normal")
+
+	for _, reviewerID := range withdrawal.ReviewerIDs {
+		CreateNotification(reviewerID.String(), "application_withdrawn", staffTitle, staffMessage,
+			applicationID, "application", "normal")
+	}
+	for _, interviewerID := range withdrawal.InterviewerIDs {
+		CreateNotification(interviewerID.String(), "interview_cancelled", staffTitle,
+			staffMessage+" นัดสัมภาษณ์ที่เกี่ยวข้องถูกยกเลิกแล้ว", applicationID, "application", "normal")
+	}
+}
+
+// verifyApplicationOwnership checks if the user owns the application
+func (h *ApplicationWithdrawalHandler) verifyApplicationOwnership(applicationID uint, userID uuid.UUID) error {
+	application, err := h.applicationRepo.GetByID(applicationID)
+	if err != nil {
+		return err
+	}
+
+	var studentID string
+	err = database.DB.QueryRow(
+		"SELECT student_id FROM students WHERE user_id = $1",
+		userID,
+	).Scan(&studentID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// If no student record exists, try matching with email
+			user, userErr := repository.NewUserRepository().GetByID(userID)
+			if userErr != nil {
+				return userErr
+			}
+			if application.StudentID != user.Email {
+				return fiber.NewError(fiber.StatusForbidden, "Unauthorized")
+			}
+			return nil
+		}
+		return err
+	}
+
+	if application.StudentID != studentID {
+		return fiber.NewError(fiber.StatusForbidden, "Unauthorized")
+	}
+
+	return nil
+}
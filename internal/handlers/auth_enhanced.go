@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"path"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 
+	"scholarship-system/internal/database"
 	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/storage"
 )
 
 // AuthEnhancedHandler handles enhanced authentication operations
@@ -389,50 +394,58 @@ func (h *AuthEnhancedHandler) UpdateProfile(c *fiber.Ctx) error {
 // @Router /api/v1/profile/avatar [post]
 func (h *AuthEnhancedHandler) UploadAvatar(c *fiber.Ctx) error {
 	userClaims := c.Locals("user").(*jwt.Token).Claims.(jwt.MapClaims)
-	_ = userClaims["user_id"].(string) // Will be used when implementing usecase
-
-	file, err := c.FormFile("avatar")
+	userID, err := uuid.Parse(userClaims["user_id"].(string))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"success": false,
-			"error":   "Avatar file is required",
+			"error":   "Invalid user ID",
 		})
 	}
 
-	// Validate file type
-	allowedTypes := []string{"image/jpeg", "image/png", "image/gif"}
-	fileType := file.Header.Get("Content-Type")
-	typeAllowed := false
-	for _, allowedType := range allowedTypes {
-		if fileType == allowedType {
-			typeAllowed = true
-			break
-		}
-	}
-
-	if !typeAllowed {
+	file, err := c.FormFile("avatar")
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"error":   "Only JPEG, PNG, and GIF files are allowed",
+			"error":   "Avatar file is required",
 		})
 	}
 
-	// Validate file size (max 5MB)
-	if file.Size > 5*1024*1024 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+	// JPEG, PNG or GIF up to 5MB
+	stored, err := storage.SaveUpload(c.Context(), file, "avatars/"+userID.String(), "avatar", avatarUploadPolicy)
+	if err != nil {
+		return uploadFailed(c, err)
+	}
+
+	relatedTable := "users"
+	record := &models.FileStorage{
+		FileID:       uuid.New(),
+		OriginalName: stored.OriginalName,
+		StoredName:   path.Base(stored.Key),
+		StoredPath:   stored.Key,
+		FileSize:     stored.Size,
+		MimeType:     stored.ContentType,
+		FileHash:     stored.SHA256,
+		UploadedBy:   userID,
+		RelatedTable: &relatedTable,
+		RelatedID:    &userID,
+		StorageType:  stored.Backend,
+		AccessLevel:  "public",
+	}
+	if err := repository.NewFileRepository(database.DB).CreateFile(record); err != nil {
+		removeStoredFile(c, stored.Backend, stored.Key)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"error":   "File size must be less than 5MB",
+			"error":   "Failed to save avatar",
 		})
 	}
 
-	// TODO: Save file and update user avatar URL
-	// avatarURL, err := h.fileService.SaveAvatar(c.Context(), userID, file)
+	// TODO: Update user avatar URL once avatars are served
 	// err = h.userUsecase.UpdateAvatarURL(c.Context(), userID, avatarURL)
 
 	return c.JSON(fiber.Map{
-		"success":    true,
-		"message":    "Avatar uploaded successfully",
-		"avatar_url": "/uploads/avatars/mock-avatar.jpg",
+		"success": true,
+		"message": "Avatar uploaded successfully",
+		"file_id": record.FileID,
 	})
 }
 
@@ -0,0 +1,759 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/services"
+	"scholarship-system/internal/storage"
+)
+
+const (
+	// bulkUploadWorkers is how many files of a request are processed at once
+	bulkUploadWorkers = 4
+	// maxBulkFiles caps the files of one bulk upload request
+	maxBulkFiles = 50
+	// maxImportFiles caps the manifest rows of one officer import
+	maxImportFiles = 500
+)
+
+// BulkUploadHandler handles bulk document upload sessions, where a student
+// sends several documents at once, and officer imports of documents for many
+// students from a ZIP archive. Bulk uploads are staged like resumable uploads
+// and share their session, progress and cleanup.
+type BulkUploadHandler struct {
+	*ResumableUploadHandler
+}
+
+func NewBulkUploadHandler(cfg *config.Config) *BulkUploadHandler {
+	return &BulkUploadHandler{
+		ResumableUploadHandler: NewResumableUploadHandler(cfg),
+	}
+}
+
+// StartBulkUpload starts a bulk upload session
+// @Summary Start bulk document upload
+// @Description Start a session for uploading several documents of an application at once. Send the files to the files endpoint, watch the progress, then complete the session to attach them. Sessions expire after UPLOAD_SESSION_TTL_HOURS without activity
+// @Tags Document Management
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.BulkUploadRequest true "Application and the document types expected"
+// @Success 201 {object} models.BulkUploadResponse
+// @Failure 400 {object} object{error=string}
+// @Failure 401 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/documents/bulk-upload [post]
+func (h *BulkUploadHandler) StartBulkUpload(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	var req models.BulkUploadRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if req.ApplicationID == nil || *req.ApplicationID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "application_id is required",
+		})
+	}
+	if len(req.DocumentTypes) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "At least one document type is required",
+		})
+	}
+	for _, documentType := range req.DocumentTypes {
+		if !services.IsDocumentType(documentType) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Unknown document type: " + documentType,
+			})
+		}
+	}
+
+	ownerID, err := h.applicationRepo.GetApplicantUserID(uint(*req.ApplicationID))
+	if err != nil || ownerID != userID {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Application not found",
+		})
+	}
+
+	session := &models.BulkUploadSession{
+		UserID:        userID.String(),
+		ApplicationID: req.ApplicationID,
+		SessionType:   models.UploadSessionStudent,
+		DocumentTypes: req.DocumentTypes,
+		ExpiresAt:     h.expiry(),
+	}
+	if err := h.sessionRepo.CreateSession(session); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start upload session",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.BulkUploadResponse{
+		Success:      true,
+		SessionToken: session.SessionToken,
+		UploadURL:    "/api/v1/documents/bulk-upload/files?session_token=" + session.SessionToken,
+		ExpiresAt:    session.ExpiresAt,
+	})
+}
+
+// UploadBulkFiles adds files to a bulk upload session
+// @Summary Upload files in bulk session
+// @Description Upload several documents at once. Each file's document type comes from the mapping, or else from its name (e.g. id_card.pdf, transcript_2567.pdf, ทะเบียนบ้าน.jpg). Files are checked one by one; rejected files are reported without failing the others. Accepted files are attached when the session is completed
+// @Tags Document Management
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param session_token query string true "Bulk upload session token"
+// @Param files formData file true "Files to upload"
+// @Param mapping formData string false "JSON object of file name to document type"
+// @Success 200 {object} object{success=bool,data=object{uploaded_files=int,failed_files=int,results=[]models.BulkFileResult}}
+// @Failure 400 {object} object{error=string}
+// @Failure 401 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Failure 410 {object} object{error=string}
+// @Router /api/v1/documents/bulk-upload/files [post]
+func (h *BulkUploadHandler) UploadBulkFiles(c *fiber.Ctx) error {
+	session, err := h.loadBulkSession(c)
+	if session == nil {
+		return err
+	}
+	if session.SessionType != models.UploadSessionStudent || session.ApplicationID == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Not a bulk upload session",
+		})
+	}
+	if session.ExpiresAt.Before(time.Now()) {
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{
+			"error": "Upload session has expired",
+		})
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse multipart form",
+		})
+	}
+	files := form.File["files"]
+	if len(files) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "No files provided",
+		})
+	}
+	if len(files) > maxBulkFiles {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("At most %d files can be uploaded at once", maxBulkFiles),
+		})
+	}
+	var mapping map[string]string
+	if values := form.Value["mapping"]; len(values) > 0 && values[0] != "" {
+		if err := json.Unmarshal([]byte(values[0]), &mapping); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "mapping must be a JSON object of file name to document type",
+			})
+		}
+	}
+
+	store := storage.Default()
+	if store == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "File storage is not available",
+		})
+	}
+
+	results := make([]models.BulkFileResult, len(files))
+	runConcurrently(len(files), func(i int) {
+		results[i] = h.stageFile(c, session, store, files[i], mapping)
+	})
+
+	uploaded := 0
+	for _, result := range results {
+		if result.Status != models.BulkFileFailed {
+			uploaded++
+		}
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"session_token":  session.SessionToken,
+			"uploaded_files": uploaded,
+			"failed_files":   len(results) - uploaded,
+			"results":        results,
+		},
+	})
+}
+
+// stageFile checks a file of a bulk upload and stores it as a fully received
+// upload of the session. Rejected files are recorded as failed uploads, so
+// they show up in the session's error summary.
+func (h *BulkUploadHandler) stageFile(c *fiber.Ctx, session *models.BulkUploadSession, store storage.Storage,
+	file *multipart.FileHeader, mapping map[string]string) models.BulkFileResult {
+	upload := &models.ResumableUpload{
+		SessionID:      session.ID,
+		SessionToken:   session.SessionToken,
+		ApplicationID:  *session.ApplicationID,
+		DocumentType:   services.ClassifyDocument(file.Filename, mapping),
+		FileName:       filepath.Base(file.Filename),
+		UploadLength:   file.Size,
+		StorageBackend: store.Name(),
+		ExpiresAt:      h.expiry(),
+	}
+
+	data, reason := h.checkFile(session, upload.DocumentType, file)
+	if reason != "" {
+		return h.rejectFile(upload, reason)
+	}
+	upload.UploadLength = int64(len(data))
+
+	if err := h.sessionRepo.CreateUpload(upload); err != nil {
+		log.Printf("Failed to stage bulk upload file %s: %v", upload.FileName, err)
+		return bulkFileResult(upload, models.BulkFileFailed, "Failed to save file")
+	}
+	key := storage.ChunkKey(upload.UploadID, 0)
+	if err := store.Put(c.Context(), key, bytes.NewReader(data), upload.UploadLength, fiber.MIMEOctetStream); err != nil {
+		log.Printf("Failed to store bulk upload file %s: %v", upload.FileName, err)
+		h.failUpload(c, upload, []string{key}, "Failed to save file")
+		return bulkFileResult(upload, models.BulkFileFailed, "Failed to save file")
+	}
+	if _, err := h.sessionRepo.AddChunk(upload, 0, upload.UploadLength, key, h.expiry()); err != nil {
+		log.Printf("Failed to record bulk upload file %s: %v", upload.FileName, err)
+		h.failUpload(c, upload, []string{key}, "Failed to save file")
+		return bulkFileResult(upload, models.BulkFileFailed, "Failed to save file")
+	}
+	return bulkFileResult(upload, models.BulkFileUploaded, "")
+}
+
+// checkFile reads a file of a bulk upload and explains why it cannot be
+// accepted, or returns its contents and ""
+func (h *BulkUploadHandler) checkFile(session *models.BulkUploadSession, documentType string, file *multipart.FileHeader) ([]byte, string) {
+	switch {
+	case documentType == "":
+		return nil, "Cannot tell the document type from the file name"
+	case !services.IsDocumentType(documentType):
+		return nil, "Unknown document type: " + documentType
+	case len(session.DocumentTypes) > 0 && !containsString(session.DocumentTypes, documentType):
+		return nil, fmt.Sprintf("Document type %s is not expected in this session", documentType)
+	case file.Size == 0:
+		return nil, "File is empty"
+	}
+
+	reason, err := editLockReason(h.revisionRepo, uint(*session.ApplicationID), "document", documentType)
+	if err != nil {
+		return nil, "Failed to verify application status"
+	}
+	if reason != "" {
+		return nil, reason
+	}
+
+	requirement, err := loadDocumentRequirement(h.requirementRepo, uint(*session.ApplicationID), documentType)
+	if err != nil {
+		return nil, "Failed to load document requirements"
+	}
+	policy := documentUploadPolicy(h.cfg, requirement, documentType, documentTypes, "PDF, JPEG, PNG")
+	if err := policy.Check(file); err != nil {
+		return nil, err.Error()
+	}
+	src, err := file.Open()
+	if err != nil {
+		return nil, "Failed to read file"
+	}
+	defer src.Close()
+	data, err := io.ReadAll(io.LimitReader(src, file.Size))
+	if err != nil {
+		return nil, "Failed to read file"
+	}
+	if err := policy.Validate(data); err != nil {
+		var uploadErr *storage.UploadError
+		if errors.As(err, &uploadErr) {
+			return nil, uploadErr.Message
+		}
+		return nil, "Failed to read file"
+	}
+	return data, ""
+}
+
+// rejectFile records a file that failed its checks as a failed upload
+func (h *BulkUploadHandler) rejectFile(upload *models.ResumableUpload, reason string) models.BulkFileResult {
+	if err := h.sessionRepo.CreateUpload(upload); err != nil {
+		log.Printf("Failed to record rejected file %s: %v", upload.FileName, err)
+	} else if err := h.sessionRepo.FailUpload(upload, reason, h.expiry()); err != nil {
+		log.Printf("Failed to record rejected file %s: %v", upload.FileName, err)
+	}
+	return bulkFileResult(upload, models.BulkFileFailed, reason)
+}
+
+// GetUploadProgress returns the progress of a bulk upload session
+// @Summary Get bulk upload progress
+// @Description Get the files received, attached and failed in a bulk upload session or officer import, with the reason each failed file was rejected
+// @Tags Document Management
+// @Produce json
+// @Security BearerAuth
+// @Param session_token query string true "Bulk upload session token"
+// @Success 200 {object} object{success=bool,data=models.UploadSessionProgress}
+// @Failure 400 {object} object{error=string}
+// @Failure 401 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/documents/bulk-upload/progress [get]
+func (h *BulkUploadHandler) GetUploadProgress(c *fiber.Ctx) error {
+	session, err := h.loadBulkSession(c)
+	if session == nil {
+		return err
+	}
+	return sendSessionProgress(c, h.sessionRepo, session)
+}
+
+// CompleteBulkUpload attaches the files of a bulk upload session
+// @Summary Complete bulk upload
+// @Description Attach every accepted file of a bulk upload session to the application. A file for a document type that already has a document becomes its new version. Files are quarantined until the malware scan passes them
+// @Tags Document Management
+// @Produce json
+// @Security BearerAuth
+// @Param session_token query string true "Bulk upload session token"
+// @Success 200 {object} object{success=bool,data=object{attached_files=int,failed_files=int,results=[]models.BulkFileResult}}
+// @Failure 400 {object} object{error=string}
+// @Failure 401 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Failure 410 {object} object{error=string}
+// @Router /api/v1/documents/bulk-upload/complete [post]
+func (h *BulkUploadHandler) CompleteBulkUpload(c *fiber.Ctx) error {
+	session, err := h.loadBulkSession(c)
+	if session == nil {
+		return err
+	}
+	if session.SessionType != models.UploadSessionStudent || session.ApplicationID == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Not a bulk upload session",
+		})
+	}
+	if session.ExpiresAt.Before(time.Now()) {
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{
+			"error": "Upload session has expired",
+		})
+	}
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	uploads, err := h.sessionRepo.ListUploads(session.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch uploads",
+		})
+	}
+
+	// Files are attached one at a time so two files of a type become
+	// versions of one document in the order they were sent
+	results := []models.BulkFileResult{}
+	attached := 0
+	for i := range uploads {
+		upload := &uploads[i]
+		if upload.UploadStatus != models.ResumableUploading || upload.UploadOffset < upload.UploadLength {
+			continue
+		}
+		result := h.attachStaged(c, upload, userID)
+		if result.Status == models.BulkFileAttached {
+			attached++
+		}
+		results = append(results, result)
+	}
+	if attached > 0 {
+		queueDocumentScan()
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"session_token":  session.SessionToken,
+			"attached_files": attached,
+			"failed_files":   len(results) - attached,
+			"results":        results,
+		},
+	})
+}
+
+// attachStaged attaches a staged file of a bulk upload to its application
+func (h *BulkUploadHandler) attachStaged(c *fiber.Ctx, upload *models.ResumableUpload, userID uuid.UUID) models.BulkFileResult {
+	reason, err := editLockReason(h.revisionRepo, uint(upload.ApplicationID), "document", upload.DocumentType)
+	if err != nil {
+		return bulkFileResult(upload, models.BulkFileFailed, "Failed to verify application status")
+	}
+	if reason != "" {
+		h.failUpload(c, upload, nil, reason)
+		return bulkFileResult(upload, models.BulkFileFailed, reason)
+	}
+
+	doc, _, err := h.attachUpload(c, upload, userID)
+	if err != nil {
+		var uploadErr *storage.UploadError
+		switch {
+		case err == errUploadBusy:
+			return bulkFileResult(upload, models.BulkFileFailed, "File is already being attached")
+		case err == sql.ErrNoRows:
+			return bulkFileResult(upload, models.BulkFileFailed, "Document to replace not found")
+		case errors.As(err, &uploadErr):
+			return bulkFileResult(upload, models.BulkFileFailed, uploadErr.Message)
+		}
+		log.Printf("Failed to attach bulk upload %s: %v", upload.UploadID, err)
+		return bulkFileResult(upload, models.BulkFileFailed, "Failed to attach file; complete the session again to retry")
+	}
+
+	result := bulkFileResult(upload, models.BulkFileAttached, "")
+	result.DocumentID = doc.DocumentID
+	return result
+}
+
+// ImportDocuments attaches documents for many students from a ZIP archive
+// @Summary Import documents from ZIP
+// @Description Attach documents to many applications at once (Admin/Officer only). The manifest CSV has a header row with file, document_type and either application_id or student_id and scholarship_id; it is sent as the manifest field or included in the archive as manifest.csv. A blank document_type is worked out from the file name. Rows are checked one by one and failures are reported without stopping the import
+// @Tags Document Management
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param archive formData file true "ZIP archive of the documents"
+// @Param manifest formData file false "Manifest CSV (default: manifest.csv in the archive)"
+// @Success 200 {object} object{success=bool,data=object{session_token=string,total_files=int,uploaded_files=int,failed_files=int,results=[]models.BulkFileResult}}
+// @Failure 400 {object} object{error=string}
+// @Failure 401 {object} object{error=string}
+// @Failure 403 {object} object{error=string}
+// @Router /api/v1/documents/bulk-import [post]
+func (h *BulkUploadHandler) ImportDocuments(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	archiveFile, err := c.FormFile("archive")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "archive is required",
+		})
+	}
+	src, err := archiveFile.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to read archive",
+		})
+	}
+	defer src.Close()
+	archive, err := zip.NewReader(src, archiveFile.Size)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "archive must be a ZIP file",
+		})
+	}
+
+	entries := map[string]*zip.File{}
+	var manifestEntry *zip.File
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		name := zipEntryName(entry.Name)
+		if strings.EqualFold(name, "manifest.csv") {
+			manifestEntry = entry
+			continue
+		}
+		entries[name] = entry
+	}
+
+	var manifest io.ReadCloser
+	if manifestFile, err := c.FormFile("manifest"); err == nil {
+		manifest, err = manifestFile.Open()
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Failed to read manifest",
+			})
+		}
+	} else if manifestEntry != nil {
+		manifest, err = manifestEntry.Open()
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Failed to read manifest.csv",
+			})
+		}
+	} else {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "A manifest is required, as the manifest field or manifest.csv in the archive",
+		})
+	}
+	rows, err := services.ParseDocumentManifest(manifest)
+	manifest.Close()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if len(rows) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Manifest has no files",
+		})
+	}
+	if len(rows) > maxImportFiles {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("At most %d files can be imported at once", maxImportFiles),
+		})
+	}
+
+	if storage.Default() == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "File storage is not available",
+		})
+	}
+
+	session := &models.BulkUploadSession{
+		UserID:      userID.String(),
+		SessionType: models.UploadSessionImport,
+		ExpiresAt:   h.expiry(),
+	}
+	if err := h.sessionRepo.CreateSession(session); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start upload session",
+		})
+	}
+
+	// Rows of one application are imported in manifest order, so a later
+	// file of a type becomes the newer version; applications run in parallel
+	results := make([]models.BulkFileResult, len(rows))
+	groups := map[int][]int{}
+	var order []int
+	for i, row := range rows {
+		results[i] = models.BulkFileResult{FileName: row.File, Line: row.Line, DocumentType: row.DocumentType}
+		applicationID, reason := h.resolveApplication(row)
+		if reason != "" {
+			results[i].Status, results[i].Error = models.BulkFileFailed, reason
+			continue
+		}
+		results[i].ApplicationID = applicationID
+		if _, seen := groups[applicationID]; !seen {
+			order = append(order, applicationID)
+		}
+		groups[applicationID] = append(groups[applicationID], i)
+	}
+	runConcurrently(len(order), func(g int) {
+		for _, i := range groups[order[g]] {
+			h.importFile(c, entries, rows[i], &results[i], userID)
+		}
+	})
+
+	if err := h.sessionRepo.FinishImport(session, results); err != nil {
+		log.Printf("Failed to record import %s: %v", session.SessionToken, err)
+	}
+	if session.UploadedFiles > 0 {
+		queueDocumentScan()
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"session_token":  session.SessionToken,
+			"total_files":    session.TotalFiles,
+			"uploaded_files": session.UploadedFiles,
+			"failed_files":   session.FailedFiles,
+			"results":        results,
+		},
+	})
+}
+
+// resolveApplication finds the application a manifest row is for
+func (h *BulkUploadHandler) resolveApplication(row models.DocumentManifestRow) (int, string) {
+	if row.ApplicationID > 0 {
+		if _, err := h.revisionRepo.GetApplicationStatus(uint(row.ApplicationID)); err != nil {
+			if err == sql.ErrNoRows {
+				return 0, fmt.Sprintf("Application %d not found", row.ApplicationID)
+			}
+			return 0, "Failed to look up application"
+		}
+		return row.ApplicationID, ""
+	}
+	applicationID, err := h.applicationRepo.FindApplicationID(row.StudentID, row.ScholarshipID)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Sprintf("Student %s has no application for scholarship %d", row.StudentID, row.ScholarshipID)
+	}
+	if err != nil {
+		return 0, "Failed to look up application"
+	}
+	return applicationID, ""
+}
+
+// importFile attaches the archive entry named in a manifest row to its
+// application and fills in the row's result
+func (h *BulkUploadHandler) importFile(c *fiber.Ctx, entries map[string]*zip.File, row models.DocumentManifestRow,
+	result *models.BulkFileResult, userID uuid.UUID) {
+	fail := func(message string) {
+		result.Status, result.Error = models.BulkFileFailed, message
+	}
+
+	entry, ok := entries[zipEntryName(row.File)]
+	if !ok {
+		fail("File not found in archive")
+		return
+	}
+	documentType := row.DocumentType
+	if documentType == "" {
+		documentType = services.ClassifyDocument(row.File, nil)
+		result.DocumentType = documentType
+	}
+	if documentType == "" {
+		fail("Cannot tell the document type from the file name")
+		return
+	}
+	if !services.IsDocumentType(documentType) {
+		fail("Unknown document type: " + documentType)
+		return
+	}
+
+	requirement, err := loadDocumentRequirement(h.requirementRepo, uint(result.ApplicationID), documentType)
+	if err != nil {
+		fail("Failed to load document requirements")
+		return
+	}
+	policy := documentUploadPolicy(h.cfg, requirement, documentType, documentTypes, "PDF, JPEG, PNG")
+	if policy.MaxSize > 0 && entry.UncompressedSize64 > uint64(policy.MaxSize) {
+		fail(fmt.Sprintf("File size exceeds %dMB limit", policy.MaxSize/(1024*1024)))
+		return
+	}
+	src, err := entry.Open()
+	if err != nil {
+		fail("Failed to read file from archive")
+		return
+	}
+	stored, err := storage.SaveUploadFrom(c.Context(), src, path.Base(entry.Name),
+		fmt.Sprintf("applications/%d", result.ApplicationID), documentType, policy)
+	src.Close()
+	if err != nil {
+		var uploadErr *storage.UploadError
+		if errors.As(err, &uploadErr) {
+			fail(uploadErr.Message)
+			return
+		}
+		log.Printf("Failed to import %s: %v", row.File, err)
+		fail("Failed to save file")
+		return
+	}
+
+	doc := &models.ApplicationDocument{
+		ApplicationID:  result.ApplicationID,
+		DocumentType:   documentType,
+		DocumentName:   stored.OriginalName,
+		FilePath:       stored.Key,
+		StorageBackend: stored.Backend,
+		FileSize:       stored.Size,
+		MimeType:       stored.ContentType,
+		FileHash:       stored.SHA256,
+		UploadStatus:   models.DocumentQuarantined,
+		UploadedAt:     time.Now(),
+		UploadedBy:     &userID,
+	}
+	if _, err := saveApplicationDocument(h.fileRepo, h.applicationRepo, doc, 0, requirement); err != nil {
+		removeStoredFile(c, stored.Backend, stored.Key)
+		var uploadErr *storage.UploadError
+		if errors.As(err, &uploadErr) {
+			fail(uploadErr.Message)
+			return
+		}
+		log.Printf("Failed to save imported document %s: %v", row.File, err)
+		fail("Failed to save document metadata")
+		return
+	}
+	result.Status = models.BulkFileAttached
+	result.DocumentID = doc.DocumentID
+}
+
+// loadBulkSession fetches the bulk upload session named by the
+// session_token query parameter if it belongs to the user. On failure it
+// returns nil and the response already sent.
+func (h *BulkUploadHandler) loadBulkSession(c *fiber.Ctx) (*models.BulkUploadSession, error) {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return nil, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+	token := c.Query("session_token")
+	if token == "" {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Session token is required",
+		})
+	}
+
+	session, err := h.sessionRepo.GetSession(token, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Upload session not found",
+			})
+		}
+		return nil, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch upload session",
+		})
+	}
+	return session, nil
+}
+
+func bulkFileResult(upload *models.ResumableUpload, status, message string) models.BulkFileResult {
+	return models.BulkFileResult{
+		FileName:      upload.FileName,
+		ApplicationID: upload.ApplicationID,
+		DocumentType:  upload.DocumentType,
+		UploadID:      upload.UploadID,
+		Status:        status,
+		Error:         message,
+	}
+}
+
+// runConcurrently calls fn for 0..n-1 on up to bulkUploadWorkers goroutines
+// and waits for them
+func runConcurrently(n int, fn func(i int)) {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, bulkUploadWorkers)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// zipEntryName normalizes a path in an archive or manifest for matching
+func zipEntryName(name string) string {
+	name = path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))
+	return strings.ToLower(strings.TrimPrefix(name, "/"))
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
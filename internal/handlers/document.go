@@ -1,26 +1,39 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"path"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"scholarship-system/internal/config"
 	"scholarship-system/internal/database"
 	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+	"scholarship-system/internal/storage"
 )
 
 type DocumentHandler struct {
-	cfg *config.Config
+	cfg             *config.Config
+	revisionRepo    *repository.RevisionRepository
+	applicationRepo *repository.ApplicationRepository
+	fileRepo        *repository.FileRepository
+	requirementRepo *repository.DocumentRequirementRepository
 }
 
 func NewDocumentHandler(cfg *config.Config) *DocumentHandler {
-	return &DocumentHandler{cfg: cfg}
+	return &DocumentHandler{
+		cfg:             cfg,
+		revisionRepo:    repository.NewRevisionRepository(),
+		applicationRepo: repository.NewApplicationRepository(),
+		fileRepo:        repository.NewFileRepository(database.DB),
+		requirementRepo: repository.NewDocumentRequirementRepository(),
+	}
 }
 
 // UploadDocument handles file upload for application documents
@@ -33,10 +46,13 @@ func NewDocumentHandler(cfg *config.Config) *DocumentHandler {
 // @Param application_id path string true "Application ID"
 // @Param file formData file true "Document file (PDF, JPEG, PNG, DOC, DOCX - max 10MB)"
 // @Param document_type formData string true "Document type (id_card, transcript, income_certificate, etc.)"
-// @Success 201 {object} object{message=string,document_id=int,filename=string}
+// @Param replace_document_id formData int false "Document to replace with a new version (single-file types are replaced automatically)"
+// @Param issued_date formData string false "Date the document was issued (YYYY-MM-DD), needed for documents the scholarship only accepts within a validity period"
+// @Success 201 {object} object{message=string,document_id=int,filename=string,version_number=int}
 // @Failure 400 {object} object{error=string}
 // @Failure 401 {object} object{error=string}
 // @Failure 403 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
 // @Router /documents/applications/{application_id}/upload [post]
 func (h *DocumentHandler) UploadDocument(c *fiber.Ctx) error {
 	applicationID := c.Params("application_id")
@@ -69,82 +85,108 @@ func (h *DocumentHandler) UploadDocument(c *fiber.Ctx) error {
 		})
 	}
 
-	// Get uploaded file
-	file, err := c.FormFile("file")
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "No file uploaded",
+	// Only flagged document types accept uploads while the application is
+	// sent back for revision
+	appID, _ := strconv.Atoi(applicationID)
+	if reason, err := editLockReason(h.revisionRepo, uint(appID), "document", documentType); err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Application not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify application status",
+		})
+	} else if reason != "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": reason,
 		})
 	}
 
-	// Validate file size (10MB limit)
-	maxSize := int64(10 * 1024 * 1024) // 10MB
-	if file.Size > maxSize {
+	replaceID, err := replaceDocumentID(c)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "File size exceeds 10MB limit",
+			"error": "Invalid replace_document_id",
 		})
 	}
 
-	// Validate file type
-	allowedTypes := map[string]bool{
-		"application/pdf":  true,
-		"image/jpeg":       true,
-		"image/jpg":        true,
-		"image/png":        true,
-		"application/msword": true,
-		"application/vnd.openxmlformats-officedocument.wordprocessingml.document": true,
-	}
-	
-	if !allowedTypes[file.Header.Get("Content-Type")] {
+	// Get uploaded file
+	file, err := c.FormFile("file")
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid file type. Allowed: PDF, JPEG, PNG, DOC, DOCX",
+			"error": "No file uploaded",
 		})
 	}
 
-	// Create upload directory if not exists
-	uploadDir := filepath.Join("./uploads", "documents", applicationID)
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create upload directory",
+	issuedDate, err := parseIssuedDate(c.FormValue("issued_date"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
-
-	// Generate unique filename
-	ext := filepath.Ext(file.Filename)
-	filename := fmt.Sprintf("%s_%d%s", documentType, time.Now().Unix(), ext)
-	filepath := filepath.Join(uploadDir, filename)
-
-	// Save file
-	if err := c.SaveFile(file, filepath); err != nil {
+	requirement, err := loadDocumentRequirement(h.requirementRepo, uint(appID), documentType)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save file",
+			"error": "Failed to load document requirements",
 		})
 	}
 
-	// Save document metadata to database
-	query := `INSERT INTO application_documents 
-		(application_id, document_type, document_name, file_path, file_size, mime_type)
-		VALUES ($1, $2, $3, $4, $5, $6) RETURNING document_id`
-
-	var documentID int
-	err = database.DB.QueryRow(query,
-		applicationID, documentType, file.Filename, filepath,
-		file.Size, file.Header.Get("Content-Type"),
-	).Scan(&documentID)
-
+	// Store the file (PDF, JPEG, PNG, DOC, DOCX, narrowed by the scholarship's
+	// requirement) in quarantine until scanned
+	stored, err := storage.SaveUpload(c.Context(), file, fmt.Sprintf("applications/%d", appID), documentType,
+		documentUploadPolicy(h.cfg, requirement, documentType, officeDocumentTypes, "PDF, JPEG, PNG, DOC, DOCX"))
+	if err != nil {
+		return uploadFailed(c, err)
+	}
+	filename := path.Base(stored.Key)
+
+	// Save document metadata to database, as a new version when the upload
+	// replaces an existing document
+	uploadedBy := userIDValue.(uuid.UUID)
+	doc := &models.ApplicationDocument{
+		ApplicationID:  appID,
+		DocumentType:   documentType,
+		DocumentName:   stored.OriginalName,
+		FilePath:       stored.Key,
+		StorageBackend: stored.Backend,
+		FileSize:       stored.Size,
+		MimeType:       stored.ContentType,
+		FileHash:       stored.SHA256,
+		UploadStatus:   models.DocumentQuarantined,
+		IssuedDate:     issuedDate,
+		UploadedBy:     &uploadedBy,
+	}
+	replaced, err := saveApplicationDocument(h.fileRepo, h.applicationRepo, doc, replaceID, requirement)
 	if err != nil {
 		// Delete uploaded file if database insert fails
-		os.Remove(filepath)
+		removeStoredFile(c, stored.Backend, stored.Key)
+		var uploadErr *storage.UploadError
+		if errors.As(err, &uploadErr) {
+			return uploadFailed(c, err)
+		}
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Document to replace not found",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to save document metadata",
 		})
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+	queueDocumentScan()
+
+	response := fiber.Map{
 		"message": "Document uploaded successfully",
-		"document_id": documentID,
+		"document_id": doc.DocumentID,
 		"filename": filename,
-	})
+		"upload_status": models.DocumentQuarantined,
+		"version_number": doc.VersionNumber,
+	}
+	if replaced != nil {
+		response["replaced_version"] = replaced.VersionNumber
+	}
+	return c.Status(fiber.StatusCreated).JSON(response)
 }
 
 // GetDocuments retrieves documents for an application
@@ -181,7 +223,7 @@ func (h *DocumentHandler) GetDocuments(c *fiber.Ctx) error {
 
 	query := `SELECT document_id, document_type, document_name, file_path,
 		file_size, mime_type, upload_status, verification_notes,
-		uploaded_at, verified_at
+		uploaded_at, verified_at, page_count, image_width, image_height, preview_status
 		FROM application_documents
 		WHERE application_id = $1
 		ORDER BY uploaded_at DESC`
@@ -202,6 +244,7 @@ func (h *DocumentHandler) GetDocuments(c *fiber.Ctx) error {
 			&doc.FilePath, &doc.FileSize, &doc.MimeType,
 			&doc.UploadStatus, &doc.VerificationNotes,
 			&doc.UploadedAt, &doc.VerifiedAt,
+			&doc.PageCount, &doc.ImageWidth, &doc.ImageHeight, &doc.PreviewStatus,
 		)
 		if err != nil {
 			continue
@@ -228,58 +271,62 @@ func (h *DocumentHandler) GetDocuments(c *fiber.Ctx) error {
 // @Failure 401 {object} object{error=string}
 // @Failure 403 {object} object{error=string}
 // @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
 // @Router /documents/{document_id}/download [get]
 func (h *DocumentHandler) DownloadDocument(c *fiber.Ctx) error {
-	documentID := c.Params("document_id")
-	userID := c.Locals("user_id").(string)
-	userRole := c.Locals("user_role").(string)
+	doc, err := loadAccessibleDocument(c, h.applicationRepo)
+	if doc == nil {
+		return err
+	}
 
-	// Get document info and check permissions
-	var doc models.ApplicationDocument
-	var applicationID int
-	query := `SELECT ad.document_id, ad.application_id, ad.document_type,
-		ad.document_name, ad.file_path, ad.mime_type
-		FROM application_documents ad`
+	if reason := scanHoldReason(doc.UploadStatus); reason != "" {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": reason,
+		})
+	}
 
-	if userRole == "student" {
-		query += ` JOIN scholarship_applications sa ON ad.application_id = sa.application_id
-			JOIN students s ON sa.student_id = s.student_id
-			JOIN users u ON s.user_id = u.user_id
-			WHERE ad.document_id = $1 AND u.user_id = $2`
-		err := database.DB.QueryRow(query, documentID, userID).Scan(
-			&doc.DocumentID, &applicationID, &doc.DocumentType,
-			&doc.DocumentName, &doc.FilePath, &doc.MimeType,
-		)
-		if err != nil {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Document not found or access denied",
-			})
-		}
-	} else {
-		query += " WHERE ad.document_id = $1"
-		err := database.DB.QueryRow(query, documentID).Scan(
-			&doc.DocumentID, &applicationID, &doc.DocumentType,
-			&doc.DocumentName, &doc.FilePath, &doc.MimeType,
-		)
-		if err != nil {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Document not found",
-			})
-		}
+	logDocumentAccess(c, h.fileRepo, doc, doc.VersionNumber, models.FileAccessDownload, models.AccessMethodBearer)
+	return sendStoredFile(c, doc.StorageBackend, doc.FilePath, doc.DocumentName, doc.MimeType)
+}
+
+// PreviewDocument serves the preview image of a document
+// @Summary Preview document
+// @Description Get a JPEG preview of a document: a scaled-down copy of an image, or the first page of a scanned PDF. Page count and image dimensions are on the document record.
+// @Tags Document Management
+// @Produce image/jpeg
+// @Security BearerAuth
+// @Param document_id path string true "Document ID"
+// @Success 200 {file} file "Preview image"
+// @Failure 401 {object} object{error=string}
+// @Failure 403 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
+// @Router /documents/{document_id}/preview [get]
+func (h *DocumentHandler) PreviewDocument(c *fiber.Ctx) error {
+	doc, err := loadAccessibleDocument(c, h.applicationRepo)
+	if doc == nil {
+		return err
 	}
 
-	// Check if file exists
-	if _, err := os.Stat(doc.FilePath); os.IsNotExist(err) {
+	if reason := scanHoldReason(doc.UploadStatus); reason != "" {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": reason,
+		})
+	}
+	if doc.PreviewStatus == nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Preview is still being generated",
+		})
+	}
+	if *doc.PreviewStatus != models.PreviewReady || doc.PreviewPath == "" {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "File not found on server",
+			"error": "No preview is available for this document",
 		})
 	}
 
-	// Set headers for file download
-	c.Set("Content-Type", doc.MimeType)
-	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, doc.DocumentName))
-
-	return c.SendFile(doc.FilePath)
+	logDocumentAccess(c, h.fileRepo, doc, doc.VersionNumber, models.FileAccessPreview, models.AccessMethodBearer)
+	name := strings.TrimSuffix(doc.DocumentName, path.Ext(doc.DocumentName)) + "-preview.jpg"
+	return sendStoredFileAs(c, doc.PreviewBackend, doc.PreviewPath, name, storage.TypeJPEG, true)
 }
 
 // VerifyDocument allows officers to verify uploaded documents
@@ -296,6 +343,7 @@ func (h *DocumentHandler) DownloadDocument(c *fiber.Ctx) error {
 // @Failure 401 {object} object{error=string}
 // @Failure 403 {object} object{error=string}
 // @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
 // @Router /documents/{document_id}/verify [post]
 func (h *DocumentHandler) VerifyDocument(c *fiber.Ctx) error {
 	documentID := c.Params("document_id")
@@ -321,9 +369,10 @@ func (h *DocumentHandler) VerifyDocument(c *fiber.Ctx) error {
 	query := `UPDATE application_documents 
 		SET upload_status = $1, verification_notes = $2, 
 		    verified_by = $3, verified_at = CURRENT_TIMESTAMP
-		WHERE document_id = $4`
+		WHERE document_id = $4 AND upload_status NOT IN ($5, $6, $7, $8)`
 
-	result, err := database.DB.Exec(query, verification.Status, verification.Notes, userID, documentID)
+	result, err := database.DB.Exec(query, verification.Status, verification.Notes, userID, documentID,
+		models.DocumentQuarantined, models.DocumentInfected, models.DocumentPurged, models.DocumentMissing)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to verify document",
@@ -332,6 +381,14 @@ func (h *DocumentHandler) VerifyDocument(c *fiber.Ctx) error {
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
+		// Files that have not passed the malware scan cannot be verified
+		var uploadStatus string
+		err := database.DB.QueryRow("SELECT upload_status FROM application_documents WHERE document_id = $1", documentID).Scan(&uploadStatus)
+		if err == nil && scanHoldReason(uploadStatus) != "" {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": scanHoldReason(uploadStatus),
+			})
+		}
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Document not found",
 		})
@@ -360,16 +417,18 @@ func (h *DocumentHandler) DeleteDocument(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 
 	// Check if document belongs to current user and is not verified
-	var filePath string
+	var filePath, storageBackend string
 	var uploadStatus string
-	checkQuery := `SELECT ad.file_path, ad.upload_status
+	var previewPath, previewBackend string
+	checkQuery := `SELECT ad.file_path, ad.storage_backend, ad.upload_status,
+		       COALESCE(ad.preview_path, ''), COALESCE(ad.preview_backend, '')
 		FROM application_documents ad
 		JOIN scholarship_applications sa ON ad.application_id = sa.application_id
 		JOIN students s ON sa.student_id = s.student_id
 		JOIN users u ON s.user_id = u.user_id
 		WHERE ad.document_id = $1 AND u.user_id = $2`
 
-	err := database.DB.QueryRow(checkQuery, documentID, userID).Scan(&filePath, &uploadStatus)
+	err := database.DB.QueryRow(checkQuery, documentID, userID).Scan(&filePath, &storageBackend, &uploadStatus, &previewPath, &previewBackend)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Document not found or access denied",
@@ -382,6 +441,15 @@ func (h *DocumentHandler) DeleteDocument(c *fiber.Ctx) error {
 		})
 	}
 
+	// Earlier versions go with the document
+	docID, _ := strconv.Atoi(documentID)
+	versions, err := h.fileRepo.GetDocumentVersions(docID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve document versions",
+		})
+	}
+
 	// Delete from database
 	deleteQuery := `DELETE FROM application_documents WHERE document_id = $1`
 	_, err = database.DB.Exec(deleteQuery, documentID)
@@ -391,10 +459,11 @@ func (h *DocumentHandler) DeleteDocument(c *fiber.Ctx) error {
 		})
 	}
 
-	// Delete physical file
-	if err := os.Remove(filePath); err != nil {
-		// Log error but don't fail the request
-		fmt.Printf("Warning: Failed to delete file %s: %v\n", filePath, err)
+	// Delete stored files
+	removeStoredFile(c, storageBackend, filePath)
+	removeStoredFile(c, previewBackend, previewPath)
+	for _, version := range versions {
+		removeStoredFile(c, version.StorageBackend, version.FilePath)
 	}
 
 	return c.JSON(fiber.Map{
@@ -402,32 +471,78 @@ func (h *DocumentHandler) DeleteDocument(c *fiber.Ctx) error {
 	})
 }
 
+// documentTypeDescriptions lists the document types students can upload
+var documentTypeDescriptions = []struct {
+	Type        string
+	Description string
+}{
+	{"id_card", "Identity Card"},
+	{"house_registration", "House Registration"},
+	{"transcript", "Academic Transcript"},
+	{"income_certificate", "Family Income Certificate"},
+	{"residence_photo", "Residence Photo"},
+	{"house_photos", "House Photos"},
+	{"living_situation_photos", "Living Situation Photos"},
+	{"activity_certificate", "Activity Certificate"},
+	{"recommendation_letter", "Recommendation Letter"},
+	{"medical_certificate", "Medical Certificate"},
+	{"scholarship_certificate", "Previous Scholarship Certificate"},
+	{"bank_account", "Bank Account Statement"},
+	{"other", "Other Supporting Documents"},
+}
+
 // GetDocumentTypes returns available document types
 // @Summary Get document types
-// @Description Get list of available document types for uploads
+// @Description Get list of available document types for uploads. With scholarship_id, each type the scholarship asks for carries its requirement (mandatory, conditional or optional, size, formats, count and validity); without it, the default requirements apply
 // @Tags Document Management
 // @Produce json
 // @Security BearerAuth
+// @Param scholarship_id query int false "Scholarship whose requirements to show"
 // @Success 200 {object} object{data=[]object}
+// @Failure 400 {object} object{error=string}
 // @Failure 401 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
 // @Router /documents/types [get]
 func (h *DocumentHandler) GetDocumentTypes(c *fiber.Ctx) error {
-    types := []map[string]interface{}{
-        {"type": "id_card", "description": "Identity Card", "required": true},
-        {"type": "transcript", "description": "Academic Transcript", "required": true},
-        {"type": "income_certificate", "description": "Family Income Certificate", "required": true},
-        {"type": "residence_photo", "description": "Residence Photo", "required": true},
-        {"type": "activity_certificate", "description": "Activity Certificate", "required": false},
-        {"type": "recommendation_letter", "description": "Recommendation Letter", "required": false},
-        {"type": "medical_certificate", "description": "Medical Certificate", "required": false},
-        {"type": "scholarship_certificate", "description": "Previous Scholarship Certificate", "required": false},
-        {"type": "bank_account", "description": "Bank Account Statement", "required": false},
-        {"type": "other", "description": "Other Supporting Documents", "required": false},
-    }
-
-    return c.JSON(fiber.Map{
-        "data": types,
-    })
+	requirements := services.DefaultDocumentRequirements
+	if value := c.Query("scholarship_id"); value != "" {
+		scholarshipID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid scholarship ID",
+			})
+		}
+		own, err := h.requirementRepo.GetForScholarship(uint(scholarshipID))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": "Scholarship not found",
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to load document requirements",
+			})
+		}
+		requirements = services.DocumentRequirementsOrDefault(own)
+	}
+
+	types := make([]fiber.Map, 0, len(documentTypeDescriptions))
+	for _, documentType := range documentTypeDescriptions {
+		entry := fiber.Map{
+			"type":        documentType.Type,
+			"description": documentType.Description,
+			"required":    false,
+		}
+		if req := services.FindDocumentRequirement(requirements, documentType.Type); req != nil {
+			entry["required"] = req.Requirement == models.RequirementMandatory
+			entry["requirement"] = req
+		}
+		types = append(types, entry)
+	}
+
+	return c.JSON(fiber.Map{
+		"data": types,
+	})
 }
 
 // GetDocumentStats returns document verification statistics
@@ -447,7 +562,9 @@ func (h *DocumentHandler) GetDocumentStats(c *fiber.Ctx) error {
 		COUNT(*) as total_documents,
 		COUNT(CASE WHEN upload_status = 'pending' THEN 1 END) as pending_docs,
 		COUNT(CASE WHEN upload_status = 'verified' THEN 1 END) as verified_docs,
-		COUNT(CASE WHEN upload_status = 'rejected' THEN 1 END) as rejected_docs
+		COUNT(CASE WHEN upload_status = 'rejected' THEN 1 END) as rejected_docs,
+		COUNT(CASE WHEN upload_status = 'quarantined' THEN 1 END) as quarantined_docs,
+		COUNT(CASE WHEN upload_status = 'infected' THEN 1 END) as infected_docs
 		FROM application_documents`
 	
 	args := []interface{}{}
@@ -457,15 +574,18 @@ func (h *DocumentHandler) GetDocumentStats(c *fiber.Ctx) error {
 	}
 
 	var stats struct {
-		TotalDocuments    int `json:"total_documents"`
-		PendingDocuments  int `json:"pending_documents"`
-		VerifiedDocuments int `json:"verified_documents"`
-		RejectedDocuments int `json:"rejected_documents"`
+		TotalDocuments       int `json:"total_documents"`
+		PendingDocuments     int `json:"pending_documents"`
+		VerifiedDocuments    int `json:"verified_documents"`
+		RejectedDocuments    int `json:"rejected_documents"`
+		QuarantinedDocuments int `json:"quarantined_documents"`
+		InfectedDocuments    int `json:"infected_documents"`
 	}
 
 	err := database.DB.QueryRow(baseQuery, args...).Scan(
 		&stats.TotalDocuments, &stats.PendingDocuments,
 		&stats.VerifiedDocuments, &stats.RejectedDocuments,
+		&stats.QuarantinedDocuments, &stats.InfectedDocuments,
 	)
 
 	if err != nil {
@@ -521,17 +641,19 @@ func (h *DocumentHandler) BulkVerifyDocuments(c *fiber.Ctx) error {
 	
 	// Build query with placeholders
 	placeholders := make([]string, len(request.DocumentIDs))
-	args := []interface{}{request.Status, request.Notes, userID}
+	args := []interface{}{request.Status, request.Notes, userID,
+		models.DocumentQuarantined, models.DocumentInfected, models.DocumentPurged, models.DocumentMissing}
 	
 	for i, id := range request.DocumentIDs {
-		placeholders[i] = "$" + strconv.Itoa(i+4)
+		placeholders[i] = "$" + strconv.Itoa(i+8)
 		args = append(args, id)
 	}
 
+	// Documents that have not passed the malware scan are left alone
 	query := fmt.Sprintf(`UPDATE application_documents 
 		SET upload_status = $1, verification_notes = $2, 
 		    verified_by = $3, verified_at = CURRENT_TIMESTAMP
-		WHERE document_id IN (%s)`, strings.Join(placeholders, ","))
+		WHERE upload_status NOT IN ($4, $5, $6, $7) AND document_id IN (%s)`, strings.Join(placeholders, ","))
 
 	result, err := database.DB.Exec(query, args...)
 	if err != nil {
@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/jobs"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+)
+
+type DocumentBundleHandler struct {
+	cfg      *config.Config
+	fileRepo *repository.FileRepository
+	viewRepo *repository.ApplicationViewRepository
+	jobRepo  *repository.JobRepository
+}
+
+func NewDocumentBundleHandler(cfg *config.Config) *DocumentBundleHandler {
+	return &DocumentBundleHandler{
+		cfg:      cfg,
+		fileRepo: repository.NewFileRepository(database.DB),
+		viewRepo: repository.NewApplicationViewRepository(),
+		jobRepo:  repository.NewJobRepository(),
+	}
+}
+
+// DownloadBundle downloads every document of an application in one file
+// @Summary Download application document bundle
+// @Description Download the documents of an application, named by student ID and document type, with the submitted form rendered as PDF and a manifest. The zip format keeps the files as uploaded; the pdf format merges the manifest, form and documents into one PDF. Documents still held by the malware scan are listed but left out (Admin/Officer/Committee only)
+// @Tags Document Management
+// @Produce application/zip
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Param format query string false "zip (default) or pdf"
+// @Success 200 {file} binary
+// @Failure 400 {object} object{error=string}
+// @Failure 401 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/applications/{id}/document-bundle [get]
+func (h *DocumentBundleHandler) DownloadBundle(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	applicationID, err := c.ParamsInt("id")
+	if err != nil || applicationID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid application ID",
+		})
+	}
+	format, err := services.BundleFormat(c.Query("format"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	manifest, form, open, err := jobs.LoadDocumentBundle(uint(applicationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Application not found",
+			})
+		}
+		log.Printf("Failed to load document bundle of application %d: %v", applicationID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load application documents",
+		})
+	}
+
+	font := jobs.BundleFont(h.cfg)
+	name := services.BundleFolder(manifest.StudentID, manifest.ApplicationID)
+	var buf bytes.Buffer
+	contentType := "application/zip"
+	if format == models.BundleFormatPDF {
+		name += ".pdf"
+		contentType = "application/pdf"
+		err = services.WriteBundlePDF(&buf, font, manifest, form, open)
+	} else {
+		name += ".zip"
+		var formPDF []byte
+		formPDF, err = services.ApplicationFormPDF(font, manifest, form)
+		if err == nil {
+			zw := zip.NewWriter(&buf)
+			if err = services.WriteBundleZip(zw, "", manifest, formPDF, open); err == nil {
+				err = zw.Close()
+			}
+		}
+	}
+	if err != nil {
+		log.Printf("Failed to build document bundle of application %d: %v", applicationID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to build document bundle",
+		})
+	}
+
+	for _, entry := range manifest.Documents {
+		if entry.File == "" || (format == models.BundleFormatPDF && entry.Note != "") {
+			continue
+		}
+		doc := &models.ApplicationDocument{
+			DocumentID:    entry.DocumentID,
+			ApplicationID: applicationID,
+			DocumentType:  entry.DocumentType,
+		}
+		logAccess(c, h.fileRepo, userID, doc, entry.VersionNumber, models.FileAccessDownload, models.AccessMethodBearer)
+	}
+
+	c.Set("Content-Type", contentType)
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+	c.Set("X-Content-Type-Options", "nosniff")
+	c.Set("Cache-Control", "private, no-store")
+	return c.Send(buf.Bytes())
+}
+
+// StartCommitteePack queues the export of a scholarship's shortlisted
+// applications
+// @Summary Export committee pack
+// @Description Queue a background job that bundles the documents, form and manifest of every shortlisted application of a scholarship into one ZIP, as a folder per application or, with format pdf, a merged PDF per application. The shortlist is the applications under review or scheduled for interview unless statuses is given. The job result has a download_url once it completes (Admin/Officer/Committee only)
+// @Tags Document Management
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CommitteePackRequest true "Scholarship, statuses and format"
+// @Success 202 {object} object{success=bool,message=string,data=models.JobQueue}
+// @Failure 400 {object} object{error=string}
+// @Failure 401 {object} object{error=string}
+// @Router /api/v1/committee-packs [post]
+func (h *DocumentBundleHandler) StartCommitteePack(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	var req models.CommitteePackRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if err := services.ValidateCommitteePack(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Resolve the shortlist now so the pack holds what the officer asked for
+	ids, err := h.viewRepo.ListApplicationIDs(models.ApplicationFilter{
+		Statuses:       req.Statuses,
+		ScholarshipIDs: []uint{req.ScholarshipID},
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to select applications",
+		})
+	}
+	if err := services.CheckBulkSelection(ids); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	job, err := h.jobRepo.Enqueue(models.JobTypeCommitteePack, models.CommitteePackJob{
+		ScholarshipID:  req.ScholarshipID,
+		ApplicationIDs: ids,
+		Format:         req.Format,
+	}, &userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to queue committee pack",
+		})
+	}
+	job.ProgressTotal = len(ids)
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"message": "Committee pack queued",
+		"data":    job,
+	})
+}
+
+// ListCommitteePacks lists the current user's committee packs
+// @Summary List committee packs
+// @Description List the latest committee packs queued by the current user with their progress and results (Admin/Officer/Committee only)
+// @Tags Document Management
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} object{success=bool,data=[]models.JobQueue}
+// @Failure 401 {object} object{error=string}
+// @Router /api/v1/committee-packs [get]
+func (h *DocumentBundleHandler) ListCommitteePacks(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	packs, err := h.jobRepo.ListByCreator(models.JobTypeCommitteePack, userID, 50)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve committee packs",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    packs,
+	})
+}
+
+// GetCommitteePack returns the progress of a committee pack
+// @Summary Get committee pack progress
+// @Description Get the status, progress and per-application errors of a committee pack, with its download_url once complete (Admin/Officer/Committee only)
+// @Tags Document Management
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID"
+// @Success 200 {object} object{success=bool,data=models.JobQueue}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/committee-packs/{id} [get]
+func (h *DocumentBundleHandler) GetCommitteePack(c *fiber.Ctx) error {
+	job, err := h.loadCommitteePack(c)
+	if job == nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    job,
+	})
+}
+
+// DownloadCommitteePack downloads the ZIP of a completed committee pack
+// @Summary Download committee pack
+// @Description Download the ZIP written by a completed committee pack (Admin/Officer/Committee only)
+// @Tags Document Management
+// @Produce application/zip
+// @Security BearerAuth
+// @Param id path string true "Job ID"
+// @Success 200 {file} binary
+// @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
+// @Router /api/v1/committee-packs/{id}/download [get]
+func (h *DocumentBundleHandler) DownloadCommitteePack(c *fiber.Ctx) error {
+	job, err := h.loadCommitteePack(c)
+	if job == nil {
+		return err
+	}
+
+	var result models.CommitteePackResult
+	if job.Status != "completed" || json.Unmarshal(job.Result, &result) != nil || result.ExportFile == "" {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Committee pack is not ready",
+		})
+	}
+
+	return sendStoredFile(c, result.ExportBackend, jobs.ExportKey(result.ExportFile), result.ExportFile, "application/zip")
+}
+
+// loadCommitteePack returns the committee pack job of the :id param if the
+// user queued it or is an admin. Otherwise it writes the error response
+// itself and returns a nil job.
+func (h *DocumentBundleHandler) loadCommitteePack(c *fiber.Ctx) (*models.JobQueue, error) {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return nil, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid job ID",
+		})
+	}
+
+	job, err := h.jobRepo.GetByID(jobID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve committee pack",
+		})
+	}
+	isAdmin := false
+	roles, _ := c.Locals("roles").([]string)
+	for _, role := range roles {
+		if role == "admin" {
+			isAdmin = true
+			break
+		}
+	}
+	if err == sql.ErrNoRows || job.JobType != models.JobTypeCommitteePack ||
+		((job.CreatedBy == nil || *job.CreatedBy != userID) && !isAdmin) {
+		return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Committee pack not found",
+		})
+	}
+	return job, nil
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+)
+
+// documentChecklist checks the documents of an application against the
+// requirements of its scholarship. form may be nil for an application
+// without one.
+func documentChecklist(requirementRepo *repository.DocumentRequirementRepository, applicationID uint,
+	form *models.CompleteApplicationForm, documents []models.ApplicationDocument) (*models.DocumentChecklist, error) {
+	scholarshipID, requirements, err := requirementRepo.GetForApplication(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	var formJSON json.RawMessage
+	if form != nil {
+		if formJSON, err = json.Marshal(form); err != nil {
+			return nil, err
+		}
+	}
+	return services.EvaluateDocumentChecklist(applicationID, scholarshipID, requirements, formJSON, documents, time.Now()), nil
+}
+
+type DocumentChecklistHandler struct {
+	cfg             *config.Config
+	applicationRepo *repository.ApplicationRepository
+	detailsRepo     *repository.ApplicationDetailsRepository
+	requirementRepo *repository.DocumentRequirementRepository
+	revisionRepo    *repository.RevisionRepository
+	fileRepo        *repository.FileRepository
+}
+
+func NewDocumentChecklistHandler(cfg *config.Config) *DocumentChecklistHandler {
+	return &DocumentChecklistHandler{
+		cfg:             cfg,
+		applicationRepo: repository.NewApplicationRepository(),
+		detailsRepo:     repository.NewApplicationDetailsRepository(),
+		requirementRepo: repository.NewDocumentRequirementRepository(),
+		revisionRepo:    repository.NewRevisionRepository(),
+		fileRepo:        repository.NewFileRepository(database.DB),
+	}
+}
+
+// GetChecklist returns the document checklist of an application
+// @Summary Get document checklist
+// @Description Check the uploaded documents of an application against the requirements of its scholarship: each required, conditional and optional document with its files, count, validity and what is still missing. Submission is blocked until complete is true. Students see their own applications; officers any
+// @Tags Document Management
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Success 200 {object} object{success=bool,data=models.DocumentChecklist}
+// @Failure 400 {object} object{error=string}
+// @Failure 401 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/applications/{id}/document-checklist [get]
+func (h *DocumentChecklistHandler) GetChecklist(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	applicationID, err := c.ParamsInt("id")
+	if err != nil || applicationID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid application ID",
+		})
+	}
+
+	isOfficer := false
+	roles, _ := c.Locals("roles").([]string)
+	for _, role := range roles {
+		if role == "admin" || role == "scholarship_officer" {
+			isOfficer = true
+			break
+		}
+	}
+	if !isOfficer {
+		ownerID, err := h.applicationRepo.GetApplicantUserID(uint(applicationID))
+		if err != nil || ownerID != userID {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Application not found",
+			})
+		}
+	}
+
+	form, err := h.detailsRepo.GetCompleteForm(uint(applicationID))
+	if err != nil && err != sql.ErrNoRows {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load application form",
+		})
+	}
+	documents, err := h.applicationRepo.GetDocuments(uint(applicationID))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load application documents",
+		})
+	}
+
+	checklist, err := documentChecklist(h.requirementRepo, uint(applicationID), form, documents)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Application not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check application documents",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    checklist,
+	})
+}
+
+// SetIssuedDate records when a document was issued
+// @Summary Set document issue date
+// @Description Record the date a document was issued, for documents the scholarship only accepts within a validity period such as "issued within 6 months". An empty date clears it (Student only)
+// @Tags Document Management
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param document_id path int true "Document ID"
+// @Param request body models.SetIssuedDateRequest true "Issue date"
+// @Success 200 {object} object{success=bool,data=models.ApplicationDocument}
+// @Failure 400 {object} object{error=string}
+// @Failure 403 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/documents/{document_id}/issued-date [put]
+func (h *DocumentChecklistHandler) SetIssuedDate(c *fiber.Ctx) error {
+	doc, err := loadAccessibleDocument(c, h.applicationRepo)
+	if doc == nil {
+		return err
+	}
+
+	var req models.SetIssuedDateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	issuedDate, err := parseIssuedDate(req.IssuedDate)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if reason, err := editLockReason(h.revisionRepo, uint(doc.ApplicationID), "document", doc.DocumentType); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify application status",
+		})
+	} else if reason != "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": reason,
+		})
+	}
+
+	if err := h.fileRepo.SetIssuedDate(doc.DocumentID, issuedDate); err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Document not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update document",
+		})
+	}
+	doc.IssuedDate = issuedDate
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    doc,
+	})
+}
@@ -2,10 +2,8 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -15,12 +13,16 @@ import (
 	"scholarship-system/internal/database"
 	"scholarship-system/internal/models"
 	"scholarship-system/internal/repository"
+	"scholarship-system/internal/storage"
 )
 
 type DocumentEnhancedHandler struct {
 	cfg             *config.Config
 	applicationRepo *repository.ApplicationRepository
 	userRepo        *repository.UserRepository
+	revisionRepo    *repository.RevisionRepository
+	fileRepo        *repository.FileRepository
+	requirementRepo *repository.DocumentRequirementRepository
 }
 
 func NewDocumentEnhancedHandler(cfg *config.Config) *DocumentEnhancedHandler {
@@ -28,6 +30,9 @@ func NewDocumentEnhancedHandler(cfg *config.Config) *DocumentEnhancedHandler {
 		cfg:             cfg,
 		applicationRepo: repository.NewApplicationRepository(),
 		userRepo:        repository.NewUserRepository(),
+		revisionRepo:    repository.NewRevisionRepository(),
+		fileRepo:        repository.NewFileRepository(database.DB),
+		requirementRepo: repository.NewDocumentRequirementRepository(),
 	}
 }
 
@@ -41,6 +46,8 @@ func NewDocumentEnhancedHandler(cfg *config.Config) *DocumentEnhancedHandler {
 // @Param id path int true "Application ID"
 // @Param document_type formData string true "Document type (id_card, transcript, income_certificate, house_registration, etc.)"
 // @Param file formData file true "Document file (PDF, JPEG, PNG - max 10MB)"
+// @Param replace_document_id formData int false "Document to replace with a new version (single-file types are replaced automatically)"
+// @Param issued_date formData string false "Date the document was issued (YYYY-MM-DD), needed for documents the scholarship only accepts within a validity period"
 // @Success 200 {object} object{success=bool,message=string,data=object}
 // @Failure 400 {object} object{error=string}
 // @Failure 401 {object} object{error=string}
@@ -95,69 +102,99 @@ func (h *DocumentEnhancedHandler) UploadDocumentEnhanced(c *fiber.Ctx) error {
 		})
 	}
 
-	// Get uploaded file
-	file, err := c.FormFile("file")
+	// Only flagged document types accept uploads while the application is
+	// sent back for revision
+	if reason, err := editLockReason(h.revisionRepo, uint(applicationID), "document", documentType); err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Application not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify application status",
+		})
+	} else if reason != "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": reason,
+		})
+	}
+
+	replaceID, err := replaceDocumentID(c)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "No file uploaded",
+			"error": "Invalid replace_document_id",
 		})
 	}
 
-	// Validate file size based on document type
-	maxSize := h.getMaxFileSize(documentType)
-	if file.Size > maxSize {
+	// Get uploaded file
+	file, err := c.FormFile("file")
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("File size exceeds %dMB limit", maxSize/(1024*1024)),
+			"error": "No file uploaded",
 		})
 	}
 
-	// Validate file type
-	mimeType := file.Header.Get("Content-Type")
-	if !h.isAllowedMimeType(mimeType) {
+	issuedDate, err := parseIssuedDate(c.FormValue("issued_date"))
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid file type. Allowed: PDF, JPEG, PNG",
+			"error": err.Error(),
 		})
 	}
-
-	// Create upload directory if not exists
-	uploadDir := filepath.Join("./uploads", "applications", fmt.Sprintf("%d", applicationID))
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+	requirement, err := loadDocumentRequirement(h.requirementRepo, uint(applicationID), documentType)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create upload directory",
+			"error": "Failed to load document requirements",
 		})
 	}
 
-	// Generate unique filename
-	ext := filepath.Ext(file.Filename)
-	uniqueFilename := fmt.Sprintf("%s_%d%s", documentType, time.Now().Unix(), ext)
-	filePath := filepath.Join(uploadDir, uniqueFilename)
-
-	// Save file
-	if err := c.SaveFile(file, filePath); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save file",
-		})
+	// Store the file in quarantine until scanned; size and format limits
+	// depend on the document type and the scholarship's requirements
+	stored, err := storage.SaveUpload(c.Context(), file, fmt.Sprintf("applications/%d", applicationID), documentType,
+		documentUploadPolicy(h.cfg, requirement, documentType, documentTypes, "PDF, JPEG, PNG"))
+	if err != nil {
+		return uploadFailed(c, err)
 	}
 
-	// Save document metadata to database
+	// Save document metadata to database, as a new version when the upload
+	// replaces an existing document
 	doc := &models.ApplicationDocument{
-		ApplicationID: applicationID,
-		DocumentType:  documentType,
-		DocumentName:  file.Filename,
-		FilePath:      filePath,
-		FileSize:      file.Size,
-		MimeType:      mimeType,
-		UploadStatus:  "pending",
-		UploadedAt:    time.Now(),
+		ApplicationID:  applicationID,
+		DocumentType:   documentType,
+		DocumentName:   stored.OriginalName,
+		FilePath:       stored.Key,
+		StorageBackend: stored.Backend,
+		FileSize:       stored.Size,
+		MimeType:       stored.ContentType,
+		FileHash:       stored.SHA256,
+		UploadStatus:   models.DocumentQuarantined,
+		IssuedDate:     issuedDate,
+		UploadedAt:     time.Now(),
+		UploadedBy:     &userID,
 	}
 
-	if err := h.applicationRepo.AddDocument(doc); err != nil {
+	replaced, err := saveApplicationDocument(h.fileRepo, h.applicationRepo, doc, replaceID, requirement)
+	if err != nil {
 		// Delete uploaded file if database insert fails
-		os.Remove(filePath)
+		removeStoredFile(c, stored.Backend, stored.Key)
+		var uploadErr *storage.UploadError
+		if errors.As(err, &uploadErr) {
+			return uploadFailed(c, err)
+		}
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Document to replace not found",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to save document metadata",
 		})
 	}
+	var replacedVersion *int
+	if replaced != nil {
+		replacedVersion = &replaced.VersionNumber
+	}
+
+	queueDocumentScan()
 
 	return c.JSON(fiber.Map{
 		"success": true,
@@ -166,11 +203,13 @@ func (h *DocumentEnhancedHandler) UploadDocumentEnhanced(c *fiber.Ctx) error {
 			"document_id":         doc.DocumentID,
 			"document_type":       doc.DocumentType,
 			"file_name":          doc.DocumentName,
-			"file_path":          filePath,
+			"file_path":          doc.FilePath,
 			"file_size":          doc.FileSize,
 			"mime_type":          doc.MimeType,
 			"verification_status": doc.UploadStatus,
 			"uploaded_at":        doc.UploadedAt,
+			"version_number":      doc.VersionNumber,
+			"replaced_version":    replacedVersion,
 		},
 	})
 }
@@ -222,7 +261,8 @@ func (h *DocumentEnhancedHandler) DeleteDocument(c *fiber.Ctx) error {
 	var filePath string
 	query := `
 		SELECT d.document_id, d.application_id, d.document_type, d.document_name,
-		       d.file_path, d.file_size, d.mime_type, d.upload_status
+		       d.file_path, d.storage_backend, d.file_size, d.mime_type, d.upload_status,
+		       COALESCE(d.preview_path, ''), COALESCE(d.preview_backend, '')
 		FROM application_documents d
 		WHERE d.document_id = $1
 	`
@@ -233,9 +273,12 @@ func (h *DocumentEnhancedHandler) DeleteDocument(c *fiber.Ctx) error {
 		&doc.DocumentType,
 		&doc.DocumentName,
 		&filePath,
+		&doc.StorageBackend,
 		&doc.FileSize,
 		&doc.MimeType,
 		&doc.UploadStatus,
+		&doc.PreviewPath,
+		&doc.PreviewBackend,
 	)
 
 	if err != nil {
@@ -256,26 +299,44 @@ func (h *DocumentEnhancedHandler) DeleteDocument(c *fiber.Ctx) error {
 		})
 	}
 
-	// Verify application is still in draft status
-	application, err := h.applicationRepo.GetByID(uint(doc.ApplicationID))
+	// Verify application is still in draft status, or the document type was
+	// flagged for revision
+	status, err := h.revisionRepo.GetApplicationStatus(uint(doc.ApplicationID))
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Application not found",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to verify application status",
 		})
 	}
-
-	if application.ApplicationStatus != "draft" {
+	if status != "draft" && status != "needs_revision" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Cannot delete documents for applications that are not in draft status",
 		})
 	}
 
-	// Delete file from disk
-	if filePath != "" {
-		if err := os.Remove(filePath); err != nil {
-			// Log error but continue with database deletion
-			fmt.Printf("Warning: Failed to delete file %s: %v\n", filePath, err)
-		}
+	reason, err := editLockReason(h.revisionRepo, uint(doc.ApplicationID), "document", doc.DocumentType)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify application status",
+		})
+	}
+
+	if reason != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": reason,
+		})
+	}
+
+	// Earlier versions go with the document
+	versions, err := h.fileRepo.GetDocumentVersions(documentID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve document versions",
+		})
 	}
 
 	// Delete database record
@@ -287,6 +348,13 @@ func (h *DocumentEnhancedHandler) DeleteDocument(c *fiber.Ctx) error {
 		})
 	}
 
+	// Delete the stored files once nothing points at them
+	removeStoredFile(c, doc.StorageBackend, filePath)
+	removeStoredFile(c, doc.PreviewBackend, doc.PreviewPath)
+	for _, version := range versions {
+		removeStoredFile(c, version.StorageBackend, version.FilePath)
+	}
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Document deleted successfully",
@@ -305,6 +373,7 @@ func (h *DocumentEnhancedHandler) DeleteDocument(c *fiber.Ctx) error {
 // @Failure 401 {object} object{error=string}
 // @Failure 403 {object} object{error=string}
 // @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
 // @Router /api/v1/documents/{id}/download [get]
 func (h *DocumentEnhancedHandler) DownloadDocument(c *fiber.Ctx) error {
 	// Get user_id from context
@@ -340,7 +409,7 @@ func (h *DocumentEnhancedHandler) DownloadDocument(c *fiber.Ctx) error {
 	var filePath string
 	query := `
 		SELECT d.document_id, d.application_id, d.document_type, d.document_name,
-		       d.file_path, d.file_size, d.mime_type
+		       d.file_path, d.storage_backend, d.file_size, d.mime_type, d.upload_status, d.version_number
 		FROM application_documents d
 		WHERE d.document_id = $1
 	`
@@ -351,8 +420,11 @@ func (h *DocumentEnhancedHandler) DownloadDocument(c *fiber.Ctx) error {
 		&doc.DocumentType,
 		&doc.DocumentName,
 		&filePath,
+		&doc.StorageBackend,
 		&doc.FileSize,
 		&doc.MimeType,
+		&doc.UploadStatus,
+		&doc.VersionNumber,
 	)
 
 	if err != nil {
@@ -387,49 +459,18 @@ func (h *DocumentEnhancedHandler) DownloadDocument(c *fiber.Ctx) error {
 		}
 	}
 
-	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Document file not found on server",
+	if reason := scanHoldReason(doc.UploadStatus); reason != "" {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": reason,
 		})
 	}
 
-	// Set headers for download
-	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", doc.DocumentName))
-	c.Set("Content-Type", doc.MimeType)
-
-	// Send file
-	return c.SendFile(filePath)
+	logAccess(c, h.fileRepo, userID, &doc, doc.VersionNumber, models.FileAccessDownload, models.AccessMethodBearer)
+	return sendStoredFile(c, doc.StorageBackend, filePath, doc.DocumentName, doc.MimeType)
 }
 
 // Helper functions
 
-func (h *DocumentEnhancedHandler) getMaxFileSize(documentType string) int64 {
-	// Default 10MB
-	maxSize := int64(10 * 1024 * 1024)
-
-	// Specific limits for different document types
-	switch documentType {
-	case "id_card", "transcript", "income_certificate":
-		maxSize = int64(5 * 1024 * 1024) // 5MB
-	case "house_photos", "living_situation_photos":
-		maxSize = int64(20 * 1024 * 1024) // 20MB
-	}
-
-	return maxSize
-}
-
-func (h *DocumentEnhancedHandler) isAllowedMimeType(mimeType string) bool {
-	allowedTypes := map[string]bool{
-		"application/pdf": true,
-		"image/jpeg":      true,
-		"image/jpg":       true,
-		"image/png":       true,
-	}
-
-	return allowedTypes[strings.ToLower(mimeType)]
-}
-
 func (h *DocumentEnhancedHandler) verifyApplicationOwnership(applicationID uint, userID uuid.UUID) error {
 	application, err := h.applicationRepo.GetByID(applicationID)
 	if err != nil {
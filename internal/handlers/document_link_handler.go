@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+)
+
+// logDocumentAccess records who opened a document and how. Failures are
+// logged only so a broken audit insert never blocks a download.
+func logDocumentAccess(c *fiber.Ctx, fileRepo *repository.FileRepository, doc *models.ApplicationDocument,
+	version int, action, method string) {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return
+	}
+	logAccess(c, fileRepo, userID, doc, version, action, method)
+}
+
+func logAccess(c *fiber.Ctx, fileRepo *repository.FileRepository, userID uuid.UUID, doc *models.ApplicationDocument,
+	version int, action, method string) {
+	ip := c.IP()
+	userAgent := c.Get("User-Agent")
+	entry := &models.FileAccessLog{
+		UserID:        userID,
+		Action:        action,
+		IPAddress:     &ip,
+		Success:       true,
+		DocumentID:    &doc.DocumentID,
+		ApplicationID: &doc.ApplicationID,
+		DocumentType:  doc.DocumentType,
+		VersionNumber: &version,
+		AccessMethod:  method,
+	}
+	if userAgent != "" {
+		entry.UserAgent = &userAgent
+	}
+	if err := fileRepo.LogFileAccess(entry); err != nil {
+		log.Printf("Failed to log access to document %d: %v", doc.DocumentID, err)
+	}
+}
+
+type DocumentLinkHandler struct {
+	cfg             *config.Config
+	fileRepo        *repository.FileRepository
+	applicationRepo *repository.ApplicationRepository
+}
+
+func NewDocumentLinkHandler(cfg *config.Config) *DocumentLinkHandler {
+	return &DocumentLinkHandler{
+		cfg:             cfg,
+		fileRepo:        repository.NewFileRepository(database.DB),
+		applicationRepo: repository.NewApplicationRepository(),
+	}
+}
+
+// secret returns the key download links are signed with
+func (h *DocumentLinkHandler) secret() []byte {
+	return services.DownloadLinkSecret(h.cfg.DownloadURLSecret, h.cfg.JWTSecret)
+}
+
+// CreateLink issues a short-lived signed URL for a document
+// @Summary Create document link
+// @Description Issue a signed URL for the current or an earlier version of a document that works without an Authorization header, e.g. as an iframe src. The link is bound to the requesting user and expires after DOWNLOAD_URL_TTL_SECONDS
+// @Tags Document Management
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param document_id path int true "Document ID"
+// @Param request body object{version=int,inline=bool} false "Version (default: current) and whether to display inline"
+// @Success 200 {object} object{success=bool,data=object{url=string,expires_at=string,version_number=int}}
+// @Failure 400 {object} object{error=string}
+// @Failure 401 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
+// @Router /api/v1/documents/{document_id}/link [post]
+func (h *DocumentLinkHandler) CreateLink(c *fiber.Ctx) error {
+	doc, err := loadAccessibleDocument(c, h.applicationRepo)
+	if doc == nil {
+		return err
+	}
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req struct {
+		Version int  `json:"version"`
+		Inline  bool `json:"inline"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+	if req.Version == 0 {
+		req.Version = doc.VersionNumber
+	}
+
+	version, found, err := findDocumentVersion(h.fileRepo, doc, req.Version)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve document version",
+		})
+	}
+	if !found {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Document version not found",
+		})
+	}
+	if reason := scanHoldReason(version.UploadStatus); reason != "" {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": reason,
+		})
+	}
+
+	ttl := time.Duration(h.cfg.DownloadURLTTLSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+	link := services.DownloadLink{
+		DocumentID:    doc.DocumentID,
+		VersionNumber: version.VersionNumber,
+		UserID:        userID,
+		Inline:        req.Inline,
+		ExpiresAt:     time.Now().Add(ttl).Truncate(time.Second),
+	}
+	linkURL := fmt.Sprintf("/api/v1/files/documents/%d?%s", doc.DocumentID, link.Query(h.secret()).Encode())
+
+	logAccess(c, h.fileRepo, userID, doc, version.VersionNumber, models.FileAccessLinkIssued, models.AccessMethodBearer)
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"url":            linkURL,
+			"expires_at":     link.ExpiresAt,
+			"version_number": version.VersionNumber,
+		},
+	})
+}
+
+// OpenLink serves a document through a signed link
+// @Summary Open document link
+// @Description Stream a document through a signed URL issued by the link endpoint. No Authorization header is needed; the signature binds the URL to one user, document and version until it expires
+// @Tags Document Management
+// @Produce application/octet-stream
+// @Param document_id path int true "Document ID"
+// @Param uid query string true "User the link was issued to"
+// @Param v query int true "Version number"
+// @Param expires query int true "Expiry (Unix seconds)"
+// @Param inline query string false "1 to display in the browser"
+// @Param sig query string true "Signature"
+// @Success 200 {file} binary
+// @Failure 400 {object} object{error=string}
+// @Failure 403 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
+// @Failure 410 {object} object{error=string}
+// @Router /api/v1/files/documents/{document_id} [get]
+func (h *DocumentLinkHandler) OpenLink(c *fiber.Ctx) error {
+	documentID, err := c.ParamsInt("document_id")
+	if err != nil || documentID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid document ID",
+		})
+	}
+
+	query, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Invalid download link",
+		})
+	}
+	link, err := services.VerifyDownloadLink(h.secret(), documentID, query, time.Now())
+	if err == services.ErrDownloadLinkExpired {
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{
+			"error": "Download link has expired",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Invalid download link",
+		})
+	}
+
+	doc, err := h.applicationRepo.GetDocument(documentID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Document not found",
+		})
+	}
+	version, found, err := findDocumentVersion(h.fileRepo, doc, link.VersionNumber)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve document version",
+		})
+	}
+	if !found {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Document version not found",
+		})
+	}
+	if reason := scanHoldReason(version.UploadStatus); reason != "" {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": reason,
+		})
+	}
+
+	action := models.FileAccessDownload
+	if link.Inline {
+		action = models.FileAccessView
+	}
+	logAccess(c, h.fileRepo, link.UserID, doc, version.VersionNumber, action, models.AccessMethodSignedLink)
+
+	name := version.DocumentName
+	if name == "" {
+		name = path.Base(version.FilePath)
+	}
+	return sendStoredFileAs(c, version.StorageBackend, version.FilePath, name, version.MimeType, link.Inline)
+}
+
+// ListAccess lists who viewed or downloaded the documents of an application
+// @Summary List document access
+// @Description List views, downloads and issued links for the documents of an application, newest first, with the user and their roles (Admin/Officer only)
+// @Tags Document Management
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Param document_id query int false "Only this document"
+// @Param document_type query string false "Only this document type"
+// @Param sensitive query bool false "Only sensitive documents (ID card, house registration, income, bank and medical documents)"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 20, max: 100)"
+// @Success 200 {object} object{success=bool,data=[]models.DocumentAccessEntry,pagination=object}
+// @Failure 400 {object} object{error=string}
+// @Failure 401 {object} object{error=string}
+// @Failure 403 {object} object{error=string}
+// @Router /api/v1/admin/applications/{id}/document-access [get]
+func (h *DocumentLinkHandler) ListAccess(c *fiber.Ctx) error {
+	applicationID, err := c.ParamsInt("id")
+	if err != nil || applicationID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid application ID",
+		})
+	}
+
+	filter := models.DocumentAccessFilter{
+		DocumentID:    c.QueryInt("document_id", 0),
+		DocumentType:  c.Query("document_type"),
+		SensitiveOnly: c.QueryBool("sensitive", false),
+		Page:          c.QueryInt("page", 1),
+		Limit:         c.QueryInt("limit", 20),
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	entries, total, err := h.fileRepo.ListDocumentAccess(applicationID, filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve document access log",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    entries,
+		"pagination": fiber.Map{
+			"page":       filter.Page,
+			"limit":      filter.Limit,
+			"total":      total,
+			"totalPages": (total + filter.Limit - 1) / filter.Limit,
+		},
+	})
+}
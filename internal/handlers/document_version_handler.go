@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"path"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+	"scholarship-system/internal/storage"
+)
+
+// multiFileDocumentTypes may have several files attached at once. Uploading
+// another file of any other type replaces the current one with a new version.
+var multiFileDocumentTypes = map[string]bool{
+	"house_photos":            true,
+	"living_situation_photos": true,
+	"activity_certificate":    true,
+	"other":                   true,
+}
+
+// keepsSeveralFiles reports whether a new upload of a document type is added
+// next to the current files. A scholarship requirement that allows or needs
+// more than one file, or exactly one, decides over multiFileDocumentTypes.
+func keepsSeveralFiles(req *models.DocumentRequirement, documentType string) bool {
+	switch {
+	case req != nil && (req.MaxCount > 1 || req.MinCount > 1):
+		return true
+	case req != nil && req.MaxCount == 1:
+		return false
+	}
+	return multiFileDocumentTypes[documentType]
+}
+
+// saveApplicationDocument attaches an uploaded file to an application. When
+// replaceID is set, or the application already has a document of a
+// single-file type, the upload becomes a new version of that document and the
+// replaced version is returned; otherwise a new document is added, unless
+// the application already holds as many as req allows, which is reported as
+// a *storage.UploadError.
+func saveApplicationDocument(fileRepo *repository.FileRepository, applicationRepo *repository.ApplicationRepository,
+	doc *models.ApplicationDocument, replaceID int, req *models.DocumentRequirement) (*models.FileVersion, error) {
+	if replaceID == 0 && !keepsSeveralFiles(req, doc.DocumentType) {
+		currentID, err := fileRepo.FindCurrentDocument(doc.ApplicationID, doc.DocumentType)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		replaceID = currentID
+	}
+	if replaceID != 0 {
+		return fileRepo.ReplaceDocument(replaceID, doc, doc.UploadedBy)
+	}
+
+	if req != nil && req.MaxCount > 0 {
+		count, err := fileRepo.CountDocuments(doc.ApplicationID, doc.DocumentType)
+		if err != nil {
+			return nil, err
+		}
+		if count >= req.MaxCount {
+			return nil, &storage.UploadError{
+				Message: fmt.Sprintf("At most %d files of %s can be uploaded; replace one of them instead", req.MaxCount, doc.DocumentType),
+			}
+		}
+	}
+	return nil, applicationRepo.AddDocument(doc)
+}
+
+// replaceDocumentID reads the optional replace_document_id form field
+func replaceDocumentID(c *fiber.Ctx) (int, error) {
+	value := c.FormValue("replace_document_id")
+	if value == "" {
+		return 0, nil
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid replace_document_id")
+	}
+	return id, nil
+}
+
+type DocumentVersionHandler struct {
+	cfg             *config.Config
+	fileRepo        *repository.FileRepository
+	applicationRepo *repository.ApplicationRepository
+}
+
+func NewDocumentVersionHandler(cfg *config.Config) *DocumentVersionHandler {
+	return &DocumentVersionHandler{
+		cfg:             cfg,
+		fileRepo:        repository.NewFileRepository(database.DB),
+		applicationRepo: repository.NewApplicationRepository(),
+	}
+}
+
+// ListVersions lists every version of a document
+// @Summary List document versions
+// @Description List the current and earlier versions of an application document, newest first, with the verification each version had. Officers see any document; students only their own
+// @Tags Document Management
+// @Produce json
+// @Security BearerAuth
+// @Param document_id path int true "Document ID"
+// @Success 200 {object} object{success=bool,data=[]models.FileVersion}
+// @Failure 400 {object} object{error=string}
+// @Failure 401 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/documents/{document_id}/versions [get]
+func (h *DocumentVersionHandler) ListVersions(c *fiber.Ctx) error {
+	doc, err := loadAccessibleDocument(c, h.applicationRepo)
+	if doc == nil {
+		return err
+	}
+
+	earlier, err := h.fileRepo.GetDocumentVersions(doc.DocumentID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve document versions",
+		})
+	}
+
+	versions := append([]models.FileVersion{services.CurrentDocumentVersion(*doc)}, earlier...)
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    versions,
+	})
+}
+
+// DownloadVersion downloads the file of one version of a document
+// @Summary Download document version
+// @Description Download the file of the current or an earlier version of an application document
+// @Tags Document Management
+// @Produce application/octet-stream
+// @Security BearerAuth
+// @Param document_id path int true "Document ID"
+// @Param version path int true "Version number"
+// @Success 200 {file} binary
+// @Failure 400 {object} object{error=string}
+// @Failure 401 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
+// @Router /api/v1/documents/{document_id}/versions/{version}/download [get]
+func (h *DocumentVersionHandler) DownloadVersion(c *fiber.Ctx) error {
+	doc, err := loadAccessibleDocument(c, h.applicationRepo)
+	if doc == nil {
+		return err
+	}
+
+	number, err := c.ParamsInt("version")
+	if err != nil || number <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid version number",
+		})
+	}
+	version, found, err := findDocumentVersion(h.fileRepo, doc, number)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve document version",
+		})
+	}
+	if !found {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Document version not found",
+		})
+	}
+
+	if reason := scanHoldReason(version.UploadStatus); reason != "" {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": reason,
+		})
+	}
+
+	name := version.DocumentName
+	if name == "" {
+		name = path.Base(version.FilePath)
+	}
+	logDocumentAccess(c, h.fileRepo, doc, version.VersionNumber, models.FileAccessDownload, models.AccessMethodBearer)
+	return sendStoredFile(c, version.StorageBackend, version.FilePath, name, version.MimeType)
+}
+
+// DiffVersions compares two versions of a document
+// @Summary Compare document versions
+// @Description Compare the name, size, type, contents (by SHA-256) and verification of two versions of an application document. Defaults to the previous version against the current one
+// @Tags Document Management
+// @Produce json
+// @Security BearerAuth
+// @Param document_id path int true "Document ID"
+// @Param from query int false "Older version number (default: the one before to)"
+// @Param to query int false "Newer version number (default: current)"
+// @Success 200 {object} object{success=bool,data=models.DocumentVersionDiff}
+// @Failure 400 {object} object{error=string}
+// @Failure 401 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/documents/{document_id}/versions/diff [get]
+func (h *DocumentVersionHandler) DiffVersions(c *fiber.Ctx) error {
+	doc, err := loadAccessibleDocument(c, h.applicationRepo)
+	if doc == nil {
+		return err
+	}
+
+	to := c.QueryInt("to", doc.VersionNumber)
+	from := c.QueryInt("from", to-1)
+	if from <= 0 || to <= 0 || from == to {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Two different version numbers are required",
+		})
+	}
+
+	versions := make([]models.FileVersion, 2)
+	for i, number := range []int{from, to} {
+		version, found, err := findDocumentVersion(h.fileRepo, doc, number)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to retrieve document version",
+			})
+		}
+		if !found {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": fmt.Sprintf("Document version %d not found", number),
+			})
+		}
+		versions[i] = *version
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    services.DiffDocumentVersions(versions[0], versions[1]),
+	})
+}
+
+// findDocumentVersion returns a version of a document, the current one included
+func findDocumentVersion(fileRepo *repository.FileRepository, doc *models.ApplicationDocument, number int) (*models.FileVersion, bool, error) {
+	if number == doc.VersionNumber {
+		current := services.CurrentDocumentVersion(*doc)
+		return &current, true, nil
+	}
+	version, err := fileRepo.GetDocumentVersion(doc.DocumentID, number)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return version, true, nil
+}
+
+// loadAccessibleDocument fetches the document named in the path if the user
+// may see it: officers see any document, students only their own. On failure
+// it returns nil and the response already sent.
+func loadAccessibleDocument(c *fiber.Ctx, applicationRepo *repository.ApplicationRepository) (*models.ApplicationDocument, error) {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return nil, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	documentID, err := c.ParamsInt("document_id")
+	if err != nil || documentID <= 0 {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid document ID",
+		})
+	}
+
+	doc, err := applicationRepo.GetDocument(documentID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Document not found",
+			})
+		}
+		return nil, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch document",
+		})
+	}
+
+	isOfficer := false
+	roles, _ := c.Locals("roles").([]string)
+	for _, role := range roles {
+		if role == "admin" || role == "scholarship_officer" {
+			isOfficer = true
+			break
+		}
+	}
+	if !isOfficer {
+		ownerID, err := applicationRepo.GetApplicantUserID(uint(doc.ApplicationID))
+		if err != nil || ownerID != userID {
+			// Do not reveal documents of other students
+			return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Document not found",
+			})
+		}
+	}
+	return doc, nil
+}
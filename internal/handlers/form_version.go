@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+)
+
+// ifMatchVersion reads the version the client based its edit on from the
+// If-Match header. A missing header or "*" skips the check so older clients
+// keep last-write-wins behaviour.
+func ifMatchVersion(c *fiber.Ctx) (*int, error) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+	value := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 0 {
+		return nil, fmt.Errorf("invalid If-Match header %q", header)
+	}
+	return &version, nil
+}
+
+// setVersionETag returns the version of the saved resource as its ETag
+func setVersionETag(c *fiber.Ctx, version int) {
+	c.Set(fiber.HeaderETag, fmt.Sprintf(`"%d"`, version))
+}
+
+// formSaveError answers a failed form save. Version conflicts get a 409 with
+// the current server state of the section so the client can merge; anything
+// else is a 500 with the given message.
+func formSaveError(c *fiber.Ctx, detailsRepo *repository.ApplicationDetailsRepository, applicationID uint, err error, message string) error {
+	var conflict *repository.VersionConflictError
+	if !errors.As(err, &conflict) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	response := fiber.Map{
+		"error":           "This information was changed in another session. Review the current data and save again.",
+		"section":         conflict.Section,
+		"current_version": conflict.CurrentVersion,
+	}
+	if form, err := detailsRepo.GetCompleteForm(applicationID); err == nil {
+		if current, err := sectionState(form, conflict.Section); err == nil {
+			response["current"] = current
+		}
+	}
+
+	setVersionETag(c, conflict.CurrentVersion)
+	return c.Status(fiber.StatusConflict).JSON(response)
+}
+
+// sectionState picks the parts of a complete form that belong to a section.
+// An empty section returns the whole form.
+func sectionState(form interface{}, section string) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(form)
+	if err != nil {
+		return nil, err
+	}
+	var parts map[string]json.RawMessage
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return nil, err
+	}
+	if section == "" {
+		return parts, nil
+	}
+
+	state := map[string]json.RawMessage{}
+	for key, value := range parts {
+		if services.FormSection(key) == section {
+			state[key] = value
+		}
+	}
+	return state, nil
+}
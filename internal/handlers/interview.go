@@ -1,28 +1,66 @@
 package handlers
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"strconv"
-	"time"
 
 	"scholarship-system/internal/config"
-	"scholarship-system/internal/database"
 	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
+// InterviewHandler serves the original /interviews routes. Schedules,
+// appointments and results are interview slots, bookings and result
+// summaries, so schedule_id is a slot ID and appointment_id a booking ID,
+// the same records InterviewReviewHandler manages under /interview.
 type InterviewHandler struct {
-	cfg *config.Config
+	cfg             *config.Config
+	interviewRepo   *repository.InterviewRepository
+	applicationRepo *repository.ApplicationRepository
 }
 
 func NewInterviewHandler(cfg *config.Config) *InterviewHandler {
-	return &InterviewHandler{cfg: cfg}
+	return &InterviewHandler{
+		cfg:             cfg,
+		interviewRepo:   repository.NewInterviewRepository(),
+		applicationRepo: repository.NewApplicationRepository(),
+	}
+}
+
+// scheduleFromSlot presents a slot in the shape of an old interview schedule
+func scheduleFromSlot(slot models.InterviewSlot) models.InterviewSchedule {
+	schedule := models.InterviewSchedule{
+		ScheduleID:    uint(slot.ID),
+		ScholarshipID: uint(slot.ScholarshipID),
+		InterviewDate: slot.InterviewDate,
+		StartTime:     slot.StartTime,
+		EndTime:       slot.EndTime,
+		MaxApplicants: slot.MaxCapacity,
+		IsActive:      slot.IsAvailable,
+		CreatedAt:     slot.CreatedAt,
+	}
+	if slot.Location != "" {
+		schedule.Location = &slot.Location
+	}
+	if slot.Notes != "" {
+		schedule.Notes = &slot.Notes
+	}
+	if ids, err := json.Marshal([]string{slot.InterviewerID}); err == nil {
+		interviewerIDs := string(ids)
+		schedule.InterviewerIDs = &interviewerIDs
+	}
+	schedule.CreatedBy, _ = uuid.Parse(slot.CreatedBy)
+	return schedule
 }
 
 // CreateSchedule creates new interview schedule
 // @Summary Create interview schedule
-// @Description Create a new interview schedule for scholarship applications (Admin/Officer only)
+// @Description Create an interview slot from an old-style schedule (Admin/Officer only). The first of interviewer_ids becomes the slot's interviewer, or the creator when none is given, and max_applicants its capacity. Prefer POST /api/v1/interview/slots
 // @Tags Interview Management
 // @Accept json
 // @Produce json
@@ -41,27 +79,65 @@ func (h *InterviewHandler) CreateSchedule(c *fiber.Ctx) error {
 		})
 	}
 
-	// Get current user ID from context
-	userIDStr := c.Locals("user_id").(string)
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+	if schedule.ScholarshipID == 0 || schedule.InterviewDate.IsZero() ||
+		!isValidTimeFormat(schedule.StartTime) || !isValidTimeFormat(schedule.EndTime) ||
+		schedule.EndTime <= schedule.StartTime {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid user ID format",
+			"error": "scholarship_id, interview_date, start_time and end_time (HH:MM) are required",
 		})
 	}
-	schedule.CreatedBy = userID
 
-	query := `INSERT INTO interview_schedules 
-		(scholarship_id, interview_date, start_time, end_time, location, max_applicants, interviewer_ids, notes, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING schedule_id`
+	interviewerID := userID
+	if schedule.InterviewerIDs != nil && *schedule.InterviewerIDs != "" {
+		var ids []string
+		if err := json.Unmarshal([]byte(*schedule.InterviewerIDs), &ids); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "interviewer_ids must be a JSON array of user IDs",
+			})
+		}
+		if len(ids) > 0 {
+			id, err := uuid.Parse(ids[0])
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Invalid interviewer ID format",
+				})
+			}
+			interviewerID = id
+		}
+	}
 
-	err = database.DB.QueryRow(query,
-		schedule.ScholarshipID, schedule.InterviewDate, schedule.StartTime,
-		schedule.EndTime, schedule.Location, schedule.MaxApplicants,
-		schedule.InterviewerIDs, schedule.Notes, schedule.CreatedBy,
-	).Scan(&schedule.ScheduleID)
+	slot := models.InterviewSlot{
+		ScholarshipID:   int(schedule.ScholarshipID),
+		InterviewerID:   interviewerID.String(),
+		InterviewDate:   schedule.InterviewDate,
+		StartTime:       schedule.StartTime,
+		EndTime:         schedule.EndTime,
+		MaxCapacity:     schedule.MaxApplicants,
+		IsAvailable:     true,
+		SlotType:        "individual",
+		DurationMinutes: 30,
+		CreatedBy:       userID.String(),
+	}
+	if slot.MaxCapacity < 1 {
+		slot.MaxCapacity = 1
+	}
+	if slot.MaxCapacity > 1 {
+		slot.SlotType = "group"
+	}
+	if schedule.Location != nil {
+		slot.Location = *schedule.Location
+	}
+	if schedule.Notes != nil {
+		slot.Notes = *schedule.Notes
+	}
 
-	if err != nil {
+	if err := h.interviewRepo.CreateSlot(&slot); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to create interview schedule",
 		})
@@ -69,13 +145,13 @@ func (h *InterviewHandler) CreateSchedule(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "Interview schedule created successfully",
-		"data":    schedule,
+		"data":    scheduleFromSlot(slot),
 	})
 }
 
 // GetSchedules retrieves all interview schedules
 // @Summary Get interview schedules
-// @Description Get list of interview schedules with optional scholarship filter (Admin/Officer only)
+// @Description Get the interview slots open for booking as old-style schedules, with optional scholarship filter (Admin/Officer only). Prefer GET /api/v1/interview/slots
 // @Tags Interview Management
 // @Produce json
 // @Security BearerAuth
@@ -85,41 +161,23 @@ func (h *InterviewHandler) CreateSchedule(c *fiber.Ctx) error {
 // @Failure 403 {object} object{error=string}
 // @Router /interviews/schedules [get]
 func (h *InterviewHandler) GetSchedules(c *fiber.Ctx) error {
-	scholarshipID := c.Query("scholarship_id")
-
-	query := `SELECT schedule_id, scholarship_id, interview_date, start_time, end_time, 
-		location, max_applicants, interviewer_ids, notes, is_active, created_by, created_at
-		FROM interview_schedules WHERE is_active = true`
-
-	args := []interface{}{}
-	if scholarshipID != "" {
-		query += " AND scholarship_id = $1"
-		args = append(args, scholarshipID)
+	scholarshipID, err := strconv.Atoi(c.Query("scholarship_id", "0"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid scholarship ID",
+		})
 	}
 
-	query += " ORDER BY interview_date, start_time"
-
-	rows, err := database.DB.Query(query, args...)
+	slots, err := h.interviewRepo.ListOpenSlots(scholarshipID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch interview schedules",
 		})
 	}
-	defer rows.Close()
-
-	var schedules []models.InterviewSchedule
-	for rows.Next() {
-		var schedule models.InterviewSchedule
-		err := rows.Scan(
-			&schedule.ScheduleID, &schedule.ScholarshipID, &schedule.InterviewDate,
-			&schedule.StartTime, &schedule.EndTime, &schedule.Location,
-			&schedule.MaxApplicants, &schedule.InterviewerIDs, &schedule.Notes,
-			&schedule.IsActive, &schedule.CreatedBy, &schedule.CreatedAt,
-		)
-		if err != nil {
-			continue
-		}
-		schedules = append(schedules, schedule)
+
+	schedules := make([]models.InterviewSchedule, 0, len(slots))
+	for _, slot := range slots {
+		schedules = append(schedules, scheduleFromSlot(slot))
 	}
 
 	return c.JSON(fiber.Map{
@@ -129,43 +187,65 @@ func (h *InterviewHandler) GetSchedules(c *fiber.Ctx) error {
 
 // BookInterview allows students to book interview slots
 // @Summary Book interview slot
-// @Description Allow student to book an interview appointment for their application
+// @Description Allow student to book an interview slot for their application. The booking counts against the slot's capacity like POST /api/v1/interview/book
 // @Tags Interview Booking
 // @Produce json
 // @Security BearerAuth
 // @Param application_id path string true "Application ID"
-// @Param schedule_id path string true "Schedule ID"
+// @Param schedule_id path string true "Interview slot ID"
 // @Success 200 {object} object{message=string,appointment_id=int}
+// @Failure 400 {object} object{error=string}
 // @Failure 401 {object} object{error=string}
 // @Failure 403 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
 // @Router /interviews/applications/{application_id}/schedules/{schedule_id}/book [post]
 func (h *InterviewHandler) BookInterview(c *fiber.Ctx) error {
-	applicationID := c.Params("application_id")
-	scheduleID := c.Params("schedule_id")
-
-	// Check if application belongs to current user
-	userID := c.Locals("user_id").(string)
+	applicationID, err := strconv.Atoi(c.Params("application_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid application ID",
+		})
+	}
+	slotID, err := strconv.Atoi(c.Params("schedule_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid schedule ID",
+		})
+	}
 
-	var studentID string
-	checkQuery := `SELECT s.student_id FROM scholarship_applications sa
-		JOIN students s ON sa.student_id = s.student_id
-		JOIN users u ON s.user_id = u.user_id
-		WHERE sa.application_id = $1 AND u.user_id = $2`
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
 
-	err := database.DB.QueryRow(checkQuery, applicationID, userID).Scan(&studentID)
-	if err != nil {
+	ownerID, err := h.applicationRepo.GetApplicantUserID(uint(applicationID))
+	if err != nil || ownerID != userID {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 			"error": "Application not found or access denied",
 		})
 	}
 
-	// Insert appointment
-	query := `INSERT INTO interview_appointments (application_id, schedule_id)
-		VALUES ($1, $2) RETURNING appointment_id`
-
-	var appointmentID int
-	err = database.DB.QueryRow(query, applicationID, scheduleID).Scan(&appointmentID)
-	if err != nil {
+	bookingID, err := h.interviewRepo.BookSlot(slotID, applicationID, userID, "")
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Interview schedule not found",
+		})
+	case errors.Is(err, repository.ErrSlotScholarship):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Interview schedule is for another scholarship",
+		})
+	case errors.Is(err, repository.ErrSlotUnavailable):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Interview schedule is full or closed",
+		})
+	case errors.Is(err, repository.ErrAlreadyBooked):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Application already has an interview booked",
+		})
+	case err != nil:
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to book interview",
 		})
@@ -173,51 +253,47 @@ func (h *InterviewHandler) BookInterview(c *fiber.Ctx) error {
 
 	return c.JSON(fiber.Map{
 		"message":        "Interview booked successfully",
-		"appointment_id": appointmentID,
+		"appointment_id": bookingID,
 	})
 }
 
 // ConfirmInterview allows students to confirm their interview
 // @Summary Confirm interview appointment
-// @Description Allow student to confirm their scheduled interview appointment
+// @Description Allow student to confirm their interview booking
 // @Tags Interview Booking
 // @Produce json
 // @Security BearerAuth
-// @Param appointment_id path string true "Appointment ID"
+// @Param appointment_id path string true "Booking ID"
 // @Success 200 {object} object{message=string}
 // @Failure 401 {object} object{error=string}
 // @Failure 403 {object} object{error=string}
 // @Router /interviews/appointments/{appointment_id}/confirm [post]
 func (h *InterviewHandler) ConfirmInterview(c *fiber.Ctx) error {
-	appointmentID := c.Params("appointment_id")
-	userID := c.Locals("user_id").(string)
-
-	// Check if appointment belongs to current user
-	checkQuery := `SELECT ia.appointment_id FROM interview_appointments ia
-		JOIN scholarship_applications sa ON ia.application_id = sa.application_id
-		JOIN students s ON sa.student_id = s.student_id
-		JOIN users u ON s.user_id = u.user_id
-		WHERE ia.appointment_id = $1 AND u.user_id = $2`
-
-	var id int
-	err := database.DB.QueryRow(checkQuery, appointmentID, userID).Scan(&id)
+	bookingID, err := strconv.Atoi(c.Params("appointment_id"))
 	if err != nil {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Appointment not found or access denied",
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid appointment ID format",
 		})
 	}
 
-	// Update confirmation
-	updateQuery := `UPDATE interview_appointments 
-		SET student_confirmed = true, confirmation_date = CURRENT_TIMESTAMP
-		WHERE appointment_id = $1`
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
 
-	_, err = database.DB.Exec(updateQuery, appointmentID)
+	confirmed, err := h.interviewRepo.ConfirmStudentBooking(bookingID, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to confirm interview",
 		})
 	}
+	if !confirmed {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Appointment not found or access denied",
+		})
+	}
 
 	return c.JSON(fiber.Map{
 		"message": "Interview confirmed successfully",
@@ -226,20 +302,26 @@ func (h *InterviewHandler) ConfirmInterview(c *fiber.Ctx) error {
 
 // SubmitInterviewResult allows interviewers to submit results
 // @Summary Submit interview result
-// @Description Submit interview evaluation and scoring (Interviewer/Admin/Officer only)
+// @Description Submit the evaluation of an interview booking and mark it completed (Interviewer/Admin/Officer only). overall_score is out of 100; scores, when given, is a JSON object of score per criterion
 // @Tags Interview Results
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param appointment_id path string true "Appointment ID"
+// @Param appointment_id path string true "Booking ID"
 // @Param result body models.InterviewResult true "Interview result data"
 // @Success 200 {object} object{message=string,data=object}
 // @Failure 400 {object} object{error=string}
 // @Failure 401 {object} object{error=string}
 // @Failure 403 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
 // @Router /interviews/appointments/{appointment_id}/result [post]
 func (h *InterviewHandler) SubmitInterviewResult(c *fiber.Ctx) error {
-	appointmentID := c.Params("appointment_id")
+	bookingID, err := strconv.Atoi(c.Params("appointment_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid appointment ID format",
+		})
+	}
 
 	var result models.InterviewResult
 	if err := c.BodyParser(&result); err != nil {
@@ -247,46 +329,41 @@ func (h *InterviewHandler) SubmitInterviewResult(c *fiber.Ctx) error {
 			"error": "Invalid request body",
 		})
 	}
-
-	// Get interviewer ID from context and convert to UUID
-	userIDStr := c.Locals("user_id").(string)
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
+	if result.OverallScore == nil || *result.OverallScore < 0 || *result.OverallScore > 100 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid user ID format",
+			"error": "overall_score between 0 and 100 is required",
 		})
 	}
-	result.InterviewerID = userID
-	appointmentIDInt, err := strconv.ParseUint(appointmentID, 10, 32)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid appointment ID format",
-		})
+	scores := map[string]float64{}
+	if result.Scores != nil && *result.Scores != "" {
+		if err := json.Unmarshal([]byte(*result.Scores), &scores); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "scores must be a JSON object of score per criterion",
+			})
+		}
 	}
-	result.AppointmentID = uint(appointmentIDInt)
-
-	query := `INSERT INTO interview_results 
-		(appointment_id, interviewer_id, scores, overall_score, comments, recommendation, interview_notes)
-		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING result_id`
 
-	err = database.DB.QueryRow(query,
-		result.AppointmentID, result.InterviewerID, result.Scores,
-		result.OverallScore, result.Comments, result.Recommendation, result.InterviewNotes,
-	).Scan(&result.ResultID)
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+	result.InterviewerID = userID
+	result.AppointmentID = uint(bookingID)
 
+	err = h.interviewRepo.SubmitResult(bookingID, &result, scores)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Appointment not found",
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to submit interview result",
 		})
 	}
 
-	// Update appointment status
-	updateQuery := `UPDATE interview_appointments 
-		SET appointment_status = 'completed', actual_end_time = CURRENT_TIMESTAMP
-		WHERE appointment_id = $1`
-
-	database.DB.Exec(updateQuery, appointmentID)
-
 	return c.JSON(fiber.Map{
 		"message": "Interview result submitted successfully",
 		"data":    result,
@@ -295,125 +372,40 @@ func (h *InterviewHandler) SubmitInterviewResult(c *fiber.Ctx) error {
 
 // GetMyInterviews retrieves interviews for current user (student or interviewer)
 // @Summary Get my interviews
-// @Description Get interview appointments for current user (different view for students vs interviewers)
+// @Description Get interview bookings for current user: a student's own bookings, or the bookings in an interviewer's slots with student names
 // @Tags Interview Management
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} object{data=[]object}
+// @Success 200 {object} object{data=[]models.InterviewAppointmentView}
 // @Failure 401 {object} object{error=string}
 // @Router /interviews/my [get]
 func (h *InterviewHandler) GetMyInterviews(c *fiber.Ctx) error {
-	userID := c.Locals("user_id").(string)
-	role := c.Locals("user_role").(string)
-
-	var query string
-	var args []interface{}
-
-	if role == "student" {
-		query = `SELECT ia.appointment_id, ia.application_id, ia.schedule_id, 
-			ia.appointment_status, ia.student_confirmed, ia.confirmation_date,
-			isch.interview_date, isch.start_time, isch.end_time, isch.location,
-			s.scholarship_name
-			FROM interview_appointments ia
-			JOIN interview_schedules isch ON ia.schedule_id = isch.schedule_id
-			JOIN scholarship_applications sa ON ia.application_id = sa.application_id
-			JOIN scholarships s ON isch.scholarship_id = s.scholarship_id
-			JOIN students st ON sa.student_id = st.student_id
-			WHERE st.user_id = $1
-			ORDER BY isch.interview_date, isch.start_time`
-		args = []interface{}{userID}
-	} else {
-		// For interviewers
-		query = `SELECT ia.appointment_id, ia.application_id, ia.schedule_id,
-			ia.appointment_status, ia.student_confirmed,
-			isch.interview_date, isch.start_time, isch.end_time, isch.location,
-			s.scholarship_name, u.first_name, u.last_name
-			FROM interview_appointments ia
-			JOIN interview_schedules isch ON ia.schedule_id = isch.schedule_id
-			JOIN scholarships s ON isch.scholarship_id = s.scholarship_id
-			JOIN scholarship_applications sa ON ia.application_id = sa.application_id
-			JOIN students st ON sa.student_id = st.student_id
-			JOIN users u ON st.user_id = u.user_id
-			WHERE JSON_EXTRACT(isch.interviewer_ids, '$[*]') LIKE CONCAT('%', $1, '%')
-			ORDER BY isch.interview_date, isch.start_time`
-		args = []interface{}{userID}
-	}
-
-	rows, err := database.DB.Query(query, args...)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch interviews",
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
 		})
 	}
-	defer rows.Close()
-
-	var interviews []map[string]interface{}
-	for rows.Next() {
-		interview := make(map[string]interface{})
-		var cols []interface{}
-
+	isStudent := false
+	roles, _ := c.Locals("roles").([]string)
+	for _, role := range roles {
 		if role == "student" {
-			var appointmentID, applicationID, scheduleID int
-			var status string
-			var confirmed bool
-			var confirmationDate *time.Time
-			var interviewDate time.Time
-			var startTime, endTime, location, scholarshipName string
-
-			cols = []interface{}{
-				&appointmentID, &applicationID, &scheduleID,
-				&status, &confirmed, &confirmationDate,
-				&interviewDate, &startTime, &endTime, &location,
-				&scholarshipName,
-			}
-
-			if err := rows.Scan(cols...); err != nil {
-				continue
-			}
-
-			interview["appointment_id"] = appointmentID
-			interview["application_id"] = applicationID
-			interview["schedule_id"] = scheduleID
-			interview["status"] = status
-			interview["confirmed"] = confirmed
-			interview["confirmation_date"] = confirmationDate
-			interview["interview_date"] = interviewDate
-			interview["start_time"] = startTime
-			interview["end_time"] = endTime
-			interview["location"] = location
-			interview["scholarship_name"] = scholarshipName
-		} else {
-			var appointmentID, applicationID, scheduleID int
-			var status string
-			var confirmed bool
-			var interviewDate time.Time
-			var startTime, endTime, location, scholarshipName, firstName, lastName string
-
-			cols = []interface{}{
-				&appointmentID, &applicationID, &scheduleID,
-				&status, &confirmed,
-				&interviewDate, &startTime, &endTime, &location,
-				&scholarshipName, &firstName, &lastName,
-			}
-
-			if err := rows.Scan(cols...); err != nil {
-				continue
-			}
-
-			interview["appointment_id"] = appointmentID
-			interview["application_id"] = applicationID
-			interview["schedule_id"] = scheduleID
-			interview["status"] = status
-			interview["confirmed"] = confirmed
-			interview["interview_date"] = interviewDate
-			interview["start_time"] = startTime
-			interview["end_time"] = endTime
-			interview["location"] = location
-			interview["scholarship_name"] = scholarshipName
-			interview["student_name"] = firstName + " " + lastName
+			isStudent = true
+			break
 		}
+	}
 
-		interviews = append(interviews, interview)
+	var interviews []models.InterviewAppointmentView
+	var err error
+	if isStudent {
+		interviews, err = h.interviewRepo.ListStudentAppointments(userID)
+	} else {
+		interviews, err = h.interviewRepo.ListInterviewerAppointments(userID)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch interviews",
+		})
 	}
 
 	return c.JSON(fiber.Map{
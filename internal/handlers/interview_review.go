@@ -11,17 +11,19 @@ import (
 	"scholarship-system/internal/config"
 	"scholarship-system/internal/database"
 	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
 type InterviewReviewHandler struct {
-	db *sql.DB
+	db            *sql.DB
+	interviewRepo *repository.InterviewRepository
 }
 
 func NewInterviewReviewHandler(cfg *config.Config) *InterviewReviewHandler {
-	return &InterviewReviewHandler{db: database.DB}
+	return &InterviewReviewHandler{db: database.DB, interviewRepo: repository.NewInterviewRepository()}
 }
 
 // ==================== INTERVIEW SLOT MANAGEMENT ====================
@@ -662,37 +664,20 @@ func (h *InterviewReviewHandler) BookInterviewSlot(c *fiber.Ctx) error {
 		})
 	}
 
-	// Check if student already has an active booking for this application
-	var existingBooking int
-	err = h.db.QueryRow(`
-		SELECT COUNT(*) FROM interview_bookings 
-		WHERE application_id = $1 AND booking_status IN ('booked', 'confirmed')
-	`, applicationID).Scan(&existingBooking)
-
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+	// Create booking and take a seat in the slot
+	bookingID, err := h.interviewRepo.BookSlot(req.SlotID, applicationID, userUUID, req.StudentNotes)
+	if err == repository.ErrAlreadyBooked {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"message": "เกิดข้อผิดพลาด",
+			"message": "คุณมีการจองสัมภาษณ์สำหรับทุนนี้แล้ว",
 		})
 	}
-
-	if existingBooking > 0 {
+	if err == repository.ErrSlotUnavailable {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"message": "คุณมีการจองสัมภาษณ์สำหรับทุนนี้แล้ว",
+			"message": "ช่วงเวลานี้ไม่สามารถจองได้",
 		})
 	}
-
-	// Create booking
-	insertQuery := `
-		INSERT INTO interview_bookings (
-			slot_id, application_id, student_id, student_notes
-		) VALUES ($1, $2, $3, $4)
-		RETURNING id
-	`
-
-	var bookingID int
-	err = h.db.QueryRow(insertQuery, req.SlotID, applicationID, studentID, req.StudentNotes).Scan(&bookingID)
 	if err != nil {
 		log.Printf("Error creating booking: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -1166,17 +1151,14 @@ func (h *InterviewReviewHandler) CancelBooking(c *fiber.Ctx) error {
 		})
 	}
 
-	// Update booking to cancelled
-	updateQuery := `
-		UPDATE interview_bookings
-		SET booking_status = 'cancelled',
-		    cancelled_at = CURRENT_TIMESTAMP,
-		    cancellation_reason = $1,
-		    updated_at = CURRENT_TIMESTAMP
-		WHERE id = $2
-	`
-
-	_, err = h.db.Exec(updateQuery, req.CancellationReason, bookingID)
+	// Cancel booking and free its seat
+	err = h.interviewRepo.CancelBooking(bookingID, req.CancellationReason)
+	if err == repository.ErrBookingCancelled {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "การจองนี้ถูกยกเลิกแล้ว",
+		})
+	}
 	if err != nil {
 		log.Printf("Error cancelling booking: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -1185,9 +1167,6 @@ func (h *InterviewReviewHandler) CancelBooking(c *fiber.Ctx) error {
 		})
 	}
 
-	// Update slot availability
-	h.db.Exec("UPDATE interview_slots SET current_bookings = current_bookings - 1 WHERE id = $1", booking.SlotID)
-
 	// Fetch updated booking
 	cancelledBooking, err := h.getInterviewBookingByID(bookingID)
 	if err != nil {
@@ -1237,7 +1216,7 @@ func (h *InterviewReviewHandler) RescheduleBooking(c *fiber.Ctx) error {
 	}
 
 	// Check if booking exists
-	booking, err := h.getInterviewBookingByID(bookingID)
+	_, err = h.getInterviewBookingByID(bookingID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -1273,59 +1252,31 @@ func (h *InterviewReviewHandler) RescheduleBooking(c *fiber.Ctx) error {
 		})
 	}
 
-	// Begin transaction
-	tx, err := h.db.Begin()
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+	// Move booking: take a seat in the new slot and free the old one
+	err = h.interviewRepo.RescheduleBooking(bookingID, req.NewSlotID)
+	if err == repository.ErrBookingClosed {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"message": "เกิดข้อผิดพลาด",
+			"message": "ไม่สามารถเปลี่ยนนัดการจองที่ถูกยกเลิกหรือสัมภาษณ์แล้วได้",
 		})
 	}
-	defer tx.Rollback()
-
-	// Update booking
-	updateQuery := `
-		UPDATE interview_bookings
-		SET slot_id = $1,
-		    rescheduled_from_slot_id = $2,
-		    rescheduled_to_slot_id = $1,
-		    booking_status = 'booked',
-		    confirmed_at = NULL,
-		    updated_at = CURRENT_TIMESTAMP
-		WHERE id = $3
-	`
-	_, err = tx.Exec(updateQuery, req.NewSlotID, booking.SlotID, bookingID)
-	if err != nil {
-		log.Printf("Error rescheduling booking: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+	if err == repository.ErrSlotUnavailable {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"message": "เกิดข้อผิดพลาดในการเปลี่ยนนัด",
+			"message": "ช่วงเวลาใหม่ไม่สามารถจองได้",
 		})
 	}
-
-	// Update old slot bookings count
-	_, err = tx.Exec("UPDATE interview_slots SET current_bookings = current_bookings - 1 WHERE id = $1", booking.SlotID)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+	if err == repository.ErrSlotScholarship {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"message": "เกิดข้อผิดพลาด",
+			"message": "ช่วงเวลาใหม่เป็นของทุนอื่น",
 		})
 	}
-
-	// Update new slot bookings count
-	_, err = tx.Exec("UPDATE interview_slots SET current_bookings = current_bookings + 1 WHERE id = $1", req.NewSlotID)
 	if err != nil {
+		log.Printf("Error rescheduling booking: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "เกิดข้อผิดพลาด",
-		})
-	}
-
-	// Commit transaction
-	if err = tx.Commit(); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "เกิดข้อผิดพลาด",
+			"message": "เกิดข้อผิดพลาดในการเปลี่ยนนัด",
 		})
 	}
 
@@ -1592,64 +1543,30 @@ func (h *InterviewReviewHandler) CheckOutBooking(c *fiber.Ctx) error {
 }
 
 // @Summary Get Interview Statistics
-// @Description Get statistics about interviews
+// @Description Get counts of interview slots and bookings, including those migrated from the old interview schedules; the dashboard's pending interviews come from the same figures
 // @Tags Interview Management
 // @Accept json
 // @Produce json
 // @Param scholarship_id query int false "Scholarship ID"
 // @Param date_from query string false "Start date (YYYY-MM-DD)"
 // @Param date_to query string false "End date (YYYY-MM-DD)"
-// @Success 200 {object} models.InterviewStatisticsResponse
+// @Success 200 {object} object{success=bool,data=models.InterviewBookingStats}
 // @Failure 500 {object} ErrorResponse
 // @Router /api/interview/statistics [get]
 func (h *InterviewReviewHandler) GetStatistics(c *fiber.Ctx) error {
-	scholarshipID := c.Query("scholarship_id")
-	dateFrom := c.Query("date_from", time.Now().AddDate(0, -1, 0).Format("2006-01-02"))
-	dateTo := c.Query("date_to", time.Now().Format("2006-01-02"))
-
-	whereConditions := []string{"is.interview_date BETWEEN $1 AND $2"}
-	args := []interface{}{dateFrom, dateTo}
-
-	if scholarshipID != "" {
-		args = append(args, scholarshipID)
-		whereConditions = append(whereConditions, fmt.Sprintf("is.scholarship_id = $%d", len(args)))
+	scholarshipID, err := strconv.Atoi(c.Query("scholarship_id", "0"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "รหัสทุนการศึกษาไม่ถูกต้อง",
+		})
 	}
 
-	whereClause := strings.Join(whereConditions, " AND ")
-
-	// Get overall statistics
-	statsQuery := fmt.Sprintf(`
-		SELECT
-			COUNT(DISTINCT is.id) as total_slots,
-			COUNT(DISTINCT CASE WHEN is.is_available = true THEN is.id END) as available_slots,
-			COUNT(DISTINCT ib.id) as total_bookings,
-			COUNT(DISTINCT CASE WHEN ib.booking_status = 'confirmed' THEN ib.id END) as confirmed_bookings,
-			COUNT(DISTINCT CASE WHEN ib.booking_status = 'cancelled' THEN ib.id END) as cancelled_bookings,
-			COUNT(DISTINCT CASE WHEN ib.booking_status = 'completed' THEN ib.id END) as completed_bookings,
-			COUNT(DISTINCT CASE WHEN ib.check_in_time IS NOT NULL THEN ib.id END) as checked_in,
-			AVG(CASE WHEN ib.actual_duration_minutes IS NOT NULL THEN ib.actual_duration_minutes END) as avg_duration
-		FROM interview_slots is
-		LEFT JOIN interview_bookings ib ON is.id = ib.slot_id
-		WHERE %s
-	`, whereClause)
-
-	var stats struct {
-		TotalSlots        int
-		AvailableSlots    int
-		TotalBookings     int
-		ConfirmedBookings int
-		CancelledBookings int
-		CompletedBookings int
-		CheckedIn         int
-		AvgDuration       sql.NullFloat64
-	}
-
-	err := h.db.QueryRow(statsQuery, args...).Scan(
-		&stats.TotalSlots, &stats.AvailableSlots, &stats.TotalBookings,
-		&stats.ConfirmedBookings, &stats.CancelledBookings, &stats.CompletedBookings,
-		&stats.CheckedIn, &stats.AvgDuration,
-	)
-
+	stats, err := h.interviewRepo.Statistics(models.InterviewStatsFilter{
+		ScholarshipID: scholarshipID,
+		DateFrom:      c.Query("date_from", time.Now().AddDate(0, -1, 0).Format("2006-01-02")),
+		DateTo:        c.Query("date_to", time.Now().Format("2006-01-02")),
+	})
 	if err != nil {
 		log.Printf("Error fetching statistics: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -1658,30 +1575,9 @@ func (h *InterviewReviewHandler) GetStatistics(c *fiber.Ctx) error {
 		})
 	}
 
-	avgDuration := 0.0
-	if stats.AvgDuration.Valid {
-		avgDuration = stats.AvgDuration.Float64
-	}
-
-	result := map[string]interface{}{
-		"total_slots":        stats.TotalSlots,
-		"available_slots":    stats.AvailableSlots,
-		"total_bookings":     stats.TotalBookings,
-		"confirmed_bookings": stats.ConfirmedBookings,
-		"cancelled_bookings": stats.CancelledBookings,
-		"completed_bookings": stats.CompletedBookings,
-		"checked_in":         stats.CheckedIn,
-		"avg_duration":       avgDuration,
-		"utilization_rate":   0.0,
-	}
-
-	if stats.TotalSlots > 0 {
-		result["utilization_rate"] = float64(stats.TotalBookings) / float64(stats.TotalSlots) * 100
-	}
-
 	return c.JSON(fiber.Map{
 		"success": true,
-		"data":    result,
+		"data":    stats,
 	})
 }
 
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"database/sql"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+)
+
+type NeedAssessmentHandler struct {
+	cfg      *config.Config
+	needRepo *repository.NeedAssessmentRepository
+}
+
+func NewNeedAssessmentHandler(cfg *config.Config) *NeedAssessmentHandler {
+	return &NeedAssessmentHandler{
+		cfg:      cfg,
+		needRepo: repository.NewNeedAssessmentRepository(),
+	}
+}
+
+// GetParameters returns the active need formula parameters
+// @Summary Get need assessment parameters
+// @Description Get the parameters of the household financial need formula currently in use (Admin/Officer only)
+// @Tags Need Assessment
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} object{success=bool,data=models.NeedParameterSet}
+// @Router /api/v1/admin/need-assessment/parameters [get]
+func (h *NeedAssessmentHandler) GetParameters(c *fiber.Ctx) error {
+	set, err := h.needRepo.GetActiveParameters()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve need assessment parameters",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    set,
+	})
+}
+
+// UpdateParameters saves a new version of the need formula parameters
+// @Summary Update need assessment parameters
+// @Description Save new parameters for the household financial need formula. Omitted fields keep their default value. Existing assessments are not recalculated (Admin only)
+// @Tags Need Assessment
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.NeedParameters true "Formula parameters"
+// @Success 200 {object} object{success=bool,data=models.NeedParameterSet}
+// @Failure 400 {object} object{error=string}
+// @Router /api/v1/admin/need-assessment/parameters [put]
+func (h *NeedAssessmentHandler) UpdateParameters(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	params := models.DefaultNeedParameters()
+	if err := c.BodyParser(&params); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if err := services.ValidateNeedParameters(params); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	set, err := h.needRepo.SaveParameters(params, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save need assessment parameters",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Need assessment parameters updated",
+		"data":    set,
+	})
+}
+
+// GetAssessment returns the stored need assessment of an application
+// @Summary Get application need assessment
+// @Description Get the household size, per-capita income, debt and asset adjustments and unmet need calculated for an application (Admin/Officer only)
+// @Tags Need Assessment
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Success 200 {object} object{success=bool,data=models.NeedAssessment}
+// @Failure 400 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/applications/{id}/need-assessment [get]
+func (h *NeedAssessmentHandler) GetAssessment(c *fiber.Ctx) error {
+	applicationID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid application ID",
+		})
+	}
+
+	assessment, err := h.needRepo.GetAssessment(uint(applicationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Application has not been assessed yet",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve need assessment",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    assessment,
+	})
+}
+
+// RecalculateAssessment runs the need assessment again with the active parameters
+// @Summary Recalculate application need assessment
+// @Description Recalculate the need assessment and priority score of an application from its current form data (Admin/Officer only)
+// @Tags Need Assessment
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Success 200 {object} object{success=bool,data=models.NeedAssessment,priority_score=number}
+// @Failure 400 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/applications/{id}/need-assessment [post]
+func (h *NeedAssessmentHandler) RecalculateAssessment(c *fiber.Ctx) error {
+	applicationID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid application ID",
+		})
+	}
+
+	assessment, breakdown, err := refreshApplicationScores(uint(applicationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Application not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to calculate need assessment",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success":        true,
+		"data":           assessment,
+		"priority_score": breakdown.TotalScore,
+	})
+}
+
+// assessApplicationNeed calculates the need assessment of an application with
+// the active parameters and stores it
+func assessApplicationNeed(applicationID uint) (*models.NeedAssessment, error) {
+	if _, err := repository.NewApplicationRepository().GetByID(applicationID); err != nil {
+		return nil, err
+	}
+	form, err := repository.NewApplicationDetailsRepository().GetCompleteForm(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	needRepo := repository.NewNeedAssessmentRepository()
+	set, err := needRepo.GetActiveParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	assessment := services.AssessNeed(form, set.Parameters)
+	assessment.ApplicationID = applicationID
+	assessment.ParameterSetID = set.ParameterSetID
+	if err := needRepo.SaveAssessment(assessment); err != nil {
+		return nil, err
+	}
+	return assessment, nil
+}
+
+// refreshApplicationScores reassesses the financial need of an application
+// and recomputes its priority score, which may depend on it
+func refreshApplicationScores(applicationID uint) (*models.NeedAssessment, *models.ScoreBreakdown, error) {
+	assessment, err := assessApplicationNeed(applicationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	breakdown, err := scoreApplication(applicationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return assessment, breakdown, nil
+}
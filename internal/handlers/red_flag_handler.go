@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+)
+
+type RedFlagHandler struct {
+	cfg         *config.Config
+	redFlagRepo *repository.RedFlagRepository
+	jobRepo     *repository.JobRepository
+}
+
+func NewRedFlagHandler(cfg *config.Config) *RedFlagHandler {
+	return &RedFlagHandler{
+		cfg:         cfg,
+		redFlagRepo: repository.NewRedFlagRepository(),
+		jobRepo:     repository.NewJobRepository(),
+	}
+}
+
+// ListRedFlags lists duplicate and fraud signals for triage
+// @Summary List red flags
+// @Description List the signals found by the duplicate and fraud detection job, most severe first. Defaults to open flags; pass status=all for every status (Admin/Officer only)
+// @Tags Red Flags
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "open, confirmed, dismissed, resolved or all" default(open)
+// @Param signal_type query string false "shared_bank_account, shared_guardian_id, shared_house_registration, duplicate_file or income_asset_mismatch"
+// @Param scholarship_id query int false "Scholarship ID"
+// @Success 200 {object} object{success=bool,data=[]models.RedFlag}
+// @Router /api/v1/admin/red-flags [get]
+func (h *RedFlagHandler) ListRedFlags(c *fiber.Ctx) error {
+	status := c.Query("status", models.RedFlagOpen)
+	if status == "all" {
+		status = ""
+	}
+
+	var scholarshipID *uint
+	if id := c.QueryInt("scholarship_id", 0); id > 0 {
+		value := uint(id)
+		scholarshipID = &value
+	}
+
+	flags, err := h.redFlagRepo.List(status, c.Query("signal_type"), scholarshipID, nil)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve red flags",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    flags,
+	})
+}
+
+// GetApplicationRedFlags lists every red flag of an application
+// @Summary Get application red flags
+// @Description List the duplicate and fraud signals found on an application, whatever their status (Admin/Officer only)
+// @Tags Red Flags
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Success 200 {object} object{success=bool,data=[]models.RedFlag}
+// @Failure 400 {object} object{error=string}
+// @Router /api/v1/admin/applications/{id}/red-flags [get]
+func (h *RedFlagHandler) GetApplicationRedFlags(c *fiber.Ctx) error {
+	applicationID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid application ID",
+		})
+	}
+
+	id := uint(applicationID)
+	flags, err := h.redFlagRepo.List("", "", nil, &id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve red flags",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    flags,
+	})
+}
+
+// GetRedFlag returns one red flag
+// @Summary Get red flag
+// @Description Get a duplicate or fraud signal with the applications it relates to (Admin/Officer only)
+// @Tags Red Flags
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Flag ID"
+// @Success 200 {object} object{success=bool,data=models.RedFlag}
+// @Failure 400 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/red-flags/{id} [get]
+func (h *RedFlagHandler) GetRedFlag(c *fiber.Ctx) error {
+	flagID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid flag ID",
+		})
+	}
+
+	flag, err := h.redFlagRepo.GetByID(uint(flagID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Red flag not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve red flag",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    flag,
+	})
+}
+
+// TriageRedFlag confirms, dismisses or reopens a red flag
+// @Summary Triage red flag
+// @Description Confirm or dismiss a duplicate or fraud signal, or reopen it. Dismissing needs a note. The red_flag of the application's reviews is cleared once it has no open or confirmed flags left (Admin/Officer only)
+// @Tags Red Flags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Flag ID"
+// @Param request body models.RedFlagTriageRequest true "Triage decision"
+// @Success 200 {object} object{success=bool,message=string,data=models.RedFlag}
+// @Failure 400 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/red-flags/{id} [put]
+func (h *RedFlagHandler) TriageRedFlag(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	flagID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid flag ID",
+		})
+	}
+
+	var req models.RedFlagTriageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	req.Note = strings.TrimSpace(req.Note)
+
+	switch req.Status {
+	case models.RedFlagOpen, models.RedFlagConfirmed:
+	case models.RedFlagDismissed:
+		if req.Note == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "A note is required to dismiss a red flag",
+			})
+		}
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Status must be open, confirmed or dismissed",
+		})
+	}
+
+	flag, err := h.redFlagRepo.Triage(uint(flagID), req.Status, req.Note, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Red flag not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to triage red flag",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Red flag " + req.Status,
+		"data":    flag,
+	})
+}
+
+// RunScan queues a duplicate and fraud detection run
+// @Summary Run red flag detection
+// @Description Queue a run of the duplicate and fraud detection job over all submitted applications. The job also runs on a schedule (Admin/Officer only)
+// @Tags Red Flags
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} object{success=bool,message=string,data=models.JobQueue}
+// @Failure 409 {object} object{error=string}
+// @Router /api/v1/admin/red-flags/scans [post]
+func (h *RedFlagHandler) RunScan(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	active, err := h.jobRepo.HasActive(models.JobTypeFraudScan)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check running scans",
+		})
+	}
+	if active {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "A detection run is already queued or running",
+		})
+	}
+
+	job, err := h.jobRepo.Enqueue(models.JobTypeFraudScan, nil, &userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to queue detection run",
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"message": "Detection run queued",
+		"data":    job,
+	})
+}
+
+// ListScans lists recent detection runs
+// @Summary List red flag detection runs
+// @Description List the latest runs of the duplicate and fraud detection job with their status and result summary (Admin/Officer only)
+// @Tags Red Flags
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} object{success=bool,data=[]models.JobQueue}
+// @Router /api/v1/admin/red-flags/scans [get]
+func (h *RedFlagHandler) ListScans(c *fiber.Ctx) error {
+	jobs, err := h.jobRepo.List(models.JobTypeFraudScan, 20)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve detection runs",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    jobs,
+	})
+}
@@ -7,14 +7,17 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"scholarship-system/internal/config"
 	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
 )
 
 type ReportHandler struct {
-	cfg *config.Config
+	cfg           *config.Config
+	interviewRepo *repository.InterviewRepository
 }
 
 func NewReportHandler(cfg *config.Config) *ReportHandler {
-	return &ReportHandler{cfg: cfg}
+	return &ReportHandler{cfg: cfg, interviewRepo: repository.NewInterviewRepository()}
 }
 
 // GetDashboardSummary provides dashboard statistics
@@ -59,7 +62,9 @@ func (h *ReportHandler) GetDashboardSummary(c *fiber.Ctx) error {
 	database.DB.QueryRow("SELECT COUNT(*) FROM students WHERE student_status = 'active'").Scan(&stats.TotalStudents)
 
 	// Get interview statistics
-	database.DB.QueryRow("SELECT COUNT(*) FROM interview_appointments WHERE appointment_status = 'scheduled'").Scan(&stats.InterviewsPending)
+	if interviews, err := h.interviewRepo.Statistics(models.InterviewStatsFilter{}); err == nil {
+		stats.InterviewsPending = interviews.PendingBookings
+	}
 
 	return c.JSON(fiber.Map{
 		"data": stats,
@@ -93,13 +98,13 @@ func (h *ReportHandler) GetApplicationReport(c *fiber.Ctx) error {
 		s.scholarship_name, s.amount,
 		u.first_name, u.last_name, u.email,
 		st.faculty_code, st.department_code, st.gpa,
-		CASE WHEN ia.appointment_id IS NOT NULL THEN 'Yes' ELSE 'No' END as has_interview,
+		CASE WHEN EXISTS (SELECT 1 FROM interview_bookings ib
+			WHERE ib.application_id = sa.application_id AND ib.booking_status <> 'cancelled') THEN 'Yes' ELSE 'No' END as has_interview,
 		CASE WHEN sal.allocation_id IS NOT NULL THEN sal.allocated_amount ELSE 0 END as allocated_amount
 		FROM scholarship_applications sa
 		JOIN scholarships s ON sa.scholarship_id = s.scholarship_id
 		JOIN students st ON sa.student_id = st.student_id
 		JOIN users u ON st.user_id = u.user_id
-		LEFT JOIN interview_appointments ia ON sa.application_id = ia.application_id
 		LEFT JOIN scholarship_allocations sal ON sa.application_id = sal.application_id
 		WHERE 1=1`
 
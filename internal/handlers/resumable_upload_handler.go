@@ -0,0 +1,634 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/storage"
+)
+
+// maxUploadChunk is the largest chunk accepted in one request. Clients on
+// poor connections should send smaller ones.
+const maxUploadChunk = 2 * 1024 * 1024
+
+type ResumableUploadHandler struct {
+	cfg             *config.Config
+	sessionRepo     *repository.UploadSessionRepository
+	applicationRepo *repository.ApplicationRepository
+	revisionRepo    *repository.RevisionRepository
+	fileRepo        *repository.FileRepository
+	requirementRepo *repository.DocumentRequirementRepository
+}
+
+func NewResumableUploadHandler(cfg *config.Config) *ResumableUploadHandler {
+	return &ResumableUploadHandler{
+		cfg:             cfg,
+		sessionRepo:     repository.NewUploadSessionRepository(),
+		applicationRepo: repository.NewApplicationRepository(),
+		revisionRepo:    repository.NewRevisionRepository(),
+		fileRepo:        repository.NewFileRepository(database.DB),
+		requirementRepo: repository.NewDocumentRequirementRepository(),
+	}
+}
+
+// expiry is when a session touched now expires if nothing more is sent
+func (h *ResumableUploadHandler) expiry() time.Time {
+	ttl := time.Duration(h.cfg.UploadSessionTTLHours) * time.Hour
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return time.Now().Add(ttl)
+}
+
+// CreateUpload starts a resumable upload
+// @Summary Start resumable upload
+// @Description Start uploading a document in chunks. Send the chunks with PATCH, check the offset with HEAD after a lost connection, then finalize. Uploads are grouped into a session (a new one unless session_token is given) that expires after UPLOAD_SESSION_TTL_HOURS without activity
+// @Tags Document Management
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateResumableUploadRequest true "File to upload"
+// @Success 201 {object} object{success=bool,data=models.ResumableUpload,chunk_size=int}
+// @Failure 400 {object} object{error=string}
+// @Failure 401 {object} object{error=string}
+// @Failure 403 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Failure 410 {object} object{error=string}
+// @Router /api/v1/uploads [post]
+func (h *ResumableUploadHandler) CreateUpload(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	var req models.CreateResumableUploadRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if req.ApplicationID <= 0 || req.DocumentType == "" || req.FileName == "" || req.FileSize <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "application_id, document_type, file_name and file_size are required",
+		})
+	}
+
+	ownerID, err := h.applicationRepo.GetApplicantUserID(uint(req.ApplicationID))
+	if err != nil || ownerID != userID {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Application not found",
+		})
+	}
+	requirement, err := loadDocumentRequirement(h.requirementRepo, uint(req.ApplicationID), req.DocumentType)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load document requirements",
+		})
+	}
+	if limit := documentSizeLimit(h.cfg, requirement, req.DocumentType); limit > 0 && req.FileSize > limit {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("File size exceeds %dMB limit", limit/(1024*1024)),
+		})
+	}
+	if reason, err := editLockReason(h.revisionRepo, uint(req.ApplicationID), "document", req.DocumentType); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify application status",
+		})
+	} else if reason != "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": reason,
+		})
+	}
+
+	store := storage.Default()
+	if store == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "File storage is not available",
+		})
+	}
+
+	var session *models.BulkUploadSession
+	if req.SessionToken != "" {
+		session, err = h.sessionRepo.GetSession(req.SessionToken, userID)
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Upload session not found",
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to fetch upload session",
+			})
+		}
+		if session.ExpiresAt.Before(time.Now()) {
+			return c.Status(fiber.StatusGone).JSON(fiber.Map{
+				"error": "Upload session has expired",
+			})
+		}
+		if session.ApplicationID != nil && *session.ApplicationID != req.ApplicationID {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Upload session belongs to another application",
+			})
+		}
+	} else {
+		session = &models.BulkUploadSession{
+			UserID:        userID.String(),
+			ApplicationID: &req.ApplicationID,
+			ExpiresAt:     h.expiry(),
+		}
+		if err := h.sessionRepo.CreateSession(session); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to start upload session",
+			})
+		}
+	}
+
+	upload := &models.ResumableUpload{
+		SessionID:      session.ID,
+		SessionToken:   session.SessionToken,
+		ApplicationID:  req.ApplicationID,
+		DocumentType:   req.DocumentType,
+		FileName:       filepath.Base(req.FileName),
+		UploadLength:   req.FileSize,
+		StorageBackend: store.Name(),
+		ExpiresAt:      h.expiry(),
+	}
+	if req.ReplaceDocumentID > 0 {
+		upload.ReplaceDocumentID = &req.ReplaceDocumentID
+	}
+	if err := h.sessionRepo.CreateUpload(upload); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start upload",
+		})
+	}
+
+	c.Set("Location", "/api/v1/uploads/"+upload.UploadID)
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success":    true,
+		"data":       upload,
+		"chunk_size": maxUploadChunk,
+	})
+}
+
+// UploadOffset reports how much of an upload has been received
+// @Summary Get resumable upload offset
+// @Description Return the number of bytes received in the Upload-Offset header, so a client can resume after a lost connection
+// @Tags Document Management
+// @Security BearerAuth
+// @Param upload_id path string true "Upload ID"
+// @Success 200 "Upload-Offset and Upload-Length headers"
+// @Failure 401 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/uploads/{upload_id} [head]
+func (h *ResumableUploadHandler) UploadOffset(c *fiber.Ctx) error {
+	upload, err := h.loadUpload(c)
+	if upload == nil {
+		return err
+	}
+	setUploadHeaders(c, upload)
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// GetUpload returns the progress of an upload
+// @Summary Get resumable upload
+// @Description Get the offset, status and resulting document of a resumable upload
+// @Tags Document Management
+// @Produce json
+// @Security BearerAuth
+// @Param upload_id path string true "Upload ID"
+// @Success 200 {object} object{success=bool,data=models.ResumableUpload}
+// @Failure 401 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/uploads/{upload_id} [get]
+func (h *ResumableUploadHandler) GetUpload(c *fiber.Ctx) error {
+	upload, err := h.loadUpload(c)
+	if upload == nil {
+		return err
+	}
+	setUploadHeaders(c, upload)
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    upload,
+	})
+}
+
+// UploadChunk appends a chunk to an upload
+// @Summary Upload chunk
+// @Description Append the request body to an upload. Upload-Offset must equal the bytes received so far; on 409 ask for the offset with HEAD and resume from there
+// @Tags Document Management
+// @Accept application/offset+octet-stream
+// @Security BearerAuth
+// @Param upload_id path string true "Upload ID"
+// @Param Upload-Offset header int true "Offset of this chunk"
+// @Success 204 "Upload-Offset header holds the new offset"
+// @Failure 400 {object} object{error=string}
+// @Failure 401 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
+// @Failure 410 {object} object{error=string}
+// @Failure 413 {object} object{error=string}
+// @Failure 415 {object} object{error=string}
+// @Router /api/v1/uploads/{upload_id} [patch]
+func (h *ResumableUploadHandler) UploadChunk(c *fiber.Ctx) error {
+	upload, err := h.loadUpload(c)
+	if upload == nil {
+		return err
+	}
+	setUploadHeaders(c, upload)
+
+	if upload.UploadStatus != models.ResumableUploading {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Upload is " + upload.UploadStatus,
+		})
+	}
+	if upload.ExpiresAt.Before(time.Now()) {
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{
+			"error": "Upload session has expired",
+		})
+	}
+
+	switch c.Get(fiber.HeaderContentType) {
+	case "application/offset+octet-stream", fiber.MIMEOctetStream:
+	default:
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{
+			"error": "Chunks must be sent as application/offset+octet-stream",
+		})
+	}
+
+	offset, err := strconv.ParseInt(c.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid Upload-Offset header",
+		})
+	}
+	if offset != upload.UploadOffset {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": fmt.Sprintf("Upload-Offset must be %d", upload.UploadOffset),
+		})
+	}
+
+	chunk := c.Body()
+	size := int64(len(chunk))
+	switch {
+	case size == 0:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Chunk is empty",
+		})
+	case size > maxUploadChunk:
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error": fmt.Sprintf("Chunks may not exceed %d bytes", maxUploadChunk),
+		})
+	case offset+size > upload.UploadLength:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Chunk goes past the declared file size",
+		})
+	}
+
+	store, err := storage.Backend(upload.StorageBackend)
+	if err != nil {
+		log.Printf("Upload %s: %v", upload.UploadID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "File storage is not available",
+		})
+	}
+	key := storage.ChunkKey(upload.UploadID, offset)
+	if err := store.Put(c.Context(), key, bytes.NewReader(chunk), size, fiber.MIMEOctetStream); err != nil {
+		log.Printf("Failed to store chunk of upload %s: %v", upload.UploadID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save chunk",
+		})
+	}
+
+	added, err := h.sessionRepo.AddChunk(upload, offset, size, key, h.expiry())
+	if err != nil || !added {
+		removeStoredFile(c, upload.StorageBackend, key)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to save chunk",
+			})
+		}
+		// Another request for the same offset got there first
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Upload offset has changed; check it with HEAD and resume",
+		})
+	}
+
+	setUploadHeaders(c, upload)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// FinalizeUpload turns a fully received upload into an application document
+// @Summary Finalize resumable upload
+// @Description Check the assembled file like a single upload and attach it to the application, as a new version when it replaces a document. Files failing the checks mark the upload failed
+// @Tags Document Management
+// @Produce json
+// @Security BearerAuth
+// @Param upload_id path string true "Upload ID"
+// @Success 200 {object} object{success=bool,message=string,data=object}
+// @Failure 400 {object} object{error=string}
+// @Failure 401 {object} object{error=string}
+// @Failure 403 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
+// @Router /api/v1/uploads/{upload_id}/finalize [post]
+func (h *ResumableUploadHandler) FinalizeUpload(c *fiber.Ctx) error {
+	upload, err := h.loadUpload(c)
+	if upload == nil {
+		return err
+	}
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	switch {
+	case upload.UploadStatus == models.ResumableCompleted:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":       "Upload is already finalized",
+			"document_id": upload.DocumentID,
+		})
+	case upload.UploadStatus == models.ResumableFailed:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Upload failed: " + stringValue(upload.ErrorMessage),
+		})
+	case upload.UploadOffset < upload.UploadLength:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": fmt.Sprintf("Upload is incomplete: %d of %d bytes received", upload.UploadOffset, upload.UploadLength),
+		})
+	}
+
+	if reason, err := editLockReason(h.revisionRepo, uint(upload.ApplicationID), "document", upload.DocumentType); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify application status",
+		})
+	} else if reason != "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": reason,
+		})
+	}
+
+	doc, replacedVersion, err := h.attachUpload(c, upload, userID)
+	if err != nil {
+		var uploadErr *storage.UploadError
+		switch {
+		case err == errUploadBusy:
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Upload is already being finalized",
+			})
+		case err == sql.ErrNoRows:
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Document to replace not found",
+			})
+		case errors.As(err, &uploadErr):
+			return uploadFailed(c, err)
+		}
+		log.Printf("Failed to finalize upload %s: %v", upload.UploadID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to finalize upload",
+		})
+	}
+	queueDocumentScan()
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Document uploaded successfully",
+		"data": fiber.Map{
+			"upload_id":           upload.UploadID,
+			"document_id":         doc.DocumentID,
+			"document_type":       doc.DocumentType,
+			"file_name":           doc.DocumentName,
+			"file_size":           doc.FileSize,
+			"mime_type":           doc.MimeType,
+			"verification_status": doc.UploadStatus,
+			"uploaded_at":         doc.UploadedAt,
+			"version_number":      doc.VersionNumber,
+			"replaced_version":    replacedVersion,
+		},
+	})
+}
+
+// GetSessionProgress returns the progress of an upload session
+// @Summary Get upload session progress
+// @Description Get the files, bytes received and errors of an upload session
+// @Tags Document Management
+// @Produce json
+// @Security BearerAuth
+// @Param session_token path string true "Upload session token"
+// @Success 200 {object} object{success=bool,data=models.UploadSessionProgress}
+// @Failure 401 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/uploads/sessions/{session_token} [get]
+func (h *ResumableUploadHandler) GetSessionProgress(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	session, err := h.sessionRepo.GetSession(c.Params("session_token"), userID)
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Upload session not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch upload session",
+		})
+	}
+	return sendSessionProgress(c, h.sessionRepo, session)
+}
+
+// sendSessionProgress answers with the progress of an upload session and
+// its uploads
+func sendSessionProgress(c *fiber.Ctx, sessionRepo *repository.UploadSessionRepository, session *models.BulkUploadSession) error {
+	uploads, err := sessionRepo.ListUploads(session.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch uploads",
+		})
+	}
+
+	status := session.SessionStatus
+	if status == models.UploadSessionInProgress && session.ExpiresAt.Before(time.Now()) {
+		status = "expired"
+	}
+	var errs []string
+	if len(session.ErrorSummary) > 0 {
+		json.Unmarshal(session.ErrorSummary, &errs)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": models.UploadSessionProgress{
+			UploadProgressResponse: models.UploadProgressResponse{
+				SessionToken:  session.SessionToken,
+				TotalFiles:    session.TotalFiles,
+				UploadedFiles: session.UploadedFiles,
+				FailedFiles:   session.FailedFiles,
+				Progress:      session.UploadProgress,
+				Status:        status,
+				Errors:        errs,
+			},
+			ApplicationID: session.ApplicationID,
+			ExpiresAt:     session.ExpiresAt,
+			Uploads:       uploads,
+		},
+	})
+}
+
+// errUploadBusy means another request is already finalizing an upload
+var errUploadBusy = errors.New("upload is already being finalized")
+
+// attachUpload checks a fully received upload like a single upload and
+// saves it as an application document, as a new version when it replaces a
+// document. Rejected files (a *storage.UploadError, or sql.ErrNoRows for a
+// missing document to replace) mark the upload failed; after other errors it
+// can be retried.
+func (h *ResumableUploadHandler) attachUpload(c *fiber.Ctx, upload *models.ResumableUpload, userID uuid.UUID) (*models.ApplicationDocument, *int, error) {
+	claimed, err := h.sessionRepo.ClaimUpload(upload.UploadID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !claimed {
+		return nil, nil, errUploadBusy
+	}
+
+	keys, err := h.sessionRepo.ListChunkKeys(upload.UploadID)
+	if err != nil {
+		h.sessionRepo.ReleaseUpload(upload.UploadID)
+		return nil, nil, err
+	}
+
+	requirement, err := loadDocumentRequirement(h.requirementRepo, uint(upload.ApplicationID), upload.DocumentType)
+	if err != nil {
+		h.sessionRepo.ReleaseUpload(upload.UploadID)
+		return nil, nil, err
+	}
+
+	chunks := storage.OpenChunks(c.Context(), upload.StorageBackend, keys)
+	stored, err := storage.SaveUploadFrom(c.Context(), chunks, upload.FileName,
+		fmt.Sprintf("applications/%d", upload.ApplicationID), upload.DocumentType,
+		documentUploadPolicy(h.cfg, requirement, upload.DocumentType, documentTypes, "PDF, JPEG, PNG"))
+	chunks.Close()
+	if err != nil {
+		var uploadErr *storage.UploadError
+		if errors.As(err, &uploadErr) {
+			h.failUpload(c, upload, keys, uploadErr.Message)
+		} else {
+			h.sessionRepo.ReleaseUpload(upload.UploadID)
+		}
+		return nil, nil, err
+	}
+
+	doc := &models.ApplicationDocument{
+		ApplicationID:  upload.ApplicationID,
+		DocumentType:   upload.DocumentType,
+		DocumentName:   stored.OriginalName,
+		FilePath:       stored.Key,
+		StorageBackend: stored.Backend,
+		FileSize:       stored.Size,
+		MimeType:       stored.ContentType,
+		FileHash:       stored.SHA256,
+		UploadStatus:   models.DocumentQuarantined,
+		UploadedAt:     time.Now(),
+		UploadedBy:     &userID,
+	}
+	replaceID := 0
+	if upload.ReplaceDocumentID != nil {
+		replaceID = *upload.ReplaceDocumentID
+	}
+	replaced, err := saveApplicationDocument(h.fileRepo, h.applicationRepo, doc, replaceID, requirement)
+	if err != nil {
+		removeStoredFile(c, stored.Backend, stored.Key)
+		var uploadErr *storage.UploadError
+		if err == sql.ErrNoRows {
+			h.failUpload(c, upload, keys, "Document to replace not found")
+		} else if errors.As(err, &uploadErr) {
+			h.failUpload(c, upload, keys, uploadErr.Message)
+		} else {
+			h.sessionRepo.ReleaseUpload(upload.UploadID)
+		}
+		return nil, nil, err
+	}
+	var replacedVersion *int
+	if replaced != nil {
+		replacedVersion = &replaced.VersionNumber
+	}
+
+	if err := h.sessionRepo.CompleteUpload(upload, doc.DocumentID, h.expiry()); err != nil {
+		// The document is saved; the expired session cleanup removes the rest
+		log.Printf("Failed to complete upload %s: %v", upload.UploadID, err)
+	} else {
+		for _, key := range keys {
+			removeStoredFile(c, upload.StorageBackend, key)
+		}
+	}
+	return doc, replacedVersion, nil
+}
+
+// failUpload records why an upload was rejected and deletes its chunks
+func (h *ResumableUploadHandler) failUpload(c *fiber.Ctx, upload *models.ResumableUpload, keys []string, message string) {
+	if err := h.sessionRepo.FailUpload(upload, message, h.expiry()); err != nil {
+		log.Printf("Failed to mark upload %s failed: %v", upload.UploadID, err)
+		return
+	}
+	for _, key := range keys {
+		removeStoredFile(c, upload.StorageBackend, key)
+	}
+}
+
+// loadUpload fetches the upload named in the path if it belongs to the
+// user. On failure it returns nil and the response already sent.
+func (h *ResumableUploadHandler) loadUpload(c *fiber.Ctx) (*models.ResumableUpload, error) {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return nil, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	upload, err := h.sessionRepo.GetUpload(c.Params("upload_id"), userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Upload not found",
+			})
+		}
+		return nil, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch upload",
+		})
+	}
+	return upload, nil
+}
+
+// setUploadHeaders reports the progress of an upload the way tus clients
+// expect it
+func setUploadHeaders(c *fiber.Ctx, upload *models.ResumableUpload) {
+	c.Set("Upload-Offset", strconv.FormatInt(upload.UploadOffset, 10))
+	c.Set("Upload-Length", strconv.FormatInt(upload.UploadLength, 10))
+	c.Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
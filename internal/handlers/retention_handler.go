@@ -0,0 +1,444 @@
+package handlers
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+)
+
+type RetentionHandler struct {
+	cfg           *config.Config
+	retentionRepo *repository.RetentionRepository
+	jobRepo       *repository.JobRepository
+}
+
+func NewRetentionHandler(cfg *config.Config) *RetentionHandler {
+	return &RetentionHandler{
+		cfg:           cfg,
+		retentionRepo: repository.NewRetentionRepository(),
+		jobRepo:       repository.NewJobRepository(),
+	}
+}
+
+// ListRules lists the retention rules
+// @Summary List retention rules
+// @Description List the rules deciding how long documents and application form details are kept after the announcement date of the round, per application outcome (Admin only)
+// @Tags Data Retention
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} object{success=bool,data=[]models.RetentionRule}
+// @Router /api/v1/admin/retention-rules [get]
+func (h *RetentionHandler) ListRules(c *fiber.Ctx) error {
+	rules, err := h.retentionRepo.ListRules()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve retention rules",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    rules,
+	})
+}
+
+// CreateRule creates a retention rule
+// @Summary Create retention rule
+// @Description Create a retention rule. Documents rules may name a document type; without one they apply to every type without a rule of its own. Outcomes are awarded (approved, completed), rejected, withdrawn (withdrawn, declined) and not_submitted (draft) (Admin only)
+// @Tags Data Retention
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.RetentionRuleRequest true "Retention rule"
+// @Success 201 {object} object{success=bool,data=models.RetentionRule}
+// @Failure 400 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
+// @Router /api/v1/admin/retention-rules [post]
+func (h *RetentionHandler) CreateRule(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	rule := &models.RetentionRule{IsActive: true, CreatedBy: &userID}
+	if status, message := h.applyRequest(c, rule); message != "" {
+		return c.Status(status).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	if err := h.retentionRepo.SaveRule(rule); err != nil {
+		return h.saveFailed(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Retention rule created",
+		"data":    rule,
+	})
+}
+
+// UpdateRule updates a retention rule
+// @Summary Update retention rule
+// @Description Update a retention rule. It applies from the next retention run (Admin only)
+// @Tags Data Retention
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Rule ID"
+// @Param request body models.RetentionRuleRequest true "Retention rule"
+// @Success 200 {object} object{success=bool,data=models.RetentionRule}
+// @Failure 400 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/retention-rules/{id} [put]
+func (h *RetentionHandler) UpdateRule(c *fiber.Ctx) error {
+	ruleID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid rule ID",
+		})
+	}
+
+	rule, err := h.retentionRepo.GetRule(ruleID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Retention rule not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve retention rule",
+		})
+	}
+
+	if status, message := h.applyRequest(c, rule); message != "" {
+		return c.Status(status).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	if err := h.retentionRepo.SaveRule(rule); err != nil {
+		return h.saveFailed(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Retention rule updated",
+		"data":    rule,
+	})
+}
+
+// DeleteRule deletes a retention rule
+// @Summary Delete retention rule
+// @Description Delete a retention rule. Records it covered are kept until another rule applies; the retention log keeps its entries (Admin only)
+// @Tags Data Retention
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Rule ID"
+// @Success 200 {object} object{success=bool,message=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/retention-rules/{id} [delete]
+func (h *RetentionHandler) DeleteRule(c *fiber.Ctx) error {
+	ruleID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid rule ID",
+		})
+	}
+
+	if err := h.retentionRepo.DeleteRule(ruleID); err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Retention rule not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete retention rule",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Retention rule deleted",
+	})
+}
+
+// StartRun queues a retention run
+// @Summary Run retention
+// @Description Queue a run of the retention job. With dry_run the result lists what is due without deleting anything; otherwise due files are deleted and their rows deleted or anonymized. The purge also runs on a schedule (Admin only)
+// @Tags Data Retention
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.RetentionRunRequest false "Dry run"
+// @Success 202 {object} object{success=bool,message=string,data=models.JobQueue}
+// @Failure 409 {object} object{error=string}
+// @Router /api/v1/admin/retention/runs [post]
+func (h *RetentionHandler) StartRun(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	var req models.RetentionRunRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+	jobType := retentionJobType(req.DryRun)
+
+	active, err := h.jobRepo.HasActive(jobType)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check running retention runs",
+		})
+	}
+	if active {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "A retention run is already queued or running",
+		})
+	}
+
+	job, err := h.jobRepo.Enqueue(jobType, nil, &userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to queue retention run",
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"message": "Retention run queued",
+		"data":    job,
+	})
+}
+
+// ListRuns lists recent retention runs
+// @Summary List retention runs
+// @Description List the latest retention purges, or with dry_run the latest dry runs, with their status and result (Admin only)
+// @Tags Data Retention
+// @Produce json
+// @Security BearerAuth
+// @Param dry_run query bool false "List dry runs"
+// @Success 200 {object} object{success=bool,data=[]models.JobQueue}
+// @Router /api/v1/admin/retention/runs [get]
+func (h *RetentionHandler) ListRuns(c *fiber.Ctx) error {
+	runs, err := h.jobRepo.List(retentionJobType(c.QueryBool("dry_run")), 20)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve retention runs",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    runs,
+	})
+}
+
+// GetRun returns a retention run
+// @Summary Get retention run
+// @Description Get the status and result of a retention run or dry run, including the first actions it took or would take (Admin only)
+// @Tags Data Retention
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID"
+// @Success 200 {object} object{success=bool,data=models.JobQueue}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/retention/runs/{id} [get]
+func (h *RetentionHandler) GetRun(c *fiber.Ctx) error {
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid job ID",
+		})
+	}
+
+	job, err := h.jobRepo.GetByID(jobID)
+	if err != nil && err != sql.ErrNoRows {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve retention run",
+		})
+	}
+	if err == sql.ErrNoRows || (job.JobType != models.JobTypeRetentionPurge && job.JobType != models.JobTypeRetentionReport) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Retention run not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    job,
+	})
+}
+
+// ListLog lists what the retention job deleted
+// @Summary List retention log
+// @Description List the purges and anonymizations performed by the retention job, newest first (Admin only)
+// @Tags Data Retention
+// @Produce json
+// @Security BearerAuth
+// @Param application_id query int false "Application ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(50)
+// @Success 200 {object} object{success=bool,data=[]models.RetentionLogEntry,pagination=object}
+// @Router /api/v1/admin/retention/log [get]
+func (h *RetentionHandler) ListLog(c *fiber.Ctx) error {
+	var applicationID *int
+	if id := c.QueryInt("application_id", 0); id > 0 {
+		applicationID = &id
+	}
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 50)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	entries, total, err := h.retentionRepo.ListLog(applicationID, limit, (page-1)*limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve retention log",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    entries,
+		"pagination": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// SetLegalHold places or lifts the legal hold of an application
+// @Summary Set legal hold
+// @Description Place an application on legal hold, which stops the retention job from deleting or anonymizing any of its records, or lift the hold (Admin only)
+// @Tags Data Retention
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Param request body models.LegalHoldRequest true "Legal hold"
+// @Success 200 {object} object{success=bool,message=string}
+// @Failure 400 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/applications/{id}/legal-hold [put]
+func (h *RetentionHandler) SetLegalHold(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	applicationID, err := c.ParamsInt("id")
+	if err != nil || applicationID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid application ID",
+		})
+	}
+
+	var req models.LegalHoldRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if req.Reason != nil {
+		reason := strings.TrimSpace(*req.Reason)
+		req.Reason = &reason
+	}
+	if req.LegalHold && (req.Reason == nil || *req.Reason == "") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "reason is required to place a legal hold",
+		})
+	}
+
+	if err := h.retentionRepo.SetLegalHold(applicationID, req.LegalHold, req.Reason, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Application not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update legal hold",
+		})
+	}
+
+	message := "Legal hold lifted"
+	if req.LegalHold {
+		message = "Legal hold placed"
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": message,
+	})
+}
+
+// applyRequest copies a create/update request onto rule and validates the
+// result. On failure it returns the HTTP status and error message to answer with.
+func (h *RetentionHandler) applyRequest(c *fiber.Ctx, rule *models.RetentionRule) (int, string) {
+	var req models.RetentionRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.StatusBadRequest, "Invalid request body"
+	}
+
+	rule.RecordType = req.RecordType
+	rule.DocumentType = req.DocumentType
+	rule.Outcome = req.Outcome
+	rule.RetentionMonths = req.RetentionMonths
+	rule.Action = req.Action
+	rule.Notes = req.Notes
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := services.ValidateRetentionRule(rule); err != nil {
+		return fiber.StatusBadRequest, err.Error()
+	}
+	return fiber.StatusOK, ""
+}
+
+// saveFailed answers a failed SaveRule
+func (h *RetentionHandler) saveFailed(c *fiber.Ctx, err error) error {
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Retention rule not found",
+		})
+	}
+	if strings.Contains(err.Error(), "already exists") {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Failed to save retention rule",
+	})
+}
+
+// retentionJobType is the job type of a retention run
+func retentionJobType(dryRun bool) string {
+	if dryRun {
+		return models.JobTypeRetentionReport
+	}
+	return models.JobTypeRetentionPurge
+}
@@ -12,6 +12,7 @@ import (
 	"scholarship-system/internal/config"
 	"scholarship-system/internal/models"
 	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
 )
 
 type ScholarshipHandler struct {
@@ -111,18 +112,20 @@ func (h *ScholarshipHandler) GetSources(c *fiber.Ctx) error {
 
 // Scholarship Handlers
 type CreateScholarshipRequest struct {
-	SourceID             uint      `json:"source_id" validate:"required"`
-	ScholarshipName      string    `json:"scholarship_name" validate:"required"`
-	ScholarshipType      string    `json:"scholarship_type" validate:"required"`
-	Amount               float64   `json:"amount" validate:"required,min=0"`
-	TotalQuota           int       `json:"total_quota" validate:"required,min=1"`
-	AcademicYear         string    `json:"academic_year" validate:"required"`
-	Semester             string    `json:"semester"`
-	EligibilityCriteria  string    `json:"eligibility_criteria"`
-	RequiredDocuments    string    `json:"required_documents"`
-	ApplicationStartDate time.Time `json:"application_start_date" validate:"required"`
-	ApplicationEndDate   time.Time `json:"application_end_date" validate:"required"`
-	InterviewRequired    bool      `json:"interview_required"`
+	SourceID             uint                         `json:"source_id" validate:"required"`
+	ScholarshipName      string                       `json:"scholarship_name" validate:"required"`
+	ScholarshipType      string                       `json:"scholarship_type" validate:"required"`
+	Amount               float64                      `json:"amount" validate:"required,min=0"`
+	TotalQuota           int                          `json:"total_quota" validate:"required,min=1"`
+	AcademicYear         string                       `json:"academic_year" validate:"required"`
+	Semester             string                       `json:"semester"`
+	EligibilityCriteria  string                       `json:"eligibility_criteria"`
+	EligibilityRules     *models.EligibilityRules     `json:"eligibility_rules"`
+	RequiredDocuments    string                       `json:"required_documents"`
+	DocumentRequirements []models.DocumentRequirement `json:"document_requirements"`
+	ApplicationStartDate time.Time                    `json:"application_start_date" validate:"required"`
+	ApplicationEndDate   time.Time                    `json:"application_end_date" validate:"required"`
+	InterviewRequired    bool                         `json:"interview_required"`
 }
 
 func (h *ScholarshipHandler) CreateScholarship(c *fiber.Ctx) error {
@@ -148,6 +151,11 @@ func (h *ScholarshipHandler) CreateScholarship(c *fiber.Ctx) error {
 			"error": "Application end date must be after start date",
 		})
 	}
+	if err := services.ValidateDocumentRequirements(req.DocumentRequirements); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 
 	scholarship := &models.Scholarship{
 		SourceID:             req.SourceID,
@@ -159,7 +167,9 @@ func (h *ScholarshipHandler) CreateScholarship(c *fiber.Ctx) error {
 		AcademicYear:         req.AcademicYear,
 		Semester:             &req.Semester,
 		EligibilityCriteria:  &req.EligibilityCriteria,
+		EligibilityRules:     req.EligibilityRules,
 		RequiredDocuments:    &req.RequiredDocuments,
+		DocumentRequirements: req.DocumentRequirements,
 		ApplicationStartDate: req.ApplicationStartDate,
 		ApplicationEndDate:   req.ApplicationEndDate,
 		InterviewRequired:    req.InterviewRequired,
@@ -291,6 +301,11 @@ func (h *ScholarshipHandler) UpdateScholarship(c *fiber.Ctx) error {
 			"error": "Application end date must be after start date",
 		})
 	}
+	if err := services.ValidateDocumentRequirements(req.DocumentRequirements); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 
 	// Update scholarship fields
 	scholarship.SourceID = req.SourceID
@@ -302,7 +317,9 @@ func (h *ScholarshipHandler) UpdateScholarship(c *fiber.Ctx) error {
 	scholarship.AcademicYear = req.AcademicYear
 	scholarship.Semester = &req.Semester
 	scholarship.EligibilityCriteria = &req.EligibilityCriteria
+	scholarship.EligibilityRules = req.EligibilityRules
 	scholarship.RequiredDocuments = &req.RequiredDocuments
+	scholarship.DocumentRequirements = req.DocumentRequirements
 	scholarship.ApplicationStartDate = req.ApplicationStartDate
 	scholarship.ApplicationEndDate = req.ApplicationEndDate
 	scholarship.InterviewRequired = req.InterviewRequired
@@ -545,7 +562,9 @@ func (h *ScholarshipHandler) DuplicateScholarship(c *fiber.Ctx) error {
 		AcademicYear:         original.AcademicYear,
 		Semester:             original.Semester,
 		EligibilityCriteria:  original.EligibilityCriteria,
+		EligibilityRules:     original.EligibilityRules,
 		RequiredDocuments:    original.RequiredDocuments,
+		DocumentRequirements: original.DocumentRequirements,
 		ApplicationStartDate: original.ApplicationStartDate,
 		ApplicationEndDate:   original.ApplicationEndDate,
 		InterviewRequired:    original.InterviewRequired,
@@ -0,0 +1,435 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+)
+
+type ScoringProfileHandler struct {
+	cfg         *config.Config
+	scoringRepo *repository.ScoringRepository
+}
+
+func NewScoringProfileHandler(cfg *config.Config) *ScoringProfileHandler {
+	return &ScoringProfileHandler{
+		cfg:         cfg,
+		scoringRepo: repository.NewScoringRepository(),
+	}
+}
+
+// ListProfiles lists the scoring profiles
+// @Summary List scoring profiles
+// @Description List priority scoring profiles, optionally of one scholarship. Also returns the metrics components can use and the built-in default (Admin/Officer only)
+// @Tags Scoring Profiles
+// @Produce json
+// @Security BearerAuth
+// @Param scholarship_id query int false "Scholarship ID"
+// @Success 200 {object} object{success=bool,data=[]models.ScoringProfile,metrics=[]string,default=models.ScoringProfile}
+// @Router /api/v1/admin/scoring-profiles [get]
+func (h *ScoringProfileHandler) ListProfiles(c *fiber.Ctx) error {
+	var scholarshipID *uint
+	if id := c.QueryInt("scholarship_id", 0); id > 0 {
+		value := uint(id)
+		scholarshipID = &value
+	}
+
+	profiles, err := h.scoringRepo.List(scholarshipID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve scoring profiles",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    profiles,
+		"metrics": models.ScoringMetrics,
+		"default": models.DefaultScoringProfile(),
+	})
+}
+
+// GetProfile returns a scoring profile
+// @Summary Get scoring profile
+// @Description Get a priority scoring profile (Admin/Officer only)
+// @Tags Scoring Profiles
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Profile ID"
+// @Success 200 {object} object{success=bool,data=models.ScoringProfile}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/scoring-profiles/{id} [get]
+func (h *ScoringProfileHandler) GetProfile(c *fiber.Ctx) error {
+	profileID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid profile ID",
+		})
+	}
+
+	profile, err := h.scoringRepo.GetByID(uint(profileID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Scoring profile not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve scoring profile",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    profile,
+	})
+}
+
+// CreateProfile creates a scoring profile
+// @Summary Create scoring profile
+// @Description Create a priority scoring profile for a scholarship, a round, or (with neither) all scholarships. An active profile replaces the active profile of the same scope. Weights must add up to 1 (Admin/Officer only)
+// @Tags Scoring Profiles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ScoringProfileRequest true "Scoring profile"
+// @Success 201 {object} object{success=bool,data=models.ScoringProfile}
+// @Failure 400 {object} object{error=string}
+// @Router /api/v1/admin/scoring-profiles [post]
+func (h *ScoringProfileHandler) CreateProfile(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	profile := &models.ScoringProfile{IsActive: true, CreatedBy: &userID}
+	if status, message := h.applyRequest(c, profile); message != "" {
+		return c.Status(status).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	if err := h.scoringRepo.Save(profile); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save scoring profile",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Scoring profile created",
+		"data":    profile,
+	})
+}
+
+// UpdateProfile updates a scoring profile
+// @Summary Update scoring profile
+// @Description Update a priority scoring profile. Stored scores are not recalculated until requested (Admin/Officer only)
+// @Tags Scoring Profiles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Profile ID"
+// @Param request body models.ScoringProfileRequest true "Scoring profile"
+// @Success 200 {object} object{success=bool,data=models.ScoringProfile}
+// @Failure 400 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/scoring-profiles/{id} [put]
+func (h *ScoringProfileHandler) UpdateProfile(c *fiber.Ctx) error {
+	profileID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid profile ID",
+		})
+	}
+
+	profile, err := h.scoringRepo.GetByID(uint(profileID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Scoring profile not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve scoring profile",
+		})
+	}
+
+	if status, message := h.applyRequest(c, profile); message != "" {
+		return c.Status(status).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	if err := h.scoringRepo.Save(profile); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save scoring profile",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Scoring profile updated",
+		"data":    profile,
+	})
+}
+
+// DeleteProfile deletes a scoring profile
+// @Summary Delete scoring profile
+// @Description Delete a priority scoring profile. Applications keep the scores already calculated with it (Admin/Officer only)
+// @Tags Scoring Profiles
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Profile ID"
+// @Success 200 {object} object{success=bool,message=string}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/scoring-profiles/{id} [delete]
+func (h *ScoringProfileHandler) DeleteProfile(c *fiber.Ctx) error {
+	profileID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid profile ID",
+		})
+	}
+
+	if err := h.scoringRepo.Delete(uint(profileID)); err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Scoring profile not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete scoring profile",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Scoring profile deleted",
+	})
+}
+
+// RecalculateScholarship recomputes the priority scores of a scholarship's applications
+// @Summary Recalculate priority scores
+// @Description Reassess need and recompute the priority score of every submitted application of a scholarship, e.g. after changing its scoring profile (Admin/Officer only)
+// @Tags Scoring Profiles
+// @Produce json
+// @Security BearerAuth
+// @Param scholarship_id query int true "Scholarship ID"
+// @Success 200 {object} object{success=bool,data=object{scored=int,failed=[]int}}
+// @Failure 400 {object} object{error=string}
+// @Router /api/v1/admin/scoring-profiles/recalculate [post]
+func (h *ScoringProfileHandler) RecalculateScholarship(c *fiber.Ctx) error {
+	scholarshipID := c.QueryInt("scholarship_id", 0)
+	if scholarshipID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "scholarship_id is required",
+		})
+	}
+
+	applicationIDs, err := h.scoringRepo.ListSubmittedApplicationIDs(uint(scholarshipID))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve applications",
+		})
+	}
+
+	scored := 0
+	failed := []uint{}
+	for _, applicationID := range applicationIDs {
+		if _, _, err := refreshApplicationScores(applicationID); err != nil {
+			fmt.Printf("Warning: Failed to score application %d: %v\n", applicationID, err)
+			failed = append(failed, applicationID)
+			continue
+		}
+		scored++
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"scored": scored,
+			"failed": failed,
+		},
+	})
+}
+
+// GetApplicationScore returns the stored priority score breakdown of an application
+// @Summary Get application priority score
+// @Description Get the per-component breakdown of an application's priority score (Admin/Officer only)
+// @Tags Scoring Profiles
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Success 200 {object} object{success=bool,data=models.ScoreBreakdown}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/applications/{id}/priority-score [get]
+func (h *ScoringProfileHandler) GetApplicationScore(c *fiber.Ctx) error {
+	applicationID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid application ID",
+		})
+	}
+
+	breakdown, err := h.scoringRepo.GetScore(uint(applicationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Application has not been scored yet",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve priority score",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    breakdown,
+	})
+}
+
+// RecalculateApplicationScore recomputes the priority score of an application
+// @Summary Recalculate application priority score
+// @Description Recompute the priority score of an application with the scoring profile that applies to its scholarship (Admin/Officer only)
+// @Tags Scoring Profiles
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Success 200 {object} object{success=bool,data=models.ScoreBreakdown}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/applications/{id}/priority-score [post]
+func (h *ScoringProfileHandler) RecalculateApplicationScore(c *fiber.Ctx) error {
+	applicationID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid application ID",
+		})
+	}
+
+	breakdown, err := scoreApplication(uint(applicationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Application not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to calculate priority score",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    breakdown,
+	})
+}
+
+// applyRequest copies a create/update request onto profile and validates the
+// result. On failure it returns the HTTP status and error message to answer with.
+func (h *ScoringProfileHandler) applyRequest(c *fiber.Ctx, profile *models.ScoringProfile) (int, string) {
+	var req models.ScoringProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.StatusBadRequest, "Invalid request body"
+	}
+
+	profile.ProfileName = strings.TrimSpace(req.ProfileName)
+	profile.ScholarshipID = req.ScholarshipID
+	profile.RoundID = req.RoundID
+	profile.Components = req.Components
+	if req.IsActive != nil {
+		profile.IsActive = *req.IsActive
+	}
+
+	if profile.ProfileName == "" {
+		return fiber.StatusBadRequest, "profile_name is required"
+	}
+	if profile.ScholarshipID != nil && profile.RoundID != nil {
+		return fiber.StatusBadRequest, "A profile applies to either a scholarship or a round, not both"
+	}
+
+	criteria, err := h.scoringRepo.GetCriteria(profile.ScholarshipID)
+	if err != nil {
+		return fiber.StatusInternalServerError, "Failed to retrieve review criteria"
+	}
+	if err := services.ValidateScoringComponents(profile.Components, criteria); err != nil {
+		return fiber.StatusBadRequest, err.Error()
+	}
+	return fiber.StatusOK, ""
+}
+
+// scoreApplication computes the priority score of an application with the
+// profile that applies to its scholarship and stores it with its breakdown
+func scoreApplication(applicationID uint) (*models.ScoreBreakdown, error) {
+	application, err := repository.NewApplicationRepository().GetByID(applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	scoringRepo := repository.NewScoringRepository()
+	profile, err := scoringRepo.Resolve(application.ScholarshipID)
+	if err != nil {
+		return nil, err
+	}
+	criteria, err := scoringRepo.GetCriteria(&application.ScholarshipID)
+	if err != nil {
+		return nil, err
+	}
+	metrics, err := applicationScoringMetrics(application)
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown := services.ScoreApplication(profile, metrics, criteria)
+	if err := scoringRepo.SaveScore(applicationID, breakdown); err != nil {
+		return nil, err
+	}
+	return breakdown, nil
+}
+
+// applicationScoringMetrics collects the metrics scoring components use.
+// Metrics without data are left out.
+func applicationScoringMetrics(application *models.ScholarshipApplication) (map[string]float64, error) {
+	metrics := map[string]float64{}
+
+	profile, err := repository.NewEligibilityRepository().GetProfile(application.StudentID)
+	if err != nil {
+		return nil, err
+	}
+	if profile.GPA != nil {
+		metrics[models.MetricGPA] = *profile.GPA
+	}
+
+	if application.FamilyIncome != nil {
+		metrics[models.MetricFamilyIncome] = *application.FamilyIncome
+	}
+	assessment, err := repository.NewNeedAssessmentRepository().GetAssessment(application.ApplicationID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if assessment != nil {
+		metrics[models.MetricNeedScore] = assessment.NeedScore
+		metrics[models.MetricPerCapitaIncome] = assessment.PerCapitaIncome
+		metrics[models.MetricUnmetNeed] = assessment.UnmetNeed
+		metrics[models.MetricFamilyIncome] = assessment.HouseholdIncome
+	}
+
+	activities, err := repository.NewApplicationDetailsRepository().GetActivitiesByApplicationID(int(application.ApplicationID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	metrics[models.MetricActivityCount] = float64(len(activities))
+
+	return metrics, nil
+}
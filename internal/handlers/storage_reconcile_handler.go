@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"database/sql"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+)
+
+type StorageReconcileHandler struct {
+	cfg         *config.Config
+	storageRepo *repository.StorageRepository
+	jobRepo     *repository.JobRepository
+}
+
+func NewStorageReconcileHandler(cfg *config.Config) *StorageReconcileHandler {
+	return &StorageReconcileHandler{
+		cfg:         cfg,
+		storageRepo: repository.NewStorageRepository(),
+		jobRepo:     repository.NewJobRepository(),
+	}
+}
+
+// StartReconciliation queues a storage reconciliation
+// @Summary Run storage reconciliation
+// @Description Queue a check of every stored file against the document and file records: files no record points at, records whose file is gone, and with verify_hashes (default) files whose SHA-256 differs from the one recorded at upload. With quarantine orphaned files are moved under orphaned/; with repair documents whose file is missing or damaged are marked missing so the student uploads them again. A report-only check also runs on a schedule (Admin only)
+// @Tags Storage
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.StorageReconcileRequest false "Options"
+// @Success 202 {object} object{success=bool,message=string,data=models.JobQueue}
+// @Failure 409 {object} object{error=string}
+// @Router /api/v1/admin/storage/reconciliations [post]
+func (h *StorageReconcileHandler) StartReconciliation(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	var req models.StorageReconcileRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	active, err := h.jobRepo.HasActive(models.JobTypeStorageReconcile)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check running reconciliations",
+		})
+	}
+	if active {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "A storage reconciliation is already queued or running",
+		})
+	}
+
+	job, err := h.jobRepo.Enqueue(models.JobTypeStorageReconcile, req, &userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to queue storage reconciliation",
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"message": "Storage reconciliation queued",
+		"data":    job,
+	})
+}
+
+// ListReconciliations lists recent storage reconciliations
+// @Summary List storage reconciliations
+// @Description List the latest checks of stored files against their records, from the scheduled job, admin requests and cmd/migrate, with their totals (Admin only)
+// @Tags Storage
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} object{success=bool,data=[]models.StorageReconcileResult}
+// @Router /api/v1/admin/storage/reconciliations [get]
+func (h *StorageReconcileHandler) ListReconciliations(c *fiber.Ctx) error {
+	results, err := h.storageRepo.ListReconciliations(20)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve storage reconciliations",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    results,
+	})
+}
+
+// GetReconciliation returns a storage reconciliation
+// @Summary Get storage reconciliation
+// @Description Get the totals of a storage reconciliation with the orphaned files, missing files and hash mismatches it found and what was done about each (Admin only)
+// @Tags Storage
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Reconciliation ID"
+// @Success 200 {object} object{success=bool,data=models.StorageReconcileResult}
+// @Failure 404 {object} object{error=string}
+// @Router /api/v1/admin/storage/reconciliations/{id} [get]
+func (h *StorageReconcileHandler) GetReconciliation(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid reconciliation ID",
+		})
+	}
+
+	result, err := h.storageRepo.GetReconciliation(id)
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Storage reconciliation not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve storage reconciliation",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
@@ -4,20 +4,33 @@ import (
 	"database/sql"
 	"math"
 	"strconv"
+	"strings"
+	"time"
 
 	"scholarship-system/internal/config"
 	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
 type StudentHandler struct {
-	cfg *config.Config
+	cfg             *config.Config
+	scholarshipRepo *repository.ScholarshipRepository
+	scoringRepo     *repository.ScoringRepository
+	eligibility     *services.EligibilityService
 }
 
 func NewStudentHandler(cfg *config.Config) *StudentHandler {
-	return &StudentHandler{cfg: cfg}
+	return &StudentHandler{
+		cfg:             cfg,
+		scholarshipRepo: repository.NewScholarshipRepository(),
+		scoringRepo:     repository.NewScoringRepository(),
+		eligibility:     services.NewEligibilityService(),
+	}
 }
 
 type StudentProfileRequest struct {
@@ -35,21 +48,24 @@ type PriorityScoreRequest struct {
 	GPA           float64 `json:"gpa" validate:"required,min=0,max=4"`
 	FamilyIncome  float64 `json:"family_income" validate:"required,min=0"`
 	ActivityCount int     `json:"activity_count" validate:"min=0"`
+	ScholarshipID uint    `json:"scholarship_id"` // score with this scholarship's profile
 }
 
 // PriorityScoreResponse represents the response with calculated score breakdown
 type PriorityScoreResponse struct {
-	TotalScore      float64  `json:"total_score"`
-	GPAScore        float64  `json:"gpa_score"`
-	FinancialScore  float64  `json:"financial_score"`
-	ActivityScore   float64  `json:"activity_score"`
-	ScoreLevel      string   `json:"score_level"`
-	Recommendations []string `json:"recommendations"`
+	TotalScore      float64                `json:"total_score"`
+	GPAScore        float64                `json:"gpa_score"`
+	FinancialScore  float64                `json:"financial_score"`
+	ActivityScore   float64                `json:"activity_score"`
+	ScoreLevel      string                 `json:"score_level"`
+	Recommendations []string               `json:"recommendations"`
+	Breakdown       *models.ScoreBreakdown `json:"breakdown"`
 }
 
-// CalculatePriorityScore calculates priority score based on PROJECT_RULES.md algorithm
+// CalculatePriorityScore estimates a priority score with the scoring profile
+// of a scholarship, or the default profile when none is given
 // @Summary Calculate priority score
-// @Description Calculate scholarship priority score using GPA (40%), financial need (30%), activities (30%)
+// @Description Estimate the scholarship priority score with the scholarship's scoring profile (default: GPA 40%, financial need 30%, activities 30%)
 // @Tags Student Profile
 // @Accept json
 // @Produce json
@@ -80,14 +96,38 @@ func (h *StudentHandler) CalculatePriorityScore(c *fiber.Ctx) error {
 		})
 	}
 
-	// Calculate scores using algorithm from PROJECT_RULES.md
-	gpaScore := calculateGPAScore(req.GPA)
-	financialScore := calculateFinancialScore(req.FamilyIncome)
-	activityScore := calculateActivityScore(req.ActivityCount)
+	profile := models.DefaultScoringProfile()
+	if req.ScholarshipID != 0 {
+		resolved, err := h.scoringRepo.Resolve(req.ScholarshipID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to retrieve scoring profile",
+			})
+		}
+		profile = resolved
+	}
 
-	// Calculate weighted total (GPA 40%, Financial 30%, Activities 30%)
-	totalScore := (gpaScore * 0.4) + (financialScore * 0.3) + (activityScore * 0.3)
-	totalScore = math.Round(totalScore*100) / 100
+	// Without a need assessment the family income curve stands in for the
+	// need score
+	breakdown := services.ScoreApplication(profile, map[string]float64{
+		models.MetricGPA:           req.GPA,
+		models.MetricFamilyIncome:  req.FamilyIncome,
+		models.MetricNeedScore:     calculateFinancialScore(req.FamilyIncome),
+		models.MetricActivityCount: float64(req.ActivityCount),
+	}, nil)
+	totalScore := breakdown.TotalScore
+
+	var gpaScore, financialScore, activityScore float64
+	for _, component := range breakdown.Components {
+		switch component.Metric {
+		case models.MetricGPA:
+			gpaScore = component.Score
+		case models.MetricNeedScore, models.MetricFamilyIncome:
+			financialScore = component.Score
+		case models.MetricActivityCount:
+			activityScore = component.Score
+		}
+	}
 
 	// Determine score level
 	scoreLevel := getScoreLevel(totalScore)
@@ -102,6 +142,7 @@ func (h *StudentHandler) CalculatePriorityScore(c *fiber.Ctx) error {
 		ActivityScore:   activityScore,
 		ScoreLevel:      scoreLevel,
 		Recommendations: recommendations,
+		Breakdown:       breakdown,
 	}
 
 	return c.JSON(fiber.Map{
@@ -111,18 +152,6 @@ func (h *StudentHandler) CalculatePriorityScore(c *fiber.Ctx) error {
 	})
 }
 
-// calculateGPAScore implements GPA scoring algorithm
-func calculateGPAScore(gpa float64) float64 {
-	// GPA 4.00 = 100 points, GPA 2.00 = 50 points (linear scale)
-	if gpa >= 4.0 {
-		return 100.0
-	}
-	if gpa <= 2.0 {
-		return 50.0
-	}
-	return 50.0 + ((gpa-2.0)/2.0)*50.0
-}
-
 // calculateFinancialScore implements financial need scoring
 func calculateFinancialScore(income float64) float64 {
 	// Lower income = higher score (inverse relationship)
@@ -136,16 +165,6 @@ func calculateFinancialScore(income float64) float64 {
 	return 100.0 - ((income-15000)/(50000-15000))*80.0
 }
 
-// calculateActivityScore implements activity scoring
-func calculateActivityScore(activityCount int) float64 {
-	// Each activity = 20 points, maximum 100 points
-	score := float64(activityCount * 20)
-	if score > 100 {
-		return 100.0
-	}
-	return score
-}
-
 // getScoreLevel determines score level description
 func getScoreLevel(score float64) string {
 	if score >= 80 {
@@ -388,72 +407,88 @@ func (h *StudentHandler) GetStudentApplicationHistory(c *fiber.Ctx) error {
 	})
 }
 
-// GetEligibleScholarships returns scholarships the student is eligible for
+// GetEligibleScholarships returns open scholarships evaluated against the student's eligibility profile
 // @Summary Get eligible scholarships
-// @Description Get scholarships that the student meets criteria for
+// @Description Evaluate every open scholarship's eligibility rules against the student's profile and return per-rule reasons
 // @Tags Student Profile
 // @Produce json
 // @Param Authorization header string true "Bearer token"
-// @Param gpa query number false "Student's current GPA"
-// @Param income query number false "Student's family income"
+// @Param gpa query number false "GPA to use when the student record has none"
+// @Param income query number false "Monthly family income to evaluate with"
+// @Param eligible_only query bool false "Only return scholarships the student is eligible for"
 // @Success 200 {object} object{data=[]object}
 // @Failure 401 {object} object{error=string}
 // @Router /student/eligible-scholarships [get]
 func (h *StudentHandler) GetEligibleScholarships(c *fiber.Ctx) error {
-	// Get query parameters
-	gpaStr := c.Query("gpa", "0")
-	incomeStr := c.Query("income", "0")
-
-	gpa, _ := strconv.ParseFloat(gpaStr, 64)
-	income, _ := strconv.ParseFloat(incomeStr, 64)
-
-	// Mock eligible scholarships based on criteria
-	scholarships := []map[string]interface{}{
-		{
-			"scholarship_id":   1,
-			"scholarship_name": "ทุนวิจัยระดับปริญญาตรี",
-			"amount":           25000,
-			"deadline":         "2024-12-31",
-			"days_left":        16,
-			"type":             "research",
-			"is_eligible":      gpa >= 3.25,
-			"eligibility_reason": func() string {
-				if gpa >= 3.25 {
-					return "เข้าเกณฑ์คุณสมบัติ"
-				}
-				return "เกรดเฉลี่ยต้องมากกว่า 3.25"
-			}(),
-		},
-		{
-			"scholarship_id":   2,
-			"scholarship_name": "ทุนความเป็นเลิศทางวิชาการ",
-			"amount":           30000,
-			"deadline":         "2025-01-15",
-			"days_left":        31,
-			"type":             "excellence",
-			"is_eligible":      gpa >= 3.75,
-			"eligibility_reason": func() string {
-				if gpa >= 3.75 {
-					return "เข้าเกณฑ์คุณสมบัติ"
-				}
-				return "เกรดเฉลี่ยต้องมากกว่า 3.75"
-			}(),
-		},
-		{
-			"scholarship_id":   3,
-			"scholarship_name": "ทุนช่วยเหลือการศึกษา",
-			"amount":           12000,
-			"deadline":         "2024-12-25",
-			"days_left":        10,
-			"type":             "financial_aid",
-			"is_eligible":      income < 30000,
-			"eligibility_reason": func() string {
-				if income < 30000 {
-					return "เข้าเกณฑ์คุณสมบัติ"
-				}
-				return "รายได้ครอบครัวต้องน้อยกว่า 30,000 บาท/เดือน"
-			}(),
-		},
+	userIDValue := c.Locals("user_id")
+	if userIDValue == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+	userID := userIDValue.(uuid.UUID)
+
+	// Get student_id from students table, falling back to email
+	var studentID string
+	err := database.DB.QueryRow(`SELECT student_id FROM students WHERE user_id = $1`, userID).Scan(&studentID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get student information",
+			})
+		}
+		studentID, _ = c.Locals("email").(string)
+	}
+
+	profile, err := h.eligibility.LoadProfile(studentID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load student profile",
+		})
+	}
+
+	if gpa, err := strconv.ParseFloat(c.Query("gpa"), 64); err == nil && profile.GPA == nil {
+		profile.GPA = &gpa
+	}
+	if income, err := strconv.ParseFloat(c.Query("income"), 64); err == nil {
+		profile.FamilyIncome = &income
+	}
+	eligibleOnly := c.QueryBool("eligible_only", false)
+
+	available, err := h.scholarshipRepo.GetAvailableScholarships()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch scholarships",
+		})
+	}
+
+	now := time.Now()
+	scholarships := []fiber.Map{}
+	for _, scholarship := range available {
+		result := services.EvaluateEligibility(scholarship.ScholarshipID, scholarship.EligibilityRules, profile)
+		if eligibleOnly && !result.IsEligible {
+			continue
+		}
+
+		reason := "เข้าเกณฑ์คุณสมบัติ"
+		if !result.IsEligible {
+			reasons := append(append([]string{}, result.FailedReasons...), result.MissingRequirements...)
+			reason = strings.Join(reasons, ", ")
+		}
+
+		scholarships = append(scholarships, fiber.Map{
+			"scholarship_id":       scholarship.ScholarshipID,
+			"scholarship_name":     scholarship.ScholarshipName,
+			"amount":               scholarship.Amount,
+			"deadline":             scholarship.ApplicationEndDate.Format("2006-01-02"),
+			"days_left":            int(math.Ceil(scholarship.ApplicationEndDate.Sub(now).Hours() / 24)),
+			"type":                 scholarship.ScholarshipType,
+			"is_eligible":          result.IsEligible,
+			"eligibility_reason":   reason,
+			"eligibility_score":    result.EligibilityScore,
+			"criteria_results":     result.Results,
+			"missing_requirements": result.MissingRequirements,
+		})
 	}
 
 	return c.JSON(fiber.Map{
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+	"scholarship-system/internal/storage"
+)
+
+// Content types accepted for application documents, as detected from the
+// file contents
+var (
+	documentTypes       = []string{storage.TypePDF, storage.TypeJPEG, storage.TypePNG}
+	officeDocumentTypes = append(append([]string{}, documentTypes...), storage.TypeDOC, storage.TypeDOCX)
+)
+
+// avatarUploadPolicy limits profile pictures
+var avatarUploadPolicy = storage.UploadPolicy{
+	MaxSize:      5 * 1024 * 1024,
+	AllowedTypes: []string{storage.TypeJPEG, storage.TypePNG, storage.TypeGIF},
+	TypeNames:    "JPEG, PNG, GIF",
+}
+
+// documentUploadPolicy is the policy for an application document. Documents
+// are quarantined until the malware scan passes them. The scholarship's
+// requirement for the document type, if any, narrows the size and formats.
+func documentUploadPolicy(cfg *config.Config, req *models.DocumentRequirement, documentType string, allowedTypes []string, typeNames string) storage.UploadPolicy {
+	allowedTypes, typeNames = services.AcceptedContentTypes(req, allowedTypes, typeNames)
+	return storage.UploadPolicy{
+		MaxSize:      documentSizeLimit(cfg, req, documentType),
+		AllowedTypes: allowedTypes,
+		TypeNames:    typeNames,
+		Quarantine:   true,
+	}
+}
+
+// documentSizeLimit is the largest file accepted for a document type. The
+// scholarship's requirement decides if it sets a limit; otherwise
+// MAX_FILE_SIZE applies unless the type has a limit of its own.
+func documentSizeLimit(cfg *config.Config, req *models.DocumentRequirement, documentType string) int64 {
+	if req != nil && req.MaxSizeMB > 0 {
+		return int64(req.MaxSizeMB) * 1024 * 1024
+	}
+	switch documentType {
+	case "id_card", "transcript", "income_certificate":
+		return 5 * 1024 * 1024
+	case "house_photos", "living_situation_photos":
+		return 20 * 1024 * 1024
+	}
+	return cfg.MaxFileSize
+}
+
+// loadDocumentRequirement returns what the scholarship of an application
+// asks of a document type, or nil when it sets nothing for the type
+func loadDocumentRequirement(requirementRepo *repository.DocumentRequirementRepository, applicationID uint, documentType string) (*models.DocumentRequirement, error) {
+	_, requirements, err := requirementRepo.GetForApplication(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	return services.FindDocumentRequirement(requirements, documentType), nil
+}
+
+// parseIssuedDate reads the optional issue date of an uploaded document
+func parseIssuedDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	issued, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("issued_date must be a date in YYYY-MM-DD format")
+	}
+	if issued.After(time.Now()) {
+		return nil, fmt.Errorf("issued_date cannot be in the future")
+	}
+	return &issued, nil
+}
+
+// queueDocumentScan makes sure a malware scan is queued for a new upload.
+// The scheduled scan picks the document up if this fails.
+func queueDocumentScan() {
+	jobRepo := repository.NewJobRepository()
+	active, err := jobRepo.HasActive(models.JobTypeDocumentScan)
+	if err != nil || active {
+		return
+	}
+	if _, err := jobRepo.Enqueue(models.JobTypeDocumentScan, fiber.Map{}, nil); err != nil {
+		log.Printf("Warning: failed to queue document scan: %v", err)
+	}
+}
+
+// scanHoldReason explains why a document's file cannot be downloaded, or
+// returns "" when it has passed the malware scan and is still kept
+func scanHoldReason(uploadStatus string) string {
+	switch uploadStatus {
+	case models.DocumentQuarantined:
+		return "Document is still being scanned for malware"
+	case models.DocumentInfected:
+		return "Document was removed because malware was found"
+	case models.DocumentPurged:
+		return "Document was deleted at the end of its retention period"
+	case models.DocumentMissing:
+		return "Document file is missing or damaged; it must be uploaded again"
+	}
+	return ""
+}
+
+// uploadFailed answers a failed storage.SaveUpload: policy violations are the
+// client's fault, anything else is ours
+func uploadFailed(c *fiber.Ctx, err error) error {
+	var uploadErr *storage.UploadError
+	if errors.As(err, &uploadErr) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": uploadErr.Message,
+		})
+	}
+	log.Printf("Upload failed: %v", err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Failed to save file",
+	})
+}
+
+// sendStoredFile streams a file from its storage backend as a download
+func sendStoredFile(c *fiber.Ctx, backend, key, filename, mimeType string) error {
+	return sendStoredFileAs(c, backend, key, filename, mimeType, false)
+}
+
+// sendStoredFileAs streams a file from its storage backend, inline for
+// display in the browser or as an attachment
+func sendStoredFileAs(c *fiber.Ctx, backend, key, filename, mimeType string, inline bool) error {
+	reader, info, err := storage.Open(c.Context(), backend, key)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "File not found on server",
+			})
+		}
+		log.Printf("Failed to read %s from %s storage: %v", key, backend, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to read file",
+		})
+	}
+
+	if mimeType == "" {
+		mimeType = info.ContentType
+	}
+	if mimeType != "" {
+		c.Set("Content-Type", mimeType)
+	}
+	disposition := "attachment"
+	if inline {
+		disposition = "inline"
+	}
+	c.Set("Content-Disposition", fmt.Sprintf(`%s; filename="%s"`, disposition, filename))
+	c.Set("X-Content-Type-Options", "nosniff")
+	c.Set("Cache-Control", "private, no-store")
+	return c.SendStream(reader, int(info.Size))
+}
+
+// removeStoredFile deletes a file whose record is already gone. Failures are
+// logged only; the orphaned file does no harm.
+func removeStoredFile(c *fiber.Ctx, backend, key string) {
+	if key == "" {
+		return
+	}
+	if err := storage.Remove(c.Context(), backend, key); err != nil {
+		fmt.Printf("Warning: Failed to delete file %s: %v\n", key, err)
+	}
+}
@@ -0,0 +1,143 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+	"scholarship-system/internal/storage"
+)
+
+// progressEvery is how often, in items, a bulk job records its progress
+const progressEvery = 10
+
+// maxBulkErrors caps the per-application errors kept in a job result
+const maxBulkErrors = 100
+
+// ExportKey is the storage key of a bulk export file
+func ExportKey(file string) string {
+	return path.Join("exports", path.Base(file))
+}
+
+// applicationBulk runs an officer's action over a selection of applications
+func applicationBulk(job *models.JobQueue) (interface{}, error) {
+	var action models.BulkActionJob
+	if err := json.Unmarshal(job.Payload, &action); err != nil {
+		return nil, fmt.Errorf("invalid bulk action payload: %w", err)
+	}
+	if job.CreatedBy == nil {
+		return nil, fmt.Errorf("bulk action has no requesting user")
+	}
+
+	jobRepo := repository.NewJobRepository()
+	viewRepo := repository.NewApplicationViewRepository()
+	total := len(action.ApplicationIDs)
+	result := &models.BulkActionResult{Action: action.Action, Total: total, Errors: []models.BulkItemError{}}
+	progress := func(done int) {
+		if err := jobRepo.SetProgress(job.JobID, done, total); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+	progress(0)
+
+	if action.Action == models.BulkExport {
+		file, backend, err := exportApplications(viewRepo, job, action.ApplicationIDs)
+		if err != nil {
+			return nil, err
+		}
+		result.Succeeded = total
+		result.ExportFile = file
+		result.ExportBackend = backend
+		progress(total)
+		return result, nil
+	}
+
+	for i, applicationID := range action.ApplicationIDs {
+		done, err := applyBulkAction(viewRepo, &action, job, applicationID)
+		switch {
+		case err != nil:
+			result.Failed++
+			if len(result.Errors) < maxBulkErrors {
+				result.Errors = append(result.Errors, models.BulkItemError{ApplicationID: applicationID, Error: err.Error()})
+			}
+		case done:
+			result.Succeeded++
+		default:
+			result.Skipped++
+		}
+		if (i+1)%progressEvery == 0 {
+			progress(i + 1)
+		}
+	}
+	progress(total)
+	return result, nil
+}
+
+// applyBulkAction runs the action on one application. It reports false when
+// there was nothing to do.
+func applyBulkAction(viewRepo *repository.ApplicationViewRepository, action *models.BulkActionJob, job *models.JobQueue, applicationID uint) (bool, error) {
+	switch action.Action {
+	case models.BulkAssignReviewer:
+		return viewRepo.AssignReviewer(applicationID, *action.ReviewerID, action.ReviewStage)
+
+	case models.BulkChangeStatus:
+		current, err := viewRepo.GetStatus(applicationID)
+		if err != nil {
+			return false, fmt.Errorf("application not found")
+		}
+		if current == action.Status {
+			return false, nil
+		}
+		if err := services.CheckStatusTransition(current, action.Status); err != nil {
+			return false, err
+		}
+		changed, err := viewRepo.ChangeStatus(applicationID, current, action.Status, *job.CreatedBy, action.Notes)
+		if err != nil {
+			return false, err
+		}
+		if !changed {
+			return false, fmt.Errorf("status changed while the job was running")
+		}
+		return true, nil
+
+	case models.BulkNotify:
+		sent, err := viewRepo.NotifyApplicant(applicationID, "application_bulk_notice", action.Title, action.Message)
+		if err != nil {
+			return false, err
+		}
+		if !sent {
+			return false, fmt.Errorf("student has no user account")
+		}
+		return true, nil
+	}
+	return false, fmt.Errorf("unknown action %q", action.Action)
+}
+
+// exportApplications writes the selection as CSV to storage and returns the
+// file name and backend
+func exportApplications(viewRepo *repository.ApplicationViewRepository, job *models.JobQueue, ids []uint) (string, string, error) {
+	items, err := viewRepo.GetListItems(ids)
+	if err != nil {
+		return "", "", err
+	}
+
+	// BOM so spreadsheet programs read the Thai text as UTF-8
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	if err := services.WriteApplicationsCSV(&buf, items); err != nil {
+		return "", "", fmt.Errorf("failed to write export: %w", err)
+	}
+
+	store := storage.Default()
+	name := fmt.Sprintf("applications-%s.csv", job.JobID)
+	if err := store.Put(context.Background(), ExportKey(name), &buf, int64(buf.Len()), "text/csv; charset=utf-8"); err != nil {
+		return "", "", fmt.Errorf("failed to store export: %w", err)
+	}
+	return name, store.Name(), nil
+}
@@ -0,0 +1,242 @@
+package jobs
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+	"scholarship-system/internal/storage"
+)
+
+// BundleFont returns the font generated PDFs are set in. A font that fails
+// to load is logged and Courier, which has no Thai, is used instead.
+func BundleFont(cfg *config.Config) *services.PDFFont {
+	font, err := services.LoadPDFFontFile(cfg.PDFFontPath)
+	if err != nil {
+		log.Printf("Warning: %v; PDFs are set in Courier", err)
+	}
+	return font
+}
+
+// LoadDocumentBundle gathers the bundle of an application: a manifest of its
+// documents, its form and a way to open the document files. The form is the
+// latest submission, or the live form of an application never submitted.
+// It returns sql.ErrNoRows when the application does not exist.
+func LoadDocumentBundle(applicationID uint) (*models.DocumentBundleManifest, json.RawMessage, services.BundleOpener, error) {
+	items, err := repository.NewApplicationViewRepository().GetListItems([]uint{applicationID})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(items) == 0 {
+		return nil, nil, nil, sql.ErrNoRows
+	}
+	item := items[0]
+	manifest := &models.DocumentBundleManifest{
+		ApplicationID:     item.ApplicationID,
+		StudentID:         item.StudentID,
+		StudentName:       item.StudentName,
+		ScholarshipID:     item.ScholarshipID,
+		ScholarshipName:   item.ScholarshipName,
+		ApplicationStatus: item.ApplicationStatus,
+		FormFile:          services.BundleFormFile(item.StudentID),
+		GeneratedAt:       time.Now(),
+	}
+
+	var form json.RawMessage
+	snapshot, err := repository.NewSnapshotRepository().GetLatest(applicationID)
+	switch {
+	case err == nil:
+		form = snapshot.FormData
+		manifest.FormVersion = snapshot.Version
+		manifest.FormChecksum = snapshot.Checksum
+	case err == sql.ErrNoRows:
+		live, err := repository.NewApplicationDetailsRepository().GetCompleteForm(applicationID)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to load application form: %w", err)
+		}
+		if form, err = json.Marshal(live); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to encode application form: %w", err)
+		}
+	default:
+		return nil, nil, nil, err
+	}
+
+	documents, err := repository.NewApplicationRepository().GetDocuments(applicationID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	manifest.Documents = services.PlanDocumentBundle(item.StudentID, documents)
+
+	byID := make(map[int]models.ApplicationDocument, len(documents))
+	for _, doc := range documents {
+		byID[doc.DocumentID] = doc
+	}
+	open := func(documentID int) (io.ReadCloser, error) {
+		doc, ok := byID[documentID]
+		if !ok {
+			return nil, storage.ErrNotFound
+		}
+		reader, _, err := storage.Open(context.Background(), doc.StorageBackend, doc.FilePath)
+		return reader, err
+	}
+	return manifest, form, open, nil
+}
+
+// CommitteePackDownloadURL is where the file of a committee pack job is
+// downloaded from
+func CommitteePackDownloadURL(job *models.JobQueue) string {
+	return fmt.Sprintf("/api/v1/committee-packs/%s/download", job.JobID)
+}
+
+// committeePack writes the bundles of a scholarship's shortlisted
+// applications into one ZIP: a folder per application, or a merged PDF per
+// application in the pdf format, with an index of them all
+func committeePack(cfg *config.Config) Handler {
+	return func(job *models.JobQueue) (interface{}, error) {
+		var pack models.CommitteePackJob
+		if err := json.Unmarshal(job.Payload, &pack); err != nil {
+			return nil, fmt.Errorf("invalid committee pack payload: %w", err)
+		}
+
+		jobRepo := repository.NewJobRepository()
+		font := BundleFont(cfg)
+		total := len(pack.ApplicationIDs)
+		result := &models.CommitteePackResult{
+			ScholarshipID: pack.ScholarshipID,
+			Format:        pack.Format,
+			Total:         total,
+			Errors:        []models.BulkItemError{},
+		}
+		progress := func(done int) {
+			if err := jobRepo.SetProgress(job.JobID, done, total); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		}
+		progress(0)
+
+		// Packs can be large, so they are built on disk rather than in memory
+		file, err := os.CreateTemp("", "committee-pack-*.zip")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create pack file: %w", err)
+		}
+		defer os.Remove(file.Name())
+		defer file.Close()
+
+		zw := zip.NewWriter(file)
+		index := make([]models.CommitteePackEntry, 0, total)
+		for i, applicationID := range pack.ApplicationIDs {
+			entry, err := packApplication(zw, font, pack.Format, applicationID)
+			if err != nil {
+				return nil, err
+			}
+			if entry.Error != "" {
+				result.Failed++
+				if len(result.Errors) < maxBulkErrors {
+					result.Errors = append(result.Errors, models.BulkItemError{ApplicationID: applicationID, Error: entry.Error})
+				}
+			} else {
+				result.Succeeded++
+			}
+			index = append(index, entry)
+			progress(i + 1)
+		}
+
+		// BOM so spreadsheet programs read the Thai names as UTF-8
+		w, err := zw.Create("manifest.csv")
+		if err != nil {
+			return nil, err
+		}
+		io.WriteString(w, "\ufeff")
+		if err := services.WriteCommitteePackIndex(w, index); err != nil {
+			return nil, fmt.Errorf("failed to write pack index: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("failed to write pack: %w", err)
+		}
+
+		size, err := file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		store := storage.Default()
+		name := fmt.Sprintf("committee-pack-%s.zip", job.JobID)
+		if err := store.Put(context.Background(), ExportKey(name), file, size, "application/zip"); err != nil {
+			return nil, fmt.Errorf("failed to store pack: %w", err)
+		}
+
+		result.ExportFile = name
+		result.ExportBackend = store.Name()
+		result.DownloadURL = CommitteePackDownloadURL(job)
+		return result, nil
+	}
+}
+
+// packApplication adds the bundle of one application to a pack. Problems
+// with the application are reported in the entry; only failures to write the
+// pack are returned as errors.
+func packApplication(zw *zip.Writer, font *services.PDFFont, format string, applicationID uint) (models.CommitteePackEntry, error) {
+	entry := models.CommitteePackEntry{ApplicationID: applicationID}
+	manifest, form, open, err := LoadDocumentBundle(applicationID)
+	if err == sql.ErrNoRows {
+		entry.Error = "application not found"
+		return entry, nil
+	}
+	if err != nil {
+		entry.Error = err.Error()
+		return entry, nil
+	}
+	entry.StudentID = manifest.StudentID
+	entry.StudentName = manifest.StudentName
+	entry.ApplicationStatus = manifest.ApplicationStatus
+	folder := services.BundleFolder(manifest.StudentID, applicationID)
+
+	if format == models.BundleFormatPDF {
+		// Rendered first so a broken form leaves no half-written file
+		var buf bytes.Buffer
+		if err := services.WriteBundlePDF(&buf, font, manifest, form, open); err != nil {
+			entry.Error = err.Error()
+			return entry, nil
+		}
+		entry.Path = folder + ".pdf"
+		w, err := zw.Create(entry.Path)
+		if err == nil {
+			_, err = w.Write(buf.Bytes())
+		}
+		if err != nil {
+			return entry, fmt.Errorf("failed to write pack: %w", err)
+		}
+	} else {
+		formPDF, err := services.ApplicationFormPDF(font, manifest, form)
+		if err != nil {
+			entry.Error = err.Error()
+			return entry, nil
+		}
+		entry.Path = folder + "/"
+		if err := services.WriteBundleZip(zw, entry.Path, manifest, formPDF, open); err != nil {
+			return entry, fmt.Errorf("failed to write pack: %w", err)
+		}
+	}
+
+	for _, doc := range manifest.Documents {
+		if doc.File == "" || (format == models.BundleFormatPDF && doc.Note != "") {
+			entry.LeftOut++
+		} else {
+			entry.Documents++
+		}
+	}
+	return entry, nil
+}
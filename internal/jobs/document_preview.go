@@ -0,0 +1,142 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+	"scholarship-system/internal/storage"
+)
+
+// documentPreviewBatch is how many documents are loaded per query
+const documentPreviewBatch = 50
+
+// maxPreviewSourceSize skips files too large to load for a preview
+const maxPreviewSourceSize = 100 << 20
+
+// previewKey is where the preview of a document is kept. Every version of
+// the document shares it, so a new preview replaces the last one.
+func previewKey(documentID int) string {
+	return fmt.Sprintf("previews/documents/%d.jpg", documentID)
+}
+
+// documentPreview generates the previews, page counts and image dimensions
+// of documents that passed the malware scan. Files that cannot be read are
+// marked failed and not tried again until they are replaced; storage errors
+// leave the document for the next run.
+func documentPreview(job *models.JobQueue) (interface{}, error) {
+	result := &models.DocumentPreviewResult{}
+	storageRepo := repository.NewStorageRepository()
+	ctx := context.Background()
+	fail := func(doc models.ApplicationDocument, err error) {
+		result.Failed++
+		if len(result.Errors) < maxBulkErrors {
+			result.Errors = append(result.Errors, fmt.Sprintf("document %d: %v", doc.DocumentID, err))
+		}
+	}
+
+	afterID := 0
+	for {
+		docs, err := storageRepo.ListPendingPreviews(afterID, documentPreviewBatch)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			afterID = doc.DocumentID
+			status, err := generatePreview(ctx, storageRepo, doc)
+			switch {
+			case err != nil:
+				fail(doc, err)
+			case status == models.PreviewReady:
+				result.Generated++
+			case status == models.PreviewUnavailable:
+				result.Unavailable++
+			}
+		}
+		if len(docs) < documentPreviewBatch {
+			return result, nil
+		}
+	}
+}
+
+// generatePreview stores the preview of one document and records it. It
+// returns "" when the document changed while the preview was made, and an
+// error when the file could not be previewed.
+func generatePreview(ctx context.Context, storageRepo *repository.StorageRepository, doc models.ApplicationDocument) (string, error) {
+	reader, info, err := storage.Open(ctx, doc.StorageBackend, doc.FilePath)
+	if errors.Is(err, storage.ErrNotFound) {
+		return previewFailed(ctx, storageRepo, doc, err)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	if info.Size > maxPreviewSourceSize {
+		return savePreview(ctx, storageRepo, doc, models.PreviewUnavailable, "", nil)
+	}
+	data, err := io.ReadAll(io.LimitReader(reader, maxPreviewSourceSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	preview, err := services.GeneratePreview(data)
+	if err != nil {
+		return previewFailed(ctx, storageRepo, doc, err)
+	}
+	if preview.Image == nil {
+		return savePreview(ctx, storageRepo, doc, models.PreviewUnavailable, "", preview)
+	}
+
+	store, err := storage.Backend(doc.StorageBackend)
+	if err != nil {
+		return "", err
+	}
+	key := previewKey(doc.DocumentID)
+	if err := store.Put(ctx, key, bytes.NewReader(preview.Image), int64(len(preview.Image)), storage.TypeJPEG); err != nil {
+		return "", fmt.Errorf("failed to store preview: %w", err)
+	}
+	status, err := savePreview(ctx, storageRepo, doc, models.PreviewReady, key, preview)
+	if status == "" {
+		// Only one preview job runs at a time, so nothing else wrote the key
+		if delErr := store.Delete(ctx, key); delErr != nil {
+			log.Printf("Warning: %v", delErr)
+		}
+	}
+	return status, err
+}
+
+// savePreview records the outcome for a document and deletes the preview of
+// its previous version when that is no longer where the new one went
+func savePreview(ctx context.Context, storageRepo *repository.StorageRepository, doc models.ApplicationDocument,
+	status, key string, preview *models.DocumentPreview) (string, error) {
+	backend := doc.StorageBackend
+	if key == "" {
+		backend = ""
+	}
+	saved, err := storageRepo.SavePreview(doc, status, backend, key, preview)
+	if err != nil || !saved {
+		return "", err
+	}
+	stale := doc.PreviewPath != "" && (doc.PreviewPath != key || doc.PreviewBackend != doc.StorageBackend)
+	if stale {
+		if err := storage.Remove(ctx, doc.PreviewBackend, doc.PreviewPath); err != nil {
+			log.Printf("Warning: failed to delete old preview %s: %v", doc.PreviewPath, err)
+		}
+	}
+	return status, nil
+}
+
+// previewFailed marks a document whose file could not be read, so it is not
+// tried again until it is replaced
+func previewFailed(ctx context.Context, storageRepo *repository.StorageRepository, doc models.ApplicationDocument, cause error) (string, error) {
+	if _, err := savePreview(ctx, storageRepo, doc, models.PreviewFailed, "", nil); err != nil {
+		return "", err
+	}
+	return models.PreviewFailed, cause
+}
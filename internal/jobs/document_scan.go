@@ -0,0 +1,90 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/storage"
+)
+
+// documentScanBatch is how many quarantined documents are loaded per query
+const documentScanBatch = 100
+
+// documentScan runs quarantined uploads through the malware scanner. Clean
+// files are moved out of quarantine and become pending; infected files are
+// deleted. Documents that could not be scanned stay quarantined for the next
+// run.
+func documentScan(job *models.JobQueue) (interface{}, error) {
+	result := &models.DocumentScanResult{}
+	scanner := storage.DefaultScanner()
+	if scanner == nil {
+		result.Errors = append(result.Errors, "no malware scanner is configured (CLAMD_ADDRESS); documents stay quarantined")
+		return result, nil
+	}
+
+	storageRepo := repository.NewStorageRepository()
+	ctx := context.Background()
+	fail := func(doc models.ApplicationDocument, err error) {
+		result.Failed++
+		if len(result.Errors) < maxBulkErrors {
+			result.Errors = append(result.Errors, fmt.Sprintf("document %d: %v", doc.DocumentID, err))
+		}
+	}
+
+	afterID := 0
+	for {
+		docs, err := storageRepo.ListQuarantined(afterID, documentScanBatch)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			afterID = doc.DocumentID
+			verdict, err := storage.ScanStored(ctx, scanner, doc.StorageBackend, doc.FilePath)
+			if err != nil {
+				fail(doc, err)
+				continue
+			}
+			result.Scanned++
+
+			if !verdict.Clean {
+				if err := storage.Remove(ctx, doc.StorageBackend, doc.FilePath); err != nil {
+					log.Printf("Warning: failed to delete infected file %s: %v", doc.FilePath, err)
+				}
+				if err := storageRepo.MarkInfected(doc, verdict.Signature); err != nil {
+					fail(doc, err)
+					continue
+				}
+				log.Printf("Document %d of application %d is infected: %s", doc.DocumentID, doc.ApplicationID, verdict.Signature)
+				result.Infected++
+				continue
+			}
+
+			key, err := storage.Release(ctx, doc.StorageBackend, doc.FilePath)
+			if err != nil {
+				fail(doc, err)
+				continue
+			}
+			released, err := storageRepo.ReleaseDocument(doc, key)
+			if err != nil || !released {
+				// The document was replaced or deleted while it was scanned
+				if delErr := storage.Remove(ctx, doc.StorageBackend, key); delErr != nil {
+					log.Printf("Warning: %v", delErr)
+				}
+				if err != nil {
+					fail(doc, err)
+				}
+				continue
+			}
+			if err := storage.Remove(ctx, doc.StorageBackend, doc.FilePath); err != nil {
+				log.Printf("Warning: failed to delete quarantined copy %s: %v", doc.FilePath, err)
+			}
+			result.Released++
+		}
+		if len(docs) < documentScanBatch {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+	"scholarship-system/internal/storage"
+)
+
+// fraudScan compares all submitted applications for shared bank accounts,
+// guardian IDs and files, and for assets out of line with the income
+func fraudScan(cfg *config.Config) Handler {
+	params := models.FraudScanParams{
+		AssetIncomeYears: float64(cfg.FraudAssetIncomeYears),
+		MinAssetValue:    float64(cfg.FraudMinAssetValue),
+	}
+
+	return func(job *models.JobQueue) (interface{}, error) {
+		redFlagRepo := repository.NewRedFlagRepository()
+		runStart := time.Now()
+
+		// Documents uploaded before hashes were stored are hashed on the first run
+		documents, err := redFlagRepo.ListUnhashedDocuments()
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range documents {
+			hash := storage.HashDocument(doc)
+			if hash == "" {
+				continue
+			}
+			if err := redFlagRepo.SetDocumentHash(doc.DocumentID, hash); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		}
+
+		input, err := redFlagRepo.LoadScanInput()
+		if err != nil {
+			return nil, err
+		}
+		flags := services.DetectRedFlags(*input, params)
+
+		result := services.NewFraudScanResult(flags)
+		result.New, result.Resolved, err = redFlagRepo.SaveFindings(flags, runStart)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+}
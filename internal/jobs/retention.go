@@ -0,0 +1,131 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+	"scholarship-system/internal/storage"
+)
+
+// retentionBatch is how many applications are loaded per query
+const retentionBatch = 200
+
+// maxRetentionItems is how many actions a run lists in its result
+const maxRetentionItems = 1000
+
+// retentionRun purges or anonymizes the documents and form details whose
+// retention period is over. Files are deleted while the application row is
+// locked, just before their rows are committed, so a failed action is retried
+// in full on the next run. A dry run only reports what is due. Applications on
+// legal hold are skipped.
+func retentionRun(dryRun bool) Handler {
+	return func(job *models.JobQueue) (interface{}, error) {
+		retentionRepo := repository.NewRetentionRepository()
+		rules, err := retentionRepo.ListRules()
+		if err != nil {
+			return nil, err
+		}
+
+		result := &models.RetentionRunResult{DryRun: dryRun}
+		ctx := context.Background()
+		now := time.Now()
+		fail := func(action *models.RetentionAction, err error) {
+			action.Error = err.Error()
+			result.Failed++
+			if len(result.Errors) < maxBulkErrors {
+				result.Errors = append(result.Errors, fmt.Sprintf("application %d %s: %v", action.ApplicationID, action.RecordType, err))
+			}
+		}
+
+		afterID := 0
+		for {
+			candidates, err := retentionRepo.ListCandidates(services.RetentionStatuses(), afterID, retentionBatch)
+			if err != nil {
+				return nil, err
+			}
+			for _, candidate := range candidates {
+				afterID = candidate.ApplicationID
+				result.Applications++
+				if candidate.AnnouncementDate == nil {
+					result.NoAnnouncement++
+					continue
+				}
+
+				documents, err := retentionRepo.ListDocuments(candidate.ApplicationID)
+				if err != nil {
+					return nil, err
+				}
+				actions := services.PlanRetention(candidate, documents, rules, now)
+				if len(actions) == 0 {
+					continue
+				}
+				if candidate.LegalHold {
+					result.Held++
+					continue
+				}
+				if !dryRun {
+					// The hold may have been placed since the batch was loaded
+					if held, err := retentionRepo.IsOnLegalHold(candidate.ApplicationID); err != nil || held {
+						if err != nil {
+							return nil, err
+						}
+						result.Held++
+						continue
+					}
+				}
+
+				for i := range actions {
+					action := &actions[i]
+					if !dryRun {
+						if err := applyRetention(ctx, retentionRepo, action, job); err != nil {
+							if errors.Is(err, repository.ErrLegalHold) {
+								result.Held++
+								break
+							}
+							fail(action, err)
+						}
+					}
+					if action.Error == "" {
+						if action.RecordType == models.RetentionDocuments {
+							result.Documents++
+						} else {
+							result.Details++
+						}
+						result.FilesDeleted += action.Files
+					}
+					if len(result.Items) < maxRetentionItems {
+						result.Items = append(result.Items, *action)
+					}
+				}
+			}
+			if len(candidates) < retentionBatch {
+				return result, nil
+			}
+		}
+	}
+}
+
+// applyRetention deletes the rows and stored files of a due action
+func applyRetention(ctx context.Context, retentionRepo *repository.RetentionRepository, action *models.RetentionAction, job *models.JobQueue) error {
+	removeFiles := func() error {
+		if action.Document == nil {
+			return nil
+		}
+		for _, file := range action.Document.Files {
+			if err := storage.Remove(ctx, file.Backend, file.Key); err != nil {
+				return fmt.Errorf("failed to delete %s: %w", file.Key, err)
+			}
+		}
+		return nil
+	}
+	if err := retentionRepo.ApplyRetention(action, &job.JobID, removeFiles); err != nil {
+		return err
+	}
+	action.Done = true
+	return nil
+}
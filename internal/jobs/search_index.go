@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"encoding/json"
+	"time"
+
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/services"
+)
+
+// searchIndexBatch is how many applications are indexed per query
+const searchIndexBatch = 200
+
+// searchIndex refreshes the search index of applications changed since they
+// were last indexed. With {"full": true} every application is reindexed.
+func searchIndex(job *models.JobQueue) (interface{}, error) {
+	searchRepo := repository.NewSearchRepository()
+	result := &models.SearchIndexResult{RanAt: time.Now()}
+
+	var payload struct {
+		Full bool `json:"full"`
+	}
+	if len(job.Payload) > 0 {
+		_ = json.Unmarshal(job.Payload, &payload)
+	}
+	if payload.Full {
+		if err := searchRepo.MarkAllStale(); err != nil {
+			return nil, err
+		}
+		result.Full = true
+	}
+
+	for {
+		sources, err := searchRepo.ListStale(searchIndexBatch)
+		if err != nil {
+			return nil, err
+		}
+		for _, src := range sources {
+			if err := searchRepo.Save(services.BuildSearchDocument(src)); err != nil {
+				return nil, err
+			}
+			result.Indexed++
+		}
+		if len(sources) < searchIndexBatch {
+			return result, nil
+		}
+	}
+}
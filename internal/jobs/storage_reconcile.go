@@ -0,0 +1,118 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/storage"
+)
+
+// maxReconcileIssues is how many inconsistencies a reconciliation lists; the
+// totals count them all
+const maxReconcileIssues = 1000
+
+// reconcileGracePeriod skips files written this recently, as an upload stores
+// its file before the record pointing at it
+const reconcileGracePeriod = time.Hour
+
+// storageReconcile checks the files of every configured backend against
+// their records. Scheduled runs only report; runs queued by an administrator
+// may quarantine orphaned files and repair records.
+func storageReconcile(job *models.JobQueue) (interface{}, error) {
+	var req models.StorageReconcileRequest
+	if len(job.Payload) > 0 {
+		if err := json.Unmarshal(job.Payload, &req); err != nil {
+			return nil, fmt.Errorf("invalid reconciliation payload: %w", err)
+		}
+	}
+	jobID := job.JobID
+	return ReconcileStorage(context.Background(), storage.Backends(), req, models.ReconcileSourceJob, &jobID)
+}
+
+// ReconcileStorage reports the files no record points at, the records whose
+// file is gone and the files that differ from the hash recorded at upload,
+// optionally moving orphaned files aside and marking broken records, and
+// stores the result for the admin dashboard. It is shared by the job and
+// cmd/migrate.
+func ReconcileStorage(ctx context.Context, stores []storage.Storage, req models.StorageReconcileRequest, source string, jobID *uuid.UUID) (*models.StorageReconcileResult, error) {
+	result := &models.StorageReconcileResult{
+		Source:       source,
+		JobID:        jobID,
+		Backends:     []string{},
+		VerifyHashes: req.VerifyHashes == nil || *req.VerifyHashes,
+		Quarantine:   req.Quarantine,
+		Repair:       req.Repair,
+		Issues:       []models.ReconcileIssue{},
+		StartedAt:    time.Now(),
+	}
+	storageRepo := repository.NewStorageRepository()
+	fail := func(issue *models.ReconcileIssue, message string) {
+		issue.Error = message
+		result.Failed++
+		if len(result.Errors) < maxBulkErrors {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s %s: %s", issue.Backend, issue.Key, message))
+		}
+	}
+
+	for _, store := range stores {
+		result.Backends = append(result.Backends, store.Name())
+		objects, err := storageRepo.ListObjects(store.Name())
+		if err != nil {
+			return nil, err
+		}
+		result.RecordsChecked += len(objects)
+
+		issues, scanned, err := storage.Reconcile(ctx, store, objects, storage.ReconcileOptions{
+			VerifyHashes: result.VerifyHashes,
+			Quarantine:   req.Quarantine,
+			GracePeriod:  reconcileGracePeriod,
+		})
+		result.FilesScanned += scanned
+		if err != nil {
+			return nil, fmt.Errorf("failed to reconcile %s: %w", store.Name(), err)
+		}
+
+		for i := range issues {
+			issue := &issues[i]
+			switch issue.Kind {
+			case models.ReconcileOrphanFile:
+				result.OrphanFiles++
+			case models.ReconcileMissingFile:
+				result.MissingFiles++
+			case models.ReconcileHashMismatch:
+				result.HashMismatches++
+			}
+
+			switch {
+			case issue.Action == models.ReconcileQuarantined:
+				result.Quarantined++
+			case issue.Error != "":
+				fail(issue, issue.Error) // the file could not be moved aside
+			case req.Repair && issue.Kind != models.ReconcileOrphanFile:
+				repaired, err := storageRepo.RepairObject(*issue)
+				if err != nil {
+					fail(issue, err.Error())
+				} else if repaired {
+					issue.Action = models.ReconcileRepaired
+					result.Repaired++
+				}
+			}
+
+			if len(result.Issues) < maxReconcileIssues {
+				result.Issues = append(result.Issues, *issue)
+			}
+		}
+	}
+
+	result.FinishedAt = time.Now()
+	if err := storageRepo.SaveReconciliation(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
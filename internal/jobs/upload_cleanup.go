@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+	"scholarship-system/internal/storage"
+)
+
+// uploadCleanupBatch is how many expired sessions are loaded per query
+const uploadCleanupBatch = 100
+
+// uploadCleanup deletes upload sessions that have expired, with the chunks of
+// any upload that was never finished. Sessions whose chunks could not all be
+// deleted are kept for the next run.
+func uploadCleanup(job *models.JobQueue) (interface{}, error) {
+	result := &models.UploadCleanupResult{}
+	sessionRepo := repository.NewUploadSessionRepository()
+	ctx := context.Background()
+	now := time.Now()
+
+	for {
+		ids, err := sessionRepo.ListExpiredSessions(now, uploadCleanupBatch)
+		if err != nil {
+			return nil, err
+		}
+		deleted := 0
+		for _, id := range ids {
+			if err := cleanupSession(ctx, sessionRepo, id, result); err != nil {
+				result.Failed++
+				if len(result.Errors) < maxBulkErrors {
+					result.Errors = append(result.Errors, fmt.Sprintf("session %d: %v", id, err))
+				}
+				continue
+			}
+			deleted++
+		}
+		result.Sessions += deleted
+		// Stop when the last batch was short, or when every session in it
+		// failed and would only be loaded again
+		if len(ids) < uploadCleanupBatch || deleted == 0 {
+			return result, nil
+		}
+	}
+}
+
+func cleanupSession(ctx context.Context, sessionRepo *repository.UploadSessionRepository, sessionID int, result *models.UploadCleanupResult) error {
+	chunks, err := sessionRepo.ListSessionChunks(sessionID)
+	if err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		if err := storage.Remove(ctx, chunk.Backend, chunk.Key); err != nil {
+			return fmt.Errorf("failed to delete chunk %s: %w", chunk.Key, err)
+		}
+		result.Chunks++
+	}
+	return sessionRepo.DeleteSession(sessionID)
+}
@@ -0,0 +1,180 @@
+// Package jobs runs the background jobs queued in job_queue
+package jobs
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+)
+
+// staleAfter is how long a job may stay running before it is assumed to
+// belong to a worker that was stopped
+const staleAfter = time.Hour
+
+// Handler runs a job and returns its result, which is stored as JSON
+type Handler func(job *models.JobQueue) (interface{}, error)
+
+type schedule struct {
+	jobType string
+	every   time.Duration
+}
+
+// Worker polls job_queue and runs the jobs it has handlers for
+type Worker struct {
+	jobRepo      *repository.JobRepository
+	handlers     map[string]Handler
+	schedules    []schedule
+	pollInterval time.Duration
+}
+
+// NewWorker creates a worker with the built-in jobs registered
+func NewWorker(cfg *config.Config) *Worker {
+	w := &Worker{
+		jobRepo:      repository.NewJobRepository(),
+		handlers:     map[string]Handler{},
+		pollInterval: time.Duration(cfg.JobPollSeconds) * time.Second,
+	}
+	if w.pollInterval <= 0 {
+		w.pollInterval = 5 * time.Second
+	}
+
+	w.Register(models.JobTypeFraudScan, fraudScan(cfg))
+	if cfg.FraudScanIntervalHours > 0 {
+		w.Schedule(models.JobTypeFraudScan, time.Duration(cfg.FraudScanIntervalHours)*time.Hour)
+	}
+
+	w.Register(models.JobTypeApplicationBulk, applicationBulk)
+	w.Register(models.JobTypeSearchIndex, searchIndex)
+	if cfg.SearchIndexIntervalMinutes > 0 {
+		w.Schedule(models.JobTypeSearchIndex, time.Duration(cfg.SearchIndexIntervalMinutes)*time.Minute)
+	}
+
+	// Uploads queue a scan themselves; the schedule retries failed scans
+	w.Register(models.JobTypeDocumentScan, documentScan)
+	if cfg.DocumentScanIntervalMinutes > 0 {
+		w.Schedule(models.JobTypeDocumentScan, time.Duration(cfg.DocumentScanIntervalMinutes)*time.Minute)
+	}
+
+	w.Register(models.JobTypeDocumentPreview, documentPreview)
+	if cfg.DocumentPreviewIntervalMinutes > 0 {
+		w.Schedule(models.JobTypeDocumentPreview, time.Duration(cfg.DocumentPreviewIntervalMinutes)*time.Minute)
+	}
+
+	w.Register(models.JobTypeCommitteePack, committeePack(cfg))
+
+	w.Register(models.JobTypeUploadCleanup, uploadCleanup)
+	if cfg.UploadCleanupIntervalMinutes > 0 {
+		w.Schedule(models.JobTypeUploadCleanup, time.Duration(cfg.UploadCleanupIntervalMinutes)*time.Minute)
+	}
+
+	// Dry runs have their own type so they do not hold back the schedule
+	w.Register(models.JobTypeRetentionReport, retentionRun(true))
+	w.Register(models.JobTypeRetentionPurge, retentionRun(false))
+	if cfg.RetentionPurgeIntervalHours > 0 {
+		w.Schedule(models.JobTypeRetentionPurge, time.Duration(cfg.RetentionPurgeIntervalHours)*time.Hour)
+	}
+
+	w.Register(models.JobTypeStorageReconcile, storageReconcile)
+	if cfg.StorageReconcileIntervalHours > 0 {
+		w.Schedule(models.JobTypeStorageReconcile, time.Duration(cfg.StorageReconcileIntervalHours)*time.Hour)
+	}
+	return w
+}
+
+// Register sets the handler of a job type
+func (w *Worker) Register(jobType string, handler Handler) {
+	w.handlers[jobType] = handler
+}
+
+// Schedule queues a job of the type whenever the last one was queued more
+// than every ago
+func (w *Worker) Schedule(jobType string, every time.Duration) {
+	w.schedules = append(w.schedules, schedule{jobType: jobType, every: every})
+}
+
+// Start runs the worker in the background
+func (w *Worker) Start() {
+	if count, err := w.jobRepo.RequeueStale(time.Now().Add(-staleAfter)); err != nil {
+		log.Printf("Warning: %v", err)
+	} else if count > 0 {
+		log.Printf("Requeued %d stale jobs", count)
+	}
+
+	go func() {
+		ticker := time.NewTicker(w.pollInterval)
+		defer ticker.Stop()
+		for {
+			w.enqueueScheduled()
+			w.runPending()
+			<-ticker.C
+		}
+	}()
+}
+
+func (w *Worker) enqueueScheduled() {
+	for _, s := range w.schedules {
+		last, err := w.jobRepo.List(s.jobType, 1)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+			continue
+		}
+		if len(last) > 0 && (last[0].Status == "pending" || last[0].Status == "running" || time.Since(last[0].ScheduledAt) < s.every) {
+			continue
+		}
+		if _, err := w.jobRepo.Enqueue(s.jobType, nil, nil); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+}
+
+func (w *Worker) runPending() {
+	jobTypes := make([]string, 0, len(w.handlers))
+	for jobType := range w.handlers {
+		jobTypes = append(jobTypes, jobType)
+	}
+
+	for {
+		job, err := w.jobRepo.Claim(jobTypes)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+			return
+		}
+		if job == nil {
+			return
+		}
+		w.run(job)
+	}
+}
+
+func (w *Worker) run(job *models.JobQueue) {
+	started := time.Now()
+	result, err := w.safeRun(job)
+	if err != nil {
+		log.Printf("Job %s (%s) failed on attempt %d: %v", job.JobID, job.JobType, job.Attempts, err)
+		retryAfter := time.Duration(job.Attempts) * time.Minute
+		if err := w.jobRepo.Fail(job.JobID, err.Error(), retryAfter); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		return
+	}
+
+	log.Printf("Job %s (%s) completed in %s", job.JobID, job.JobType, time.Since(started).Round(time.Millisecond))
+	if err := w.jobRepo.Complete(job.JobID, result); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// safeRun turns a panicking handler into a failed job instead of a crashed
+// server
+func (w *Worker) safeRun(job *models.JobQueue) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return w.handlers[job.JobType](job)
+}
@@ -29,7 +29,9 @@ func CORSMiddleware() fiber.Handler {
 	return cors.New(cors.Config{
 		AllowOrigins: strings.Join(allowedOrigins, ","),
 		AllowMethods: "GET,POST,HEAD,PUT,DELETE,PATCH,OPTIONS",
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization, X-Requested-With",
+		AllowHeaders: "Origin, Content-Type, Accept, Authorization, X-Requested-With, Upload-Offset",
+		// Resumable uploads report progress in headers
+		ExposeHeaders: "Location, Upload-Offset, Upload-Length, Upload-Expires",
 		AllowCredentials: true,
 	})
 }
\ No newline at end of file
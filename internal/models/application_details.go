@@ -117,6 +117,7 @@ type ApplicationGuardian struct {
 	MonthlyIncome   sql.NullFloat64 `json:"monthly_income,omitempty" db:"monthly_income"`
 	Debts           sql.NullFloat64 `json:"debts,omitempty" db:"debts"`
 	DebtDetails     sql.NullString `json:"debt_details,omitempty" db:"debt_details"`
+	NationalID      sql.NullString `json:"national_id,omitempty" db:"national_id"`
 	CreatedAt       time.Time      `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at" db:"updated_at"`
 }
@@ -101,6 +101,8 @@ type BulkUploadSession struct {
 	StartedAt      time.Time       `json:"started_at" db:"started_at"`
 	CompletedAt    *time.Time      `json:"completed_at,omitempty" db:"completed_at"`
 	ExpiresAt      time.Time       `json:"expires_at" db:"expires_at"`
+	SessionType    string          `json:"session_type" db:"session_type"`
+	DocumentTypes  []string        `json:"document_types,omitempty" db:"document_types"` // expected in a student session
 }
 
 // Enhanced Application model
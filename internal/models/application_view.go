@@ -0,0 +1,143 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Document verification filters
+const (
+	DocumentsAllVerified = "all_verified" // every uploaded document is verified
+	DocumentsPending     = "has_pending"  // at least one document awaits verification
+	DocumentsRejected    = "has_rejected" // at least one document was rejected
+	DocumentsMissing     = "none"         // nothing uploaded
+)
+
+// Bulk actions on a selection of applications
+const (
+	BulkAssignReviewer = "assign_reviewer"
+	BulkChangeStatus   = "change_status"
+	BulkNotify         = "notify"
+	BulkExport         = "export"
+)
+
+// ApplicationFilter is a filter of the officer application list. All set
+// conditions must hold; list conditions match any of their values.
+type ApplicationFilter struct {
+	Statuses       []string   `json:"statuses,omitempty"`
+	ScholarshipIDs []uint     `json:"scholarship_ids,omitempty"`
+	Faculties      []string   `json:"faculties,omitempty"` // faculty name or code
+	YearLevels     []int      `json:"year_levels,omitempty"`
+	GPAMin         *float64   `json:"gpa_min,omitempty"`
+	GPAMax         *float64   `json:"gpa_max,omitempty"`
+	IncomeMin      *float64   `json:"income_min,omitempty"` // family income
+	IncomeMax      *float64   `json:"income_max,omitempty"`
+	DocumentStatus string     `json:"document_status,omitempty"` // all_verified, has_pending, has_rejected, none
+	ReviewStages   []string   `json:"review_stages,omitempty"`   // stage of the latest review
+	ReviewerID     *uuid.UUID `json:"reviewer_id,omitempty"`     // has a review by this user
+	HasRedFlags    *bool      `json:"has_red_flags,omitempty"`   // has open or confirmed red flags
+	SubmittedFrom  *time.Time `json:"submitted_from,omitempty"`
+	SubmittedTo    *time.Time `json:"submitted_to,omitempty"`
+}
+
+// ApplicationView is a filter an officer saved for the application list
+type ApplicationView struct {
+	ViewID      uint              `json:"view_id" db:"view_id"`
+	UserID      uuid.UUID         `json:"user_id" db:"user_id"`
+	ViewName    string            `json:"view_name" db:"view_name"`
+	Description *string           `json:"description,omitempty" db:"description"`
+	Filter      ApplicationFilter `json:"filter" db:"filter"`
+	SortBy      string            `json:"sort_by" db:"sort_by"`
+	SortDesc    bool              `json:"sort_desc" db:"sort_desc"`
+	IsDefault   bool              `json:"is_default" db:"is_default"`
+	CreatedAt   time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// ApplicationViewRequest is the body for saving a view
+type ApplicationViewRequest struct {
+	ViewName    string            `json:"view_name"`
+	Description *string           `json:"description"`
+	Filter      ApplicationFilter `json:"filter"`
+	SortBy      string            `json:"sort_by"`
+	SortDesc    *bool             `json:"sort_desc"`
+	IsDefault   bool              `json:"is_default"`
+}
+
+// ApplicationQueryRequest is the body for listing applications with a filter
+// or a saved view
+type ApplicationQueryRequest struct {
+	ViewID   *uint              `json:"view_id"`
+	Filter   *ApplicationFilter `json:"filter"` // used when view_id is not set
+	SortBy   string             `json:"sort_by"`
+	SortDesc *bool              `json:"sort_desc"`
+	Page     int                `json:"page"`
+	Limit    int                `json:"limit"`
+}
+
+// ApplicationListItem is a row of the officer application list
+type ApplicationListItem struct {
+	ApplicationID     uint       `json:"application_id"`
+	StudentID         string     `json:"student_id"`
+	StudentName       string     `json:"student_name"`
+	ScholarshipID     uint       `json:"scholarship_id"`
+	ScholarshipName   string     `json:"scholarship_name"`
+	ApplicationStatus string     `json:"application_status"`
+	Faculty           string     `json:"faculty"`
+	YearLevel         *int       `json:"year_level"`
+	GPA               *float64   `json:"gpa"`
+	FamilyIncome      *float64   `json:"family_income"`
+	DocumentsTotal    int        `json:"documents_total"`
+	DocumentsVerified int        `json:"documents_verified"`
+	ReviewStage       *string    `json:"review_stage"`
+	OpenRedFlags      int        `json:"open_red_flags"`
+	SubmittedAt       *time.Time `json:"submitted_at"`
+}
+
+// BulkActionRequest is the body for running an action on many applications.
+// The selection is application_ids, or else the applications matching
+// view_id or filter when the job is queued.
+type BulkActionRequest struct {
+	Action         string             `json:"action"`
+	ApplicationIDs []uint             `json:"application_ids"`
+	ViewID         *uint              `json:"view_id"`
+	Filter         *ApplicationFilter `json:"filter"`
+
+	ReviewerID  *uuid.UUID `json:"reviewer_id"`  // assign_reviewer
+	ReviewStage string     `json:"review_stage"` // assign_reviewer, defaults to initial_screening
+	Status      string     `json:"status"`       // change_status
+	Notes       string     `json:"notes"`        // change_status
+	Title       string     `json:"title"`        // notify
+	Message     string     `json:"message"`      // notify
+}
+
+// BulkActionJob is the payload of an application_bulk job
+type BulkActionJob struct {
+	Action         string     `json:"action"`
+	ApplicationIDs []uint     `json:"application_ids"`
+	ReviewerID     *uuid.UUID `json:"reviewer_id,omitempty"`
+	ReviewStage    string     `json:"review_stage,omitempty"`
+	Status         string     `json:"status,omitempty"`
+	Notes          string     `json:"notes,omitempty"`
+	Title          string     `json:"title,omitempty"`
+	Message        string     `json:"message,omitempty"`
+}
+
+// BulkItemError is an application the action failed on
+type BulkItemError struct {
+	ApplicationID uint   `json:"application_id"`
+	Error         string `json:"error"`
+}
+
+// BulkActionResult is the result of an application_bulk job
+type BulkActionResult struct {
+	Action        string          `json:"action"`
+	Total         int             `json:"total"`
+	Succeeded     int             `json:"succeeded"`
+	Skipped       int             `json:"skipped"` // nothing to do, e.g. reviewer already assigned
+	Failed        int             `json:"failed"`
+	Errors        []BulkItemError `json:"errors"`
+	ExportFile    string          `json:"export_file,omitempty"`
+	ExportBackend string          `json:"export_backend,omitempty"`
+}
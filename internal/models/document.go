@@ -1,18 +1,33 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type ApplicationDocument struct {
 	DocumentID        int        `json:"document_id" db:"document_id"`
 	ApplicationID     int        `json:"application_id" db:"application_id"`
 	DocumentType      string     `json:"document_type" db:"document_type"`
 	DocumentName      string     `json:"document_name" db:"document_name"`
-	FilePath          string     `json:"file_path,omitempty" db:"file_path"`
+	FilePath          string     `json:"file_path,omitempty" db:"file_path"` // key within the storage backend
+	StorageBackend    string     `json:"-" db:"storage_backend"`
 	FileSize          int64      `json:"file_size" db:"file_size"`
 	MimeType          string     `json:"mime_type" db:"mime_type"`
+	FileHash          string     `json:"file_hash,omitempty" db:"file_hash"` // SHA-256 of the file
 	UploadStatus      string     `json:"upload_status" db:"upload_status"`
 	VerificationNotes *string    `json:"verification_notes,omitempty" db:"verification_notes"`
 	UploadedAt        time.Time  `json:"uploaded_at" db:"uploaded_at"`
+	IssuedDate        *time.Time `json:"issued_date,omitempty" db:"issued_date"` // checked against the valid_months of a requirement
 	VerifiedBy        *string    `json:"verified_by,omitempty" db:"verified_by"`
 	VerifiedAt        *time.Time `json:"verified_at,omitempty" db:"verified_at"`
+	VersionNumber     int        `json:"version_number" db:"version_number"` // earlier versions are in document_versions
+	UploadedBy        *uuid.UUID `json:"uploaded_by,omitempty" db:"uploaded_by"`
+	PageCount         *int       `json:"page_count,omitempty" db:"page_count"` // set with the preview; PDFs only
+	ImageWidth        *int       `json:"image_width,omitempty" db:"image_width"`
+	ImageHeight       *int       `json:"image_height,omitempty" db:"image_height"`
+	PreviewStatus     *string    `json:"preview_status,omitempty" db:"preview_status"` // nil while the preview is pending
+	PreviewPath       string     `json:"-" db:"preview_path"`
+	PreviewBackend    string     `json:"-" db:"preview_backend"`
 }
\ No newline at end of file
@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+// Formats of a document bundle
+const (
+	BundleFormatZip = "zip" // the files as uploaded, the form as PDF and a manifest
+	BundleFormatPDF = "pdf" // one PDF: a cover page, the form, then every document
+)
+
+// DocumentBundleManifest lists what the document bundle of an application
+// holds
+type DocumentBundleManifest struct {
+	ApplicationID     uint                  `json:"application_id"`
+	StudentID         string                `json:"student_id"`
+	StudentName       string                `json:"student_name"`
+	ScholarshipID     uint                  `json:"scholarship_id"`
+	ScholarshipName   string                `json:"scholarship_name"`
+	ApplicationStatus string                `json:"application_status"`
+	FormVersion       int                   `json:"form_version"` // submission snapshot; 0 is the live form of an unsubmitted application
+	FormChecksum      string                `json:"form_checksum,omitempty"`
+	FormFile          string                `json:"form_file"`
+	GeneratedAt       time.Time             `json:"generated_at"`
+	Documents         []BundleManifestEntry `json:"documents"`
+}
+
+// BundleManifestEntry is a document of a bundle. File is empty when the
+// document was left out, with the reason in Note.
+type BundleManifestEntry struct {
+	File          string    `json:"file,omitempty"`
+	DocumentID    int       `json:"document_id"`
+	DocumentType  string    `json:"document_type"`
+	DocumentName  string    `json:"document_name"`
+	VersionNumber int       `json:"version_number"`
+	MimeType      string    `json:"mime_type"`
+	FileSize      int64     `json:"file_size"`
+	SHA256        string    `json:"sha256,omitempty"`
+	Status        string    `json:"status"`
+	UploadedAt    time.Time `json:"uploaded_at"`
+	Note          string    `json:"note,omitempty"`
+}
+
+// CommitteePackRequest is the body for exporting the bundles of a
+// scholarship's shortlisted applications
+type CommitteePackRequest struct {
+	ScholarshipID uint     `json:"scholarship_id"`
+	Statuses      []string `json:"statuses"` // defaults to the shortlist: under_review and interview_scheduled
+	Format        string   `json:"format"`   // zip (default) or pdf
+}
+
+// CommitteePackJob is the payload of a committee_pack job
+type CommitteePackJob struct {
+	ScholarshipID  uint   `json:"scholarship_id"`
+	ApplicationIDs []uint `json:"application_ids"`
+	Format         string `json:"format"`
+}
+
+// CommitteePackEntry is a line of the index of a committee pack
+type CommitteePackEntry struct {
+	ApplicationID     uint   `json:"application_id"`
+	StudentID         string `json:"student_id"`
+	StudentName       string `json:"student_name"`
+	ApplicationStatus string `json:"application_status"`
+	Path              string `json:"path"` // folder or file within the pack
+	Documents         int    `json:"documents"`
+	LeftOut           int    `json:"left_out"` // documents held by the malware scan or unreadable
+	Error             string `json:"error,omitempty"`
+}
+
+// CommitteePackResult is the result of a committee_pack job
+type CommitteePackResult struct {
+	ScholarshipID uint            `json:"scholarship_id"`
+	Format        string          `json:"format"`
+	Total         int             `json:"total"`
+	Succeeded     int             `json:"succeeded"`
+	Failed        int             `json:"failed"`
+	Errors        []BulkItemError `json:"errors"`
+	ExportFile    string          `json:"export_file,omitempty"`
+	ExportBackend string          `json:"export_backend,omitempty"`
+	DownloadURL   string          `json:"download_url,omitempty"`
+}
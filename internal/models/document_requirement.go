@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// Kinds of document requirement
+const (
+	RequirementMandatory   = "mandatory"   // always needed
+	RequirementConditional = "conditional" // needed when the condition holds for the application form
+	RequirementOptional    = "optional"    // accepted but never needed
+)
+
+// DocumentRequirement is a document a scholarship asks for, stored in
+// scholarships.document_requirements. Limits left at zero are not applied.
+type DocumentRequirement struct {
+	DocumentType    string             `json:"document_type"`
+	Label           string             `json:"label,omitempty"`
+	Requirement     string             `json:"requirement"` // mandatory (default), conditional or optional
+	Condition       *DocumentCondition `json:"condition,omitempty"`
+	MaxSizeMB       int                `json:"max_size_mb,omitempty"`
+	AcceptedFormats []string           `json:"accepted_formats,omitempty"` // pdf, jpeg, png, doc, docx
+	MinCount        int                `json:"min_count,omitempty"`        // files needed; 1 when unset
+	MaxCount        int                `json:"max_count,omitempty"`        // files allowed at once
+	ValidMonths     int                `json:"valid_months,omitempty"`     // must be issued within this many months
+	MaxPages        int                `json:"max_pages,omitempty"`        // pages allowed in a PDF
+	Notes           string             `json:"notes,omitempty"`
+}
+
+// DocumentCondition says when a conditional document is needed. Field is a
+// dotted path into the application form, e.g. "health_info.has_disability"
+// or "family_members.living_status"; a path through a list matches any item.
+// The document is needed when the field has one of Values, or with no Values
+// when it is set to anything but false, zero or "".
+type DocumentCondition struct {
+	Field       string        `json:"field"`
+	Values      []interface{} `json:"values,omitempty"`
+	Description string        `json:"description,omitempty"`
+}
+
+// Statuses of a document checklist item
+const (
+	ChecklistComplete         = "complete"
+	ChecklistMissing          = "missing"
+	ChecklistScanning         = "scanning"           // files are still held by the malware scan
+	ChecklistRejected         = "rejected"           // every file was rejected by an officer
+	ChecklistIssueDateMissing = "issue_date_missing" // the validity cannot be checked
+	ChecklistExpired          = "expired"
+	ChecklistTooMany          = "too_many"
+	ChecklistProcessing       = "processing"     // the page count of a PDF is not known yet
+	ChecklistTooManyPages     = "too_many_pages" // a PDF is longer than max_pages
+	ChecklistNotRequired      = "not_required"   // optional, or a condition that does not hold
+)
+
+// DocumentChecklist is the state of an application's documents against the
+// requirements of its scholarship. Complete is false while any item blocks
+// submission.
+type DocumentChecklist struct {
+	ApplicationID uint                    `json:"application_id"`
+	ScholarshipID uint                    `json:"scholarship_id"`
+	Complete      bool                    `json:"complete"`
+	Items         []DocumentChecklistItem `json:"items"`
+	Problems      []string                `json:"problems"`
+}
+
+// DocumentChecklistItem is one requirement of a checklist with the files
+// that count towards it
+type DocumentChecklistItem struct {
+	DocumentRequirement
+	Required    bool                `json:"required"`
+	Status      string              `json:"status"`
+	Count       int                 `json:"count"`
+	Documents   []ChecklistDocument `json:"documents"`
+	Problem     string              `json:"problem,omitempty"`
+	ValidBefore *time.Time          `json:"valid_before,omitempty"` // files issued before this have expired
+}
+
+// ChecklistDocument is an uploaded file as the checklist sees it
+type ChecklistDocument struct {
+	DocumentID   int        `json:"document_id"`
+	DocumentName string     `json:"document_name"`
+	UploadStatus string     `json:"upload_status"`
+	IssuedDate   *time.Time `json:"issued_date,omitempty"`
+	Counted      bool       `json:"counted"`
+	Problem      string     `json:"problem,omitempty"`
+}
+
+// SetIssuedDateRequest is the body for recording when a document was issued
+type SetIssuedDateRequest struct {
+	IssuedDate string `json:"issued_date"` // YYYY-MM-DD
+}
@@ -0,0 +1,63 @@
+package models
+
+// EligibilityRules is the structured eligibility definition stored in
+// scholarships.eligibility_rules. Every field is optional; an unset field
+// means the rule is not applied.
+type EligibilityRules struct {
+	MinGPA                 *float64 `json:"min_gpa,omitempty"`
+	MinYearLevel           *int     `json:"min_year_level,omitempty"`
+	MaxYearLevel           *int     `json:"max_year_level,omitempty"`
+	AllowedFaculties       []string `json:"allowed_faculties,omitempty"`
+	ExcludedFaculties      []string `json:"excluded_faculties,omitempty"`
+	MaxFamilyIncome        *float64 `json:"max_family_income,omitempty"` // monthly, baht
+	AllowedStudentStatuses []string `json:"allowed_student_statuses,omitempty"`
+
+	// Prior-award exclusions
+	ExcludePriorRecipients bool     `json:"exclude_prior_recipients,omitempty"` // never awarded this scholarship before
+	ExcludedAwardTypes     []string `json:"excluded_award_types,omitempty"`     // holds an active award of these types
+	MaxActiveAwards        *int     `json:"max_active_awards,omitempty"`        // concurrent awards allowed
+}
+
+// PriorAward is an allocation the student has already received
+type PriorAward struct {
+	AllocationID    uint    `json:"allocation_id"`
+	ApplicationID   uint    `json:"application_id"`
+	ScholarshipID   uint    `json:"scholarship_id"`
+	ScholarshipName string  `json:"scholarship_name"`
+	ScholarshipType string  `json:"scholarship_type"`
+	AcademicYear    string  `json:"academic_year"`
+	Amount          float64 `json:"amount"`
+	Status          string  `json:"status"`
+	Active          bool    `json:"active"`
+}
+
+// EligibilityProfile is the student data the rules are evaluated against
+type EligibilityProfile struct {
+	StudentID     string       `json:"student_id"`
+	GPA           *float64     `json:"gpa"`
+	YearLevel     *int         `json:"year_level"`
+	FacultyCode   *string      `json:"faculty_code"`
+	StudentStatus *string      `json:"student_status"`
+	FamilyIncome  *float64     `json:"family_income"`
+	PriorAwards   []PriorAward `json:"prior_awards"`
+}
+
+// EligibilityRuleResult explains the outcome of a single rule
+type EligibilityRuleResult struct {
+	Rule     string      `json:"rule"`
+	Passed   bool        `json:"passed"`
+	Missing  bool        `json:"missing"`
+	Required interface{} `json:"required"`
+	Actual   interface{} `json:"actual"`
+	Reason   string      `json:"reason"`
+}
+
+// EligibilityResult is the overall outcome of evaluating a scholarship's rules
+type EligibilityResult struct {
+	ScholarshipID       uint                    `json:"scholarship_id"`
+	IsEligible          bool                    `json:"is_eligible"`
+	EligibilityScore    float64                 `json:"eligibility_score"`
+	Results             []EligibilityRuleResult `json:"criteria_results"`
+	FailedReasons       []string                `json:"failed_reasons"`
+	MissingRequirements []string                `json:"missing_requirements"`
+}
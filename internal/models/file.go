@@ -26,29 +26,110 @@ type FileStorage struct {
 	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
 }
 
-// FileVersion represents a document version in file storage
+// FileVersion represents a document version in file storage. Versions of
+// application documents also keep the file and the verification the version
+// had when it was replaced.
 type FileVersion struct {
 	VersionID         uuid.UUID  `json:"version_id" db:"version_id"`
-	FileID            uuid.UUID  `json:"file_id" db:"file_id"`
+	FileID            *uuid.UUID `json:"file_id,omitempty" db:"file_id"`
 	VersionNumber     int        `json:"version_number" db:"version_number"`
 	ChangeDescription *string    `json:"change_description,omitempty" db:"change_description"`
-	UploadedBy        uuid.UUID  `json:"uploaded_by" db:"uploaded_by"`
+	UploadedBy        *uuid.UUID `json:"uploaded_by,omitempty" db:"uploaded_by"`
 	FileSize          int64      `json:"file_size" db:"file_size"`
 	IsCurrent         bool       `json:"is_current" db:"is_current"`
 	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
 	ReplacedAt        *time.Time `json:"replaced_at,omitempty" db:"replaced_at"`
+
+	DocumentID        *int       `json:"document_id,omitempty" db:"document_id"`
+	DocumentName      string     `json:"document_name,omitempty" db:"document_name"`
+	FilePath          string     `json:"-" db:"file_path"`
+	StorageBackend    string     `json:"-" db:"storage_backend"`
+	MimeType          string     `json:"mime_type,omitempty" db:"mime_type"`
+	FileHash          string     `json:"file_hash,omitempty" db:"file_hash"`
+	UploadStatus      string     `json:"upload_status,omitempty" db:"upload_status"`
+	VerificationNotes *string    `json:"verification_notes,omitempty" db:"verification_notes"`
+	VerifiedBy        *uuid.UUID `json:"verified_by,omitempty" db:"verified_by"`
+	VerifiedAt        *time.Time `json:"verified_at,omitempty" db:"verified_at"`
+}
+
+// DocumentVersionDiff compares two versions of an application document
+type DocumentVersionDiff struct {
+	DocumentID int           `json:"document_id"`
+	From       FileVersion   `json:"from"`
+	To         FileVersion   `json:"to"`
+	SameFile   bool          `json:"same_file"` // identical contents
+	Changes    []FieldChange `json:"changes"`
 }
 
-// FileAccessLog represents a file access log entry
+// File access actions
+const (
+	FileAccessView       = "view"        // opened inline, e.g. in an iframe
+	FileAccessDownload   = "download"    // saved as an attachment
+	FileAccessLinkIssued = "link_issued" // a signed link was handed out
+	FileAccessPreview    = "preview"     // the generated preview image was shown
+)
+
+// How a file was reached
+const (
+	AccessMethodBearer     = "bearer"      // API call with a JWT
+	AccessMethodSignedLink = "signed_link" // expiring HMAC-signed URL
+)
+
+// FileAccessLog represents a file access log entry. Accesses of application
+// documents carry the document instead of a file_storage entry.
 type FileAccessLog struct {
-	AccessID   uuid.UUID `json:"access_id" db:"access_id"`
-	FileID     uuid.UUID `json:"file_id" db:"file_id"`
-	UserID     uuid.UUID `json:"user_id" db:"user_id"`
-	AccessTime time.Time `json:"access_time" db:"access_time"`
-	Action     string    `json:"action" db:"action"`
-	IPAddress  *string   `json:"ip_address,omitempty" db:"ip_address"`
-	UserAgent  *string   `json:"user_agent,omitempty" db:"user_agent"`
-	Success    bool      `json:"success" db:"success"`
+	AccessID   uuid.UUID  `json:"access_id" db:"access_id"`
+	FileID     *uuid.UUID `json:"file_id,omitempty" db:"file_id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	AccessTime time.Time  `json:"access_time" db:"access_time"`
+	Action     string     `json:"action" db:"action"`
+	IPAddress  *string    `json:"ip_address,omitempty" db:"ip_address"`
+	UserAgent  *string    `json:"user_agent,omitempty" db:"user_agent"`
+	Success    bool       `json:"success" db:"success"`
+
+	DocumentID    *int   `json:"document_id,omitempty" db:"document_id"`
+	ApplicationID *int   `json:"application_id,omitempty" db:"application_id"`
+	DocumentType  string `json:"document_type,omitempty" db:"document_type"`
+	VersionNumber *int   `json:"version_number,omitempty" db:"version_number"`
+	AccessMethod  string `json:"access_method,omitempty" db:"access_method"`
+}
+
+// SensitiveDocumentTypes hold personal or financial details whose access
+// students and auditors may want to review
+var SensitiveDocumentTypes = []string{
+	"id_card",
+	"house_registration",
+	"income_certificate",
+	"bank_account",
+	"medical_certificate",
+}
+
+// IsSensitiveDocument reports whether a document type is sensitive
+func IsSensitiveDocument(documentType string) bool {
+	for _, t := range SensitiveDocumentTypes {
+		if t == documentType {
+			return true
+		}
+	}
+	return false
+}
+
+// DocumentAccessEntry is an access log entry with the user who made it
+type DocumentAccessEntry struct {
+	FileAccessLog
+	UserName  string   `json:"user_name"`
+	UserEmail string   `json:"user_email"`
+	UserRoles []string `json:"user_roles"`
+	Sensitive bool     `json:"sensitive"`
+}
+
+// DocumentAccessFilter narrows the access log of an application
+type DocumentAccessFilter struct {
+	DocumentID    int
+	DocumentType  string
+	SensitiveOnly bool
+	Page          int
+	Limit         int
 }
 
 // Value implements the driver.Valuer interface
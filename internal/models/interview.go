@@ -43,4 +43,21 @@ type InterviewResult struct {
 	Recommendation *string   `json:"recommendation" db:"recommendation"`
 	InterviewNotes *string   `json:"interview_notes" db:"interview_notes"`
 	CreatedAt      time.Time `json:"created_at" db:"created_at"`
-}
\ No newline at end of file
+}
+
+// InterviewAppointmentView is a booking as listed by the /interviews
+// compatibility routes, keyed by the old appointment and schedule names
+type InterviewAppointmentView struct {
+	AppointmentID    int        `json:"appointment_id"`
+	ApplicationID    int        `json:"application_id"`
+	ScheduleID       int        `json:"schedule_id"`
+	Status           string     `json:"status"`
+	Confirmed        bool       `json:"confirmed"`
+	ConfirmationDate *time.Time `json:"confirmation_date,omitempty"`
+	InterviewDate    time.Time  `json:"interview_date"`
+	StartTime        string     `json:"start_time"`
+	EndTime          string     `json:"end_time"`
+	Location         string     `json:"location"`
+	ScholarshipName  string     `json:"scholarship_name"`
+	StudentName      string     `json:"student_name,omitempty"`
+}
@@ -434,6 +434,31 @@ type InterviewStatistics struct {
 	UpcomingWeek   int            `json:"upcoming_week"`
 }
 
+// InterviewStatsFilter narrows interview booking statistics to slots of a
+// scholarship and a date range. Zero values leave the filter off.
+type InterviewStatsFilter struct {
+	ScholarshipID int
+	DateFrom      string // YYYY-MM-DD
+	DateTo        string // YYYY-MM-DD
+}
+
+// InterviewBookingStats counts slots and bookings. Every interview figure in
+// the API and reports comes from here.
+type InterviewBookingStats struct {
+	TotalSlots        int     `json:"total_slots"`
+	AvailableSlots    int     `json:"available_slots"`
+	TotalBookings     int     `json:"total_bookings"`
+	BookedBookings    int     `json:"booked_bookings"`
+	ConfirmedBookings int     `json:"confirmed_bookings"`
+	PendingBookings   int     `json:"pending_bookings"` // booked or confirmed, not yet held
+	CancelledBookings int     `json:"cancelled_bookings"`
+	CompletedBookings int     `json:"completed_bookings"`
+	NoShowBookings    int     `json:"no_show_bookings"`
+	CheckedIn         int     `json:"checked_in"`
+	AvgDuration       float64 `json:"avg_duration"`
+	UtilizationRate   float64 `json:"utilization_rate"`
+}
+
 type ReviewWorkflowStatistics struct {
 	TotalApplications  int            `json:"total_applications"`
 	InProgress         int            `json:"in_progress"`
@@ -8,6 +8,20 @@ import (
 	"github.com/google/uuid"
 )
 
+// Job types handled by the background worker
+const (
+	JobTypeFraudScan        = "fraud_scan"
+	JobTypeSearchIndex      = "search_index"
+	JobTypeApplicationBulk  = "application_bulk"
+	JobTypeDocumentScan     = "document_scan"
+	JobTypeUploadCleanup    = "upload_cleanup"
+	JobTypeCommitteePack    = "committee_pack"
+	JobTypeRetentionPurge   = "retention_purge"
+	JobTypeRetentionReport  = "retention_report" // dry run of retention_purge
+	JobTypeDocumentPreview  = "document_preview"
+	JobTypeStorageReconcile = "storage_reconcile"
+)
+
 // JobQueue represents a job in the queue
 type JobQueue struct {
 	JobID        uuid.UUID       `json:"job_id" db:"job_id"`
@@ -21,6 +35,13 @@ type JobQueue struct {
 	StartedAt    *time.Time      `json:"started_at,omitempty" db:"started_at"`
 	CompletedAt  *time.Time      `json:"completed_at,omitempty" db:"completed_at"`
 	ErrorMessage *string         `json:"error_message,omitempty" db:"error_message"`
+	Result       json.RawMessage `json:"result,omitempty" db:"result"`
+	CreatedBy    *uuid.UUID      `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt    *time.Time      `json:"created_at,omitempty" db:"created_at"`
+
+	// Progress of jobs that work through a list of items
+	ProgressDone  int `json:"progress_done" db:"progress_done"`
+	ProgressTotal int `json:"progress_total" db:"progress_total"`
 }
 
 // BackgroundTask represents a background task
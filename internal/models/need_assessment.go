@@ -0,0 +1,121 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NeedParameters are the tunable inputs of the financial need formula. They
+// are stored as JSON in need_assessment_parameters so officers can adjust the
+// formula without a release. All amounts are in baht.
+type NeedParameters struct {
+	// Household
+	ExcludedLivingStatuses []string `json:"excluded_living_statuses"` // family members with these statuses are not counted
+	CountGuardians         bool     `json:"count_guardians"`          // guardians count towards household size
+	CountSiblings          bool     `json:"count_siblings"`           // siblings count towards household size
+	WorkingSiblingShare    float64  `json:"working_sibling_share"`    // share of a working sibling's income counted as household income
+	IncludeStudentIncome   bool     `json:"include_student_income"`   // the applicant's own income counts as household income
+
+	// Income certificates: "declared" uses the incomes typed into the form,
+	// "certified" uses the certificates when there are any, "max" the higher
+	IncomeBasis string `json:"income_basis"`
+
+	// Debt: total debt is spread over this many months and deducted from income
+	DebtRepaymentMonths int `json:"debt_repayment_months"`
+
+	// Assets: value above the exemption is treated as income at the given
+	// monthly rate (0.005 = 0.5% of the value per month)
+	ExemptAssetTypes     []string `json:"exempt_asset_types"`
+	AssetExemption       float64  `json:"asset_exemption"`
+	AssetMonthlyRate     float64  `json:"asset_monthly_rate"`
+	MaxAssetAdjustment   float64  `json:"max_asset_adjustment"` // monthly cap, 0 = no cap
+	StudyDaysPerMonth    int      `json:"study_days_per_month"` // for daily travel costs
+	AcademicMonths       int      `json:"academic_months"`      // months of costs per academic year
+	SubsistencePerCapita float64  `json:"subsistence_per_capita"`
+	ContributionRate     float64  `json:"contribution_rate"` // share of per-capita income above subsistence expected to go to the student
+
+	// Need score: per-capita income at or below FullNeedPerCapita scores 100,
+	// at or above NoNeedPerCapita scores MinNeedScore, linear in between
+	FullNeedPerCapita float64 `json:"full_need_per_capita"`
+	NoNeedPerCapita   float64 `json:"no_need_per_capita"`
+	MinNeedScore      float64 `json:"min_need_score"`
+}
+
+// Income bases for NeedParameters.IncomeBasis
+const (
+	IncomeBasisDeclared  = "declared"
+	IncomeBasisCertified = "certified"
+	IncomeBasisMax       = "max"
+)
+
+// DefaultNeedParameters are used until officers save their own parameters
+func DefaultNeedParameters() NeedParameters {
+	return NeedParameters{
+		ExcludedLivingStatuses: []string{"deceased", "dead"},
+		CountGuardians:         true,
+		CountSiblings:          true,
+		WorkingSiblingShare:    0.5,
+		IncludeStudentIncome:   true,
+		IncomeBasis:            IncomeBasisMax,
+		DebtRepaymentMonths:    60,
+		ExemptAssetTypes:       []string{"own_house"},
+		AssetExemption:         500000,
+		AssetMonthlyRate:       0.002,
+		MaxAssetAdjustment:     20000,
+		StudyDaysPerMonth:      22,
+		AcademicMonths:         10,
+		SubsistencePerCapita:   3000,
+		ContributionRate:       0.5,
+		FullNeedPerCapita:      3000,
+		NoNeedPerCapita:        15000,
+		MinNeedScore:           20,
+	}
+}
+
+// NeedParameterSet is a saved version of the need formula parameters
+type NeedParameterSet struct {
+	ParameterSetID int            `json:"parameter_set_id"` // 0 = built-in defaults
+	Parameters     NeedParameters `json:"parameters"`
+	IsActive       bool           `json:"is_active"`
+	CreatedBy      *uuid.UUID     `json:"created_by,omitempty"`
+	CreatedAt      *time.Time     `json:"created_at,omitempty"`
+}
+
+// NeedIncomeLine is one monthly income counted in a need assessment
+type NeedIncomeLine struct {
+	Source string  `json:"source"` // family_member, guardian, sibling, student, certificate
+	Label  string  `json:"label"`
+	Amount float64 `json:"amount"`
+}
+
+// NeedAssessment is the breakdown of the financial need of an application.
+// Amounts are monthly unless the name says otherwise.
+type NeedAssessment struct {
+	ApplicationID  uint `json:"application_id"`
+	ParameterSetID int  `json:"parameter_set_id"`
+
+	HouseholdSize   int              `json:"household_size"`
+	IncomeLines     []NeedIncomeLine `json:"income_lines"`
+	DeclaredIncome  float64          `json:"declared_income"`
+	CertifiedIncome float64          `json:"certified_income"`
+	HouseholdIncome float64          `json:"household_income"`
+
+	TotalDebt       float64 `json:"total_debt"`
+	DebtBurden      float64 `json:"debt_burden"`
+	AssetValue      float64 `json:"asset_value"`
+	AssetAdjustment float64 `json:"asset_adjustment"`
+
+	AdjustedIncome  float64 `json:"adjusted_income"`
+	PerCapitaIncome float64 `json:"per_capita_income"`
+
+	StudentMonthlyCosts      float64 `json:"student_monthly_costs"`
+	AnnualCosts              float64 `json:"annual_costs"`
+	AnnualFamilyContribution float64 `json:"annual_family_contribution"`
+	RequestedAmount          float64 `json:"requested_amount"`
+	UnmetNeed                float64 `json:"unmet_need"` // per academic year
+
+	NeedScore  float64   `json:"need_score"`
+	Notes      []string  `json:"notes"`
+	AssessedAt time.Time `json:"assessed_at"`
+}
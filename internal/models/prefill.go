@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StaleField is a prefilled value the student should check before
+// submitting. It is resolved once its section is saved again.
+type StaleField struct {
+	Section    string     `json:"section"`
+	Field      string     `json:"field"` // e.g. "guardians[0].monthly_income"
+	Reason     string     `json:"reason"`
+	RecordedAt *time.Time `json:"recorded_at,omitempty"` // when the value was last entered
+	Resolved   bool       `json:"resolved"`
+}
+
+// ApplicationPrefill records which previous application a draft was started
+// from and what needs checking
+type ApplicationPrefill struct {
+	ApplicationID       uint           `json:"application_id" db:"application_id"`
+	SourceApplicationID *uint          `json:"source_application_id" db:"source_application_id"`
+	CopiedSections      []string       `json:"copied_sections" db:"copied_sections"`
+	StaleFields         []StaleField   `json:"stale_fields" db:"stale_fields"`
+	SectionVersions     map[string]int `json:"section_versions" db:"section_versions"` // section versions right after the copy
+	AwardsAdded         int            `json:"awards_added" db:"awards_added"`
+	CreatedBy           *uuid.UUID     `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt           time.Time      `json:"created_at" db:"created_at"`
+}
+
+// PrefillDraftRequest is the body for starting a draft from a previous application
+type PrefillDraftRequest struct {
+	ScholarshipID       uint  `json:"scholarship_id"`
+	SourceApplicationID *uint `json:"source_application_id"` // defaults to the latest submitted application
+}
+
+// PrefillSource is a previous application a draft is copied from
+type PrefillSource struct {
+	Form         *CompleteApplicationForm
+	AcademicYear string    // academic year of the source scholarship
+	RecordedAt   time.Time // when the source was submitted or last saved
+}
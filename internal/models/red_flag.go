@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Signal types raised by the duplicate and fraud detection job
+const (
+	SignalSharedBankAccount       = "shared_bank_account"       // same account number on different students
+	SignalSharedGuardianID        = "shared_guardian_id"        // same guardian national ID on different students' applications
+	SignalSharedHouseRegistration = "shared_house_registration" // identical house registration document file
+	SignalDuplicateFile           = "duplicate_file"            // any other identical uploaded file
+	SignalIncomeAssetMismatch     = "income_asset_mismatch"     // declared assets far above what the income explains
+)
+
+// Red flag statuses
+const (
+	RedFlagOpen      = "open"
+	RedFlagConfirmed = "confirmed"
+	RedFlagDismissed = "dismissed"
+	RedFlagResolved  = "resolved" // the signal was not found again by a later run
+)
+
+// RedFlagReasonPrefix marks the red_flag_reason of reviews flagged by the
+// detection job, so clearing the flag leaves reviewers' own flags alone
+const RedFlagReasonPrefix = "[Automated check] "
+
+// RedFlag is a signal found by the detection job on one application
+type RedFlag struct {
+	FlagID                uint                   `json:"flag_id" db:"flag_id"`
+	ApplicationID         uint                   `json:"application_id" db:"application_id"`
+	SignalType            string                 `json:"signal_type" db:"signal_type"`
+	Severity              string                 `json:"severity" db:"severity"`
+	MatchKey              string                 `json:"match_key" db:"match_key"`
+	RelatedApplicationIDs []uint                 `json:"related_application_ids" db:"related_application_ids"`
+	Reason                string                 `json:"reason" db:"reason"`
+	Details               map[string]interface{} `json:"details,omitempty" db:"details"`
+	Status                string                 `json:"status" db:"status"`
+	TriagedBy             *uuid.UUID             `json:"triaged_by,omitempty" db:"triaged_by"`
+	TriagedAt             *time.Time             `json:"triaged_at,omitempty" db:"triaged_at"`
+	TriageNote            *string                `json:"triage_note,omitempty" db:"triage_note"`
+	DetectedAt            time.Time              `json:"detected_at" db:"detected_at"`
+	LastSeenAt            time.Time              `json:"last_seen_at" db:"last_seen_at"`
+
+	// Filled in listings
+	StudentID       string `json:"student_id,omitempty"`
+	ScholarshipName string `json:"scholarship_name,omitempty"`
+}
+
+// RedFlagTriageRequest is the body for triaging a red flag
+type RedFlagTriageRequest struct {
+	Status string `json:"status"` // open, confirmed or dismissed
+	Note   string `json:"note"`
+}
+
+// SignalRecord is one value that may be shared between applications, such as
+// a normalised bank account number or a file hash
+type SignalRecord struct {
+	ApplicationID uint
+	StudentID     string
+	Key           string
+	Label         string // masked value shown to officers
+}
+
+// IncomeAssetRecord is the declared income and assets of an application
+type IncomeAssetRecord struct {
+	ApplicationID   uint
+	StudentID       string
+	MonthlyIncome   float64 // household income from the need assessment
+	TotalAssetValue float64 // every declared asset, exempt ones included
+}
+
+// FraudScanInput is everything the detection job compares
+type FraudScanInput struct {
+	BankAccounts       []SignalRecord
+	GuardianIDs        []SignalRecord
+	HouseRegistrations []SignalRecord
+	Files              []SignalRecord
+	IncomeAssets       []IncomeAssetRecord
+}
+
+// FraudScanParams are the thresholds of the income/asset check: assets worth
+// more than AssetIncomeYears of household income, and at least MinAssetValue,
+// are flagged
+type FraudScanParams struct {
+	AssetIncomeYears float64
+	MinAssetValue    float64
+}
+
+// FraudScanResult summarises a detection run
+type FraudScanResult struct {
+	Findings int            `json:"findings"`
+	New      int            `json:"new"`
+	Resolved int            `json:"resolved"`
+	BySignal map[string]int `json:"by_signal"`
+	RanAt    time.Time      `json:"ran_at"`
+}
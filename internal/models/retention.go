@@ -0,0 +1,139 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// What a retention rule applies to
+const (
+	RetentionDocuments          = "documents"           // uploaded documents and their earlier versions
+	RetentionApplicationDetails = "application_details" // the personal data of the application form
+)
+
+// Outcomes of an application that retention rules are written for
+const (
+	OutcomeAwarded      = "awarded"       // approved, completed
+	OutcomeRejected     = "rejected"      // rejected
+	OutcomeWithdrawn    = "withdrawn"     // withdrawn, declined
+	OutcomeNotSubmitted = "not_submitted" // draft
+)
+
+// RetentionOutcomes lists the outcomes in the order they are reported
+var RetentionOutcomes = []string{OutcomeAwarded, OutcomeRejected, OutcomeWithdrawn, OutcomeNotSubmitted}
+
+// What happens to records once their retention period is over
+const (
+	RetentionPurge     = "purge"     // files and rows are deleted
+	RetentionAnonymize = "anonymize" // files are deleted, rows are kept without personal data
+)
+
+// RetentionRule is a row of retention_rules. Records are kept for
+// RetentionMonths after the announcement date of the application's round.
+type RetentionRule struct {
+	RuleID          int        `json:"rule_id" db:"rule_id"`
+	RecordType      string     `json:"record_type" db:"record_type"`
+	DocumentType    *string    `json:"document_type,omitempty" db:"document_type"` // nil applies to every document type
+	Outcome         string     `json:"outcome" db:"outcome"`
+	RetentionMonths int        `json:"retention_months" db:"retention_months"`
+	Action          string     `json:"action" db:"action"`
+	IsActive        bool       `json:"is_active" db:"is_active"`
+	Notes           *string    `json:"notes,omitempty" db:"notes"`
+	CreatedBy       *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt       *time.Time `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// RetentionRuleRequest is the body for creating or updating a retention rule
+type RetentionRuleRequest struct {
+	RecordType      string  `json:"record_type"`
+	DocumentType    *string `json:"document_type"`
+	Outcome         string  `json:"outcome"`
+	RetentionMonths int     `json:"retention_months"`
+	Action          string  `json:"action"`
+	IsActive        *bool   `json:"is_active"`
+	Notes           *string `json:"notes"`
+}
+
+// RetentionCandidate is an application with an outcome whose records may be
+// due for purging
+type RetentionCandidate struct {
+	ApplicationID    int        `json:"application_id"`
+	ScholarshipID    int        `json:"scholarship_id"`
+	Status           string     `json:"status"`
+	AnnouncementDate *time.Time `json:"announcement_date,omitempty"`
+	LegalHold        bool       `json:"legal_hold"`
+	DetailsRetention *string    `json:"details_retention,omitempty"` // action already applied to the form details
+}
+
+// RetentionDocument is a document of a candidate with every stored file
+// that belongs to it
+type RetentionDocument struct {
+	DocumentID   int            `json:"document_id"`
+	DocumentType string         `json:"document_type"`
+	UploadStatus string         `json:"upload_status"`
+	Files        []StoredObject `json:"-"` // the current file and those of earlier versions
+}
+
+// RetentionAction is a purge or anonymization that is due
+type RetentionAction struct {
+	ApplicationID    int                `json:"application_id"`
+	ScholarshipID    int                `json:"scholarship_id"`
+	Outcome          string             `json:"outcome"`
+	RecordType       string             `json:"record_type"`
+	DocumentID       *int               `json:"document_id,omitempty"`
+	DocumentType     string             `json:"document_type,omitempty"`
+	Action           string             `json:"action"`
+	RuleID           int                `json:"rule_id"`
+	AnnouncementDate time.Time          `json:"announcement_date"`
+	DueAt            time.Time          `json:"due_at"`
+	Files            int                `json:"files"`
+	Done             bool               `json:"done"`
+	Error            string             `json:"error,omitempty"`
+	Document         *RetentionDocument `json:"-"`
+}
+
+// RetentionRunResult summarises a run of the retention job. A dry run only
+// reports what would be purged.
+type RetentionRunResult struct {
+	DryRun         bool              `json:"dry_run"`
+	Applications   int               `json:"applications"`    // applications with an outcome that were checked
+	NoAnnouncement int               `json:"no_announcement"` // skipped: the round has no announcement date
+	Held           int               `json:"held"`            // skipped: on legal hold
+	Documents      int               `json:"documents"`
+	Details        int               `json:"details"`
+	FilesDeleted   int               `json:"files_deleted"`
+	Failed         int               `json:"failed"`
+	Items          []RetentionAction `json:"items,omitempty"` // the first actions, for review
+	Errors         []string          `json:"errors,omitempty"`
+}
+
+// RetentionRunRequest is the body for starting a retention run
+type RetentionRunRequest struct {
+	DryRun bool `json:"dry_run"`
+}
+
+// RetentionLogEntry is a row of retention_log, kept after the records it
+// describes are gone
+type RetentionLogEntry struct {
+	LogID            int64      `json:"log_id" db:"log_id"`
+	ApplicationID    int        `json:"application_id" db:"application_id"`
+	ScholarshipID    *int       `json:"scholarship_id,omitempty" db:"scholarship_id"`
+	RecordType       string     `json:"record_type" db:"record_type"`
+	DocumentID       *int       `json:"document_id,omitempty" db:"document_id"`
+	DocumentType     *string    `json:"document_type,omitempty" db:"document_type"`
+	FilesDeleted     int        `json:"files_deleted" db:"files_deleted"`
+	Action           string     `json:"action" db:"action"`
+	Outcome          string     `json:"outcome" db:"outcome"`
+	RuleID           *int       `json:"rule_id,omitempty" db:"rule_id"`
+	AnnouncementDate *time.Time `json:"announcement_date,omitempty" db:"announcement_date"`
+	JobID            *uuid.UUID `json:"job_id,omitempty" db:"job_id"`
+	PerformedAt      time.Time  `json:"performed_at" db:"performed_at"`
+}
+
+// LegalHoldRequest is the body for placing or lifting a legal hold
+type LegalHoldRequest struct {
+	LegalHold bool    `json:"legal_hold"`
+	Reason    *string `json:"reason"`
+}
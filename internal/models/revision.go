@@ -0,0 +1,134 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Application form sections, as used by /applications/:id/sections/:section_name
+const (
+	SectionPersonalInfo     = "personal_info"
+	SectionAddressInfo      = "address_info"
+	SectionEducationHistory = "education_history"
+	SectionFamilyInfo       = "family_info"
+	SectionFinancialInfo    = "financial_info"
+	SectionActivitiesSkills = "activities_skills"
+)
+
+// ApplicationSections lists every section that can be flagged for revision
+var ApplicationSections = []string{
+	SectionPersonalInfo,
+	SectionAddressInfo,
+	SectionEducationHistory,
+	SectionFamilyInfo,
+	SectionFinancialInfo,
+	SectionActivitiesSkills,
+}
+
+// Revision request statuses
+const (
+	RevisionStatusOpen        = "open"
+	RevisionStatusResubmitted = "resubmitted"
+	RevisionStatusExpired     = "expired"
+	RevisionStatusCancelled   = "cancelled"
+)
+
+// ApplicationRevisionRequest is an officer's request to send an application
+// back to the student
+type ApplicationRevisionRequest struct {
+	RevisionID           uint            `json:"revision_id" db:"revision_id"`
+	ApplicationID        uint            `json:"application_id" db:"application_id"`
+	RequestedBy          uuid.UUID       `json:"requested_by" db:"requested_by"`
+	Comments             *string         `json:"comments" db:"comments"`
+	ResubmissionDeadline time.Time       `json:"resubmission_deadline" db:"resubmission_deadline"`
+	Status               string          `json:"status" db:"status"`
+	PreviousStatus       *string         `json:"previous_status" db:"previous_status"`
+	FormSnapshot         json.RawMessage `json:"-" db:"form_snapshot"`
+	DocumentSnapshot     json.RawMessage `json:"-" db:"document_snapshot"`
+	Changes              *RevisionDiff   `json:"changes,omitempty" db:"changes"`
+	ResubmittedAt        *time.Time      `json:"resubmitted_at" db:"resubmitted_at"`
+	CreatedAt            time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time       `json:"updated_at" db:"updated_at"`
+
+	// Loaded relationships
+	Items []ApplicationRevisionItem `json:"items"`
+}
+
+// IsOverdue reports whether the resubmission deadline has passed
+func (r *ApplicationRevisionRequest) IsOverdue() bool {
+	return time.Now().After(r.ResubmissionDeadline)
+}
+
+// AllowsSection reports whether the request flags the given section
+func (r *ApplicationRevisionRequest) AllowsSection(section string) bool {
+	for _, item := range r.Items {
+		if item.ItemType == "section" && item.SectionName != nil && *item.SectionName == section {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsDocument reports whether the request flags the given document type
+func (r *ApplicationRevisionRequest) AllowsDocument(documentType string) bool {
+	for _, item := range r.Items {
+		if item.ItemType == "document" && item.DocumentType != nil && *item.DocumentType == documentType {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplicationRevisionItem is a single flagged section or document
+type ApplicationRevisionItem struct {
+	ItemID       uint      `json:"item_id" db:"item_id"`
+	RevisionID   uint      `json:"revision_id" db:"revision_id"`
+	ItemType     string    `json:"item_type" db:"item_type"` // section, document
+	SectionName  *string   `json:"section_name,omitempty" db:"section_name"`
+	DocumentType *string   `json:"document_type,omitempty" db:"document_type"`
+	DocumentID   *int      `json:"document_id,omitempty" db:"document_id"`
+	Comment      string    `json:"comment" db:"comment"`
+	IsResolved   bool      `json:"is_resolved" db:"is_resolved"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// RevisionDocumentRef is the document metadata kept in a revision snapshot
+type RevisionDocumentRef struct {
+	DocumentID   int       `json:"document_id"`
+	DocumentType string    `json:"document_type"`
+	DocumentName string    `json:"document_name"`
+	UploadedAt   time.Time `json:"uploaded_at"`
+}
+
+// FieldChange is one changed value between two versions of an application form
+type FieldChange struct {
+	Section  string      `json:"section"`
+	Path     string      `json:"path"`
+	OldValue interface{} `json:"old_value"`
+	NewValue interface{} `json:"new_value"`
+}
+
+// RevisionDiff describes what the student changed when resubmitting
+type RevisionDiff struct {
+	Fields           []FieldChange         `json:"fields"`
+	DocumentsAdded   []RevisionDocumentRef `json:"documents_added"`
+	DocumentsRemoved []RevisionDocumentRef `json:"documents_removed"`
+}
+
+// RevisionItemRequest is a section or document to flag when sending back
+type RevisionItemRequest struct {
+	ItemType     string `json:"item_type" validate:"required,oneof=section document"`
+	SectionName  string `json:"section_name"`
+	DocumentType string `json:"document_type"`
+	DocumentID   *int   `json:"document_id"`
+	Comment      string `json:"comment" validate:"required"`
+}
+
+// RequestRevisionRequest is the body of POST /admin/applications/:id/request-revision
+type RequestRevisionRequest struct {
+	Comments             string                `json:"comments"`
+	ResubmissionDeadline time.Time             `json:"resubmission_deadline" validate:"required"`
+	Items                []RevisionItemRequest `json:"items" validate:"required,min=1"`
+}
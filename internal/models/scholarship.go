@@ -20,24 +20,26 @@ type ScholarshipSource struct {
 }
 
 type Scholarship struct {
-	ScholarshipID        uint      `json:"scholarship_id" db:"scholarship_id"`
-	SourceID             uint      `json:"source_id" db:"source_id"`
-	ScholarshipName      string    `json:"scholarship_name" db:"scholarship_name"`
-	ScholarshipType      string    `json:"scholarship_type" db:"scholarship_type"`
-	Amount               float64   `json:"amount" db:"amount"`
-	TotalQuota           int       `json:"total_quota" db:"total_quota"`
-	AvailableQuota       int       `json:"available_quota" db:"available_quota"`
-	AcademicYear         string    `json:"academic_year" db:"academic_year"`
-	Semester             *string   `json:"semester" db:"semester"`
-	EligibilityCriteria  *string   `json:"eligibility_criteria" db:"eligibility_criteria"`
-	RequiredDocuments    *string   `json:"required_documents" db:"required_documents"`
-	ApplicationStartDate time.Time `json:"application_start_date" db:"application_start_date"`
-	ApplicationEndDate   time.Time `json:"application_end_date" db:"application_end_date"`
-	InterviewRequired    bool      `json:"interview_required" db:"interview_required"`
-	IsActive             bool      `json:"is_active" db:"is_active"`
-	CreatedBy            uuid.UUID `json:"created_by" db:"created_by"`
-	CreatedAt            time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
+	ScholarshipID        uint                  `json:"scholarship_id" db:"scholarship_id"`
+	SourceID             uint                  `json:"source_id" db:"source_id"`
+	ScholarshipName      string                `json:"scholarship_name" db:"scholarship_name"`
+	ScholarshipType      string                `json:"scholarship_type" db:"scholarship_type"`
+	Amount               float64               `json:"amount" db:"amount"`
+	TotalQuota           int                   `json:"total_quota" db:"total_quota"`
+	AvailableQuota       int                   `json:"available_quota" db:"available_quota"`
+	AcademicYear         string                `json:"academic_year" db:"academic_year"`
+	Semester             *string               `json:"semester" db:"semester"`
+	EligibilityCriteria  *string               `json:"eligibility_criteria" db:"eligibility_criteria"`
+	EligibilityRules     *EligibilityRules     `json:"eligibility_rules,omitempty" db:"eligibility_rules"`
+	RequiredDocuments    *string               `json:"required_documents" db:"required_documents"`
+	DocumentRequirements []DocumentRequirement `json:"document_requirements,omitempty" db:"document_requirements"`
+	ApplicationStartDate time.Time             `json:"application_start_date" db:"application_start_date"`
+	ApplicationEndDate   time.Time             `json:"application_end_date" db:"application_end_date"`
+	InterviewRequired    bool                  `json:"interview_required" db:"interview_required"`
+	IsActive             bool                  `json:"is_active" db:"is_active"`
+	CreatedBy            uuid.UUID             `json:"created_by" db:"created_by"`
+	CreatedAt            time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time             `json:"updated_at" db:"updated_at"`
 
 	// Loaded relationships
 	Source *ScholarshipSource `json:"source,omitempty"`
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Application metrics a scoring component can be based on
+const (
+	MetricGPA             = "gpa"               // students.gpa
+	MetricNeedScore       = "need_score"        // NeedAssessment.NeedScore, 0-100
+	MetricPerCapitaIncome = "per_capita_income" // NeedAssessment.PerCapitaIncome
+	MetricFamilyIncome    = "family_income"     // household income, monthly
+	MetricUnmetNeed       = "unmet_need"        // NeedAssessment.UnmetNeed, per academic year
+	MetricActivityCount   = "activity_count"    // number of activities on the form
+)
+
+// ScoringMetrics lists the metrics scoring components can use
+var ScoringMetrics = []string{
+	MetricGPA,
+	MetricNeedScore,
+	MetricPerCapitaIncome,
+	MetricFamilyIncome,
+	MetricUnmetNeed,
+	MetricActivityCount,
+}
+
+// CurvePoint is a point of a piecewise linear scoring curve: a metric value
+// of X scores Score (0-100)
+type CurvePoint struct {
+	X     float64 `json:"x"`
+	Score float64 `json:"score"`
+}
+
+// ScoringComponent turns one metric into a weighted part of the priority
+// score. CriteriaID ties it to a review_criteria row, whose name and max
+// score are used in the breakdown.
+type ScoringComponent struct {
+	Metric     string       `json:"metric"`
+	CriteriaID *int         `json:"criteria_id,omitempty"`
+	Label      string       `json:"label"`
+	Weight     float64      `json:"weight"` // weights of a profile add up to 1
+	Curve      []CurvePoint `json:"curve"`  // sorted by X; values outside are clamped
+}
+
+// ScoringProfile is a priority scoring formula for a scholarship, a round or,
+// with neither set, every scholarship without a more specific profile
+type ScoringProfile struct {
+	ProfileID     uint               `json:"profile_id" db:"profile_id"` // 0 = built-in default
+	ProfileName   string             `json:"profile_name" db:"profile_name"`
+	ScholarshipID *uint              `json:"scholarship_id" db:"scholarship_id"`
+	RoundID       *uint              `json:"round_id" db:"round_id"`
+	Components    []ScoringComponent `json:"components" db:"components"`
+	IsActive      bool               `json:"is_active" db:"is_active"`
+	CreatedBy     *uuid.UUID         `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt     *time.Time         `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt     *time.Time         `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// ScoringProfileRequest is the body for creating or updating a scoring profile
+type ScoringProfileRequest struct {
+	ProfileName   string             `json:"profile_name"`
+	ScholarshipID *uint              `json:"scholarship_id"`
+	RoundID       *uint              `json:"round_id"`
+	Components    []ScoringComponent `json:"components"`
+	IsActive      *bool              `json:"is_active"`
+}
+
+// ReviewCriterion is a row of review_criteria
+type ReviewCriterion struct {
+	CriteriaID    int     `json:"criteria_id" db:"criteria_id"`
+	ScholarshipID *uint   `json:"scholarship_id" db:"scholarship_id"`
+	CriteriaName  string  `json:"criteria_name" db:"criteria_name"`
+	CriteriaType  string  `json:"criteria_type" db:"criteria_type"`
+	MaxScore      float64 `json:"max_score" db:"max_score"`
+	Weight        float64 `json:"weight" db:"weight"`
+	IsActive      bool    `json:"is_active" db:"is_active"`
+}
+
+// ComponentScore is the result of one scoring component
+type ComponentScore struct {
+	Metric        string   `json:"metric"`
+	Label         string   `json:"label"`
+	CriteriaID    *int     `json:"criteria_id,omitempty"`
+	Value         *float64 `json:"value"` // nil when the metric is not available
+	Score         float64  `json:"score"` // 0-100 from the curve
+	Weight        float64  `json:"weight"`
+	WeightedScore float64  `json:"weighted_score"`
+	CriteriaScore *float64 `json:"criteria_score,omitempty"` // Score scaled to the criterion's max score
+}
+
+// ScoreBreakdown is the stored result of scoring an application
+type ScoreBreakdown struct {
+	ProfileID   uint             `json:"profile_id"`
+	ProfileName string           `json:"profile_name"`
+	TotalScore  float64          `json:"total_score"`
+	Components  []ComponentScore `json:"components"`
+	Missing     []string         `json:"missing"` // metrics that were not available and scored 0
+	ScoredAt    time.Time        `json:"scored_at"`
+}
+
+// Component returns the score of the first component using metric
+func (b *ScoreBreakdown) Component(metric string) *ComponentScore {
+	for i := range b.Components {
+		if b.Components[i].Metric == metric {
+			return &b.Components[i]
+		}
+	}
+	return nil
+}
+
+// DefaultScoringProfile is used when no profile applies: GPA 40%, financial
+// need 30%, activities 30%
+func DefaultScoringProfile() *ScoringProfile {
+	return &ScoringProfile{
+		ProfileName: "Default (GPA 40% / Need 30% / Activities 30%)",
+		IsActive:    true,
+		Components: []ScoringComponent{
+			{Metric: MetricGPA, Label: "ผลการเรียน", Weight: 0.4, Curve: []CurvePoint{{X: 2, Score: 50}, {X: 4, Score: 100}}},
+			{Metric: MetricNeedScore, Label: "ความจำเป็นทางการเงิน", Weight: 0.3, Curve: []CurvePoint{{X: 0, Score: 0}, {X: 100, Score: 100}}},
+			{Metric: MetricActivityCount, Label: "กิจกรรม", Weight: 0.3, Curve: []CurvePoint{{X: 0, Score: 0}, {X: 5, Score: 100}}},
+		},
+	}
+}
@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// Search weights, from the fields that identify a student to free text
+const (
+	SearchWeightA = "A" // names, student ID, national ID
+	SearchWeightB = "B" // faculty, addresses, schools, family
+	SearchWeightC = "C" // activities and abilities
+)
+
+// SearchSource is the text of an application that goes into the search index
+type SearchSource struct {
+	ApplicationID   uint
+	FormVersion     int
+	UpdatedAt       time.Time
+	StudentID       string
+	StudentName     string
+	CitizenID       string
+	ScholarshipName string
+	Faculty         string // faculty, department and major
+	Addresses       string
+	Schools         string
+	Family          string // names, occupations and workplaces of family and guardians
+	Activities      string
+}
+
+// SearchField is one indexed field, kept as text for highlighting
+type SearchField struct {
+	Name   string `json:"name"`
+	Label  string `json:"label"`
+	Weight string `json:"weight"`
+	Text   string `json:"text"`
+}
+
+// SearchDocument is the indexed form of an application
+type SearchDocument struct {
+	ApplicationID uint
+	FormVersion   int
+	SourceUpdated time.Time
+	Fields        []SearchField
+	Tokens        map[string][]string // weight -> tokens
+}
+
+// SearchScope limits which applications a user can find
+type SearchScope struct {
+	All       bool     // admins and scholarship officers
+	Faculties []string // faculty names or codes, lower case
+}
+
+// ApplicationSearchParams are the filters of an application search
+type ApplicationSearchParams struct {
+	Query         string
+	Status        string
+	ScholarshipID *uint
+	Limit         int
+	Offset        int
+}
+
+// SearchHighlight is a matching part of an indexed field
+type SearchHighlight struct {
+	Field   string `json:"field"`
+	Label   string `json:"label"`
+	Snippet string `json:"snippet"` // matches wrapped in <mark></mark>
+}
+
+// ApplicationSearchResult is an application matching a search
+type ApplicationSearchResult struct {
+	ApplicationID     uint              `json:"application_id"`
+	StudentID         string            `json:"student_id"`
+	StudentName       string            `json:"student_name"`
+	ScholarshipID     uint              `json:"scholarship_id"`
+	ScholarshipName   string            `json:"scholarship_name"`
+	ApplicationStatus string            `json:"application_status"`
+	SubmittedAt       *time.Time        `json:"submitted_at,omitempty"`
+	Rank              float64           `json:"rank"`
+	Highlights        []SearchHighlight `json:"highlights"`
+	Fields            []SearchField     `json:"-"`
+}
+
+// SearchIndexResult is the result of a search_index job
+type SearchIndexResult struct {
+	Indexed int       `json:"indexed"`
+	Full    bool      `json:"full"`
+	RanAt   time.Time `json:"ran_at"`
+}
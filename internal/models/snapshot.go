@@ -0,0 +1,51 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Snapshot reasons
+const (
+	SnapshotReasonSubmit   = "submit"
+	SnapshotReasonResubmit = "resubmit"
+)
+
+// ApplicationSnapshot is an immutable copy of the complete application form
+// and its documents taken when the application was submitted
+type ApplicationSnapshot struct {
+	SnapshotID    uint               `json:"snapshot_id" db:"snapshot_id"`
+	ApplicationID uint               `json:"application_id" db:"application_id"`
+	Version       int                `json:"version" db:"version"`
+	Reason        string             `json:"reason" db:"reason"`
+	FormData      json.RawMessage    `json:"form_data,omitempty" db:"form_data"`
+	Documents     []SnapshotDocument `json:"documents" db:"documents"`
+	Checksum      string             `json:"checksum" db:"checksum"`
+	CreatedBy     *uuid.UUID         `json:"created_by" db:"created_by"`
+	CreatedAt     time.Time          `json:"created_at" db:"created_at"`
+}
+
+// SnapshotDocument is a document as it was attached at submission time.
+// SHA256 is empty when the file could not be read.
+type SnapshotDocument struct {
+	DocumentID   int       `json:"document_id"`
+	DocumentType string    `json:"document_type"`
+	DocumentName string    `json:"document_name"`
+	FileSize     int64     `json:"file_size"`
+	MimeType     string    `json:"mime_type"`
+	SHA256       string    `json:"sha256"`
+	UploadedAt   time.Time `json:"uploaded_at"`
+}
+
+// SnapshotDiff lists what changed between two snapshots, or between a
+// snapshot and the live form when ToVersion is 0
+type SnapshotDiff struct {
+	FromVersion      int                `json:"from_version"`
+	ToVersion        int                `json:"to_version"`
+	Fields           []FieldChange      `json:"fields"`
+	DocumentsAdded   []SnapshotDocument `json:"documents_added"`
+	DocumentsRemoved []SnapshotDocument `json:"documents_removed"`
+	DocumentsChanged []SnapshotDocument `json:"documents_changed"`
+}
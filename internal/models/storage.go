@@ -0,0 +1,159 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tables holding references to stored files
+const (
+	StoredInApplicationDocuments = "application_documents"
+	StoredInFileStorage          = "file_storage"
+	StoredInDocumentVersions     = "document_versions"
+	StoredInDocumentPreviews     = "document_previews" // preview_path of application_documents
+)
+
+// StoredObject is a database row that points at a file in a storage backend
+type StoredObject struct {
+	Table       string `json:"table"`
+	ID          string `json:"id"`
+	Key         string `json:"key"`
+	Backend     string `json:"backend"`
+	ContentType string `json:"content_type"`
+	Hash        string `json:"hash,omitempty"` // SHA-256 recorded at upload, if any
+}
+
+// StorageMigrationResult summarises a move of files between backends
+type StorageMigrationResult struct {
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	Total   int      `json:"total"`
+	Moved   int      `json:"moved"`
+	Missing int      `json:"missing"`
+	Failed  int      `json:"failed"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// FileEncryptionResult summarises a run putting stored files under the
+// active master key
+type FileEncryptionResult struct {
+	Backend   string   `json:"backend"`
+	KeyID     string   `json:"key_id"`
+	Total     int      `json:"total"`
+	Encrypted int      `json:"encrypted"` // plain files that were encrypted
+	Rewrapped int      `json:"rewrapped"` // files moved off an older master key
+	Current   int      `json:"current"`
+	Missing   int      `json:"missing"`
+	Failed    int      `json:"failed"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// Upload statuses of application documents set by the malware scan, the
+// retention job and storage reconciliation. Officers move pending documents
+// on to verified or rejected.
+const (
+	DocumentQuarantined = "quarantined" // stored, waiting for the malware scan
+	DocumentPending     = "pending"     // passed the scan, waiting for an officer
+	DocumentInfected    = "infected"    // malware found, the file was deleted
+	DocumentPurged      = "purged"      // retention period over, the file was deleted
+	DocumentMissing     = "missing"     // the file is gone or damaged and must be uploaded again
+)
+
+// DocumentScanResult summarises a run of the document scan job
+type DocumentScanResult struct {
+	Scanned  int      `json:"scanned"`
+	Released int      `json:"released"`
+	Infected int      `json:"infected"`
+	Failed   int      `json:"failed"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// Preview statuses of application documents. Documents without one are
+// waiting for the document preview job.
+const (
+	PreviewReady       = "ready"
+	PreviewUnavailable = "unavailable" // the format has no preview, e.g. DOCX
+	PreviewFailed      = "failed"
+)
+
+// DocumentPreview is what the preview job learns about a document file
+type DocumentPreview struct {
+	Image       []byte // JPEG, nil when the format has no preview
+	PageCount   *int
+	ImageWidth  *int
+	ImageHeight *int
+}
+
+// DocumentPreviewResult summarises a run of the document preview job
+type DocumentPreviewResult struct {
+	Generated   int      `json:"generated"`
+	Unavailable int      `json:"unavailable"`
+	Failed      int      `json:"failed"`
+	Errors      []string `json:"errors,omitempty"`
+}
+
+// Kinds of inconsistency between stored files and their records
+const (
+	ReconcileOrphanFile   = "orphan_file"   // a file no record points at
+	ReconcileMissingFile  = "missing_file"  // a record whose file is gone
+	ReconcileHashMismatch = "hash_mismatch" // the file differs from the SHA-256 on its record
+)
+
+// What reconciliation did about an inconsistency
+const (
+	ReconcileQuarantined = "quarantined" // the orphaned file was moved aside
+	ReconcileRepaired    = "repaired"    // the record was updated
+)
+
+// Sources of a storage reconciliation
+const (
+	ReconcileSourceJob = "job"
+	ReconcileSourceCLI = "cli"
+)
+
+// ReconcileIssue is one inconsistency between stored files and their records
+type ReconcileIssue struct {
+	Kind         string `json:"kind"`
+	Backend      string `json:"backend"`
+	Key          string `json:"key"`
+	Table        string `json:"table,omitempty"`
+	ID           string `json:"id,omitempty"`
+	Size         int64  `json:"size,omitempty"`
+	ExpectedHash string `json:"expected_hash,omitempty"`
+	ActualHash   string `json:"actual_hash,omitempty"`
+	Action       string `json:"action,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// StorageReconcileRequest is the body for running a storage reconciliation
+// and the payload of its job. Hashes are verified unless turned off.
+type StorageReconcileRequest struct {
+	VerifyHashes *bool `json:"verify_hashes,omitempty"`
+	Quarantine   bool  `json:"quarantine"` // move orphaned files under orphaned/
+	Repair       bool  `json:"repair"`     // update records whose file is missing or damaged
+}
+
+// StorageReconcileResult summarises a check of stored files against the
+// records that point at them
+type StorageReconcileResult struct {
+	ReconciliationID int              `json:"reconciliation_id,omitempty"`
+	Source           string           `json:"source"`
+	JobID            *uuid.UUID       `json:"job_id,omitempty"`
+	Backends         []string         `json:"backends"`
+	VerifyHashes     bool             `json:"verify_hashes"`
+	Quarantine       bool             `json:"quarantine"`
+	Repair           bool             `json:"repair"`
+	FilesScanned     int              `json:"files_scanned"`
+	RecordsChecked   int              `json:"records_checked"`
+	OrphanFiles      int              `json:"orphan_files"`
+	MissingFiles     int              `json:"missing_files"`
+	HashMismatches   int              `json:"hash_mismatches"`
+	Quarantined      int              `json:"quarantined"`
+	Repaired         int              `json:"repaired"`
+	Failed           int              `json:"failed"`
+	Issues           []ReconcileIssue `json:"issues"`
+	Errors           []string         `json:"errors,omitempty"`
+	StartedAt        time.Time        `json:"started_at"`
+	FinishedAt       time.Time        `json:"finished_at"`
+}
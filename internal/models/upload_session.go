@@ -0,0 +1,104 @@
+package models
+
+import "time"
+
+// Statuses of a BulkUploadSession
+const (
+	UploadSessionInProgress = "in_progress"
+	UploadSessionCompleted  = "completed" // every upload finished or failed
+)
+
+// Statuses of a resumable upload
+const (
+	ResumableUploading  = "uploading"  // waiting for more chunks
+	ResumableFinalizing = "finalizing" // being turned into a document
+	ResumableCompleted  = "completed"
+	ResumableFailed     = "failed"
+)
+
+// ResumableUpload is a file sent in chunks, possibly over several
+// connections, that becomes an application document once complete. Uploads
+// belong to a BulkUploadSession, which tracks progress and expiry.
+type ResumableUpload struct {
+	UploadID          string     `json:"upload_id" db:"upload_id"`
+	SessionID         int        `json:"-" db:"session_id"`
+	SessionToken      string     `json:"session_token" db:"session_token"`
+	ApplicationID     int        `json:"application_id" db:"application_id"`
+	DocumentType      string     `json:"document_type" db:"document_type"`
+	FileName          string     `json:"file_name" db:"file_name"`
+	UploadLength      int64      `json:"upload_length" db:"upload_length"`
+	UploadOffset      int64      `json:"upload_offset" db:"upload_offset"`
+	StorageBackend    string     `json:"-" db:"storage_backend"`
+	ReplaceDocumentID *int       `json:"replace_document_id,omitempty" db:"replace_document_id"`
+	DocumentID        *int       `json:"document_id,omitempty" db:"document_id"`
+	UploadStatus      string     `json:"upload_status" db:"upload_status"`
+	ErrorMessage      *string    `json:"error_message,omitempty" db:"error_message"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+	ExpiresAt         time.Time  `json:"expires_at" db:"expires_at"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// CreateResumableUploadRequest starts a resumable upload. Without a session
+// token a new session is started for the application.
+type CreateResumableUploadRequest struct {
+	SessionToken      string `json:"session_token"`
+	ApplicationID     int    `json:"application_id"`
+	DocumentType      string `json:"document_type"`
+	FileName          string `json:"file_name"`
+	FileSize          int64  `json:"file_size"`
+	ReplaceDocumentID int    `json:"replace_document_id"`
+}
+
+// UploadSessionProgress is a session with its uploads
+type UploadSessionProgress struct {
+	UploadProgressResponse
+	ApplicationID *int              `json:"application_id,omitempty"`
+	ExpiresAt     time.Time         `json:"expires_at"`
+	Uploads       []ResumableUpload `json:"uploads"`
+}
+
+// UploadCleanupResult summarises a run of the upload cleanup job
+type UploadCleanupResult struct {
+	Sessions int      `json:"sessions"`
+	Chunks   int      `json:"chunks"`
+	Failed   int      `json:"failed"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// Kinds of upload session
+const (
+	UploadSessionStudent = "student" // a student's own uploads
+	UploadSessionImport  = "import"  // an officer's ZIP import for many students
+)
+
+// DocumentManifestRow is a line of the manifest of a document import. The
+// application is given by ID, or by student and scholarship. A blank
+// document type is worked out from the file name.
+type DocumentManifestRow struct {
+	Line          int    `json:"line"`
+	File          string `json:"file"`
+	ApplicationID int    `json:"application_id,omitempty"`
+	StudentID     string `json:"student_id,omitempty"`
+	ScholarshipID int    `json:"scholarship_id,omitempty"`
+	DocumentType  string `json:"document_type,omitempty"`
+}
+
+// BulkFileResult is the outcome for one file of a bulk upload or import
+type BulkFileResult struct {
+	FileName      string `json:"file_name"`
+	Line          int    `json:"line,omitempty"`
+	ApplicationID int    `json:"application_id,omitempty"`
+	DocumentType  string `json:"document_type,omitempty"`
+	UploadID      string `json:"upload_id,omitempty"`
+	DocumentID    int    `json:"document_id,omitempty"`
+	Status        string `json:"status"`
+	Error         string `json:"error,omitempty"`
+}
+
+// Statuses of a BulkFileResult
+const (
+	BulkFileUploaded = "uploaded" // staged, attached when the session completes
+	BulkFileAttached = "attached" // saved as an application document
+	BulkFileFailed   = "failed"
+)
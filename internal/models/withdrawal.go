@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Withdrawal types
+const (
+	WithdrawalTypeWithdraw = "withdraw" // student withdraws an application before a final decision
+	WithdrawalTypeDecline  = "decline"  // student declines an awarded scholarship
+)
+
+// ApplicationWithdrawal records a withdrawal or award decline and what it
+// released back to the scholarship
+type ApplicationWithdrawal struct {
+	WithdrawalID      uint       `json:"withdrawal_id" db:"withdrawal_id"`
+	ApplicationID     uint       `json:"application_id" db:"application_id"`
+	WithdrawalType    string     `json:"withdrawal_type" db:"withdrawal_type"`
+	PreviousStatus    string     `json:"previous_status" db:"previous_status"`
+	Reason            string     `json:"reason" db:"reason"`
+	RequestedBy       *uuid.UUID `json:"requested_by" db:"requested_by"`
+	AllocationID      *int       `json:"allocation_id" db:"allocation_id"`
+	ReleasedAmount    float64    `json:"released_amount" db:"released_amount"`
+	QuotaReleased     bool       `json:"quota_released" db:"quota_released"`
+	CancelledBookings int        `json:"cancelled_bookings" db:"cancelled_bookings"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+
+	// Users to notify, filled in when the withdrawal is processed
+	ReviewerIDs    []uuid.UUID `json:"-" db:"-"`
+	InterviewerIDs []uuid.UUID `json:"-" db:"-"`
+}
+
+// WithdrawalRequest is the body of the withdraw and decline endpoints
+type WithdrawalRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
@@ -426,6 +426,46 @@ func (r *ApplicationRepository) Submit(applicationID uint) error {
 	return err
 }
 
+// GetApplicantUserID returns the user account of the student who owns an
+// application. Applications may reference the student by student_id or, for
+// users without a student record, by email.
+func (r *ApplicationRepository) GetApplicantUserID(applicationID uint) (uuid.UUID, error) {
+	query := `
+		SELECT COALESCE(s.user_id, u.user_id)
+		FROM scholarship_applications sa
+		LEFT JOIN students s ON sa.student_id = s.student_id
+		LEFT JOIN users u ON sa.student_id = u.email
+		WHERE sa.application_id = $1
+	`
+
+	var userID uuid.NullUUID
+	if err := r.db.QueryRow(query, applicationID).Scan(&userID); err != nil {
+		return uuid.Nil, err
+	}
+	if !userID.Valid {
+		return uuid.Nil, sql.ErrNoRows
+	}
+	return userID.UUID, nil
+}
+
+// FindApplicationID returns the latest application of a student for a
+// scholarship
+func (r *ApplicationRepository) FindApplicationID(studentID string, scholarshipID int) (int, error) {
+	query := `
+		SELECT application_id
+		FROM scholarship_applications
+		WHERE student_id = $1 AND scholarship_id = $2
+		ORDER BY created_at DESC, application_id DESC
+		LIMIT 1
+	`
+
+	var applicationID int
+	if err := r.db.QueryRow(query, studentID, scholarshipID).Scan(&applicationID); err != nil {
+		return 0, err
+	}
+	return applicationID, nil
+}
+
 func (r *ApplicationRepository) Delete(applicationID uint) error {
 	query := `DELETE FROM scholarship_applications WHERE application_id = $1`
 	_, err := r.db.Exec(query, applicationID)
@@ -436,8 +476,8 @@ func (r *ApplicationRepository) Delete(applicationID uint) error {
 func (r *ApplicationRepository) AddDocument(doc *models.ApplicationDocument) error {
 	query := `
 		INSERT INTO application_documents (application_id, document_type, document_name, file_path, 
-		                                 file_size, mime_type, upload_status, uploaded_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING document_id
+		                                 file_size, mime_type, upload_status, uploaded_at, file_hash, storage_backend, uploaded_by, issued_date)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), COALESCE(NULLIF($10, ''), 'local'), $11, $12) RETURNING document_id, version_number
 	`
 	
 	doc.UploadedAt = time.Now()
@@ -451,16 +491,45 @@ func (r *ApplicationRepository) AddDocument(doc *models.ApplicationDocument) err
 		doc.MimeType,
 		doc.UploadStatus,
 		doc.UploadedAt,
-	).Scan(&doc.DocumentID)
+		doc.FileHash,
+		doc.StorageBackend,
+		doc.UploadedBy,
+		doc.IssuedDate,
+	).Scan(&doc.DocumentID, &doc.VersionNumber)
 	
 	return err
 }
 
+// GetDocument returns one application document
+func (r *ApplicationRepository) GetDocument(documentID int) (*models.ApplicationDocument, error) {
+	doc := &models.ApplicationDocument{}
+	err := r.db.QueryRow(`
+		SELECT document_id, application_id, document_type, document_name, file_path,
+		       COALESCE(file_size, 0), COALESCE(mime_type, ''), upload_status, verification_notes, uploaded_at,
+		       verified_by, verified_at, COALESCE(file_hash, ''), storage_backend, version_number, uploaded_by,
+		       issued_date, page_count, image_width, image_height, preview_status,
+		       COALESCE(preview_path, ''), COALESCE(preview_backend, '')
+		FROM application_documents
+		WHERE document_id = $1
+	`, documentID).Scan(
+		&doc.DocumentID, &doc.ApplicationID, &doc.DocumentType, &doc.DocumentName, &doc.FilePath,
+		&doc.FileSize, &doc.MimeType, &doc.UploadStatus, &doc.VerificationNotes, &doc.UploadedAt,
+		&doc.VerifiedBy, &doc.VerifiedAt, &doc.FileHash, &doc.StorageBackend, &doc.VersionNumber, &doc.UploadedBy,
+		&doc.IssuedDate, &doc.PageCount, &doc.ImageWidth, &doc.ImageHeight, &doc.PreviewStatus,
+		&doc.PreviewPath, &doc.PreviewBackend,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
 func (r *ApplicationRepository) GetDocuments(applicationID uint) ([]models.ApplicationDocument, error) {
 	query := `
 		SELECT document_id, application_id, document_type, document_name, file_path, 
 		       file_size, mime_type, upload_status, verification_notes, uploaded_at, 
-		       verified_by, verified_at
+		       verified_by, verified_at, COALESCE(file_hash, ''), storage_backend, version_number, issued_date,
+		       page_count, image_width, image_height, preview_status
 		FROM application_documents 
 		WHERE application_id = $1
 		ORDER BY uploaded_at DESC
@@ -490,6 +559,14 @@ func (r *ApplicationRepository) GetDocuments(applicationID uint) ([]models.Appli
 			&doc.UploadedAt,
 			&doc.VerifiedBy,
 			&doc.VerifiedAt,
+			&doc.FileHash,
+			&doc.StorageBackend,
+			&doc.VersionNumber,
+			&doc.IssuedDate,
+			&doc.PageCount,
+			&doc.ImageWidth,
+			&doc.ImageHeight,
+			&doc.PreviewStatus,
 		)
 		
 		if err != nil {
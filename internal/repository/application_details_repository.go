@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/lib/pq"
+
 	"scholarship-system/internal/database"
 	"scholarship-system/internal/models"
 )
@@ -13,6 +15,12 @@ type ApplicationDetailsRepository struct {
 	db *sql.DB
 }
 
+// sqlRunner is satisfied by both *sql.DB and *sql.Tx
+type sqlRunner interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
 func NewApplicationDetailsRepository() *ApplicationDetailsRepository {
 	return &ApplicationDetailsRepository{
 		db: database.DB,
@@ -24,6 +32,10 @@ func NewApplicationDetailsRepository() *ApplicationDetailsRepository {
 // ========================================
 
 func (r *ApplicationDetailsRepository) CreatePersonalInfo(info *models.ApplicationPersonalInfo) error {
+	return r.createPersonalInfo(r.db, info)
+}
+
+func (r *ApplicationDetailsRepository) createPersonalInfo(q sqlRunner, info *models.ApplicationPersonalInfo) error {
 	query := `
 		INSERT INTO application_personal_info (
 			application_id, prefix_th, prefix_en, first_name_th, last_name_th,
@@ -35,7 +47,7 @@ func (r *ApplicationDetailsRepository) CreatePersonalInfo(info *models.Applicati
 	`
 
 	now := time.Now()
-	err := r.db.QueryRow(query,
+	err := q.QueryRow(query,
 		info.ApplicationID, info.PrefixTH, info.PrefixEN, info.FirstNameTH, info.LastNameTH,
 		info.FirstNameEN, info.LastNameEN, info.Email, info.Phone, info.LineID,
 		info.CitizenID, info.StudentID, info.Faculty, info.Department, info.Major,
@@ -71,6 +83,10 @@ func (r *ApplicationDetailsRepository) GetPersonalInfoByApplicationID(applicatio
 }
 
 func (r *ApplicationDetailsRepository) UpdatePersonalInfo(info *models.ApplicationPersonalInfo) error {
+	return r.updatePersonalInfo(r.db, info)
+}
+
+func (r *ApplicationDetailsRepository) updatePersonalInfo(q sqlRunner, info *models.ApplicationPersonalInfo) error {
 	query := `
 		UPDATE application_personal_info
 		SET prefix_th = $2, prefix_en = $3, first_name_th = $4, last_name_th = $5,
@@ -80,7 +96,7 @@ func (r *ApplicationDetailsRepository) UpdatePersonalInfo(info *models.Applicati
 		WHERE info_id = $1
 	`
 
-	_, err := r.db.Exec(query,
+	_, err := q.Exec(query,
 		info.InfoID, info.PrefixTH, info.PrefixEN, info.FirstNameTH, info.LastNameTH,
 		info.FirstNameEN, info.LastNameEN, info.Email, info.Phone, info.LineID,
 		info.CitizenID, info.StudentID, info.Faculty, info.Department, info.Major,
@@ -429,9 +445,9 @@ func (r *ApplicationDetailsRepository) CreateGuardian(guardian *models.Applicati
 		INSERT INTO application_guardians (
 			application_id, title, first_name, last_name, relationship,
 			address, phone, occupation, position, workplace,
-			workplace_phone, monthly_income, debts, debt_details,
+			workplace_phone, monthly_income, debts, debt_details, national_id,
 			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 		RETURNING guardian_id
 	`
 
@@ -439,7 +455,7 @@ func (r *ApplicationDetailsRepository) CreateGuardian(guardian *models.Applicati
 	err := r.db.QueryRow(query,
 		guardian.ApplicationID, guardian.Title, guardian.FirstName, guardian.LastName, guardian.Relationship,
 		guardian.Address, guardian.Phone, guardian.Occupation, guardian.Position, guardian.Workplace,
-		guardian.WorkplacePhone, guardian.MonthlyIncome, guardian.Debts, guardian.DebtDetails,
+		guardian.WorkplacePhone, guardian.MonthlyIncome, guardian.Debts, guardian.DebtDetails, guardian.NationalID,
 		now, now,
 	).Scan(&guardian.GuardianID)
 
@@ -450,7 +466,7 @@ func (r *ApplicationDetailsRepository) GetGuardiansByApplicationID(applicationID
 	query := `
 		SELECT guardian_id, application_id, title, first_name, last_name, relationship,
 		       address, phone, occupation, position, workplace,
-		       workplace_phone, monthly_income, debts, debt_details,
+		       workplace_phone, monthly_income, debts, debt_details, national_id,
 		       created_at, updated_at
 		FROM application_guardians
 		WHERE application_id = $1
@@ -468,7 +484,7 @@ func (r *ApplicationDetailsRepository) GetGuardiansByApplicationID(applicationID
 		err := rows.Scan(
 			&guardian.GuardianID, &guardian.ApplicationID, &guardian.Title, &guardian.FirstName, &guardian.LastName, &guardian.Relationship,
 			&guardian.Address, &guardian.Phone, &guardian.Occupation, &guardian.Position, &guardian.Workplace,
-			&guardian.WorkplacePhone, &guardian.MonthlyIncome, &guardian.Debts, &guardian.DebtDetails,
+			&guardian.WorkplacePhone, &guardian.MonthlyIncome, &guardian.Debts, &guardian.DebtDetails, &guardian.NationalID,
 			&guardian.CreatedAt, &guardian.UpdatedAt,
 		)
 		if err != nil {
@@ -486,7 +502,7 @@ func (r *ApplicationDetailsRepository) UpdateGuardian(guardian *models.Applicati
 		SET title = $2, first_name = $3, last_name = $4, relationship = $5,
 		    address = $6, phone = $7, occupation = $8, position = $9, workplace = $10,
 		    workplace_phone = $11, monthly_income = $12, debts = $13, debt_details = $14,
-		    updated_at = $15
+		    national_id = $16, updated_at = $15
 		WHERE guardian_id = $1
 	`
 
@@ -494,7 +510,7 @@ func (r *ApplicationDetailsRepository) UpdateGuardian(guardian *models.Applicati
 		guardian.GuardianID, guardian.Title, guardian.FirstName, guardian.LastName, guardian.Relationship,
 		guardian.Address, guardian.Phone, guardian.Occupation, guardian.Position, guardian.Workplace,
 		guardian.WorkplacePhone, guardian.MonthlyIncome, guardian.Debts, guardian.DebtDetails,
-		time.Now(),
+		time.Now(), guardian.NationalID,
 	)
 
 	return err
@@ -1256,13 +1272,36 @@ func (r *ApplicationDetailsRepository) DeleteIncomeCertificate(certID string) er
 // ========================================
 
 // SavePersonalInfo saves or updates personal information (upsert)
-func (r *ApplicationDetailsRepository) SavePersonalInfo(info *models.ApplicationPersonalInfo) (*models.ApplicationPersonalInfo, error) {
+func (r *ApplicationDetailsRepository) SavePersonalInfo(info *models.ApplicationPersonalInfo, expectedVersion *int) (*models.ApplicationPersonalInfo, int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	version, err := r.claimVersion(tx, uint(info.ApplicationID), models.SectionPersonalInfo, expectedVersion)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if _, err := r.savePersonalInfoTx(tx, info); err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return info, version, nil
+}
+
+func (r *ApplicationDetailsRepository) savePersonalInfoTx(tx *sql.Tx, info *models.ApplicationPersonalInfo) (*models.ApplicationPersonalInfo, error) {
 	// Check if exists
 	existing, err := r.GetPersonalInfoByApplicationID(info.ApplicationID)
 
 	if err == sql.ErrNoRows || existing == nil {
 		// Create new
-		err = r.CreatePersonalInfo(info)
+		err = r.createPersonalInfo(tx, info)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create personal info: %w", err)
 		}
@@ -1275,7 +1314,7 @@ func (r *ApplicationDetailsRepository) SavePersonalInfo(info *models.Application
 
 	// Update existing - use existing ID
 	info.InfoID = existing.InfoID
-	err = r.UpdatePersonalInfo(info)
+	err = r.updatePersonalInfo(tx, info)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update personal info: %w", err)
 	}
@@ -1284,13 +1323,33 @@ func (r *ApplicationDetailsRepository) SavePersonalInfo(info *models.Application
 }
 
 // SaveAddresses saves or updates addresses (replaces all addresses)
-func (r *ApplicationDetailsRepository) SaveAddresses(applicationID uint, addresses []models.ApplicationAddress) ([]models.ApplicationAddress, error) {
+func (r *ApplicationDetailsRepository) SaveAddresses(applicationID uint, addresses []models.ApplicationAddress, expectedVersion *int) ([]models.ApplicationAddress, int, error) {
 	tx, err := r.db.Begin()
 	if err != nil {
-		return nil, fmt.Errorf("failed to start transaction: %w", err)
+		return nil, 0, fmt.Errorf("failed to start transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	version, err := r.claimVersion(tx, applicationID, models.SectionAddressInfo, expectedVersion)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	savedAddresses, err := r.saveAddressesTx(tx, applicationID, addresses)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return savedAddresses, version, nil
+}
+
+func (r *ApplicationDetailsRepository) saveAddressesTx(tx *sql.Tx, applicationID uint, addresses []models.ApplicationAddress) ([]models.ApplicationAddress, error) {
+	var err error
+
 	// Delete existing addresses
 	deleteQuery := `DELETE FROM application_addresses WHERE application_id = $1`
 	_, err = tx.Exec(deleteQuery, applicationID)
@@ -1330,21 +1389,37 @@ func (r *ApplicationDetailsRepository) SaveAddresses(applicationID uint, address
 		savedAddresses = append(savedAddresses, addr)
 	}
 
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return savedAddresses, nil
 }
 
 // SaveEducation saves or updates education history (replaces all records)
-func (r *ApplicationDetailsRepository) SaveEducation(applicationID uint, education []models.ApplicationEducationHistory) ([]models.ApplicationEducationHistory, error) {
+func (r *ApplicationDetailsRepository) SaveEducation(applicationID uint, education []models.ApplicationEducationHistory, expectedVersion *int) ([]models.ApplicationEducationHistory, int, error) {
 	tx, err := r.db.Begin()
 	if err != nil {
-		return nil, fmt.Errorf("failed to start transaction: %w", err)
+		return nil, 0, fmt.Errorf("failed to start transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	version, err := r.claimVersion(tx, applicationID, models.SectionEducationHistory, expectedVersion)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	savedEducation, err := r.saveEducationTx(tx, applicationID, education)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return savedEducation, version, nil
+}
+
+func (r *ApplicationDetailsRepository) saveEducationTx(tx *sql.Tx, applicationID uint, education []models.ApplicationEducationHistory) ([]models.ApplicationEducationHistory, error) {
+	var err error
+
 	// Delete existing education records
 	deleteQuery := `DELETE FROM application_education_history WHERE application_id = $1`
 	_, err = tx.Exec(deleteQuery, applicationID)
@@ -1380,10 +1455,6 @@ func (r *ApplicationDetailsRepository) SaveEducation(applicationID uint, educati
 		savedEducation = append(savedEducation, edu)
 	}
 
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return savedEducation, nil
 }
 
@@ -1394,26 +1465,95 @@ func (r *ApplicationDetailsRepository) SaveFamily(
 	guardians []models.ApplicationGuardian,
 	siblings []models.ApplicationSibling,
 	livingSituation *models.ApplicationLivingSituation,
-) (map[string]interface{}, error) {
+	expectedVersion *int,
+) (map[string]interface{}, int, error) {
 	tx, err := r.db.Begin()
 	if err != nil {
-		return nil, fmt.Errorf("failed to start transaction: %w", err)
+		return nil, 0, fmt.Errorf("failed to start transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	version, err := r.claimVersion(tx, applicationID, models.SectionFamilyInfo, expectedVersion)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result, err := r.saveFamilyTx(tx, applicationID, members, guardians, siblings, livingSituation)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, version, nil
+}
+
+func (r *ApplicationDetailsRepository) saveFamilyTx(
+	tx *sql.Tx,
+	applicationID uint,
+	members []models.ApplicationFamilyMember,
+	guardians []models.ApplicationGuardian,
+	siblings []models.ApplicationSibling,
+	livingSituation *models.ApplicationLivingSituation,
+) (map[string]interface{}, error) {
+	var err error
+
 	result := make(map[string]interface{})
 
+	// Family rows that carry their id are updated in place so ids stay stable
+	// across saves and a concurrent editor's rows are not recreated under new
+	// ids. Rows missing from the request are removed and rows without an id
+	// are inserted.
+
 	// Save family members
 	if members != nil {
-		deleteQuery := `DELETE FROM application_family_members WHERE application_id = $1`
-		_, err = tx.Exec(deleteQuery, applicationID)
+		keep := make([]string, 0, len(members))
+		for _, member := range members {
+			if member.MemberID != "" {
+				keep = append(keep, member.MemberID)
+			}
+		}
+
+		deleteQuery := `DELETE FROM application_family_members WHERE application_id = $1 AND NOT (member_id::text = ANY($2))`
+		_, err = tx.Exec(deleteQuery, applicationID, pq.Array(keep))
 		if err != nil {
-			return nil, fmt.Errorf("failed to delete existing family members: %w", err)
+			return nil, fmt.Errorf("failed to delete removed family members: %w", err)
 		}
 
 		savedMembers := make([]models.ApplicationFamilyMember, 0, len(members))
 		for _, member := range members {
 			member.ApplicationID = int(applicationID)
+			now := time.Now()
+
+			if member.MemberID != "" {
+				updateQuery := `
+					UPDATE application_family_members
+					SET relationship = $3, title = $4, first_name = $5, last_name = $6,
+					    age = $7, living_status = $8, occupation = $9, position = $10, workplace = $11,
+					    workplace_province = $12, monthly_income = $13, phone = $14, notes = $15,
+					    updated_at = $16
+					WHERE member_id::text = $1 AND application_id = $2
+					RETURNING created_at
+				`
+
+				err = tx.QueryRow(updateQuery,
+					member.MemberID, member.ApplicationID, member.Relationship, member.Title, member.FirstName, member.LastName,
+					member.Age, member.LivingStatus, member.Occupation, member.Position, member.Workplace,
+					member.WorkplaceProvince, member.MonthlyIncome, member.Phone, member.Notes,
+					now,
+				).Scan(&member.CreatedAt)
+
+				if err == nil {
+					member.UpdatedAt = now
+					savedMembers = append(savedMembers, member)
+					continue
+				}
+				if err != sql.ErrNoRows {
+					return nil, fmt.Errorf("failed to update family member: %w", err)
+				}
+			}
 
 			insertQuery := `
 				INSERT INTO application_family_members (
@@ -1425,7 +1565,6 @@ func (r *ApplicationDetailsRepository) SaveFamily(
 				RETURNING member_id
 			`
 
-			now := time.Now()
 			err = tx.QueryRow(insertQuery,
 				member.ApplicationID, member.Relationship, member.Title, member.FirstName, member.LastName,
 				member.Age, member.LivingStatus, member.Occupation, member.Position, member.Workplace,
@@ -1446,31 +1585,66 @@ func (r *ApplicationDetailsRepository) SaveFamily(
 
 	// Save guardians
 	if guardians != nil {
-		deleteQuery := `DELETE FROM application_guardians WHERE application_id = $1`
-		_, err = tx.Exec(deleteQuery, applicationID)
+		keep := make([]string, 0, len(guardians))
+		for _, guardian := range guardians {
+			if guardian.GuardianID != "" {
+				keep = append(keep, guardian.GuardianID)
+			}
+		}
+
+		deleteQuery := `DELETE FROM application_guardians WHERE application_id = $1 AND NOT (guardian_id::text = ANY($2))`
+		_, err = tx.Exec(deleteQuery, applicationID, pq.Array(keep))
 		if err != nil {
-			return nil, fmt.Errorf("failed to delete existing guardians: %w", err)
+			return nil, fmt.Errorf("failed to delete removed guardians: %w", err)
 		}
 
 		savedGuardians := make([]models.ApplicationGuardian, 0, len(guardians))
 		for _, guardian := range guardians {
 			guardian.ApplicationID = int(applicationID)
+			now := time.Now()
+
+			if guardian.GuardianID != "" {
+				updateQuery := `
+					UPDATE application_guardians
+					SET title = $3, first_name = $4, last_name = $5, relationship = $6,
+					    address = $7, phone = $8, occupation = $9, position = $10, workplace = $11,
+					    workplace_phone = $12, monthly_income = $13, debts = $14, debt_details = $15,
+					    national_id = $17, updated_at = $16
+					WHERE guardian_id::text = $1 AND application_id = $2
+					RETURNING created_at
+				`
+
+				err = tx.QueryRow(updateQuery,
+					guardian.GuardianID, guardian.ApplicationID, guardian.Title, guardian.FirstName, guardian.LastName, guardian.Relationship,
+					guardian.Address, guardian.Phone, guardian.Occupation, guardian.Position, guardian.Workplace,
+					guardian.WorkplacePhone, guardian.MonthlyIncome, guardian.Debts, guardian.DebtDetails,
+					now, guardian.NationalID,
+				).Scan(&guardian.CreatedAt)
+
+				if err == nil {
+					guardian.UpdatedAt = now
+					savedGuardians = append(savedGuardians, guardian)
+					continue
+				}
+				if err != sql.ErrNoRows {
+					return nil, fmt.Errorf("failed to update guardian: %w", err)
+				}
+			}
 
 			insertQuery := `
 				INSERT INTO application_guardians (
 					application_id, title, first_name, last_name, relationship,
 					address, phone, occupation, position, workplace,
-					workplace_phone, monthly_income, debts, debt_details,
+					workplace_phone, monthly_income, debts, debt_details, national_id,
 					created_at, updated_at
-				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 				RETURNING guardian_id
 			`
 
-			now := time.Now()
 			err = tx.QueryRow(insertQuery,
 				guardian.ApplicationID, guardian.Title, guardian.FirstName, guardian.LastName, guardian.Relationship,
 				guardian.Address, guardian.Phone, guardian.Occupation, guardian.Position, guardian.Workplace,
-				guardian.WorkplacePhone, guardian.MonthlyIncome, guardian.Debts, guardian.DebtDetails,
+				guardian.WorkplacePhone, guardian.MonthlyIncome, guardian.Debts, guardian.DebtDetails, guardian.NationalID,
 				now, now,
 			).Scan(&guardian.GuardianID)
 
@@ -1487,15 +1661,47 @@ func (r *ApplicationDetailsRepository) SaveFamily(
 
 	// Save siblings
 	if siblings != nil {
-		deleteQuery := `DELETE FROM application_siblings WHERE application_id = $1`
-		_, err = tx.Exec(deleteQuery, applicationID)
+		keep := make([]string, 0, len(siblings))
+		for _, sibling := range siblings {
+			if sibling.SiblingID != "" {
+				keep = append(keep, sibling.SiblingID)
+			}
+		}
+
+		deleteQuery := `DELETE FROM application_siblings WHERE application_id = $1 AND NOT (sibling_id::text = ANY($2))`
+		_, err = tx.Exec(deleteQuery, applicationID, pq.Array(keep))
 		if err != nil {
-			return nil, fmt.Errorf("failed to delete existing siblings: %w", err)
+			return nil, fmt.Errorf("failed to delete removed siblings: %w", err)
 		}
 
 		savedSiblings := make([]models.ApplicationSibling, 0, len(siblings))
 		for _, sibling := range siblings {
 			sibling.ApplicationID = int(applicationID)
+			now := time.Now()
+
+			if sibling.SiblingID != "" {
+				updateQuery := `
+					UPDATE application_siblings
+					SET sibling_order = $3, gender = $4, school_or_workplace = $5, education_level = $6,
+					    is_working = $7, monthly_income = $8, notes = $9, updated_at = $10
+					WHERE sibling_id::text = $1 AND application_id = $2
+					RETURNING created_at
+				`
+
+				err = tx.QueryRow(updateQuery,
+					sibling.SiblingID, sibling.ApplicationID, sibling.SiblingOrder, sibling.Gender, sibling.SchoolOrWorkplace,
+					sibling.EducationLevel, sibling.IsWorking, sibling.MonthlyIncome, sibling.Notes, now,
+				).Scan(&sibling.CreatedAt)
+
+				if err == nil {
+					sibling.UpdatedAt = now
+					savedSiblings = append(savedSiblings, sibling)
+					continue
+				}
+				if err != sql.ErrNoRows {
+					return nil, fmt.Errorf("failed to update sibling: %w", err)
+				}
+			}
 
 			insertQuery := `
 				INSERT INTO application_siblings (
@@ -1505,7 +1711,6 @@ func (r *ApplicationDetailsRepository) SaveFamily(
 				RETURNING sibling_id
 			`
 
-			now := time.Now()
 			err = tx.QueryRow(insertQuery,
 				sibling.ApplicationID, sibling.SiblingOrder, sibling.Gender, sibling.SchoolOrWorkplace, sibling.EducationLevel,
 				sibling.IsWorking, sibling.MonthlyIncome, sibling.Notes, now, now,
@@ -1579,10 +1784,6 @@ func (r *ApplicationDetailsRepository) SaveFamily(
 		result["living_situation"] = livingSituation
 	}
 
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return result, nil
 }
 
@@ -1594,13 +1795,42 @@ func (r *ApplicationDetailsRepository) SaveFinancial(
 	scholarshipHistory []models.ApplicationScholarshipHistory,
 	healthInfo *models.ApplicationHealthInfo,
 	fundingNeeds *models.ApplicationFundingNeeds,
-) (map[string]interface{}, error) {
+	expectedVersion *int,
+) (map[string]interface{}, int, error) {
 	tx, err := r.db.Begin()
 	if err != nil {
-		return nil, fmt.Errorf("failed to start transaction: %w", err)
+		return nil, 0, fmt.Errorf("failed to start transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	version, err := r.claimVersion(tx, applicationID, models.SectionFinancialInfo, expectedVersion)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result, err := r.saveFinancialTx(tx, applicationID, financialInfo, assets, scholarshipHistory, healthInfo, fundingNeeds)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, version, nil
+}
+
+func (r *ApplicationDetailsRepository) saveFinancialTx(
+	tx *sql.Tx,
+	applicationID uint,
+	financialInfo *models.ApplicationFinancialInfo,
+	assets []models.ApplicationAsset,
+	scholarshipHistory []models.ApplicationScholarshipHistory,
+	healthInfo *models.ApplicationHealthInfo,
+	fundingNeeds *models.ApplicationFundingNeeds,
+) (map[string]interface{}, error) {
+	var err error
+
 	result := make(map[string]interface{})
 
 	// Save financial info (upsert)
@@ -1862,10 +2092,6 @@ func (r *ApplicationDetailsRepository) SaveFinancial(
 		result["funding_needs"] = fundingNeeds
 	}
 
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return result, nil
 }
 
@@ -1874,13 +2100,39 @@ func (r *ApplicationDetailsRepository) SaveActivities(
 	applicationID uint,
 	activities []models.ApplicationActivity,
 	references []models.ApplicationReference,
-) (map[string]interface{}, error) {
+	expectedVersion *int,
+) (map[string]interface{}, int, error) {
 	tx, err := r.db.Begin()
 	if err != nil {
-		return nil, fmt.Errorf("failed to start transaction: %w", err)
+		return nil, 0, fmt.Errorf("failed to start transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	version, err := r.claimVersion(tx, applicationID, models.SectionActivitiesSkills, expectedVersion)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result, err := r.saveActivitiesTx(tx, applicationID, activities, references)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, version, nil
+}
+
+func (r *ApplicationDetailsRepository) saveActivitiesTx(
+	tx *sql.Tx,
+	applicationID uint,
+	activities []models.ApplicationActivity,
+	references []models.ApplicationReference,
+) (map[string]interface{}, error) {
+	var err error
+
 	result := make(map[string]interface{})
 
 	// Save activities (replace all)
@@ -1957,34 +2209,95 @@ func (r *ApplicationDetailsRepository) SaveActivities(
 		result["references"] = savedReferences
 	}
 
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return result, nil
 }
 
-// SaveCompleteForm saves all application details at once in a transaction
-func (r *ApplicationDetailsRepository) SaveCompleteForm(applicationID uint, form *models.CompleteApplicationForm) (*models.CompleteApplicationForm, error) {
+// SaveCompleteForm saves all application details at once in a transaction.
+// Sections left out of the form are not touched.
+func (r *ApplicationDetailsRepository) SaveCompleteForm(applicationID uint, form *models.CompleteApplicationForm, expectedVersion *int) (*models.CompleteApplicationForm, int, error) {
 	tx, err := r.db.Begin()
 	if err != nil {
-		return nil, fmt.Errorf("failed to start transaction: %w", err)
+		return nil, 0, fmt.Errorf("failed to start transaction: %w", err)
 	}
 	defer tx.Rollback()
 
-	// Note: This is a simplified implementation that uses the existing Save methods
-	// In a production environment, you might want to implement this differently to use a single transaction
+	version, err := r.claimFormVersion(tx, applicationID, completeFormSections(form), expectedVersion)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	// For now, we'll call the individual save methods
-	// This is not ideal because each method starts its own transaction
-	// A better approach would be to refactor the save methods to accept a transaction parameter
+	if form.PersonalInfo != nil {
+		form.PersonalInfo.ApplicationID = int(applicationID)
+		if _, err := r.savePersonalInfoTx(tx, form.PersonalInfo); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if form.Addresses != nil {
+		if _, err := r.saveAddressesTx(tx, applicationID, form.Addresses); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if form.EducationHistory != nil {
+		if _, err := r.saveEducationTx(tx, applicationID, form.EducationHistory); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if form.FamilyMembers != nil || form.Guardians != nil || form.Siblings != nil || form.LivingSituation != nil {
+		if _, err := r.saveFamilyTx(tx, applicationID, form.FamilyMembers, form.Guardians, form.Siblings, form.LivingSituation); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if form.FinancialInfo != nil || form.Assets != nil || form.ScholarshipHistory != nil || form.HealthInfo != nil || form.FundingNeeds != nil {
+		if _, err := r.saveFinancialTx(tx, applicationID, form.FinancialInfo, form.Assets, form.ScholarshipHistory, form.HealthInfo, form.FundingNeeds); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if form.Activities != nil || form.References != nil {
+		if _, err := r.saveActivitiesTx(tx, applicationID, form.Activities, form.References); err != nil {
+			return nil, 0, err
+		}
+	}
 
 	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, 0, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	// After saving, retrieve the complete form
-	return r.GetCompleteForm(applicationID)
+	savedForm, err := r.GetCompleteForm(applicationID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return savedForm, version, nil
+}
+
+// completeFormSections lists the sections SaveCompleteForm writes for form,
+// using the same checks that decide whether each part is saved
+func completeFormSections(form *models.CompleteApplicationForm) []string {
+	var sections []string
+	if form.PersonalInfo != nil {
+		sections = append(sections, models.SectionPersonalInfo)
+	}
+	if form.Addresses != nil {
+		sections = append(sections, models.SectionAddressInfo)
+	}
+	if form.EducationHistory != nil {
+		sections = append(sections, models.SectionEducationHistory)
+	}
+	if form.FamilyMembers != nil || form.Guardians != nil || form.Siblings != nil || form.LivingSituation != nil {
+		sections = append(sections, models.SectionFamilyInfo)
+	}
+	if form.FinancialInfo != nil || form.Assets != nil || form.ScholarshipHistory != nil || form.HealthInfo != nil || form.FundingNeeds != nil {
+		sections = append(sections, models.SectionFinancialInfo)
+	}
+	if form.Activities != nil || form.References != nil {
+		sections = append(sections, models.SectionActivitiesSkills)
+	}
+	return sections
 }
 
 // GetCompleteForm retrieves all application details
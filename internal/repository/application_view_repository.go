@@ -0,0 +1,431 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+)
+
+type ApplicationViewRepository struct {
+	db *sql.DB
+}
+
+func NewApplicationViewRepository() *ApplicationViewRepository {
+	return &ApplicationViewRepository{
+		db: database.DB,
+	}
+}
+
+const applicationViewColumns = `
+	view_id, user_id, view_name, description, filter, sort_by, sort_desc, is_default, created_at, updated_at
+`
+
+// ListViews returns a user's saved views, the default first
+func (r *ApplicationViewRepository) ListViews(userID uuid.UUID) ([]models.ApplicationView, error) {
+	rows, err := r.db.Query(`
+		SELECT `+applicationViewColumns+` FROM application_views
+		WHERE user_id = $1
+		ORDER BY is_default DESC, view_name
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list views: %w", err)
+	}
+	defer rows.Close()
+
+	views := []models.ApplicationView{}
+	for rows.Next() {
+		view, err := scanApplicationView(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan view: %w", err)
+		}
+		views = append(views, *view)
+	}
+	return views, rows.Err()
+}
+
+// GetView returns a view of the user, or sql.ErrNoRows
+func (r *ApplicationViewRepository) GetView(viewID uint, userID uuid.UUID) (*models.ApplicationView, error) {
+	row := r.db.QueryRow(`
+		SELECT `+applicationViewColumns+` FROM application_views
+		WHERE view_id = $1 AND user_id = $2
+	`, viewID, userID)
+	return scanApplicationView(row)
+}
+
+// SaveView creates the view, or updates it when ViewID is set. Making a view
+// the default clears the user's previous default.
+func (r *ApplicationViewRepository) SaveView(view *models.ApplicationView) error {
+	filterJSON, err := json.Marshal(view.Filter)
+	if err != nil {
+		return fmt.Errorf("failed to encode filter: %w", err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if view.IsDefault {
+		if _, err := tx.Exec(`
+			UPDATE application_views SET is_default = FALSE
+			WHERE user_id = $1 AND is_default AND view_id <> $2
+		`, view.UserID, view.ViewID); err != nil {
+			return fmt.Errorf("failed to clear default view: %w", err)
+		}
+	}
+
+	var row *sql.Row
+	if view.ViewID == 0 {
+		row = tx.QueryRow(`
+			INSERT INTO application_views (user_id, view_name, description, filter, sort_by, sort_desc, is_default)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING `+applicationViewColumns,
+			view.UserID, view.ViewName, view.Description, filterJSON, view.SortBy, view.SortDesc, view.IsDefault,
+		)
+	} else {
+		row = tx.QueryRow(`
+			UPDATE application_views
+			SET view_name = $3, description = $4, filter = $5, sort_by = $6, sort_desc = $7,
+			    is_default = $8, updated_at = CURRENT_TIMESTAMP
+			WHERE view_id = $1 AND user_id = $2
+			RETURNING `+applicationViewColumns,
+			view.ViewID, view.UserID, view.ViewName, view.Description, filterJSON, view.SortBy, view.SortDesc, view.IsDefault,
+		)
+	}
+	saved, err := scanApplicationView(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return err
+		}
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return fmt.Errorf("a view named %q already exists", view.ViewName)
+		}
+		return fmt.Errorf("failed to save view: %w", err)
+	}
+	*view = *saved
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit view: %w", err)
+	}
+	return nil
+}
+
+// DeleteView deletes a view of the user, or returns sql.ErrNoRows
+func (r *ApplicationViewRepository) DeleteView(viewID uint, userID uuid.UUID) error {
+	result, err := r.db.Exec(`DELETE FROM application_views WHERE view_id = $1 AND user_id = $2`, viewID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete view: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func scanApplicationView(row interface{ Scan(...interface{}) error }) (*models.ApplicationView, error) {
+	view := &models.ApplicationView{}
+	var filterJSON []byte
+	err := row.Scan(
+		&view.ViewID, &view.UserID, &view.ViewName, &view.Description, &filterJSON,
+		&view.SortBy, &view.SortDesc, &view.IsDefault, &view.CreatedAt, &view.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(filterJSON, &view.Filter); err != nil {
+		return nil, fmt.Errorf("failed to decode filter: %w", err)
+	}
+	return view, nil
+}
+
+// applicationListFrom joins what the officer list shows and filters on:
+// document counts, the stage of the latest review and open red flags
+const applicationListFrom = `
+	FROM scholarship_applications sa
+	LEFT JOIN scholarships s ON s.scholarship_id = sa.scholarship_id
+	LEFT JOIN application_personal_info pi ON pi.application_id = sa.application_id
+	LEFT JOIN students st ON st.student_id = sa.student_id
+	LEFT JOIN LATERAL (
+		SELECT COUNT(*) AS total,
+		       COUNT(*) FILTER (WHERE d.upload_status = 'verified') AS verified,
+		       COUNT(*) FILTER (WHERE d.upload_status = 'pending') AS pending,
+		       COUNT(*) FILTER (WHERE d.upload_status = 'rejected') AS rejected
+		FROM application_documents d WHERE d.application_id = sa.application_id
+	) docs ON TRUE
+	LEFT JOIN LATERAL (
+		SELECT r.review_stage FROM application_reviews r
+		WHERE r.application_id = sa.application_id
+		ORDER BY r.created_at DESC LIMIT 1
+	) rv ON TRUE
+	LEFT JOIN LATERAL (
+		SELECT COUNT(*) AS open FROM application_red_flags f
+		WHERE f.application_id = sa.application_id AND f.status IN ('open', 'confirmed')
+	) rf ON TRUE
+`
+
+const applicationListColumns = `
+	sa.application_id, sa.student_id, concat_ws(' ', pi.first_name_th, pi.last_name_th),
+	sa.scholarship_id, COALESCE(s.name, ''), sa.application_status,
+	COALESCE(pi.faculty, st.faculty_code, ''), COALESCE(pi.year_level, st.year_level), st.gpa, sa.family_income,
+	docs.total, docs.verified, rv.review_stage, rf.open, sa.submitted_at
+`
+
+var applicationSortExpressions = map[string]string{
+	"submitted_at":       "sa.submitted_at",
+	"gpa":                "st.gpa",
+	"family_income":      "sa.family_income",
+	"year_level":         "COALESCE(pi.year_level, st.year_level)",
+	"student_id":         "sa.student_id",
+	"application_status": "sa.application_status",
+}
+
+// applicationFilterSQL builds the WHERE clause of a filter. Drafts are left
+// out unless the filter asks for them by status.
+func applicationFilterSQL(filter models.ApplicationFilter) (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if len(filter.Statuses) > 0 {
+		conditions = append(conditions, "sa.application_status = ANY("+arg(pq.Array(filter.Statuses))+")")
+	} else {
+		conditions = append(conditions, "sa.application_status <> 'draft'")
+	}
+	if len(filter.ScholarshipIDs) > 0 {
+		ids := make([]int64, len(filter.ScholarshipIDs))
+		for i, id := range filter.ScholarshipIDs {
+			ids[i] = int64(id)
+		}
+		conditions = append(conditions, "sa.scholarship_id = ANY("+arg(pq.Array(ids))+")")
+	}
+	if len(filter.Faculties) > 0 {
+		faculties := make([]string, len(filter.Faculties))
+		for i, faculty := range filter.Faculties {
+			faculties[i] = strings.ToLower(strings.TrimSpace(faculty))
+		}
+		p := arg(pq.Array(faculties))
+		conditions = append(conditions, fmt.Sprintf("(LOWER(pi.faculty) = ANY(%s) OR LOWER(st.faculty_code) = ANY(%s))", p, p))
+	}
+	if len(filter.YearLevels) > 0 {
+		years := make([]int64, len(filter.YearLevels))
+		for i, year := range filter.YearLevels {
+			years[i] = int64(year)
+		}
+		conditions = append(conditions, "COALESCE(pi.year_level, st.year_level) = ANY("+arg(pq.Array(years))+")")
+	}
+	if filter.GPAMin != nil {
+		conditions = append(conditions, "st.gpa >= "+arg(*filter.GPAMin))
+	}
+	if filter.GPAMax != nil {
+		conditions = append(conditions, "st.gpa <= "+arg(*filter.GPAMax))
+	}
+	if filter.IncomeMin != nil {
+		conditions = append(conditions, "sa.family_income >= "+arg(*filter.IncomeMin))
+	}
+	if filter.IncomeMax != nil {
+		conditions = append(conditions, "sa.family_income <= "+arg(*filter.IncomeMax))
+	}
+	switch filter.DocumentStatus {
+	case models.DocumentsAllVerified:
+		conditions = append(conditions, "docs.total > 0 AND docs.verified = docs.total")
+	case models.DocumentsPending:
+		conditions = append(conditions, "docs.pending > 0")
+	case models.DocumentsRejected:
+		conditions = append(conditions, "docs.rejected > 0")
+	case models.DocumentsMissing:
+		conditions = append(conditions, "docs.total = 0")
+	}
+	if len(filter.ReviewStages) > 0 {
+		conditions = append(conditions, "rv.review_stage = ANY("+arg(pq.Array(filter.ReviewStages))+")")
+	}
+	if filter.ReviewerID != nil {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM application_reviews r2
+			WHERE r2.application_id = sa.application_id AND r2.reviewer_id = `+arg(*filter.ReviewerID)+`)`)
+	}
+	if filter.HasRedFlags != nil {
+		if *filter.HasRedFlags {
+			conditions = append(conditions, "rf.open > 0")
+		} else {
+			conditions = append(conditions, "rf.open = 0")
+		}
+	}
+	if filter.SubmittedFrom != nil {
+		conditions = append(conditions, "sa.submitted_at >= "+arg(*filter.SubmittedFrom))
+	}
+	if filter.SubmittedTo != nil {
+		conditions = append(conditions, "sa.submitted_at <= "+arg(*filter.SubmittedTo))
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// QueryApplications returns a page of the officer list with the total count
+func (r *ApplicationViewRepository) QueryApplications(filter models.ApplicationFilter, sortBy string, sortDesc bool, limit, offset int) ([]models.ApplicationListItem, int, error) {
+	where, args := applicationFilterSQL(filter)
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) `+applicationListFrom+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count applications: %w", err)
+	}
+
+	order := applicationSortExpressions[sortBy]
+	if order == "" {
+		order = applicationSortExpressions["submitted_at"]
+	}
+	direction := "ASC"
+	if sortDesc {
+		direction = "DESC"
+	}
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`SELECT %s %s %s ORDER BY %s %s NULLS LAST, sa.application_id LIMIT $%d OFFSET $%d`,
+		applicationListColumns, applicationListFrom, where, order, direction, len(args)-1, len(args))
+
+	items, err := r.listItems(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// ListApplicationIDs returns the IDs of all applications matching a filter
+func (r *ApplicationViewRepository) ListApplicationIDs(filter models.ApplicationFilter) ([]uint, error) {
+	where, args := applicationFilterSQL(filter)
+	rows, err := r.db.Query(`SELECT sa.application_id `+applicationListFrom+where+` ORDER BY sa.application_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select applications: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []uint{}
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan application ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetListItems returns the list rows of the given applications
+func (r *ApplicationViewRepository) GetListItems(ids []uint) ([]models.ApplicationListItem, error) {
+	idList := make([]int64, len(ids))
+	for i, id := range ids {
+		idList[i] = int64(id)
+	}
+	return r.listItems(`SELECT `+applicationListColumns+applicationListFrom+
+		`WHERE sa.application_id = ANY($1) ORDER BY sa.application_id`, pq.Array(idList))
+}
+
+func (r *ApplicationViewRepository) listItems(query string, args ...interface{}) ([]models.ApplicationListItem, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applications: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.ApplicationListItem{}
+	for rows.Next() {
+		var item models.ApplicationListItem
+		err := rows.Scan(
+			&item.ApplicationID, &item.StudentID, &item.StudentName,
+			&item.ScholarshipID, &item.ScholarshipName, &item.ApplicationStatus,
+			&item.Faculty, &item.YearLevel, &item.GPA, &item.FamilyIncome,
+			&item.DocumentsTotal, &item.DocumentsVerified, &item.ReviewStage, &item.OpenRedFlags, &item.SubmittedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan application: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// GetStatus returns the status of an application
+func (r *ApplicationViewRepository) GetStatus(applicationID uint) (string, error) {
+	var status string
+	err := r.db.QueryRow(`SELECT application_status FROM scholarship_applications WHERE application_id = $1`, applicationID).Scan(&status)
+	return status, err
+}
+
+// ChangeStatus moves an application to a new status if it still has the
+// status the change was checked against. It reports whether it did.
+func (r *ApplicationViewRepository) ChangeStatus(applicationID uint, from, to string, changedBy uuid.UUID, notes string) (bool, error) {
+	var reviewNotes *string
+	if notes != "" {
+		reviewNotes = &notes
+	}
+	result, err := r.db.Exec(`
+		UPDATE scholarship_applications
+		SET application_status = $3, reviewed_by = $4, reviewed_at = NOW(),
+		    review_notes = COALESCE($5, review_notes), updated_at = NOW()
+		WHERE application_id = $1 AND application_status = $2
+	`, applicationID, from, to, changedBy, reviewNotes)
+	if err != nil {
+		return false, fmt.Errorf("failed to change status: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// AssignReviewer adds a pending review of the stage for the reviewer. It
+// reports false when the reviewer already has one.
+func (r *ApplicationViewRepository) AssignReviewer(applicationID uint, reviewerID uuid.UUID, stage string) (bool, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO application_reviews (application_id, reviewer_id, review_stage, review_status)
+		VALUES ($1, $2, $3, 'pending')
+		ON CONFLICT (application_id, reviewer_id, review_stage) DO NOTHING
+	`, applicationID, reviewerID, stage)
+	if err != nil {
+		return false, fmt.Errorf("failed to assign reviewer: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// IsStaff reports whether a user can review applications
+func (r *ApplicationViewRepository) IsStaff(userID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM user_roles ur
+			JOIN roles ro ON ro.role_id = ur.role_id
+			WHERE ur.user_id = $1 AND ur.is_active
+			  AND ro.role_name IN ('admin', 'scholarship_officer', 'interviewer', 'committee_member')
+		)
+	`, userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check reviewer: %w", err)
+	}
+	return exists, nil
+}
+
+// NotifyApplicant sends an in-app notification to the student of an
+// application. It reports false when the student has no user account.
+func (r *ApplicationViewRepository) NotifyApplicant(applicationID uint, notificationType, title, message string) (bool, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO notifications (user_id, notification_type, title, message, reference_id, reference_type, priority)
+		SELECT COALESCE(s.user_id, u.user_id), $2, $3, $4, sa.application_id::text, 'application', 'normal'
+		FROM scholarship_applications sa
+		LEFT JOIN students s ON sa.student_id = s.student_id
+		LEFT JOIN users u ON sa.student_id = u.email
+		WHERE sa.application_id = $1 AND COALESCE(s.user_id, u.user_id) IS NOT NULL
+	`, applicationID, notificationType, title, message)
+	if err != nil {
+		return false, fmt.Errorf("failed to notify applicant: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+)
+
+type DocumentRequirementRepository struct {
+	db *sql.DB
+}
+
+func NewDocumentRequirementRepository() *DocumentRequirementRepository {
+	return &DocumentRequirementRepository{
+		db: database.DB,
+	}
+}
+
+// GetForApplication returns the scholarship of an application and the
+// document requirements it sets, nil when none are configured. It returns
+// sql.ErrNoRows when the application does not exist.
+func (r *DocumentRequirementRepository) GetForApplication(applicationID uint) (uint, []models.DocumentRequirement, error) {
+	var scholarshipID uint
+	var requirementsJSON []byte
+	err := r.db.QueryRow(`
+		SELECT s.scholarship_id, s.document_requirements
+		FROM scholarship_applications sa
+		JOIN scholarships s ON s.scholarship_id = sa.scholarship_id
+		WHERE sa.application_id = $1
+	`, applicationID).Scan(&scholarshipID, &requirementsJSON)
+	if err != nil {
+		return 0, nil, err
+	}
+	requirements, err := decodeDocumentRequirements(requirementsJSON)
+	return scholarshipID, requirements, err
+}
+
+// GetForScholarship returns the document requirements of a scholarship, nil
+// when none are configured
+func (r *DocumentRequirementRepository) GetForScholarship(scholarshipID uint) ([]models.DocumentRequirement, error) {
+	var requirementsJSON []byte
+	err := r.db.QueryRow(`SELECT document_requirements FROM scholarships WHERE scholarship_id = $1`, scholarshipID).Scan(&requirementsJSON)
+	if err != nil {
+		return nil, err
+	}
+	return decodeDocumentRequirements(requirementsJSON)
+}
+
+func decodeDocumentRequirements(data []byte) ([]models.DocumentRequirement, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	var requirements []models.DocumentRequirement
+	if err := json.Unmarshal(data, &requirements); err != nil {
+		return nil, fmt.Errorf("failed to decode document requirements: %w", err)
+	}
+	return requirements, nil
+}
+
+func encodeDocumentRequirements(requirements []models.DocumentRequirement) (interface{}, error) {
+	if len(requirements) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(requirements)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode document requirements: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+)
+
+type EligibilityRepository struct {
+	db *sql.DB
+}
+
+func NewEligibilityRepository() *EligibilityRepository {
+	return &EligibilityRepository{
+		db: database.DB,
+	}
+}
+
+// GetRules returns the structured eligibility rules of a scholarship, or nil
+// when none are configured
+func (r *EligibilityRepository) GetRules(scholarshipID uint) (*models.EligibilityRules, error) {
+	var rulesJSON []byte
+	err := r.db.QueryRow(`SELECT eligibility_rules FROM scholarships WHERE scholarship_id = $1`, scholarshipID).Scan(&rulesJSON)
+	if err != nil {
+		return nil, err
+	}
+	return decodeEligibilityRules(rulesJSON)
+}
+
+// GetProfile builds the eligibility profile of a student from the students
+// table, the family income of their most recent application and their
+// scholarship allocations. A student without a students row yields a profile
+// with only StudentID and PriorAwards set.
+func (r *EligibilityRepository) GetProfile(studentID string) (*models.EligibilityProfile, error) {
+	profile := &models.EligibilityProfile{StudentID: studentID}
+
+	var gpa sql.NullFloat64
+	var yearLevel sql.NullInt64
+	var facultyCode, studentStatus sql.NullString
+	err := r.db.QueryRow(`
+		SELECT gpa, year_level, faculty_code, student_status
+		FROM students WHERE student_id = $1
+	`, studentID).Scan(&gpa, &yearLevel, &facultyCode, &studentStatus)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get student: %w", err)
+	}
+	if gpa.Valid {
+		profile.GPA = &gpa.Float64
+	}
+	if yearLevel.Valid {
+		year := int(yearLevel.Int64)
+		profile.YearLevel = &year
+	}
+	if facultyCode.Valid {
+		profile.FacultyCode = &facultyCode.String
+	}
+	if studentStatus.Valid {
+		profile.StudentStatus = &studentStatus.String
+	}
+
+	income, err := r.GetLatestFamilyIncome(studentID)
+	if err != nil {
+		return nil, err
+	}
+	profile.FamilyIncome = income
+
+	awards, err := r.GetPriorAwards(studentID)
+	if err != nil {
+		return nil, err
+	}
+	profile.PriorAwards = awards
+
+	return profile, nil
+}
+
+// GetLatestFamilyIncome returns the monthly family income from the student's
+// most recently updated application: the declared family_income if set,
+// otherwise the sum of family member incomes.
+func (r *EligibilityRepository) GetLatestFamilyIncome(studentID string) (*float64, error) {
+	var income sql.NullFloat64
+	err := r.db.QueryRow(`
+		SELECT COALESCE(sa.family_income,
+		       (SELECT SUM(fm.monthly_income) FROM application_family_members fm
+		        WHERE fm.application_id = sa.application_id))
+		FROM scholarship_applications sa
+		WHERE sa.student_id = $1
+		ORDER BY sa.updated_at DESC
+		LIMIT 1
+	`, studentID).Scan(&income)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get family income: %w", err)
+	}
+	if !income.Valid {
+		return nil, nil
+	}
+	return &income.Float64, nil
+}
+
+// GetPriorAwards lists scholarship allocations the student has received
+func (r *EligibilityRepository) GetPriorAwards(studentID string) ([]models.PriorAward, error) {
+	rows, err := r.db.Query(`
+		SELECT al.allocation_id, sa.application_id, s.scholarship_id, s.name AS scholarship_name, s.type, s.academic_year,
+		       al.allocated_amount, COALESCE(al.allocation_status, '')
+		FROM scholarship_allocations al
+		JOIN scholarship_applications sa ON al.application_id = sa.application_id
+		JOIN scholarships s ON al.scholarship_id = s.scholarship_id
+		WHERE sa.student_id = $1
+		ORDER BY al.allocation_date DESC
+	`, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prior awards: %w", err)
+	}
+	defer rows.Close()
+
+	awards := []models.PriorAward{}
+	for rows.Next() {
+		var award models.PriorAward
+		if err := rows.Scan(
+			&award.AllocationID, &award.ApplicationID, &award.ScholarshipID, &award.ScholarshipName, &award.ScholarshipType, &award.AcademicYear,
+			&award.Amount, &award.Status,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan prior award: %w", err)
+		}
+		award.Active = isActiveAllocationStatus(award.Status)
+		awards = append(awards, award)
+	}
+	return awards, rows.Err()
+}
+
+// isActiveAllocationStatus reports whether an allocation still counts as a
+// current award
+func isActiveAllocationStatus(status string) bool {
+	switch status {
+	case "cancelled", "rejected", "declined", "completed", "terminated":
+		return false
+	}
+	return true
+}
+
+func decodeEligibilityRules(data []byte) (*models.EligibilityRules, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	var rules models.EligibilityRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to decode eligibility rules: %w", err)
+	}
+	return &rules, nil
+}
+
+func encodeEligibilityRules(rules *models.EligibilityRules) (interface{}, error) {
+	if rules == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode eligibility rules: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPriorAwardsAgainstSchema runs the prior award and prefill queries
+// against a migrated database, where scholarships has name and type columns
+func TestPriorAwardsAgainstSchema(t *testing.T) {
+	db := openTestDB(t)
+	tag := "pa" + uuid.NewString()[:8]
+	scholarshipID, applicationID := seedAwardedStudent(t, db, tag)
+
+	repo := &EligibilityRepository{db: db}
+	profile, err := repo.GetProfile(tag)
+	require.NoError(t, err)
+	require.Len(t, profile.PriorAwards, 1)
+	award := profile.PriorAwards[0]
+	assert.Equal(t, uint(scholarshipID), award.ScholarshipID)
+	assert.Equal(t, uint(applicationID), award.ApplicationID)
+	assert.Equal(t, "Prior award "+tag, award.ScholarshipName)
+	assert.Equal(t, "merit", award.ScholarshipType)
+	assert.Equal(t, 15000.0, award.Amount)
+	assert.True(t, award.Active)
+
+	candidates, err := (&PrefillRepository{db: db}).ListCandidates(tag)
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+	assert.Equal(t, "Prior award "+tag, candidates[0].ScholarshipName)
+}
+
+// seedAwardedStudent creates a student with a submitted application and an
+// allocation for it, all keyed by tag
+func seedAwardedStudent(t *testing.T, db *sql.DB, tag string) (int, int) {
+	t.Helper()
+	var userID uuid.UUID
+	require.NoError(t, db.QueryRow(`
+		INSERT INTO users (username, email, first_name, last_name)
+		VALUES ($1, $1 || '@example.com', 'Prior', 'Award')
+		RETURNING user_id`, tag).Scan(&userID))
+	t.Cleanup(func() {
+		if _, err := db.Exec(`DELETE FROM users WHERE user_id = $1`, userID); err != nil {
+			t.Logf("cleanup: %v", err)
+		}
+	})
+
+	var scholarshipID int
+	require.NoError(t, db.QueryRow(`
+		INSERT INTO scholarships (name, type, amount, total_quota, available_quota,
+			academic_year, application_start_date, application_end_date)
+		VALUES ($1, 'merit', 15000, 5, 5, '2024', CURRENT_DATE - 60, CURRENT_DATE - 30)
+		RETURNING scholarship_id`, "Prior award "+tag).Scan(&scholarshipID))
+	t.Cleanup(func() {
+		for _, query := range []string{
+			`DELETE FROM scholarship_allocations WHERE scholarship_id = $1`,
+			`DELETE FROM scholarship_applications WHERE scholarship_id = $1`,
+			`DELETE FROM scholarships WHERE scholarship_id = $1`,
+		} {
+			if _, err := db.Exec(query, scholarshipID); err != nil {
+				t.Logf("cleanup: %v", err)
+			}
+		}
+	})
+
+	_, err := db.Exec(`INSERT INTO students (student_id, user_id, gpa, year_level) VALUES ($1, $2, 3.5, 2)`, tag, userID)
+	require.NoError(t, err)
+
+	var applicationID int
+	require.NoError(t, db.QueryRow(`
+		INSERT INTO scholarship_applications (student_id, scholarship_id, application_status, submitted_at)
+		VALUES ($1, $2, 'approved', NOW() - INTERVAL '40 days')
+		RETURNING application_id`, tag, scholarshipID).Scan(&applicationID))
+	_, err = db.Exec(`
+		INSERT INTO scholarship_allocations (application_id, scholarship_id, allocated_amount, allocation_status, allocation_date)
+		VALUES ($1, $2, 15000, 'approved', CURRENT_DATE - 20)`, applicationID, scholarshipID)
+	require.NoError(t, err)
+	return scholarshipID, applicationID
+}
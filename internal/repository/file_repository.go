@@ -2,8 +2,11 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"scholarship-system/internal/models"
 )
 
@@ -97,62 +100,215 @@ func (r *FileRepository) DeleteFile(id uuid.UUID) error {
 
 // CreateFileVersion creates a file version
 func (r *FileRepository) CreateFileVersion(version *models.FileVersion) error {
+	return createFileVersion(r.db, version)
+}
+
+func createFileVersion(db sqlRunner, version *models.FileVersion) error {
+	if version.VersionID == uuid.Nil {
+		version.VersionID = uuid.New()
+	}
 	query := `
 		INSERT INTO document_versions (
 			version_id, file_id, version_number, change_description,
-			uploaded_by, file_size, is_current
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+			uploaded_by, file_size, is_current, created_at, replaced_at,
+			document_id, document_name, file_path, storage_backend, mime_type,
+			file_hash, upload_status, verification_notes, verified_by, verified_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8, CURRENT_TIMESTAMP), $9,
+			$10, NULLIF($11, ''), NULLIF($12, ''), NULLIF($13, ''), NULLIF($14, ''),
+			NULLIF($15, ''), NULLIF($16, ''), $17, $18, $19)
 		RETURNING created_at`
 
-	return r.db.QueryRow(
+	var createdAt *time.Time
+	if !version.CreatedAt.IsZero() {
+		createdAt = &version.CreatedAt
+	}
+	return db.QueryRow(
 		query,
 		version.VersionID, version.FileID, version.VersionNumber, version.ChangeDescription,
-		version.UploadedBy, version.FileSize, version.IsCurrent,
+		version.UploadedBy, version.FileSize, version.IsCurrent, createdAt, version.ReplacedAt,
+		version.DocumentID, version.DocumentName, version.FilePath, version.StorageBackend, version.MimeType,
+		version.FileHash, version.UploadStatus, version.VerificationNotes, version.VerifiedBy, version.VerifiedAt,
 	).Scan(&version.CreatedAt)
 }
 
+const fileVersionColumns = `
+	version_id, file_id, version_number, change_description,
+	uploaded_by, file_size, COALESCE(is_current, false), created_at, replaced_at,
+	document_id, COALESCE(document_name, ''), COALESCE(file_path, ''), COALESCE(storage_backend, ''),
+	COALESCE(mime_type, ''), COALESCE(file_hash, ''), COALESCE(upload_status, ''),
+	verification_notes, verified_by, verified_at`
+
 // GetFileVersions retrieves all versions of a file
 func (r *FileRepository) GetFileVersions(fileID uuid.UUID) ([]models.FileVersion, error) {
-	query := `
-		SELECT version_id, file_id, version_number, change_description,
-			   uploaded_by, file_size, is_current, created_at, replaced_at
+	return r.listVersions(`SELECT `+fileVersionColumns+`
 		FROM document_versions
 		WHERE file_id = $1
-		ORDER BY version_number DESC`
+		ORDER BY version_number DESC`, fileID)
+}
 
-	rows, err := r.db.Query(query, fileID)
+// GetDocumentVersions retrieves the earlier versions of an application
+// document, newest first
+func (r *FileRepository) GetDocumentVersions(documentID int) ([]models.FileVersion, error) {
+	return r.listVersions(`SELECT `+fileVersionColumns+`
+		FROM document_versions
+		WHERE document_id = $1
+		ORDER BY version_number DESC`, documentID)
+}
+
+// GetDocumentVersion retrieves one earlier version of an application document
+func (r *FileRepository) GetDocumentVersion(documentID, versionNumber int) (*models.FileVersion, error) {
+	versions, err := r.listVersions(`SELECT `+fileVersionColumns+`
+		FROM document_versions
+		WHERE document_id = $1 AND version_number = $2`, documentID, versionNumber)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &versions[0], nil
+}
+
+func (r *FileRepository) listVersions(query string, args ...interface{}) ([]models.FileVersion, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var versions []models.FileVersion
+	versions := []models.FileVersion{}
 	for rows.Next() {
 		var v models.FileVersion
 		err := rows.Scan(
 			&v.VersionID, &v.FileID, &v.VersionNumber, &v.ChangeDescription,
 			&v.UploadedBy, &v.FileSize, &v.IsCurrent, &v.CreatedAt, &v.ReplacedAt,
+			&v.DocumentID, &v.DocumentName, &v.FilePath, &v.StorageBackend,
+			&v.MimeType, &v.FileHash, &v.UploadStatus,
+			&v.VerificationNotes, &v.VerifiedBy, &v.VerifiedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
 		versions = append(versions, v)
 	}
-	return versions, nil
+	return versions, rows.Err()
+}
+
+// FindCurrentDocument returns the newest document of a type attached to an
+// application, or sql.ErrNoRows when there is none
+func (r *FileRepository) FindCurrentDocument(applicationID int, documentType string) (int, error) {
+	var documentID int
+	err := r.db.QueryRow(`
+		SELECT document_id FROM application_documents
+		WHERE application_id = $1 AND document_type = $2
+		ORDER BY uploaded_at DESC, document_id DESC
+		LIMIT 1`, applicationID, documentType).Scan(&documentID)
+	return documentID, err
+}
+
+// CountDocuments counts the documents of a type attached to an application
+func (r *FileRepository) CountDocuments(applicationID int, documentType string) (int, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM application_documents
+		WHERE application_id = $1 AND document_type = $2
+	`, applicationID, documentType).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count documents: %w", err)
+	}
+	return count, nil
+}
+
+// SetIssuedDate records when the current version of a document was issued
+func (r *FileRepository) SetIssuedDate(documentID int, issued *time.Time) error {
+	result, err := r.db.Exec(`UPDATE application_documents SET issued_date = $2 WHERE document_id = $1`, documentID, issued)
+	if err != nil {
+		return fmt.Errorf("failed to set issue date: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ReplaceDocument makes doc the new current version of an application
+// document of the same application and type. The replaced file is kept as an
+// earlier version together with its verification, and the document goes
+// back to doc.UploadStatus unverified. doc is filled in with the document's
+// ID and new version number; the archived version is returned.
+func (r *FileRepository) ReplaceDocument(documentID int, doc *models.ApplicationDocument, uploadedBy *uuid.UUID) (*models.FileVersion, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	previous := &models.FileVersion{DocumentID: &documentID}
+	err = tx.QueryRow(`
+		SELECT version_number, document_name, file_path,
+		       storage_backend, COALESCE(file_size, 0), COALESCE(mime_type, ''), COALESCE(file_hash, ''),
+		       upload_status, verification_notes, verified_by, verified_at, uploaded_at, uploaded_by
+		FROM application_documents
+		WHERE document_id = $1 AND application_id = $2 AND document_type = $3
+		FOR UPDATE`, documentID, doc.ApplicationID, doc.DocumentType).Scan(
+		&previous.VersionNumber, &previous.DocumentName, &previous.FilePath,
+		&previous.StorageBackend, &previous.FileSize, &previous.MimeType, &previous.FileHash,
+		&previous.UploadStatus, &previous.VerificationNotes, &previous.VerifiedBy, &previous.VerifiedAt, &previous.CreatedAt, &previous.UploadedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	previous.ReplacedAt = &now
+	if err := createFileVersion(tx, previous); err != nil {
+		return nil, fmt.Errorf("failed to save document version: %w", err)
+	}
+
+	doc.DocumentID = documentID
+	doc.VersionNumber = previous.VersionNumber + 1
+	doc.UploadedAt = now
+	_, err = tx.Exec(`
+		UPDATE application_documents
+		SET document_name = $2, file_path = $3, storage_backend = $4, file_size = $5, mime_type = $6,
+		    file_hash = NULLIF($7, ''), upload_status = $8, uploaded_at = $9, uploaded_by = $10,
+		    version_number = $11, verification_notes = NULL, verified_by = NULL, verified_at = NULL,
+		    scanned_at = NULL, scan_result = NULL, issued_date = $12,
+		    page_count = NULL, image_width = NULL, image_height = NULL,
+		    preview_status = NULL, preview_generated_at = NULL
+		WHERE document_id = $1`,
+		documentID, doc.DocumentName, doc.FilePath, doc.StorageBackend, doc.FileSize, doc.MimeType,
+		doc.FileHash, doc.UploadStatus, doc.UploadedAt, uploadedBy, doc.VersionNumber, doc.IssuedDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replace document: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit document version: %w", err)
+	}
+	return previous, nil
 }
 
 // LogFileAccess logs file access
 func (r *FileRepository) LogFileAccess(log *models.FileAccessLog) error {
+	if log.AccessID == uuid.Nil {
+		log.AccessID = uuid.New()
+	}
+	if log.AccessTime.IsZero() {
+		log.AccessTime = time.Now()
+	}
+
 	query := `
 		INSERT INTO file_access_logs (
 			access_id, file_id, user_id, access_time, action,
-			ip_address, user_agent, success
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+			ip_address, user_agent, success,
+			document_id, application_id, document_type, version_number, access_method
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
 
 	_, err := r.db.Exec(
 		query,
 		log.AccessID, log.FileID, log.UserID, log.AccessTime, log.Action,
 		log.IPAddress, log.UserAgent, log.Success,
+		log.DocumentID, log.ApplicationID, nullString(log.DocumentType), log.VersionNumber, nullString(log.AccessMethod),
 	)
 	return err
 }
@@ -160,11 +316,10 @@ func (r *FileRepository) LogFileAccess(log *models.FileAccessLog) error {
 // GetFileAccessLogs retrieves access logs for a file
 func (r *FileRepository) GetFileAccessLogs(fileID uuid.UUID, limit int) ([]models.FileAccessLog, error) {
 	query := `
-		SELECT access_id, file_id, user_id, access_time, action,
-			   ip_address, user_agent, success
-		FROM file_access_logs
-		WHERE file_id = $1
-		ORDER BY access_time DESC
+		SELECT ` + fileAccessColumns + `
+		FROM file_access_logs l
+		WHERE l.file_id = $1
+		ORDER BY l.access_time DESC
 		LIMIT $2`
 
 	rows, err := r.db.Query(query, fileID, limit)
@@ -176,14 +331,88 @@ func (r *FileRepository) GetFileAccessLogs(fileID uuid.UUID, limit int) ([]model
 	var logs []models.FileAccessLog
 	for rows.Next() {
 		var l models.FileAccessLog
-		err := rows.Scan(
-			&l.AccessID, &l.FileID, &l.UserID, &l.AccessTime, &l.Action,
-			&l.IPAddress, &l.UserAgent, &l.Success,
-		)
-		if err != nil {
+		if err := rows.Scan(fileAccessFields(&l)...); err != nil {
 			return nil, err
 		}
 		logs = append(logs, l)
 	}
 	return logs, nil
 }
+
+// nullString stores an empty string as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+const fileAccessColumns = `l.access_id, l.file_id, l.user_id, l.access_time, l.action,
+			   host(l.ip_address), l.user_agent, COALESCE(l.success, true),
+			   l.document_id, l.application_id, COALESCE(l.document_type, ''),
+			   l.version_number, COALESCE(l.access_method, '')`
+
+func fileAccessFields(l *models.FileAccessLog) []interface{} {
+	return []interface{}{
+		&l.AccessID, &l.FileID, &l.UserID, &l.AccessTime, &l.Action,
+		&l.IPAddress, &l.UserAgent, &l.Success,
+		&l.DocumentID, &l.ApplicationID, &l.DocumentType,
+		&l.VersionNumber, &l.AccessMethod,
+	}
+}
+
+// ListDocumentAccess lists who viewed or downloaded the documents of an
+// application, newest first
+func (r *FileRepository) ListDocumentAccess(applicationID int, filter models.DocumentAccessFilter) ([]models.DocumentAccessEntry, int, error) {
+	where := "WHERE l.application_id = $1"
+	args := []interface{}{applicationID}
+	if filter.DocumentID > 0 {
+		args = append(args, filter.DocumentID)
+		where += fmt.Sprintf(" AND l.document_id = $%d", len(args))
+	}
+	if filter.DocumentType != "" {
+		args = append(args, filter.DocumentType)
+		where += fmt.Sprintf(" AND l.document_type = $%d", len(args))
+	}
+	if filter.SensitiveOnly {
+		args = append(args, pq.Array(models.SensitiveDocumentTypes))
+		where += fmt.Sprintf(" AND l.document_type = ANY($%d)", len(args))
+	}
+
+	var total int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM file_access_logs l "+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count document access: %w", err)
+	}
+
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	query := fmt.Sprintf(`
+		SELECT `+fileAccessColumns+`,
+			   TRIM(COALESCE(u.first_name, '') || ' ' || COALESCE(u.last_name, '')), COALESCE(u.email, ''),
+			   COALESCE(ARRAY(
+				   SELECT ro.role_name FROM user_roles ur
+				   JOIN roles ro ON ro.role_id = ur.role_id
+				   WHERE ur.user_id = l.user_id AND ur.is_active
+				   ORDER BY ro.role_name
+			   ), '{}')
+		FROM file_access_logs l
+		LEFT JOIN users u ON u.user_id = l.user_id
+		%s
+		ORDER BY l.access_time DESC
+		LIMIT $%d OFFSET $%d`, where, len(args)-1, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list document access: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.DocumentAccessEntry{}
+	for rows.Next() {
+		var e models.DocumentAccessEntry
+		fields := append(fileAccessFields(&e.FileAccessLog), &e.UserName, &e.UserEmail, pq.Array(&e.UserRoles))
+		if err := rows.Scan(fields...); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan document access: %w", err)
+		}
+		e.Sensitive = models.IsSensitiveDocument(e.DocumentType)
+		entries = append(entries, e)
+	}
+	return entries, total, rows.Err()
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"scholarship-system/internal/models"
+)
+
+// VersionConflictError is returned by a save that was based on an outdated
+// version of a section. Section is empty for the whole form.
+type VersionConflictError struct {
+	Section        string
+	CurrentVersion int
+}
+
+func (e *VersionConflictError) Error() string {
+	if e.Section == "" {
+		return fmt.Sprintf("application form was modified (current version %d)", e.CurrentVersion)
+	}
+	return fmt.Sprintf("section %s was modified (current version %d)", e.Section, e.CurrentVersion)
+}
+
+// FormVersions are the current edit counters of an application form
+type FormVersions struct {
+	FormVersion int            `json:"form_version"`
+	Sections    map[string]int `json:"sections"`
+}
+
+// GetFormVersions returns the form version and the version of every section
+func (r *ApplicationDetailsRepository) GetFormVersions(applicationID uint) (*FormVersions, error) {
+	versions := &FormVersions{Sections: map[string]int{}}
+	err := r.db.QueryRow(`SELECT form_version FROM scholarship_applications WHERE application_id = $1`, applicationID).
+		Scan(&versions.FormVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, section := range models.ApplicationSections {
+		versions.Sections[section] = 0
+	}
+	rows, err := r.db.Query(`SELECT section_name, version FROM application_section_versions WHERE application_id = $1`, applicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get section versions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var section string
+		var version int
+		if err := rows.Scan(&section, &version); err != nil {
+			return nil, fmt.Errorf("failed to scan section version: %w", err)
+		}
+		versions.Sections[section] = version
+	}
+	return versions, rows.Err()
+}
+
+// claimVersion serialises saves of one application and bumps the version of
+// the section being saved. The application row is locked with FOR NO KEY
+// UPDATE so concurrent saves queue behind each other while inserts into
+// child tables (which only take a key share lock) are not blocked. When
+// expected is set and differs from the stored version a
+// *VersionConflictError is returned and nothing is changed.
+func (r *ApplicationDetailsRepository) claimVersion(tx *sql.Tx, applicationID uint, section string, expected *int) (int, error) {
+	return r.claimSections(tx, applicationID, section, []string{section}, expected)
+}
+
+// claimFormVersion is claimVersion for a save of the whole form. expected is
+// compared with the form version and only the given sections, the ones the
+// save actually writes, are bumped so edits of other sections don't conflict
+func (r *ApplicationDetailsRepository) claimFormVersion(tx *sql.Tx, applicationID uint, sections []string, expected *int) (int, error) {
+	return r.claimSections(tx, applicationID, "", sections, expected)
+}
+
+// claimSections checks expected against the version of section, or the form
+// version when section is empty, and bumps the form and the given sections
+func (r *ApplicationDetailsRepository) claimSections(tx *sql.Tx, applicationID uint, section string, sections []string, expected *int) (int, error) {
+	var formVersion int
+	err := tx.QueryRow(`
+		SELECT form_version FROM scholarship_applications
+		WHERE application_id = $1 FOR NO KEY UPDATE
+	`, applicationID).Scan(&formVersion)
+	if err != nil {
+		return 0, err
+	}
+
+	current := formVersion
+	if section != "" {
+		err = tx.QueryRow(`
+			SELECT version FROM application_section_versions
+			WHERE application_id = $1 AND section_name = $2
+		`, applicationID, section).Scan(&current)
+		if err == sql.ErrNoRows {
+			current = 0
+		} else if err != nil {
+			return 0, fmt.Errorf("failed to get section version: %w", err)
+		}
+	}
+
+	if expected != nil && *expected != current {
+		return 0, &VersionConflictError{Section: section, CurrentVersion: current}
+	}
+
+	if _, err := tx.Exec(`UPDATE scholarship_applications SET form_version = form_version + 1 WHERE application_id = $1`, applicationID); err != nil {
+		return 0, fmt.Errorf("failed to update form version: %w", err)
+	}
+
+	now := time.Now()
+	for _, name := range sections {
+		var version int
+		err := tx.QueryRow(`
+			INSERT INTO application_section_versions (application_id, section_name, version, updated_at)
+			VALUES ($1, $2, 1, $3)
+			ON CONFLICT (application_id, section_name)
+			DO UPDATE SET version = application_section_versions.version + 1, updated_at = $3
+			RETURNING version
+		`, applicationID, name, now).Scan(&version)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update section version: %w", err)
+		}
+		if name == section {
+			current = version
+		}
+	}
+
+	if section == "" {
+		return formVersion + 1, nil
+	}
+	return current, nil
+}
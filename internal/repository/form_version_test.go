@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"scholarship-system/internal/models"
+)
+
+func TestCompleteFormSectionsOnlyListsPresentParts(t *testing.T) {
+	assert.Empty(t, completeFormSections(&models.CompleteApplicationForm{}))
+
+	form := &models.CompleteApplicationForm{
+		PersonalInfo: &models.ApplicationPersonalInfo{},
+		Guardians:    []models.ApplicationGuardian{},
+	}
+	assert.Equal(t, []string{models.SectionPersonalInfo, models.SectionFamilyInfo}, completeFormSections(form))
+}
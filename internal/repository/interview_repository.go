@@ -0,0 +1,483 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+)
+
+var (
+	// ErrSlotUnavailable is returned when a slot is closed or full
+	ErrSlotUnavailable = errors.New("interview slot is not available")
+	// ErrSlotScholarship is returned when a slot belongs to another
+	// scholarship than the application
+	ErrSlotScholarship = errors.New("interview slot belongs to another scholarship")
+	// ErrAlreadyBooked is returned when an application already holds an
+	// active booking
+	ErrAlreadyBooked = errors.New("application already has an active interview booking")
+	// ErrBookingCancelled is returned when cancelling a cancelled booking
+	ErrBookingCancelled = errors.New("interview booking is already cancelled")
+	// ErrBookingClosed is returned when moving a booking that is cancelled or
+	// whose interview has been held
+	ErrBookingClosed = errors.New("interview booking is no longer active")
+)
+
+// InterviewRepository keeps interview slots, bookings and results. The
+// legacy interview_schedules, interview_appointments and interview_results
+// tables were copied here by migration 052 and are no longer written.
+type InterviewRepository struct {
+	db *sql.DB
+}
+
+func NewInterviewRepository() *InterviewRepository {
+	return &InterviewRepository{
+		db: database.DB,
+	}
+}
+
+const interviewSlotColumns = `id, scholarship_id, interviewer_id, interview_date, start_time, end_time,
+	COALESCE(location, ''), COALESCE(building, ''), COALESCE(floor, ''), COALESCE(room, ''),
+	max_capacity, current_bookings, is_available, COALESCE(slot_type, 'individual'),
+	COALESCE(duration_minutes, 30), COALESCE(preparation_time, 0), COALESCE(notes, ''),
+	created_by, created_at, updated_at`
+
+func scanInterviewSlot(row interface{ Scan(...interface{}) error }) (*models.InterviewSlot, error) {
+	var slot models.InterviewSlot
+	err := row.Scan(&slot.ID, &slot.ScholarshipID, &slot.InterviewerID, &slot.InterviewDate, &slot.StartTime, &slot.EndTime,
+		&slot.Location, &slot.Building, &slot.Floor, &slot.Room,
+		&slot.MaxCapacity, &slot.CurrentBookings, &slot.IsAvailable, &slot.SlotType,
+		&slot.DurationMinutes, &slot.PreparationTime, &slot.Notes,
+		&slot.CreatedBy, &slot.CreatedAt, &slot.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &slot, nil
+}
+
+// CreateSlot inserts a slot and fills in its ID and timestamps
+func (r *InterviewRepository) CreateSlot(slot *models.InterviewSlot) error {
+	err := r.db.QueryRow(`
+		INSERT INTO interview_slots (
+			scholarship_id, interviewer_id, interview_date, start_time, end_time, location,
+			max_capacity, is_available, slot_type, duration_minutes, notes, created_by
+		) VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, $10, NULLIF($11, ''), $12)
+		RETURNING id, current_bookings, created_at, updated_at`,
+		slot.ScholarshipID, slot.InterviewerID, slot.InterviewDate, slot.StartTime, slot.EndTime, slot.Location,
+		slot.MaxCapacity, slot.IsAvailable, slot.SlotType, slot.DurationMinutes, slot.Notes, slot.CreatedBy,
+	).Scan(&slot.ID, &slot.CurrentBookings, &slot.CreatedAt, &slot.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create interview slot: %w", err)
+	}
+	return nil
+}
+
+// ListOpenSlots returns the slots open for booking, of one scholarship when
+// scholarshipID is not 0, in date order
+func (r *InterviewRepository) ListOpenSlots(scholarshipID int) ([]models.InterviewSlot, error) {
+	query := `SELECT ` + interviewSlotColumns + ` FROM interview_slots WHERE is_available = TRUE`
+	args := []interface{}{}
+	if scholarshipID != 0 {
+		query += ` AND scholarship_id = $1`
+		args = append(args, scholarshipID)
+	}
+	query += ` ORDER BY interview_date, start_time, id`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interview slots: %w", err)
+	}
+	defer rows.Close()
+
+	slots := []models.InterviewSlot{}
+	for rows.Next() {
+		slot, err := scanInterviewSlot(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan interview slot: %w", err)
+		}
+		slots = append(slots, *slot)
+	}
+	return slots, rows.Err()
+}
+
+// BookSlot books a seat in a slot for an application and counts it on the
+// slot in one transaction. The seat is taken with a conditional update, so
+// concurrent bookings queue on the slot row and the last seat goes to one of
+// them; idx_interview_bookings_one_active keeps an application to one active
+// booking. It returns sql.ErrNoRows when the slot or application does not
+// exist.
+func (r *InterviewRepository) BookSlot(slotID, applicationID int, studentID uuid.UUID, notes string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var bookingID int
+	err = tx.QueryRow(`
+		INSERT INTO interview_bookings (slot_id, application_id, student_id, student_notes)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		RETURNING id`,
+		slotID, applicationID, studentID, notes).Scan(&bookingID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code {
+			case "23505":
+				return 0, ErrAlreadyBooked
+			case "23503":
+				return 0, sql.ErrNoRows
+			}
+		}
+		return 0, fmt.Errorf("failed to create interview booking: %w", err)
+	}
+
+	if err := takeSeat(tx, slotID, applicationID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit interview booking: %w", err)
+	}
+	return bookingID, nil
+}
+
+// takeSeat counts a booking of an application on a slot when the slot is
+// open, has room and belongs to the application's scholarship. The row lock
+// of the update holds the seat until the transaction ends.
+func takeSeat(tx *sql.Tx, slotID, applicationID int) error {
+	result, err := tx.Exec(`
+		UPDATE interview_slots s
+		SET current_bookings = s.current_bookings + 1, updated_at = NOW()
+		FROM scholarship_applications sa
+		WHERE s.id = $1 AND sa.application_id = $2 AND s.scholarship_id = sa.scholarship_id
+		  AND s.is_available AND s.current_bookings < s.max_capacity`,
+		slotID, applicationID)
+	if err != nil {
+		return fmt.Errorf("failed to count interview booking: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	var sameScholarship bool
+	err = tx.QueryRow(`
+		SELECT s.scholarship_id = sa.scholarship_id
+		FROM interview_slots s, scholarship_applications sa
+		WHERE s.id = $1 AND sa.application_id = $2`,
+		slotID, applicationID).Scan(&sameScholarship)
+	if err != nil {
+		return err
+	}
+	if !sameScholarship {
+		return ErrSlotScholarship
+	}
+	return ErrSlotUnavailable
+}
+
+// releaseSeat uncounts a booking from a slot
+func releaseSeat(tx *sql.Tx, slotID int) error {
+	_, err := tx.Exec(`
+		UPDATE interview_slots
+		SET current_bookings = GREATEST(current_bookings - 1, 0), updated_at = NOW()
+		WHERE id = $1`, slotID)
+	if err != nil {
+		return fmt.Errorf("failed to release interview slot: %w", err)
+	}
+	return nil
+}
+
+// CancelBooking cancels a booking and frees its seat in one transaction. It
+// returns sql.ErrNoRows when the booking does not exist.
+func (r *InterviewRepository) CancelBooking(bookingID int, reason string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var slotID int
+	var status string
+	err = tx.QueryRow(`SELECT slot_id, booking_status FROM interview_bookings WHERE id = $1 FOR UPDATE`, bookingID).Scan(&slotID, &status)
+	if err != nil {
+		return err
+	}
+	if status == "cancelled" {
+		return ErrBookingCancelled
+	}
+
+	_, err = tx.Exec(`
+		UPDATE interview_bookings
+		SET booking_status = 'cancelled', cancelled_at = NOW(), cancellation_reason = NULLIF($2, ''), updated_at = NOW()
+		WHERE id = $1`, bookingID, reason)
+	if err != nil {
+		return fmt.Errorf("failed to cancel interview booking: %w", err)
+	}
+	if err := releaseSeat(tx, slotID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit interview cancellation: %w", err)
+	}
+	return nil
+}
+
+// RescheduleBooking moves an active booking to another slot, taking a seat
+// there and freeing the old one in one transaction. Both slots are locked in
+// ID order so opposite moves between two slots cannot deadlock. It returns
+// sql.ErrNoRows when the booking or the new slot does not exist.
+func (r *InterviewRepository) RescheduleBooking(bookingID, newSlotID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var oldSlotID, applicationID int
+	var status string
+	err = tx.QueryRow(`
+		SELECT slot_id, application_id, booking_status FROM interview_bookings
+		WHERE id = $1 FOR UPDATE`, bookingID).Scan(&oldSlotID, &applicationID, &status)
+	if err != nil {
+		return err
+	}
+	if status != "booked" && status != "confirmed" {
+		return ErrBookingClosed
+	}
+	if oldSlotID == newSlotID {
+		return nil
+	}
+
+	rows, err := tx.Query(`SELECT id FROM interview_slots WHERE id IN ($1, $2) ORDER BY id FOR NO KEY UPDATE`, oldSlotID, newSlotID)
+	if err != nil {
+		return fmt.Errorf("failed to lock interview slots: %w", err)
+	}
+	rows.Close()
+
+	if err := takeSeat(tx, newSlotID, applicationID); err != nil {
+		return err
+	}
+	if err := releaseSeat(tx, oldSlotID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE interview_bookings
+		SET slot_id = $2, rescheduled_from_slot_id = $3, rescheduled_to_slot_id = $2,
+		    booking_status = 'booked', confirmed_at = NULL, updated_at = NOW()
+		WHERE id = $1`, bookingID, newSlotID, oldSlotID)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule interview booking: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit interview reschedule: %w", err)
+	}
+	return nil
+}
+
+// ConfirmStudentBooking confirms a booked interview on behalf of the student
+// who owns its application. It returns false when the booking is not theirs
+// or is no longer active.
+func (r *InterviewRepository) ConfirmStudentBooking(bookingID int, userID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE interview_bookings b
+		SET booking_status = 'confirmed', confirmed_at = COALESCE(b.confirmed_at, NOW()), updated_at = NOW()
+		FROM scholarship_applications sa
+		JOIN students st ON st.student_id = sa.student_id
+		WHERE b.id = $1 AND sa.application_id = b.application_id AND st.user_id = $2
+		  AND b.booking_status IN ('booked', 'confirmed')`,
+		bookingID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to confirm interview booking: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// SubmitResult records the outcome of an interview, with a score per
+// criterion when given, and marks the booking completed. It fills in
+// result.ResultID and result.CreatedAt and returns sql.ErrNoRows when the
+// booking does not exist.
+func (r *InterviewRepository) SubmitResult(bookingID int, result *models.InterviewResult, scores map[string]float64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	if err := tx.QueryRow(`SELECT id FROM interview_bookings WHERE id = $1 FOR UPDATE`, bookingID).Scan(&id); err != nil {
+		return err
+	}
+
+	recommendation := "pending"
+	if result.Recommendation != nil && *result.Recommendation != "" {
+		recommendation = *result.Recommendation
+	}
+	feedback := []string{}
+	for _, text := range []*string{result.Comments, result.InterviewNotes} {
+		if text != nil && *text != "" {
+			feedback = append(feedback, *text)
+		}
+	}
+
+	var resultID int
+	err = tx.QueryRow(`
+		INSERT INTO interview_results_summary (
+			booking_id, total_score, max_possible_score, score_percentage, recommendation,
+			interviewer_feedback, submitted_by, submitted_at
+		) VALUES ($1, $2, 100, $2, $3, NULLIF($4, ''), $5, NOW())
+		RETURNING id, created_at`,
+		bookingID, *result.OverallScore, recommendation, strings.Join(feedback, "\n\n"), result.InterviewerID,
+	).Scan(&resultID, &result.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save interview result: %w", err)
+	}
+	result.ResultID = uint(resultID)
+
+	criteria := make([]string, 0, len(scores))
+	for name := range scores {
+		criteria = append(criteria, name)
+	}
+	sort.Strings(criteria)
+	for _, name := range criteria {
+		_, err = tx.Exec(`
+			INSERT INTO interview_scores_detailed (booking_id, interviewer_id, criteria_name, score)
+			VALUES ($1, $2, $3, $4)`,
+			bookingID, result.InterviewerID, name, scores[name])
+		if err != nil {
+			return fmt.Errorf("failed to save interview score: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE interview_bookings
+		SET booking_status = 'completed', check_out_time = COALESCE(check_out_time, NOW()), updated_at = NOW()
+		WHERE id = $1`, bookingID)
+	if err != nil {
+		return fmt.Errorf("failed to complete interview booking: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit interview result: %w", err)
+	}
+	return nil
+}
+
+const interviewAppointmentSelect = `
+	SELECT b.id, b.application_id, b.slot_id, b.booking_status, b.confirmed_at,
+	       s.interview_date, s.start_time, s.end_time, COALESCE(s.location, ''),
+	       sc.name, u.first_name || ' ' || u.last_name
+	FROM interview_bookings b
+	JOIN interview_slots s ON s.id = b.slot_id
+	JOIN scholarships sc ON sc.scholarship_id = s.scholarship_id
+	JOIN scholarship_applications sa ON sa.application_id = b.application_id
+	JOIN students st ON st.student_id = sa.student_id
+	JOIN users u ON u.user_id = st.user_id`
+
+// ListStudentAppointments returns the bookings of a student's applications
+func (r *InterviewRepository) ListStudentAppointments(userID uuid.UUID) ([]models.InterviewAppointmentView, error) {
+	return r.listAppointments(interviewAppointmentSelect+`
+		WHERE st.user_id = $1
+		ORDER BY s.interview_date, s.start_time`, userID, false)
+}
+
+// ListInterviewerAppointments returns the bookings in an interviewer's slots
+func (r *InterviewRepository) ListInterviewerAppointments(userID uuid.UUID) ([]models.InterviewAppointmentView, error) {
+	return r.listAppointments(interviewAppointmentSelect+`
+		WHERE s.interviewer_id = $1
+		ORDER BY s.interview_date, s.start_time`, userID, true)
+}
+
+func (r *InterviewRepository) listAppointments(query string, userID uuid.UUID, withStudent bool) ([]models.InterviewAppointmentView, error) {
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interview appointments: %w", err)
+	}
+	defer rows.Close()
+
+	appointments := []models.InterviewAppointmentView{}
+	for rows.Next() {
+		var item models.InterviewAppointmentView
+		var studentName string
+		err := rows.Scan(&item.AppointmentID, &item.ApplicationID, &item.ScheduleID, &item.Status, &item.ConfirmationDate,
+			&item.InterviewDate, &item.StartTime, &item.EndTime, &item.Location,
+			&item.ScholarshipName, &studentName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan interview appointment: %w", err)
+		}
+		item.Confirmed = item.ConfirmationDate != nil
+		if withStudent {
+			item.StudentName = studentName
+			item.ConfirmationDate = nil
+		}
+		appointments = append(appointments, item)
+	}
+	return appointments, rows.Err()
+}
+
+// Statistics counts slots and their bookings
+func (r *InterviewRepository) Statistics(filter models.InterviewStatsFilter) (*models.InterviewBookingStats, error) {
+	conditions := []string{"TRUE"}
+	args := []interface{}{}
+	if filter.DateFrom != "" {
+		args = append(args, filter.DateFrom)
+		conditions = append(conditions, fmt.Sprintf("s.interview_date >= $%d", len(args)))
+	}
+	if filter.DateTo != "" {
+		args = append(args, filter.DateTo)
+		conditions = append(conditions, fmt.Sprintf("s.interview_date <= $%d", len(args)))
+	}
+	if filter.ScholarshipID != 0 {
+		args = append(args, filter.ScholarshipID)
+		conditions = append(conditions, fmt.Sprintf("s.scholarship_id = $%d", len(args)))
+	}
+
+	var stats models.InterviewBookingStats
+	var avgDuration sql.NullFloat64
+	err := r.db.QueryRow(`
+		SELECT
+			COUNT(DISTINCT s.id),
+			COUNT(DISTINCT s.id) FILTER (WHERE s.is_available),
+			COUNT(b.id),
+			COUNT(b.id) FILTER (WHERE b.booking_status = 'booked'),
+			COUNT(b.id) FILTER (WHERE b.booking_status = 'confirmed'),
+			COUNT(b.id) FILTER (WHERE b.booking_status = 'cancelled'),
+			COUNT(b.id) FILTER (WHERE b.booking_status = 'completed'),
+			COUNT(b.id) FILTER (WHERE b.booking_status = 'no_show'),
+			COUNT(b.id) FILTER (WHERE b.check_in_time IS NOT NULL),
+			AVG(b.actual_duration_minutes)
+		FROM interview_slots s
+		LEFT JOIN interview_bookings b ON b.slot_id = s.id
+		WHERE `+strings.Join(conditions, " AND "), args...).Scan(
+		&stats.TotalSlots, &stats.AvailableSlots, &stats.TotalBookings,
+		&stats.BookedBookings, &stats.ConfirmedBookings, &stats.CancelledBookings,
+		&stats.CompletedBookings, &stats.NoShowBookings, &stats.CheckedIn, &avgDuration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count interviews: %w", err)
+	}
+
+	stats.PendingBookings = stats.BookedBookings + stats.ConfirmedBookings
+	if avgDuration.Valid {
+		stats.AvgDuration = avgDuration.Float64
+	}
+	if stats.TotalSlots > 0 {
+		stats.UtilizationRate = float64(stats.TotalBookings) / float64(stats.TotalSlots) * 100
+	}
+	return &stats, nil
+}
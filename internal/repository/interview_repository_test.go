@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scholarship-system/internal/models"
+)
+
+// TestInterviewBookingUnderLoad runs hundreds of concurrent bookings,
+// reschedules and cancellations against the database in TEST_DATABASE_URL.
+// It creates its own scholarship, students and slots and removes them
+// afterwards.
+func TestInterviewBookingUnderLoad(t *testing.T) {
+	db := openTestDB(t)
+	db.SetMaxOpenConns(50)
+
+	repo := &InterviewRepository{db: db}
+	scholarshipID, applicants := seedInterviewApplicants(t, db, 300)
+	slotA := createTestSlot(t, repo, scholarshipID, applicants[0].user, 5)
+	slotB := createTestSlot(t, repo, scholarshipID, applicants[0].user, 3)
+
+	// Every applicant books the same slot twice at once
+	type outcome struct {
+		bookingID int
+		err       error
+	}
+	outcomes := make(chan outcome, 2*len(applicants))
+	runConcurrently(2*len(applicants), func(i int) {
+		applicant := applicants[i/2]
+		bookingID, err := repo.BookSlot(slotA, applicant.applicationID, applicant.user, "")
+		outcomes <- outcome{bookingID, err}
+	})
+	close(outcomes)
+
+	booked := []int{}
+	for result := range outcomes {
+		if result.err == nil {
+			booked = append(booked, result.bookingID)
+			continue
+		}
+		if !errors.Is(result.err, ErrSlotUnavailable) && !errors.Is(result.err, ErrAlreadyBooked) {
+			t.Errorf("unexpected booking error: %v", result.err)
+		}
+	}
+	assert.Len(t, booked, 5)
+	assert.Equal(t, 5, slotSeats(t, db, slotA, 5))
+	assertOneActiveBooking(t, db, scholarshipID)
+
+	// The booked applicants move to the smaller slot while others rush for
+	// the seats they free
+	var mu sync.Mutex
+	moved := 0
+	runConcurrently(len(booked)+100, func(i int) {
+		if i < len(booked) {
+			err := repo.RescheduleBooking(booked[i], slotB)
+			if err == nil {
+				mu.Lock()
+				moved++
+				mu.Unlock()
+			} else if !errors.Is(err, ErrSlotUnavailable) {
+				t.Errorf("unexpected reschedule error: %v", err)
+			}
+			return
+		}
+		applicant := applicants[len(applicants)-1-i]
+		if _, err := repo.BookSlot(slotA, applicant.applicationID, applicant.user, ""); err != nil &&
+			!errors.Is(err, ErrSlotUnavailable) && !errors.Is(err, ErrAlreadyBooked) {
+			t.Errorf("unexpected booking error: %v", err)
+		}
+	})
+	assert.Equal(t, 3, moved)
+	assert.Equal(t, 3, slotSeats(t, db, slotB, 3))
+	slotSeats(t, db, slotA, 5)
+	assertOneActiveBooking(t, db, scholarshipID)
+
+	// Cancelling every booking twice at once frees each seat exactly once
+	rows, err := db.Query(`
+		SELECT b.id FROM interview_bookings b
+		WHERE b.slot_id IN ($1, $2) AND b.booking_status <> 'cancelled'`, slotA, slotB)
+	require.NoError(t, err)
+	active := []int{}
+	for rows.Next() {
+		var id int
+		require.NoError(t, rows.Scan(&id))
+		active = append(active, id)
+	}
+	rows.Close()
+
+	cancelled := 0
+	runConcurrently(2*len(active), func(i int) {
+		err := repo.CancelBooking(active[i/2], "load test")
+		if err == nil {
+			mu.Lock()
+			cancelled++
+			mu.Unlock()
+		} else if !errors.Is(err, ErrBookingCancelled) {
+			t.Errorf("unexpected cancel error: %v", err)
+		}
+	})
+	assert.Equal(t, len(active), cancelled)
+	assert.Equal(t, 0, slotSeats(t, db, slotA, 5))
+	assert.Equal(t, 0, slotSeats(t, db, slotB, 3))
+}
+
+type interviewApplicant struct {
+	user          uuid.UUID
+	applicationID int
+}
+
+// runConcurrently starts n goroutines that call fn together and waits for
+// them
+func runConcurrently(n int, fn func(i int)) {
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			fn(i)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+}
+
+// slotSeats checks a slot's seat count matches its bookings and capacity
+func slotSeats(t *testing.T, db *sql.DB, slotID, capacity int) int {
+	t.Helper()
+	var seats, bookings int
+	require.NoError(t, db.QueryRow(`
+		SELECT s.current_bookings,
+		       (SELECT COUNT(*) FROM interview_bookings b WHERE b.slot_id = s.id AND b.booking_status <> 'cancelled')
+		FROM interview_slots s WHERE s.id = $1`, slotID).Scan(&seats, &bookings))
+	assert.Equal(t, bookings, seats, "slot %d seat count", slotID)
+	assert.LessOrEqual(t, bookings, capacity, "slot %d is overbooked", slotID)
+	return bookings
+}
+
+func assertOneActiveBooking(t *testing.T, db *sql.DB, scholarshipID int) {
+	t.Helper()
+	var duplicated int
+	require.NoError(t, db.QueryRow(`
+		SELECT COUNT(*) FROM (
+			SELECT b.application_id FROM interview_bookings b
+			JOIN interview_slots s ON s.id = b.slot_id
+			WHERE s.scholarship_id = $1 AND b.booking_status IN ('booked', 'confirmed')
+			GROUP BY b.application_id HAVING COUNT(*) > 1
+		) duplicates`, scholarshipID).Scan(&duplicated))
+	assert.Zero(t, duplicated, "applications with more than one active booking")
+}
+
+func createTestSlot(t *testing.T, repo *InterviewRepository, scholarshipID int, interviewer uuid.UUID, capacity int) int {
+	t.Helper()
+	slot := models.InterviewSlot{
+		ScholarshipID:   scholarshipID,
+		InterviewerID:   interviewer.String(),
+		InterviewDate:   time.Now().AddDate(0, 0, 7),
+		StartTime:       "09:00",
+		EndTime:         "10:00",
+		MaxCapacity:     capacity,
+		IsAvailable:     true,
+		SlotType:        "group",
+		DurationMinutes: 60,
+		CreatedBy:       interviewer.String(),
+	}
+	require.NoError(t, repo.CreateSlot(&slot))
+	return slot.ID
+}
+
+// seedInterviewApplicants creates a scholarship and n students with a
+// submitted application each
+func seedInterviewApplicants(t *testing.T, db *sql.DB, n int) (int, []interviewApplicant) {
+	t.Helper()
+	tag := "lt" + uuid.NewString()[:8]
+
+	var scholarshipID int
+	require.NoError(t, db.QueryRow(`
+		INSERT INTO scholarships (name, type, amount, total_quota, available_quota,
+			academic_year, application_start_date, application_end_date, interview_required)
+		VALUES ($1, 'merit', 10000, 10, 10, '2025', CURRENT_DATE, CURRENT_DATE + 30, TRUE)
+		RETURNING scholarship_id`, "Interview load test "+tag).Scan(&scholarshipID))
+
+	t.Cleanup(func() {
+		for _, query := range []string{
+			`DELETE FROM interview_slots WHERE scholarship_id = $1`,
+			`DELETE FROM scholarship_applications WHERE scholarship_id = $1`,
+			`DELETE FROM scholarships WHERE scholarship_id = $1`,
+		} {
+			if _, err := db.Exec(query, scholarshipID); err != nil {
+				t.Logf("cleanup: %v", err)
+			}
+		}
+		if _, err := db.Exec(`DELETE FROM users WHERE email LIKE $1 || '-%@example.com'`, tag); err != nil {
+			t.Logf("cleanup: %v", err)
+		}
+	})
+
+	_, err := db.Exec(`
+		INSERT INTO users (username, email, first_name, last_name)
+		SELECT $1 || '-' || n, $1 || '-' || n || '@example.com', 'Load', 'Test ' || n
+		FROM generate_series(1, $2) n`, tag, n)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		INSERT INTO students (student_id, user_id)
+		SELECT username, user_id FROM users WHERE email LIKE $1 || '-%@example.com'`, tag)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		INSERT INTO scholarship_applications (student_id, scholarship_id, application_status, submitted_at)
+		SELECT student_id, $2, 'submitted', NOW() FROM students WHERE student_id LIKE $1 || '-%'`,
+		tag, scholarshipID)
+	require.NoError(t, err)
+
+	rows, err := db.Query(`
+		SELECT st.user_id, sa.application_id
+		FROM scholarship_applications sa
+		JOIN students st ON st.student_id = sa.student_id
+		WHERE sa.scholarship_id = $1
+		ORDER BY sa.application_id`, scholarshipID)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	applicants := []interviewApplicant{}
+	for rows.Next() {
+		var applicant interviewApplicant
+		require.NoError(t, rows.Scan(&applicant.user, &applicant.applicationID))
+		applicants = append(applicants, applicant)
+	}
+	require.NoError(t, rows.Err())
+	require.Len(t, applicants, n)
+	return scholarshipID, applicants
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+)
+
+type JobRepository struct {
+	db *sql.DB
+}
+
+func NewJobRepository() *JobRepository {
+	return &JobRepository{
+		db: database.DB,
+	}
+}
+
+const jobColumns = `
+	job_id, job_type, payload, priority, status, attempts, max_attempts,
+	scheduled_at, started_at, completed_at, error_message, result, created_by, created_at,
+	progress_done, progress_total
+`
+
+// Enqueue adds a pending job. payload is marshalled to JSON; nil becomes {}.
+func (r *JobRepository) Enqueue(jobType string, payload interface{}, createdBy *uuid.UUID) (*models.JobQueue, error) {
+	if payload == nil {
+		payload = map[string]interface{}{}
+	}
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	row := r.db.QueryRow(`
+		INSERT INTO job_queue (job_type, payload, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING `+jobColumns,
+		jobType, payloadJSON, createdBy,
+	)
+	job, err := scanJob(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	return job, nil
+}
+
+// HasActive reports whether a job of the type is pending or running
+func (r *JobRepository) HasActive(jobType string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM job_queue WHERE job_type = $1 AND status IN ('pending', 'running')
+		)
+	`, jobType).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check jobs: %w", err)
+	}
+	return exists, nil
+}
+
+// Claim marks the next due job of one of the given types as running and
+// returns it, or returns nil when there is none. SKIP LOCKED lets several
+// workers poll the same queue.
+func (r *JobRepository) Claim(jobTypes []string) (*models.JobQueue, error) {
+	row := r.db.QueryRow(`
+		UPDATE job_queue
+		SET status = 'running', started_at = NOW(), attempts = attempts + 1
+		WHERE job_id = (
+			SELECT job_id FROM job_queue
+			WHERE status = 'pending' AND scheduled_at <= NOW() AND job_type = ANY($1)
+			ORDER BY priority DESC, scheduled_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING `+jobColumns,
+		pq.Array(jobTypes),
+	)
+	job, err := scanJob(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return job, nil
+}
+
+// Complete stores the result of a finished job
+func (r *JobRepository) Complete(jobID uuid.UUID, result interface{}) error {
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode job result: %w", err)
+	}
+	_, err = r.db.Exec(`
+		UPDATE job_queue
+		SET status = 'completed', completed_at = NOW(), result = $2, error_message = NULL
+		WHERE job_id = $1
+	`, jobID, resultJSON)
+	if err != nil {
+		return fmt.Errorf("failed to complete job: %w", err)
+	}
+	return nil
+}
+
+// Fail records a failed attempt. The job is retried after retryAfter until it
+// has used its attempts, then it is marked failed.
+func (r *JobRepository) Fail(jobID uuid.UUID, message string, retryAfter time.Duration) error {
+	_, err := r.db.Exec(`
+		UPDATE job_queue
+		SET status = CASE WHEN attempts < max_attempts THEN 'pending' ELSE 'failed' END,
+		    scheduled_at = CASE WHEN attempts < max_attempts THEN NOW() + make_interval(secs => $3) ELSE scheduled_at END,
+		    completed_at = CASE WHEN attempts < max_attempts THEN NULL ELSE NOW() END,
+		    error_message = $2
+		WHERE job_id = $1
+	`, jobID, message, retryAfter.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to record job failure: %w", err)
+	}
+	return nil
+}
+
+// SetProgress records how many of a job's items are done
+func (r *JobRepository) SetProgress(jobID uuid.UUID, done, total int) error {
+	_, err := r.db.Exec(`
+		UPDATE job_queue SET progress_done = $2, progress_total = $3 WHERE job_id = $1
+	`, jobID, done, total)
+	if err != nil {
+		return fmt.Errorf("failed to update job progress: %w", err)
+	}
+	return nil
+}
+
+// RequeueStale puts running jobs back in the queue when they were started
+// before the cutoff, e.g. by a worker that was stopped mid-job
+func (r *JobRepository) RequeueStale(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec(`
+		UPDATE job_queue
+		SET status = CASE WHEN attempts < max_attempts THEN 'pending' ELSE 'failed' END,
+		    error_message = 'worker stopped while the job was running'
+		WHERE status = 'running' AND started_at < $1
+	`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue stale jobs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// GetByID returns a job
+func (r *JobRepository) GetByID(jobID uuid.UUID) (*models.JobQueue, error) {
+	row := r.db.QueryRow(`SELECT `+jobColumns+` FROM job_queue WHERE job_id = $1`, jobID)
+	return scanJob(row)
+}
+
+// List returns the latest jobs, optionally of one type
+func (r *JobRepository) List(jobType string, limit int) ([]models.JobQueue, error) {
+	return r.list(`WHERE ($1 = '' OR job_type = $1)`, limit, jobType)
+}
+
+// ListByCreator returns the latest jobs of a type queued by a user
+func (r *JobRepository) ListByCreator(jobType string, createdBy uuid.UUID, limit int) ([]models.JobQueue, error) {
+	return r.list(`WHERE job_type = $1 AND created_by = $2`, limit, jobType, createdBy)
+}
+
+func (r *JobRepository) list(where string, limit int, args ...interface{}) ([]models.JobQueue, error) {
+	args = append(args, limit)
+	rows, err := r.db.Query(`
+		SELECT `+jobColumns+` FROM job_queue
+		`+where+`
+		ORDER BY scheduled_at DESC
+		LIMIT `+fmt.Sprintf("$%d", len(args)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []models.JobQueue{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+func scanJob(row interface{ Scan(...interface{}) error }) (*models.JobQueue, error) {
+	job := &models.JobQueue{}
+	var payload, result []byte
+	err := row.Scan(
+		&job.JobID, &job.JobType, &payload, &job.Priority, &job.Status, &job.Attempts, &job.MaxAttempts,
+		&job.ScheduledAt, &job.StartedAt, &job.CompletedAt, &job.ErrorMessage, &result, &job.CreatedBy, &job.CreatedAt,
+		&job.ProgressDone, &job.ProgressTotal,
+	)
+	if err != nil {
+		return nil, err
+	}
+	job.Payload = payload
+	job.Result = result
+	return job, nil
+}
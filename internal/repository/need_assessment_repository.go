@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+)
+
+type NeedAssessmentRepository struct {
+	db *sql.DB
+}
+
+func NewNeedAssessmentRepository() *NeedAssessmentRepository {
+	return &NeedAssessmentRepository{
+		db: database.DB,
+	}
+}
+
+// GetActiveParameters returns the parameter set in use. Until one is saved
+// the built-in defaults are returned with ParameterSetID 0.
+func (r *NeedAssessmentRepository) GetActiveParameters() (*models.NeedParameterSet, error) {
+	set := &models.NeedParameterSet{Parameters: models.DefaultNeedParameters(), IsActive: true}
+
+	var paramsJSON []byte
+	err := r.db.QueryRow(`
+		SELECT parameter_set_id, parameters, created_by, created_at
+		FROM need_assessment_parameters
+		WHERE is_active
+	`).Scan(&set.ParameterSetID, &paramsJSON, &set.CreatedBy, &set.CreatedAt)
+	if err == sql.ErrNoRows {
+		return set, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get need assessment parameters: %w", err)
+	}
+
+	// Decode over the defaults so parameters added later get a sensible value
+	if err := json.Unmarshal(paramsJSON, &set.Parameters); err != nil {
+		return nil, fmt.Errorf("failed to decode need assessment parameters: %w", err)
+	}
+	return set, nil
+}
+
+// SaveParameters stores a new parameter set and makes it the active one.
+// Earlier sets are kept so stored assessments can be traced to their formula.
+func (r *NeedAssessmentRepository) SaveParameters(params models.NeedParameters, createdBy uuid.UUID) (*models.NeedParameterSet, error) {
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode need assessment parameters: %w", err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE need_assessment_parameters SET is_active = FALSE WHERE is_active`); err != nil {
+		return nil, fmt.Errorf("failed to deactivate need assessment parameters: %w", err)
+	}
+
+	set := &models.NeedParameterSet{Parameters: params, IsActive: true, CreatedBy: &createdBy}
+	err = tx.QueryRow(`
+		INSERT INTO need_assessment_parameters (parameters, is_active, created_by)
+		VALUES ($1, TRUE, $2)
+		RETURNING parameter_set_id, created_at
+	`, paramsJSON, createdBy).Scan(&set.ParameterSetID, &set.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save need assessment parameters: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return set, nil
+}
+
+// SaveAssessment stores the assessment on the application
+func (r *NeedAssessmentRepository) SaveAssessment(assessment *models.NeedAssessment) error {
+	assessmentJSON, err := json.Marshal(assessment)
+	if err != nil {
+		return fmt.Errorf("failed to encode need assessment: %w", err)
+	}
+
+	_, err = r.db.Exec(`
+		UPDATE scholarship_applications
+		SET need_assessment = $2, need_score = $3, per_capita_income = $4, need_assessed_at = $5
+		WHERE application_id = $1
+	`, assessment.ApplicationID, assessmentJSON, assessment.NeedScore, assessment.PerCapitaIncome, assessment.AssessedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save need assessment: %w", err)
+	}
+	return nil
+}
+
+// GetAssessment returns the stored assessment of an application, or
+// sql.ErrNoRows when it has not been assessed yet
+func (r *NeedAssessmentRepository) GetAssessment(applicationID uint) (*models.NeedAssessment, error) {
+	var assessmentJSON []byte
+	err := r.db.QueryRow(`SELECT need_assessment FROM scholarship_applications WHERE application_id = $1`, applicationID).
+		Scan(&assessmentJSON)
+	if err != nil {
+		return nil, err
+	}
+	if assessmentJSON == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	var assessment models.NeedAssessment
+	if err := json.Unmarshal(assessmentJSON, &assessment); err != nil {
+		return nil, fmt.Errorf("failed to decode need assessment: %w", err)
+	}
+	return &assessment, nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+)
+
+type PrefillRepository struct {
+	db *sql.DB
+}
+
+func NewPrefillRepository() *PrefillRepository {
+	return &PrefillRepository{
+		db: database.DB,
+	}
+}
+
+// PrefillCandidate is a previous application of a student that a new draft
+// can start from
+type PrefillCandidate struct {
+	ApplicationID     uint       `json:"application_id"`
+	ScholarshipID     uint       `json:"scholarship_id"`
+	ScholarshipName   string     `json:"scholarship_name"`
+	AcademicYear      string     `json:"academic_year"`
+	ApplicationStatus string     `json:"application_status"`
+	SubmittedAt       *time.Time `json:"submitted_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// RecordedAt is when the data of the application was last entered
+func (c *PrefillCandidate) RecordedAt() time.Time {
+	if c.SubmittedAt != nil {
+		return *c.SubmittedAt
+	}
+	return c.UpdatedAt
+}
+
+// ListCandidates returns the student's submitted applications, newest first
+func (r *PrefillRepository) ListCandidates(studentID string) ([]PrefillCandidate, error) {
+	rows, err := r.db.Query(`
+		SELECT a.application_id, a.scholarship_id, s.name AS scholarship_name, s.academic_year,
+		       a.application_status, a.submitted_at, a.updated_at
+		FROM scholarship_applications a
+		JOIN scholarships s ON s.scholarship_id = a.scholarship_id
+		WHERE a.student_id = $1 AND a.application_status <> 'draft'
+		ORDER BY COALESCE(a.submitted_at, a.updated_at) DESC
+	`, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list previous applications: %w", err)
+	}
+	defer rows.Close()
+
+	candidates := []PrefillCandidate{}
+	for rows.Next() {
+		var candidate PrefillCandidate
+		err := rows.Scan(
+			&candidate.ApplicationID, &candidate.ScholarshipID, &candidate.ScholarshipName, &candidate.AcademicYear,
+			&candidate.ApplicationStatus, &candidate.SubmittedAt, &candidate.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan previous application: %w", err)
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, rows.Err()
+}
+
+// Save stores the prefill record of a draft, replacing an earlier one
+func (r *PrefillRepository) Save(prefill *models.ApplicationPrefill) error {
+	staleJSON, err := json.Marshal(prefill.StaleFields)
+	if err != nil {
+		return fmt.Errorf("failed to encode stale fields: %w", err)
+	}
+	versionsJSON, err := json.Marshal(prefill.SectionVersions)
+	if err != nil {
+		return fmt.Errorf("failed to encode section versions: %w", err)
+	}
+
+	err = r.db.QueryRow(`
+		INSERT INTO application_prefills (
+			application_id, source_application_id, copied_sections, stale_fields,
+			section_versions, awards_added, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (application_id) DO UPDATE
+		SET source_application_id = EXCLUDED.source_application_id,
+		    copied_sections = EXCLUDED.copied_sections,
+		    stale_fields = EXCLUDED.stale_fields,
+		    section_versions = EXCLUDED.section_versions,
+		    awards_added = EXCLUDED.awards_added,
+		    created_by = EXCLUDED.created_by,
+		    created_at = CURRENT_TIMESTAMP
+		RETURNING created_at
+	`, prefill.ApplicationID, prefill.SourceApplicationID, pq.Array(prefill.CopiedSections), staleJSON,
+		versionsJSON, prefill.AwardsAdded, prefill.CreatedBy,
+	).Scan(&prefill.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save prefill: %w", err)
+	}
+	return nil
+}
+
+// Get returns the prefill record of an application, or sql.ErrNoRows when
+// it was not started from a previous application
+func (r *PrefillRepository) Get(applicationID uint) (*models.ApplicationPrefill, error) {
+	prefill := &models.ApplicationPrefill{}
+	var staleJSON, versionsJSON []byte
+	var sections pq.StringArray
+	err := r.db.QueryRow(`
+		SELECT application_id, source_application_id, copied_sections, stale_fields,
+		       section_versions, awards_added, created_by, created_at
+		FROM application_prefills
+		WHERE application_id = $1
+	`, applicationID).Scan(
+		&prefill.ApplicationID, &prefill.SourceApplicationID, &sections, &staleJSON,
+		&versionsJSON, &prefill.AwardsAdded, &prefill.CreatedBy, &prefill.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	prefill.CopiedSections = sections
+	if err := json.Unmarshal(staleJSON, &prefill.StaleFields); err != nil {
+		return nil, fmt.Errorf("failed to decode stale fields: %w", err)
+	}
+	if err := json.Unmarshal(versionsJSON, &prefill.SectionVersions); err != nil {
+		return nil, fmt.Errorf("failed to decode section versions: %w", err)
+	}
+	return prefill, nil
+}
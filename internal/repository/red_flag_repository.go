@@ -0,0 +1,352 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+)
+
+type RedFlagRepository struct {
+	db *sql.DB
+}
+
+func NewRedFlagRepository() *RedFlagRepository {
+	return &RedFlagRepository{
+		db: database.DB,
+	}
+}
+
+// scannedApplications limits the detection job to applications that were
+// submitted and are still in the running
+const scannedApplications = `a.application_status NOT IN ('draft', 'withdrawn', 'declined')`
+
+// ListUnhashedDocuments returns the documents of scanned applications whose
+// file hash has not been computed yet
+func (r *RedFlagRepository) ListUnhashedDocuments() ([]models.ApplicationDocument, error) {
+	rows, err := r.db.Query(`
+		SELECT d.document_id, d.application_id, d.file_path, d.storage_backend
+		FROM application_documents d
+		JOIN scholarship_applications a ON a.application_id = d.application_id
+		WHERE d.file_hash IS NULL AND COALESCE(d.file_path, '') <> '' AND ` + scannedApplications)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	defer rows.Close()
+
+	documents := []models.ApplicationDocument{}
+	for rows.Next() {
+		var doc models.ApplicationDocument
+		if err := rows.Scan(&doc.DocumentID, &doc.ApplicationID, &doc.FilePath, &doc.StorageBackend); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		documents = append(documents, doc)
+	}
+	return documents, rows.Err()
+}
+
+// SetDocumentHash stores the SHA-256 of a document's file
+func (r *RedFlagRepository) SetDocumentHash(documentID int, hash string) error {
+	_, err := r.db.Exec(`UPDATE application_documents SET file_hash = $2 WHERE document_id = $1`, documentID, hash)
+	if err != nil {
+		return fmt.Errorf("failed to save document hash: %w", err)
+	}
+	return nil
+}
+
+// LoadScanInput collects the values the detection job compares across the
+// scanned applications
+func (r *RedFlagRepository) LoadScanInput() (*models.FraudScanInput, error) {
+	input := &models.FraudScanInput{}
+	var err error
+
+	input.BankAccounts, err = r.signalRecords(`
+		SELECT a.application_id, a.student_id, b.account_number, b.bank_name
+		FROM student_bank_accounts b
+		JOIN scholarship_applications a ON a.student_id = b.student_id
+		WHERE b.is_active AND ` + scannedApplications)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bank accounts: %w", err)
+	}
+
+	input.GuardianIDs, err = r.signalRecords(`
+		SELECT a.application_id, a.student_id, g.national_id, ''
+		FROM application_guardians g
+		JOIN scholarship_applications a ON a.application_id = g.application_id
+		WHERE g.national_id IS NOT NULL AND ` + scannedApplications)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load guardian national IDs: %w", err)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT a.application_id, a.student_id, d.file_hash, d.document_name, d.document_type
+		FROM application_documents d
+		JOIN scholarship_applications a ON a.application_id = d.application_id
+		WHERE d.file_hash IS NOT NULL AND ` + scannedApplications)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load document hashes: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var record models.SignalRecord
+		var documentType string
+		if err := rows.Scan(&record.ApplicationID, &record.StudentID, &record.Key, &record.Label, &documentType); err != nil {
+			return nil, fmt.Errorf("failed to scan document hash: %w", err)
+		}
+		if documentType == "house_registration" {
+			input.HouseRegistrations = append(input.HouseRegistrations, record)
+		} else {
+			input.Files = append(input.Files, record)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	assetRows, err := r.db.Query(`
+		SELECT a.application_id, a.student_id,
+		       COALESCE((a.need_assessment->>'household_income')::numeric, 0),
+		       COALESCE(SUM(s.value), 0)
+		FROM scholarship_applications a
+		JOIN application_assets s ON s.application_id = a.application_id
+		     AND COALESCE(s.category, 'asset') <> 'liability'
+		WHERE a.need_assessment IS NOT NULL AND ` + scannedApplications + `
+		GROUP BY a.application_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load declared assets: %w", err)
+	}
+	defer assetRows.Close()
+	for assetRows.Next() {
+		var record models.IncomeAssetRecord
+		if err := assetRows.Scan(&record.ApplicationID, &record.StudentID, &record.MonthlyIncome, &record.TotalAssetValue); err != nil {
+			return nil, fmt.Errorf("failed to scan declared assets: %w", err)
+		}
+		input.IncomeAssets = append(input.IncomeAssets, record)
+	}
+	return input, assetRows.Err()
+}
+
+func (r *RedFlagRepository) signalRecords(query string) ([]models.SignalRecord, error) {
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []models.SignalRecord
+	for rows.Next() {
+		var record models.SignalRecord
+		if err := rows.Scan(&record.ApplicationID, &record.StudentID, &record.Key, &record.Label); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// SaveFindings stores the flags of a detection run that started at runStart.
+// Known flags keep their triage status; resolved ones that show up again are
+// reopened, and open flags that were not found again are resolved. The
+// red_flag of the affected reviews is updated to match.
+func (r *RedFlagRepository) SaveFindings(flags []models.RedFlag, runStart time.Time) (created int, resolved int, err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, flag := range flags {
+		detailsJSON, err := json.Marshal(flag.Details)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to encode flag details: %w", err)
+		}
+		related := make([]int64, len(flag.RelatedApplicationIDs))
+		for i, id := range flag.RelatedApplicationIDs {
+			related[i] = int64(id)
+		}
+
+		var inserted bool
+		err = tx.QueryRow(`
+			INSERT INTO application_red_flags (
+				application_id, signal_type, severity, match_key, related_application_ids,
+				reason, details, status, detected_at, last_seen_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, 'open', $8, $8)
+			ON CONFLICT (application_id, signal_type, match_key) DO UPDATE
+			SET severity = EXCLUDED.severity,
+			    related_application_ids = EXCLUDED.related_application_ids,
+			    reason = EXCLUDED.reason,
+			    details = EXCLUDED.details,
+			    status = CASE WHEN application_red_flags.status = 'resolved' THEN 'open' ELSE application_red_flags.status END,
+			    last_seen_at = EXCLUDED.last_seen_at
+			RETURNING (xmax = 0)
+		`, flag.ApplicationID, flag.SignalType, flag.Severity, flag.MatchKey, pq.Array(related),
+			flag.Reason, detailsJSON, runStart,
+		).Scan(&inserted)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to save red flag: %w", err)
+		}
+		if inserted {
+			created++
+		}
+	}
+
+	result, err := tx.Exec(`
+		UPDATE application_red_flags SET status = 'resolved'
+		WHERE status = 'open' AND last_seen_at < $1
+	`, runStart)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to resolve red flags: %w", err)
+	}
+	count, _ := result.RowsAffected()
+
+	if err := syncReviewFlags(tx); err != nil {
+		return 0, 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit red flags: %w", err)
+	}
+	return created, int(count), nil
+}
+
+// syncReviewFlags raises red_flag on the reviews of applications with open or
+// confirmed flags and clears the flags it raised once none are left. Reviews
+// a reviewer flagged themselves are left alone.
+func syncReviewFlags(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		UPDATE application_reviews r
+		SET red_flag = TRUE, red_flag_reason = $1 || f.reasons, updated_at = NOW()
+		FROM (
+			SELECT application_id, string_agg(reason, '; ' ORDER BY flag_id) AS reasons
+			FROM application_red_flags
+			WHERE status IN ('open', 'confirmed')
+			GROUP BY application_id
+		) f
+		WHERE r.application_id = f.application_id
+		  AND (r.red_flag IS NOT TRUE OR r.red_flag_reason LIKE $1 || '%')
+		  AND r.red_flag_reason IS DISTINCT FROM $1 || f.reasons
+	`, models.RedFlagReasonPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to flag reviews: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE application_reviews r
+		SET red_flag = FALSE, red_flag_reason = NULL, updated_at = NOW()
+		WHERE r.red_flag_reason LIKE $1 || '%'
+		  AND NOT EXISTS (
+			SELECT 1 FROM application_red_flags f
+			WHERE f.application_id = r.application_id AND f.status IN ('open', 'confirmed')
+		  )
+	`, models.RedFlagReasonPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to clear review flags: %w", err)
+	}
+	return nil
+}
+
+const redFlagColumns = `
+	f.flag_id, f.application_id, f.signal_type, f.severity, f.match_key, f.related_application_ids,
+	f.reason, f.details, f.status, f.triaged_by, f.triaged_at, f.triage_note, f.detected_at, f.last_seen_at,
+	COALESCE(a.student_id, ''), COALESCE(s.name, '')
+`
+
+const redFlagFrom = `
+	FROM application_red_flags f
+	JOIN scholarship_applications a ON a.application_id = f.application_id
+	LEFT JOIN scholarships s ON s.scholarship_id = a.scholarship_id
+`
+
+// List returns red flags, most severe and newest first. Empty filters match
+// everything.
+func (r *RedFlagRepository) List(status, signalType string, scholarshipID, applicationID *uint) ([]models.RedFlag, error) {
+	rows, err := r.db.Query(`
+		SELECT `+redFlagColumns+redFlagFrom+`
+		WHERE ($1 = '' OR f.status = $1)
+		  AND ($2 = '' OR f.signal_type = $2)
+		  AND ($3::int IS NULL OR a.scholarship_id = $3)
+		  AND ($4::int IS NULL OR f.application_id = $4)
+		ORDER BY CASE f.severity WHEN 'high' THEN 1 WHEN 'medium' THEN 2 ELSE 3 END,
+		         f.detected_at DESC, f.flag_id
+	`, status, signalType, scholarshipID, applicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list red flags: %w", err)
+	}
+	defer rows.Close()
+
+	flags := []models.RedFlag{}
+	for rows.Next() {
+		flag, err := scanRedFlag(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan red flag: %w", err)
+		}
+		flags = append(flags, *flag)
+	}
+	return flags, rows.Err()
+}
+
+// GetByID returns a red flag
+func (r *RedFlagRepository) GetByID(flagID uint) (*models.RedFlag, error) {
+	row := r.db.QueryRow(`SELECT `+redFlagColumns+redFlagFrom+` WHERE f.flag_id = $1`, flagID)
+	return scanRedFlag(row)
+}
+
+// Triage sets the status of a flag and updates the review flags
+func (r *RedFlagRepository) Triage(flagID uint, status, note string, triagedBy uuid.UUID) (*models.RedFlag, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE application_red_flags
+		SET status = $2, triage_note = NULLIF($3, ''), triaged_by = $4, triaged_at = NOW()
+		WHERE flag_id = $1
+	`, flagID, status, note, triagedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to triage red flag: %w", err)
+	}
+	if count, _ := result.RowsAffected(); count == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	if err := syncReviewFlags(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit triage: %w", err)
+	}
+	return r.GetByID(flagID)
+}
+
+func scanRedFlag(row interface{ Scan(...interface{}) error }) (*models.RedFlag, error) {
+	flag := &models.RedFlag{}
+	var related pq.Int64Array
+	var detailsJSON []byte
+	err := row.Scan(
+		&flag.FlagID, &flag.ApplicationID, &flag.SignalType, &flag.Severity, &flag.MatchKey, &related,
+		&flag.Reason, &detailsJSON, &flag.Status, &flag.TriagedBy, &flag.TriagedAt, &flag.TriageNote,
+		&flag.DetectedAt, &flag.LastSeenAt, &flag.StudentID, &flag.ScholarshipName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	flag.RelatedApplicationIDs = make([]uint, len(related))
+	for i, id := range related {
+		flag.RelatedApplicationIDs[i] = uint(id)
+	}
+	if len(detailsJSON) > 0 {
+		if err := json.Unmarshal(detailsJSON, &flag.Details); err != nil {
+			return nil, fmt.Errorf("failed to decode flag details: %w", err)
+		}
+	}
+	return flag, nil
+}
@@ -0,0 +1,410 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+)
+
+// ErrLegalHold is returned when records of an application on legal hold
+// would be purged
+var ErrLegalHold = errors.New("application is on legal hold")
+
+type RetentionRepository struct {
+	db *sql.DB
+}
+
+func NewRetentionRepository() *RetentionRepository {
+	return &RetentionRepository{
+		db: database.DB,
+	}
+}
+
+const retentionRuleColumns = `rule_id, record_type, document_type, outcome, retention_months, action, is_active, notes, created_by, created_at, updated_at`
+
+func scanRetentionRule(row interface{ Scan(...interface{}) error }) (*models.RetentionRule, error) {
+	var rule models.RetentionRule
+	err := row.Scan(&rule.RuleID, &rule.RecordType, &rule.DocumentType, &rule.Outcome, &rule.RetentionMonths,
+		&rule.Action, &rule.IsActive, &rule.Notes, &rule.CreatedBy, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// ListRules returns every retention rule
+func (r *RetentionRepository) ListRules() ([]models.RetentionRule, error) {
+	rows, err := r.db.Query(`
+		SELECT ` + retentionRuleColumns + `
+		FROM retention_rules
+		ORDER BY record_type, outcome, document_type NULLS FIRST`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []models.RetentionRule{}
+	for rows.Next() {
+		rule, err := scanRetentionRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan retention rule: %w", err)
+		}
+		rules = append(rules, *rule)
+	}
+	return rules, rows.Err()
+}
+
+// GetRule returns a retention rule
+func (r *RetentionRepository) GetRule(ruleID int) (*models.RetentionRule, error) {
+	return scanRetentionRule(r.db.QueryRow(`SELECT `+retentionRuleColumns+` FROM retention_rules WHERE rule_id = $1`, ruleID))
+}
+
+// SaveRule creates the rule when RuleID is 0 and updates it otherwise
+func (r *RetentionRepository) SaveRule(rule *models.RetentionRule) error {
+	var row *sql.Row
+	if rule.RuleID == 0 {
+		row = r.db.QueryRow(`
+			INSERT INTO retention_rules (record_type, document_type, outcome, retention_months, action, is_active, notes, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING `+retentionRuleColumns,
+			rule.RecordType, rule.DocumentType, rule.Outcome, rule.RetentionMonths, rule.Action, rule.IsActive, rule.Notes, rule.CreatedBy)
+	} else {
+		row = r.db.QueryRow(`
+			UPDATE retention_rules
+			SET record_type = $2, document_type = $3, outcome = $4, retention_months = $5, action = $6,
+			    is_active = $7, notes = $8, updated_at = CURRENT_TIMESTAMP
+			WHERE rule_id = $1
+			RETURNING `+retentionRuleColumns,
+			rule.RuleID, rule.RecordType, rule.DocumentType, rule.Outcome, rule.RetentionMonths, rule.Action, rule.IsActive, rule.Notes)
+	}
+	saved, err := scanRetentionRule(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return err
+		}
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return fmt.Errorf("a retention rule for these records already exists")
+		}
+		return fmt.Errorf("failed to save retention rule: %w", err)
+	}
+	*rule = *saved
+	return nil
+}
+
+// DeleteRule removes a retention rule. Log entries written under it keep
+// their other details.
+func (r *RetentionRepository) DeleteRule(ruleID int) error {
+	result, err := r.db.Exec(`DELETE FROM retention_rules WHERE rule_id = $1`, ruleID)
+	if err != nil {
+		return fmt.Errorf("failed to delete retention rule: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListCandidates returns applications in the statuses after afterID, with the
+// announcement date of their round
+func (r *RetentionRepository) ListCandidates(statuses []string, afterID, limit int) ([]models.RetentionCandidate, error) {
+	rows, err := r.db.Query(`
+		SELECT a.application_id, COALESCE(a.scholarship_id, 0), a.application_status, rd.announcement_date,
+		       a.legal_hold, a.details_retention
+		FROM scholarship_applications a
+		LEFT JOIN scholarships s ON s.scholarship_id = a.scholarship_id
+		LEFT JOIN scholarship_rounds rd ON rd.round_id = s.round_id
+		WHERE a.application_status = ANY($1) AND a.application_id > $2
+		ORDER BY a.application_id
+		LIMIT $3`, pq.Array(statuses), afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention candidates: %w", err)
+	}
+	defer rows.Close()
+
+	candidates := []models.RetentionCandidate{}
+	for rows.Next() {
+		var c models.RetentionCandidate
+		if err := rows.Scan(&c.ApplicationID, &c.ScholarshipID, &c.Status, &c.AnnouncementDate, &c.LegalHold, &c.DetailsRetention); err != nil {
+			return nil, fmt.Errorf("failed to scan retention candidate: %w", err)
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+// ListDocuments returns the documents of an application with their stored
+// files, earlier versions and previews included
+func (r *RetentionRepository) ListDocuments(applicationID int) ([]models.RetentionDocument, error) {
+	rows, err := r.db.Query(`
+		SELECT d.document_id, d.document_type, COALESCE(d.upload_status, ''), f.table_name, f.id, f.file_path, f.storage_backend
+		FROM application_documents d
+		LEFT JOIN LATERAL (
+			SELECT 'application_documents' AS table_name, d.document_id::text AS id, d.file_path, d.storage_backend
+			WHERE COALESCE(d.file_path, '') <> ''
+			UNION ALL
+			SELECT 'document_versions', v.version_id::text, v.file_path, v.storage_backend
+			FROM document_versions v
+			WHERE v.document_id = d.document_id AND COALESCE(v.file_path, '') <> ''
+			UNION ALL
+			SELECT 'document_previews', d.document_id::text, d.preview_path, d.preview_backend
+			WHERE COALESCE(d.preview_path, '') <> ''
+		) f ON TRUE
+		WHERE d.application_id = $1
+		ORDER BY d.document_id`, applicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list application documents: %w", err)
+	}
+	defer rows.Close()
+
+	documents := []models.RetentionDocument{}
+	for rows.Next() {
+		var doc models.RetentionDocument
+		var table, id, key, backend sql.NullString
+		if err := rows.Scan(&doc.DocumentID, &doc.DocumentType, &doc.UploadStatus, &table, &id, &key, &backend); err != nil {
+			return nil, fmt.Errorf("failed to scan application document: %w", err)
+		}
+		if n := len(documents); n == 0 || documents[n-1].DocumentID != doc.DocumentID {
+			documents = append(documents, doc)
+		}
+		if table.Valid {
+			last := &documents[len(documents)-1]
+			last.Files = append(last.Files, models.StoredObject{Table: table.String, ID: id.String, Key: key.String, Backend: backend.String})
+		}
+	}
+	return documents, rows.Err()
+}
+
+// IsOnLegalHold reports whether an application is on legal hold
+func (r *RetentionRepository) IsOnLegalHold(applicationID int) (bool, error) {
+	var held bool
+	err := r.db.QueryRow(`SELECT legal_hold FROM scholarship_applications WHERE application_id = $1`, applicationID).Scan(&held)
+	if err != nil {
+		return false, fmt.Errorf("failed to check legal hold: %w", err)
+	}
+	return held, nil
+}
+
+// SetLegalHold places or lifts the legal hold of an application
+func (r *RetentionRepository) SetLegalHold(applicationID int, hold bool, reason *string, userID uuid.UUID) error {
+	result, err := r.db.Exec(`
+		UPDATE scholarship_applications
+		SET legal_hold = $2,
+		    legal_hold_reason = CASE WHEN $2 THEN $3 END,
+		    legal_hold_by = CASE WHEN $2 THEN $4::uuid END,
+		    legal_hold_at = CASE WHEN $2 THEN CURRENT_TIMESTAMP END
+		WHERE application_id = $1`, applicationID, hold, reason, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update legal hold: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ApplyRetention deletes or anonymizes the rows of a due retention action
+// and records it in retention_log. removeFiles deletes the stored files; it
+// runs after the rows are changed and before the commit, while the
+// application is locked, so a legal hold placed meanwhile waits for it and a
+// failed removal leaves the rows for the next run. It returns ErrLegalHold,
+// changing nothing, if the application was put on hold.
+func (r *RetentionRepository) ApplyRetention(action *models.RetentionAction, jobID *uuid.UUID, removeFiles func() error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var held bool
+	err = tx.QueryRow(`SELECT legal_hold FROM scholarship_applications WHERE application_id = $1 FOR UPDATE`, action.ApplicationID).Scan(&held)
+	if err != nil {
+		return fmt.Errorf("failed to lock application: %w", err)
+	}
+	if held {
+		return ErrLegalHold
+	}
+
+	var statements []string
+	switch {
+	case action.RecordType == models.RetentionDocuments && action.Action == models.RetentionPurge:
+		statements = []string{`DELETE FROM application_documents WHERE document_id = $1`}
+	case action.RecordType == models.RetentionDocuments:
+		statements = anonymizeDocumentStatements
+	case action.Action == models.RetentionPurge:
+		statements = purgeDetailsStatements
+	default:
+		statements = anonymizeDetailsStatements
+	}
+	id := action.ApplicationID
+	if action.DocumentID != nil {
+		id = *action.DocumentID
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement, id); err != nil {
+			return fmt.Errorf("failed to %s %s of application %d: %w", action.Action, action.RecordType, action.ApplicationID, err)
+		}
+	}
+	if action.RecordType == models.RetentionApplicationDetails {
+		_, err := tx.Exec(`
+			UPDATE scholarship_applications SET details_retention = $2, details_retention_at = CURRENT_TIMESTAMP
+			WHERE application_id = $1`, action.ApplicationID, action.Action)
+		if err != nil {
+			return fmt.Errorf("failed to mark application details: %w", err)
+		}
+	}
+
+	var documentType *string
+	if action.DocumentType != "" {
+		documentType = &action.DocumentType
+	}
+	_, err = tx.Exec(`
+		INSERT INTO retention_log (application_id, scholarship_id, record_type, document_id, document_type, files_deleted,
+		                           action, outcome, rule_id, announcement_date, job_id)
+		VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		action.ApplicationID, action.ScholarshipID, action.RecordType, action.DocumentID, documentType, action.Files,
+		action.Action, action.Outcome, action.RuleID, action.AnnouncementDate, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to write retention log: %w", err)
+	}
+
+	if err := removeFiles(); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// anonymizeDocumentStatements keep the row of a document, so counts and
+// checklists still show it was submitted, without its file or file name.
+// $1 is the document ID.
+var anonymizeDocumentStatements = []string{
+	`DELETE FROM document_versions WHERE document_id = $1`,
+	`UPDATE application_documents
+	 SET document_name = document_type, original_filename = NULL, file_path = '', file_size = 0, file_hash = NULL,
+	     verification_notes = NULL, scan_result = NULL, upload_status = 'purged',
+	     preview_path = NULL, preview_backend = NULL, preview_status = NULL
+	 WHERE document_id = $1`,
+}
+
+// purgeDetailsStatements delete the personal data of an application form.
+// The application row stays with its status, scholarship and amounts for
+// reporting. $1 is the application ID.
+var purgeDetailsStatements = []string{
+	`DELETE FROM application_personal_info WHERE application_id = $1`,
+	`DELETE FROM application_addresses WHERE application_id = $1`,
+	`DELETE FROM application_education_history WHERE application_id = $1`,
+	`DELETE FROM application_family_members WHERE application_id = $1`,
+	`DELETE FROM application_assets WHERE application_id = $1`,
+	`DELETE FROM application_guardians WHERE application_id = $1`,
+	`DELETE FROM application_siblings WHERE application_id = $1`,
+	`DELETE FROM application_living_situation WHERE application_id = $1`,
+	`DELETE FROM application_financial_info WHERE application_id = $1`,
+	`DELETE FROM application_scholarship_history WHERE application_id = $1`,
+	`DELETE FROM application_activities WHERE application_id = $1`,
+	`DELETE FROM application_references WHERE application_id = $1`,
+	`DELETE FROM application_health_info WHERE application_id = $1`,
+	`DELETE FROM application_funding_needs WHERE application_id = $1`,
+	`DELETE FROM application_house_documents WHERE application_id = $1`,
+	`DELETE FROM application_income_certificates WHERE application_id = $1`,
+	`DELETE FROM application_snapshots WHERE application_id = $1`,
+	`DELETE FROM application_prefills WHERE application_id = $1`,
+	`DELETE FROM application_search_index WHERE application_id = $1`,
+	clearApplicationFormStatement,
+}
+
+// anonymizeDetailsStatements blank whatever identifies the applicant and
+// their family but keep incomes, costs, education levels and provinces for
+// statistics. $1 is the application ID.
+var anonymizeDetailsStatements = []string{
+	`UPDATE application_personal_info
+	 SET prefix_th = NULL, prefix_en = NULL, first_name_th = '-', last_name_th = '-', first_name_en = NULL, last_name_en = NULL,
+	     email = '', phone = NULL, line_id = NULL, citizen_id = NULL, student_id = NULL, admission_details = NULL
+	 WHERE application_id = $1`,
+	`UPDATE application_addresses
+	 SET house_number = NULL, village_number = NULL, alley = NULL, road = NULL, subdistrict = NULL, postal_code = NULL,
+	     address_line1 = NULL, address_line2 = NULL, latitude = NULL, longitude = NULL, map_image_url = NULL
+	 WHERE application_id = $1`,
+	`UPDATE application_education_history SET school_name = '-' WHERE application_id = $1`,
+	`UPDATE application_family_members
+	 SET title = NULL, first_name = '-', last_name = '-', workplace = NULL, phone = NULL, notes = NULL
+	 WHERE application_id = $1`,
+	`UPDATE application_assets SET description = NULL, notes = NULL WHERE application_id = $1`,
+	`UPDATE application_guardians
+	 SET title = NULL, first_name = '-', last_name = '-', address = NULL, phone = NULL, workplace = NULL,
+	     workplace_phone = NULL, debt_details = NULL
+	 WHERE application_id = $1`,
+	`UPDATE application_siblings SET school_or_workplace = NULL, notes = NULL WHERE application_id = $1`,
+	`UPDATE application_living_situation
+	 SET living_details = NULL, front_house_image = NULL, side_house_image = NULL, back_house_image = NULL
+	 WHERE application_id = $1`,
+	`UPDATE application_financial_info SET income_source = NULL, financial_notes = NULL WHERE application_id = $1`,
+	`UPDATE application_scholarship_history SET notes = NULL WHERE application_id = $1`,
+	`UPDATE application_activities SET description = NULL, achievement = NULL, evidence_url = NULL WHERE application_id = $1`,
+	`DELETE FROM application_references WHERE application_id = $1`,
+	`UPDATE application_health_info
+	 SET health_condition = NULL, health_details = NULL, study_impact_details = NULL
+	 WHERE application_id = $1`,
+	`UPDATE application_funding_needs SET other_details = NULL, necessity_reason = NULL WHERE application_id = $1`,
+	`DELETE FROM application_house_documents WHERE application_id = $1`,
+	`UPDATE application_income_certificates
+	 SET owner_name = '-', certified_by = NULL, certifier_position = NULL, certifier_id_card = NULL,
+	     certificate_url = NULL, id_card_copy_url = NULL
+	 WHERE application_id = $1`,
+	`DELETE FROM application_snapshots WHERE application_id = $1`,
+	`DELETE FROM application_prefills WHERE application_id = $1`,
+	`DELETE FROM application_search_index WHERE application_id = $1`,
+	clearApplicationFormStatement,
+}
+
+// clearApplicationFormStatement drops the copies of the form kept on the
+// application row
+const clearApplicationFormStatement = `
+	UPDATE scholarship_applications
+	SET application_data = NULL, special_abilities = NULL, activities_participation = NULL,
+	    personal_info = NULL, academic_info = NULL, address_info = NULL, education_history = NULL,
+	    family_info = NULL, assets_liabilities = NULL, guardian_info = NULL, siblings_info = NULL,
+	    living_condition = NULL, financial_info = NULL, scholarship_history = NULL, activities_skills = NULL,
+	    reference_person = NULL, special_abilities_detailed = NULL, health_issues = NULL, funding_needs = NULL,
+	    advisor_name = NULL
+	WHERE application_id = $1`
+
+// ListLog returns retention log entries, newest first, optionally of one
+// application
+func (r *RetentionRepository) ListLog(applicationID *int, limit, offset int) ([]models.RetentionLogEntry, int, error) {
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM retention_log WHERE $1::int IS NULL OR application_id = $1`, applicationID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count retention log: %w", err)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT log_id, application_id, scholarship_id, record_type, document_id, document_type, files_deleted,
+		       action, outcome, rule_id, announcement_date, job_id, performed_at
+		FROM retention_log
+		WHERE $1::int IS NULL OR application_id = $1
+		ORDER BY performed_at DESC, log_id DESC
+		LIMIT $2 OFFSET $3`, applicationID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list retention log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.RetentionLogEntry{}
+	for rows.Next() {
+		var e models.RetentionLogEntry
+		if err := rows.Scan(&e.LogID, &e.ApplicationID, &e.ScholarshipID, &e.RecordType, &e.DocumentID, &e.DocumentType,
+			&e.FilesDeleted, &e.Action, &e.Outcome, &e.RuleID, &e.AnnouncementDate, &e.JobID, &e.PerformedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan retention log: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, total, rows.Err()
+}
@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+)
+
+type RevisionRepository struct {
+	db *sql.DB
+}
+
+func NewRevisionRepository() *RevisionRepository {
+	return &RevisionRepository{
+		db: database.DB,
+	}
+}
+
+// CreateRevision stores a revision request with its items and moves the
+// application to needs_revision in a single transaction
+func (r *RevisionRepository) CreateRevision(revision *models.ApplicationRevisionRequest) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var previousStatus string
+	err = tx.QueryRow(`
+		SELECT application_status FROM scholarship_applications
+		WHERE application_id = $1 FOR UPDATE
+	`, revision.ApplicationID).Scan(&previousStatus)
+	if err != nil {
+		return err
+	}
+	revision.PreviousStatus = &previousStatus
+
+	err = tx.QueryRow(`
+		INSERT INTO application_revision_requests
+			(application_id, requested_by, comments, resubmission_deadline, status,
+			 previous_status, form_snapshot, document_snapshot)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING revision_id, created_at, updated_at
+	`, revision.ApplicationID, revision.RequestedBy, revision.Comments, revision.ResubmissionDeadline,
+		models.RevisionStatusOpen, previousStatus, nullJSON(revision.FormSnapshot), nullJSON(revision.DocumentSnapshot),
+	).Scan(&revision.RevisionID, &revision.CreatedAt, &revision.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create revision request: %w", err)
+	}
+	revision.Status = models.RevisionStatusOpen
+
+	for i := range revision.Items {
+		item := &revision.Items[i]
+		item.RevisionID = revision.RevisionID
+		err = tx.QueryRow(`
+			INSERT INTO application_revision_items
+				(revision_id, item_type, section_name, document_type, document_id, comment)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING item_id, created_at
+		`, item.RevisionID, item.ItemType, item.SectionName, item.DocumentType, item.DocumentID, item.Comment,
+		).Scan(&item.ItemID, &item.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create revision item: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE scholarship_applications
+		SET application_status = 'needs_revision', updated_at = NOW()
+		WHERE application_id = $1
+	`, revision.ApplicationID)
+	if err != nil {
+		return fmt.Errorf("failed to update application status: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetOpenRevision returns the open revision request of an application, or
+// sql.ErrNoRows when there is none
+func (r *RevisionRepository) GetOpenRevision(applicationID uint) (*models.ApplicationRevisionRequest, error) {
+	revisions, err := r.listRevisions(`WHERE application_id = $1 AND status = 'open'`, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &revisions[0], nil
+}
+
+// ListRevisions returns every revision request of an application, newest first
+func (r *RevisionRepository) ListRevisions(applicationID uint) ([]models.ApplicationRevisionRequest, error) {
+	return r.listRevisions(`WHERE application_id = $1`, applicationID)
+}
+
+func (r *RevisionRepository) listRevisions(where string, args ...interface{}) ([]models.ApplicationRevisionRequest, error) {
+	rows, err := r.db.Query(`
+		SELECT revision_id, application_id, requested_by, comments, resubmission_deadline, status,
+		       previous_status, form_snapshot, document_snapshot, changes, resubmitted_at,
+		       created_at, updated_at
+		FROM application_revision_requests `+where+`
+		ORDER BY created_at DESC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision requests: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []models.ApplicationRevisionRequest{}
+	for rows.Next() {
+		var rev models.ApplicationRevisionRequest
+		var formSnapshot, documentSnapshot, changes []byte
+		err := rows.Scan(
+			&rev.RevisionID, &rev.ApplicationID, &rev.RequestedBy, &rev.Comments,
+			&rev.ResubmissionDeadline, &rev.Status, &rev.PreviousStatus,
+			&formSnapshot, &documentSnapshot, &changes, &rev.ResubmittedAt,
+			&rev.CreatedAt, &rev.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan revision request: %w", err)
+		}
+		rev.FormSnapshot = formSnapshot
+		rev.DocumentSnapshot = documentSnapshot
+		if len(changes) > 0 {
+			rev.Changes = &models.RevisionDiff{}
+			if err := json.Unmarshal(changes, rev.Changes); err != nil {
+				return nil, fmt.Errorf("failed to decode revision changes: %w", err)
+			}
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range revisions {
+		items, err := r.getItems(revisions[i].RevisionID)
+		if err != nil {
+			return nil, err
+		}
+		revisions[i].Items = items
+	}
+	return revisions, nil
+}
+
+func (r *RevisionRepository) getItems(revisionID uint) ([]models.ApplicationRevisionItem, error) {
+	rows, err := r.db.Query(`
+		SELECT item_id, revision_id, item_type, section_name, document_type, document_id,
+		       comment, is_resolved, created_at
+		FROM application_revision_items
+		WHERE revision_id = $1
+		ORDER BY item_id
+	`, revisionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.ApplicationRevisionItem{}
+	for rows.Next() {
+		var item models.ApplicationRevisionItem
+		err := rows.Scan(&item.ItemID, &item.RevisionID, &item.ItemType, &item.SectionName,
+			&item.DocumentType, &item.DocumentID, &item.Comment, &item.IsResolved, &item.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan revision item: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// CompleteResubmission records the student's changes, resolves the flagged
+// items and returns the application to the status it had before it was sent
+// back
+func (r *RevisionRepository) CompleteResubmission(revision *models.ApplicationRevisionRequest, changes *models.RevisionDiff) error {
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode revision changes: %w", err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE application_revision_requests
+		SET status = 'resubmitted', changes = $2, resubmitted_at = NOW()
+		WHERE revision_id = $1 AND status = 'open'
+	`, revision.RevisionID, changesJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update revision request: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.Exec(`UPDATE application_revision_items SET is_resolved = true WHERE revision_id = $1`, revision.RevisionID); err != nil {
+		return fmt.Errorf("failed to resolve revision items: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE scholarship_applications
+		SET application_status = $2, updated_at = NOW()
+		WHERE application_id = $1
+	`, revision.ApplicationID, statusBeforeRevision(revision))
+	if err != nil {
+		return fmt.Errorf("failed to update application status: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	revision.Status = models.RevisionStatusResubmitted
+	revision.Changes = changes
+	return nil
+}
+
+// MarkExpired closes an open revision request whose deadline has passed and
+// returns the application to the status it had before it was sent back, so
+// officers can act on it again. A request that is no longer open is left as
+// it is.
+func (r *RevisionRepository) MarkExpired(revision *models.ApplicationRevisionRequest) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE application_revision_requests SET status = 'expired'
+		WHERE revision_id = $1 AND status = 'open'
+	`, revision.RevisionID)
+	if err != nil {
+		return fmt.Errorf("failed to expire revision request: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil
+	}
+
+	_, err = tx.Exec(`
+		UPDATE scholarship_applications
+		SET application_status = $2, updated_at = NOW()
+		WHERE application_id = $1 AND application_status = 'needs_revision'
+	`, revision.ApplicationID, statusBeforeRevision(revision))
+	if err != nil {
+		return fmt.Errorf("failed to update application status: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	revision.Status = models.RevisionStatusExpired
+	return nil
+}
+
+// statusBeforeRevision is the status an application returns to when its
+// revision request is closed
+func statusBeforeRevision(revision *models.ApplicationRevisionRequest) string {
+	if revision.PreviousStatus != nil && *revision.PreviousStatus != "" && *revision.PreviousStatus != "needs_revision" {
+		return *revision.PreviousStatus
+	}
+	return "submitted"
+}
+
+// GetApplicationStatus returns the current status of an application
+func (r *RevisionRepository) GetApplicationStatus(applicationID uint) (string, error) {
+	var status string
+	err := r.db.QueryRow(`SELECT application_status FROM scholarship_applications WHERE application_id = $1`, applicationID).Scan(&status)
+	return status, err
+}
+
+// GetDocumentRefs lists the documents currently attached to an application
+func (r *RevisionRepository) GetDocumentRefs(applicationID uint) ([]models.RevisionDocumentRef, error) {
+	rows, err := r.db.Query(`
+		SELECT document_id, document_type, document_name, uploaded_at
+		FROM application_documents
+		WHERE application_id = $1
+		ORDER BY document_id
+	`, applicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get documents: %w", err)
+	}
+	defer rows.Close()
+
+	docs := []models.RevisionDocumentRef{}
+	for rows.Next() {
+		var doc models.RevisionDocumentRef
+		if err := rows.Scan(&doc.DocumentID, &doc.DocumentType, &doc.DocumentName, &doc.UploadedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+func nullJSON(data json.RawMessage) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return []byte(data)
+}
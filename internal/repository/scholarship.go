@@ -141,8 +141,8 @@ func (r *ScholarshipRepository) Create(scholarship *models.Scholarship) error {
 		INSERT INTO scholarships (source_id, name, type, amount, total_quota, available_quota,
 		                         academic_year, semester, eligibility_criteria, required_documents,
 		                         application_start_date, application_end_date, interview_required,
-		                         is_active, created_by, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17) RETURNING scholarship_id
+		                         is_active, created_by, created_at, updated_at, eligibility_rules, document_requirements)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19) RETURNING scholarship_id
 	`
 
 	now := time.Now()
@@ -179,7 +179,16 @@ func (r *ScholarshipRepository) Create(scholarship *models.Scholarship) error {
 		requiredDocumentsJSON = nil
 	}
 
-	err := r.db.QueryRow(query,
+	eligibilityRulesJSON, err := encodeEligibilityRules(scholarship.EligibilityRules)
+	if err != nil {
+		return err
+	}
+	documentRequirementsJSON, err := encodeDocumentRequirements(scholarship.DocumentRequirements)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(query,
 		scholarship.SourceID,
 		scholarship.ScholarshipName,
 		scholarship.ScholarshipType,
@@ -197,6 +206,8 @@ func (r *ScholarshipRepository) Create(scholarship *models.Scholarship) error {
 		scholarship.CreatedBy,
 		scholarship.CreatedAt,
 		scholarship.UpdatedAt,
+		eligibilityRulesJSON,
+		documentRequirementsJSON,
 	).Scan(&scholarship.ScholarshipID)
 
 	return err
@@ -208,6 +219,7 @@ func (r *ScholarshipRepository) GetByID(scholarshipID uint) (*models.Scholarship
 		       s.total_quota, s.available_quota, s.academic_year, s.semester, s.eligibility_criteria,
 		       s.required_documents, s.application_start_date, s.application_end_date,
 		       s.interview_required, s.is_active, s.created_by, s.created_at, s.updated_at,
+		       s.eligibility_rules, s.document_requirements,
 		       src.source_id, src.source_name, src.source_type, src.contact_person,
 		       src.contact_email, src.contact_phone, src.description, src.is_active,
 		       src.created_at, src.updated_at
@@ -221,6 +233,8 @@ func (r *ScholarshipRepository) GetByID(scholarshipID uint) (*models.Scholarship
 
 	var eligibilityCriteriaBytes []byte
 	var requiredDocumentsBytes []byte
+	var eligibilityRulesBytes []byte
+	var documentRequirementsBytes []byte
 
 	err := r.db.QueryRow(query, scholarshipID).Scan(
 		&scholarship.ScholarshipID,
@@ -241,6 +255,8 @@ func (r *ScholarshipRepository) GetByID(scholarshipID uint) (*models.Scholarship
 		&scholarship.CreatedBy,
 		&scholarship.CreatedAt,
 		&scholarship.UpdatedAt,
+		&eligibilityRulesBytes,
+		&documentRequirementsBytes,
 		&source.SourceID,
 		&source.SourceName,
 		&source.SourceType,
@@ -274,6 +290,13 @@ func (r *ScholarshipRepository) GetByID(scholarshipID uint) (*models.Scholarship
 		}
 	}
 
+	if scholarship.EligibilityRules, err = decodeEligibilityRules(eligibilityRulesBytes); err != nil {
+		return nil, err
+	}
+	if scholarship.DocumentRequirements, err = decodeDocumentRequirements(documentRequirementsBytes); err != nil {
+		return nil, err
+	}
+
 	scholarship.Source = source
 	return scholarship, nil
 }
@@ -330,6 +353,7 @@ func (r *ScholarshipRepository) List(limit, offset int, search, scholarshipType,
 		       s.total_quota, s.available_quota, s.academic_year, s.semester, s.eligibility_criteria,
 		       s.required_documents, s.application_start_date, s.application_end_date,
 		       s.interview_required, s.is_active, s.created_by, s.created_at, s.updated_at,
+		       s.eligibility_rules, s.document_requirements,
 		       src.source_id, src.source_name, src.source_type, src.contact_person,
 		       src.contact_email, src.contact_phone, src.description, src.is_active,
 		       src.created_at, src.updated_at
@@ -351,6 +375,8 @@ func (r *ScholarshipRepository) List(limit, offset int, search, scholarshipType,
 		var source models.ScholarshipSource
 		var eligibilityCriteriaBytes []byte
 		var requiredDocumentsBytes []byte
+		var eligibilityRulesBytes []byte
+		var documentRequirementsBytes []byte
 
 		err := rows.Scan(
 			&scholarship.ScholarshipID,
@@ -371,6 +397,8 @@ func (r *ScholarshipRepository) List(limit, offset int, search, scholarshipType,
 			&scholarship.CreatedBy,
 			&scholarship.CreatedAt,
 			&scholarship.UpdatedAt,
+			&eligibilityRulesBytes,
+			&documentRequirementsBytes,
 			&source.SourceID,
 			&source.SourceName,
 			&source.SourceType,
@@ -387,6 +415,13 @@ func (r *ScholarshipRepository) List(limit, offset int, search, scholarshipType,
 			return nil, 0, err
 		}
 
+		if scholarship.EligibilityRules, err = decodeEligibilityRules(eligibilityRulesBytes); err != nil {
+			return nil, 0, err
+		}
+		if scholarship.DocumentRequirements, err = decodeDocumentRequirements(documentRequirementsBytes); err != nil {
+			return nil, 0, err
+		}
+
 		// Convert JSONB back to string
 		if eligibilityCriteriaBytes != nil {
 			var criteriaArray []string
@@ -417,7 +452,8 @@ func (r *ScholarshipRepository) Update(scholarship *models.Scholarship) error {
 		SET source_id = $2, name = $3, type = $4, amount = $5,
 		    total_quota = $6, available_quota = $7, academic_year = $8, semester = $9,
 		    eligibility_criteria = $10, required_documents = $11, application_start_date = $12,
-		    application_end_date = $13, interview_required = $14, is_active = $15, updated_at = $16
+		    application_end_date = $13, interview_required = $14, is_active = $15, updated_at = $16,
+		    eligibility_rules = $17, document_requirements = $18
 		WHERE scholarship_id = $1
 	`
 
@@ -448,7 +484,16 @@ func (r *ScholarshipRepository) Update(scholarship *models.Scholarship) error {
 		requiredDocumentsJSON = nil
 	}
 
-	_, err := r.db.Exec(query,
+	eligibilityRulesJSON, err := encodeEligibilityRules(scholarship.EligibilityRules)
+	if err != nil {
+		return err
+	}
+	documentRequirementsJSON, err := encodeDocumentRequirements(scholarship.DocumentRequirements)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query,
 		scholarship.ScholarshipID,
 		scholarship.SourceID,
 		scholarship.ScholarshipName,
@@ -465,6 +510,8 @@ func (r *ScholarshipRepository) Update(scholarship *models.Scholarship) error {
 		scholarship.InterviewRequired,
 		scholarship.IsActive,
 		scholarship.UpdatedAt,
+		eligibilityRulesJSON,
+		documentRequirementsJSON,
 	)
 
 	return err
@@ -501,7 +548,7 @@ func (r *ScholarshipRepository) GetAvailableScholarships() ([]models.Scholarship
 		       s.total_quota, s.available_quota, s.academic_year, s.semester, s.eligibility_criteria,
 		       s.required_documents, s.application_start_date, s.application_end_date,
 		       s.interview_required, s.is_active, s.created_by, s.created_at, s.updated_at,
-		       src.source_name, src.source_type
+		       s.eligibility_rules, s.document_requirements, src.source_name, src.source_type
 		FROM scholarships s
 		LEFT JOIN scholarship_sources src ON s.source_id = src.source_id
 		WHERE s.is_active = true
@@ -524,6 +571,8 @@ func (r *ScholarshipRepository) GetAvailableScholarships() ([]models.Scholarship
 		var sourceName, sourceType sql.NullString
 		var eligibilityCriteriaBytes []byte
 		var requiredDocumentsBytes []byte
+		var eligibilityRulesBytes []byte
+		var documentRequirementsBytes []byte
 
 		err := rows.Scan(
 			&scholarship.ScholarshipID,
@@ -544,6 +593,8 @@ func (r *ScholarshipRepository) GetAvailableScholarships() ([]models.Scholarship
 			&scholarship.CreatedBy,
 			&scholarship.CreatedAt,
 			&scholarship.UpdatedAt,
+			&eligibilityRulesBytes,
+			&documentRequirementsBytes,
 			&sourceName,
 			&sourceType,
 		)
@@ -552,6 +603,13 @@ func (r *ScholarshipRepository) GetAvailableScholarships() ([]models.Scholarship
 			return nil, err
 		}
 
+		if scholarship.EligibilityRules, err = decodeEligibilityRules(eligibilityRulesBytes); err != nil {
+			return nil, err
+		}
+		if scholarship.DocumentRequirements, err = decodeDocumentRequirements(documentRequirementsBytes); err != nil {
+			return nil, err
+		}
+
 		// Convert JSONB back to string
 		if eligibilityCriteriaBytes != nil {
 			var criteriaArray []string
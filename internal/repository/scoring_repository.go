@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+)
+
+type ScoringRepository struct {
+	db *sql.DB
+}
+
+func NewScoringRepository() *ScoringRepository {
+	return &ScoringRepository{
+		db: database.DB,
+	}
+}
+
+const scoringProfileColumns = `profile_id, profile_name, scholarship_id, round_id, components, is_active, created_by, created_at, updated_at`
+
+func scanScoringProfile(row interface{ Scan(...interface{}) error }) (*models.ScoringProfile, error) {
+	var profile models.ScoringProfile
+	var componentsJSON []byte
+	err := row.Scan(&profile.ProfileID, &profile.ProfileName, &profile.ScholarshipID, &profile.RoundID,
+		&componentsJSON, &profile.IsActive, &profile.CreatedBy, &profile.CreatedAt, &profile.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(componentsJSON, &profile.Components); err != nil {
+		return nil, fmt.Errorf("failed to decode scoring components: %w", err)
+	}
+	return &profile, nil
+}
+
+// List returns scoring profiles, optionally only those of one scholarship
+func (r *ScoringRepository) List(scholarshipID *uint) ([]models.ScoringProfile, error) {
+	rows, err := r.db.Query(`
+		SELECT `+scoringProfileColumns+`
+		FROM scoring_profiles
+		WHERE $1::int IS NULL OR scholarship_id = $1
+		ORDER BY is_active DESC, scholarship_id NULLS LAST, round_id NULLS LAST, updated_at DESC
+	`, scholarshipID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scoring profiles: %w", err)
+	}
+	defer rows.Close()
+
+	profiles := []models.ScoringProfile{}
+	for rows.Next() {
+		profile, err := scanScoringProfile(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scoring profile: %w", err)
+		}
+		profiles = append(profiles, *profile)
+	}
+	return profiles, rows.Err()
+}
+
+// GetByID returns a scoring profile
+func (r *ScoringRepository) GetByID(profileID uint) (*models.ScoringProfile, error) {
+	return scanScoringProfile(r.db.QueryRow(`SELECT `+scoringProfileColumns+` FROM scoring_profiles WHERE profile_id = $1`, profileID))
+}
+
+// Resolve returns the active profile that applies to a scholarship: its own
+// profile, else the profile of its round, else the global profile, else the
+// built-in default
+func (r *ScoringRepository) Resolve(scholarshipID uint) (*models.ScoringProfile, error) {
+	profile, err := scanScoringProfile(r.db.QueryRow(`
+		SELECT `+scoringProfileColumns+`
+		FROM scoring_profiles p
+		WHERE p.is_active AND (
+		      p.scholarship_id = $1
+		   OR (p.scholarship_id IS NULL AND p.round_id = (SELECT round_id FROM scholarships WHERE scholarship_id = $1))
+		   OR (p.scholarship_id IS NULL AND p.round_id IS NULL))
+		ORDER BY (p.scholarship_id IS NOT NULL) DESC, (p.round_id IS NOT NULL) DESC
+		LIMIT 1
+	`, scholarshipID))
+	if err == sql.ErrNoRows {
+		return models.DefaultScoringProfile(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve scoring profile: %w", err)
+	}
+	return profile, nil
+}
+
+// Save creates the profile when ProfileID is 0 and updates it otherwise. An
+// active profile replaces the active profile of the same scholarship or round.
+func (r *ScoringRepository) Save(profile *models.ScoringProfile) error {
+	componentsJSON, err := json.Marshal(profile.Components)
+	if err != nil {
+		return fmt.Errorf("failed to encode scoring components: %w", err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if profile.IsActive {
+		_, err := tx.Exec(`
+			UPDATE scoring_profiles SET is_active = FALSE, updated_at = NOW()
+			WHERE is_active AND profile_id <> $1
+			  AND scholarship_id IS NOT DISTINCT FROM $2 AND round_id IS NOT DISTINCT FROM $3
+		`, profile.ProfileID, profile.ScholarshipID, profile.RoundID)
+		if err != nil {
+			return fmt.Errorf("failed to deactivate scoring profiles: %w", err)
+		}
+	}
+
+	if profile.ProfileID == 0 {
+		err = tx.QueryRow(`
+			INSERT INTO scoring_profiles (profile_name, scholarship_id, round_id, components, is_active, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING profile_id, created_at, updated_at
+		`, profile.ProfileName, profile.ScholarshipID, profile.RoundID, componentsJSON, profile.IsActive, profile.CreatedBy).
+			Scan(&profile.ProfileID, &profile.CreatedAt, &profile.UpdatedAt)
+	} else {
+		err = tx.QueryRow(`
+			UPDATE scoring_profiles
+			SET profile_name = $2, scholarship_id = $3, round_id = $4, components = $5, is_active = $6, updated_at = NOW()
+			WHERE profile_id = $1
+			RETURNING created_at, updated_at
+		`, profile.ProfileID, profile.ProfileName, profile.ScholarshipID, profile.RoundID, componentsJSON, profile.IsActive).
+			Scan(&profile.CreatedAt, &profile.UpdatedAt)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a scoring profile. Applications scored with it keep their
+// stored breakdown.
+func (r *ScoringRepository) Delete(profileID uint) error {
+	result, err := r.db.Exec(`DELETE FROM scoring_profiles WHERE profile_id = $1`, profileID)
+	if err != nil {
+		return fmt.Errorf("failed to delete scoring profile: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetCriteria returns the active review criteria of a scholarship together
+// with the criteria shared by all scholarships, keyed by criteria_id. A nil
+// scholarshipID returns only the shared criteria.
+func (r *ScoringRepository) GetCriteria(scholarshipID *uint) (map[int]models.ReviewCriterion, error) {
+	rows, err := r.db.Query(`
+		SELECT criteria_id, scholarship_id, criteria_name, criteria_type, max_score, weight, is_active
+		FROM review_criteria
+		WHERE is_active AND (scholarship_id IS NULL OR scholarship_id = $1)
+	`, scholarshipID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review criteria: %w", err)
+	}
+	defer rows.Close()
+
+	criteria := map[int]models.ReviewCriterion{}
+	for rows.Next() {
+		var criterion models.ReviewCriterion
+		err := rows.Scan(&criterion.CriteriaID, &criterion.ScholarshipID, &criterion.CriteriaName, &criterion.CriteriaType,
+			&criterion.MaxScore, &criterion.Weight, &criterion.IsActive)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan review criteria: %w", err)
+		}
+		criteria[criterion.CriteriaID] = criterion
+	}
+	return criteria, rows.Err()
+}
+
+// SaveScore stores the priority score of an application and its breakdown
+func (r *ScoringRepository) SaveScore(applicationID uint, breakdown *models.ScoreBreakdown) error {
+	breakdownJSON, err := json.Marshal(breakdown)
+	if err != nil {
+		return fmt.Errorf("failed to encode score breakdown: %w", err)
+	}
+
+	var profileID *uint
+	if breakdown.ProfileID != 0 {
+		profileID = &breakdown.ProfileID
+	}
+	_, err = r.db.Exec(`
+		UPDATE scholarship_applications
+		SET priority_score = $2, priority_breakdown = $3, scoring_profile_id = $4, priority_scored_at = $5
+		WHERE application_id = $1
+	`, applicationID, breakdown.TotalScore, breakdownJSON, profileID, breakdown.ScoredAt)
+	if err != nil {
+		return fmt.Errorf("failed to save priority score: %w", err)
+	}
+	return nil
+}
+
+// GetScore returns the stored score breakdown of an application, or
+// sql.ErrNoRows when it has not been scored yet
+func (r *ScoringRepository) GetScore(applicationID uint) (*models.ScoreBreakdown, error) {
+	var breakdownJSON []byte
+	err := r.db.QueryRow(`SELECT priority_breakdown FROM scholarship_applications WHERE application_id = $1`, applicationID).
+		Scan(&breakdownJSON)
+	if err != nil {
+		return nil, err
+	}
+	if breakdownJSON == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	var breakdown models.ScoreBreakdown
+	if err := json.Unmarshal(breakdownJSON, &breakdown); err != nil {
+		return nil, fmt.Errorf("failed to decode score breakdown: %w", err)
+	}
+	return &breakdown, nil
+}
+
+// ListSubmittedApplicationIDs returns the applications of a scholarship that
+// are in review or decided, i.e. those that carry a priority score
+func (r *ScoringRepository) ListSubmittedApplicationIDs(scholarshipID uint) ([]uint, error) {
+	rows, err := r.db.Query(`
+		SELECT application_id FROM scholarship_applications
+		WHERE scholarship_id = $1 AND application_status NOT IN ('draft', 'withdrawn', 'declined')
+		ORDER BY application_id
+	`, scholarshipID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applications: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []uint{}
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan application: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+)
+
+type SearchRepository struct {
+	db *sql.DB
+}
+
+func NewSearchRepository() *SearchRepository {
+	return &SearchRepository{
+		db: database.DB,
+	}
+}
+
+// ListStale returns the text of applications that were never indexed or
+// changed since they were
+func (r *SearchRepository) ListStale(limit int) ([]models.SearchSource, error) {
+	rows, err := r.db.Query(`
+		SELECT sa.application_id, sa.form_version, sa.updated_at, sa.student_id,
+		       COALESCE(s.name, ''),
+		       concat_ws(' ', pi.prefix_th, pi.first_name_th, pi.last_name_th, pi.first_name_en, pi.last_name_en),
+		       COALESCE(pi.citizen_id, ''),
+		       concat_ws(' ', pi.faculty, pi.department, pi.major, st.faculty_code, st.department_code),
+		       COALESCE((
+		           SELECT string_agg(concat_ws(' ', ad.house_number, ad.village_number, ad.alley, ad.road,
+		                                       ad.subdistrict, ad.district, ad.province, ad.postal_code,
+		                                       ad.address_line1, ad.address_line2), ' / ')
+		           FROM application_addresses ad WHERE ad.application_id = sa.application_id
+		       ), ''),
+		       COALESCE((
+		           SELECT string_agg(concat_ws(' ', eh.school_name, eh.school_province), ' / ')
+		           FROM application_education_history eh WHERE eh.application_id = sa.application_id
+		       ), ''),
+		       concat_ws(' / ',
+		           (SELECT string_agg(concat_ws(' ', fm.relationship, fm.title, fm.first_name, fm.last_name,
+		                                        fm.occupation, fm.position, fm.workplace, fm.workplace_province), ' / ')
+		            FROM application_family_members fm WHERE fm.application_id = sa.application_id),
+		           (SELECT string_agg(concat_ws(' ', g.relationship, g.title, g.first_name, g.last_name,
+		                                        g.occupation, g.position, g.workplace, g.address), ' / ')
+		            FROM application_guardians g WHERE g.application_id = sa.application_id)
+		       ),
+		       concat_ws(' / ',
+		           (SELECT string_agg(concat_ws(' ', ac.activity_name, ac.description, ac.achievement), ' / ')
+		            FROM application_activities ac WHERE ac.application_id = sa.application_id),
+		           sa.special_abilities, sa.activities_participation
+		       )
+		FROM scholarship_applications sa
+		LEFT JOIN scholarships s ON s.scholarship_id = sa.scholarship_id
+		LEFT JOIN application_personal_info pi ON pi.application_id = sa.application_id
+		LEFT JOIN students st ON st.student_id = sa.student_id
+		LEFT JOIN application_search_index si ON si.application_id = sa.application_id
+		WHERE si.application_id IS NULL
+		   OR si.form_version <> sa.form_version
+		   OR si.source_updated_at IS DISTINCT FROM sa.updated_at
+		ORDER BY sa.application_id
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applications to index: %w", err)
+	}
+	defer rows.Close()
+
+	sources := []models.SearchSource{}
+	for rows.Next() {
+		var src models.SearchSource
+		err := rows.Scan(
+			&src.ApplicationID, &src.FormVersion, &src.UpdatedAt, &src.StudentID,
+			&src.ScholarshipName, &src.StudentName, &src.CitizenID, &src.Faculty,
+			&src.Addresses, &src.Schools, &src.Family, &src.Activities,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan application to index: %w", err)
+		}
+		sources = append(sources, src)
+	}
+	return sources, rows.Err()
+}
+
+// MarkAllStale makes the next index run rebuild every application
+func (r *SearchRepository) MarkAllStale() error {
+	if _, err := r.db.Exec(`UPDATE application_search_index SET form_version = -1`); err != nil {
+		return fmt.Errorf("failed to reset search index: %w", err)
+	}
+	return nil
+}
+
+// Save stores the indexed form of an application
+func (r *SearchRepository) Save(doc models.SearchDocument) error {
+	fieldsJSON, err := json.Marshal(doc.Fields)
+	if err != nil {
+		return fmt.Errorf("failed to encode search fields: %w", err)
+	}
+	tokens := func(weight string) interface{} {
+		if doc.Tokens[weight] == nil {
+			return pq.Array([]string{})
+		}
+		return pq.Array(doc.Tokens[weight])
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO application_search_index (application_id, search_vector, fields, form_version, source_updated_at, indexed_at)
+		VALUES ($1,
+		        setweight(array_to_tsvector($2::text[]), 'A') ||
+		        setweight(array_to_tsvector($3::text[]), 'B') ||
+		        setweight(array_to_tsvector($4::text[]), 'C'),
+		        $5, $6, $7, CURRENT_TIMESTAMP)
+		ON CONFLICT (application_id) DO UPDATE
+		SET search_vector = EXCLUDED.search_vector,
+		    fields = EXCLUDED.fields,
+		    form_version = EXCLUDED.form_version,
+		    source_updated_at = EXCLUDED.source_updated_at,
+		    indexed_at = CURRENT_TIMESTAMP
+	`, doc.ApplicationID, tokens(models.SearchWeightA), tokens(models.SearchWeightB), tokens(models.SearchWeightC),
+		fieldsJSON, doc.FormVersion, doc.SourceUpdated)
+	if err != nil {
+		return fmt.Errorf("failed to save search index of application %d: %w", doc.ApplicationID, err)
+	}
+	return nil
+}
+
+// Search returns the applications matching a tsquery built by
+// services.BuildSearchQuery, best matches first, with the total count
+func (r *SearchRepository) Search(tsQuery string, params models.ApplicationSearchParams, scope models.SearchScope) ([]models.ApplicationSearchResult, int, error) {
+	results := []models.ApplicationSearchResult{}
+	if !scope.All && len(scope.Faculties) == 0 {
+		return results, 0, nil
+	}
+
+	conditions := []string{"si.search_vector @@ $1::tsquery"}
+	args := []interface{}{tsQuery}
+	if params.Status != "" {
+		args = append(args, params.Status)
+		conditions = append(conditions, fmt.Sprintf("sa.application_status = $%d", len(args)))
+	} else {
+		conditions = append(conditions, "sa.application_status <> 'draft'")
+	}
+	if params.ScholarshipID != nil {
+		args = append(args, *params.ScholarshipID)
+		conditions = append(conditions, fmt.Sprintf("sa.scholarship_id = $%d", len(args)))
+	}
+	if !scope.All {
+		args = append(args, pq.Array(scope.Faculties))
+		conditions = append(conditions, fmt.Sprintf("(LOWER(pi.faculty) = ANY($%d) OR LOWER(st.faculty_code) = ANY($%d))", len(args), len(args)))
+	}
+	args = append(args, params.Limit, params.Offset)
+
+	rows, err := r.db.Query(fmt.Sprintf(`
+		SELECT sa.application_id, sa.student_id, concat_ws(' ', pi.first_name_th, pi.last_name_th),
+		       sa.scholarship_id, COALESCE(s.name, ''), sa.application_status, sa.submitted_at,
+		       ts_rank(si.search_vector, $1::tsquery), si.fields, COUNT(*) OVER()
+		FROM application_search_index si
+		JOIN scholarship_applications sa ON sa.application_id = si.application_id
+		LEFT JOIN scholarships s ON s.scholarship_id = sa.scholarship_id
+		LEFT JOIN application_personal_info pi ON pi.application_id = sa.application_id
+		LEFT JOIN students st ON st.student_id = sa.student_id
+		WHERE %s
+		ORDER BY 8 DESC, sa.submitted_at DESC NULLS LAST, sa.application_id DESC
+		LIMIT $%d OFFSET $%d
+	`, strings.Join(conditions, " AND "), len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search applications: %w", err)
+	}
+	defer rows.Close()
+
+	total := 0
+	for rows.Next() {
+		var result models.ApplicationSearchResult
+		var fieldsJSON []byte
+		err := rows.Scan(
+			&result.ApplicationID, &result.StudentID, &result.StudentName,
+			&result.ScholarshipID, &result.ScholarshipName, &result.ApplicationStatus, &result.SubmittedAt,
+			&result.Rank, &fieldsJSON, &total,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		if err := json.Unmarshal(fieldsJSON, &result.Fields); err != nil {
+			return nil, 0, fmt.Errorf("failed to decode search fields: %w", err)
+		}
+		results = append(results, result)
+	}
+	return results, total, rows.Err()
+}
+
+// GetStaffFaculties returns the faculties of a user's interviewer and
+// advisor records
+func (r *SearchRepository) GetStaffFaculties(userID uuid.UUID) ([]string, error) {
+	rows, err := r.db.Query(`
+		SELECT faculty FROM interviewers WHERE user_id = $1 AND faculty IS NOT NULL
+		UNION
+		SELECT faculty FROM advisors WHERE user_id = $1 AND faculty IS NOT NULL
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staff faculties: %w", err)
+	}
+	defer rows.Close()
+
+	faculties := []string{}
+	for rows.Next() {
+		var faculty string
+		if err := rows.Scan(&faculty); err != nil {
+			return nil, fmt.Errorf("failed to scan faculty: %w", err)
+		}
+		faculties = append(faculties, faculty)
+	}
+	return faculties, rows.Err()
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+)
+
+type SnapshotRepository struct {
+	db *sql.DB
+}
+
+func NewSnapshotRepository() *SnapshotRepository {
+	return &SnapshotRepository{
+		db: database.DB,
+	}
+}
+
+// SubmitWithSnapshot marks a draft application as submitted and stores its
+// first snapshot in the same transaction, so a submitted application always
+// has the copy reviewers score against
+func (r *SnapshotRepository) SubmitWithSnapshot(snapshot *models.ApplicationSnapshot) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE scholarship_applications
+		SET application_status = 'submitted', submitted_at = NOW(), updated_at = NOW()
+		WHERE application_id = $1 AND application_status = 'draft'
+	`, snapshot.ApplicationID)
+	if err != nil {
+		return fmt.Errorf("failed to submit application: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := r.insert(tx, snapshot); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Create stores a new snapshot version of an application
+func (r *SnapshotRepository) Create(snapshot *models.ApplicationSnapshot) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.insert(tx, snapshot); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SnapshotRepository) insert(tx *sql.Tx, snapshot *models.ApplicationSnapshot) error {
+	// Lock the application so concurrent submissions get distinct versions
+	var locked uint
+	err := tx.QueryRow(`
+		SELECT application_id FROM scholarship_applications WHERE application_id = $1 FOR UPDATE
+	`, snapshot.ApplicationID).Scan(&locked)
+	if err != nil {
+		return err
+	}
+
+	documents, err := json.Marshal(snapshot.Documents)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot documents: %w", err)
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO application_snapshots
+			(application_id, version, reason, form_data, documents, checksum, created_by)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4, $5, $6
+		FROM application_snapshots WHERE application_id = $1
+		RETURNING snapshot_id, version, created_at
+	`, snapshot.ApplicationID, snapshot.Reason, []byte(snapshot.FormData), documents, snapshot.Checksum, snapshot.CreatedBy,
+	).Scan(&snapshot.SnapshotID, &snapshot.Version, &snapshot.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetLatest returns the newest snapshot of an application, or sql.ErrNoRows
+// when it has never been submitted
+func (r *SnapshotRepository) GetLatest(applicationID uint) (*models.ApplicationSnapshot, error) {
+	return r.get(`WHERE application_id = $1 ORDER BY version DESC LIMIT 1`, applicationID)
+}
+
+// GetVersion returns a specific snapshot version of an application
+func (r *SnapshotRepository) GetVersion(applicationID uint, version int) (*models.ApplicationSnapshot, error) {
+	return r.get(`WHERE application_id = $1 AND version = $2`, applicationID, version)
+}
+
+func (r *SnapshotRepository) get(where string, args ...interface{}) (*models.ApplicationSnapshot, error) {
+	snapshot := &models.ApplicationSnapshot{}
+	var formData, documents []byte
+	err := r.db.QueryRow(`
+		SELECT snapshot_id, application_id, version, reason, form_data, documents, checksum, created_by, created_at
+		FROM application_snapshots `+where,
+		args...,
+	).Scan(&snapshot.SnapshotID, &snapshot.ApplicationID, &snapshot.Version, &snapshot.Reason,
+		&formData, &documents, &snapshot.Checksum, &snapshot.CreatedBy, &snapshot.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.FormData = formData
+	if err := json.Unmarshal(documents, &snapshot.Documents); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot documents: %w", err)
+	}
+	return snapshot, nil
+}
+
+// List returns the snapshot versions of an application without their form
+// data, oldest first
+func (r *SnapshotRepository) List(applicationID uint) ([]models.ApplicationSnapshot, error) {
+	rows, err := r.db.Query(`
+		SELECT snapshot_id, application_id, version, reason, documents, checksum, created_by, created_at
+		FROM application_snapshots
+		WHERE application_id = $1
+		ORDER BY version
+	`, applicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []models.ApplicationSnapshot{}
+	for rows.Next() {
+		var snapshot models.ApplicationSnapshot
+		var documents []byte
+		err := rows.Scan(&snapshot.SnapshotID, &snapshot.ApplicationID, &snapshot.Version, &snapshot.Reason,
+			&documents, &snapshot.Checksum, &snapshot.CreatedBy, &snapshot.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot: %w", err)
+		}
+		if err := json.Unmarshal(documents, &snapshot.Documents); err != nil {
+			return nil, fmt.Errorf("failed to decode snapshot documents: %w", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, rows.Err()
+}
@@ -0,0 +1,356 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+)
+
+type StorageRepository struct {
+	db *sql.DB
+}
+
+func NewStorageRepository() *StorageRepository {
+	return &StorageRepository{
+		db: database.DB,
+	}
+}
+
+// ListObjects returns every file record kept in a backend, with the SHA-256
+// recorded at upload where there is one. Records written before backends
+// were tracked count as local; infected documents, whose file the malware
+// scan deleted, are left out.
+func (r *StorageRepository) ListObjects(backend string) ([]models.StoredObject, error) {
+	rows, err := r.db.Query(`
+		SELECT 'application_documents', document_id::text, file_path, storage_backend, COALESCE(mime_type, ''),
+		       COALESCE(file_hash, '')
+		FROM application_documents
+		WHERE storage_backend = $1 AND COALESCE(file_path, '') <> '' AND upload_status <> $2
+		UNION ALL
+		SELECT 'file_storage', file_id::text, stored_path, COALESCE(storage_type, 'local'), mime_type,
+		       COALESCE(file_hash, '')
+		FROM file_storage
+		WHERE COALESCE(storage_type, 'local') = $1 AND stored_path <> ''
+		UNION ALL
+		SELECT 'document_versions', version_id::text, file_path, storage_backend, COALESCE(mime_type, ''),
+		       COALESCE(file_hash, '')
+		FROM document_versions
+		WHERE storage_backend = $1 AND COALESCE(file_path, '') <> '' AND COALESCE(upload_status, '') <> $2
+		UNION ALL
+		SELECT 'document_previews', document_id::text, preview_path, preview_backend, 'image/jpeg', ''
+		FROM application_documents
+		WHERE preview_backend = $1 AND COALESCE(preview_path, '') <> ''
+		ORDER BY 1, 2`, backend, models.DocumentInfected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stored files: %w", err)
+	}
+	defer rows.Close()
+
+	objects := []models.StoredObject{}
+	for rows.Next() {
+		var obj models.StoredObject
+		if err := rows.Scan(&obj.Table, &obj.ID, &obj.Key, &obj.Backend, &obj.ContentType, &obj.Hash); err != nil {
+			return nil, fmt.Errorf("failed to scan stored file: %w", err)
+		}
+		objects = append(objects, obj)
+	}
+	return objects, rows.Err()
+}
+
+// MoveObject points a record at its new backend and key. It reports false
+// when the record changed or disappeared since it was listed.
+func (r *StorageRepository) MoveObject(obj models.StoredObject, backend, key string) (bool, error) {
+	var query string
+	switch obj.Table {
+	case models.StoredInApplicationDocuments:
+		query = `UPDATE application_documents SET file_path = $4, storage_backend = $5
+			WHERE document_id = $1::integer AND file_path = $2 AND storage_backend = $3`
+	case models.StoredInFileStorage:
+		query = `UPDATE file_storage SET stored_path = $4, storage_type = $5
+			WHERE file_id = $1::uuid AND stored_path = $2 AND COALESCE(storage_type, 'local') = $3`
+	case models.StoredInDocumentVersions:
+		query = `UPDATE document_versions SET file_path = $4, storage_backend = $5
+			WHERE version_id = $1::uuid AND file_path = $2 AND storage_backend = $3`
+	case models.StoredInDocumentPreviews:
+		query = `UPDATE application_documents SET preview_path = $4, preview_backend = $5
+			WHERE document_id = $1::integer AND preview_path = $2 AND preview_backend = $3`
+	default:
+		return false, fmt.Errorf("unknown file table %q", obj.Table)
+	}
+
+	result, err := r.db.Exec(query, obj.ID, obj.Key, obj.Backend, key, backend)
+	if err != nil {
+		return false, fmt.Errorf("failed to update file location: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to update file location: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// ListQuarantined returns documents waiting for a malware scan, oldest first,
+// after the given document ID
+func (r *StorageRepository) ListQuarantined(afterID, limit int) ([]models.ApplicationDocument, error) {
+	rows, err := r.db.Query(`
+		SELECT document_id, application_id, document_type, document_name, file_path,
+		       storage_backend, COALESCE(file_size, 0), COALESCE(mime_type, ''), upload_status
+		FROM application_documents
+		WHERE upload_status = $1 AND document_id > $2
+		ORDER BY document_id
+		LIMIT $3`, models.DocumentQuarantined, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list quarantined documents: %w", err)
+	}
+	defer rows.Close()
+
+	docs := []models.ApplicationDocument{}
+	for rows.Next() {
+		var doc models.ApplicationDocument
+		if err := rows.Scan(&doc.DocumentID, &doc.ApplicationID, &doc.DocumentType, &doc.DocumentName, &doc.FilePath,
+			&doc.StorageBackend, &doc.FileSize, &doc.MimeType, &doc.UploadStatus); err != nil {
+			return nil, fmt.Errorf("failed to scan quarantined document: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+// ReleaseDocument marks a quarantined document clean and points it at its
+// released key. It reports false when the document changed or was deleted
+// while it was scanned.
+func (r *StorageRepository) ReleaseDocument(doc models.ApplicationDocument, key string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE application_documents
+		SET upload_status = $1, file_path = $2, scanned_at = CURRENT_TIMESTAMP, scan_result = 'OK'
+		WHERE document_id = $3 AND upload_status = $4 AND file_path = $5`,
+		models.DocumentPending, key, doc.DocumentID, models.DocumentQuarantined, doc.FilePath)
+	if err != nil {
+		return false, fmt.Errorf("failed to release document: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to release document: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// MarkInfected records the malware found in a quarantined document
+func (r *StorageRepository) MarkInfected(doc models.ApplicationDocument, signature string) error {
+	_, err := r.db.Exec(`
+		UPDATE application_documents
+		SET upload_status = $1, scanned_at = CURRENT_TIMESTAMP, scan_result = $2,
+		    verification_notes = $3
+		WHERE document_id = $4 AND upload_status = $5 AND file_path = $6`,
+		models.DocumentInfected, signature, "Malware detected: "+signature+". Please upload a clean copy.",
+		doc.DocumentID, models.DocumentQuarantined, doc.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to mark document infected: %w", err)
+	}
+	return nil
+}
+
+// ListPendingPreviews returns documents waiting for the preview job, after
+// the given document ID. Documents still held by the malware scan or whose
+// file is gone are left out.
+func (r *StorageRepository) ListPendingPreviews(afterID, limit int) ([]models.ApplicationDocument, error) {
+	rows, err := r.db.Query(`
+		SELECT document_id, application_id, document_type, file_path, storage_backend,
+		       COALESCE(mime_type, ''), upload_status, COALESCE(preview_path, ''), COALESCE(preview_backend, '')
+		FROM application_documents
+		WHERE preview_status IS NULL AND upload_status NOT IN ($1, $2, $3, $4)
+		  AND COALESCE(file_path, '') <> '' AND document_id > $5
+		ORDER BY document_id
+		LIMIT $6`, models.DocumentQuarantined, models.DocumentInfected, models.DocumentPurged, models.DocumentMissing, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents waiting for a preview: %w", err)
+	}
+	defer rows.Close()
+
+	docs := []models.ApplicationDocument{}
+	for rows.Next() {
+		var doc models.ApplicationDocument
+		if err := rows.Scan(&doc.DocumentID, &doc.ApplicationID, &doc.DocumentType, &doc.FilePath, &doc.StorageBackend,
+			&doc.MimeType, &doc.UploadStatus, &doc.PreviewPath, &doc.PreviewBackend); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+// SavePreview records the preview and metadata of a document. An empty key
+// clears the preview. It reports false when the file was replaced or the
+// document deleted since it was listed.
+func (r *StorageRepository) SavePreview(doc models.ApplicationDocument, status, backend, key string, preview *models.DocumentPreview) (bool, error) {
+	if preview == nil {
+		preview = &models.DocumentPreview{}
+	}
+	result, err := r.db.Exec(`
+		UPDATE application_documents
+		SET preview_status = $1, preview_path = NULLIF($2, ''), preview_backend = NULLIF($3, ''),
+		    page_count = $4, image_width = $5, image_height = $6, preview_generated_at = CURRENT_TIMESTAMP
+		WHERE document_id = $7 AND file_path = $8 AND preview_status IS NULL`,
+		status, key, backend, preview.PageCount, preview.ImageWidth, preview.ImageHeight, doc.DocumentID, doc.FilePath)
+	if err != nil {
+		return false, fmt.Errorf("failed to save document preview: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to save document preview: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// RepairObject records on a file record that reconciliation found its file
+// missing or damaged: documents and their versions are marked missing so the
+// student uploads them again, and a lost preview is queued to be generated
+// again. File storage records are left for an administrator. It reports false
+// when there was nothing to change or the record changed since it was listed.
+func (r *StorageRepository) RepairObject(issue models.ReconcileIssue) (bool, error) {
+	note := "The stored file is missing. Please upload the document again."
+	if issue.Kind == models.ReconcileHashMismatch {
+		note = "The stored file is damaged. Please upload the document again."
+	}
+
+	var result sql.Result
+	var err error
+	switch {
+	case issue.Table == models.StoredInApplicationDocuments:
+		result, err = r.db.Exec(`
+			UPDATE application_documents SET upload_status = $3, verification_notes = $4
+			WHERE document_id = $1::integer AND file_path = $2 AND upload_status NOT IN ($3, $5, $6)`,
+			issue.ID, issue.Key, models.DocumentMissing, note, models.DocumentInfected, models.DocumentPurged)
+	case issue.Table == models.StoredInDocumentVersions:
+		result, err = r.db.Exec(`
+			UPDATE document_versions SET upload_status = $3
+			WHERE version_id = $1::uuid AND file_path = $2 AND COALESCE(upload_status, '') <> $3`,
+			issue.ID, issue.Key, models.DocumentMissing)
+	case issue.Table == models.StoredInDocumentPreviews && issue.Kind == models.ReconcileMissingFile:
+		result, err = r.db.Exec(`
+			UPDATE application_documents
+			SET preview_status = NULL, preview_path = NULL, preview_backend = NULL, preview_generated_at = NULL
+			WHERE document_id = $1::integer AND preview_path = $2`,
+			issue.ID, issue.Key)
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to repair file record: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to repair file record: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// SaveReconciliation stores the result of a storage reconciliation and sets
+// its ID
+func (r *StorageRepository) SaveReconciliation(result *models.StorageReconcileResult) error {
+	issues, err := json.Marshal(result.Issues)
+	if err != nil {
+		return fmt.Errorf("failed to marshal reconciliation issues: %w", err)
+	}
+	errorsJSON, err := json.Marshal(result.Errors)
+	if err != nil {
+		return fmt.Errorf("failed to marshal reconciliation errors: %w", err)
+	}
+	if result.Issues == nil {
+		issues = []byte("[]")
+	}
+	if result.Errors == nil {
+		errorsJSON = []byte("[]")
+	}
+
+	err = r.db.QueryRow(`
+		INSERT INTO storage_reconciliations (source, job_id, backends, verify_hashes, quarantine, repair,
+		       files_scanned, records_checked, orphan_files, missing_files, hash_mismatches,
+		       quarantined, repaired, failed, issues, errors, started_at, finished_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		RETURNING reconciliation_id`,
+		result.Source, result.JobID, pq.Array(result.Backends), result.VerifyHashes, result.Quarantine, result.Repair,
+		result.FilesScanned, result.RecordsChecked, result.OrphanFiles, result.MissingFiles, result.HashMismatches,
+		result.Quarantined, result.Repaired, result.Failed, issues, errorsJSON, result.StartedAt, result.FinishedAt,
+	).Scan(&result.ReconciliationID)
+	if err != nil {
+		return fmt.Errorf("failed to save reconciliation: %w", err)
+	}
+	return nil
+}
+
+const reconciliationColumns = `reconciliation_id, source, job_id, backends, verify_hashes, quarantine, repair,
+	files_scanned, records_checked, orphan_files, missing_files, hash_mismatches,
+	quarantined, repaired, failed, errors, started_at, finished_at`
+
+func scanReconciliation(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*models.StorageReconcileResult, error) {
+	var result models.StorageReconcileResult
+	var errorsJSON []byte
+	dest := []interface{}{&result.ReconciliationID, &result.Source, &result.JobID, pq.Array(&result.Backends),
+		&result.VerifyHashes, &result.Quarantine, &result.Repair,
+		&result.FilesScanned, &result.RecordsChecked, &result.OrphanFiles, &result.MissingFiles, &result.HashMismatches,
+		&result.Quarantined, &result.Repaired, &result.Failed, &errorsJSON, &result.StartedAt, &result.FinishedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(errorsJSON, &result.Errors); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal reconciliation errors: %w", err)
+	}
+	return &result, nil
+}
+
+// ListReconciliations returns the latest storage reconciliations, newest
+// first, without their issues
+func (r *StorageRepository) ListReconciliations(limit int) ([]models.StorageReconcileResult, error) {
+	rows, err := r.db.Query(`
+		SELECT `+reconciliationColumns+`
+		FROM storage_reconciliations
+		ORDER BY finished_at DESC, reconciliation_id DESC
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reconciliations: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.StorageReconcileResult{}
+	for rows.Next() {
+		result, err := scanReconciliation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reconciliation: %w", err)
+		}
+		results = append(results, *result)
+	}
+	return results, rows.Err()
+}
+
+// GetReconciliation returns a storage reconciliation with its issues, or
+// sql.ErrNoRows
+func (r *StorageRepository) GetReconciliation(id int) (*models.StorageReconcileResult, error) {
+	var issues []byte
+	result, err := scanReconciliation(r.db.QueryRow(`
+		SELECT `+reconciliationColumns+`, issues
+		FROM storage_reconciliations
+		WHERE reconciliation_id = $1`, id), &issues)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(issues, &result.Issues); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal reconciliation issues: %w", err)
+	}
+	return result, nil
+}
+
+// LatestReconciliation returns the newest storage reconciliation without its
+// issues, or nil when none has run
+func (r *StorageRepository) LatestReconciliation() (*models.StorageReconcileResult, error) {
+	results, err := r.ListReconciliations(1)
+	if err != nil || len(results) == 0 {
+		return nil, err
+	}
+	return &results[0], nil
+}
@@ -0,0 +1,427 @@
+package repository
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+)
+
+// UploadSessionRepository stores resumable uploads and the sessions that
+// track their progress
+type UploadSessionRepository struct {
+	db *sql.DB
+}
+
+func NewUploadSessionRepository() *UploadSessionRepository {
+	return &UploadSessionRepository{
+		db: database.DB,
+	}
+}
+
+// CreateSession starts an upload session for a user and sets its token
+func (r *UploadSessionRepository) CreateSession(session *models.BulkUploadSession) error {
+	token := make([]byte, 24)
+	if _, err := rand.Read(token); err != nil {
+		return fmt.Errorf("failed to generate session token: %w", err)
+	}
+	session.SessionToken = hex.EncodeToString(token)
+	session.SessionStatus = models.UploadSessionInProgress
+	if session.SessionType == "" {
+		session.SessionType = models.UploadSessionStudent
+	}
+
+	err := r.db.QueryRow(`
+		INSERT INTO bulk_upload_sessions (
+			session_token, user_id, application_id, session_status, expires_at, session_type, document_types
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, started_at`,
+		session.SessionToken, session.UserID, session.ApplicationID, session.SessionStatus, session.ExpiresAt,
+		session.SessionType, pq.Array(session.DocumentTypes),
+	).Scan(&session.ID, &session.StartedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create upload session: %w", err)
+	}
+	return nil
+}
+
+// GetSession returns a session of a user by its token
+func (r *UploadSessionRepository) GetSession(token string, userID uuid.UUID) (*models.BulkUploadSession, error) {
+	var s models.BulkUploadSession
+	var errorSummary []byte
+	err := r.db.QueryRow(`
+		SELECT id, session_token, user_id, application_id, total_files, uploaded_files, failed_files,
+		       session_status, upload_progress, error_summary, started_at, completed_at, expires_at,
+		       session_type, document_types
+		FROM bulk_upload_sessions
+		WHERE session_token = $1 AND user_id = $2`, token, userID,
+	).Scan(
+		&s.ID, &s.SessionToken, &s.UserID, &s.ApplicationID, &s.TotalFiles, &s.UploadedFiles, &s.FailedFiles,
+		&s.SessionStatus, &s.UploadProgress, &errorSummary, &s.StartedAt, &s.CompletedAt, &s.ExpiresAt,
+		&s.SessionType, pq.Array(&s.DocumentTypes),
+	)
+	if err != nil {
+		return nil, err
+	}
+	s.ErrorSummary = errorSummary
+	return &s, nil
+}
+
+// CreateUpload adds a resumable upload to its session
+func (r *UploadSessionRepository) CreateUpload(upload *models.ResumableUpload) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	upload.UploadStatus = models.ResumableUploading
+	err = tx.QueryRow(`
+		INSERT INTO resumable_uploads (
+			session_id, application_id, document_type, file_name, upload_length,
+			storage_backend, replace_document_id, upload_status
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING upload_id, created_at, updated_at`,
+		upload.SessionID, upload.ApplicationID, upload.DocumentType, upload.FileName, upload.UploadLength,
+		upload.StorageBackend, upload.ReplaceDocumentID, upload.UploadStatus,
+	).Scan(&upload.UploadID, &upload.CreatedAt, &upload.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create upload: %w", err)
+	}
+	if err := refreshSession(tx, upload.SessionID, upload.ExpiresAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetUpload returns an upload of a user
+func (r *UploadSessionRepository) GetUpload(uploadID string, userID uuid.UUID) (*models.ResumableUpload, error) {
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return nil, sql.ErrNoRows
+	}
+	rows, err := r.db.Query(`
+		SELECT `+resumableUploadColumns+`
+		FROM resumable_uploads u
+		JOIN bulk_upload_sessions s ON s.id = u.session_id
+		WHERE u.upload_id = $1 AND s.user_id = $2`, uploadID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload: %w", err)
+	}
+	uploads, err := scanResumableUploads(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(uploads) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &uploads[0], nil
+}
+
+// ListUploads returns the uploads of a session, oldest first
+func (r *UploadSessionRepository) ListUploads(sessionID int) ([]models.ResumableUpload, error) {
+	rows, err := r.db.Query(`
+		SELECT `+resumableUploadColumns+`
+		FROM resumable_uploads u
+		JOIN bulk_upload_sessions s ON s.id = u.session_id
+		WHERE u.session_id = $1
+		ORDER BY u.created_at, u.upload_id`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list uploads: %w", err)
+	}
+	return scanResumableUploads(rows)
+}
+
+const resumableUploadColumns = `u.upload_id, u.session_id, s.session_token, u.application_id, u.document_type,
+		       u.file_name, u.upload_length, u.upload_offset, u.storage_backend, u.replace_document_id,
+		       u.document_id, u.upload_status, u.error_message, u.created_at, u.updated_at, s.expires_at,
+		       u.completed_at`
+
+func scanResumableUploads(rows *sql.Rows) ([]models.ResumableUpload, error) {
+	defer rows.Close()
+	uploads := []models.ResumableUpload{}
+	for rows.Next() {
+		var u models.ResumableUpload
+		err := rows.Scan(
+			&u.UploadID, &u.SessionID, &u.SessionToken, &u.ApplicationID, &u.DocumentType,
+			&u.FileName, &u.UploadLength, &u.UploadOffset, &u.StorageBackend, &u.ReplaceDocumentID,
+			&u.DocumentID, &u.UploadStatus, &u.ErrorMessage, &u.CreatedAt, &u.UpdatedAt, &u.ExpiresAt,
+			&u.CompletedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan upload: %w", err)
+		}
+		uploads = append(uploads, u)
+	}
+	return uploads, rows.Err()
+}
+
+// AddChunk records a chunk stored at key and moves the upload offset past
+// it. It reports false, recording nothing, when the upload is no longer at
+// offset, e.g. because the same chunk was sent twice at once.
+func (r *UploadSessionRepository) AddChunk(upload *models.ResumableUpload, offset, size int64, key string, expiresAt time.Time) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE resumable_uploads
+		SET upload_offset = upload_offset + $3, updated_at = CURRENT_TIMESTAMP
+		WHERE upload_id = $1 AND upload_offset = $2 AND upload_status = $4
+		  AND upload_offset + $3 <= upload_length`,
+		upload.UploadID, offset, size, models.ResumableUploading)
+	if err != nil {
+		return false, fmt.Errorf("failed to update upload offset: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO resumable_upload_chunks (upload_id, chunk_offset, chunk_size, chunk_key)
+		VALUES ($1, $2, $3, $4)`, upload.UploadID, offset, size, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to record chunk: %w", err)
+	}
+	if err := refreshSession(tx, upload.SessionID, expiresAt); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit chunk: %w", err)
+	}
+	upload.UploadOffset = offset + size
+	if expiresAt.After(upload.ExpiresAt) {
+		upload.ExpiresAt = expiresAt
+	}
+	return true, nil
+}
+
+// ListChunkKeys returns the storage keys of the chunks of an upload in order
+func (r *UploadSessionRepository) ListChunkKeys(uploadID string) ([]string, error) {
+	rows, err := r.db.Query(`
+		SELECT chunk_key FROM resumable_upload_chunks
+		WHERE upload_id = $1
+		ORDER BY chunk_offset`, uploadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunks: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// ClaimUpload marks a fully received upload as being finalized. It reports
+// false when the upload is incomplete or another request already claimed it.
+func (r *UploadSessionRepository) ClaimUpload(uploadID string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE resumable_uploads
+		SET upload_status = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE upload_id = $1 AND upload_status = $3 AND upload_offset = upload_length`,
+		uploadID, models.ResumableFinalizing, models.ResumableUploading)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim upload: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// ReleaseUpload returns a claimed upload to uploading so finalizing can be
+// retried after a temporary failure
+func (r *UploadSessionRepository) ReleaseUpload(uploadID string) error {
+	_, err := r.db.Exec(`
+		UPDATE resumable_uploads
+		SET upload_status = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE upload_id = $1 AND upload_status = $3`,
+		uploadID, models.ResumableUploading, models.ResumableFinalizing)
+	if err != nil {
+		return fmt.Errorf("failed to release upload: %w", err)
+	}
+	return nil
+}
+
+// CompleteUpload links a finalized upload to its document and forgets its
+// chunks, whose files the caller deletes
+func (r *UploadSessionRepository) CompleteUpload(upload *models.ResumableUpload, documentID int, expiresAt time.Time) error {
+	return r.finish(upload, models.ResumableCompleted, &documentID, nil, expiresAt)
+}
+
+// FailUpload marks an upload as rejected and forgets its chunks, whose files
+// the caller deletes. The message is added to the session's error summary.
+func (r *UploadSessionRepository) FailUpload(upload *models.ResumableUpload, message string, expiresAt time.Time) error {
+	return r.finish(upload, models.ResumableFailed, nil, &message, expiresAt)
+}
+
+func (r *UploadSessionRepository) finish(upload *models.ResumableUpload, status string, documentID *int, message *string, expiresAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE resumable_uploads
+		SET upload_status = $2, document_id = $3, error_message = $4,
+		    completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE upload_id = $1`, upload.UploadID, status, documentID, message)
+	if err != nil {
+		return fmt.Errorf("failed to update upload: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM resumable_upload_chunks WHERE upload_id = $1`, upload.UploadID); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	if message != nil {
+		entry, _ := json.Marshal([]string{upload.FileName + ": " + *message})
+		_, err = tx.Exec(`
+			UPDATE bulk_upload_sessions
+			SET error_summary = COALESCE(error_summary, '[]'::jsonb) || $2::jsonb
+			WHERE id = $1`, upload.SessionID, string(entry))
+		if err != nil {
+			return fmt.Errorf("failed to update session errors: %w", err)
+		}
+	}
+	if err := refreshSession(tx, upload.SessionID, expiresAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// refreshSession recounts the files and bytes of a session and pushes its
+// expiry out to at least expiresAt. Failed uploads count as fully processed.
+func refreshSession(db sqlRunner, sessionID int, expiresAt time.Time) error {
+	// Lock the session first so the recount below sees uploads committed by
+	// concurrent requests of the same session
+	var locked int
+	if err := db.QueryRow(`SELECT id FROM bulk_upload_sessions WHERE id = $1 FOR UPDATE`, sessionID).Scan(&locked); err != nil {
+		return fmt.Errorf("failed to lock upload session: %w", err)
+	}
+
+	_, err := db.Exec(`
+		UPDATE bulk_upload_sessions s
+		SET total_files = c.total,
+		    uploaded_files = c.uploaded,
+		    failed_files = c.failed,
+		    upload_progress = c.progress,
+		    session_status = CASE WHEN c.uploaded + c.failed = c.total THEN $3 ELSE $4 END,
+		    completed_at = CASE WHEN c.uploaded + c.failed = c.total THEN COALESCE(s.completed_at, CURRENT_TIMESTAMP) END,
+		    expires_at = GREATEST(s.expires_at, $2)
+		FROM (
+			SELECT COUNT(*) AS total,
+			       COUNT(*) FILTER (WHERE upload_status = 'completed') AS uploaded,
+			       COUNT(*) FILTER (WHERE upload_status = 'failed') AS failed,
+			       COALESCE(ROUND(100.0 * SUM(CASE WHEN upload_status = 'failed' THEN upload_length ELSE upload_offset END)
+			                      / NULLIF(SUM(upload_length), 0), 2), 0) AS progress
+			FROM resumable_uploads
+			WHERE session_id = $1
+		) c
+		WHERE s.id = $1`,
+		sessionID, expiresAt, models.UploadSessionCompleted, models.UploadSessionInProgress)
+	if err != nil {
+		return fmt.Errorf("failed to update upload session: %w", err)
+	}
+	return nil
+}
+
+// FinishImport records the outcome of an officer import, whose files are
+// not staged as uploads, and completes its session
+func (r *UploadSessionRepository) FinishImport(session *models.BulkUploadSession, results []models.BulkFileResult) error {
+	uploaded, errs := 0, []string{}
+	for _, result := range results {
+		if result.Status == models.BulkFileFailed {
+			errs = append(errs, result.FileName+": "+result.Error)
+		} else {
+			uploaded++
+		}
+	}
+	errorSummary, _ := json.Marshal(errs)
+
+	err := r.db.QueryRow(`
+		UPDATE bulk_upload_sessions
+		SET total_files = $2, uploaded_files = $3, failed_files = $4, upload_progress = 100,
+		    session_status = $5, error_summary = $6, completed_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING completed_at`,
+		session.ID, len(results), uploaded, len(results)-uploaded, models.UploadSessionCompleted, string(errorSummary),
+	).Scan(&session.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to finish import: %w", err)
+	}
+	session.TotalFiles = len(results)
+	session.UploadedFiles = uploaded
+	session.FailedFiles = len(results) - uploaded
+	session.UploadProgress = 100
+	session.SessionStatus = models.UploadSessionCompleted
+	session.ErrorSummary = errorSummary
+	return nil
+}
+
+// ListExpiredSessions returns the IDs of sessions that expired before now
+func (r *UploadSessionRepository) ListExpiredSessions(now time.Time, limit int) ([]int, error) {
+	rows, err := r.db.Query(`
+		SELECT id FROM bulk_upload_sessions
+		WHERE expires_at < $1
+		ORDER BY expires_at
+		LIMIT $2`, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired upload sessions: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan upload session: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ListSessionChunks returns the stored chunks of every upload in a session
+func (r *UploadSessionRepository) ListSessionChunks(sessionID int) ([]models.StoredObject, error) {
+	rows, err := r.db.Query(`
+		SELECT c.upload_id::text, c.chunk_key, u.storage_backend
+		FROM resumable_upload_chunks c
+		JOIN resumable_uploads u ON u.upload_id = c.upload_id
+		WHERE u.session_id = $1
+		ORDER BY c.upload_id, c.chunk_offset`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session chunks: %w", err)
+	}
+	defer rows.Close()
+
+	chunks := []models.StoredObject{}
+	for rows.Next() {
+		chunk := models.StoredObject{Table: "resumable_upload_chunks"}
+		if err := rows.Scan(&chunk.ID, &chunk.Key, &chunk.Backend); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk: %w", err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, rows.Err()
+}
+
+// DeleteSession removes a session with its uploads and chunk records
+func (r *UploadSessionRepository) DeleteSession(sessionID int) error {
+	if _, err := r.db.Exec(`DELETE FROM bulk_upload_sessions WHERE id = $1`, sessionID); err != nil {
+		return fmt.Errorf("failed to delete upload session: %w", err)
+	}
+	return nil
+}
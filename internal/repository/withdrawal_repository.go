@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/database"
+	"scholarship-system/internal/models"
+)
+
+// ErrApplicationStatusChanged is returned by Process when the application no
+// longer has the status the withdrawal was checked against
+var ErrApplicationStatusChanged = errors.New("application status changed")
+
+type WithdrawalRepository struct {
+	db *sql.DB
+}
+
+func NewWithdrawalRepository() *WithdrawalRepository {
+	return &WithdrawalRepository{
+		db: database.DB,
+	}
+}
+
+// HasFinalRanking reports whether the application appears in a ranking that
+// has already been finalised or approved
+func (r *WithdrawalRepository) HasFinalRanking(applicationID uint) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM application_rankings
+			WHERE application_id = $1 AND ranking_status IN ('final', 'approved')
+		)
+	`, applicationID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check ranking status: %w", err)
+	}
+	return exists, nil
+}
+
+// IsDisbursed reports whether any money has already been transferred for the
+// application's award
+func (r *WithdrawalRepository) IsDisbursed(applicationID uint) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM scholarship_allocations
+			WHERE application_id = $1
+			  AND (allocation_status = 'disbursed' OR transfer_date IS NOT NULL)
+		)
+	`, applicationID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check disbursement: %w", err)
+	}
+	return exists, nil
+}
+
+// Process closes an application on the student's behalf. In one transaction
+// it moves the application to withdrawn or declined, cancels any open
+// revision request and interview bookings, puts pending reviews on hold,
+// releases any allocation back to the scholarship quota and budget and
+// records the withdrawal. The application row itself is kept for statistics.
+// withdrawal.PreviousStatus must hold the status the caller checked; if the
+// locked row has moved on, for example through a concurrent withdrawal, it
+// returns ErrApplicationStatusChanged and changes nothing.
+func (r *WithdrawalRepository) Process(withdrawal *models.ApplicationWithdrawal) error {
+	status, timeColumn, reasonColumn, allocationStatus := "withdrawn", "withdrawn_at", "withdrawal_reason", "cancelled"
+	if withdrawal.WithdrawalType == models.WithdrawalTypeDecline {
+		status, timeColumn, reasonColumn, allocationStatus = "declined", "declined_at", "decline_reason", "declined"
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var currentStatus string
+	var scholarshipID uint
+	err = tx.QueryRow(`
+		SELECT application_status, scholarship_id FROM scholarship_applications
+		WHERE application_id = $1 FOR UPDATE
+	`, withdrawal.ApplicationID).Scan(&currentStatus, &scholarshipID)
+	if err != nil {
+		return err
+	}
+	if currentStatus != withdrawal.PreviousStatus {
+		return ErrApplicationStatusChanged
+	}
+
+	_, err = tx.Exec(`
+		UPDATE scholarship_applications
+		SET application_status = $2, `+timeColumn+` = NOW(), `+reasonColumn+` = $3, updated_at = NOW()
+		WHERE application_id = $1
+	`, withdrawal.ApplicationID, status, withdrawal.Reason)
+	if err != nil {
+		return fmt.Errorf("failed to update application status: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE application_revision_requests SET status = 'cancelled'
+		WHERE application_id = $1 AND status = 'open'
+	`, withdrawal.ApplicationID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel revision request: %w", err)
+	}
+
+	// Cancel interview bookings and free their seats like CancelBooking, so a
+	// slot an officer closed stays closed
+	rows, err := tx.Query(`
+		UPDATE interview_bookings b
+		SET booking_status = 'cancelled', cancelled_at = NOW(), updated_at = NOW()
+		FROM interview_slots s
+		WHERE b.slot_id = s.id AND b.application_id = $1
+		  AND b.booking_status NOT IN ('cancelled', 'completed')
+		RETURNING b.slot_id, s.interviewer_id
+	`, withdrawal.ApplicationID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel interview bookings: %w", err)
+	}
+	var slotIDs []int
+	for rows.Next() {
+		var slotID int
+		var interviewerID uuid.UUID
+		if err := rows.Scan(&slotID, &interviewerID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan cancelled booking: %w", err)
+		}
+		slotIDs = append(slotIDs, slotID)
+		withdrawal.InterviewerIDs = appendUniqueUUID(withdrawal.InterviewerIDs, interviewerID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, slotID := range slotIDs {
+		if err := releaseSeat(tx, slotID); err != nil {
+			return err
+		}
+	}
+	withdrawal.CancelledBookings = len(slotIDs)
+
+	// Put outstanding reviews on hold so they drop out of reviewer queues
+	rows, err = tx.Query(`
+		UPDATE application_reviews SET review_status = 'on_hold', updated_at = NOW()
+		WHERE application_id = $1 AND review_status = 'pending'
+		RETURNING reviewer_id
+	`, withdrawal.ApplicationID)
+	if err != nil {
+		return fmt.Errorf("failed to hold reviews: %w", err)
+	}
+	for rows.Next() {
+		var reviewerID uuid.UUID
+		if err := rows.Scan(&reviewerID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan reviewer: %w", err)
+		}
+		withdrawal.ReviewerIDs = appendUniqueUUID(withdrawal.ReviewerIDs, reviewerID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Release the allocation back to the scholarship quota and budget
+	var allocationID int
+	var allocatedAmount float64
+	err = tx.QueryRow(`
+		SELECT allocation_id, allocated_amount FROM scholarship_allocations
+		WHERE application_id = $1 AND allocation_status NOT IN ('cancelled', 'declined', 'rejected')
+		ORDER BY allocation_id DESC LIMIT 1
+		FOR UPDATE
+	`, withdrawal.ApplicationID).Scan(&allocationID, &allocatedAmount)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get allocation: %w", err)
+	}
+	if err == nil {
+		_, err = tx.Exec(`
+			UPDATE scholarship_allocations SET allocation_status = $2, updated_at = NOW()
+			WHERE allocation_id = $1
+		`, allocationID, allocationStatus)
+		if err != nil {
+			return fmt.Errorf("failed to update allocation: %w", err)
+		}
+
+		_, err = tx.Exec(`
+			UPDATE scholarship_budgets
+			SET allocated_budget = GREATEST(allocated_budget - $1, 0)
+			WHERE scholarship_id = $2
+		`, allocatedAmount, scholarshipID)
+		if err != nil {
+			return fmt.Errorf("failed to release budget: %w", err)
+		}
+
+		_, err = tx.Exec(`
+			UPDATE scholarships SET available_quota = available_quota + 1
+			WHERE scholarship_id = $1
+		`, scholarshipID)
+		if err != nil {
+			return fmt.Errorf("failed to release quota: %w", err)
+		}
+
+		withdrawal.AllocationID = &allocationID
+		withdrawal.ReleasedAmount = allocatedAmount
+		withdrawal.QuotaReleased = true
+	}
+
+	_, err = tx.Exec(`
+		UPDATE application_rankings SET is_awarded = false, updated_at = NOW()
+		WHERE application_id = $1 AND is_awarded = true
+	`, withdrawal.ApplicationID)
+	if err != nil {
+		return fmt.Errorf("failed to update ranking: %w", err)
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO application_withdrawals
+			(application_id, withdrawal_type, previous_status, reason, requested_by,
+			 allocation_id, released_amount, quota_released, cancelled_bookings)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING withdrawal_id, created_at
+	`, withdrawal.ApplicationID, withdrawal.WithdrawalType, withdrawal.PreviousStatus, withdrawal.Reason,
+		withdrawal.RequestedBy, withdrawal.AllocationID, withdrawal.ReleasedAmount, withdrawal.QuotaReleased,
+		withdrawal.CancelledBookings,
+	).Scan(&withdrawal.WithdrawalID, &withdrawal.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record withdrawal: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func appendUniqueUUID(ids []uuid.UUID, id uuid.UUID) []uuid.UUID {
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
@@ -80,6 +80,9 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 	// Setup public news routes (no authentication)
 	setupPublicNewsRoutes(api, newsHandler)
 
+	// Signed document links carry their own authorization (no JWT)
+	api.Get("/files/documents/:document_id", handlers.NewDocumentLinkHandler(cfg).OpenLink)
+
 	// Protected routes with JWT middleware
 	protected := api.Group("", middleware.JWTMiddleware(cfg))
 
@@ -113,11 +116,6 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 	protected.Get("/applications/draft", applicationEnhanced.LoadDraft)
 	protected.Delete("/applications/draft", applicationEnhanced.DeleteDraft)
 
-	// Document management
-	protected.Post("/documents/bulk-upload", applicationEnhanced.StartBulkUpload)
-	protected.Post("/documents/bulk-upload/files", applicationEnhanced.UploadBulkFiles)
-	protected.Get("/documents/bulk-upload/progress", applicationEnhanced.GetUploadProgress)
-
 	// Validation
 	protected.Post("/applications/validate", applicationEnhanced.ValidateApplication)
 	protected.Get("/applications/validation-rules", applicationEnhanced.GetValidationRules)
@@ -227,12 +225,21 @@ func setupProtectedRoutes(protected fiber.Router,
 	// Interview routes
 	setupInterviewRoutes(protected, interviewHandler)
 
+	// Document version, link and bulk upload routes; registered before the
+	// officer-only middleware of the document group so students can use them
+	setupDocumentVersionRoutes(protected, cfg)
+	setupDocumentLinkRoutes(protected, cfg)
+	setupBulkUploadRoutes(protected, cfg)
+
 	// Document routes
 	setupDocumentRoutes(protected, documentHandler)
 
 	// Enhanced document routes
 	setupEnhancedDocumentRoutes(protected, docEnhancedHandler)
 
+	// Resumable chunked uploads
+	setupResumableUploadRoutes(protected, cfg)
+
 	// Allocation routes
 	setupAllocationRoutes(protected, allocationHandler)
 
@@ -292,18 +299,64 @@ func setupApplicationRoutes(protected fiber.Router, applicationHandler *handlers
 	// Student application routes
 	applications.Post("/", middleware.RequireRole("student"), applicationHandler.CreateApplication)
 	applications.Get("/my", middleware.RequireRole("student"), applicationHandler.GetMyApplications)
+
+	// Full-text search (registered before /:id so "search" is not taken as an ID)
+	searchHandler := handlers.NewApplicationSearchHandler(cfg)
+	applications.Get("/search", middleware.RequireRole("admin", "scholarship_officer", "interviewer", "advisor", "committee_member"), searchHandler.SearchApplications)
+	protected.Post("/admin/search/reindex", middleware.RequireRole("admin", "scholarship_officer"), searchHandler.Reindex)
+
 	applications.Get("/:id", applicationHandler.GetApplication)
 	applications.Put("/:id", applicationHandler.UpdateApplication)
 	applications.Post("/:id/submit", middleware.RequireRole("student"), applicationHandler.SubmitApplication)
 	applications.Delete("/:id", applicationHandler.DeleteApplication)
 
+	// Document bundles and committee packs (registered before the student-only
+	// details group, which covers every path under /:id)
+	setupDocumentBundleRoutes(protected, applications, cfg)
+
+	// Document checklist and issue dates (registered before the student-only
+	// details group and the officer-only document routes)
+	setupDocumentChecklistRoutes(protected, applications, cfg)
+
 	// Application Details routes (Student only)
 	setupApplicationDetailsRoutes(applications, middleware.RequireRole("student"), cfg)
 
+	// Revision routes (send back / resubmit)
+	setupApplicationRevisionRoutes(protected, applications, cfg)
+
+	// Financial need assessment routes
+	setupNeedAssessmentRoutes(protected, cfg)
+
+	// Priority scoring profile routes
+	setupScoringRoutes(protected, cfg)
+
+	// Duplicate and fraud signal routes
+	setupRedFlagRoutes(protected, cfg)
+
+	// Document retention routes
+	setupRetentionRoutes(protected, cfg)
+
+	// Storage reconciliation routes
+	setupStorageReconcileRoutes(protected, cfg)
+
+	// Saved views and bulk action routes
+	setupApplicationViewRoutes(protected, cfg)
+
+	// Withdrawal and award decline routes
+	withdrawalHandler := handlers.NewApplicationWithdrawalHandler(cfg)
+	applications.Post("/:id/withdraw", middleware.RequireRole("student"), withdrawalHandler.WithdrawApplication)
+	applications.Post("/:id/decline", middleware.RequireRole("student"), withdrawalHandler.DeclineAward)
+
 	// Draft Application routes
 	applications.Post("/draft", middleware.RequireRole("student"), draftHandler.CreateDraft)
 	applications.Get("/draft", middleware.RequireRole("student"), draftHandler.GetDraft)
 
+	// Reapplication prefill routes
+	prefillHandler := handlers.NewApplicationPrefillHandler(cfg)
+	applications.Get("/draft/sources", middleware.RequireRole("student"), prefillHandler.ListSources)
+	applications.Post("/draft/from-previous", middleware.RequireRole("student"), prefillHandler.CreateFromPrevious)
+	applications.Get("/:id/prefill", middleware.RequireRole("student"), prefillHandler.GetPrefill)
+
 	// Section Save routes
 	applications.Post("/:id/sections/:section_name", middleware.RequireRole("student"), sectionHandler.SaveSection)
 
@@ -329,14 +382,14 @@ func setupAdminApplicationRoutes(protected fiber.Router, applicationHandler *han
 	adminApplications.Delete("/:id", applicationHandler.DeleteApplication)
 }
 
-// setupInterviewRoutes configures interview management routes
+// setupInterviewRoutes configures the original interview routes, which now
+// work on the interview slots and bookings served under /interview
 func setupInterviewRoutes(protected fiber.Router, interviewHandler *handlers.InterviewHandler) {
 	interviews := protected.Group("/interviews")
 
 	// Interview management (officers/admins)
-	interviewAdmin := interviews.Use(middleware.RequireRole("admin", "scholarship_officer"))
-	interviewAdmin.Post("/schedules", interviewHandler.CreateSchedule)
-	interviewAdmin.Get("/schedules", interviewHandler.GetSchedules)
+	interviews.Post("/schedules", middleware.RequireRole("admin", "scholarship_officer"), interviewHandler.CreateSchedule)
+	interviews.Get("/schedules", middleware.RequireRole("admin", "scholarship_officer"), interviewHandler.GetSchedules)
 
 	// Student interview routes
 	interviews.Post("/applications/:application_id/schedules/:schedule_id/book",
@@ -358,6 +411,7 @@ func setupDocumentRoutes(protected fiber.Router, documentHandler *handlers.Docum
 		middleware.RequireRole("student"), documentHandler.UploadDocument)
 	documents.Get("/applications/:application_id", documentHandler.GetDocuments)
 	documents.Get("/:document_id/download", documentHandler.DownloadDocument)
+	documents.Get("/:document_id/preview", documentHandler.PreviewDocument)
 	documents.Delete("/:document_id", middleware.RequireRole("student"), documentHandler.DeleteDocument)
 	documents.Get("/types", documentHandler.GetDocumentTypes)
 	documents.Get("/stats", documentHandler.GetDocumentStats)
@@ -368,6 +422,39 @@ func setupDocumentRoutes(protected fiber.Router, documentHandler *handlers.Docum
 	documentAdmin.Post("/bulk-verify", documentHandler.BulkVerifyDocuments)
 }
 
+// setupDocumentVersionRoutes configures document version history routes
+func setupDocumentVersionRoutes(protected fiber.Router, cfg *config.Config) {
+	versionHandler := handlers.NewDocumentVersionHandler(cfg)
+
+	versions := protected.Group("/documents/:document_id/versions")
+	versions.Get("/", versionHandler.ListVersions)
+	versions.Get("/diff", versionHandler.DiffVersions)
+	versions.Get("/:version/download", versionHandler.DownloadVersion)
+}
+
+// setupDocumentLinkRoutes configures signed document links and the document
+// access log
+func setupDocumentLinkRoutes(protected fiber.Router, cfg *config.Config) {
+	linkHandler := handlers.NewDocumentLinkHandler(cfg)
+
+	protected.Post("/documents/:document_id/link", linkHandler.CreateLink)
+	protected.Get("/admin/applications/:id/document-access",
+		middleware.RequireRole("admin", "scholarship_officer"), linkHandler.ListAccess)
+}
+
+// setupBulkUploadRoutes configures bulk document upload sessions and
+// officer document imports
+func setupBulkUploadRoutes(protected fiber.Router, cfg *config.Config) {
+	bulkHandler := handlers.NewBulkUploadHandler(cfg)
+
+	protected.Post("/documents/bulk-upload", middleware.RequireRole("student"), bulkHandler.StartBulkUpload)
+	protected.Post("/documents/bulk-upload/files", middleware.RequireRole("student"), bulkHandler.UploadBulkFiles)
+	protected.Post("/documents/bulk-upload/complete", middleware.RequireRole("student"), bulkHandler.CompleteBulkUpload)
+	protected.Get("/documents/bulk-upload/progress", bulkHandler.GetUploadProgress)
+	protected.Post("/documents/bulk-import",
+		middleware.RequireRole("admin", "scholarship_officer"), bulkHandler.ImportDocuments)
+}
+
 // setupEnhancedDocumentRoutes configures enhanced document management routes
 func setupEnhancedDocumentRoutes(protected fiber.Router, docEnhancedHandler *handlers.DocumentEnhancedHandler) {
 	// Enhanced document routes
@@ -376,6 +463,20 @@ func setupEnhancedDocumentRoutes(protected fiber.Router, docEnhancedHandler *han
 	protected.Get("/documents/:id/download-enhanced", docEnhancedHandler.DownloadDocument)
 }
 
+// setupResumableUploadRoutes configures chunked document uploads
+func setupResumableUploadRoutes(protected fiber.Router, cfg *config.Config) {
+	uploadHandler := handlers.NewResumableUploadHandler(cfg)
+
+	uploads := protected.Group("/uploads", middleware.RequireRole("student"))
+	uploads.Post("/", uploadHandler.CreateUpload)
+	uploads.Get("/sessions/:session_token", uploadHandler.GetSessionProgress)
+	// HEAD is registered first; Get would otherwise answer it
+	uploads.Head("/:upload_id", uploadHandler.UploadOffset)
+	uploads.Get("/:upload_id", uploadHandler.GetUpload)
+	uploads.Patch("/:upload_id", uploadHandler.UploadChunk)
+	uploads.Post("/:upload_id/finalize", uploadHandler.FinalizeUpload)
+}
+
 // setupAllocationRoutes configures allocation management routes
 func setupAllocationRoutes(protected fiber.Router, allocationHandler *handlers.AllocationHandler) {
 	allocations := protected.Group("/allocations", middleware.RequireRole("admin", "scholarship_officer"))
@@ -533,6 +634,148 @@ func setupAnalyticsRoutes(protected fiber.Router, analyticsHandler *handlers.Ana
 	analytics.Get("/dashboard", analyticsHandler.GetDashboardSummary)
 }
 
+// setupApplicationRevisionRoutes configures the send-back-for-revision loop
+func setupApplicationRevisionRoutes(protected fiber.Router, applications fiber.Router, cfg *config.Config) {
+	revisionHandler := handlers.NewApplicationRevisionHandler(cfg)
+
+	// Student: see what to fix and resubmit
+	applications.Get("/:id/revision", middleware.RequireRole("student"), revisionHandler.GetCurrentRevision)
+	applications.Post("/:id/resubmit", middleware.RequireRole("student"), revisionHandler.Resubmit)
+
+	// Officer: send back and review history
+	adminRevisions := protected.Group("/admin/applications", middleware.RequireRole("admin", "scholarship_officer"))
+	adminRevisions.Post("/:id/request-revision", revisionHandler.RequestRevision)
+	adminRevisions.Get("/:id/revisions", revisionHandler.ListRevisions)
+
+	// Officer: the form as submitted and how it changed since
+	snapshotHandler := handlers.NewApplicationSnapshotHandler(cfg)
+	adminRevisions.Get("/:id/form", snapshotHandler.GetSubmittedForm)
+	adminRevisions.Get("/:id/snapshots", snapshotHandler.ListSnapshots)
+	adminRevisions.Get("/:id/snapshots/diff", snapshotHandler.DiffSnapshots)
+}
+
+// setupNeedAssessmentRoutes configures the household financial need calculator
+func setupNeedAssessmentRoutes(protected fiber.Router, cfg *config.Config) {
+	needHandler := handlers.NewNeedAssessmentHandler(cfg)
+
+	needParameters := protected.Group("/admin/need-assessment", middleware.RequireRole("admin", "scholarship_officer"))
+	needParameters.Get("/parameters", needHandler.GetParameters)
+	needParameters.Put("/parameters", middleware.RequireRole("admin"), needHandler.UpdateParameters)
+
+	needAssessments := protected.Group("/admin/applications", middleware.RequireRole("admin", "scholarship_officer"))
+	needAssessments.Get("/:id/need-assessment", needHandler.GetAssessment)
+	needAssessments.Post("/:id/need-assessment", needHandler.RecalculateAssessment)
+}
+
+// setupScoringRoutes configures priority scoring profiles and application scores
+func setupScoringRoutes(protected fiber.Router, cfg *config.Config) {
+	scoringHandler := handlers.NewScoringProfileHandler(cfg)
+
+	profiles := protected.Group("/admin/scoring-profiles", middleware.RequireRole("admin", "scholarship_officer"))
+	profiles.Get("/", scoringHandler.ListProfiles)
+	profiles.Post("/", scoringHandler.CreateProfile)
+	profiles.Post("/recalculate", scoringHandler.RecalculateScholarship)
+	profiles.Get("/:id", scoringHandler.GetProfile)
+	profiles.Put("/:id", scoringHandler.UpdateProfile)
+	profiles.Delete("/:id", scoringHandler.DeleteProfile)
+
+	scores := protected.Group("/admin/applications", middleware.RequireRole("admin", "scholarship_officer"))
+	scores.Get("/:id/priority-score", scoringHandler.GetApplicationScore)
+	scores.Post("/:id/priority-score", scoringHandler.RecalculateApplicationScore)
+}
+
+// setupRetentionRoutes configures retention rules, runs and legal holds
+func setupRetentionRoutes(protected fiber.Router, cfg *config.Config) {
+	retentionHandler := handlers.NewRetentionHandler(cfg)
+
+	rules := protected.Group("/admin/retention-rules", middleware.RequireRole("admin"))
+	rules.Get("/", retentionHandler.ListRules)
+	rules.Post("/", retentionHandler.CreateRule)
+	rules.Put("/:id", retentionHandler.UpdateRule)
+	rules.Delete("/:id", retentionHandler.DeleteRule)
+
+	retention := protected.Group("/admin/retention", middleware.RequireRole("admin"))
+	retention.Get("/runs", retentionHandler.ListRuns)
+	retention.Post("/runs", retentionHandler.StartRun)
+	retention.Get("/runs/:id", retentionHandler.GetRun)
+	retention.Get("/log", retentionHandler.ListLog)
+
+	// A group here would put admin-only middleware on every later
+	// /admin/applications route
+	protected.Put("/admin/applications/:id/legal-hold", middleware.RequireRole("admin"), retentionHandler.SetLegalHold)
+}
+
+// setupStorageReconcileRoutes configures checks of stored files against
+// their records
+func setupStorageReconcileRoutes(protected fiber.Router, cfg *config.Config) {
+	reconcileHandler := handlers.NewStorageReconcileHandler(cfg)
+
+	reconciliations := protected.Group("/admin/storage/reconciliations", middleware.RequireRole("admin"))
+	reconciliations.Get("/", reconcileHandler.ListReconciliations)
+	reconciliations.Post("/", reconcileHandler.StartReconciliation)
+	reconciliations.Get("/:id", reconcileHandler.GetReconciliation)
+}
+
+// setupRedFlagRoutes configures triage of duplicate and fraud signals
+func setupRedFlagRoutes(protected fiber.Router, cfg *config.Config) {
+	redFlagHandler := handlers.NewRedFlagHandler(cfg)
+
+	redFlags := protected.Group("/admin/red-flags", middleware.RequireRole("admin", "scholarship_officer"))
+	redFlags.Get("/", redFlagHandler.ListRedFlags)
+	redFlags.Get("/scans", redFlagHandler.ListScans)
+	redFlags.Post("/scans", redFlagHandler.RunScan)
+	redFlags.Get("/:id", redFlagHandler.GetRedFlag)
+	redFlags.Put("/:id", redFlagHandler.TriageRedFlag)
+
+	applicationFlags := protected.Group("/admin/applications", middleware.RequireRole("admin", "scholarship_officer"))
+	applicationFlags.Get("/:id/red-flags", redFlagHandler.GetApplicationRedFlags)
+}
+
+// setupApplicationViewRoutes configures the officer application list with
+// saved views and bulk actions
+func setupApplicationViewRoutes(protected fiber.Router, cfg *config.Config) {
+	viewHandler := handlers.NewApplicationViewHandler(cfg)
+
+	views := protected.Group("/admin/application-views", middleware.RequireRole("admin", "scholarship_officer"))
+	views.Get("/", viewHandler.ListViews)
+	views.Post("/", viewHandler.CreateView)
+	views.Put("/:id", viewHandler.UpdateView)
+	views.Delete("/:id", viewHandler.DeleteView)
+
+	applicationList := protected.Group("/admin/applications", middleware.RequireRole("admin", "scholarship_officer"))
+	applicationList.Post("/query", viewHandler.QueryApplications)
+	applicationList.Post("/bulk", viewHandler.StartBulkAction)
+
+	bulkJobs := protected.Group("/admin/bulk-jobs", middleware.RequireRole("admin", "scholarship_officer"))
+	bulkJobs.Get("/", viewHandler.ListBulkJobs)
+	bulkJobs.Get("/:id", viewHandler.GetBulkJob)
+	bulkJobs.Get("/:id/export", viewHandler.DownloadExport)
+}
+
+// setupDocumentBundleRoutes configures per-application document bundles and
+// committee pack exports
+func setupDocumentBundleRoutes(protected fiber.Router, applications fiber.Router, cfg *config.Config) {
+	bundleHandler := handlers.NewDocumentBundleHandler(cfg)
+	reviewers := middleware.RequireRole("admin", "scholarship_officer", "committee_member")
+
+	applications.Get("/:id/document-bundle", reviewers, bundleHandler.DownloadBundle)
+
+	packs := protected.Group("/committee-packs", reviewers)
+	packs.Get("/", bundleHandler.ListCommitteePacks)
+	packs.Post("/", bundleHandler.StartCommitteePack)
+	packs.Get("/:id", bundleHandler.GetCommitteePack)
+	packs.Get("/:id/download", bundleHandler.DownloadCommitteePack)
+}
+
+// setupDocumentChecklistRoutes configures the per-scholarship document
+// checklist of an application
+func setupDocumentChecklistRoutes(protected fiber.Router, applications fiber.Router, cfg *config.Config) {
+	checklistHandler := handlers.NewDocumentChecklistHandler(cfg)
+
+	applications.Get("/:id/document-checklist", checklistHandler.GetChecklist)
+	protected.Put("/documents/:document_id/issued-date", middleware.RequireRole("student"), checklistHandler.SetIssuedDate)
+}
+
 // setupApplicationDetailsRoutes configures detailed application form routes
 func setupApplicationDetailsRoutes(applications fiber.Router, roleMiddleware fiber.Handler, cfg *config.Config) {
 	// Initialize application details handler
@@ -0,0 +1,55 @@
+package router
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scholarship-system/internal/config"
+)
+
+// newRoleApp mounts the retention and application view routes behind a
+// stand-in for the JWT middleware that grants the given role
+func newRoleApp(role string) *fiber.App {
+	app := fiber.New()
+	protected := app.Group("/api/v1", func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.New())
+		c.Locals("roles", []string{role})
+		return c.Next()
+	})
+	cfg := &config.Config{}
+	setupRetentionRoutes(protected, cfg)
+	setupApplicationViewRoutes(protected, cfg)
+	return app
+}
+
+func TestRetentionRoutesKeepOfficerApplicationRoutes(t *testing.T) {
+	tests := []struct {
+		name   string
+		role   string
+		method string
+		path   string
+		want   int
+	}{
+		// An unparseable body stops the handler before it reaches the database
+		{"officer queries applications", "scholarship_officer", fiber.MethodPost, "/api/v1/admin/applications/query", fiber.StatusBadRequest},
+		{"officer starts bulk action", "scholarship_officer", fiber.MethodPost, "/api/v1/admin/applications/bulk", fiber.StatusBadRequest},
+		{"officer sets legal hold", "scholarship_officer", fiber.MethodPut, "/api/v1/admin/applications/1/legal-hold", fiber.StatusForbidden},
+		{"admin sets legal hold", "admin", fiber.MethodPut, "/api/v1/admin/applications/1/legal-hold", fiber.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader("{"))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := newRoleApp(tt.role).Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"scholarship-system/internal/models"
+)
+
+// ApplicationTransitions are the status changes officers can make. Drafts,
+// revisions, withdrawals and declines have their own endpoints and are not
+// reachable from here.
+var ApplicationTransitions = map[string][]string{
+	"submitted":           {"under_review", "rejected"},
+	"under_review":        {"interview_scheduled", "approved", "rejected"},
+	"interview_scheduled": {"under_review", "approved", "rejected"},
+	"approved":            {"completed"},
+}
+
+// ReviewStages are the stages of application_reviews
+var ReviewStages = []string{"initial_screening", "document_verification", "eligibility_check", "final_review"}
+
+// ApplicationSortColumns are the columns the officer list can be sorted by
+var ApplicationSortColumns = []string{"submitted_at", "gpa", "family_income", "year_level", "student_id", "application_status"}
+
+// maxBulkApplications caps the selection of one bulk action
+const maxBulkApplications = 5000
+
+// CheckStatusTransition returns an error when an officer may not move an
+// application from one status to the other
+func CheckStatusTransition(from, to string) error {
+	if contains(ApplicationTransitions[from], to) {
+		return nil
+	}
+	if len(ApplicationTransitions[from]) == 0 {
+		return fmt.Errorf("status %s cannot be changed here", from)
+	}
+	return fmt.Errorf("cannot change status from %s to %s; allowed: %s",
+		from, to, strings.Join(ApplicationTransitions[from], ", "))
+}
+
+// ValidateApplicationFilter checks the values and ranges of a filter
+func ValidateApplicationFilter(filter *models.ApplicationFilter) error {
+	if filter == nil {
+		return nil
+	}
+	for _, status := range filter.Statuses {
+		if !knownApplicationStatus(status) {
+			return fmt.Errorf("unknown status %q", status)
+		}
+	}
+	for _, stage := range filter.ReviewStages {
+		if !contains(ReviewStages, stage) {
+			return fmt.Errorf("unknown review stage %q", stage)
+		}
+	}
+	switch filter.DocumentStatus {
+	case "", models.DocumentsAllVerified, models.DocumentsPending, models.DocumentsRejected, models.DocumentsMissing:
+	default:
+		return fmt.Errorf("unknown document status %q", filter.DocumentStatus)
+	}
+	if filter.GPAMin != nil && filter.GPAMax != nil && *filter.GPAMin > *filter.GPAMax {
+		return fmt.Errorf("gpa_min is greater than gpa_max")
+	}
+	if filter.IncomeMin != nil && filter.IncomeMax != nil && *filter.IncomeMin > *filter.IncomeMax {
+		return fmt.Errorf("income_min is greater than income_max")
+	}
+	if filter.SubmittedFrom != nil && filter.SubmittedTo != nil && filter.SubmittedFrom.After(*filter.SubmittedTo) {
+		return fmt.Errorf("submitted_from is after submitted_to")
+	}
+	return nil
+}
+
+// ValidateApplicationSort checks a sort column; "" means the default
+func ValidateApplicationSort(sortBy string) error {
+	if sortBy != "" && !contains(ApplicationSortColumns, sortBy) {
+		return fmt.Errorf("cannot sort by %q; use one of %s", sortBy, strings.Join(ApplicationSortColumns, ", "))
+	}
+	return nil
+}
+
+// ValidateBulkAction checks the parameters of a bulk action. The selection is
+// checked separately once it is resolved.
+func ValidateBulkAction(req *models.BulkActionRequest) error {
+	switch req.Action {
+	case models.BulkAssignReviewer:
+		if req.ReviewerID == nil {
+			return fmt.Errorf("reviewer_id is required")
+		}
+		if req.ReviewStage == "" {
+			req.ReviewStage = ReviewStages[0]
+		}
+		if !contains(ReviewStages, req.ReviewStage) {
+			return fmt.Errorf("unknown review stage %q", req.ReviewStage)
+		}
+	case models.BulkChangeStatus:
+		if !reachableStatus(req.Status) {
+			return fmt.Errorf("status must be one of %s", strings.Join(reachableStatuses(), ", "))
+		}
+	case models.BulkNotify:
+		if strings.TrimSpace(req.Title) == "" || strings.TrimSpace(req.Message) == "" {
+			return fmt.Errorf("title and message are required")
+		}
+	case models.BulkExport:
+	default:
+		return fmt.Errorf("unknown action %q", req.Action)
+	}
+	if len(req.ApplicationIDs) == 0 && req.ViewID == nil && req.Filter == nil {
+		return fmt.Errorf("select applications with application_ids, view_id or filter")
+	}
+	return ValidateApplicationFilter(req.Filter)
+}
+
+// CheckBulkSelection checks the size of a resolved selection
+func CheckBulkSelection(ids []uint) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("no applications selected")
+	}
+	if len(ids) > maxBulkApplications {
+		return fmt.Errorf("%d applications selected; a bulk action can handle at most %d", len(ids), maxBulkApplications)
+	}
+	return nil
+}
+
+// csvText makes a text cell safe to open in a spreadsheet. Cells starting
+// with a character that spreadsheets read as a formula get a leading quote,
+// so a name like =HYPERLINK(...) is shown as text instead of being run.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// WriteApplicationsCSV writes the officer list as CSV
+func WriteApplicationsCSV(w io.Writer, items []models.ApplicationListItem) error {
+	writer := csv.NewWriter(w)
+	header := []string{
+		"application_id", "student_id", "student_name", "scholarship", "status", "faculty", "year_level",
+		"gpa", "family_income", "documents_verified", "documents_total", "review_stage", "open_red_flags", "submitted_at",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		record := []string{
+			strconv.FormatUint(uint64(item.ApplicationID), 10),
+			csvText(item.StudentID),
+			csvText(item.StudentName),
+			csvText(item.ScholarshipName),
+			csvText(item.ApplicationStatus),
+			csvText(item.Faculty),
+			"", "", "",
+			strconv.Itoa(item.DocumentsVerified),
+			strconv.Itoa(item.DocumentsTotal),
+			"",
+			strconv.Itoa(item.OpenRedFlags),
+			"",
+		}
+		if item.YearLevel != nil {
+			record[6] = strconv.Itoa(*item.YearLevel)
+		}
+		if item.GPA != nil {
+			record[7] = strconv.FormatFloat(*item.GPA, 'f', 2, 64)
+		}
+		if item.FamilyIncome != nil {
+			record[8] = strconv.FormatFloat(*item.FamilyIncome, 'f', 2, 64)
+		}
+		if item.ReviewStage != nil {
+			record[11] = csvText(*item.ReviewStage)
+		}
+		if item.SubmittedAt != nil {
+			record[13] = item.SubmittedAt.Format("2006-01-02 15:04:05")
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func knownApplicationStatus(status string) bool {
+	switch status {
+	case "draft", "needs_revision", "withdrawn", "declined":
+		return true
+	}
+	_, ok := ApplicationTransitions[status]
+	return ok || reachableStatus(status)
+}
+
+func reachableStatus(status string) bool {
+	return contains(reachableStatuses(), status)
+}
+
+func reachableStatuses() []string {
+	statuses := []string{}
+	for _, from := range []string{"submitted", "under_review", "interview_scheduled", "approved"} {
+		for _, to := range ApplicationTransitions[from] {
+			if !contains(statuses, to) {
+				statuses = append(statuses, to)
+			}
+		}
+	}
+	return statuses
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scholarship-system/internal/models"
+)
+
+func TestCheckStatusTransition(t *testing.T) {
+	assert.NoError(t, CheckStatusTransition("submitted", "under_review"))
+	assert.NoError(t, CheckStatusTransition("interview_scheduled", "approved"))
+
+	err := CheckStatusTransition("submitted", "completed")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "allowed: under_review, rejected")
+
+	err = CheckStatusTransition("withdrawn", "under_review")
+	require.Error(t, err)
+	assert.Equal(t, "status withdrawn cannot be changed here", err.Error())
+}
+
+func TestValidateApplicationFilter(t *testing.T) {
+	low, high := 2.5, 3.5
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	assert.NoError(t, ValidateApplicationFilter(nil))
+	assert.NoError(t, ValidateApplicationFilter(&models.ApplicationFilter{
+		Statuses: []string{"submitted", "needs_revision"}, GPAMin: &low, GPAMax: &high,
+		ReviewStages: []string{"final_review"}, DocumentStatus: models.DocumentsPending,
+		SubmittedFrom: &from, SubmittedTo: &to,
+	}))
+
+	assert.EqualError(t, ValidateApplicationFilter(&models.ApplicationFilter{Statuses: []string{"archived"}}), `unknown status "archived"`)
+	assert.EqualError(t, ValidateApplicationFilter(&models.ApplicationFilter{GPAMin: &high, GPAMax: &low}), "gpa_min is greater than gpa_max")
+	assert.EqualError(t, ValidateApplicationFilter(&models.ApplicationFilter{DocumentStatus: "lost"}), `unknown document status "lost"`)
+	assert.EqualError(t, ValidateApplicationFilter(&models.ApplicationFilter{SubmittedFrom: &to, SubmittedTo: &from}), "submitted_from is after submitted_to")
+}
+
+func TestValidateBulkAction(t *testing.T) {
+	reviewer := uuid.New()
+
+	req := &models.BulkActionRequest{Action: models.BulkAssignReviewer, ReviewerID: &reviewer, ApplicationIDs: []uint{1}}
+	require.NoError(t, ValidateBulkAction(req))
+	assert.Equal(t, "initial_screening", req.ReviewStage)
+
+	assert.EqualError(t, ValidateBulkAction(&models.BulkActionRequest{Action: models.BulkChangeStatus, Status: "draft", ApplicationIDs: []uint{1}}),
+		"status must be one of under_review, rejected, interview_scheduled, approved, completed")
+	assert.EqualError(t, ValidateBulkAction(&models.BulkActionRequest{Action: models.BulkNotify, Title: "x", ApplicationIDs: []uint{1}}),
+		"title and message are required")
+	assert.EqualError(t, ValidateBulkAction(&models.BulkActionRequest{Action: models.BulkExport}),
+		"select applications with application_ids, view_id or filter")
+	assert.EqualError(t, ValidateBulkAction(&models.BulkActionRequest{Action: "delete"}), `unknown action "delete"`)
+
+	assert.Error(t, CheckBulkSelection(nil))
+	assert.Error(t, CheckBulkSelection(make([]uint, maxBulkApplications+1)))
+}
+
+func TestWriteApplicationsCSV(t *testing.T) {
+	gpa, year := 3.456, 2
+	stage, minus := "final_review", "-1"
+	submitted := time.Date(2025, 3, 4, 9, 30, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	err := WriteApplicationsCSV(&buf, []models.ApplicationListItem{
+		{ApplicationID: 12, StudentID: "6401001", StudentName: "สมชาย ใจดี, Jr.", ScholarshipName: "ทุนเรียนดี",
+			ApplicationStatus: "under_review", Faculty: "Economics", YearLevel: &year, GPA: &gpa,
+			DocumentsTotal: 4, DocumentsVerified: 3, ReviewStage: &stage, OpenRedFlags: 1, SubmittedAt: &submitted},
+		{ApplicationID: 13, StudentID: "6401002"},
+		{ApplicationID: 14, StudentID: "6401003", StudentName: `=HYPERLINK("http://evil.example","x")`,
+			ScholarshipName: "+ทุน", Faculty: "@SUM(A1)", ReviewStage: &minus},
+	})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[0], "application_id,student_id,student_name"))
+	assert.Equal(t, `12,6401001,"สมชาย ใจดี, Jr.",ทุนเรียนดี,under_review,Economics,2,3.46,,3,4,final_review,1,2025-03-04 09:30:00`, lines[1])
+	assert.Equal(t, "13,6401002,,,,,,,,0,0,,0,", lines[2])
+	assert.Equal(t, `14,6401003,"'=HYPERLINK(""http://evil.example"",""x"")",'+ทุน,,'@SUM(A1),,,,0,0,'-1,0,`, lines[3])
+}
@@ -0,0 +1,447 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"scholarship-system/internal/models"
+)
+
+// ShortlistStatuses are the statuses of applications that passed screening
+// and wait for the committee; a committee pack holds these by default
+var ShortlistStatuses = []string{"under_review", "interview_scheduled"}
+
+// maxBundleDocumentSize caps a document read into a merged PDF
+const maxBundleDocumentSize = 100 << 20
+
+// BundleOpener opens the stored file of a document
+type BundleOpener func(documentID int) (io.ReadCloser, error)
+
+// formSections is the order sections of the application form are printed
+// in; any others follow alphabetically
+var formSections = []string{
+	"application", "personal_info", "addresses", "education_history", "family_members", "assets",
+	"guardians", "siblings", "living_situation", "financial_info", "scholarship_history",
+	"activities", "references", "health_info", "funding_needs", "house_documents", "income_certificates",
+}
+
+// bundleNamePart makes s safe as part of a file name inside a bundle
+func bundleNamePart(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(s))
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+// BundleFileName names a document in a bundle by student and document type,
+// e.g. 6401001_id_card.pdf. Further documents of the same type are numbered
+// from 2.
+func BundleFileName(studentID, documentType, mimeType, originalName string, n int) string {
+	ext := ""
+	switch mimeType {
+	case "application/pdf":
+		ext = ".pdf"
+	case "image/jpeg":
+		ext = ".jpg"
+	case "image/png":
+		ext = ".png"
+	default:
+		if e := strings.ToLower(path.Ext(originalName)); len(e) > 1 {
+			ext = "." + bundleNamePart(e[1:])
+		}
+	}
+	name := bundleNamePart(studentID) + "_" + bundleNamePart(documentType)
+	if n > 1 {
+		name += "_" + strconv.Itoa(n)
+	}
+	return name + ext
+}
+
+// BundleFormFile is the name of the application form PDF in a bundle
+func BundleFormFile(studentID string) string {
+	return bundleNamePart(studentID) + "_application_form.pdf"
+}
+
+// BundleFolder names the folder or merged PDF of an application in a
+// committee pack, e.g. 6401001_42
+func BundleFolder(studentID string, applicationID uint) string {
+	return fmt.Sprintf("%s_%d", bundleNamePart(studentID), applicationID)
+}
+
+// PlanDocumentBundle lists the documents of an application under the names
+// they get in its bundle. Documents held by the malware scan are listed but
+// left out.
+func PlanDocumentBundle(studentID string, documents []models.ApplicationDocument) []models.BundleManifestEntry {
+	sorted := append([]models.ApplicationDocument(nil), documents...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].DocumentType != sorted[j].DocumentType {
+			return sorted[i].DocumentType < sorted[j].DocumentType
+		}
+		return sorted[i].UploadedAt.Before(sorted[j].UploadedAt)
+	})
+
+	counts := map[string]int{}
+	entries := make([]models.BundleManifestEntry, 0, len(sorted))
+	for _, doc := range sorted {
+		entry := models.BundleManifestEntry{
+			DocumentID:    doc.DocumentID,
+			DocumentType:  doc.DocumentType,
+			DocumentName:  doc.DocumentName,
+			VersionNumber: doc.VersionNumber,
+			MimeType:      doc.MimeType,
+			FileSize:      doc.FileSize,
+			SHA256:        doc.FileHash,
+			Status:        doc.UploadStatus,
+			UploadedAt:    doc.UploadedAt,
+		}
+		switch doc.UploadStatus {
+		case models.DocumentQuarantined:
+			entry.Note = "left out: still being scanned for malware"
+		case models.DocumentInfected:
+			entry.Note = "left out: malware was found"
+		case models.DocumentPurged:
+			entry.Note = "left out: deleted at the end of its retention period"
+		case models.DocumentMissing:
+			entry.Note = "left out: the file is missing or damaged"
+		default:
+			counts[doc.DocumentType]++
+			entry.File = BundleFileName(studentID, doc.DocumentType, doc.MimeType, doc.DocumentName, counts[doc.DocumentType])
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// humanizeKey turns a JSON key such as "family_income" into "Family income"
+func humanizeKey(key string) string {
+	s := strings.ReplaceAll(key, "_", " ")
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// RenderApplicationForm sets out an application form, as stored in a
+// submission snapshot, after a heading naming the application
+func RenderApplicationForm(pdf *PDFDocument, manifest *models.DocumentBundleManifest, form json.RawMessage) error {
+	version := "live form; the application has not been submitted"
+	if manifest.FormVersion > 0 {
+		version = fmt.Sprintf("submission %d", manifest.FormVersion)
+	}
+	pdf.AddText([]PDFTextLine{
+		{Text: "Application form", Size: 16},
+		{Text: "Scholarship: " + manifest.ScholarshipName},
+		{Text: fmt.Sprintf("Student: %s (%s)", manifest.StudentName, manifest.StudentID)},
+		{Text: fmt.Sprintf("Application %d, status %s", manifest.ApplicationID, manifest.ApplicationStatus)},
+		{Text: "Form: " + version},
+		{},
+	})
+
+	var sections map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(form))
+	decoder.UseNumber()
+	if err := decoder.Decode(&sections); err != nil {
+		return fmt.Errorf("failed to decode application form: %w", err)
+	}
+
+	keys := make([]string, 0, len(sections))
+	for key := range sections {
+		if !contains(formSections, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range append(append([]string{}, formSections...), keys...) {
+		value, ok := sections[key]
+		if !ok || isEmptyFormValue(value) {
+			continue
+		}
+		lines := []PDFTextLine{{Text: humanizeKey(key), Size: 13}}
+		lines = appendFormValue(lines, value, 0)
+		pdf.AddText(append(lines, PDFTextLine{}))
+	}
+	return nil
+}
+
+func isEmptyFormValue(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		for _, item := range v {
+			if !isEmptyFormValue(item) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func formScalar(v interface{}) string {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return "Yes"
+		}
+		return "No"
+	case json.Number:
+		return v.String()
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+// appendFormValue lays out the fields of a form section, indenting nested
+// objects and numbering list items
+func appendFormValue(lines []PDFTextLine, v interface{}, depth int) []PDFTextLine {
+	indent := float64(depth) * 14
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			if key != "created_at" && key != "updated_at" && !isEmptyFormValue(v[key]) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			switch value := v[key].(type) {
+			case map[string]interface{}, []interface{}:
+				lines = append(lines, PDFTextLine{Text: humanizeKey(key) + ":", Indent: indent})
+				lines = appendFormValue(lines, value, depth+1)
+			default:
+				lines = append(lines, PDFTextLine{Text: humanizeKey(key) + ": " + formScalar(value), Indent: indent})
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				lines = append(lines, PDFTextLine{Text: fmt.Sprintf("%d.", i+1), Indent: indent})
+				lines = appendFormValue(lines, item, depth+1)
+			default:
+				lines = append(lines, PDFTextLine{Text: fmt.Sprintf("%d. %s", i+1, formScalar(item)), Indent: indent})
+			}
+		}
+	default:
+		lines = append(lines, PDFTextLine{Text: formScalar(v), Indent: indent})
+	}
+	return lines
+}
+
+// ApplicationFormPDF renders the application form on its own
+func ApplicationFormPDF(font *PDFFont, manifest *models.DocumentBundleManifest, form json.RawMessage) ([]byte, error) {
+	pdf := NewPDFDocument(font)
+	if err := RenderApplicationForm(pdf, manifest, form); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := pdf.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteBundleZip adds the bundle of an application to a ZIP under dir: its
+// documents, the form PDF and manifest.json. Documents that cannot be read
+// are left out and noted in the manifest; only errors writing the ZIP are
+// returned.
+func WriteBundleZip(zw *zip.Writer, dir string, manifest *models.DocumentBundleManifest, formPDF []byte, open BundleOpener) error {
+	for i := range manifest.Documents {
+		entry := &manifest.Documents[i]
+		if entry.File == "" {
+			continue
+		}
+		reader, err := open(entry.DocumentID)
+		if err != nil {
+			entry.File, entry.Note = "", "left out: the file could not be read"
+			continue
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: dir + entry.File, Method: zip.Deflate, Modified: entry.UploadedAt})
+		if err == nil {
+			_, err = io.Copy(w, reader)
+		}
+		reader.Close()
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", entry.File, err)
+		}
+	}
+
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: dir + manifest.FormFile, Method: zip.Deflate, Modified: manifest.GeneratedAt})
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(formPDF); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	w, err = zw.CreateHeader(&zip.FileHeader{Name: dir + "manifest.json", Method: zip.Deflate, Modified: manifest.GeneratedAt})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// WriteBundlePDF merges the bundle of an application into one PDF: a cover
+// page listing the documents, the form, then each document. PDFs keep their
+// pages and images get a page each; a document that cannot be merged is
+// replaced by a page saying so.
+func WriteBundlePDF(w io.Writer, font *PDFFont, manifest *models.DocumentBundleManifest, form json.RawMessage, open BundleOpener) error {
+	pdf := NewPDFDocument(font)
+	if err := RenderApplicationForm(pdf, manifest, form); err != nil {
+		return err
+	}
+	pdf.EndPage()
+
+	for i := range manifest.Documents {
+		entry := &manifest.Documents[i]
+		if entry.File == "" {
+			continue
+		}
+		if err := addBundleDocument(pdf, entry, open); err != nil {
+			entry.Note = "could not be merged: " + err.Error()
+			pdf.AddText([]PDFTextLine{
+				{Text: entry.File, Size: 13},
+				{Text: "This document could not be merged into the PDF (" + err.Error() + "). It is in the ZIP bundle as uploaded."},
+			})
+			pdf.EndPage()
+		}
+	}
+
+	// The cover lists what could not be merged, so it is laid out last
+	start := pdf.PageCount()
+	writeBundleCover(pdf, manifest)
+	pdf.EndPage()
+	pdf.pages = append(pdf.pages[start:], pdf.pages[:start]...)
+	return pdf.Write(w)
+}
+
+func addBundleDocument(pdf *PDFDocument, entry *models.BundleManifestEntry, open BundleOpener) error {
+	data, err := readBundleDocument(open, entry.DocumentID)
+	if err != nil {
+		return err
+	}
+	if entry.MimeType == "application/pdf" {
+		_, err = pdf.AddPDF(data)
+		return err
+	}
+	return pdf.AddImage(data, entry.File)
+}
+
+func readBundleDocument(open BundleOpener, documentID int) ([]byte, error) {
+	reader, err := open(documentID)
+	if err != nil {
+		return nil, fmt.Errorf("the file could not be read")
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(io.LimitReader(reader, maxBundleDocumentSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxBundleDocumentSize {
+		return nil, fmt.Errorf("file is larger than %dMB", maxBundleDocumentSize>>20)
+	}
+	return data, nil
+}
+
+// writeBundleCover lists the application and its documents
+func writeBundleCover(pdf *PDFDocument, manifest *models.DocumentBundleManifest) {
+	lines := []PDFTextLine{
+		{Text: "Application documents", Size: 16},
+		{Text: "Scholarship: " + manifest.ScholarshipName},
+		{Text: fmt.Sprintf("Student: %s (%s)", manifest.StudentName, manifest.StudentID)},
+		{Text: fmt.Sprintf("Application %d, status %s", manifest.ApplicationID, manifest.ApplicationStatus)},
+		{Text: "Generated " + manifest.GeneratedAt.Format("2006-01-02 15:04 MST")},
+		{},
+		{Text: "Documents", Size: 13},
+	}
+	if len(manifest.Documents) == 0 {
+		lines = append(lines, PDFTextLine{Text: "No documents were uploaded."})
+	}
+	for i, entry := range manifest.Documents {
+		name := entry.File
+		if name == "" {
+			name = entry.DocumentName
+		}
+		lines = append(lines, PDFTextLine{Text: fmt.Sprintf("%d. %s (%s, version %d, %s)",
+			i+1, name, entry.DocumentType, entry.VersionNumber, entry.Status)})
+		if entry.Note != "" {
+			lines = append(lines, PDFTextLine{Text: entry.Note, Indent: 14})
+		}
+	}
+	pdf.AddText(lines)
+}
+
+// WriteCommitteePackIndex writes the index of a committee pack as CSV
+func WriteCommitteePackIndex(w io.Writer, entries []models.CommitteePackEntry) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"application_id", "student_id", "student_name", "application_status", "path", "documents", "left_out", "error"})
+	for _, entry := range entries {
+		writer.Write([]string{
+			strconv.FormatUint(uint64(entry.ApplicationID), 10),
+			csvText(entry.StudentID),
+			csvText(entry.StudentName),
+			csvText(entry.ApplicationStatus),
+			csvText(entry.Path),
+			strconv.Itoa(entry.Documents),
+			strconv.Itoa(entry.LeftOut),
+			csvText(entry.Error),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// BundleFormat checks the format of a bundle, zip when not given
+func BundleFormat(format string) (string, error) {
+	switch format {
+	case "":
+		return models.BundleFormatZip, nil
+	case models.BundleFormatZip, models.BundleFormatPDF:
+		return format, nil
+	}
+	return "", fmt.Errorf("format must be %s or %s", models.BundleFormatZip, models.BundleFormatPDF)
+}
+
+// ValidateCommitteePack checks a committee pack request and fills in the
+// default format and statuses
+func ValidateCommitteePack(req *models.CommitteePackRequest) error {
+	if req.ScholarshipID == 0 {
+		return fmt.Errorf("scholarship_id is required")
+	}
+	format, err := BundleFormat(req.Format)
+	if err != nil {
+		return err
+	}
+	req.Format = format
+	if len(req.Statuses) == 0 {
+		req.Statuses = ShortlistStatuses
+	}
+	return ValidateApplicationFilter(&models.ApplicationFilter{Statuses: req.Statuses})
+}
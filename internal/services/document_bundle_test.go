@@ -0,0 +1,173 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scholarship-system/internal/models"
+)
+
+func TestPlanDocumentBundle(t *testing.T) {
+	uploaded := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	documents := []models.ApplicationDocument{
+		{DocumentID: 3, DocumentType: "house_photos", DocumentName: "บ้าน 2.jpg", MimeType: "image/jpeg", UploadStatus: "verified", UploadedAt: uploaded.Add(time.Hour)},
+		{DocumentID: 1, DocumentType: "id_card", DocumentName: "card.pdf", MimeType: "application/pdf", UploadStatus: "pending", UploadedAt: uploaded},
+		{DocumentID: 2, DocumentType: "house_photos", DocumentName: "บ้าน 1.png", MimeType: "image/png", UploadStatus: "pending", UploadedAt: uploaded},
+		{DocumentID: 4, DocumentType: "transcript", DocumentName: "grades.pdf", MimeType: "application/pdf", UploadStatus: models.DocumentQuarantined, UploadedAt: uploaded},
+	}
+
+	entries := PlanDocumentBundle("6401001", documents)
+	files := make([]string, len(entries))
+	for i, entry := range entries {
+		files[i] = entry.File
+	}
+	assert.Equal(t, []string{"6401001_house_photos.png", "6401001_house_photos_2.jpg", "6401001_id_card.pdf", ""}, files)
+	assert.Equal(t, "left out: still being scanned for malware", entries[3].Note)
+
+	assert.Equal(t, "6401_001_other.docx", BundleFileName("6401/001", "other", "application/octet-stream", "Letter.DOCX", 1))
+	assert.Equal(t, "6401001_application_form.pdf", BundleFormFile("6401001"))
+	assert.Equal(t, "unknown_42", BundleFolder(" ", 42))
+}
+
+func testBundle(t *testing.T) (*models.DocumentBundleManifest, json.RawMessage, BundleOpener) {
+	scan := NewPDFDocument(nil)
+	scan.AddText([]PDFTextLine{{Text: "Scanned ID card"}})
+	var scanPDF bytes.Buffer
+	require.NoError(t, scan.Write(&scanPDF))
+
+	files := map[int][]byte{
+		1: scanPDF.Bytes(),
+		2: testPNG(t),
+		3: []byte("%PDF-1.4 truncated"),
+	}
+	manifest := &models.DocumentBundleManifest{
+		ApplicationID:     42,
+		StudentID:         "6401001",
+		StudentName:       "สมชาย ใจดี",
+		ScholarshipName:   "Need-based grant",
+		ApplicationStatus: "under_review",
+		FormVersion:       2,
+		FormFile:          BundleFormFile("6401001"),
+		GeneratedAt:       time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC),
+		Documents: PlanDocumentBundle("6401001", []models.ApplicationDocument{
+			{DocumentID: 1, DocumentType: "id_card", MimeType: "application/pdf", UploadStatus: "verified", VersionNumber: 1},
+			{DocumentID: 2, DocumentType: "house_photos", MimeType: "image/png", UploadStatus: "pending", VersionNumber: 1},
+			{DocumentID: 3, DocumentType: "transcript", MimeType: "application/pdf", UploadStatus: "pending", VersionNumber: 2},
+			{DocumentID: 4, DocumentType: "other", MimeType: "application/pdf", UploadStatus: "pending", VersionNumber: 1},
+			{DocumentID: 5, DocumentType: "medical_certificate", MimeType: "application/pdf", UploadStatus: models.DocumentInfected, VersionNumber: 1},
+		}),
+	}
+	form := json.RawMessage(`{"application":{"application_id":42,"application_status":"under_review"},` +
+		`"personal_info":{"first_name_th":"สมชาย","created_at":"2025-05-01"},"family_members":[{"relationship":"father","monthly_income":15000}],` +
+		`"assets":[],"health_info":null,"extra_section":{"agreed":true}}`)
+	open := func(documentID int) (io.ReadCloser, error) {
+		data, ok := files[documentID]
+		if !ok {
+			return nil, errors.New("file not found in storage")
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	return manifest, form, open
+}
+
+func TestWriteBundleZip(t *testing.T) {
+	manifest, form, open := testBundle(t)
+	formPDF, err := ApplicationFormPDF(nil, manifest, form)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	require.NoError(t, WriteBundleZip(zw, "6401001_42/", manifest, formPDF, open))
+	require.NoError(t, zw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	var names []string
+	for _, file := range zr.File {
+		names = append(names, file.Name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{
+		"6401001_42/6401001_application_form.pdf",
+		"6401001_42/6401001_house_photos.png",
+		"6401001_42/6401001_id_card.pdf",
+		"6401001_42/6401001_transcript.pdf",
+		"6401001_42/manifest.json",
+	}, names)
+
+	other := manifest.Documents[3]
+	assert.Equal(t, "other", other.DocumentType)
+	assert.Empty(t, other.File)
+	assert.Equal(t, "left out: the file could not be read", other.Note)
+
+	r, err := zr.Open("6401001_42/manifest.json")
+	require.NoError(t, err)
+	var written models.DocumentBundleManifest
+	require.NoError(t, json.NewDecoder(r).Decode(&written))
+	assert.Equal(t, *manifest, written)
+}
+
+func TestWriteBundlePDF(t *testing.T) {
+	manifest, form, open := testBundle(t)
+	var buf bytes.Buffer
+	require.NoError(t, WriteBundlePDF(&buf, nil, manifest, form, open))
+
+	notes := map[string]string{}
+	for _, entry := range manifest.Documents {
+		notes[entry.DocumentType] = entry.Note
+	}
+	assert.Empty(t, notes["id_card"])
+	assert.Empty(t, notes["house_photos"])
+	assert.True(t, strings.HasPrefix(notes["transcript"], "could not be merged: "), notes["transcript"])
+	assert.Equal(t, "could not be merged: the file could not be read", notes["other"])
+	assert.Equal(t, "left out: malware was found", notes["medical_certificate"])
+
+	r, err := newPDFReader(buf.Bytes())
+	require.NoError(t, err)
+	pages, err := r.pages()
+	require.NoError(t, err)
+	// Cover, form, ID card, house photo and a notice for each broken document
+	assert.Len(t, pages, 6)
+}
+
+func TestRenderApplicationForm(t *testing.T) {
+	manifest, form, _ := testBundle(t)
+	pdf := NewPDFDocument(nil)
+	require.NoError(t, RenderApplicationForm(pdf, manifest, form))
+
+	var text []string
+	var lines []PDFTextLine
+	var sections map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(form))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&sections))
+	lines = appendFormValue(lines, sections["family_members"], 0)
+	for _, line := range lines {
+		text = append(text, line.Text)
+	}
+	assert.Equal(t, []string{"1.", "Monthly income: 15000", "Relationship: father"}, text)
+	assert.Equal(t, "Personal info", humanizeKey("personal_info"))
+
+	assert.Error(t, RenderApplicationForm(NewPDFDocument(nil), manifest, json.RawMessage(`[1]`)))
+}
+
+func TestValidateCommitteePack(t *testing.T) {
+	req := &models.CommitteePackRequest{ScholarshipID: 3}
+	require.NoError(t, ValidateCommitteePack(req))
+	assert.Equal(t, models.BundleFormatZip, req.Format)
+	assert.Equal(t, ShortlistStatuses, req.Statuses)
+
+	assert.EqualError(t, ValidateCommitteePack(&models.CommitteePackRequest{}), "scholarship_id is required")
+	assert.EqualError(t, ValidateCommitteePack(&models.CommitteePackRequest{ScholarshipID: 3, Format: "tar"}), "format must be zip or pdf")
+	assert.EqualError(t, ValidateCommitteePack(&models.CommitteePackRequest{ScholarshipID: 3, Statuses: []string{"shortlisted"}}), `unknown status "shortlisted"`)
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"path"
+	"strings"
+	"unicode"
+)
+
+// documentTypeAliases are the file name stems recognised for each document
+// type, besides the type name itself. Longer matches win, so
+// "house_registration" is not mistaken for "house_photos".
+var documentTypeAliases = map[string][]string{
+	"id_card":                 {"idcard", "citizen_id", "national_id", "บัตรประชาชน", "บัตรประจำตัวประชาชน"},
+	"house_registration":      {"house_reg", "tabien_baan", "ทะเบียนบ้าน"},
+	"transcript":              {"grades", "gpa", "ใบแสดงผลการเรียน", "ผลการเรียน", "ทรานสคริปต์"},
+	"income_certificate":      {"income", "salary", "หนังสือรับรองรายได้", "รับรองรายได้", "รายได้"},
+	"bank_account":            {"bank", "bankbook", "book_bank", "สมุดบัญชี", "บัญชีธนาคาร"},
+	"medical_certificate":     {"medical", "ใบรับรองแพทย์"},
+	"activity_certificate":    {"activity", "เกียรติบัตร", "กิจกรรม"},
+	"recommendation_letter":   {"recommendation", "reference_letter", "จดหมายแนะนำ"},
+	"scholarship_certificate": {"previous_scholarship", "ทุนเดิม"},
+	"residence_photo":         {"residence"},
+	"house_photos":            {"house_photo", "home_photo", "รูปบ้าน", "ภาพบ้าน"},
+	"living_situation_photos": {"living_situation", "living", "สภาพความเป็นอยู่"},
+	"other":                   {},
+}
+
+// IsDocumentType reports whether a document type is one the system knows
+func IsDocumentType(documentType string) bool {
+	_, ok := documentTypeAliases[documentType]
+	return ok
+}
+
+// ClassifyDocument works out the document type of an uploaded file. An entry
+// in mapping for the file name wins; otherwise the name must start with a
+// document type or one of its aliases, e.g. "id_card.pdf",
+// "Income-Certificate 2.jpg" or "ทะเบียนบ้าน_หน้า2.png". It returns "" when
+// the type cannot be told.
+func ClassifyDocument(filename string, mapping map[string]string) string {
+	base := path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	for name, documentType := range mapping {
+		if strings.EqualFold(name, base) || strings.EqualFold(name, filename) {
+			return documentType
+		}
+	}
+
+	stem := normalizeFileStem(strings.TrimSuffix(base, path.Ext(base)))
+	best, bestLen := "", 0
+	for documentType, aliases := range documentTypeAliases {
+		for _, name := range append([]string{documentType}, aliases...) {
+			if len(name) > bestLen && hasNamePrefix(stem, name) {
+				best, bestLen = documentType, len(name)
+			}
+		}
+	}
+	return best
+}
+
+// normalizeFileStem lower-cases a file name and turns separators into "_"
+func normalizeFileStem(stem string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(stem)) {
+		if r == '-' || r == ' ' || r == '.' {
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// hasNamePrefix reports whether stem starts with name as a whole word, so
+// "income_2.pdf" matches "income" but "incomes" does not
+func hasNamePrefix(stem, name string) bool {
+	if !strings.HasPrefix(stem, name) {
+		return false
+	}
+	rest := []rune(stem[len(name):])
+	return len(rest) == 0 || rest[0] == '_' || unicode.IsDigit(rest[0]) || !isLatin(name)
+}
+
+// isLatin reports whether a name is written in ASCII letters; Thai names are
+// not separated by spaces so any continuation is accepted
+func isLatin(name string) bool {
+	for _, r := range name {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
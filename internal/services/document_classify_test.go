@@ -0,0 +1,39 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyDocument(t *testing.T) {
+	cases := map[string]string{
+		"id_card.pdf":                 "id_card",
+		"ID-Card front.jpg":           "id_card",
+		"IDCARD2.png":                 "id_card",
+		"house_registration_p1.pdf":   "house_registration",
+		"house_photos_1.jpg":          "house_photos",
+		"House Photo.jpg":             "house_photos",
+		"Income-Certificate 2024.pdf": "income_certificate",
+		"income.pdf":                  "income_certificate",
+		"ทะเบียนบ้าน_หน้า2.png": "house_registration",
+		"บัตรประชาชน.jpg":       "id_card",
+		"ใบรับรองแพทย์.pdf":     "medical_certificate",
+		"docs/transcript.pdf":   "transcript",
+		"incomes.pdf":           "",
+		"scan001.pdf":           "",
+		"":                      "",
+	}
+	for name, want := range cases {
+		assert.Equal(t, want, ClassifyDocument(name, nil), name)
+	}
+
+	// An explicit mapping wins over the file name
+	mapping := map[string]string{"SCAN001.pdf": "bank_account", "income.pdf": "other"}
+	assert.Equal(t, "bank_account", ClassifyDocument("scan001.pdf", mapping))
+	assert.Equal(t, "other", ClassifyDocument("income.pdf", mapping))
+	assert.Equal(t, "bank_account", ClassifyDocument("student/scan001.pdf", mapping))
+
+	assert.True(t, IsDocumentType("bank_account"))
+	assert.False(t, IsDocumentType("passport"))
+}
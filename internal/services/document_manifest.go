@@ -0,0 +1,84 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"scholarship-system/internal/models"
+)
+
+// ParseDocumentManifest reads the CSV manifest of a document import. The
+// header names the columns: file is required, with application_id or
+// student_id and scholarship_id; document_type is optional.
+func ParseDocumentManifest(r io.Reader) ([]models.DocumentManifestRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("manifest is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		// Spreadsheets often save a byte order mark before the first column
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	if _, ok := columns["file"]; !ok {
+		return nil, fmt.Errorf("manifest needs a file column")
+	}
+	_, hasApplication := columns["application_id"]
+	_, hasStudent := columns["student_id"]
+	_, hasScholarship := columns["scholarship_id"]
+	if !hasApplication && !(hasStudent && hasScholarship) {
+		return nil, fmt.Errorf("manifest needs an application_id column, or student_id and scholarship_id columns")
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	rows := []models.DocumentManifestRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		row := models.DocumentManifestRow{
+			Line:         line,
+			File:         field(record, "file"),
+			StudentID:    field(record, "student_id"),
+			DocumentType: field(record, "document_type"),
+		}
+		if row.File == "" && row.StudentID == "" && field(record, "application_id") == "" {
+			continue // a line of empty cells
+		}
+		if value := field(record, "application_id"); value != "" {
+			if row.ApplicationID, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("manifest line %d: invalid application_id %q", line, value)
+			}
+		}
+		if value := field(record, "scholarship_id"); value != "" {
+			if row.ScholarshipID, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("manifest line %d: invalid scholarship_id %q", line, value)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scholarship-system/internal/models"
+)
+
+func TestParseDocumentManifest(t *testing.T) {
+	manifest := "\ufeffFile,Student_ID,Scholarship_ID,Document_Type,application_id\n" +
+		"6401001/id_card.pdf, 6401001 ,3,,\n" +
+		"\n" +
+		"scan001.pdf,,,income_certificate,42\n"
+	rows, err := ParseDocumentManifest(strings.NewReader(manifest))
+	require.NoError(t, err)
+	assert.Equal(t, []models.DocumentManifestRow{
+		{Line: 2, File: "6401001/id_card.pdf", StudentID: "6401001", ScholarshipID: 3},
+		{Line: 4, File: "scan001.pdf", ApplicationID: 42, DocumentType: "income_certificate"},
+	}, rows)
+
+	_, err = ParseDocumentManifest(strings.NewReader("file,document_type\nid_card.pdf,id_card\n"))
+	assert.EqualError(t, err, "manifest needs an application_id column, or student_id and scholarship_id columns")
+
+	_, err = ParseDocumentManifest(strings.NewReader("file,application_id\nid_card.pdf,abc\n"))
+	assert.EqualError(t, err, `manifest line 2: invalid application_id "abc"`)
+
+	_, err = ParseDocumentManifest(strings.NewReader(""))
+	assert.EqualError(t, err, "manifest is empty")
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"sort"
+
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/storage"
+)
+
+// PreviewMaxSize is the longest side in pixels of a document preview
+const PreviewMaxSize = 1024
+
+// previewQuality is the JPEG quality of previews
+const previewQuality = 80
+
+// maxPreviewPixels skips images that would take too much memory to decode,
+// the same limit uploads are held to
+const maxPreviewPixels = 50_000_000
+
+// maxPreviewDepth caps how deep form XObjects are searched for images
+const maxPreviewDepth = 4
+
+// GeneratePreview reads a document file and returns its JPEG preview along
+// with its page count or image dimensions. Images are scaled down to fit
+// PreviewMaxSize. A PDF is previewed by the largest image on its first page,
+// which is the scan itself for scanned documents; PDFs without one and other
+// formats get metadata only, with no image. An error means the file could
+// not be read.
+func GeneratePreview(data []byte) (*models.DocumentPreview, error) {
+	switch storage.DetectType(data) {
+	case storage.TypeJPEG, storage.TypePNG, storage.TypeGIF:
+		config, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image: %w", err)
+		}
+		if config.Width*config.Height > maxPreviewPixels {
+			return nil, errors.New("image dimensions are too large")
+		}
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image: %w", err)
+		}
+		width, height := config.Width, config.Height
+		preview := &models.DocumentPreview{ImageWidth: &width, ImageHeight: &height}
+		preview.Image, err = encodePreview(img)
+		return preview, err
+	case storage.TypePDF:
+		return pdfPreview(data)
+	}
+	return &models.DocumentPreview{}, nil
+}
+
+func pdfPreview(data []byte) (*models.DocumentPreview, error) {
+	r, err := newPDFReader(data)
+	if err != nil {
+		return nil, err
+	}
+	pages, err := r.pages()
+	if err != nil {
+		return nil, err
+	}
+	count := len(pages)
+	preview := &models.DocumentPreview{PageCount: &count}
+
+	images := r.images(pages[0]["Resources"], 0, map[*pdfStream]bool{})
+	sort.SliceStable(images, func(i, j int) bool {
+		return r.imageArea(images[i]) > r.imageArea(images[j])
+	})
+	// Images in a filter or colour space we cannot decode are passed over
+	for _, stream := range images {
+		img, err := r.decodeImage(stream)
+		if err != nil {
+			continue
+		}
+		preview.Image, err = encodePreview(img)
+		return preview, err
+	}
+	return preview, nil
+}
+
+// images returns the image XObjects of a resource dictionary, including
+// those drawn by its form XObjects. Masks are left out.
+func (r *pdfReader) images(resources interface{}, depth int, seen map[*pdfStream]bool) []*pdfStream {
+	res, _ := r.resolve(resources).(pdfDict)
+	xobjects, _ := r.resolve(res["XObject"]).(pdfDict)
+	var images []*pdfStream
+	for _, v := range xobjects {
+		stream, ok := r.resolve(v).(*pdfStream)
+		if !ok || seen[stream] {
+			continue
+		}
+		seen[stream] = true
+		switch r.resolve(stream.dict["Subtype"]) {
+		case pdfName("Image"):
+			if mask, _ := r.resolve(stream.dict["ImageMask"]).(bool); !mask {
+				images = append(images, stream)
+			}
+		case pdfName("Form"):
+			if depth < maxPreviewDepth {
+				images = append(images, r.images(stream.dict["Resources"], depth+1, seen)...)
+			}
+		}
+	}
+	return images
+}
+
+func (r *pdfReader) imageArea(stream *pdfStream) int {
+	width, _ := pdfInt(r.resolve(stream.dict["Width"]))
+	height, _ := pdfInt(r.resolve(stream.dict["Height"]))
+	return width * height
+}
+
+// decodeImage decodes a JPEG image XObject, or an uncompressed or Flate one
+// in 8 bits per component or 1-bit grey
+func (r *pdfReader) decodeImage(stream *pdfStream) (image.Image, error) {
+	width, _ := pdfInt(r.resolve(stream.dict["Width"]))
+	height, _ := pdfInt(r.resolve(stream.dict["Height"]))
+	if width <= 0 || height <= 0 || width*height > maxPreviewPixels {
+		return nil, errors.New("image dimensions are out of range")
+	}
+
+	filters := r.resolve(stream.dict["Filter"])
+	params := r.resolve(stream.dict["DecodeParms"])
+	if name, ok := filters.(pdfName); ok {
+		filters, params = pdfArray{name}, pdfArray{params}
+	}
+	list, _ := filters.(pdfArray)
+	if last := len(list) - 1; last >= 0 && r.resolve(list[last]) == pdfName("DCTDecode") {
+		// Filters before the JPEG one are undone first
+		paramList, _ := params.(pdfArray)
+		data, err := r.decodeStream(&pdfStream{
+			dict: pdfDict{"Filter": list[:last], "DecodeParms": paramList[:min(last, len(paramList))]},
+			data: stream.data,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return jpeg.Decode(bytes.NewReader(data))
+	}
+
+	data, err := r.decodeStream(stream)
+	if err != nil {
+		return nil, err
+	}
+	components, err := r.colorComponents(stream.dict["ColorSpace"])
+	if err != nil {
+		return nil, err
+	}
+	bits, _ := pdfInt(r.resolve(stream.dict["BitsPerComponent"]))
+	decode, _ := r.resolve(stream.dict["Decode"]).(pdfArray)
+	inverted := false
+	if len(decode) == 2 {
+		low, _ := pdfInt(r.resolve(decode[0]))
+		inverted = components == 1 && low == 1
+	}
+	return rasterImage(data, width, height, bits, components, inverted)
+}
+
+// colorComponents returns the number of components of a colour space
+func (r *pdfReader) colorComponents(v interface{}) (int, error) {
+	space := r.resolve(v)
+	if array, ok := space.(pdfArray); ok && len(array) > 0 {
+		if r.resolve(array[0]) == pdfName("ICCBased") && len(array) > 1 {
+			if profile, ok := r.resolve(array[1]).(*pdfStream); ok {
+				if n, _ := pdfInt(r.resolve(profile.dict["N"])); n == 1 || n == 3 || n == 4 {
+					return n, nil
+				}
+			}
+		}
+		space = r.resolve(array[0])
+	}
+	switch space {
+	case pdfName("DeviceGray"), pdfName("CalGray"), pdfName("G"):
+		return 1, nil
+	case pdfName("DeviceRGB"), pdfName("CalRGB"), pdfName("RGB"):
+		return 3, nil
+	case pdfName("DeviceCMYK"), pdfName("CMYK"):
+		return 4, nil
+	}
+	return 0, fmt.Errorf("unsupported colour space %v", space)
+}
+
+// rasterImage builds an image from decoded samples
+func rasterImage(data []byte, width, height, bits, components int, inverted bool) (image.Image, error) {
+	rect := image.Rect(0, 0, width, height)
+	switch {
+	case bits == 1 && components == 1:
+		rowLen := (width + 7) / 8
+		if len(data) < rowLen*height {
+			return nil, errors.New("image data is truncated")
+		}
+		img := image.NewGray(rect)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				if data[y*rowLen+x/8]>>(7-x%8)&1 == 1 {
+					img.Pix[y*img.Stride+x] = 0xff
+				}
+			}
+		}
+		return invertGray(img, inverted), nil
+	case bits == 8:
+		if len(data) < width*height*components {
+			return nil, errors.New("image data is truncated")
+		}
+		switch components {
+		case 1:
+			img := image.NewGray(rect)
+			copy(img.Pix, data)
+			return invertGray(img, inverted), nil
+		case 3:
+			img := image.NewRGBA(rect)
+			for i := 0; i < width*height; i++ {
+				copy(img.Pix[4*i:4*i+3], data[3*i:3*i+3])
+				img.Pix[4*i+3] = 0xff
+			}
+			return img, nil
+		case 4:
+			img := image.NewCMYK(rect)
+			copy(img.Pix, data)
+			return img, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported image format: %d bits, %d components", bits, components)
+}
+
+// invertGray applies a [1 0] Decode array
+func invertGray(img *image.Gray, inverted bool) *image.Gray {
+	if inverted {
+		for i := range img.Pix {
+			img.Pix[i] = 0xff - img.Pix[i]
+		}
+	}
+	return img
+}
+
+func encodePreview(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, thumbnail(img, PreviewMaxSize), &jpeg.Options{Quality: previewQuality}); err != nil {
+		return nil, fmt.Errorf("failed to encode preview: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// thumbnail flattens an image onto white and scales it down so its longest
+// side is at most size, averaging the source pixels under each target pixel
+func thumbnail(img image.Image, size int) *image.RGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	src := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Over)
+	if w <= size && h <= size {
+		return src
+	}
+
+	dw, dh := size, max(1, h*size/w)
+	if h > w {
+		dw, dh = max(1, w*size/h), size
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for dy := 0; dy < dh; dy++ {
+		y0, y1 := dy*h/dh, (dy+1)*h/dh
+		for dx := 0; dx < dw; dx++ {
+			x0, x1 := dx*w/dw, (dx+1)*w/dw
+			var sum [3]int
+			for y := y0; y < y1; y++ {
+				row := src.Pix[y*src.Stride+4*x0 : y*src.Stride+4*x1]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			at := dy*dst.Stride + 4*dx
+			dst.Pix[at] = uint8(sum[0] / n)
+			dst.Pix[at+1] = uint8(sum[1] / n)
+			dst.Pix[at+2] = uint8(sum[2] / n)
+			dst.Pix[at+3] = 0xff
+		}
+	}
+	return dst
+}
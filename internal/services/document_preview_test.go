@@ -0,0 +1,121 @@
+package services
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testJPEG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0x80
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+func previewSize(t *testing.T, data []byte) image.Point {
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	return img.Bounds().Size()
+}
+
+func TestGeneratePreviewImages(t *testing.T) {
+	preview, err := GeneratePreview(testJPEG(t, 2000, 1000))
+	require.NoError(t, err)
+	assert.Equal(t, 2000, *preview.ImageWidth)
+	assert.Equal(t, 1000, *preview.ImageHeight)
+	assert.Nil(t, preview.PageCount)
+	assert.Equal(t, image.Pt(PreviewMaxSize, PreviewMaxSize/2), previewSize(t, preview.Image))
+
+	// Small images are not enlarged and transparency turns white
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 5))
+	img.Set(1, 1, color.NRGBA{B: 255, A: 255})
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	preview, err = GeneratePreview(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, 3, *preview.ImageWidth)
+	decoded, err := jpeg.Decode(bytes.NewReader(preview.Image))
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(3, 5), decoded.Bounds().Size())
+	r, g, b, _ := decoded.At(0, 4).RGBA()
+	assert.Greater(t, min(r, g, b), uint32(0xf000))
+
+	_, err = GeneratePreview([]byte("\x89PNG\r\n\x1a\ntruncated"))
+	assert.Error(t, err)
+}
+
+func TestGeneratePreviewPDF(t *testing.T) {
+	t.Run("scanned pages are previewed by their image", func(t *testing.T) {
+		d := NewPDFDocument(nil)
+		require.NoError(t, d.AddImage(testJPEG(t, 600, 1200), "id_card"))
+		d.AddText([]PDFTextLine{{Text: "Second page"}})
+		preview, err := GeneratePreview(writePDF(t, d))
+		require.NoError(t, err)
+		assert.Equal(t, 2, *preview.PageCount)
+		assert.Nil(t, preview.ImageWidth)
+		assert.Equal(t, image.Pt(PreviewMaxSize/2, PreviewMaxSize), previewSize(t, preview.Image))
+	})
+
+	t.Run("Flate images are decoded", func(t *testing.T) {
+		d := NewPDFDocument(nil)
+		require.NoError(t, d.AddImage(testPNG(t), ""))
+		preview, err := GeneratePreview(writePDF(t, d))
+		require.NoError(t, err)
+		assert.Equal(t, 1, *preview.PageCount)
+		decoded, err := jpeg.Decode(bytes.NewReader(preview.Image))
+		require.NoError(t, err)
+		assert.Equal(t, image.Pt(4, 2), decoded.Bounds().Size())
+		r, _, b, _ := decoded.At(0, 0).RGBA()
+		assert.Greater(t, r, b) // the red pixel, blurred by chroma subsampling
+	})
+
+	t.Run("text pages have a page count only", func(t *testing.T) {
+		d := NewPDFDocument(nil)
+		d.AddText([]PDFTextLine{{Text: "Income certificate"}})
+		preview, err := GeneratePreview(writePDF(t, d))
+		require.NoError(t, err)
+		assert.Equal(t, 1, *preview.PageCount)
+		assert.Nil(t, preview.Image)
+	})
+
+	_, err := GeneratePreview([]byte("%PDF-1.7\nnot really"))
+	assert.Error(t, err)
+}
+
+func TestGeneratePreviewOtherFormats(t *testing.T) {
+	preview, err := GeneratePreview([]byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1, 0})
+	require.NoError(t, err)
+	assert.Nil(t, preview.Image)
+	assert.Nil(t, preview.PageCount)
+}
+
+func TestRasterImage(t *testing.T) {
+	img, err := rasterImage([]byte{0b10100000, 0b01000000}, 3, 2, 1, 1, true)
+	require.NoError(t, err)
+	gray := img.(*image.Gray)
+	assert.Equal(t, []byte{0, 0xff, 0, 0xff, 0, 0xff}, gray.Pix)
+
+	_, err = rasterImage([]byte{1, 2, 3}, 2, 2, 8, 3, false)
+	assert.EqualError(t, err, "image data is truncated")
+	_, err = rasterImage(make([]byte, 16), 2, 2, 16, 1, false)
+	assert.Error(t, err)
+}
+
+func TestThumbnailAverages(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 4, 2))
+	copy(img.Pix, []byte{0, 0, 200, 200, 100, 100, 0, 0})
+	small := thumbnail(img, 2)
+	require.Equal(t, image.Pt(2, 1), small.Bounds().Size())
+	assert.Equal(t, uint8(50), small.Pix[0])
+	assert.Equal(t, uint8(100), small.Pix[4])
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/storage"
+)
+
+// DocumentFormats maps the format names of a requirement to the content
+// types detected in uploads
+var DocumentFormats = map[string]string{
+	"pdf":  storage.TypePDF,
+	"jpeg": storage.TypeJPEG,
+	"png":  storage.TypePNG,
+	"doc":  storage.TypeDOC,
+	"docx": storage.TypeDOCX,
+}
+
+// DefaultDocumentRequirements apply to scholarships that set none of their
+// own
+var DefaultDocumentRequirements = []models.DocumentRequirement{
+	{DocumentType: "id_card", Requirement: models.RequirementMandatory},
+	{DocumentType: "transcript", Requirement: models.RequirementMandatory},
+}
+
+// maxRequirementSizeMB is the largest size limit a requirement may set
+const maxRequirementSizeMB = 100
+
+// maxRequirementPages is the largest page limit a requirement may set
+const maxRequirementPages = 500
+
+// DocumentRequirementsOrDefault returns the requirements of a scholarship, or
+// the defaults when it sets none
+func DocumentRequirementsOrDefault(requirements []models.DocumentRequirement) []models.DocumentRequirement {
+	if len(requirements) == 0 {
+		return DefaultDocumentRequirements
+	}
+	return requirements
+}
+
+// FindDocumentRequirement returns the requirement for a document type, or nil
+func FindDocumentRequirement(requirements []models.DocumentRequirement, documentType string) *models.DocumentRequirement {
+	for i := range requirements {
+		if requirements[i].DocumentType == documentType {
+			return &requirements[i]
+		}
+	}
+	return nil
+}
+
+// ValidateDocumentRequirements checks the requirements of a scholarship and
+// normalises them: the requirement defaults to mandatory and format names are
+// lower-cased, with "jpg" read as "jpeg".
+func ValidateDocumentRequirements(requirements []models.DocumentRequirement) error {
+	seen := map[string]bool{}
+	for i := range requirements {
+		req := &requirements[i]
+		req.DocumentType = strings.TrimSpace(req.DocumentType)
+		switch {
+		case req.DocumentType == "":
+			return fmt.Errorf("document_type is required")
+		case !IsDocumentType(req.DocumentType):
+			return fmt.Errorf("unknown document type %q", req.DocumentType)
+		case seen[req.DocumentType]:
+			return fmt.Errorf("document type %q is listed more than once", req.DocumentType)
+		}
+		seen[req.DocumentType] = true
+
+		if req.Requirement == "" {
+			req.Requirement = models.RequirementMandatory
+		}
+		switch req.Requirement {
+		case models.RequirementMandatory, models.RequirementOptional:
+			if req.Condition != nil {
+				return fmt.Errorf("%s: only conditional documents take a condition", req.DocumentType)
+			}
+		case models.RequirementConditional:
+			if req.Condition == nil || strings.TrimSpace(req.Condition.Field) == "" {
+				return fmt.Errorf("%s: a conditional document needs a condition field", req.DocumentType)
+			}
+			req.Condition.Field = strings.TrimSpace(req.Condition.Field)
+		default:
+			return fmt.Errorf("%s: requirement must be mandatory, conditional or optional", req.DocumentType)
+		}
+
+		if req.MaxSizeMB < 0 || req.MaxSizeMB > maxRequirementSizeMB {
+			return fmt.Errorf("%s: max_size_mb must be between 0 and %d", req.DocumentType, maxRequirementSizeMB)
+		}
+		for j, format := range req.AcceptedFormats {
+			format = strings.ToLower(strings.TrimSpace(format))
+			if format == "jpg" {
+				format = "jpeg"
+			}
+			if _, ok := DocumentFormats[format]; !ok {
+				return fmt.Errorf("%s: unknown format %q", req.DocumentType, req.AcceptedFormats[j])
+			}
+			req.AcceptedFormats[j] = format
+		}
+		if len(req.AcceptedFormats) > 0 && !containsAny(req.AcceptedFormats, "pdf", "jpeg", "png") {
+			// Most upload paths take only these, so one of them must be allowed
+			return fmt.Errorf("%s: accepted_formats must include pdf, jpeg or png", req.DocumentType)
+		}
+		if req.MinCount < 0 || req.MaxCount < 0 {
+			return fmt.Errorf("%s: counts cannot be negative", req.DocumentType)
+		}
+		if req.MaxCount > 0 && req.MinCount > req.MaxCount {
+			return fmt.Errorf("%s: min_count is larger than max_count", req.DocumentType)
+		}
+		if req.ValidMonths < 0 || req.ValidMonths > 120 {
+			return fmt.Errorf("%s: valid_months must be between 0 and 120", req.DocumentType)
+		}
+		if req.MaxPages < 0 || req.MaxPages > maxRequirementPages {
+			return fmt.Errorf("%s: max_pages must be between 0 and %d", req.DocumentType, maxRequirementPages)
+		}
+	}
+	return nil
+}
+
+// AcceptedContentTypes narrows the content types an upload accepts to the
+// formats of a requirement. It returns allowed and typeNames unchanged when
+// the requirement lists no formats or none of them is allowed, which
+// ValidateDocumentRequirements rules out for the usual upload types.
+func AcceptedContentTypes(req *models.DocumentRequirement, allowed []string, typeNames string) ([]string, string) {
+	if req == nil || len(req.AcceptedFormats) == 0 {
+		return allowed, typeNames
+	}
+	var types, names []string
+	for _, format := range req.AcceptedFormats {
+		contentType := DocumentFormats[format]
+		if contentType == "" || !contains(allowed, contentType) || contains(types, contentType) {
+			continue
+		}
+		types = append(types, contentType)
+		names = append(names, strings.ToUpper(format))
+	}
+	if len(types) == 0 {
+		return allowed, typeNames
+	}
+	return types, strings.Join(names, ", ")
+}
+
+// EvaluateDocumentChecklist checks the documents of an application against
+// its scholarship's requirements, or the defaults. form is the application
+// form as JSON, used for the conditions of conditional documents. A file
+// counts towards its requirement once it has passed the malware scan, has
+// not been rejected, is in an accepted format, for PDFs with a page limit
+// has no more pages than allowed and, for documents with a validity period,
+// was issued within it. Page counts are read by the document preview job.
+func EvaluateDocumentChecklist(applicationID, scholarshipID uint, requirements []models.DocumentRequirement,
+	form json.RawMessage, documents []models.ApplicationDocument, now time.Time) *models.DocumentChecklist {
+	checklist := &models.DocumentChecklist{
+		ApplicationID: applicationID,
+		ScholarshipID: scholarshipID,
+		Items:         []models.DocumentChecklistItem{},
+		Problems:      []string{},
+	}
+
+	var formData interface{}
+	if len(form) > 0 {
+		json.Unmarshal(form, &formData)
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	for _, req := range DocumentRequirementsOrDefault(requirements) {
+		item := models.DocumentChecklistItem{
+			DocumentRequirement: req,
+			Documents:           []models.ChecklistDocument{},
+		}
+		if item.MinCount == 0 {
+			item.MinCount = 1
+		}
+		switch req.Requirement {
+		case models.RequirementMandatory, "":
+			item.Required = true
+		case models.RequirementConditional:
+			item.Required = req.Condition != nil && conditionHolds(formData, strings.Split(req.Condition.Field, "."), req.Condition.Values)
+		}
+		if req.ValidMonths > 0 {
+			validBefore := today.AddDate(0, -req.ValidMonths, 0)
+			item.ValidBefore = &validBefore
+		}
+
+		held := map[string]bool{}
+		for _, doc := range documents {
+			if doc.DocumentType != req.DocumentType {
+				continue
+			}
+			entry := models.ChecklistDocument{
+				DocumentID:   doc.DocumentID,
+				DocumentName: doc.DocumentName,
+				UploadStatus: doc.UploadStatus,
+				IssuedDate:   doc.IssuedDate,
+			}
+			status := models.ChecklistComplete
+			switch {
+			case doc.UploadStatus == models.DocumentInfected:
+				status, entry.Problem = models.ChecklistMissing, "malware was found; upload the file again"
+			case doc.UploadStatus == models.DocumentPurged:
+				status, entry.Problem = models.ChecklistMissing, "deleted at the end of its retention period"
+			case doc.UploadStatus == models.DocumentMissing:
+				status, entry.Problem = models.ChecklistMissing, "the file is missing or damaged; upload it again"
+			case doc.UploadStatus == models.DocumentQuarantined:
+				status, entry.Problem = models.ChecklistScanning, "still being scanned for malware"
+			case doc.UploadStatus == "rejected":
+				status, entry.Problem = models.ChecklistRejected, "rejected by an officer"
+			case len(req.AcceptedFormats) > 0 && !acceptsContentType(req.AcceptedFormats, doc.MimeType):
+				status, entry.Problem = models.ChecklistMissing, "format is not accepted"
+			case req.MaxPages > 0 && doc.MimeType == storage.TypePDF && doc.PreviewStatus == nil:
+				status, entry.Problem = models.ChecklistProcessing, "pages are still being counted"
+			case req.MaxPages > 0 && doc.PageCount != nil && *doc.PageCount > req.MaxPages:
+				status, entry.Problem = models.ChecklistTooManyPages, fmt.Sprintf("has %d pages, at most %d allowed", *doc.PageCount, req.MaxPages)
+			case item.ValidBefore != nil && doc.IssuedDate == nil:
+				status, entry.Problem = models.ChecklistIssueDateMissing, "issue date is needed"
+			case item.ValidBefore != nil && doc.IssuedDate.Before(*item.ValidBefore):
+				status, entry.Problem = models.ChecklistExpired, fmt.Sprintf("issued more than %d months ago", req.ValidMonths)
+			case item.ValidBefore != nil && doc.IssuedDate.After(today):
+				status, entry.Problem = models.ChecklistIssueDateMissing, "issue date is in the future"
+			default:
+				entry.Counted = true
+				item.Count++
+			}
+			held[status] = true
+			item.Documents = append(item.Documents, entry)
+		}
+
+		label := req.Label
+		if label == "" {
+			label = req.DocumentType
+		}
+		switch {
+		case req.MaxCount > 0 && item.Count > req.MaxCount:
+			item.Status = models.ChecklistTooMany
+			item.Problem = fmt.Sprintf("Document '%s' allows at most %d files", label, req.MaxCount)
+		case item.Count >= item.MinCount:
+			item.Status = models.ChecklistComplete
+		case !item.Required:
+			item.Status = models.ChecklistNotRequired
+		case held[models.ChecklistScanning]:
+			item.Status = models.ChecklistScanning
+			item.Problem = fmt.Sprintf("Required document '%s' is still being scanned for malware", label)
+		case held[models.ChecklistProcessing]:
+			item.Status = models.ChecklistProcessing
+			item.Problem = fmt.Sprintf("Required document '%s' is still being processed", label)
+		case held[models.ChecklistTooManyPages]:
+			item.Status = models.ChecklistTooManyPages
+			item.Problem = fmt.Sprintf("Required document '%s' allows at most %d pages", label, req.MaxPages)
+		case held[models.ChecklistIssueDateMissing]:
+			item.Status = models.ChecklistIssueDateMissing
+			item.Problem = fmt.Sprintf("Required document '%s' needs a valid issue date", label)
+		case held[models.ChecklistExpired]:
+			item.Status = models.ChecklistExpired
+			item.Problem = fmt.Sprintf("Required document '%s' must be issued on or after %s", label, item.ValidBefore.Format("2006-01-02"))
+		case held[models.ChecklistRejected]:
+			item.Status = models.ChecklistRejected
+			item.Problem = fmt.Sprintf("Required document '%s' was rejected; upload it again", label)
+		case item.MinCount > 1 && item.Count > 0:
+			item.Status = models.ChecklistMissing
+			item.Problem = fmt.Sprintf("Required document '%s' needs %d files, %d uploaded", label, item.MinCount, item.Count)
+		default:
+			item.Status = models.ChecklistMissing
+			item.Problem = fmt.Sprintf("Required document '%s' is missing", label)
+		}
+		if item.Problem != "" {
+			checklist.Problems = append(checklist.Problems, item.Problem)
+		}
+		checklist.Items = append(checklist.Items, item)
+	}
+
+	checklist.Complete = len(checklist.Problems) == 0
+	return checklist
+}
+
+// containsAny reports whether list holds any of the values
+func containsAny(list []string, values ...string) bool {
+	for _, value := range values {
+		if contains(list, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsContentType reports whether a content type is one of the formats
+func acceptsContentType(formats []string, contentType string) bool {
+	for _, format := range formats {
+		if DocumentFormats[format] == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionHolds walks path through decoded JSON. A list matches when any of
+// its items does. At the end of the path the value must be one of values, or
+// with no values anything but null, false, zero, "" or an empty list.
+func conditionHolds(value interface{}, path []string, values []interface{}) bool {
+	if list, ok := value.([]interface{}); ok {
+		for _, item := range list {
+			if conditionHolds(item, path, values) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) > 0 {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		return conditionHolds(object[path[0]], path[1:], values)
+	}
+
+	if len(values) == 0 {
+		switch v := value.(type) {
+		case nil:
+			return false
+		case bool:
+			return v
+		case float64:
+			return v != 0
+		case string:
+			return v != ""
+		}
+		return true
+	}
+	for _, want := range values {
+		if fmt.Sprint(want) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
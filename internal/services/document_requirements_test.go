@@ -0,0 +1,185 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/storage"
+)
+
+func TestValidateDocumentRequirements(t *testing.T) {
+	requirements := []models.DocumentRequirement{
+		{DocumentType: " id_card ", AcceptedFormats: []string{"PDF", "jpg"}},
+		{DocumentType: "medical_certificate", Requirement: models.RequirementConditional,
+			Condition: &models.DocumentCondition{Field: "health_info.has_chronic_disease"}},
+	}
+	require.NoError(t, ValidateDocumentRequirements(requirements))
+	assert.Equal(t, "id_card", requirements[0].DocumentType)
+	assert.Equal(t, models.RequirementMandatory, requirements[0].Requirement)
+	assert.Equal(t, []string{"pdf", "jpeg"}, requirements[0].AcceptedFormats)
+
+	cases := map[string][]models.DocumentRequirement{
+		`unknown document type "passport"`:                                   {{DocumentType: "passport"}},
+		`document type "id_card" is listed more than once`:                   {{DocumentType: "id_card"}, {DocumentType: "id_card"}},
+		"transcript: a conditional document needs a condition field":         {{DocumentType: "transcript", Requirement: models.RequirementConditional}},
+		"transcript: only conditional documents take a condition":            {{DocumentType: "transcript", Condition: &models.DocumentCondition{Field: "x"}}},
+		"transcript: requirement must be mandatory, conditional or optional": {{DocumentType: "transcript", Requirement: "sometimes"}},
+		`transcript: unknown format "tiff"`:                                  {{DocumentType: "transcript", AcceptedFormats: []string{"tiff"}}},
+		"transcript: accepted_formats must include pdf, jpeg or png":         {{DocumentType: "transcript", AcceptedFormats: []string{"docx"}}},
+		"transcript: min_count is larger than max_count":                     {{DocumentType: "transcript", MinCount: 3, MaxCount: 2}},
+		"transcript: max_size_mb must be between 0 and 100":                  {{DocumentType: "transcript", MaxSizeMB: 500}},
+		"transcript: max_pages must be between 0 and 500":                    {{DocumentType: "transcript", MaxPages: -1}},
+	}
+	for message, invalid := range cases {
+		assert.EqualError(t, ValidateDocumentRequirements(invalid), message)
+	}
+}
+
+func TestAcceptedContentTypes(t *testing.T) {
+	allowed := []string{storage.TypePDF, storage.TypeJPEG, storage.TypePNG}
+
+	types, names := AcceptedContentTypes(nil, allowed, "PDF, JPEG, PNG")
+	assert.Equal(t, allowed, types)
+	assert.Equal(t, "PDF, JPEG, PNG", names)
+
+	req := &models.DocumentRequirement{AcceptedFormats: []string{"pdf", "docx"}}
+	types, names = AcceptedContentTypes(req, allowed, "PDF, JPEG, PNG")
+	assert.Equal(t, []string{storage.TypePDF}, types)
+	assert.Equal(t, "PDF", names)
+}
+
+func TestEvaluateDocumentChecklist(t *testing.T) {
+	now := time.Date(2025, 7, 15, 10, 0, 0, 0, time.UTC)
+	date := func(year int, month time.Month, day int) *time.Time {
+		d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+	requirements := []models.DocumentRequirement{
+		{DocumentType: "id_card", Requirement: models.RequirementMandatory, AcceptedFormats: []string{"pdf"}},
+		{DocumentType: "income_certificate", Requirement: models.RequirementMandatory, ValidMonths: 6},
+		{DocumentType: "house_photos", Requirement: models.RequirementMandatory, MinCount: 2, MaxCount: 4},
+		{DocumentType: "medical_certificate", Requirement: models.RequirementConditional,
+			Condition: &models.DocumentCondition{Field: "family_members.living_status", Values: []interface{}{"deceased"}}},
+		{DocumentType: "activity_certificate", Requirement: models.RequirementOptional},
+	}
+	form := json.RawMessage(`{"family_members":[{"living_status":"alive"},{"living_status":"deceased"}]}`)
+
+	t.Run("reports what blocks submission", func(t *testing.T) {
+		documents := []models.ApplicationDocument{
+			{DocumentID: 1, DocumentType: "id_card", MimeType: storage.TypeJPEG, UploadStatus: "verified"},
+			{DocumentID: 2, DocumentType: "income_certificate", MimeType: storage.TypePDF, UploadStatus: "pending", IssuedDate: date(2024, 12, 1)},
+			{DocumentID: 3, DocumentType: "house_photos", MimeType: storage.TypeJPEG, UploadStatus: "pending"},
+			{DocumentID: 4, DocumentType: "house_photos", MimeType: storage.TypeJPEG, UploadStatus: models.DocumentQuarantined},
+		}
+
+		checklist := EvaluateDocumentChecklist(7, 3, requirements, form, documents, now)
+
+		assert.False(t, checklist.Complete)
+		statuses := map[string]string{}
+		for _, item := range checklist.Items {
+			statuses[item.DocumentType] = item.Status
+		}
+		assert.Equal(t, map[string]string{
+			"id_card":              models.ChecklistMissing,
+			"income_certificate":   models.ChecklistExpired,
+			"house_photos":         models.ChecklistScanning,
+			"medical_certificate":  models.ChecklistMissing,
+			"activity_certificate": models.ChecklistNotRequired,
+		}, statuses)
+		assert.Equal(t, []string{
+			"Required document 'id_card' is missing",
+			"Required document 'income_certificate' must be issued on or after 2025-01-15",
+			"Required document 'house_photos' is still being scanned for malware",
+			"Required document 'medical_certificate' is missing",
+		}, checklist.Problems)
+		assert.Equal(t, "format is not accepted", checklist.Items[0].Documents[0].Problem)
+		assert.Equal(t, 1, checklist.Items[2].Count)
+	})
+
+	t.Run("complete when every requirement is met", func(t *testing.T) {
+		documents := []models.ApplicationDocument{
+			{DocumentID: 1, DocumentType: "id_card", MimeType: storage.TypePDF, UploadStatus: "verified"},
+			{DocumentID: 2, DocumentType: "income_certificate", MimeType: storage.TypePDF, UploadStatus: "pending", IssuedDate: date(2025, 3, 1)},
+			{DocumentID: 3, DocumentType: "house_photos", MimeType: storage.TypeJPEG, UploadStatus: "pending"},
+			{DocumentID: 4, DocumentType: "house_photos", MimeType: storage.TypePNG, UploadStatus: "verified"},
+		}
+		alive := json.RawMessage(`{"family_members":[{"living_status":"alive"}]}`)
+
+		checklist := EvaluateDocumentChecklist(7, 3, requirements, alive, documents, now)
+
+		assert.True(t, checklist.Complete, checklist.Problems)
+		assert.Empty(t, checklist.Problems)
+		assert.False(t, checklist.Items[3].Required)
+		assert.Equal(t, models.ChecklistNotRequired, checklist.Items[3].Status)
+	})
+
+	t.Run("needs issue dates and caps counts", func(t *testing.T) {
+		documents := []models.ApplicationDocument{
+			{DocumentID: 2, DocumentType: "income_certificate", MimeType: storage.TypePDF, UploadStatus: "pending"},
+		}
+		for i := 0; i < 5; i++ {
+			documents = append(documents, models.ApplicationDocument{DocumentID: 10 + i, DocumentType: "house_photos", MimeType: storage.TypePNG, UploadStatus: "pending"})
+		}
+
+		checklist := EvaluateDocumentChecklist(7, 3, requirements[1:3], nil, documents, now)
+
+		assert.Equal(t, models.ChecklistIssueDateMissing, checklist.Items[0].Status)
+		assert.Equal(t, models.ChecklistTooMany, checklist.Items[1].Status)
+		assert.Equal(t, "Document 'house_photos' allows at most 4 files", checklist.Items[1].Problem)
+	})
+
+	t.Run("limits the pages of PDFs once they are counted", func(t *testing.T) {
+		onePage := []models.DocumentRequirement{{DocumentType: "id_card", Requirement: models.RequirementMandatory, MaxPages: 1}}
+		ready := models.PreviewReady
+		pages := func(n int) *int { return &n }
+		documents := []models.ApplicationDocument{
+			{DocumentID: 1, DocumentType: "id_card", MimeType: storage.TypePDF, UploadStatus: "pending"},
+			{DocumentID: 2, DocumentType: "id_card", MimeType: storage.TypePDF, UploadStatus: "pending", PreviewStatus: &ready, PageCount: pages(3)},
+		}
+
+		checklist := EvaluateDocumentChecklist(7, 3, onePage, nil, documents, now)
+
+		assert.Equal(t, models.ChecklistProcessing, checklist.Items[0].Status)
+		assert.Equal(t, "pages are still being counted", checklist.Items[0].Documents[0].Problem)
+		assert.Equal(t, "has 3 pages, at most 1 allowed", checklist.Items[0].Documents[1].Problem)
+
+		documents[0].PreviewStatus = &ready
+		documents[0].PageCount = pages(2)
+		checklist = EvaluateDocumentChecklist(7, 3, onePage, nil, documents, now)
+		assert.Equal(t, []string{"Required document 'id_card' allows at most 1 pages"}, checklist.Problems)
+
+		documents = append(documents, models.ApplicationDocument{DocumentID: 3, DocumentType: "id_card", MimeType: storage.TypeJPEG, UploadStatus: "pending"})
+		checklist = EvaluateDocumentChecklist(7, 3, onePage, nil, documents, now)
+		assert.True(t, checklist.Complete, checklist.Problems)
+	})
+
+	t.Run("defaults apply without requirements", func(t *testing.T) {
+		documents := []models.ApplicationDocument{
+			{DocumentID: 1, DocumentType: "id_card", MimeType: storage.TypePDF, UploadStatus: models.DocumentInfected},
+			{DocumentID: 2, DocumentType: "transcript", MimeType: storage.TypePDF, UploadStatus: models.DocumentMissing},
+		}
+
+		checklist := EvaluateDocumentChecklist(7, 3, nil, nil, documents, now)
+
+		assert.Equal(t, []string{
+			"Required document 'id_card' is missing",
+			"Required document 'transcript' is missing",
+		}, checklist.Problems)
+	})
+}
+
+func TestConditionHolds(t *testing.T) {
+	var form interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"health_info":{"has_disability":true,"chronic":""},"siblings":[{"age":7},{"age":12}]}`), &form))
+
+	assert.True(t, conditionHolds(form, []string{"health_info", "has_disability"}, nil))
+	assert.False(t, conditionHolds(form, []string{"health_info", "chronic"}, nil))
+	assert.False(t, conditionHolds(form, []string{"assets"}, nil))
+	assert.True(t, conditionHolds(form, []string{"siblings", "age"}, []interface{}{12}))
+	assert.False(t, conditionHolds(form, []string{"siblings", "age"}, []interface{}{"3"}))
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"github.com/google/uuid"
+
+	"scholarship-system/internal/models"
+)
+
+// CurrentDocumentVersion describes the current file of a document in the
+// same shape as its earlier versions
+func CurrentDocumentVersion(doc models.ApplicationDocument) models.FileVersion {
+	documentID := doc.DocumentID
+	version := models.FileVersion{
+		VersionNumber:     doc.VersionNumber,
+		UploadedBy:        doc.UploadedBy,
+		FileSize:          doc.FileSize,
+		IsCurrent:         true,
+		CreatedAt:         doc.UploadedAt,
+		DocumentID:        &documentID,
+		DocumentName:      doc.DocumentName,
+		FilePath:          doc.FilePath,
+		StorageBackend:    doc.StorageBackend,
+		MimeType:          doc.MimeType,
+		FileHash:          doc.FileHash,
+		UploadStatus:      doc.UploadStatus,
+		VerificationNotes: doc.VerificationNotes,
+		VerifiedAt:        doc.VerifiedAt,
+	}
+	if doc.VerifiedBy != nil {
+		if id, err := uuid.Parse(*doc.VerifiedBy); err == nil {
+			version.VerifiedBy = &id
+		}
+	}
+	return version
+}
+
+// DiffDocumentVersions compares two versions of a document. Files are
+// compared by hash, so SameFile is only reported when both hashes are known.
+func DiffDocumentVersions(from, to models.FileVersion) *models.DocumentVersionDiff {
+	diff := &models.DocumentVersionDiff{
+		From:     from,
+		To:       to,
+		SameFile: from.FileHash != "" && from.FileHash == to.FileHash,
+		Changes:  []models.FieldChange{},
+	}
+	if to.DocumentID != nil {
+		diff.DocumentID = *to.DocumentID
+	}
+
+	add := func(path string, oldValue, newValue interface{}) {
+		diff.Changes = append(diff.Changes, models.FieldChange{Section: "document", Path: path, OldValue: oldValue, NewValue: newValue})
+	}
+	if from.DocumentName != to.DocumentName {
+		add("document_name", from.DocumentName, to.DocumentName)
+	}
+	if from.FileSize != to.FileSize {
+		add("file_size", from.FileSize, to.FileSize)
+	}
+	if from.MimeType != to.MimeType {
+		add("mime_type", from.MimeType, to.MimeType)
+	}
+	if !diff.SameFile {
+		add("file_hash", from.FileHash, to.FileHash)
+	}
+	if from.UploadStatus != to.UploadStatus {
+		add("upload_status", from.UploadStatus, to.UploadStatus)
+	}
+	if !sameUUID(from.VerifiedBy, to.VerifiedBy) {
+		add("verified_by", from.VerifiedBy, to.VerifiedBy)
+	}
+	if !sameString(from.VerificationNotes, to.VerificationNotes) {
+		add("verification_notes", from.VerificationNotes, to.VerificationNotes)
+	}
+	return diff
+}
+
+func sameUUID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func sameString(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scholarship-system/internal/models"
+)
+
+func TestDiffDocumentVersions(t *testing.T) {
+	officer := uuid.New()
+	verifiedAt := time.Now().Add(-time.Hour)
+	notes := "Income matches the declared amount"
+	previous := models.FileVersion{
+		VersionNumber:     1,
+		DocumentName:      "income_2024.pdf",
+		FileSize:          1200,
+		MimeType:          "application/pdf",
+		FileHash:          "aaa",
+		UploadStatus:      "verified",
+		VerificationNotes: &notes,
+		VerifiedBy:        &officer,
+		VerifiedAt:        &verifiedAt,
+	}
+
+	officerID := officer.String()
+	current := CurrentDocumentVersion(models.ApplicationDocument{
+		DocumentID:    9,
+		DocumentName:  "income_2025.pdf",
+		FileSize:      1500,
+		MimeType:      "application/pdf",
+		FileHash:      "bbb",
+		UploadStatus:  models.DocumentPending,
+		VersionNumber: 2,
+		UploadedAt:    time.Now(),
+	})
+	assert.True(t, current.IsCurrent)
+	assert.Nil(t, current.VerifiedBy)
+
+	diff := DiffDocumentVersions(previous, current)
+	assert.Equal(t, 9, diff.DocumentID)
+	assert.False(t, diff.SameFile)
+	paths := map[string]models.FieldChange{}
+	for _, change := range diff.Changes {
+		paths[change.Path] = change
+	}
+	assert.Contains(t, paths, "document_name")
+	assert.Contains(t, paths, "file_size")
+	assert.Contains(t, paths, "file_hash")
+	assert.Contains(t, paths, "upload_status")
+	assert.Contains(t, paths, "verified_by")
+	assert.Contains(t, paths, "verification_notes")
+	assert.NotContains(t, paths, "mime_type")
+
+	// The same file uploaded again and verified by the same officer
+	again := CurrentDocumentVersion(models.ApplicationDocument{
+		DocumentID: 9, DocumentName: "income_2024.pdf", FileSize: 1200, MimeType: "application/pdf",
+		FileHash: "aaa", UploadStatus: "verified", VerificationNotes: &notes, VerifiedBy: &officerID,
+	})
+	require.NotNil(t, again.VerifiedBy)
+	diff = DiffDocumentVersions(previous, again)
+	assert.True(t, diff.SameFile)
+	assert.Empty(t, diff.Changes)
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadLink(t *testing.T) {
+	secret := []byte("download-secret")
+	now := time.Now()
+	link := DownloadLink{
+		DocumentID:    42,
+		VersionNumber: 3,
+		UserID:        uuid.New(),
+		Inline:        true,
+		ExpiresAt:     now.Add(5 * time.Minute),
+	}
+	query := link.Query(secret)
+
+	verified, err := VerifyDownloadLink(secret, 42, query, now)
+	require.NoError(t, err)
+	assert.Equal(t, link.UserID, verified.UserID)
+	assert.Equal(t, 3, verified.VersionNumber)
+	assert.True(t, verified.Inline)
+
+	// Bound to the document, the secret and every signed field
+	_, err = VerifyDownloadLink(secret, 43, query, now)
+	assert.Equal(t, ErrDownloadLinkInvalid, err)
+	_, err = VerifyDownloadLink([]byte("other-secret"), 42, query, now)
+	assert.Equal(t, ErrDownloadLinkInvalid, err)
+	for field, value := range map[string]string{"uid": uuid.NewString(), "v": "2", "inline": "0", "expires": "9999999999"} {
+		tampered := link.Query(secret)
+		tampered.Set(field, value)
+		_, err = VerifyDownloadLink(secret, 42, tampered, now)
+		assert.Equal(t, ErrDownloadLinkInvalid, err, field)
+	}
+	tampered := link.Query(secret)
+	tampered.Del("sig")
+	_, err = VerifyDownloadLink(secret, 42, tampered, now)
+	assert.Equal(t, ErrDownloadLinkInvalid, err)
+
+	_, err = VerifyDownloadLink(secret, 42, query, now.Add(5*time.Minute))
+	assert.Equal(t, ErrDownloadLinkExpired, err)
+}
+
+func TestDownloadLinkSecret(t *testing.T) {
+	assert.Equal(t, []byte("download-secret"), DownloadLinkSecret("download-secret", "jwt-secret"))
+
+	derived := DownloadLinkSecret("", "jwt-secret")
+	assert.Len(t, derived, 32)
+	assert.NotEqual(t, []byte("jwt-secret"), derived)
+	assert.Equal(t, derived, DownloadLinkSecret("", "jwt-secret"))
+	assert.NotEqual(t, derived, DownloadLinkSecret("", "other-jwt-secret"))
+}
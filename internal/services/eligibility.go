@@ -0,0 +1,284 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"scholarship-system/internal/models"
+	"scholarship-system/internal/repository"
+)
+
+// EligibilityService evaluates scholarship eligibility rules against student
+// data loaded from the database
+type EligibilityService struct {
+	repo *repository.EligibilityRepository
+}
+
+func NewEligibilityService() *EligibilityService {
+	return &EligibilityService{
+		repo: repository.NewEligibilityRepository(),
+	}
+}
+
+// LoadProfile loads the eligibility profile of a student
+func (s *EligibilityService) LoadProfile(studentID string) (*models.EligibilityProfile, error) {
+	return s.repo.GetProfile(studentID)
+}
+
+// Check evaluates a scholarship's rules for a student. A non-nil familyIncome
+// takes precedence over the income recorded on the student's applications.
+func (s *EligibilityService) Check(scholarship *models.Scholarship, studentID string, familyIncome *float64) (*models.EligibilityResult, error) {
+	profile, err := s.repo.GetProfile(studentID)
+	if err != nil {
+		return nil, err
+	}
+	if familyIncome != nil {
+		profile.FamilyIncome = familyIncome
+	}
+	return EvaluateEligibility(scholarship.ScholarshipID, scholarship.EligibilityRules, profile), nil
+}
+
+// Eligibility rule names used in EligibilityRuleResult.Rule
+const (
+	RuleMinGPA             = "min_gpa"
+	RuleMinYearLevel       = "min_year_level"
+	RuleMaxYearLevel       = "max_year_level"
+	RuleAllowedFaculties   = "allowed_faculties"
+	RuleExcludedFaculties  = "excluded_faculties"
+	RuleMaxFamilyIncome    = "max_family_income"
+	RuleStudentStatus      = "allowed_student_statuses"
+	RulePriorRecipient     = "exclude_prior_recipients"
+	RuleExcludedAwardTypes = "excluded_award_types"
+	RuleMaxActiveAwards    = "max_active_awards"
+)
+
+// EvaluateEligibility checks a student profile against a scholarship's rules.
+// Each configured rule produces one result with a Thai reason. A rule whose
+// input data is missing is reported as failed and listed in
+// MissingRequirements, since it cannot be verified.
+func EvaluateEligibility(scholarshipID uint, rules *models.EligibilityRules, profile *models.EligibilityProfile) *models.EligibilityResult {
+	result := &models.EligibilityResult{
+		ScholarshipID:       scholarshipID,
+		IsEligible:          true,
+		EligibilityScore:    100,
+		Results:             []models.EligibilityRuleResult{},
+		FailedReasons:       []string{},
+		MissingRequirements: []string{},
+	}
+
+	if rules == nil {
+		return result
+	}
+	if profile == nil {
+		profile = &models.EligibilityProfile{}
+	}
+
+	add := func(r models.EligibilityRuleResult) {
+		result.Results = append(result.Results, r)
+		if r.Passed {
+			return
+		}
+		result.IsEligible = false
+		if r.Missing {
+			result.MissingRequirements = append(result.MissingRequirements, r.Reason)
+		} else {
+			result.FailedReasons = append(result.FailedReasons, r.Reason)
+		}
+	}
+
+	if rules.MinGPA != nil {
+		r := models.EligibilityRuleResult{Rule: RuleMinGPA, Required: *rules.MinGPA}
+		if profile.GPA == nil {
+			r.Missing = true
+			r.Reason = "ไม่พบข้อมูลเกรดเฉลี่ย (GPA)"
+		} else {
+			r.Actual = *profile.GPA
+			r.Passed = *profile.GPA >= *rules.MinGPA
+			if r.Passed {
+				r.Reason = fmt.Sprintf("เกรดเฉลี่ย %.2f ผ่านเกณฑ์ขั้นต่ำ %.2f", *profile.GPA, *rules.MinGPA)
+			} else {
+				r.Reason = fmt.Sprintf("เกรดเฉลี่ยต้องไม่ต่ำกว่า %.2f (ปัจจุบัน %.2f)", *rules.MinGPA, *profile.GPA)
+			}
+		}
+		add(r)
+	}
+
+	if rules.MinYearLevel != nil {
+		r := models.EligibilityRuleResult{Rule: RuleMinYearLevel, Required: *rules.MinYearLevel}
+		if profile.YearLevel == nil {
+			r.Missing = true
+			r.Reason = "ไม่พบข้อมูลชั้นปี"
+		} else {
+			r.Actual = *profile.YearLevel
+			r.Passed = *profile.YearLevel >= *rules.MinYearLevel
+			if r.Passed {
+				r.Reason = fmt.Sprintf("ชั้นปีที่ %d ผ่านเกณฑ์", *profile.YearLevel)
+			} else {
+				r.Reason = fmt.Sprintf("ต้องเป็นนักศึกษาชั้นปีที่ %d ขึ้นไป (ปัจจุบันชั้นปีที่ %d)", *rules.MinYearLevel, *profile.YearLevel)
+			}
+		}
+		add(r)
+	}
+
+	if rules.MaxYearLevel != nil {
+		r := models.EligibilityRuleResult{Rule: RuleMaxYearLevel, Required: *rules.MaxYearLevel}
+		if profile.YearLevel == nil {
+			r.Missing = true
+			r.Reason = "ไม่พบข้อมูลชั้นปี"
+		} else {
+			r.Actual = *profile.YearLevel
+			r.Passed = *profile.YearLevel <= *rules.MaxYearLevel
+			if r.Passed {
+				r.Reason = fmt.Sprintf("ชั้นปีที่ %d ผ่านเกณฑ์", *profile.YearLevel)
+			} else {
+				r.Reason = fmt.Sprintf("ต้องเป็นนักศึกษาชั้นปีที่ %d หรือต่ำกว่า (ปัจจุบันชั้นปีที่ %d)", *rules.MaxYearLevel, *profile.YearLevel)
+			}
+		}
+		add(r)
+	}
+
+	if len(rules.AllowedFaculties) > 0 {
+		r := models.EligibilityRuleResult{Rule: RuleAllowedFaculties, Required: rules.AllowedFaculties}
+		if profile.FacultyCode == nil || *profile.FacultyCode == "" {
+			r.Missing = true
+			r.Reason = "ไม่พบข้อมูลคณะ"
+		} else {
+			r.Actual = *profile.FacultyCode
+			r.Passed = containsFold(rules.AllowedFaculties, *profile.FacultyCode)
+			if r.Passed {
+				r.Reason = fmt.Sprintf("คณะ %s อยู่ในรายชื่อคณะที่มีสิทธิ์", *profile.FacultyCode)
+			} else {
+				r.Reason = fmt.Sprintf("ทุนนี้เปิดรับเฉพาะคณะ %s", strings.Join(rules.AllowedFaculties, ", "))
+			}
+		}
+		add(r)
+	}
+
+	if len(rules.ExcludedFaculties) > 0 {
+		r := models.EligibilityRuleResult{Rule: RuleExcludedFaculties, Required: rules.ExcludedFaculties}
+		if profile.FacultyCode == nil || *profile.FacultyCode == "" {
+			r.Missing = true
+			r.Reason = "ไม่พบข้อมูลคณะ"
+		} else {
+			r.Actual = *profile.FacultyCode
+			r.Passed = !containsFold(rules.ExcludedFaculties, *profile.FacultyCode)
+			if r.Passed {
+				r.Reason = fmt.Sprintf("คณะ %s ไม่อยู่ในรายชื่อคณะที่ถูกยกเว้น", *profile.FacultyCode)
+			} else {
+				r.Reason = fmt.Sprintf("ทุนนี้ไม่เปิดรับนักศึกษาคณะ %s", *profile.FacultyCode)
+			}
+		}
+		add(r)
+	}
+
+	if rules.MaxFamilyIncome != nil {
+		r := models.EligibilityRuleResult{Rule: RuleMaxFamilyIncome, Required: *rules.MaxFamilyIncome}
+		if profile.FamilyIncome == nil {
+			r.Missing = true
+			r.Reason = "ไม่พบข้อมูลรายได้ครอบครัว"
+		} else {
+			r.Actual = *profile.FamilyIncome
+			r.Passed = *profile.FamilyIncome <= *rules.MaxFamilyIncome
+			if r.Passed {
+				r.Reason = fmt.Sprintf("รายได้ครอบครัว %.0f บาท/เดือน ไม่เกินเพดาน", *profile.FamilyIncome)
+			} else {
+				r.Reason = fmt.Sprintf("รายได้ครอบครัวต้องไม่เกิน %.0f บาท/เดือน (ปัจจุบัน %.0f บาท/เดือน)", *rules.MaxFamilyIncome, *profile.FamilyIncome)
+			}
+		}
+		add(r)
+	}
+
+	if len(rules.AllowedStudentStatuses) > 0 {
+		r := models.EligibilityRuleResult{Rule: RuleStudentStatus, Required: rules.AllowedStudentStatuses}
+		if profile.StudentStatus == nil || *profile.StudentStatus == "" {
+			r.Missing = true
+			r.Reason = "ไม่พบข้อมูลสถานะนักศึกษา"
+		} else {
+			r.Actual = *profile.StudentStatus
+			r.Passed = containsFold(rules.AllowedStudentStatuses, *profile.StudentStatus)
+			if r.Passed {
+				r.Reason = fmt.Sprintf("สถานะนักศึกษา %s ผ่านเกณฑ์", *profile.StudentStatus)
+			} else {
+				r.Reason = fmt.Sprintf("สถานะนักศึกษาต้องเป็น %s (ปัจจุบัน %s)", strings.Join(rules.AllowedStudentStatuses, ", "), *profile.StudentStatus)
+			}
+		}
+		add(r)
+	}
+
+	if rules.ExcludePriorRecipients {
+		r := models.EligibilityRuleResult{Rule: RulePriorRecipient, Required: true, Passed: true}
+		for _, award := range profile.PriorAwards {
+			if award.ScholarshipID == scholarshipID {
+				r.Passed = false
+				r.Actual = award.AcademicYear
+				break
+			}
+		}
+		if r.Passed {
+			r.Reason = "ไม่เคยได้รับทุนนี้มาก่อน"
+		} else {
+			r.Reason = "เคยได้รับทุนนี้แล้ว ไม่สามารถสมัครซ้ำได้"
+		}
+		add(r)
+	}
+
+	if len(rules.ExcludedAwardTypes) > 0 {
+		r := models.EligibilityRuleResult{Rule: RuleExcludedAwardTypes, Required: rules.ExcludedAwardTypes, Passed: true}
+		for _, award := range profile.PriorAwards {
+			if award.Active && containsFold(rules.ExcludedAwardTypes, award.ScholarshipType) {
+				r.Passed = false
+				r.Actual = award.ScholarshipType
+				break
+			}
+		}
+		if r.Passed {
+			r.Reason = "ไม่ได้รับทุนประเภทที่ซ้ำซ้อนอยู่"
+		} else {
+			r.Reason = fmt.Sprintf("กำลังได้รับทุนประเภท %s อยู่ ซึ่งไม่สามารถรับซ้อนกับทุนนี้ได้", r.Actual)
+		}
+		add(r)
+	}
+
+	if rules.MaxActiveAwards != nil {
+		active := 0
+		for _, award := range profile.PriorAwards {
+			if award.Active {
+				active++
+			}
+		}
+		r := models.EligibilityRuleResult{
+			Rule:     RuleMaxActiveAwards,
+			Required: *rules.MaxActiveAwards,
+			Actual:   active,
+			Passed:   active < *rules.MaxActiveAwards,
+		}
+		if r.Passed {
+			r.Reason = fmt.Sprintf("จำนวนทุนที่ได้รับอยู่ (%d) ยังไม่เกินกำหนด", active)
+		} else {
+			r.Reason = fmt.Sprintf("ได้รับทุนอยู่แล้ว %d ทุน เกินจำนวนที่กำหนด (%d)", active, *rules.MaxActiveAwards)
+		}
+		add(r)
+	}
+
+	if len(result.Results) > 0 {
+		passed := 0
+		for _, r := range result.Results {
+			if r.Passed {
+				passed++
+			}
+		}
+		result.EligibilityScore = math.Round(float64(passed)/float64(len(result.Results))*10000) / 100
+	}
+
+	return result
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimSpace(item), strings.TrimSpace(value)) {
+			return true
+		}
+	}
+	return false
+}
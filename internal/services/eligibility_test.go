@@ -0,0 +1,91 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"scholarship-system/internal/models"
+)
+
+func floatPtr(v float64) *float64 { return &v }
+func intPtr(v int) *int           { return &v }
+func strPtr(v string) *string     { return &v }
+
+func TestEvaluateEligibility(t *testing.T) {
+	rules := &models.EligibilityRules{
+		MinGPA:                 floatPtr(3.0),
+		MinYearLevel:           intPtr(2),
+		AllowedFaculties:       []string{"ENG", "SCI"},
+		MaxFamilyIncome:        floatPtr(30000),
+		AllowedStudentStatuses: []string{"active"},
+		ExcludePriorRecipients: true,
+	}
+
+	t.Run("eligible student passes every rule", func(t *testing.T) {
+		profile := &models.EligibilityProfile{
+			GPA:           floatPtr(3.25),
+			YearLevel:     intPtr(3),
+			FacultyCode:   strPtr("eng"),
+			StudentStatus: strPtr("active"),
+			FamilyIncome:  floatPtr(15000),
+		}
+
+		result := EvaluateEligibility(1, rules, profile)
+
+		assert.True(t, result.IsEligible)
+		assert.Len(t, result.Results, 6)
+		assert.Equal(t, 100.0, result.EligibilityScore)
+		assert.Empty(t, result.FailedReasons)
+	})
+
+	t.Run("failed and missing rules are explained", func(t *testing.T) {
+		profile := &models.EligibilityProfile{
+			GPA:           floatPtr(2.5),
+			YearLevel:     intPtr(3),
+			FacultyCode:   strPtr("SCI"),
+			StudentStatus: strPtr("active"),
+			PriorAwards:   []models.PriorAward{{ScholarshipID: 1, AcademicYear: "2567", Active: true}},
+		}
+
+		result := EvaluateEligibility(1, rules, profile)
+
+		assert.False(t, result.IsEligible)
+		assert.Len(t, result.FailedReasons, 2)
+		assert.Len(t, result.MissingRequirements, 1)
+		assert.Equal(t, 50.0, result.EligibilityScore)
+
+		for _, r := range result.Results {
+			assert.NotEmpty(t, r.Reason, r.Rule)
+			if r.Rule == RuleMaxFamilyIncome {
+				assert.True(t, r.Missing)
+			}
+		}
+	})
+
+	t.Run("no rules means eligible", func(t *testing.T) {
+		result := EvaluateEligibility(1, nil, nil)
+
+		assert.True(t, result.IsEligible)
+		assert.Empty(t, result.Results)
+	})
+
+	t.Run("award limits only count active awards", func(t *testing.T) {
+		limits := &models.EligibilityRules{
+			ExcludedAwardTypes: []string{"need_based"},
+			MaxActiveAwards:    intPtr(1),
+		}
+		profile := &models.EligibilityProfile{
+			PriorAwards: []models.PriorAward{
+				{ScholarshipID: 5, ScholarshipType: "need_based", Active: false},
+				{ScholarshipID: 6, ScholarshipType: "merit", Active: true},
+			},
+		}
+
+		result := EvaluateEligibility(1, limits, profile)
+
+		assert.False(t, result.IsEligible)
+		assert.True(t, result.Results[0].Passed)
+		assert.False(t, result.Results[1].Passed)
+	})
+}
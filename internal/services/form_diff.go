@@ -0,0 +1,190 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"scholarship-system/internal/models"
+)
+
+// formKeySections maps CompleteApplicationForm JSON keys to form sections
+var formKeySections = map[string]string{
+	"personal_info":       models.SectionPersonalInfo,
+	"addresses":           models.SectionAddressInfo,
+	"education_history":   models.SectionEducationHistory,
+	"family_members":      models.SectionFamilyInfo,
+	"guardians":           models.SectionFamilyInfo,
+	"siblings":            models.SectionFamilyInfo,
+	"living_situation":    models.SectionFamilyInfo,
+	"financial_info":      models.SectionFinancialInfo,
+	"assets":              models.SectionFinancialInfo,
+	"scholarship_history": models.SectionFinancialInfo,
+	"health_info":         models.SectionFinancialInfo,
+	"funding_needs":       models.SectionFinancialInfo,
+	"activities":          models.SectionActivitiesSkills,
+	"references":          models.SectionActivitiesSkills,
+}
+
+// FormSection returns the section a CompleteApplicationForm key belongs to
+func FormSection(key string) string {
+	if section, ok := formKeySections[key]; ok {
+		return section
+	}
+	return key
+}
+
+// DiffApplicationForms compares two JSON encodings of a
+// CompleteApplicationForm and lists the changed values. Timestamps and
+// record ids are ignored since they change whenever a section is re-saved,
+// and sql.Null* wrappers are compared by their value.
+func DiffApplicationForms(before, after []byte) ([]models.FieldChange, error) {
+	var oldForm, newForm map[string]interface{}
+	if len(before) > 0 {
+		if err := json.Unmarshal(before, &oldForm); err != nil {
+			return nil, fmt.Errorf("failed to decode previous form: %w", err)
+		}
+	}
+	if len(after) > 0 {
+		if err := json.Unmarshal(after, &newForm); err != nil {
+			return nil, fmt.Errorf("failed to decode current form: %w", err)
+		}
+	}
+
+	keys := map[string]bool{}
+	for k := range oldForm {
+		keys[k] = true
+	}
+	for k := range newForm {
+		keys[k] = true
+	}
+	delete(keys, "application")
+
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	changes := []models.FieldChange{}
+	for _, key := range sorted {
+		section := FormSection(key)
+		diffValues(section, key, normalizeFormValue(oldForm[key]), normalizeFormValue(newForm[key]), &changes)
+	}
+	return changes, nil
+}
+
+// DiffDocuments lists documents added and removed between two snapshots. A
+// document replaced by a new version keeps its ID, so it shows up as both:
+// the old upload removed and the new one added.
+func DiffDocuments(before, after []models.RevisionDocumentRef) (added, removed []models.RevisionDocumentRef) {
+	same := func(a, b models.RevisionDocumentRef) bool {
+		return a.DocumentID == b.DocumentID && a.UploadedAt.Equal(b.UploadedAt)
+	}
+	contains := func(docs []models.RevisionDocumentRef, doc models.RevisionDocumentRef) bool {
+		for _, other := range docs {
+			if same(other, doc) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, doc := range after {
+		if !contains(before, doc) {
+			added = append(added, doc)
+		}
+	}
+	for _, doc := range before {
+		if !contains(after, doc) {
+			removed = append(removed, doc)
+		}
+	}
+	return added, removed
+}
+
+func diffValues(section, path string, oldValue, newValue interface{}, changes *[]models.FieldChange) {
+	oldMap, oldIsMap := oldValue.(map[string]interface{})
+	newMap, newIsMap := newValue.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		keys := map[string]bool{}
+		for k := range oldMap {
+			keys[k] = true
+		}
+		for k := range newMap {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			diffValues(section, path+"."+k, oldMap[k], newMap[k], changes)
+		}
+		return
+	}
+
+	oldList, oldIsList := oldValue.([]interface{})
+	newList, newIsList := newValue.([]interface{})
+	if oldIsList || newIsList {
+		if !oldIsList && oldValue != nil || !newIsList && newValue != nil {
+			*changes = append(*changes, models.FieldChange{Section: section, Path: path, OldValue: oldValue, NewValue: newValue})
+			return
+		}
+		n := len(oldList)
+		if len(newList) > n {
+			n = len(newList)
+		}
+		for i := 0; i < n; i++ {
+			var o, v interface{}
+			if i < len(oldList) {
+				o = oldList[i]
+			}
+			if i < len(newList) {
+				v = newList[i]
+			}
+			diffValues(section, fmt.Sprintf("%s[%d]", path, i), o, v, changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(oldValue, newValue) {
+		*changes = append(*changes, models.FieldChange{Section: section, Path: path, OldValue: oldValue, NewValue: newValue})
+	}
+}
+
+// normalizeFormValue drops noisy keys and unwraps sql.Null* encodings
+// ({"String": "x", "Valid": true}) into plain values
+func normalizeFormValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if valid, ok := v["Valid"].(bool); ok && len(v) == 2 {
+			if !valid {
+				return nil
+			}
+			for k, inner := range v {
+				if k != "Valid" {
+					return inner
+				}
+			}
+		}
+		out := make(map[string]interface{}, len(v))
+		for k, inner := range v {
+			if k == "created_at" || k == "updated_at" || strings.HasSuffix(k, "_id") {
+				continue
+			}
+			out[k] = normalizeFormValue(inner)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, inner := range v {
+			out[i] = normalizeFormValue(inner)
+		}
+		return out
+	}
+	return value
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scholarship-system/internal/models"
+)
+
+func TestDiffApplicationForms(t *testing.T) {
+	before := []byte(`{
+		"application": {"application_status": "submitted"},
+		"personal_info": {"info_id": "a", "phone": {"String": "0811111111", "Valid": true}, "updated_at": "2024-01-01T00:00:00Z"},
+		"family_members": [{"member_id": "m1", "first_name": "Somchai", "monthly_income": {"Float64": 15000, "Valid": true}}]
+	}`)
+	after := []byte(`{
+		"application": {"application_status": "needs_revision"},
+		"personal_info": {"info_id": "b", "phone": {"String": "0822222222", "Valid": true}, "updated_at": "2024-02-01T00:00:00Z"},
+		"family_members": [
+			{"member_id": "m9", "first_name": "Somchai", "monthly_income": {"Float64": 12000, "Valid": true}},
+			{"member_id": "m10", "first_name": "Somsri", "monthly_income": {"Float64": 0, "Valid": false}}
+		]
+	}`)
+
+	changes, err := DiffApplicationForms(before, after)
+	require.NoError(t, err)
+
+	paths := map[string]models.FieldChange{}
+	for _, change := range changes {
+		paths[change.Path] = change
+	}
+
+	assert.Len(t, changes, 3)
+	assert.Equal(t, "0822222222", paths["personal_info.phone"].NewValue)
+	assert.Equal(t, models.SectionPersonalInfo, paths["personal_info.phone"].Section)
+	assert.Equal(t, 12000.0, paths["family_members[0].monthly_income"].NewValue)
+	assert.Equal(t, models.SectionFamilyInfo, paths["family_members[1]"].Section)
+	assert.Nil(t, paths["family_members[1]"].OldValue)
+}
+
+func TestDiffDocuments(t *testing.T) {
+	now := time.Now()
+	before := []models.RevisionDocumentRef{
+		{DocumentID: 1, DocumentType: "id_card", UploadedAt: now},
+		{DocumentID: 2, DocumentType: "transcript", UploadedAt: now},
+	}
+	after := []models.RevisionDocumentRef{
+		{DocumentID: 1, DocumentType: "id_card", UploadedAt: now},
+		{DocumentID: 3, DocumentType: "transcript", UploadedAt: now},
+	}
+
+	added, removed := DiffDocuments(before, after)
+
+	require.Len(t, added, 1)
+	require.Len(t, removed, 1)
+	assert.Equal(t, 3, added[0].DocumentID)
+	assert.Equal(t, 2, removed[0].DocumentID)
+}
+
+func TestDiffDocumentsReplacedVersion(t *testing.T) {
+	now := time.Now()
+	before := []models.RevisionDocumentRef{{DocumentID: 5, DocumentType: "income_certificate", UploadedAt: now}}
+	after := []models.RevisionDocumentRef{{DocumentID: 5, DocumentType: "income_certificate", UploadedAt: now.Add(time.Hour)}}
+
+	added, removed := DiffDocuments(before, after)
+
+	require.Len(t, added, 1)
+	require.Len(t, removed, 1)
+	assert.True(t, added[0].UploadedAt.After(removed[0].UploadedAt))
+}
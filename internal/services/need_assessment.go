@@ -0,0 +1,216 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"scholarship-system/internal/models"
+)
+
+// AssessNeed derives household size, per-capita income, debt and asset
+// adjustments and the unmet need of an application from its form data.
+//
+// Household income is the monthly income of the family members, guardians,
+// working siblings (at WorkingSiblingShare) and the applicant. Debt is spread
+// over DebtRepaymentMonths and deducted together with the monthly cost of
+// liabilities; non-exempt assets above AssetExemption add AssetMonthlyRate of
+// their value. The family is expected to contribute ContributionRate of the
+// per-capita income above SubsistencePerCapita, and whatever the student's
+// costs exceed that contribution is the unmet need, capped at the amount
+// requested.
+func AssessNeed(form *models.CompleteApplicationForm, params models.NeedParameters) *models.NeedAssessment {
+	assessment := &models.NeedAssessment{
+		HouseholdSize: 1,
+		IncomeLines:   []models.NeedIncomeLine{},
+		Notes:         []string{},
+		AssessedAt:    time.Now(),
+	}
+	if form == nil {
+		return assessment
+	}
+	if form.Application != nil {
+		assessment.ApplicationID = form.Application.ApplicationID
+	}
+
+	addIncome := func(source, label string, amount float64) {
+		if amount <= 0 {
+			return
+		}
+		assessment.IncomeLines = append(assessment.IncomeLines, models.NeedIncomeLine{
+			Source: source, Label: label, Amount: roundMoney(amount),
+		})
+		assessment.DeclaredIncome += amount
+	}
+
+	for _, member := range form.FamilyMembers {
+		if member.LivingStatus.Valid && containsFold(params.ExcludedLivingStatuses, member.LivingStatus.String) {
+			continue
+		}
+		assessment.HouseholdSize++
+		if member.MonthlyIncome.Valid {
+			addIncome("family_member", personLabel(member.Relationship, member.FirstName, member.LastName), member.MonthlyIncome.Float64)
+		}
+	}
+
+	for _, guardian := range form.Guardians {
+		if params.CountGuardians {
+			assessment.HouseholdSize++
+		}
+		if guardian.MonthlyIncome.Valid {
+			addIncome("guardian", personLabel(guardian.Relationship.String, guardian.FirstName, guardian.LastName), guardian.MonthlyIncome.Float64)
+		}
+		if guardian.Debts.Valid && guardian.Debts.Float64 > 0 {
+			assessment.TotalDebt += guardian.Debts.Float64
+		}
+	}
+
+	for _, sibling := range form.Siblings {
+		if params.CountSiblings {
+			assessment.HouseholdSize++
+		}
+		if sibling.IsWorking && sibling.MonthlyIncome.Valid {
+			addIncome("sibling", fmt.Sprintf("พี่น้องคนที่ %d", sibling.SiblingOrder), sibling.MonthlyIncome.Float64*params.WorkingSiblingShare)
+		}
+	}
+
+	if info := form.FinancialInfo; info != nil && params.IncludeStudentIncome && info.HasIncome && info.MonthlyIncome.Valid {
+		addIncome("student", "รายได้ของผู้สมัคร", info.MonthlyIncome.Float64)
+	}
+
+	// Income certificates are a cross-check of the declared incomes
+	for _, cert := range form.IncomeCertificates {
+		if cert.MonthlyIncome.Valid {
+			assessment.CertifiedIncome += cert.MonthlyIncome.Float64
+		}
+	}
+	assessment.HouseholdIncome = assessment.DeclaredIncome
+	hasCertificates := assessment.CertifiedIncome > 0
+	switch params.IncomeBasis {
+	case models.IncomeBasisCertified:
+		if hasCertificates {
+			assessment.HouseholdIncome = assessment.CertifiedIncome
+		} else {
+			assessment.Notes = append(assessment.Notes, "ไม่มีหนังสือรับรองรายได้ ใช้รายได้ตามที่กรอกในใบสมัคร")
+		}
+	case models.IncomeBasisMax:
+		assessment.HouseholdIncome = math.Max(assessment.DeclaredIncome, assessment.CertifiedIncome)
+	}
+	if hasCertificates && math.Abs(assessment.CertifiedIncome-assessment.DeclaredIncome) > 0.2*math.Max(assessment.DeclaredIncome, 1) {
+		assessment.Notes = append(assessment.Notes, fmt.Sprintf("รายได้ตามหนังสือรับรอง (%.2f บาท) ต่างจากที่กรอกในใบสมัคร (%.2f บาท) เกิน 20%%",
+			assessment.CertifiedIncome, assessment.DeclaredIncome))
+	}
+
+	// Liabilities add to the debt, other assets may count as income
+	for _, asset := range form.Assets {
+		if asset.Category.Valid && asset.Category.String == "liability" {
+			if asset.Value.Valid {
+				assessment.TotalDebt += asset.Value.Float64
+			}
+			if asset.MonthlyCost.Valid {
+				assessment.DebtBurden += asset.MonthlyCost.Float64
+			}
+			continue
+		}
+		if containsFold(params.ExemptAssetTypes, asset.AssetType) || !asset.Value.Valid {
+			continue
+		}
+		assessment.AssetValue += asset.Value.Float64
+	}
+	if params.DebtRepaymentMonths > 0 {
+		assessment.DebtBurden += assessment.TotalDebt / float64(params.DebtRepaymentMonths)
+	}
+	if excess := assessment.AssetValue - params.AssetExemption; excess > 0 {
+		assessment.AssetAdjustment = excess * params.AssetMonthlyRate
+		if params.MaxAssetAdjustment > 0 && assessment.AssetAdjustment > params.MaxAssetAdjustment {
+			assessment.AssetAdjustment = params.MaxAssetAdjustment
+		}
+	}
+
+	assessment.AdjustedIncome = math.Max(0, assessment.HouseholdIncome+assessment.AssetAdjustment-assessment.DebtBurden)
+	assessment.PerCapitaIncome = assessment.AdjustedIncome / float64(assessment.HouseholdSize)
+
+	// What studying costs the student and how much of it the family can carry
+	if info := form.FinancialInfo; info != nil {
+		assessment.StudentMonthlyCosts += info.MonthlyDormCost.Float64 + info.OtherMonthlyCosts.Float64
+		assessment.StudentMonthlyCosts += info.DailyTravelCost.Float64 * float64(params.StudyDaysPerMonth)
+	}
+	if health := form.HealthInfo; health != nil {
+		assessment.StudentMonthlyCosts += health.MonthlyMedicalCost.Float64
+	}
+	assessment.AnnualCosts = assessment.StudentMonthlyCosts * float64(params.AcademicMonths)
+	if needs := form.FundingNeeds; needs != nil {
+		assessment.AnnualCosts += needs.TuitionSupport.Float64 + needs.BookSupport.Float64
+		assessment.RequestedAmount = needs.TotalRequested.Float64
+		if !needs.TotalRequested.Valid {
+			assessment.RequestedAmount = needs.TuitionSupport.Float64 + needs.MonthlySupport.Float64 + needs.BookSupport.Float64 +
+				needs.DormSupport.Float64 + needs.OtherSupport.Float64
+		}
+	}
+
+	if surplus := assessment.PerCapitaIncome - params.SubsistencePerCapita; surplus > 0 {
+		assessment.AnnualFamilyContribution = surplus * params.ContributionRate * float64(params.AcademicMonths)
+	}
+	assessment.UnmetNeed = math.Max(0, assessment.AnnualCosts-assessment.AnnualFamilyContribution)
+	if assessment.RequestedAmount > 0 && assessment.UnmetNeed > assessment.RequestedAmount {
+		assessment.UnmetNeed = assessment.RequestedAmount
+		assessment.Notes = append(assessment.Notes, "ความต้องการที่คำนวณได้สูงกว่าจำนวนที่ขอ จึงใช้จำนวนที่ขอ")
+	}
+
+	assessment.NeedScore = NeedScore(assessment.PerCapitaIncome, params)
+
+	for _, value := range []*float64{
+		&assessment.DeclaredIncome, &assessment.CertifiedIncome, &assessment.HouseholdIncome,
+		&assessment.TotalDebt, &assessment.DebtBurden, &assessment.AssetValue, &assessment.AssetAdjustment,
+		&assessment.AdjustedIncome, &assessment.PerCapitaIncome, &assessment.StudentMonthlyCosts,
+		&assessment.AnnualCosts, &assessment.AnnualFamilyContribution, &assessment.RequestedAmount, &assessment.UnmetNeed,
+	} {
+		*value = roundMoney(*value)
+	}
+	return assessment
+}
+
+// NeedScore maps per-capita income to a 0-100 need score: FullNeedPerCapita
+// or less scores 100, NoNeedPerCapita or more scores MinNeedScore
+func NeedScore(perCapita float64, params models.NeedParameters) float64 {
+	if perCapita <= params.FullNeedPerCapita {
+		return 100
+	}
+	if perCapita >= params.NoNeedPerCapita || params.NoNeedPerCapita <= params.FullNeedPerCapita {
+		return params.MinNeedScore
+	}
+	ratio := (perCapita - params.FullNeedPerCapita) / (params.NoNeedPerCapita - params.FullNeedPerCapita)
+	return math.Round((100-ratio*(100-params.MinNeedScore))*100) / 100
+}
+
+// ValidateNeedParameters reports the first parameter that would make the
+// formula meaningless
+func ValidateNeedParameters(params models.NeedParameters) error {
+	switch {
+	case params.IncomeBasis != models.IncomeBasisDeclared && params.IncomeBasis != models.IncomeBasisCertified &&
+		params.IncomeBasis != models.IncomeBasisMax:
+		return fmt.Errorf("income_basis must be declared, certified or max")
+	case params.WorkingSiblingShare < 0 || params.WorkingSiblingShare > 1:
+		return fmt.Errorf("working_sibling_share must be between 0 and 1")
+	case params.ContributionRate < 0 || params.ContributionRate > 1:
+		return fmt.Errorf("contribution_rate must be between 0 and 1")
+	case params.DebtRepaymentMonths < 0 || params.StudyDaysPerMonth < 0 || params.AcademicMonths < 0:
+		return fmt.Errorf("month and day counts cannot be negative")
+	case params.AssetExemption < 0 || params.AssetMonthlyRate < 0 || params.MaxAssetAdjustment < 0 || params.SubsistencePerCapita < 0:
+		return fmt.Errorf("amounts and rates cannot be negative")
+	case params.NoNeedPerCapita <= params.FullNeedPerCapita:
+		return fmt.Errorf("no_need_per_capita must be greater than full_need_per_capita")
+	case params.MinNeedScore < 0 || params.MinNeedScore > 100:
+		return fmt.Errorf("min_need_score must be between 0 and 100")
+	}
+	return nil
+}
+
+func personLabel(relationship, firstName, lastName string) string {
+	return strings.TrimSpace(fmt.Sprintf("%s %s %s", relationship, firstName, lastName))
+}
+
+func roundMoney(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"scholarship-system/internal/models"
+)
+
+func validFloat(v float64) sql.NullFloat64 { return sql.NullFloat64{Float64: v, Valid: true} }
+
+func TestAssessNeed(t *testing.T) {
+	params := models.DefaultNeedParameters()
+	form := &models.CompleteApplicationForm{
+		Application: &models.ScholarshipApplication{ApplicationID: 3},
+		FamilyMembers: []models.ApplicationFamilyMember{
+			{Relationship: "father", MonthlyIncome: validFloat(18000), LivingStatus: sql.NullString{String: "alive", Valid: true}},
+			{Relationship: "mother", MonthlyIncome: validFloat(12000), LivingStatus: sql.NullString{String: "Deceased", Valid: true}},
+		},
+		Siblings: []models.ApplicationSibling{
+			{SiblingOrder: 1, IsWorking: true, MonthlyIncome: validFloat(10000)},
+			{SiblingOrder: 3},
+		},
+		Assets: []models.ApplicationAsset{
+			{AssetType: "own_house", Value: validFloat(1500000)},
+			{AssetType: "land", Value: validFloat(1500000)},
+			{AssetType: "car_loan", Category: sql.NullString{String: "liability", Valid: true}, Value: validFloat(120000), MonthlyCost: validFloat(1000)},
+		},
+		FinancialInfo: &models.ApplicationFinancialInfo{
+			HasIncome: true, MonthlyIncome: validFloat(2000),
+			DailyTravelCost: validFloat(50), MonthlyDormCost: validFloat(3000),
+		},
+		FundingNeeds: &models.ApplicationFundingNeeds{TuitionSupport: validFloat(20000), TotalRequested: validFloat(30000)},
+	}
+
+	a := AssessNeed(form, params)
+
+	assert.Equal(t, uint(3), a.ApplicationID)
+	assert.Equal(t, 4, a.HouseholdSize)                  // applicant, father, two siblings
+	assert.Equal(t, 25000.0, a.HouseholdIncome)          // 18000 + 10000*0.5 + 2000
+	assert.Equal(t, 120000.0, a.TotalDebt)               // liability value
+	assert.Equal(t, 3000.0, a.DebtBurden)                // 1000 + 120000/60
+	assert.Equal(t, 1500000.0, a.AssetValue)             // own house is exempt
+	assert.Equal(t, 2000.0, a.AssetAdjustment)           // (1500000-500000)*0.002
+	assert.Equal(t, 24000.0, a.AdjustedIncome)           // 25000 + 2000 - 3000
+	assert.Equal(t, 6000.0, a.PerCapitaIncome)           // 24000 / 4
+	assert.Equal(t, 4100.0, a.StudentMonthlyCosts)       // 3000 + 50*22
+	assert.Equal(t, 61000.0, a.AnnualCosts)              // 4100*10 + 20000
+	assert.Equal(t, 15000.0, a.AnnualFamilyContribution) // (6000-3000)*0.5*10
+	assert.Equal(t, 30000.0, a.UnmetNeed)                // 46000 capped at the request
+	assert.Equal(t, 80.0, a.NeedScore)                   // 100 - (3000/12000)*80
+	assert.Len(t, a.IncomeLines, 3)
+	assert.NotEmpty(t, a.Notes)
+}
+
+func TestAssessNeedIncomeBasis(t *testing.T) {
+	form := &models.CompleteApplicationForm{
+		FamilyMembers:      []models.ApplicationFamilyMember{{Relationship: "mother", MonthlyIncome: validFloat(8000)}},
+		IncomeCertificates: []models.ApplicationIncomeCertificate{{OwnerName: "mother", MonthlyIncome: validFloat(12000)}},
+	}
+
+	params := models.DefaultNeedParameters()
+	params.IncomeBasis = models.IncomeBasisDeclared
+	assert.Equal(t, 8000.0, AssessNeed(form, params).HouseholdIncome)
+
+	params.IncomeBasis = models.IncomeBasisCertified
+	assert.Equal(t, 12000.0, AssessNeed(form, params).HouseholdIncome)
+
+	params.IncomeBasis = models.IncomeBasisMax
+	a := AssessNeed(form, params)
+	assert.Equal(t, 12000.0, a.HouseholdIncome)
+	assert.Len(t, a.Notes, 1) // certificates differ from the declared income by more than 20%
+}
+
+func TestNeedScore(t *testing.T) {
+	params := models.DefaultNeedParameters()
+	assert.Equal(t, 100.0, NeedScore(0, params))
+	assert.Equal(t, 100.0, NeedScore(3000, params))
+	assert.Equal(t, 60.0, NeedScore(9000, params))
+	assert.Equal(t, 20.0, NeedScore(15000, params))
+	assert.Equal(t, 20.0, NeedScore(50000, params))
+}
+
+func TestValidateNeedParameters(t *testing.T) {
+	assert.NoError(t, ValidateNeedParameters(models.DefaultNeedParameters()))
+
+	params := models.DefaultNeedParameters()
+	params.IncomeBasis = "guess"
+	assert.Error(t, ValidateNeedParameters(params))
+
+	params = models.DefaultNeedParameters()
+	params.NoNeedPerCapita = params.FullNeedPerCapita
+	assert.Error(t, ValidateNeedParameters(params))
+}
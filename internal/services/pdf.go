@@ -0,0 +1,614 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"fmt"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// Page layout of generated PDFs: A4 in points
+const (
+	pdfPageWidth  = 595.28
+	pdfPageHeight = 841.89
+	pdfMargin     = 50.0
+)
+
+// PDF values as read from or written to a file. Dictionaries and arrays hold
+// these, plus nil, bool, int and float64 for values built here.
+type (
+	pdfName  string
+	pdfRaw   string // a number as it appeared in a file
+	pdfHex   []byte // a string, written in hex
+	pdfDict  map[string]interface{}
+	pdfArray []interface{}
+	pdfRef   struct{ num int }
+)
+
+// pdfStream is a stream object; data is kept encoded
+type pdfStream struct {
+	dict pdfDict
+	data []byte
+}
+
+// PDFDocument builds a PDF from text, images and the pages of other PDFs.
+// It covers what document bundles need: no outlines, forms or compression
+// of anything but generated content.
+type PDFDocument struct {
+	objects []interface{} // by object number - 1
+	pagesID int
+	pages   []int
+
+	font     *PDFFont // nil uses Courier, which has no Thai
+	fontID   int
+	fontUsed map[uint16]rune
+
+	content *bytes.Buffer // the text page being laid out
+	y       float64
+}
+
+// PDFTextLine is a paragraph of text. Lines longer than the page wrap.
+type PDFTextLine struct {
+	Text   string
+	Size   float64 // points; 0 means 10
+	Indent float64 // points from the left margin
+}
+
+// NewPDFDocument starts an empty document. Text is set in font, or in Courier
+// when font is nil.
+func NewPDFDocument(font *PDFFont) *PDFDocument {
+	d := &PDFDocument{font: font, fontUsed: map[uint16]rune{}}
+	d.pagesID = d.reserve()
+	return d
+}
+
+// PageCount is the number of pages so far
+func (d *PDFDocument) PageCount() int {
+	n := len(d.pages)
+	if d.content != nil {
+		n++
+	}
+	return n
+}
+
+func (d *PDFDocument) reserve() int {
+	d.objects = append(d.objects, nil)
+	return len(d.objects)
+}
+
+func (d *PDFDocument) add(v interface{}) int {
+	id := d.reserve()
+	d.objects[id-1] = v
+	return id
+}
+
+func (d *PDFDocument) addPage(content []byte, resources pdfDict) {
+	stream := d.add(flateStream(pdfDict{}, content))
+	d.pages = append(d.pages, d.add(pdfDict{
+		"Type":      pdfName("Page"),
+		"Parent":    pdfRef{d.pagesID},
+		"MediaBox":  pdfArray{0, 0, pdfPageWidth, pdfPageHeight},
+		"Resources": resources,
+		"Contents":  pdfRef{stream},
+	}))
+}
+
+// AddText lays out lines after the text added so far, starting new pages as
+// needed
+func (d *PDFDocument) AddText(lines []PDFTextLine) {
+	for _, line := range lines {
+		size := line.Size
+		if size == 0 {
+			size = 10
+		}
+		leading := size * 1.4
+		width := pdfPageWidth - 2*pdfMargin - line.Indent
+		for _, text := range d.wrap(line.Text, size, width) {
+			if d.content == nil || d.y-leading < pdfMargin {
+				d.EndPage()
+				d.content = &bytes.Buffer{}
+				d.y = pdfPageHeight - pdfMargin
+			}
+			if text != "" {
+				fmt.Fprintf(d.content, "BT /F1 %s Tf %s %s Td %s Tj ET\n",
+					pdfNum(size), pdfNum(pdfMargin+line.Indent), pdfNum(d.y-size), d.encodeText(text))
+			}
+			d.y -= leading
+		}
+	}
+}
+
+// EndPage finishes the current text page, so the next text starts on a new
+// one
+func (d *PDFDocument) EndPage() {
+	if d.content == nil {
+		return
+	}
+	d.addPage(d.content.Bytes(), pdfDict{"Font": pdfDict{"F1": pdfRef{d.fontRef()}}})
+	d.content = nil
+}
+
+// AddImage adds a page with a JPEG or PNG image scaled to fit below a
+// caption
+func (d *PDFDocument) AddImage(data []byte, caption string) error {
+	img, width, height, err := pdfImage(data)
+	if err != nil {
+		return err
+	}
+	d.EndPage()
+
+	var content bytes.Buffer
+	resources := pdfDict{"XObject": pdfDict{"Im1": pdfRef{d.add(img)}}}
+	top := pdfPageHeight - pdfMargin
+	if caption != "" {
+		fmt.Fprintf(&content, "BT /F1 10 Tf %s %s Td %s Tj ET\n",
+			pdfNum(pdfMargin), pdfNum(top-10), d.encodeText(caption))
+		resources["Font"] = pdfDict{"F1": pdfRef{d.fontRef()}}
+		top -= 24
+	}
+
+	boxWidth, boxHeight := pdfPageWidth-2*pdfMargin, top-pdfMargin
+	scale := min(boxWidth/width, boxHeight/height)
+	w, h := width*scale, height*scale
+	fmt.Fprintf(&content, "q %s 0 0 %s %s %s cm /Im1 Do Q\n",
+		pdfNum(w), pdfNum(h), pdfNum(pdfMargin+(boxWidth-w)/2), pdfNum(top-h))
+	d.addPage(content.Bytes(), resources)
+	return nil
+}
+
+// AddPDF appends the pages of a PDF and returns how many there were. Links
+// and other annotations pointing elsewhere in the source are dropped.
+func (d *PDFDocument) AddPDF(data []byte) (int, error) {
+	r, err := newPDFReader(data)
+	if err != nil {
+		return 0, err
+	}
+	pages, err := r.pages()
+	if err != nil {
+		return 0, err
+	}
+	d.EndPage()
+
+	c := &pdfCopier{r: r, d: d, ids: map[int]int{}}
+	for _, page := range pages {
+		dict := pdfDict{}
+		for key, value := range page {
+			switch key {
+			case "Parent", "B", "Annots":
+				continue
+			}
+			dict[key] = c.copy(value)
+		}
+		if annots := c.copyAnnots(page["Annots"]); len(annots) > 0 {
+			dict["Annots"] = annots
+		}
+		dict["Parent"] = pdfRef{d.pagesID}
+		d.pages = append(d.pages, d.add(dict))
+	}
+	return len(pages), nil
+}
+
+// Write finishes the document and writes it out. The document cannot be
+// changed afterwards.
+func (d *PDFDocument) Write(w io.Writer) error {
+	d.EndPage()
+	d.finishFont()
+
+	kids := make(pdfArray, len(d.pages))
+	for i, id := range d.pages {
+		kids[i] = pdfRef{id}
+	}
+	d.objects[d.pagesID-1] = pdfDict{"Type": pdfName("Pages"), "Kids": kids, "Count": len(d.pages)}
+	catalog := d.add(pdfDict{"Type": pdfName("Catalog"), "Pages": pdfRef{d.pagesID}})
+
+	bw := bufio.NewWriter(w)
+	out := &countingWriter{w: bw}
+	out.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int64, len(d.objects))
+	for i, obj := range d.objects {
+		offsets[i] = out.n
+		fmt.Fprintf(out, "%d 0 obj\n", i+1)
+		var buf bytes.Buffer
+		if stream, ok := obj.(*pdfStream); ok {
+			dict := pdfDict{}
+			for key, value := range stream.dict {
+				dict[key] = value
+			}
+			dict["Length"] = len(stream.data)
+			writePDFValue(&buf, dict)
+			buf.WriteString("\nstream\n")
+			buf.Write(stream.data)
+			buf.WriteString("\nendstream")
+		} else {
+			writePDFValue(&buf, obj)
+		}
+		out.Write(buf.Bytes())
+		out.WriteString("\nendobj\n")
+	}
+
+	xref := out.n
+	fmt.Fprintf(out, "xref\n0 %d\n0000000000 65535 f \n", len(d.objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(out, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(d.objects)+1, catalog, xref)
+	if out.err != nil {
+		return out.err
+	}
+	return bw.Flush()
+}
+
+// wrap breaks text into lines no wider than width. Runs without spaces, as
+// in Thai, are broken between characters.
+func (d *PDFDocument) wrap(text string, size, width float64) []string {
+	text = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\r' || r == '\n' {
+			return ' '
+		}
+		return r
+	}, text)
+	if text == "" || d.textWidth(text, size) <= width {
+		return []string{text}
+	}
+
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if d.textWidth(candidate, size) <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+			line = ""
+		}
+		for d.textWidth(word, size) > width {
+			cut := d.fit(word, size, width)
+			lines = append(lines, word[:cut])
+			word = word[cut:]
+		}
+		line = word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fit returns how many bytes of s fit in width, at least one character and
+// never splitting a combining mark from its base
+func (d *PDFDocument) fit(s string, size, width float64) int {
+	cut := 0
+	for i, r := range s {
+		if i > 0 && !unicode.Is(unicode.Mn, r) {
+			if d.textWidth(s[:i], size) > width {
+				break
+			}
+			cut = i
+		}
+	}
+	if cut == 0 {
+		for i, r := range s {
+			if i > 0 && !unicode.Is(unicode.Mn, r) {
+				return i
+			}
+		}
+		return len(s)
+	}
+	return cut
+}
+
+func (d *PDFDocument) textWidth(s string, size float64) float64 {
+	if d.font == nil {
+		return float64(len([]rune(s))) * 0.6 * size
+	}
+	total := 0.0
+	for _, r := range s {
+		total += d.font.advance(d.font.glyph(r))
+	}
+	return total * size / 1000
+}
+
+// encodeText returns s as a PDF string in the document font
+func (d *PDFDocument) encodeText(s string) string {
+	if d.font == nil {
+		b := make([]byte, 0, len(s))
+		for _, r := range s {
+			if r < 0x20 || r > 0xFF || (r >= 0x7F && r < 0xA0) {
+				r = '?'
+			}
+			b = append(b, byte(r))
+		}
+		return "<" + hex.EncodeToString(b) + ">"
+	}
+	b := make([]byte, 0, 2*len(s))
+	for _, r := range s {
+		g := d.font.glyph(r)
+		if _, ok := d.fontUsed[g]; !ok {
+			d.fontUsed[g] = r
+		}
+		b = append(b, byte(g>>8), byte(g))
+	}
+	return "<" + hex.EncodeToString(b) + ">"
+}
+
+func (d *PDFDocument) fontRef() int {
+	if d.fontID == 0 {
+		d.fontID = d.reserve()
+	}
+	return d.fontID
+}
+
+// finishFont writes the font objects once all text is known
+func (d *PDFDocument) finishFont() {
+	if d.fontID == 0 {
+		return
+	}
+	if d.font == nil {
+		d.objects[d.fontID-1] = pdfDict{
+			"Type":     pdfName("Font"),
+			"Subtype":  pdfName("Type1"),
+			"BaseFont": pdfName("Courier"),
+			"Encoding": pdfName("WinAnsiEncoding"),
+		}
+		return
+	}
+	d.objects[d.fontID-1] = d.font.objects(d, d.fontUsed)
+}
+
+// pdfImage turns a JPEG or PNG into an image XObject
+func pdfImage(data []byte) (*pdfStream, float64, float64, error) {
+	if bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}) {
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("failed to read JPEG: %w", err)
+		}
+		colorSpace := "DeviceRGB"
+		switch cfg.ColorModel {
+		case color.GrayModel:
+			colorSpace = "DeviceGray"
+		case color.CMYKModel:
+			colorSpace = "DeviceCMYK"
+		}
+		return &pdfStream{
+			dict: pdfDict{
+				"Type":             pdfName("XObject"),
+				"Subtype":          pdfName("Image"),
+				"Width":            cfg.Width,
+				"Height":           cfg.Height,
+				"ColorSpace":       pdfName(colorSpace),
+				"BitsPerComponent": 8,
+				"Filter":           pdfName("DCTDecode"),
+			},
+			data: data,
+		}, float64(cfg.Width), float64(cfg.Height), nil
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("unsupported image: %w", err)
+	}
+	bounds := img.Bounds()
+	rgb := make([]byte, 0, 3*bounds.Dx()*bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			// Premultiplied, so adding the missing alpha puts it on white
+			r, g, b, a := img.At(x, y).RGBA()
+			rgb = append(rgb, byte((r+0xffff-a)>>8), byte((g+0xffff-a)>>8), byte((b+0xffff-a)>>8))
+		}
+	}
+	return flateStream(pdfDict{
+		"Type":             pdfName("XObject"),
+		"Subtype":          pdfName("Image"),
+		"Width":            bounds.Dx(),
+		"Height":           bounds.Dy(),
+		"ColorSpace":       pdfName("DeviceRGB"),
+		"BitsPerComponent": 8,
+	}, rgb), float64(bounds.Dx()), float64(bounds.Dy()), nil
+}
+
+func flateStream(dict pdfDict, data []byte) *pdfStream {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	dict["Filter"] = pdfName("FlateDecode")
+	return &pdfStream{dict: dict, data: buf.Bytes()}
+}
+
+// pdfCopier copies objects of a source PDF into a document, each once
+type pdfCopier struct {
+	r   *pdfReader
+	d   *PDFDocument
+	ids map[int]int // source object number to new one
+}
+
+func (c *pdfCopier) copy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case pdfRef:
+		if id, ok := c.ids[v.num]; ok {
+			return pdfRef{id}
+		}
+		obj := c.r.object(v.num)
+		if dict, ok := obj.(pdfDict); ok && dict["Type"] == pdfName("Page") {
+			// Other pages are only reachable through links; leave them out
+			return nil
+		}
+		id := c.d.reserve()
+		c.ids[v.num] = id
+		c.d.objects[id-1] = c.copy(obj)
+		return pdfRef{id}
+	case pdfDict:
+		dict := make(pdfDict, len(v))
+		for key, value := range v {
+			dict[key] = c.copy(value)
+		}
+		return dict
+	case pdfArray:
+		array := make(pdfArray, len(v))
+		for i, value := range v {
+			array[i] = c.copy(value)
+		}
+		return array
+	case *pdfStream:
+		dict := pdfDict{}
+		for key, value := range v.dict {
+			if key != "Length" {
+				dict[key] = c.copy(value)
+			}
+		}
+		return &pdfStream{dict: dict, data: v.data}
+	}
+	return v
+}
+
+// copyAnnots keeps the annotations of a page that draw something, such as
+// filled form fields, without their links to the rest of the source
+func (c *pdfCopier) copyAnnots(v interface{}) pdfArray {
+	annots, _ := c.r.resolve(v).(pdfArray)
+	var copied pdfArray
+	for _, item := range annots {
+		annot, ok := c.r.resolve(item).(pdfDict)
+		if !ok || annot["AP"] == nil {
+			continue
+		}
+		dict := pdfDict{}
+		for key, value := range annot {
+			switch key {
+			case "P", "Parent", "Dest", "A", "AA", "Popup", "IRT", "Kids":
+				continue
+			}
+			dict[key] = c.copy(value)
+		}
+		copied = append(copied, pdfRef{c.d.add(dict)})
+	}
+	return copied
+}
+
+// writePDFValue writes a value in PDF syntax
+func writePDFValue(b *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		b.WriteString("null")
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case int:
+		b.WriteString(strconv.Itoa(v))
+	case float64:
+		b.WriteString(pdfNum(v))
+	case pdfRaw:
+		b.WriteString(string(v))
+	case pdfName:
+		b.WriteByte('/')
+		for i := 0; i < len(v); i++ {
+			c := v[i]
+			if c <= ' ' || c >= 0x7F || c == '#' || isPDFDelimiter(c) {
+				fmt.Fprintf(b, "#%02X", c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	case pdfHex:
+		b.WriteByte('<')
+		b.WriteString(hex.EncodeToString(v))
+		b.WriteByte('>')
+	case string:
+		writePDFValue(b, pdfTextString(v))
+	case pdfRef:
+		fmt.Fprintf(b, "%d 0 R", v.num)
+	case pdfArray:
+		b.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			writePDFValue(b, item)
+		}
+		b.WriteByte(']')
+	case pdfDict:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		b.WriteString("<<")
+		for _, key := range keys {
+			b.WriteByte(' ')
+			writePDFValue(b, pdfName(key))
+			b.WriteByte(' ')
+			writePDFValue(b, v[key])
+		}
+		b.WriteString(" >>")
+	default:
+		// Streams are only valid as objects of their own
+		b.WriteString("null")
+	}
+}
+
+// pdfTextString encodes text for metadata such as a title, as UTF-16 when
+// it is not plain ASCII
+func pdfTextString(s string) pdfHex {
+	ascii := true
+	for _, r := range s {
+		if r >= 0x80 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return pdfHex(s)
+	}
+	b := []byte{0xFE, 0xFF}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u>>8), byte(u))
+	}
+	return b
+}
+
+func pdfNum(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+// countingWriter remembers the first error and how much was written
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	w.err = err
+	return n, err
+}
+
+func (w *countingWriter) WriteString(s string) {
+	w.Write([]byte(s))
+}
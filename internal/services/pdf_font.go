@@ -0,0 +1,324 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf16"
+)
+
+// PDFFont is a TrueType font embedded in generated PDFs, so text in any
+// script it covers, Thai in particular, can be shown. The whole font file is
+// embedded; fonts made for screens are small enough.
+type PDFFont struct {
+	name       string
+	data       []byte
+	unitsPerEm int
+	ascent     int
+	descent    int
+	bbox       [4]int
+	advances   []int
+	cmap       map[rune]uint16
+}
+
+// LoadPDFFont reads a TrueType (.ttf) font. Fonts with PostScript outlines
+// (.otf) and collections (.ttc) are not supported.
+func LoadPDFFont(data []byte) (*PDFFont, error) {
+	if len(data) < 12 {
+		return nil, errors.New("font file is too short")
+	}
+	switch string(data[:4]) {
+	case "\x00\x01\x00\x00", "true":
+	case "OTTO":
+		return nil, errors.New("fonts with PostScript outlines are not supported; use a TrueType font")
+	case "ttcf":
+		return nil, errors.New("font collections are not supported")
+	default:
+		return nil, errors.New("not a TrueType font")
+	}
+
+	tables := map[string][]byte{}
+	for i := 0; i < ttU16(data, 4); i++ {
+		record := 12 + 16*i
+		offset, length := ttU32(data, record+8), ttU32(data, record+12)
+		if record+16 > len(data) || offset+length > len(data) {
+			return nil, errors.New("font table directory is damaged")
+		}
+		tables[string(data[record:record+4])] = data[offset : offset+length]
+	}
+	for _, tag := range []string{"head", "hhea", "hmtx", "maxp", "cmap"} {
+		if tables[tag] == nil {
+			return nil, fmt.Errorf("font has no %s table", tag)
+		}
+	}
+
+	head, hhea := tables["head"], tables["hhea"]
+	f := &PDFFont{
+		data:       data,
+		unitsPerEm: ttU16(head, 18),
+		ascent:     ttI16(hhea, 4),
+		descent:    ttI16(hhea, 6),
+		bbox:       [4]int{ttI16(head, 36), ttI16(head, 38), ttI16(head, 40), ttI16(head, 42)},
+		name:       ttPostScriptName(tables["name"]),
+	}
+	if f.unitsPerEm == 0 {
+		return nil, errors.New("font has no units per em")
+	}
+
+	metrics := ttU16(hhea, 34)
+	hmtx := tables["hmtx"]
+	for g := 0; g < metrics && 4*g+2 <= len(hmtx); g++ {
+		f.advances = append(f.advances, ttU16(hmtx, 4*g))
+	}
+	if len(f.advances) == 0 {
+		return nil, errors.New("font has no glyph widths")
+	}
+
+	var err error
+	if f.cmap, err = ttCmap(tables["cmap"]); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+var pdfFonts sync.Map // path to *PDFFont
+
+// LoadPDFFontFile loads the font at path once and keeps it for later
+// documents. An empty path gives nil, which sets text in Courier.
+func LoadPDFFontFile(path string) (*PDFFont, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if font, ok := pdfFonts.Load(path); ok {
+		return font.(*PDFFont), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF font: %w", err)
+	}
+	font, err := LoadPDFFont(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load PDF font %s: %w", path, err)
+	}
+	pdfFonts.Store(path, font)
+	return font, nil
+}
+
+// glyph returns the glyph of a character, or 0 (.notdef) when the font lacks it
+func (f *PDFFont) glyph(r rune) uint16 {
+	return f.cmap[r]
+}
+
+// advance is the width of a glyph in thousandths of the font size
+func (f *PDFFont) advance(g uint16) float64 {
+	i := min(int(g), len(f.advances)-1)
+	return float64(f.advances[i]) * 1000 / float64(f.unitsPerEm)
+}
+
+func (f *PDFFont) scale(v int) int {
+	return v * 1000 / f.unitsPerEm
+}
+
+// objects adds the font to a document and returns its font dictionary. Text
+// is encoded as glyph IDs (Identity-H); used maps them back to characters so
+// the text can be searched and copied.
+func (f *PDFFont) objects(d *PDFDocument, used map[uint16]rune) pdfDict {
+	glyphs := make([]int, 0, len(used))
+	for g := range used {
+		glyphs = append(glyphs, int(g))
+	}
+	sort.Ints(glyphs)
+
+	widths := pdfArray{}
+	for _, g := range glyphs {
+		widths = append(widths, g, pdfArray{int(f.advance(uint16(g)))})
+	}
+
+	file := flateStream(pdfDict{"Length1": len(f.data)}, f.data)
+	descriptor := d.add(pdfDict{
+		"Type":        pdfName("FontDescriptor"),
+		"FontName":    pdfName(f.name),
+		"Flags":       32,
+		"FontBBox":    pdfArray{f.scale(f.bbox[0]), f.scale(f.bbox[1]), f.scale(f.bbox[2]), f.scale(f.bbox[3])},
+		"ItalicAngle": 0,
+		"Ascent":      f.scale(f.ascent),
+		"Descent":     f.scale(f.descent),
+		"CapHeight":   f.scale(f.ascent),
+		"StemV":       80,
+		"FontFile2":   pdfRef{d.add(file)},
+	})
+	cidFont := d.add(pdfDict{
+		"Type":     pdfName("Font"),
+		"Subtype":  pdfName("CIDFontType2"),
+		"BaseFont": pdfName(f.name),
+		"CIDSystemInfo": pdfDict{
+			"Registry":   "Adobe",
+			"Ordering":   "Identity",
+			"Supplement": 0,
+		},
+		"FontDescriptor": pdfRef{descriptor},
+		"W":              widths,
+		"CIDToGIDMap":    pdfName("Identity"),
+	})
+
+	return pdfDict{
+		"Type":            pdfName("Font"),
+		"Subtype":         pdfName("Type0"),
+		"BaseFont":        pdfName(f.name),
+		"Encoding":        pdfName("Identity-H"),
+		"DescendantFonts": pdfArray{pdfRef{cidFont}},
+		"ToUnicode":       pdfRef{d.add(flateStream(pdfDict{}, toUnicodeCMap(glyphs, used)))},
+	}
+}
+
+// toUnicodeCMap maps glyph IDs back to the characters they were used for
+func toUnicodeCMap(glyphs []int, used map[uint16]rune) []byte {
+	var b bytes.Buffer
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	var entries []string
+	for _, g := range glyphs {
+		if g == 0 {
+			continue
+		}
+		var u strings.Builder
+		for _, unit := range utf16.Encode([]rune{used[uint16(g)]}) {
+			fmt.Fprintf(&u, "%04X", unit)
+		}
+		entries = append(entries, fmt.Sprintf("<%04X> <%s>", g, u.String()))
+	}
+	for len(entries) > 0 {
+		n := min(len(entries), 100)
+		fmt.Fprintf(&b, "%d beginbfchar\n%s\nendbfchar\n", n, strings.Join(entries[:n], "\n"))
+		entries = entries[n:]
+	}
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return b.Bytes()
+}
+
+// ttCmap reads the Unicode character map of a font
+func ttCmap(cmap []byte) (map[rune]uint16, error) {
+	best, bestScore := -1, 0
+	for i := 0; i < ttU16(cmap, 2); i++ {
+		record := 4 + 8*i
+		platform, encoding, offset := ttU16(cmap, record), ttU16(cmap, record+2), ttU32(cmap, record+4)
+		format := ttU16(cmap, offset)
+		score := 0
+		switch {
+		case format == 12 && (platform == 0 || (platform == 3 && encoding == 10)):
+			score = 3
+		case format == 4 && (platform == 0 || (platform == 3 && encoding == 1)):
+			score = 2
+		}
+		if score > bestScore {
+			best, bestScore = offset, score
+		}
+	}
+	if best < 0 || best >= len(cmap) {
+		return nil, errors.New("font has no Unicode character map")
+	}
+
+	sub := cmap[best:]
+	glyphs := map[rune]uint16{}
+	if ttU16(sub, 0) == 12 {
+		for i := 0; i < ttU32(sub, 12); i++ {
+			group := 16 + 12*i
+			if group+12 > len(sub) {
+				break
+			}
+			start, end, glyph := ttU32(sub, group), ttU32(sub, group+4), ttU32(sub, group+8)
+			if end > unicodeMax || end < start {
+				continue
+			}
+			for c := start; c <= end; c++ {
+				glyphs[rune(c)] = uint16(glyph + c - start)
+			}
+		}
+		return glyphs, nil
+	}
+
+	segments := ttU16(sub, 6) / 2
+	ends, starts, deltas, rangeOffsets := 14, 16+2*segments, 16+4*segments, 16+6*segments
+	for i := 0; i < segments; i++ {
+		end, start := ttU16(sub, ends+2*i), ttU16(sub, starts+2*i)
+		delta, rangeOffset := ttU16(sub, deltas+2*i), ttU16(sub, rangeOffsets+2*i)
+		for c := start; c <= end && c < 0xFFFF; c++ {
+			g := (c + delta) & 0xFFFF
+			if rangeOffset != 0 {
+				g = ttU16(sub, rangeOffsets+2*i+rangeOffset+2*(c-start))
+				if g != 0 {
+					g = (g + delta) & 0xFFFF
+				}
+			}
+			if g != 0 {
+				glyphs[rune(c)] = uint16(g)
+			}
+		}
+	}
+	return glyphs, nil
+}
+
+const unicodeMax = 0x10FFFF
+
+// ttPostScriptName returns the PostScript name of a font, usable as a PDF
+// name
+func ttPostScriptName(table []byte) string {
+	stringsAt := ttU16(table, 4)
+	for i := 0; i < ttU16(table, 2); i++ {
+		record := 6 + 12*i
+		platform, nameID := ttU16(table, record), ttU16(table, record+6)
+		length, offset := ttU16(table, record+8), ttU16(table, record+10)
+		start := stringsAt + offset
+		if nameID != 6 || start+length > len(table) {
+			continue
+		}
+		raw := table[start : start+length]
+		var name string
+		if platform == 1 {
+			name = string(raw)
+		} else {
+			units := make([]uint16, len(raw)/2)
+			for j := range units {
+				units[j] = binary.BigEndian.Uint16(raw[2*j:])
+			}
+			name = string(utf16.Decode(units))
+		}
+		name = strings.Map(func(r rune) rune {
+			if r < 0x21 || r > 0x7E || isPDFDelimiter(byte(r)) {
+				return -1
+			}
+			return r
+		}, name)
+		if name != "" {
+			return name
+		}
+	}
+	return "EmbeddedFont"
+}
+
+// ttU16 reads a big-endian uint16, or 0 past the end of b
+func ttU16(b []byte, i int) int {
+	if i < 0 || i+2 > len(b) {
+		return 0
+	}
+	return int(binary.BigEndian.Uint16(b[i:]))
+}
+
+func ttI16(b []byte, i int) int {
+	return int(int16(ttU16(b, i)))
+}
+
+// ttU32 reads a big-endian uint32, or 0 past the end of b
+func ttU32(b []byte, i int) int {
+	if i < 0 || i+4 > len(b) {
+		return 0
+	}
+	return int(binary.BigEndian.Uint32(b[i:]))
+}
@@ -0,0 +1,840 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// maxPDFStreamSize caps decompressed cross-reference and object streams
+const maxPDFStreamSize = 64 << 20
+
+// pdfReader reads the objects of an existing PDF so its pages can be copied.
+// Damaged cross-reference tables are rebuilt by scanning the file; encrypted
+// files are refused.
+type pdfReader struct {
+	data     []byte
+	offsets  map[int]int // object number to byte offset
+	inStream map[int]int // object number to the object stream holding it
+	cache    map[int]interface{}
+	streams  map[int]map[int][]byte // decoded object streams, by object
+	trailer  pdfDict
+	depth    int
+}
+
+func newPDFReader(data []byte) (*pdfReader, error) {
+	// Readers accept junk before the header, as some scanners write it
+	if !bytes.Contains(data[:min(len(data), 1024)], []byte("%PDF-")) {
+		return nil, errors.New("not a PDF file")
+	}
+	r := &pdfReader{data: data}
+	r.reset()
+	if err := r.loadXref(); err != nil || !r.hasRoot() {
+		r.reset()
+		r.rebuildXref()
+		if !r.hasRoot() {
+			return nil, errors.New("PDF file is damaged: no document catalog found")
+		}
+	}
+	if r.trailer["Encrypt"] != nil {
+		return nil, errors.New("encrypted PDF files are not supported")
+	}
+	return r, nil
+}
+
+func (r *pdfReader) reset() {
+	r.offsets = map[int]int{}
+	r.inStream = map[int]int{}
+	r.cache = map[int]interface{}{}
+	r.streams = map[int]map[int][]byte{}
+	r.trailer = nil
+}
+
+func (r *pdfReader) hasRoot() bool {
+	if r.trailer == nil {
+		return false
+	}
+	_, ok := r.resolve(r.trailer["Root"]).(pdfDict)
+	return ok
+}
+
+// object returns an object by number, or nil when it is missing or broken
+func (r *pdfReader) object(num int) interface{} {
+	if v, ok := r.cache[num]; ok {
+		return v
+	}
+	if r.depth > 32 {
+		return nil
+	}
+	r.depth++
+	defer func() { r.depth-- }()
+
+	// Guards against objects whose stream length refers to themselves
+	r.cache[num] = nil
+	var v interface{}
+	if offset, ok := r.offsets[num]; ok {
+		v, _ = r.objectAt(offset)
+	} else if stream, ok := r.inStream[num]; ok {
+		v = r.streamObject(stream, num)
+	}
+	r.cache[num] = v
+	return v
+}
+
+// resolve follows references to the value they point at
+func (r *pdfReader) resolve(v interface{}) interface{} {
+	for i := 0; i < 32; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = r.object(ref.num)
+	}
+	return nil
+}
+
+// pages returns the page dictionaries in order, with inherited attributes
+// filled in
+func (r *pdfReader) pages() ([]pdfDict, error) {
+	root, _ := r.resolve(r.trailer["Root"]).(pdfDict)
+	var pages []pdfDict
+	visited := map[int]bool{}
+
+	var walk func(node interface{}, inherited pdfDict, depth int)
+	walk = func(node interface{}, inherited pdfDict, depth int) {
+		if ref, ok := node.(pdfRef); ok {
+			if visited[ref.num] {
+				return
+			}
+			visited[ref.num] = true
+		}
+		dict, ok := r.resolve(node).(pdfDict)
+		if !ok || depth > 64 {
+			return
+		}
+
+		inherit := pdfDict{}
+		for key, value := range inherited {
+			inherit[key] = value
+		}
+		for _, key := range []string{"Resources", "MediaBox", "CropBox", "Rotate"} {
+			if dict[key] != nil {
+				inherit[key] = dict[key]
+			}
+		}
+
+		kids, hasKids := r.resolve(dict["Kids"]).(pdfArray)
+		if dict["Type"] == pdfName("Page") || (!hasKids && dict["Type"] != pdfName("Pages")) {
+			page := pdfDict{}
+			for key, value := range dict {
+				page[key] = value
+			}
+			for key, value := range inherit {
+				if page[key] == nil {
+					page[key] = value
+				}
+			}
+			if page["MediaBox"] == nil {
+				page["MediaBox"] = pdfArray{0, 0, 612, 792}
+			}
+			if page["Resources"] == nil {
+				page["Resources"] = pdfDict{}
+			}
+			pages = append(pages, page)
+			return
+		}
+		for _, kid := range kids {
+			walk(kid, inherit, depth+1)
+		}
+	}
+	walk(root["Pages"], pdfDict{}, 0)
+
+	if len(pages) == 0 {
+		return nil, errors.New("PDF file has no pages")
+	}
+	return pages, nil
+}
+
+// loadXref reads the cross-reference sections from the end of the file back
+func (r *pdfReader) loadXref() error {
+	at := bytes.LastIndex(r.data, []byte("startxref"))
+	if at < 0 {
+		return errors.New("no startxref")
+	}
+	l := &pdfLexer{data: r.data, pos: at + len("startxref")}
+	offset, err := strconv.Atoi(l.word())
+	if err != nil {
+		return errors.New("bad startxref")
+	}
+
+	visited := map[int]bool{}
+	for offset > 0 {
+		if visited[offset] || offset >= len(r.data) {
+			return errors.New("bad cross-reference chain")
+		}
+		visited[offset] = true
+
+		trailer, err := r.xrefSection(offset)
+		if err != nil {
+			return err
+		}
+		if r.trailer == nil {
+			r.trailer = trailer
+		}
+		// Hybrid files keep newer entries in a stream beside the table
+		if stm, ok := pdfInt(trailer["XRefStm"]); ok && !visited[stm] {
+			visited[stm] = true
+			if _, err := r.xrefSection(stm); err != nil {
+				return err
+			}
+		}
+		offset, _ = pdfInt(trailer["Prev"])
+	}
+	return nil
+}
+
+// xrefSection reads a cross-reference table or stream and returns its
+// trailer
+func (r *pdfReader) xrefSection(offset int) (pdfDict, error) {
+	l := &pdfLexer{data: r.data, pos: offset}
+	if l.word() != "xref" {
+		v, err := r.objectAt(offset)
+		if err != nil {
+			return nil, err
+		}
+		stream, ok := v.(*pdfStream)
+		if !ok || stream.dict["Type"] != pdfName("XRef") {
+			return nil, errors.New("no cross-reference at startxref")
+		}
+		return stream.dict, r.xrefStream(stream)
+	}
+
+	for {
+		word := l.word()
+		if word == "trailer" {
+			break
+		}
+		start, err1 := strconv.Atoi(word)
+		count, err2 := strconv.Atoi(l.word())
+		if err1 != nil || err2 != nil {
+			return nil, errors.New("bad cross-reference table")
+		}
+		for i := 0; i < count; i++ {
+			entry, _, kind := l.word(), l.word(), l.word()
+			switch kind {
+			case "n":
+				offset, err := strconv.Atoi(entry)
+				if err != nil {
+					return nil, errors.New("bad cross-reference entry")
+				}
+				r.record(start+i, offset, false)
+			case "f":
+			default:
+				return nil, errors.New("bad cross-reference entry")
+			}
+		}
+	}
+	v, err := l.value()
+	if err != nil {
+		return nil, err
+	}
+	trailer, ok := v.(pdfDict)
+	if !ok {
+		return nil, errors.New("bad trailer")
+	}
+	return trailer, nil
+}
+
+// record notes where an object is, unless a newer section already did
+func (r *pdfReader) record(num, at int, compressed bool) {
+	if _, ok := r.offsets[num]; ok {
+		return
+	}
+	if _, ok := r.inStream[num]; ok {
+		return
+	}
+	if compressed {
+		r.inStream[num] = at
+	} else {
+		r.offsets[num] = at
+	}
+}
+
+func (r *pdfReader) xrefStream(stream *pdfStream) error {
+	data, err := r.decodeStream(stream)
+	if err != nil {
+		return err
+	}
+	widths, _ := r.resolve(stream.dict["W"]).(pdfArray)
+	if len(widths) != 3 {
+		return errors.New("bad cross-reference stream")
+	}
+	var w [3]int
+	for i := range w {
+		w[i], _ = pdfInt(widths[i])
+		if w[i] < 0 || w[i] > 8 {
+			return errors.New("bad cross-reference stream")
+		}
+	}
+	index, _ := r.resolve(stream.dict["Index"]).(pdfArray)
+	if index == nil {
+		size, _ := pdfInt(stream.dict["Size"])
+		index = pdfArray{0, size}
+	}
+
+	field := func(b []byte) int {
+		n := 0
+		for _, c := range b {
+			n = n<<8 | int(c)
+		}
+		return n
+	}
+	rowLen := w[0] + w[1] + w[2]
+	pos := 0
+	for i := 0; i+1 < len(index); i += 2 {
+		start, _ := pdfInt(index[i])
+		count, _ := pdfInt(index[i+1])
+		for j := 0; j < count && pos+rowLen <= len(data); j++ {
+			row := data[pos : pos+rowLen]
+			pos += rowLen
+			kind := 1
+			if w[0] > 0 {
+				kind = field(row[:w[0]])
+			}
+			second := field(row[w[0] : w[0]+w[1]])
+			switch kind {
+			case 1:
+				r.record(start+j, second, false)
+			case 2:
+				r.record(start+j, second, true)
+			}
+		}
+	}
+	return nil
+}
+
+var pdfObjectHeader = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b`)
+
+// rebuildXref finds objects by scanning the file, for when the
+// cross-reference data is missing or wrong. Later copies of an object win,
+// as incremental updates append them.
+func (r *pdfReader) rebuildXref() {
+	for _, m := range pdfObjectHeader.FindAllSubmatchIndex(r.data, -1) {
+		if m[0] > 0 && !isPDFSpace(r.data[m[0]-1]) && !isPDFDelimiter(r.data[m[0]-1]) {
+			continue
+		}
+		if num, err := strconv.Atoi(string(r.data[m[2]:m[3]])); err == nil {
+			r.offsets[num] = m[0]
+		}
+	}
+
+	var catalog int
+	for num := range r.offsets {
+		switch v := r.object(num).(type) {
+		case pdfDict:
+			if v["Type"] == pdfName("Catalog") {
+				catalog = num
+			}
+		case *pdfStream:
+			if v.dict["Type"] == pdfName("ObjStm") {
+				for inner := range r.objectStream(num) {
+					if _, ok := r.offsets[inner]; !ok {
+						r.inStream[inner] = num
+					}
+				}
+			}
+		}
+	}
+	// Objects read before all object streams were known may be incomplete
+	r.cache = map[int]interface{}{}
+
+	if at := bytes.LastIndex(r.data, []byte("trailer")); at >= 0 {
+		l := &pdfLexer{data: r.data, pos: at + len("trailer")}
+		if v, err := l.value(); err == nil {
+			r.trailer, _ = v.(pdfDict)
+		}
+	}
+	if !r.hasRoot() && catalog > 0 {
+		r.trailer = pdfDict{"Root": pdfRef{catalog}}
+	}
+}
+
+// objectAt parses the object whose header starts at offset
+func (r *pdfReader) objectAt(offset int) (interface{}, error) {
+	l := &pdfLexer{data: r.data, pos: offset}
+	l.word()
+	l.word()
+	if l.word() != "obj" {
+		return nil, fmt.Errorf("no object at offset %d", offset)
+	}
+	v, err := l.value()
+	if err != nil {
+		return nil, err
+	}
+	dict, ok := v.(pdfDict)
+	if !ok {
+		return v, nil
+	}
+
+	l.skipSpace()
+	if !bytes.HasPrefix(r.data[l.pos:], []byte("stream")) {
+		return dict, nil
+	}
+	start := l.pos + len("stream")
+	if bytes.HasPrefix(r.data[start:], []byte("\r\n")) {
+		start += 2
+	} else if start < len(r.data) && (r.data[start] == '\n' || r.data[start] == '\r') {
+		start++
+	}
+
+	if length, ok := pdfInt(r.resolve(dict["Length"])); ok && length >= 0 && start+length <= len(r.data) {
+		rest := bytes.TrimLeft(r.data[start+length:], "\x00\t\n\f\r ")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			return &pdfStream{dict: dict, data: r.data[start : start+length]}, nil
+		}
+	}
+	end := bytes.Index(r.data[start:], []byte("endstream"))
+	if end < 0 {
+		return nil, fmt.Errorf("unterminated stream at offset %d", offset)
+	}
+	data := r.data[start : start+end]
+	if bytes.HasSuffix(data, []byte("\r\n")) {
+		data = data[:len(data)-2]
+	} else if bytes.HasSuffix(data, []byte("\n")) || bytes.HasSuffix(data, []byte("\r")) {
+		data = data[:len(data)-1]
+	}
+	return &pdfStream{dict: dict, data: data}, nil
+}
+
+// objectStream decodes an object stream into the source of each object in it
+func (r *pdfReader) objectStream(num int) map[int][]byte {
+	if objects, ok := r.streams[num]; ok {
+		return objects
+	}
+	objects := map[int][]byte{}
+	r.streams[num] = objects
+
+	stream, ok := r.object(num).(*pdfStream)
+	if !ok {
+		return objects
+	}
+	data, err := r.decodeStream(stream)
+	if err != nil {
+		return objects
+	}
+	count, _ := pdfInt(r.resolve(stream.dict["N"]))
+	first, _ := pdfInt(r.resolve(stream.dict["First"]))
+	if first < 0 || first > len(data) {
+		return objects
+	}
+
+	l := &pdfLexer{data: data[:first]}
+	nums := make([]int, 0, count)
+	offsets := make([]int, 0, count)
+	for i := 0; i < count; i++ {
+		n, err1 := strconv.Atoi(l.word())
+		at, err2 := strconv.Atoi(l.word())
+		if err1 != nil || err2 != nil || at < 0 || first+at > len(data) {
+			break
+		}
+		nums = append(nums, n)
+		offsets = append(offsets, first+at)
+	}
+	for i, n := range nums {
+		end := len(data)
+		if i+1 < len(offsets) && offsets[i+1] >= offsets[i] {
+			end = offsets[i+1]
+		}
+		objects[n] = data[offsets[i]:end]
+	}
+	return objects
+}
+
+func (r *pdfReader) streamObject(stream, num int) interface{} {
+	source, ok := r.objectStream(stream)[num]
+	if !ok {
+		return nil
+	}
+	v, err := (&pdfLexer{data: source}).value()
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// decodeStream undoes the filters of a stream. Only Flate, the filter of
+// cross-reference and object streams, is supported.
+func (r *pdfReader) decodeStream(stream *pdfStream) ([]byte, error) {
+	filters := r.resolve(stream.dict["Filter"])
+	params := r.resolve(stream.dict["DecodeParms"])
+	if name, ok := filters.(pdfName); ok {
+		filters, params = pdfArray{name}, pdfArray{params}
+	}
+	list, _ := filters.(pdfArray)
+	paramList, _ := params.(pdfArray)
+
+	data := stream.data
+	for i, filter := range list {
+		if r.resolve(filter) != pdfName("FlateDecode") {
+			return nil, fmt.Errorf("unsupported stream filter %v", filter)
+		}
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		decoded, err := io.ReadAll(io.LimitReader(zr, maxPDFStreamSize+1))
+		if len(decoded) > maxPDFStreamSize {
+			return nil, errors.New("stream is too large")
+		}
+		if err != nil && len(decoded) == 0 {
+			return nil, err
+		}
+		data = decoded
+		if i < len(paramList) {
+			if parms, ok := r.resolve(paramList[i]).(pdfDict); ok {
+				if data, err = r.unpredict(data, parms); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return data, nil
+}
+
+// unpredict reverses the PNG predictors used by cross-reference streams
+func (r *pdfReader) unpredict(data []byte, parms pdfDict) ([]byte, error) {
+	predictor, _ := pdfInt(r.resolve(parms["Predictor"]))
+	if predictor <= 1 {
+		return data, nil
+	}
+	if predictor < 10 {
+		return nil, errors.New("unsupported TIFF predictor")
+	}
+	columns, colors, bits := 1, 1, 8
+	if v, ok := pdfInt(r.resolve(parms["Columns"])); ok && v > 0 {
+		columns = v
+	}
+	if v, ok := pdfInt(r.resolve(parms["Colors"])); ok && v > 0 {
+		colors = v
+	}
+	if v, ok := pdfInt(r.resolve(parms["BitsPerComponent"])); ok && v > 0 {
+		bits = v
+	}
+	bpp := max(colors*bits/8, 1)
+	rowLen := (columns*colors*bits + 7) / 8
+
+	out := make([]byte, 0, len(data))
+	prev := make([]byte, rowLen)
+	for pos := 0; pos+1+rowLen <= len(data); pos += 1 + rowLen {
+		kind, row := data[pos], append([]byte(nil), data[pos+1:pos+1+rowLen]...)
+		for i := range row {
+			var left, upLeft byte
+			if i >= bpp {
+				left, upLeft = row[i-bpp], prev[i-bpp]
+			}
+			up := prev[i]
+			switch kind {
+			case 0:
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			default:
+				return nil, fmt.Errorf("bad PNG predictor %d", kind)
+			}
+		}
+		out = append(out, row...)
+		prev = row
+	}
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	}
+	return c
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// pdfInt reads an integer written by us or read from a file
+func pdfInt(v interface{}) (int, bool) {
+	switch v := v.(type) {
+	case int:
+		return v, true
+	case float64:
+		return int(v), true
+	case pdfRaw:
+		if n, err := strconv.Atoi(string(v)); err == nil {
+			return n, true
+		}
+		if f, err := strconv.ParseFloat(string(v), 64); err == nil {
+			return int(f), true
+		}
+	}
+	return 0, false
+}
+
+func isPDFSpace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+// pdfLexer parses PDF values from source text
+type pdfLexer struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+// skipSpace skips white space and comments
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		if !isPDFSpace(c) {
+			return
+		}
+		l.pos++
+	}
+}
+
+// word reads a run of regular characters, such as a number or keyword
+func (l *pdfLexer) word() string {
+	l.skipSpace()
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+func (l *pdfLexer) value() (interface{}, error) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if l.depth > 64 {
+		return nil, errors.New("PDF objects are nested too deeply")
+	}
+
+	switch c := l.data[l.pos]; {
+	case c == '/':
+		return l.name(), nil
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		return l.dict()
+	case c == '<':
+		return l.hexString()
+	case c == '[':
+		return l.array()
+	case c == '(':
+		return l.literalString()
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return l.number(), nil
+	}
+
+	switch word := l.word(); word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	case "":
+		return nil, fmt.Errorf("unexpected %q at offset %d", l.data[l.pos], l.pos)
+	default:
+		return nil, fmt.Errorf("unexpected %q at offset %d", word, l.pos)
+	}
+}
+
+func (l *pdfLexer) name() pdfName {
+	l.pos++
+	var b []byte
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isPDFSpace(c) || isPDFDelimiter(c) {
+			break
+		}
+		if c == '#' && l.pos+2 < len(l.data) {
+			if n, err := strconv.ParseUint(string(l.data[l.pos+1:l.pos+3]), 16, 8); err == nil {
+				b = append(b, byte(n))
+				l.pos += 3
+				continue
+			}
+		}
+		b = append(b, c)
+		l.pos++
+	}
+	return pdfName(b)
+}
+
+// number reads a number, or a reference when followed by a generation and R
+func (l *pdfLexer) number() interface{} {
+	word := l.word()
+	num, err := strconv.Atoi(word)
+	if err != nil || num < 0 {
+		return pdfRaw(word)
+	}
+	save := l.pos
+	if _, err := strconv.Atoi(l.word()); err == nil {
+		l.skipSpace()
+		if l.pos < len(l.data) && l.data[l.pos] == 'R' &&
+			(l.pos+1 == len(l.data) || isPDFSpace(l.data[l.pos+1]) || isPDFDelimiter(l.data[l.pos+1])) {
+			l.pos++
+			return pdfRef{num}
+		}
+	}
+	l.pos = save
+	return pdfRaw(word)
+}
+
+func (l *pdfLexer) dict() (interface{}, error) {
+	l.pos += 2
+	l.depth++
+	defer func() { l.depth-- }()
+	dict := pdfDict{}
+	for {
+		l.skipSpace()
+		if bytes.HasPrefix(l.data[l.pos:], []byte(">>")) {
+			l.pos += 2
+			return dict, nil
+		}
+		if l.pos >= len(l.data) || l.data[l.pos] != '/' {
+			return nil, fmt.Errorf("bad dictionary key at offset %d", l.pos)
+		}
+		key := l.name()
+		value, err := l.value()
+		if err != nil {
+			return nil, err
+		}
+		dict[string(key)] = value
+	}
+}
+
+func (l *pdfLexer) array() (interface{}, error) {
+	l.pos++
+	l.depth++
+	defer func() { l.depth-- }()
+	array := pdfArray{}
+	for {
+		l.skipSpace()
+		if l.pos < len(l.data) && l.data[l.pos] == ']' {
+			l.pos++
+			return array, nil
+		}
+		value, err := l.value()
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, value)
+	}
+}
+
+func (l *pdfLexer) hexString() (interface{}, error) {
+	l.pos++
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; !isPDFSpace(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	if l.pos >= len(l.data) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	l.pos++
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	b := make([]byte, len(digits)/2)
+	for i := range b {
+		n, err := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("bad hex string at offset %d", l.pos)
+		}
+		b[i] = byte(n)
+	}
+	return pdfHex(b), nil
+}
+
+func (l *pdfLexer) literalString() (interface{}, error) {
+	l.pos++
+	var b []byte
+	nesting := 0
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			nesting++
+		case ')':
+			if nesting == 0 {
+				return pdfHex(b), nil
+			}
+			nesting--
+		case '\\':
+			if l.pos >= len(l.data) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			c = l.data[l.pos]
+			l.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					n := int(c - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						n = n*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(n)
+				}
+			}
+		}
+		b = append(b, c)
+	}
+	return nil, io.ErrUnexpectedEOF
+}
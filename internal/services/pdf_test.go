@@ -0,0 +1,263 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePDF(t *testing.T, d *PDFDocument) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, d.Write(&buf))
+	return buf.Bytes()
+}
+
+func testPNG(t *testing.T) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	img.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestPDFDocumentRoundTrip(t *testing.T) {
+	d := NewPDFDocument(nil)
+	lines := []PDFTextLine{{Text: "Application form", Size: 16}}
+	for i := 0; i < 80; i++ {
+		lines = append(lines, PDFTextLine{Text: fmt.Sprintf("Line %d (with parentheses) and a long tail %s", i, strings.Repeat("x", 120)), Indent: 12})
+	}
+	d.AddText(lines)
+	require.NoError(t, d.AddImage(testPNG(t), "house_photos"))
+	assert.Error(t, d.AddImage([]byte("not an image"), ""))
+	pages := d.PageCount()
+	assert.Greater(t, pages, 2)
+
+	data := writePDF(t, d)
+	r, err := newPDFReader(data)
+	require.NoError(t, err)
+	read, err := r.pages()
+	require.NoError(t, err)
+	assert.Len(t, read, pages)
+
+	merged := NewPDFDocument(nil)
+	merged.AddText([]PDFTextLine{{Text: "Cover"}})
+	n, err := merged.AddPDF(data)
+	require.NoError(t, err)
+	assert.Equal(t, pages, n)
+	assert.Equal(t, pages+1, merged.PageCount())
+
+	r, err = newPDFReader(writePDF(t, merged))
+	require.NoError(t, err)
+	read, err = r.pages()
+	require.NoError(t, err)
+	assert.Len(t, read, pages+1)
+}
+
+func TestPDFReaderRebuildsDamagedXref(t *testing.T) {
+	d := NewPDFDocument(nil)
+	d.AddText([]PDFTextLine{{Text: "one"}})
+	d.EndPage()
+	d.AddText([]PDFTextLine{{Text: "two"}})
+	data := writePDF(t, d)
+
+	at := bytes.LastIndex(data, []byte("startxref\n"))
+	damaged := append(append([]byte{}, data[:at]...), []byte("startxref\n9\n%%EOF\n")...)
+	r, err := newPDFReader(damaged)
+	require.NoError(t, err)
+	pages, err := r.pages()
+	require.NoError(t, err)
+	assert.Len(t, pages, 2)
+
+	_, err = newPDFReader([]byte("%PDF-1.4\nnothing here\n"))
+	assert.EqualError(t, err, "PDF file is damaged: no document catalog found")
+	_, err = newPDFReader([]byte("GIF89a"))
+	assert.EqualError(t, err, "not a PDF file")
+}
+
+func TestPDFReaderEncryptedFile(t *testing.T) {
+	data := writePDF(t, NewPDFDocument(nil))
+	data = bytes.Replace(data, []byte("<< /Size"), []byte("<< /Encrypt << /Filter /Standard >> /Size"), 1)
+	_, err := newPDFReader(data)
+	assert.EqualError(t, err, "encrypted PDF files are not supported")
+}
+
+// TestPDFReaderXrefStream reads a file in the compact PDF 1.5 layout: pages
+// inside an object stream, found through a predicted cross-reference stream
+func TestPDFReaderXrefStream(t *testing.T) {
+	deflate := func(b []byte) []byte {
+		var buf bytes.Buffer
+		zw := zlib.NewWriter(&buf)
+		zw.Write(b)
+		zw.Close()
+		return buf.Bytes()
+	}
+
+	var file bytes.Buffer
+	file.WriteString("%PDF-1.5\n")
+	offsets := map[int]int{}
+
+	offsets[1] = file.Len()
+	file.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+
+	inner := []string{
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 200 100] /Resources << >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 5 0 R >>",
+	}
+	header := fmt.Sprintf("2 0 3 %d ", len(inner[0])+1)
+	body := deflate([]byte(header + inner[0] + " " + inner[1]))
+	offsets[4] = file.Len()
+	fmt.Fprintf(&file, "4 0 obj\n<< /Type /ObjStm /N 2 /First %d /Filter /FlateDecode /Length %d >>\nstream\n", len(header), len(body))
+	file.Write(body)
+	file.WriteString("\nendstream\nendobj\n")
+
+	content := []byte("0 0 m 10 10 l S")
+	offsets[5] = file.Len()
+	fmt.Fprintf(&file, "5 0 obj\n<< /Length 6 0 R >>\nstream\n%s\nendstream\nendobj\n", content)
+	offsets[6] = file.Len()
+	fmt.Fprintf(&file, "6 0 obj\n%d\nendobj\n", len(content))
+
+	// Rows of type, offset or stream, index; each behind the PNG Up filter
+	rows := [][3]int{{0, 0, 0}, {1, offsets[1], 0}, {2, 4, 0}, {2, 4, 1}, {1, offsets[4], 0}, {1, offsets[5], 0}, {1, offsets[6], 0}, {1, 0, 0}}
+	offsets[7] = file.Len()
+	rows[7][1] = offsets[7]
+	var table []byte
+	prev := make([]byte, 4)
+	for _, row := range rows {
+		raw := []byte{byte(row[0]), 0, 0, byte(row[2])}
+		binary.BigEndian.PutUint16(raw[1:3], uint16(row[1]))
+		table = append(table, 2)
+		for i := range raw {
+			table = append(table, raw[i]-prev[i])
+		}
+		prev = raw
+	}
+	table = deflate(table)
+	fmt.Fprintf(&file, "7 0 obj\n<< /Type /XRef /Size 8 /W [1 2 1] /Root 1 0 R /Filter /FlateDecode "+
+		"/DecodeParms << /Predictor 12 /Columns 4 >> /Length %d >>\nstream\n", len(table))
+	file.Write(table)
+	fmt.Fprintf(&file, "\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", offsets[7])
+
+	r, err := newPDFReader(file.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{3: 4, 2: 4}, r.inStream)
+	pages, err := r.pages()
+	require.NoError(t, err)
+	require.Len(t, pages, 1)
+	assert.Equal(t, pdfArray{pdfRaw("0"), pdfRaw("0"), pdfRaw("200"), pdfRaw("100")}, pages[0]["MediaBox"])
+
+	contents, ok := r.resolve(pages[0]["Contents"]).(*pdfStream)
+	require.True(t, ok)
+	assert.Equal(t, content, contents.data)
+
+	d := NewPDFDocument(nil)
+	n, err := d.AddPDF(file.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Contains(t, string(writePDF(t, d)), "0 0 m 10 10 l S")
+}
+
+func TestPDFLexer(t *testing.T) {
+	l := &pdfLexer{data: []byte(`<< /A#20B (a\(b\)\n\101 (nested)) /C <414> /D [1 0 R -2.5 true null] % comment
+		/E /Name >>`)}
+	v, err := l.value()
+	require.NoError(t, err)
+	assert.Equal(t, pdfDict{
+		"A B": pdfHex("a(b)\nA (nested)"),
+		"C":   pdfHex("A@"),
+		"D":   pdfArray{pdfRef{1}, pdfRaw("-2.5"), true, nil},
+		"E":   pdfName("Name"),
+	}, v)
+
+	var b bytes.Buffer
+	writePDFValue(&b, pdfDict{"A B": pdfName("x/y"), "T": "ทุน"})
+	assert.Equal(t, "<< /A#20B /x#2Fy /T <feff0e170e380e19> >>", b.String())
+}
+
+// testFont builds a TrueType font with glyphs for "A" and Thai "ก"
+func testFont() []byte {
+	u16 := func(v ...int) []byte {
+		b := make([]byte, 2*len(v))
+		for i, n := range v {
+			binary.BigEndian.PutUint16(b[2*i:], uint16(n))
+		}
+		return b
+	}
+	head := make([]byte, 54)
+	copy(head[18:], u16(1000))
+	copy(head[36:], u16(0, 0xFFFF-199, 1000, 800))
+	hhea := make([]byte, 36)
+	copy(hhea[4:], u16(800, 0xFFFF-199))
+	copy(hhea[34:], u16(3))
+	hmtx := u16(500, 0, 600, 0, 700, 0)
+	maxp := u16(0, 0x5000, 3)
+
+	// Format 4 with segments for A, ก and the closing 0xFFFF
+	subtable := append(u16(4, 0, 0, 6, 0, 0, 0), u16(0x41, 0x0E01, 0xFFFF, 0)...)
+	subtable = append(subtable, u16(0x41, 0x0E01, 0xFFFF)...)
+	subtable = append(subtable, u16((1-0x41)&0xFFFF, (2-0x0E01)&0xFFFF, 1)...)
+	subtable = append(subtable, u16(0, 0, 0)...)
+	cmap := append(u16(0, 1, 3, 1, 0, 12), subtable...)
+
+	name := append(u16(0, 1, 18, 3, 1, 0x409, 6, 8, 0), u16('T', 'e', 's', 't')...)
+
+	tables := []struct {
+		tag  string
+		data []byte
+	}{{"cmap", cmap}, {"head", head}, {"hhea", hhea}, {"hmtx", hmtx}, {"maxp", maxp}, {"name", name}}
+	font := append([]byte{0, 1, 0, 0}, u16(len(tables), 0, 0, 0)...)
+	offset := len(font) + 16*len(tables)
+	var body []byte
+	for _, table := range tables {
+		record := make([]byte, 16)
+		copy(record, table.tag)
+		binary.BigEndian.PutUint32(record[8:], uint32(offset+len(body)))
+		binary.BigEndian.PutUint32(record[12:], uint32(len(table.data)))
+		font = append(font, record...)
+		body = append(body, table.data...)
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+	}
+	return append(font, body...)
+}
+
+func TestLoadPDFFont(t *testing.T) {
+	font, err := LoadPDFFont(testFont())
+	require.NoError(t, err)
+	assert.Equal(t, "Test", font.name)
+	assert.Equal(t, uint16(1), font.glyph('A'))
+	assert.Equal(t, uint16(2), font.glyph('ก'))
+	assert.Equal(t, uint16(0), font.glyph('z'))
+	assert.Equal(t, 700.0, font.advance(2))
+	assert.Equal(t, 700.0, font.advance(9))
+
+	d := NewPDFDocument(font)
+	d.AddText([]PDFTextLine{{Text: "Aก"}})
+	data := writePDF(t, d)
+
+	r, err := newPDFReader(data)
+	require.NoError(t, err)
+	pages, err := r.pages()
+	require.NoError(t, err)
+	resources := r.resolve(pages[0]["Resources"]).(pdfDict)
+	dict := r.resolve(resources["Font"].(pdfDict)["F1"]).(pdfDict)
+	assert.Equal(t, pdfName("Type0"), dict["Subtype"])
+	assert.Equal(t, pdfName("Identity-H"), dict["Encoding"])
+
+	toUnicode := r.resolve(dict["ToUnicode"]).(*pdfStream)
+	cmap, err := r.decodeStream(toUnicode)
+	require.NoError(t, err)
+	assert.Contains(t, string(cmap), "<0001> <0041>\n<0002> <0E01>")
+
+	_, err = LoadPDFFont([]byte("OTTO\x00\x00\x00\x00\x00\x00\x00\x00"))
+	assert.EqualError(t, err, "fonts with PostScript outlines are not supported; use a TrueType font")
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"scholarship-system/internal/models"
+)
+
+// PrefillResult is a draft form built from a previous application
+type PrefillResult struct {
+	Form               *models.CompleteApplicationForm
+	CopiedSections     []string
+	StaleFields        []models.StaleField
+	IncomeCertificates []models.ApplicationIncomeCertificate // recent enough to reuse
+	AwardsAdded        int
+}
+
+// BuildPrefill copies the reusable sections of a previous application into a
+// new form. Record IDs are cleared so the sections are inserted as new rows.
+// Incomes, house photos and income certificates older than staleMonths are
+// flagged, and so are the year level and GPA when the source belongs to
+// another academic year. Awards the student received are added to the
+// scholarship history unless the history already lists them.
+func BuildPrefill(source models.PrefillSource, academicYear string, awards []models.PriorAward, now time.Time, staleMonths int) *PrefillResult {
+	from := source.Form
+	form := &models.CompleteApplicationForm{}
+	result := &PrefillResult{Form: form, CopiedSections: []string{}, StaleFields: []models.StaleField{}}
+
+	staleBefore := now.AddDate(0, -staleMonths, 0)
+	recordedAt := source.RecordedAt
+	incomeStale := recordedAt.Before(staleBefore)
+	newYear := source.AcademicYear != "" && academicYear != "" && source.AcademicYear != academicYear
+	stale := func(section, field, reason string) {
+		result.StaleFields = append(result.StaleFields, models.StaleField{
+			Section:    section,
+			Field:      field,
+			Reason:     reason,
+			RecordedAt: &recordedAt,
+		})
+	}
+	yearReason := fmt.Sprintf("Copied from the %s application; update it for %s", source.AcademicYear, academicYear)
+	incomeReason := fmt.Sprintf("Income recorded on %s is more than %d months old", recordedAt.Format("2006-01-02"), staleMonths)
+
+	if from.PersonalInfo != nil {
+		info := *from.PersonalInfo
+		info.InfoID, info.ApplicationID = "", 0
+		form.PersonalInfo = &info
+		result.CopiedSections = append(result.CopiedSections, models.SectionPersonalInfo)
+		if newYear && info.YearLevel.Valid {
+			stale(models.SectionPersonalInfo, "year_level", yearReason)
+		}
+	}
+
+	if len(from.Addresses) > 0 {
+		form.Addresses = make([]models.ApplicationAddress, len(from.Addresses))
+		for i, address := range from.Addresses {
+			address.AddressID, address.ApplicationID = "", 0
+			form.Addresses[i] = address
+		}
+		result.CopiedSections = append(result.CopiedSections, models.SectionAddressInfo)
+	}
+
+	if len(from.EducationHistory) > 0 {
+		form.EducationHistory = make([]models.ApplicationEducationHistory, len(from.EducationHistory))
+		for i, education := range from.EducationHistory {
+			education.HistoryID, education.ApplicationID = "", 0
+			form.EducationHistory[i] = education
+			// Finished levels keep their GPA; the current one has moved on
+			if newYear && education.GPA.Valid && !education.GraduationYear.Valid {
+				stale(models.SectionEducationHistory, fmt.Sprintf("education_history[%d].gpa", i), yearReason)
+			}
+		}
+		result.CopiedSections = append(result.CopiedSections, models.SectionEducationHistory)
+	}
+
+	if len(from.FamilyMembers) > 0 || len(from.Guardians) > 0 || len(from.Siblings) > 0 || from.LivingSituation != nil {
+		form.FamilyMembers = make([]models.ApplicationFamilyMember, len(from.FamilyMembers))
+		for i, member := range from.FamilyMembers {
+			member.MemberID, member.ApplicationID = "", 0
+			form.FamilyMembers[i] = member
+			if incomeStale && member.MonthlyIncome.Valid {
+				stale(models.SectionFamilyInfo, fmt.Sprintf("family_members[%d].monthly_income", i), incomeReason)
+			}
+		}
+		form.Guardians = make([]models.ApplicationGuardian, len(from.Guardians))
+		for i, guardian := range from.Guardians {
+			guardian.GuardianID, guardian.ApplicationID = "", 0
+			form.Guardians[i] = guardian
+			if incomeStale && guardian.MonthlyIncome.Valid {
+				stale(models.SectionFamilyInfo, fmt.Sprintf("guardians[%d].monthly_income", i), incomeReason)
+			}
+		}
+		form.Siblings = make([]models.ApplicationSibling, len(from.Siblings))
+		for i, sibling := range from.Siblings {
+			sibling.SiblingID, sibling.ApplicationID = "", 0
+			form.Siblings[i] = sibling
+			if incomeStale && sibling.MonthlyIncome.Valid {
+				stale(models.SectionFamilyInfo, fmt.Sprintf("siblings[%d].monthly_income", i), incomeReason)
+			}
+		}
+		if from.LivingSituation != nil {
+			living := *from.LivingSituation
+			living.LivingID, living.ApplicationID = "", 0
+			form.LivingSituation = &living
+			if incomeStale && (living.FrontHouseImage.Valid || living.SideHouseImage.Valid || living.BackHouseImage.Valid) {
+				stale(models.SectionFamilyInfo, "living_situation.house_images",
+					fmt.Sprintf("House photos taken before %s are more than %d months old", recordedAt.Format("2006-01-02"), staleMonths))
+			}
+		}
+		result.CopiedSections = append(result.CopiedSections, models.SectionFamilyInfo)
+	}
+
+	if len(from.Activities) > 0 {
+		form.Activities = make([]models.ApplicationActivity, len(from.Activities))
+		for i, activity := range from.Activities {
+			activity.ActivityID, activity.ApplicationID = "", 0
+			form.Activities[i] = activity
+		}
+		result.CopiedSections = append(result.CopiedSections, models.SectionActivitiesSkills)
+	}
+
+	// Scholarship history: what the student listed before plus our own awards
+	history := []models.ApplicationScholarshipHistory{}
+	for _, entry := range from.ScholarshipHistory {
+		entry.HistoryID, entry.ApplicationID = "", 0
+		history = append(history, entry)
+	}
+	for _, award := range awards {
+		if !awardReceived(award.Status) || historyListsAward(history, award) {
+			continue
+		}
+		history = append(history, models.ApplicationScholarshipHistory{
+			ScholarshipName: sql.NullString{String: award.ScholarshipName, Valid: true},
+			ScholarshipType: sql.NullString{String: award.ScholarshipType, Valid: award.ScholarshipType != ""},
+			Amount:          sql.NullFloat64{Float64: award.Amount, Valid: true},
+			AcademicYear:    sql.NullString{String: award.AcademicYear, Valid: award.AcademicYear != ""},
+			Notes:           sql.NullString{String: fmt.Sprintf("Awarded through application #%d", award.ApplicationID), Valid: true},
+		})
+		result.AwardsAdded++
+	}
+	if len(history) > 0 {
+		form.ScholarshipHistory = history
+		result.CopiedSections = append(result.CopiedSections, models.SectionFinancialInfo)
+	}
+
+	// Income certificates are only reused while they are recent
+	for _, cert := range from.IncomeCertificates {
+		if cert.CreatedAt.Before(staleBefore) {
+			certifiedAt := cert.CreatedAt
+			result.StaleFields = append(result.StaleFields, models.StaleField{
+				Section:    models.SectionFinancialInfo,
+				Field:      "income_certificates",
+				Reason:     fmt.Sprintf("Income certificate for %s is more than %d months old; upload a new one", cert.OwnerName, staleMonths),
+				RecordedAt: &certifiedAt,
+			})
+			continue
+		}
+		cert.CertID, cert.ApplicationID = "", 0
+		result.IncomeCertificates = append(result.IncomeCertificates, cert)
+	}
+
+	return result
+}
+
+// ResolveStaleFields marks the stale fields of sections that were saved since
+// the prefill as resolved
+func ResolveStaleFields(prefill *models.ApplicationPrefill, sectionVersions map[string]int) {
+	for i := range prefill.StaleFields {
+		field := &prefill.StaleFields[i]
+		if sectionVersions[field.Section] > prefill.SectionVersions[field.Section] {
+			field.Resolved = true
+		}
+	}
+}
+
+// awardReceived reports whether an allocation was actually awarded
+func awardReceived(status string) bool {
+	switch status {
+	case "pending", "cancelled", "rejected", "declined":
+		return false
+	}
+	return true
+}
+
+func historyListsAward(history []models.ApplicationScholarshipHistory, award models.PriorAward) bool {
+	for _, entry := range history {
+		if strings.EqualFold(strings.TrimSpace(entry.ScholarshipName.String), strings.TrimSpace(award.ScholarshipName)) &&
+			(!entry.AcademicYear.Valid || entry.AcademicYear.String == award.AcademicYear) {
+			return true
+		}
+	}
+	return false
+}
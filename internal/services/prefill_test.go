@@ -0,0 +1,141 @@
+package services
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scholarship-system/internal/models"
+)
+
+func prefillSourceForm() *models.CompleteApplicationForm {
+	return &models.CompleteApplicationForm{
+		PersonalInfo: &models.ApplicationPersonalInfo{
+			InfoID: "info-1", ApplicationID: 4, FirstNameTH: "สมชาย",
+			YearLevel: sql.NullInt32{Int32: 2, Valid: true},
+		},
+		Addresses: []models.ApplicationAddress{{AddressID: "addr-1", ApplicationID: 4, AddressType: "home"}},
+		EducationHistory: []models.ApplicationEducationHistory{
+			{HistoryID: "edu-1", ApplicationID: 4, EducationLevel: "high_school", GPA: sql.NullFloat64{Float64: 3.8, Valid: true},
+				GraduationYear: sql.NullString{String: "2565", Valid: true}},
+			{HistoryID: "edu-2", ApplicationID: 4, EducationLevel: "bachelor", GPA: sql.NullFloat64{Float64: 3.2, Valid: true}},
+		},
+		FamilyMembers: []models.ApplicationFamilyMember{
+			{MemberID: "m-1", Relationship: "father", MonthlyIncome: sql.NullFloat64{Float64: 9000, Valid: true}},
+			{MemberID: "m-2", Relationship: "mother"},
+		},
+		Guardians: []models.ApplicationGuardian{{GuardianID: "g-1", MonthlyIncome: sql.NullFloat64{Float64: 5000, Valid: true}}},
+		Assets:    []models.ApplicationAsset{{AssetID: "a-1", AssetType: "own_house"}},
+		Activities: []models.ApplicationActivity{
+			{ActivityID: "act-1", ApplicationID: 4, ActivityType: "volunteer"},
+		},
+		ScholarshipHistory: []models.ApplicationScholarshipHistory{
+			{HistoryID: "h-1", ScholarshipName: sql.NullString{String: "ทุนเรียนดี", Valid: true},
+				AcademicYear: sql.NullString{String: "2566", Valid: true}},
+		},
+		IncomeCertificates: []models.ApplicationIncomeCertificate{
+			{CertID: "c-1", OwnerName: "บิดา", CreatedAt: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)},
+			{CertID: "c-2", OwnerName: "มารดา", CreatedAt: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+}
+
+func TestBuildPrefillCopiesReusableSections(t *testing.T) {
+	source := models.PrefillSource{
+		Form:         prefillSourceForm(),
+		AcademicYear: "2567",
+		RecordedAt:   time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+	}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	result := BuildPrefill(source, "2567", nil, now, 12)
+
+	form := result.Form
+	require.NotNil(t, form.PersonalInfo)
+	assert.Equal(t, "", form.PersonalInfo.InfoID)
+	assert.Equal(t, 0, form.PersonalInfo.ApplicationID)
+	assert.Equal(t, "สมชาย", form.PersonalInfo.FirstNameTH)
+	assert.Equal(t, "", form.Addresses[0].AddressID)
+	assert.Equal(t, "", form.Guardians[0].GuardianID)
+	assert.Equal(t, "", form.Activities[0].ActivityID)
+	assert.Nil(t, form.Assets, "financial details are not copied")
+	assert.Equal(t, []string{
+		models.SectionPersonalInfo, models.SectionAddressInfo, models.SectionEducationHistory,
+		models.SectionFamilyInfo, models.SectionActivitiesSkills, models.SectionFinancialInfo,
+	}, result.CopiedSections)
+
+	// The source itself is left alone
+	assert.Equal(t, "info-1", source.Form.PersonalInfo.InfoID)
+	assert.Equal(t, "g-1", source.Form.Guardians[0].GuardianID)
+
+	// Same academic year and recent incomes: only the old certificate is stale
+	require.Len(t, result.StaleFields, 1)
+	assert.Equal(t, "income_certificates", result.StaleFields[0].Field)
+	require.Len(t, result.IncomeCertificates, 1)
+	assert.Equal(t, "มารดา", result.IncomeCertificates[0].OwnerName)
+	assert.Equal(t, "", result.IncomeCertificates[0].CertID)
+}
+
+func TestBuildPrefillFlagsStaleFields(t *testing.T) {
+	source := models.PrefillSource{
+		Form:         prefillSourceForm(),
+		AcademicYear: "2566",
+		RecordedAt:   time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	result := BuildPrefill(source, "2567", nil, now, 12)
+
+	var fields []string
+	for _, field := range result.StaleFields {
+		fields = append(fields, field.Field)
+		assert.False(t, field.Resolved)
+	}
+	assert.Equal(t, []string{
+		"year_level",
+		"education_history[1].gpa",
+		"family_members[0].monthly_income",
+		"guardians[0].monthly_income",
+		"income_certificates",
+		"income_certificates",
+	}, fields)
+	assert.Equal(t, "Income recorded on 2024-06-01 is more than 12 months old", result.StaleFields[2].Reason)
+	assert.Empty(t, result.IncomeCertificates)
+}
+
+func TestBuildPrefillAddsPriorAwards(t *testing.T) {
+	source := models.PrefillSource{Form: prefillSourceForm(), RecordedAt: time.Now()}
+	awards := []models.PriorAward{
+		{AllocationID: 1, ApplicationID: 4, ScholarshipName: "ทุนเรียนดี", AcademicYear: "2566", Amount: 20000, Status: "disbursed"},
+		{AllocationID: 2, ApplicationID: 2, ScholarshipName: "ทุนขาดแคลนทุนทรัพย์", ScholarshipType: "need_based", AcademicYear: "2565", Amount: 15000, Status: "completed"},
+		{AllocationID: 3, ApplicationID: 5, ScholarshipName: "ทุนกิจกรรม", AcademicYear: "2566", Amount: 5000, Status: "declined"},
+	}
+
+	result := BuildPrefill(source, "2567", awards, time.Now(), 12)
+
+	assert.Equal(t, 1, result.AwardsAdded)
+	require.Len(t, result.Form.ScholarshipHistory, 2)
+	added := result.Form.ScholarshipHistory[1]
+	assert.Equal(t, "ทุนขาดแคลนทุนทรัพย์", added.ScholarshipName.String)
+	assert.Equal(t, 15000.0, added.Amount.Float64)
+	assert.Equal(t, "2565", added.AcademicYear.String)
+	assert.Equal(t, "", result.Form.ScholarshipHistory[0].HistoryID)
+}
+
+func TestResolveStaleFields(t *testing.T) {
+	prefill := &models.ApplicationPrefill{
+		StaleFields: []models.StaleField{
+			{Section: models.SectionFamilyInfo, Field: "guardians[0].monthly_income"},
+			{Section: models.SectionFinancialInfo, Field: "income_certificates"},
+		},
+		SectionVersions: map[string]int{models.SectionFamilyInfo: 1, models.SectionFinancialInfo: 1},
+	}
+
+	ResolveStaleFields(prefill, map[string]int{models.SectionFamilyInfo: 2, models.SectionFinancialInfo: 1})
+
+	assert.True(t, prefill.StaleFields[0].Resolved)
+	assert.False(t, prefill.StaleFields[1].Resolved)
+}
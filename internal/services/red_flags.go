@@ -0,0 +1,198 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"scholarship-system/internal/models"
+)
+
+// incomeAssetKey is the match key of the income/asset check, which is not
+// shared between applications
+const incomeAssetKey = "income_asset"
+
+// DetectRedFlags compares the values of every application and returns one
+// flag per application and shared value. A value only counts as shared when
+// it appears on applications of different students, so a student reusing
+// their own bank account or file across applications is not flagged.
+func DetectRedFlags(input models.FraudScanInput, params models.FraudScanParams) []models.RedFlag {
+	var flags []models.RedFlag
+
+	flags = append(flags, sharedValueFlags(models.SignalSharedBankAccount, "high", input.BankAccounts, true,
+		"Bank account %s is also used by %s")...)
+	flags = append(flags, sharedValueFlags(models.SignalSharedGuardianID, "medium", input.GuardianIDs, true,
+		"Guardian national ID %s also appears on %s")...)
+	flags = append(flags, sharedValueFlags(models.SignalSharedHouseRegistration, "low", input.HouseRegistrations, false,
+		"House registration document %s is identical to %s")...)
+	flags = append(flags, sharedValueFlags(models.SignalDuplicateFile, "high", input.Files, false,
+		"Uploaded file %s is identical to %s")...)
+
+	for _, record := range input.IncomeAssets {
+		annualIncome := record.MonthlyIncome * 12
+		if record.TotalAssetValue < params.MinAssetValue || record.TotalAssetValue <= annualIncome*params.AssetIncomeYears {
+			continue
+		}
+		reason := fmt.Sprintf("Declared assets of %.0f baht against a household income of %.0f baht per month", record.TotalAssetValue, record.MonthlyIncome)
+		details := map[string]interface{}{
+			"monthly_income":     record.MonthlyIncome,
+			"total_asset_value":  record.TotalAssetValue,
+			"asset_income_years": params.AssetIncomeYears,
+		}
+		if annualIncome > 0 {
+			details["years_of_income"] = roundMoney(record.TotalAssetValue / annualIncome)
+		}
+		flags = append(flags, models.RedFlag{
+			ApplicationID:         record.ApplicationID,
+			SignalType:            models.SignalIncomeAssetMismatch,
+			Severity:              "medium",
+			MatchKey:              incomeAssetKey,
+			RelatedApplicationIDs: []uint{},
+			Reason:                reason,
+			Details:               details,
+			Status:                models.RedFlagOpen,
+		})
+	}
+
+	sort.SliceStable(flags, func(i, j int) bool {
+		if flags[i].ApplicationID != flags[j].ApplicationID {
+			return flags[i].ApplicationID < flags[j].ApplicationID
+		}
+		return flags[i].SignalType < flags[j].SignalType
+	})
+	return flags
+}
+
+// sharedValueFlags groups records by value. Identifiers are reduced to their
+// digits, stored only as a hash and shown masked; file hashes are used as is.
+func sharedValueFlags(signalType, severity string, records []models.SignalRecord, identifier bool, reasonFormat string) []models.RedFlag {
+	groups := map[string][]models.SignalRecord{}
+	var keys []string
+	for _, record := range records {
+		key := record.Key
+		if identifier {
+			digits := digitsOnly(record.Key)
+			if len(digits) < 6 {
+				continue
+			}
+			key = hashValue(digits)
+			record.Label = MaskIdentifier(digits)
+		}
+		if key == "" {
+			continue
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], record)
+	}
+
+	var flags []models.RedFlag
+	for _, key := range keys {
+		group := groups[key]
+		students := map[string]bool{}
+		for _, record := range group {
+			students[record.StudentID] = true
+		}
+		if len(students) < 2 {
+			continue
+		}
+
+		seen := map[uint]bool{}
+		for _, record := range group {
+			if seen[record.ApplicationID] {
+				continue
+			}
+			seen[record.ApplicationID] = true
+
+			related := []uint{}
+			otherStudents := map[string]bool{}
+			for _, other := range group {
+				if other.StudentID == record.StudentID {
+					continue
+				}
+				otherStudents[other.StudentID] = true
+				if !containsID(related, other.ApplicationID) {
+					related = append(related, other.ApplicationID)
+				}
+			}
+			sort.Slice(related, func(i, j int) bool { return related[i] < related[j] })
+
+			flags = append(flags, models.RedFlag{
+				ApplicationID:         record.ApplicationID,
+				SignalType:            signalType,
+				Severity:              severity,
+				MatchKey:              key,
+				RelatedApplicationIDs: related,
+				Reason:                fmt.Sprintf(reasonFormat, record.Label, describeRelated(len(otherStudents), related)),
+				Details: map[string]interface{}{
+					"value":          record.Label,
+					"other_students": len(otherStudents),
+				},
+				Status: models.RedFlagOpen,
+			})
+		}
+	}
+	return flags
+}
+
+// MaskIdentifier hides all but the last four characters of an account or ID
+// number
+func MaskIdentifier(value string) string {
+	if len(value) <= 4 {
+		return value
+	}
+	return strings.Repeat("x", len(value)-4) + value[len(value)-4:]
+}
+
+// NewFraudScanResult counts the findings of a run by signal type
+func NewFraudScanResult(flags []models.RedFlag) *models.FraudScanResult {
+	result := &models.FraudScanResult{
+		Findings: len(flags),
+		BySignal: map[string]int{},
+		RanAt:    time.Now(),
+	}
+	for _, flag := range flags {
+		result.BySignal[flag.SignalType]++
+	}
+	return result
+}
+
+func describeRelated(students int, applications []uint) string {
+	ids := make([]string, len(applications))
+	for i, id := range applications {
+		ids[i] = fmt.Sprintf("%d", id)
+	}
+	noun := "students"
+	if students == 1 {
+		noun = "student"
+	}
+	return fmt.Sprintf("%d other %s (applications %s)", students, noun, strings.Join(ids, ", "))
+}
+
+func digitsOnly(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func hashValue(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func containsID(list []uint, id uint) bool {
+	for _, item := range list {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
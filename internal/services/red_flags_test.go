@@ -0,0 +1,88 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scholarship-system/internal/models"
+)
+
+func TestDetectRedFlagsSharedBankAccount(t *testing.T) {
+	flags := DetectRedFlags(models.FraudScanInput{
+		BankAccounts: []models.SignalRecord{
+			{ApplicationID: 1, StudentID: "6512345678", Key: "123-4-56789-0"},
+			{ApplicationID: 2, StudentID: "6512345678", Key: "1234567890"}, // same student, second application
+			{ApplicationID: 5, StudentID: "6512345679", Key: "123 456 7890"},
+			{ApplicationID: 7, StudentID: "6512345680", Key: "9999999999"},
+		},
+	}, models.FraudScanParams{})
+
+	require.Len(t, flags, 3)
+	assert.Equal(t, uint(1), flags[0].ApplicationID)
+	assert.Equal(t, models.SignalSharedBankAccount, flags[0].SignalType)
+	assert.Equal(t, []uint{5}, flags[0].RelatedApplicationIDs)
+	assert.Equal(t, []uint{1, 2}, flags[2].RelatedApplicationIDs)
+	assert.Equal(t, flags[0].MatchKey, flags[2].MatchKey)
+	assert.NotContains(t, flags[0].MatchKey, "1234567890")
+	assert.Equal(t, "Bank account xxxxxx7890 is also used by 1 other student (applications 5)", flags[0].Reason)
+	assert.Equal(t, models.RedFlagOpen, flags[0].Status)
+}
+
+func TestDetectRedFlagsSameStudentIsNotShared(t *testing.T) {
+	flags := DetectRedFlags(models.FraudScanInput{
+		Files: []models.SignalRecord{
+			{ApplicationID: 1, StudentID: "6512345678", Key: "abc", Label: "transcript.pdf"},
+			{ApplicationID: 2, StudentID: "6512345678", Key: "abc", Label: "transcript.pdf"},
+		},
+		GuardianIDs: []models.SignalRecord{
+			{ApplicationID: 1, StudentID: "6512345678", Key: "12"}, // too short to compare
+			{ApplicationID: 3, StudentID: "6512345679", Key: "12"},
+		},
+	}, models.FraudScanParams{})
+
+	assert.Empty(t, flags)
+}
+
+func TestDetectRedFlagsDocuments(t *testing.T) {
+	flags := DetectRedFlags(models.FraudScanInput{
+		HouseRegistrations: []models.SignalRecord{
+			{ApplicationID: 3, StudentID: "A", Key: "hash-1", Label: "house.pdf"},
+			{ApplicationID: 4, StudentID: "B", Key: "hash-1", Label: "house_reg.pdf"},
+		},
+		Files: []models.SignalRecord{
+			{ApplicationID: 4, StudentID: "B", Key: "hash-2", Label: "income.pdf"},
+			{ApplicationID: 6, StudentID: "C", Key: "hash-2", Label: "income.pdf"},
+		},
+	}, models.FraudScanParams{})
+
+	require.Len(t, flags, 4)
+	assert.Equal(t, models.SignalSharedHouseRegistration, flags[0].SignalType)
+	assert.Equal(t, "low", flags[0].Severity)
+	assert.Equal(t, "hash-1", flags[0].MatchKey)
+	assert.Equal(t, models.SignalDuplicateFile, flags[1].SignalType)
+	assert.Equal(t, uint(4), flags[1].ApplicationID)
+	assert.Equal(t, "Uploaded file income.pdf is identical to 1 other student (applications 6)", flags[1].Reason)
+}
+
+func TestDetectRedFlagsIncomeAssetMismatch(t *testing.T) {
+	params := models.FraudScanParams{AssetIncomeYears: 10, MinAssetValue: 1000000}
+	flags := DetectRedFlags(models.FraudScanInput{
+		IncomeAssets: []models.IncomeAssetRecord{
+			{ApplicationID: 1, StudentID: "A", MonthlyIncome: 5000, TotalAssetValue: 3000000},  // 50 years of income
+			{ApplicationID: 2, StudentID: "B", MonthlyIncome: 30000, TotalAssetValue: 3000000}, // 8.3 years
+			{ApplicationID: 3, StudentID: "C", MonthlyIncome: 0, TotalAssetValue: 500000},      // below the minimum
+		},
+	}, params)
+
+	require.Len(t, flags, 1)
+	assert.Equal(t, uint(1), flags[0].ApplicationID)
+	assert.Equal(t, models.SignalIncomeAssetMismatch, flags[0].SignalType)
+	assert.Equal(t, 50.0, flags[0].Details["years_of_income"])
+}
+
+func TestMaskIdentifier(t *testing.T) {
+	assert.Equal(t, "xxxxxxxxx0123", MaskIdentifier("1234567890123"))
+	assert.Equal(t, "123", MaskIdentifier("123"))
+}
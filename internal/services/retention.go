@@ -0,0 +1,145 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"scholarship-system/internal/models"
+)
+
+// maxRetentionMonths is the longest retention period a rule may set
+const maxRetentionMonths = 1200
+
+// RetentionOutcome maps an application status to the outcome retention rules
+// are written for. Applications still in progress have no outcome and are
+// never purged.
+func RetentionOutcome(status string) string {
+	switch status {
+	case "approved", "completed":
+		return models.OutcomeAwarded
+	case "rejected":
+		return models.OutcomeRejected
+	case "withdrawn", "declined":
+		return models.OutcomeWithdrawn
+	case "draft":
+		return models.OutcomeNotSubmitted
+	}
+	return ""
+}
+
+// RetentionStatuses returns the application statuses that have an outcome
+func RetentionStatuses() []string {
+	return []string{"approved", "completed", "rejected", "withdrawn", "declined", "draft"}
+}
+
+// ValidateRetentionRule checks a retention rule and normalises it: a blank
+// document type applies to every type.
+func ValidateRetentionRule(rule *models.RetentionRule) error {
+	rule.RecordType = strings.TrimSpace(rule.RecordType)
+	rule.Outcome = strings.TrimSpace(rule.Outcome)
+	rule.Action = strings.TrimSpace(rule.Action)
+	if rule.DocumentType != nil {
+		documentType := strings.TrimSpace(*rule.DocumentType)
+		rule.DocumentType = &documentType
+		if documentType == "" {
+			rule.DocumentType = nil
+		}
+	}
+
+	switch {
+	case rule.RecordType != models.RetentionDocuments && rule.RecordType != models.RetentionApplicationDetails:
+		return fmt.Errorf("record_type must be %s or %s", models.RetentionDocuments, models.RetentionApplicationDetails)
+	case !contains(models.RetentionOutcomes, rule.Outcome):
+		return fmt.Errorf("outcome must be one of %s", strings.Join(models.RetentionOutcomes, ", "))
+	case rule.Action != models.RetentionPurge && rule.Action != models.RetentionAnonymize:
+		return fmt.Errorf("action must be %s or %s", models.RetentionPurge, models.RetentionAnonymize)
+	case rule.RetentionMonths < 0 || rule.RetentionMonths > maxRetentionMonths:
+		return fmt.Errorf("retention_months must be between 0 and %d", maxRetentionMonths)
+	case rule.DocumentType != nil && rule.RecordType != models.RetentionDocuments:
+		return fmt.Errorf("document_type only applies to %s rules", models.RetentionDocuments)
+	case rule.DocumentType != nil && !IsDocumentType(*rule.DocumentType):
+		return fmt.Errorf("unknown document type %q", *rule.DocumentType)
+	}
+	return nil
+}
+
+// PlanRetention returns the purges and anonymizations of an application that
+// are due at now. Periods run from the announcement date of the application's
+// round, so nothing is due without one. A rule for a document type takes
+// precedence over the rule for every type; inactive rules are ignored.
+func PlanRetention(candidate models.RetentionCandidate, documents []models.RetentionDocument, rules []models.RetentionRule, now time.Time) []models.RetentionAction {
+	outcome := RetentionOutcome(candidate.Status)
+	if outcome == "" || candidate.AnnouncementDate == nil {
+		return nil
+	}
+
+	var details, anyType *models.RetentionRule
+	byType := map[string]*models.RetentionRule{}
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.IsActive || rule.Outcome != outcome {
+			continue
+		}
+		switch {
+		case rule.RecordType == models.RetentionApplicationDetails:
+			details = rule
+		case rule.DocumentType == nil:
+			anyType = rule
+		default:
+			byType[*rule.DocumentType] = rule
+		}
+	}
+
+	action := func(rule *models.RetentionRule, recordType string) (models.RetentionAction, bool) {
+		due := candidate.AnnouncementDate.AddDate(0, rule.RetentionMonths, 0)
+		return models.RetentionAction{
+			ApplicationID:    candidate.ApplicationID,
+			ScholarshipID:    candidate.ScholarshipID,
+			Outcome:          outcome,
+			RecordType:       recordType,
+			Action:           rule.Action,
+			RuleID:           rule.RuleID,
+			AnnouncementDate: *candidate.AnnouncementDate,
+			DueAt:            due,
+		}, !now.Before(due)
+	}
+
+	actions := []models.RetentionAction{}
+	for i := range documents {
+		doc := &documents[i]
+		rule := byType[doc.DocumentType]
+		if rule == nil {
+			rule = anyType
+		}
+		// An anonymized document has nothing left to anonymize, but a
+		// later purge rule still removes its row
+		if rule == nil || (doc.UploadStatus == models.DocumentPurged && rule.Action == models.RetentionAnonymize) {
+			continue
+		}
+		if due, ok := action(rule, models.RetentionDocuments); ok {
+			documentID := doc.DocumentID
+			due.DocumentID = &documentID
+			due.DocumentType = doc.DocumentType
+			due.Files = len(doc.Files)
+			due.Document = doc
+			actions = append(actions, due)
+		}
+	}
+
+	if details != nil && !detailsRetained(candidate.DetailsRetention, details.Action) {
+		if due, ok := action(details, models.RetentionApplicationDetails); ok {
+			actions = append(actions, due)
+		}
+	}
+	return actions
+}
+
+// detailsRetained reports whether the form details already went through the
+// action or a stronger one
+func detailsRetained(applied *string, action string) bool {
+	if applied == nil {
+		return false
+	}
+	return *applied == models.RetentionPurge || *applied == action
+}
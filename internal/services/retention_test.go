@@ -0,0 +1,129 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scholarship-system/internal/models"
+)
+
+func TestRetentionOutcome(t *testing.T) {
+	for status, outcome := range map[string]string{
+		"approved":            models.OutcomeAwarded,
+		"completed":           models.OutcomeAwarded,
+		"rejected":            models.OutcomeRejected,
+		"declined":            models.OutcomeWithdrawn,
+		"draft":               models.OutcomeNotSubmitted,
+		"submitted":           "",
+		"interview_scheduled": "",
+		"needs_revision":      "",
+	} {
+		assert.Equal(t, outcome, RetentionOutcome(status), status)
+	}
+	for _, status := range RetentionStatuses() {
+		assert.NotEmpty(t, RetentionOutcome(status), status)
+	}
+}
+
+func TestValidateRetentionRule(t *testing.T) {
+	blank := " "
+	rule := &models.RetentionRule{RecordType: "documents", DocumentType: &blank, Outcome: " rejected", RetentionMonths: 6, Action: "purge"}
+	require.NoError(t, ValidateRetentionRule(rule))
+	assert.Nil(t, rule.DocumentType)
+	assert.Equal(t, models.OutcomeRejected, rule.Outcome)
+
+	idCard, passport := "id_card", "passport"
+	cases := map[string]models.RetentionRule{
+		"record_type must be documents or application_details":               {RecordType: "files", Outcome: "rejected", Action: "purge"},
+		"outcome must be one of awarded, rejected, withdrawn, not_submitted": {RecordType: "documents", Outcome: "approved", Action: "purge"},
+		"action must be purge or anonymize":                                  {RecordType: "documents", Outcome: "rejected", Action: "archive"},
+		"retention_months must be between 0 and 1200":                        {RecordType: "documents", Outcome: "rejected", Action: "purge", RetentionMonths: -1},
+		"document_type only applies to documents rules":                      {RecordType: "application_details", DocumentType: &idCard, Outcome: "rejected", Action: "purge"},
+		`unknown document type "passport"`:                                   {RecordType: "documents", DocumentType: &passport, Outcome: "rejected", Action: "purge"},
+	}
+	for message, invalid := range cases {
+		assert.EqualError(t, ValidateRetentionRule(&invalid), message)
+	}
+}
+
+func TestPlanRetention(t *testing.T) {
+	announced := time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+	idCard, transcript := "id_card", "transcript"
+	rules := []models.RetentionRule{
+		{RuleID: 1, RecordType: models.RetentionDocuments, Outcome: models.OutcomeRejected, RetentionMonths: 6, Action: models.RetentionPurge, IsActive: true},
+		{RuleID: 2, RecordType: models.RetentionDocuments, DocumentType: &transcript, Outcome: models.OutcomeRejected, RetentionMonths: 24, Action: models.RetentionAnonymize, IsActive: true},
+		{RuleID: 3, RecordType: models.RetentionApplicationDetails, Outcome: models.OutcomeRejected, RetentionMonths: 12, Action: models.RetentionAnonymize, IsActive: true},
+		{RuleID: 4, RecordType: models.RetentionDocuments, DocumentType: &idCard, Outcome: models.OutcomeRejected, RetentionMonths: 1, Action: models.RetentionPurge},
+		{RuleID: 5, RecordType: models.RetentionDocuments, Outcome: models.OutcomeAwarded, RetentionMonths: 60, Action: models.RetentionPurge, IsActive: true},
+	}
+	documents := []models.RetentionDocument{
+		{DocumentID: 10, DocumentType: "id_card", UploadStatus: "verified", Files: make([]models.StoredObject, 2)},
+		{DocumentID: 11, DocumentType: "transcript", UploadStatus: "verified", Files: make([]models.StoredObject, 1)},
+		{DocumentID: 12, DocumentType: "house_photos", UploadStatus: models.DocumentPurged},
+	}
+	candidate := models.RetentionCandidate{ApplicationID: 7, ScholarshipID: 3, Status: "rejected", AnnouncementDate: &announced}
+	type planned struct {
+		RecordType string
+		DocumentID int
+		RuleID     int
+		Action     string
+	}
+	plan := func(candidate models.RetentionCandidate, now time.Time) []planned {
+		result := []planned{}
+		for _, action := range PlanRetention(candidate, documents, rules, now) {
+			p := planned{RecordType: action.RecordType, RuleID: action.RuleID, Action: action.Action}
+			if action.DocumentID != nil {
+				p.DocumentID = *action.DocumentID
+			}
+			result = append(result, p)
+		}
+		return result
+	}
+
+	t.Run("nothing is due before the period ends", func(t *testing.T) {
+		assert.Empty(t, plan(candidate, time.Date(2024, 11, 30, 23, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("the rule for the document type wins over the rule for every type", func(t *testing.T) {
+		assert.Equal(t, []planned{
+			{RecordType: models.RetentionDocuments, DocumentID: 10, RuleID: 1, Action: models.RetentionPurge},
+			{RecordType: models.RetentionDocuments, DocumentID: 12, RuleID: 1, Action: models.RetentionPurge},
+		}, plan(candidate, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)))
+
+		actions := PlanRetention(candidate, documents, rules, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))
+		require.Len(t, actions, 4)
+		assert.Equal(t, 2, actions[0].Files)
+		assert.Equal(t, 3, actions[0].ScholarshipID)
+		assert.Equal(t, time.Date(2026, 5, 31, 0, 0, 0, 0, time.UTC), actions[1].DueAt)
+		assert.Equal(t, models.RetentionAnonymize, actions[1].Action)
+		assert.Equal(t, models.RetentionApplicationDetails, actions[3].RecordType)
+	})
+
+	t.Run("records already handled are skipped", func(t *testing.T) {
+		anonymized := models.RetentionAnonymize
+		done := candidate
+		done.DetailsRetention = &anonymized
+		assert.Equal(t, []planned{
+			{RecordType: models.RetentionDocuments, DocumentID: 10, RuleID: 1, Action: models.RetentionPurge},
+			{RecordType: models.RetentionDocuments, DocumentID: 12, RuleID: 1, Action: models.RetentionPurge},
+		}, plan(done, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("outcome and announcement date are needed", func(t *testing.T) {
+		inReview := candidate
+		inReview.Status = "under_review"
+		assert.Empty(t, plan(inReview, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+		unannounced := candidate
+		unannounced.AnnouncementDate = nil
+		assert.Empty(t, plan(unannounced, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+		awarded := candidate
+		awarded.Status = "completed"
+		assert.Empty(t, plan(awarded, time.Date(2029, 5, 30, 0, 0, 0, 0, time.UTC)))
+		assert.Len(t, plan(awarded, time.Date(2029, 5, 31, 0, 0, 0, 0, time.UTC)), 3)
+	})
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"scholarship-system/internal/models"
+)
+
+// ScoreApplication applies a scoring profile to the metrics of an
+// application. A metric missing from metrics scores 0 and is listed in
+// Missing. criteria holds the review criteria the components refer to, keyed
+// by criteria_id; it may be nil.
+func ScoreApplication(profile *models.ScoringProfile, metrics map[string]float64, criteria map[int]models.ReviewCriterion) *models.ScoreBreakdown {
+	breakdown := &models.ScoreBreakdown{
+		ProfileID:   profile.ProfileID,
+		ProfileName: profile.ProfileName,
+		Components:  []models.ComponentScore{},
+		Missing:     []string{},
+		ScoredAt:    time.Now(),
+	}
+
+	for _, component := range profile.Components {
+		result := models.ComponentScore{
+			Metric:     component.Metric,
+			Label:      component.Label,
+			CriteriaID: component.CriteriaID,
+			Weight:     component.Weight,
+		}
+
+		if value, ok := metrics[component.Metric]; ok {
+			result.Value = &value
+			result.Score = roundScore(CurveScore(component.Curve, value))
+		} else {
+			breakdown.Missing = append(breakdown.Missing, component.Metric)
+		}
+		result.WeightedScore = roundScore(result.Score * component.Weight)
+
+		if component.CriteriaID != nil {
+			if criterion, ok := criteria[*component.CriteriaID]; ok {
+				if result.Label == "" {
+					result.Label = criterion.CriteriaName
+				}
+				points := roundScore(result.Score / 100 * criterion.MaxScore)
+				result.CriteriaScore = &points
+			}
+		}
+
+		breakdown.TotalScore += result.Score * component.Weight
+		breakdown.Components = append(breakdown.Components, result)
+	}
+
+	breakdown.TotalScore = roundScore(breakdown.TotalScore)
+	return breakdown
+}
+
+// CurveScore interpolates the score of x on a piecewise linear curve. Values
+// before the first or after the last point take that point's score.
+func CurveScore(curve []models.CurvePoint, x float64) float64 {
+	if len(curve) == 0 {
+		return 0
+	}
+	if x <= curve[0].X {
+		return curve[0].Score
+	}
+	for i := 1; i < len(curve); i++ {
+		if x <= curve[i].X {
+			from, to := curve[i-1], curve[i]
+			return from.Score + (x-from.X)/(to.X-from.X)*(to.Score-from.Score)
+		}
+	}
+	return curve[len(curve)-1].Score
+}
+
+// ValidateScoringComponents checks that every component uses a known metric
+// and a well-formed curve, that linked review criteria exist, and that the
+// weights add up to 1
+func ValidateScoringComponents(components []models.ScoringComponent, criteria map[int]models.ReviewCriterion) error {
+	if len(components) == 0 {
+		return fmt.Errorf("at least one component is required")
+	}
+
+	totalWeight := 0.0
+	for i, component := range components {
+		if !contains(models.ScoringMetrics, component.Metric) {
+			return fmt.Errorf("component %d: unknown metric %q", i+1, component.Metric)
+		}
+		if component.Weight < 0 || component.Weight > 1 {
+			return fmt.Errorf("component %d: weight must be between 0 and 1", i+1)
+		}
+		if len(component.Curve) == 0 {
+			return fmt.Errorf("component %d: curve needs at least one point", i+1)
+		}
+		for j, point := range component.Curve {
+			if point.Score < 0 || point.Score > 100 {
+				return fmt.Errorf("component %d: curve scores must be between 0 and 100", i+1)
+			}
+			if j > 0 && point.X <= component.Curve[j-1].X {
+				return fmt.Errorf("component %d: curve points must be sorted by x without duplicates", i+1)
+			}
+		}
+		if component.CriteriaID != nil {
+			if _, ok := criteria[*component.CriteriaID]; !ok {
+				return fmt.Errorf("component %d: review criteria %d not found for this scholarship", i+1, *component.CriteriaID)
+			}
+		}
+		totalWeight += component.Weight
+	}
+
+	if math.Abs(totalWeight-1) > 0.001 {
+		return fmt.Errorf("component weights must add up to 1 (got %.3f)", totalWeight)
+	}
+	return nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func roundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scholarship-system/internal/models"
+)
+
+func TestCurveScore(t *testing.T) {
+	curve := []models.CurvePoint{{X: 2, Score: 50}, {X: 3, Score: 60}, {X: 4, Score: 100}}
+
+	assert.Equal(t, 50.0, CurveScore(curve, 0))
+	assert.Equal(t, 55.0, CurveScore(curve, 2.5))
+	assert.Equal(t, 80.0, CurveScore(curve, 3.5))
+	assert.Equal(t, 100.0, CurveScore(curve, 4))
+	assert.Equal(t, 100.0, CurveScore(curve, 5))
+	assert.Equal(t, 0.0, CurveScore(nil, 3))
+}
+
+func TestScoreApplicationDefaultProfile(t *testing.T) {
+	// Same result as the old hard-coded GPA 40% / financial 30% / activities 30%
+	breakdown := ScoreApplication(models.DefaultScoringProfile(), map[string]float64{
+		models.MetricGPA:           3.5,
+		models.MetricNeedScore:     80,
+		models.MetricActivityCount: 2,
+	}, nil)
+
+	assert.Equal(t, 87.5, breakdown.Component(models.MetricGPA).Score)
+	assert.Equal(t, 40.0, breakdown.Component(models.MetricActivityCount).Score)
+	assert.Equal(t, 35.0+24.0+12.0, breakdown.TotalScore)
+	assert.Empty(t, breakdown.Missing)
+}
+
+func TestScoreApplicationCriteriaAndMissing(t *testing.T) {
+	criteriaID := 7
+	profile := &models.ScoringProfile{
+		ProfileID:   3,
+		ProfileName: "Need based",
+		Components: []models.ScoringComponent{
+			{Metric: models.MetricPerCapitaIncome, CriteriaID: &criteriaID, Weight: 0.6,
+				Curve: []models.CurvePoint{{X: 2000, Score: 100}, {X: 10000, Score: 0}}},
+			{Metric: models.MetricGPA, Label: "GPA", Weight: 0.4, Curve: []models.CurvePoint{{X: 0, Score: 0}, {X: 4, Score: 100}}},
+		},
+	}
+	criteria := map[int]models.ReviewCriterion{7: {CriteriaID: 7, CriteriaName: "ฐานะทางเศรษฐกิจ", MaxScore: 40}}
+
+	breakdown := ScoreApplication(profile, map[string]float64{models.MetricPerCapitaIncome: 4000}, criteria)
+
+	need := breakdown.Component(models.MetricPerCapitaIncome)
+	require.NotNil(t, need)
+	assert.Equal(t, "ฐานะทางเศรษฐกิจ", need.Label)
+	assert.Equal(t, 75.0, need.Score)
+	require.NotNil(t, need.CriteriaScore)
+	assert.Equal(t, 30.0, *need.CriteriaScore)
+
+	gpa := breakdown.Component(models.MetricGPA)
+	assert.Nil(t, gpa.Value)
+	assert.Equal(t, 0.0, gpa.Score)
+	assert.Equal(t, []string{models.MetricGPA}, breakdown.Missing)
+	assert.Equal(t, 45.0, breakdown.TotalScore)
+}
+
+func TestValidateScoringComponents(t *testing.T) {
+	assert.NoError(t, ValidateScoringComponents(models.DefaultScoringProfile().Components, nil))
+
+	components := models.DefaultScoringProfile().Components
+	components[0].Weight = 0.5
+	assert.ErrorContains(t, ValidateScoringComponents(components, nil), "add up to 1")
+
+	components = models.DefaultScoringProfile().Components
+	components[1].Metric = "height"
+	assert.ErrorContains(t, ValidateScoringComponents(components, nil), "unknown metric")
+
+	components = models.DefaultScoringProfile().Components
+	components[2].Curve = []models.CurvePoint{{X: 5, Score: 100}, {X: 0, Score: 0}}
+	assert.ErrorContains(t, ValidateScoringComponents(components, nil), "sorted")
+
+	missing := 99
+	components = models.DefaultScoringProfile().Components
+	components[0].CriteriaID = &missing
+	assert.ErrorContains(t, ValidateScoringComponents(components, nil), "review criteria 99")
+}
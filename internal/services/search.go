@@ -0,0 +1,322 @@
+package services
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"scholarship-system/internal/models"
+)
+
+// Thai is written without spaces between words and Postgres has no Thai
+// parser, so Thai text is indexed as overlapping character pairs and a query
+// matches when all of its pairs are present. Digit runs are also indexed as
+// 4-digit pieces so officers can search by a fragment of a national ID.
+const (
+	digitGram       = 4
+	snippetContext  = 30 // runes of context on each side of a match
+	maxHighlights   = 3
+	highlightOpen   = "<mark>"
+	highlightClose  = "</mark>"
+	snippetEllipsis = "…"
+)
+
+// Kinds of runs the text is split into
+const (
+	searchRunBreak = 0
+	searchRunDigit = 'd'
+	searchRunThai  = 't'
+	searchRunWord  = 'w'
+)
+
+type searchRun struct {
+	kind rune
+	text []rune
+}
+
+// normalizeSearchText lower-cases text and turns Thai digits into ASCII
+// digits, keeping one rune per input rune
+func normalizeSearchText(text string) []rune {
+	runes := []rune(text)
+	for i, r := range runes {
+		if r >= '๐' && r <= '๙' {
+			runes[i] = '0' + (r - '๐')
+			continue
+		}
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+func searchRunKind(r rune) rune {
+	switch {
+	case r >= '0' && r <= '9':
+		return searchRunDigit
+	case unicode.Is(unicode.Thai, r):
+		return searchRunThai
+	case unicode.IsLetter(r) || unicode.IsDigit(r):
+		return searchRunWord
+	}
+	return searchRunBreak
+}
+
+// searchRuns splits normalized text into runs of digits, Thai and other words
+func searchRuns(runes []rune) []searchRun {
+	var runs []searchRun
+	for i := 0; i < len(runes); {
+		kind := searchRunKind(runes[i])
+		if kind == searchRunBreak {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(runes) && searchRunKind(runes[j]) == kind {
+			j++
+		}
+		runs = append(runs, searchRun{kind: kind, text: runes[i:j]})
+		i = j
+	}
+	return runs
+}
+
+func grams(runes []rune, size int) []string {
+	if len(runes) <= size {
+		return []string{string(runes)}
+	}
+	out := make([]string, 0, len(runes)-size+1)
+	for i := 0; i+size <= len(runes); i++ {
+		out = append(out, string(runes[i:i+size]))
+	}
+	return out
+}
+
+// SearchTokens returns the index tokens of a text
+func SearchTokens(text string) []string {
+	seen := map[string]bool{}
+	tokens := []string{}
+	add := func(token string) {
+		if token != "" && !seen[token] {
+			seen[token] = true
+			tokens = append(tokens, token)
+		}
+	}
+
+	for _, run := range searchRuns(normalizeSearchText(text)) {
+		switch run.kind {
+		case searchRunThai:
+			for _, gram := range grams(run.text, 2) {
+				add(gram)
+			}
+		case searchRunDigit:
+			add(string(run.text))
+			if len(run.text) > digitGram {
+				for _, gram := range grams(run.text, digitGram) {
+					add(gram)
+				}
+			}
+		default:
+			add(string(run.text))
+		}
+	}
+	return tokens
+}
+
+// BuildSearchQuery turns what an officer typed into a tsquery that requires
+// every term. Words and short numbers match as prefixes. It returns "" when
+// the query has nothing to search for.
+func BuildSearchQuery(query string) string {
+	seen := map[string]bool{}
+	var terms []string
+	add := func(term string) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	quote := func(token string) string {
+		return "'" + strings.ReplaceAll(strings.ReplaceAll(token, `\`, `\\`), "'", "''") + "'"
+	}
+
+	for _, run := range searchRuns(normalizeSearchText(query)) {
+		switch run.kind {
+		case searchRunThai:
+			if len(run.text) == 1 {
+				add(quote(string(run.text)) + ":*")
+				continue
+			}
+			for _, gram := range grams(run.text, 2) {
+				add(quote(gram))
+			}
+		case searchRunDigit:
+			if len(run.text) <= digitGram {
+				add(quote(string(run.text)) + ":*")
+				continue
+			}
+			for _, gram := range grams(run.text, digitGram) {
+				add(quote(gram))
+			}
+		default:
+			add(quote(string(run.text)) + ":*")
+		}
+	}
+	return strings.Join(terms, " & ")
+}
+
+// BuildSearchDocument collects the searchable fields of an application
+func BuildSearchDocument(src models.SearchSource) models.SearchDocument {
+	doc := models.SearchDocument{
+		ApplicationID: src.ApplicationID,
+		FormVersion:   src.FormVersion,
+		SourceUpdated: src.UpdatedAt,
+		Fields:        []models.SearchField{},
+		Tokens:        map[string][]string{},
+	}
+
+	fields := []models.SearchField{
+		{Name: "student_name", Label: "Student name", Weight: models.SearchWeightA, Text: src.StudentName},
+		{Name: "student_id", Label: "Student ID", Weight: models.SearchWeightA, Text: src.StudentID},
+		{Name: "citizen_id", Label: "National ID", Weight: models.SearchWeightA, Text: src.CitizenID},
+		{Name: "scholarship", Label: "Scholarship", Weight: models.SearchWeightB, Text: src.ScholarshipName},
+		{Name: "faculty", Label: "Faculty", Weight: models.SearchWeightB, Text: src.Faculty},
+		{Name: "addresses", Label: "Address", Weight: models.SearchWeightB, Text: src.Addresses},
+		{Name: "schools", Label: "School", Weight: models.SearchWeightB, Text: src.Schools},
+		{Name: "family", Label: "Family", Weight: models.SearchWeightB, Text: src.Family},
+		{Name: "activities", Label: "Activities", Weight: models.SearchWeightC, Text: src.Activities},
+	}
+
+	seen := map[string]map[string]bool{}
+	for _, field := range fields {
+		field.Text = strings.Join(strings.Fields(field.Text), " ")
+		if field.Text == "" {
+			continue
+		}
+		doc.Fields = append(doc.Fields, field)
+
+		if seen[field.Weight] == nil {
+			seen[field.Weight] = map[string]bool{}
+		}
+		for _, token := range SearchTokens(field.Text) {
+			if !seen[field.Weight][token] {
+				seen[field.Weight][token] = true
+				doc.Tokens[field.Weight] = append(doc.Tokens[field.Weight], token)
+			}
+		}
+	}
+	return doc
+}
+
+// HighlightMatches returns snippets of the fields that contain the query
+// terms, with each match wrapped in <mark>
+func HighlightMatches(fields []models.SearchField, query string) []models.SearchHighlight {
+	var terms [][]rune
+	for _, run := range searchRuns(normalizeSearchText(query)) {
+		terms = append(terms, run.text)
+	}
+
+	highlights := []models.SearchHighlight{}
+	if len(terms) == 0 {
+		return highlights
+	}
+
+	for _, field := range fields {
+		original := []rune(field.Text)
+		normalized := normalizeSearchText(field.Text)
+
+		var matches [][2]int
+		for _, term := range terms {
+			for i := 0; i+len(term) <= len(normalized); i++ {
+				if string(normalized[i:i+len(term)]) == string(term) {
+					matches = append(matches, [2]int{i, i + len(term)})
+				}
+			}
+		}
+		if len(matches) == 0 {
+			continue
+		}
+
+		highlights = append(highlights, models.SearchHighlight{
+			Field:   field.Name,
+			Label:   field.Label,
+			Snippet: markSnippet(original, mergeMatches(matches)),
+		})
+		if len(highlights) == maxHighlights {
+			break
+		}
+	}
+	return highlights
+}
+
+func mergeMatches(matches [][2]int) [][2]int {
+	sort.Slice(matches, func(i, j int) bool { return matches[i][0] < matches[j][0] })
+	merged := [][2]int{matches[0]}
+	for _, m := range matches[1:] {
+		last := &merged[len(merged)-1]
+		if m[0] <= last[1] {
+			if m[1] > last[1] {
+				last[1] = m[1]
+			}
+			continue
+		}
+		merged = append(merged, m)
+	}
+	return merged
+}
+
+// markSnippet cuts the text around the first match and marks the matches
+// inside the cut
+func markSnippet(text []rune, matches [][2]int) string {
+	from := matches[0][0] - snippetContext
+	if from < 0 {
+		from = 0
+	}
+	to := matches[0][1] + snippetContext
+	if to > len(text) {
+		to = len(text)
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString(snippetEllipsis)
+	}
+	pos := from
+	for _, m := range matches {
+		if m[0] >= to {
+			break
+		}
+		end := m[1]
+		if end > to {
+			end = to
+		}
+		b.WriteString(html.EscapeString(string(text[pos:m[0]])))
+		b.WriteString(highlightOpen)
+		b.WriteString(html.EscapeString(string(text[m[0]:end])))
+		b.WriteString(highlightClose)
+		pos = end
+	}
+	b.WriteString(html.EscapeString(string(text[pos:to])))
+	if to < len(text) {
+		b.WriteString(snippetEllipsis)
+	}
+	return b.String()
+}
+
+// SearchScopeFor returns which applications a user can search. Admins and
+// scholarship officers see everything; other staff only see applicants from
+// their own faculties.
+func SearchScopeFor(roles []string, faculties []string) models.SearchScope {
+	for _, role := range roles {
+		if role == "admin" || role == "scholarship_officer" {
+			return models.SearchScope{All: true}
+		}
+	}
+
+	scope := models.SearchScope{Faculties: []string{}}
+	for _, faculty := range faculties {
+		faculty = strings.ToLower(strings.TrimSpace(faculty))
+		if faculty != "" && !contains(scope.Faculties, faculty) {
+			scope.Faculties = append(scope.Faculties, faculty)
+		}
+	}
+	return scope
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scholarship-system/internal/models"
+)
+
+func TestSearchTokens(t *testing.T) {
+	tokens := SearchTokens("เชียงราย Farmer")
+	assert.Equal(t, []string{"เช", "ชี", "ีย", "ยง", "งร", "รา", "าย", "farmer"}, tokens)
+
+	// Digit runs are kept whole and cut into 4-digit pieces; Thai digits count as digits
+	tokens = SearchTokens("ID ๑๒๓๔๕๖")
+	assert.Equal(t, []string{"id", "123456", "1234", "2345", "3456"}, tokens)
+}
+
+func TestBuildSearchQuery(t *testing.T) {
+	assert.Equal(t, "'ชา' & 'าว' & 'วน' & 'นา' & 'chiang':*", BuildSearchQuery("ชาวนา Chiang"))
+	assert.Equal(t, "'12':*", BuildSearchQuery("12"))
+	assert.Equal(t, "'3456' & '4567'", BuildSearchQuery("34567"))
+	assert.Equal(t, "", BuildSearchQuery("  - ; "))
+}
+
+func TestBuildSearchDocument(t *testing.T) {
+	doc := BuildSearchDocument(models.SearchSource{
+		ApplicationID: 7,
+		StudentName:   "สมชาย  ใจดี",
+		StudentID:     "6401001",
+		Family:        "father ชาวนา",
+		Activities:    "",
+	})
+
+	require.Len(t, doc.Fields, 3)
+	assert.Equal(t, "สมชาย ใจดี", doc.Fields[0].Text)
+	assert.Equal(t, "family", doc.Fields[2].Name)
+	assert.Contains(t, doc.Tokens[models.SearchWeightA], "6401001")
+	assert.Contains(t, doc.Tokens[models.SearchWeightA], "สม")
+	assert.Contains(t, doc.Tokens[models.SearchWeightB], "father")
+	assert.Empty(t, doc.Tokens[models.SearchWeightC])
+}
+
+func TestHighlightMatches(t *testing.T) {
+	fields := []models.SearchField{
+		{Name: "student_name", Label: "Student name", Text: "สมชาย ใจดี"},
+		{Name: "addresses", Label: "Address", Text: "ต.เวียง อ.เมือง จ.เชียงราย <ทดสอบ> 57000"},
+		{Name: "family", Label: "Family", Text: "บิดา: Somsak, Farmer"},
+	}
+
+	highlights := HighlightMatches(fields, "เชียงราย farmer")
+
+	require.Len(t, highlights, 2)
+	assert.Equal(t, "addresses", highlights[0].Field)
+	assert.Equal(t, "ต.เวียง อ.เมือง จ.<mark>เชียงราย</mark> &lt;ทดสอบ&gt; 57000", highlights[0].Snippet)
+	assert.Equal(t, "บิดา: Somsak, <mark>Farmer</mark>", highlights[1].Snippet)
+}
+
+func TestHighlightMatchesTrimsLongText(t *testing.T) {
+	text := "กิจกรรมจิตอาสาพัฒนาชุมชนและโรงเรียนในพื้นที่ห่างไกลเป็นเวลาสามปีติดต่อกัน ได้รับรางวัลเยาวชนดีเด่นระดับจังหวัด"
+	highlights := HighlightMatches([]models.SearchField{{Name: "activities", Text: text}}, "รางวัล")
+
+	require.Len(t, highlights, 1)
+	snippet := highlights[0].Snippet
+	assert.Contains(t, snippet, "<mark>รางวัล</mark>")
+	assert.True(t, len([]rune(snippet)) < len([]rune(text)))
+	assert.Equal(t, "…", string([]rune(snippet)[0]))
+}
+
+func TestSearchScopeFor(t *testing.T) {
+	assert.True(t, SearchScopeFor([]string{"interviewer", "scholarship_officer"}, nil).All)
+
+	scope := SearchScopeFor([]string{"interviewer"}, []string{" Economics ", "economics", "", "ECON"})
+	assert.False(t, scope.All)
+	assert.Equal(t, []string{"economics", "econ"}, scope.Faculties)
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"scholarship-system/internal/models"
+)
+
+// BuildSnapshot captures the complete form and the attached documents of an
+// application. Document files are read back through hashFile so later
+// replacement of a file shows up as a change even if its metadata stays the
+// same.
+func BuildSnapshot(form *models.CompleteApplicationForm, documents []models.ApplicationDocument,
+	hashFile func(models.ApplicationDocument) string) (*models.ApplicationSnapshot, error) {
+	formData, err := canonicalJSON(form)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode application form: %w", err)
+	}
+
+	snapshotDocs := make([]models.SnapshotDocument, 0, len(documents))
+	for _, doc := range documents {
+		snapshotDocs = append(snapshotDocs, models.SnapshotDocument{
+			DocumentID:   doc.DocumentID,
+			DocumentType: doc.DocumentType,
+			DocumentName: doc.DocumentName,
+			FileSize:     doc.FileSize,
+			MimeType:     doc.MimeType,
+			SHA256:       hashFile(doc),
+			UploadedAt:   doc.UploadedAt,
+		})
+	}
+
+	snapshot := &models.ApplicationSnapshot{
+		FormData:  formData,
+		Documents: snapshotDocs,
+	}
+	if form != nil && form.Application != nil {
+		snapshot.ApplicationID = form.Application.ApplicationID
+	}
+	snapshot.Checksum, err = SnapshotChecksum(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// SnapshotChecksum hashes the form data and documents of a snapshot. Both are
+// re-encoded canonically first, so the checksum survives a round trip
+// through JSONB, which does not preserve key order or whitespace.
+func SnapshotChecksum(snapshot *models.ApplicationSnapshot) (string, error) {
+	var form interface{}
+	if len(snapshot.FormData) > 0 {
+		if err := json.Unmarshal(snapshot.FormData, &form); err != nil {
+			return "", fmt.Errorf("failed to decode snapshot form: %w", err)
+		}
+	}
+	payload, err := canonicalJSON(map[string]interface{}{
+		"form":      form,
+		"documents": snapshot.Documents,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// VerifySnapshot reports whether a stored snapshot still matches its checksum
+func VerifySnapshot(snapshot *models.ApplicationSnapshot) bool {
+	checksum, err := SnapshotChecksum(snapshot)
+	return err == nil && checksum == snapshot.Checksum
+}
+
+// DiffSnapshots compares two snapshots field by field and document by
+// document. A document whose file hash differs is reported as changed.
+func DiffSnapshots(from, to *models.ApplicationSnapshot) (*models.SnapshotDiff, error) {
+	fields, err := DiffApplicationForms(from.FormData, to.FormData)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &models.SnapshotDiff{
+		FromVersion:      from.Version,
+		ToVersion:        to.Version,
+		Fields:           fields,
+		DocumentsAdded:   []models.SnapshotDocument{},
+		DocumentsRemoved: []models.SnapshotDocument{},
+		DocumentsChanged: []models.SnapshotDocument{},
+	}
+
+	oldDocs := map[int]models.SnapshotDocument{}
+	for _, doc := range from.Documents {
+		oldDocs[doc.DocumentID] = doc
+	}
+	newIDs := map[int]bool{}
+	for _, doc := range to.Documents {
+		newIDs[doc.DocumentID] = true
+		old, ok := oldDocs[doc.DocumentID]
+		if !ok {
+			diff.DocumentsAdded = append(diff.DocumentsAdded, doc)
+		} else if old.SHA256 != doc.SHA256 || old.FileSize != doc.FileSize {
+			diff.DocumentsChanged = append(diff.DocumentsChanged, doc)
+		}
+	}
+	for _, doc := range from.Documents {
+		if !newIDs[doc.DocumentID] {
+			diff.DocumentsRemoved = append(diff.DocumentsRemoved, doc)
+		}
+	}
+	return diff, nil
+}
+
+// canonicalJSON encodes v with map keys in sorted order
+func canonicalJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scholarship-system/internal/models"
+)
+
+func TestBuildSnapshotChecksum(t *testing.T) {
+	form := &models.CompleteApplicationForm{
+		Application:  &models.ScholarshipApplication{ApplicationID: 7, ApplicationStatus: "submitted"},
+		FundingNeeds: &models.ApplicationFundingNeeds{},
+	}
+	docs := []models.ApplicationDocument{
+		{DocumentID: 1, DocumentType: "transcript", FilePath: "applications/7/transcript.pdf", FileSize: 19, UploadedAt: time.Now()},
+		{DocumentID: 2, DocumentType: "id_card", FilePath: "applications/7/missing.jpg"},
+	}
+
+	stored := map[string]string{"applications/7/transcript.pdf": strings.Repeat("ab", 32)}
+	snapshot, err := BuildSnapshot(form, docs, func(doc models.ApplicationDocument) string {
+		return stored[doc.FilePath]
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint(7), snapshot.ApplicationID)
+	assert.Len(t, snapshot.Checksum, 64)
+	assert.Len(t, snapshot.Documents[0].SHA256, 64)
+	assert.Empty(t, snapshot.Documents[1].SHA256)
+	assert.True(t, VerifySnapshot(snapshot))
+
+	// Reordering keys, as JSONB does, keeps the checksum valid
+	var generic map[string]interface{}
+	require.NoError(t, json.Unmarshal(snapshot.FormData, &generic))
+	reencoded, err := json.MarshalIndent(generic, "", "  ")
+	require.NoError(t, err)
+	snapshot.FormData = reencoded
+	assert.True(t, VerifySnapshot(snapshot))
+
+	// Any change to the stored data breaks it
+	snapshot.Documents[0].SHA256 = "tampered"
+	assert.False(t, VerifySnapshot(snapshot))
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	from := &models.ApplicationSnapshot{
+		Version:  1,
+		FormData: []byte(`{"personal_info": {"phone": "0811111111"}}`),
+		Documents: []models.SnapshotDocument{
+			{DocumentID: 1, DocumentType: "id_card", SHA256: "aaa"},
+			{DocumentID: 2, DocumentType: "transcript", SHA256: "bbb"},
+		},
+	}
+	to := &models.ApplicationSnapshot{
+		Version:  2,
+		FormData: []byte(`{"personal_info": {"phone": "0822222222"}}`),
+		Documents: []models.SnapshotDocument{
+			{DocumentID: 1, DocumentType: "id_card", SHA256: "ccc"},
+			{DocumentID: 3, DocumentType: "transcript", SHA256: "ddd"},
+		},
+	}
+
+	diff, err := DiffSnapshots(from, to)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, diff.FromVersion)
+	assert.Equal(t, 2, diff.ToVersion)
+	require.Len(t, diff.Fields, 1)
+	assert.Equal(t, "personal_info.phone", diff.Fields[0].Path)
+	require.Len(t, diff.DocumentsChanged, 1)
+	assert.Equal(t, 1, diff.DocumentsChanged[0].DocumentID)
+	require.Len(t, diff.DocumentsAdded, 1)
+	assert.Equal(t, 3, diff.DocumentsAdded[0].DocumentID)
+	require.Len(t, diff.DocumentsRemoved, 1)
+	assert.Equal(t, 2, diff.DocumentsRemoved[0].DocumentID)
+}
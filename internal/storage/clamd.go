@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamdChunkSize is the size of the INSTREAM chunks sent to clamd
+const clamdChunkSize = 64 * 1024
+
+// ScanVerdict is the outcome of a malware scan
+type ScanVerdict struct {
+	Clean     bool
+	Signature string // name of the malware found, when not clean
+}
+
+// Scanner checks file contents for malware
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (*ScanVerdict, error)
+}
+
+var scanner Scanner
+
+// SetScanner replaces the scanner used for quarantined uploads. A nil
+// scanner leaves uploads in quarantine.
+func SetScanner(s Scanner) {
+	scanner = s
+}
+
+// DefaultScanner returns the configured scanner, or nil if there is none
+func DefaultScanner() Scanner {
+	return scanner
+}
+
+// Unscanned passes every file without looking at it. It is used when
+// DOCUMENT_SCAN_REQUIRED=false and there is no clamd, so quarantined uploads
+// are still released.
+type Unscanned struct{}
+
+// Scan reports the file as clean
+func (Unscanned) Scan(ctx context.Context, r io.Reader) (*ScanVerdict, error) {
+	return &ScanVerdict{Clean: true}, nil
+}
+
+// Clamd scans files with a ClamAV daemon
+type Clamd struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamd creates a clamd client. The address is "tcp://host:port",
+// "unix:///path/to/clamd.sock" or a bare "host:port".
+func NewClamd(address string, timeout time.Duration) (*Clamd, error) {
+	network := "tcp"
+	switch {
+	case strings.HasPrefix(address, "tcp://"):
+		address = strings.TrimPrefix(address, "tcp://")
+	case strings.HasPrefix(address, "unix://"):
+		network = "unix"
+		address = strings.TrimPrefix(address, "unix://")
+	}
+	if address == "" {
+		return nil, fmt.Errorf("clamd address is empty")
+	}
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+	return &Clamd{network: network, address: address, timeout: timeout}, nil
+}
+
+// Ping checks that clamd is reachable
+func (c *Clamd) Ping(ctx context.Context) error {
+	reply, err := c.command(ctx, "zPING\x00", nil)
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("unexpected clamd reply %q", reply)
+	}
+	return nil
+}
+
+// Scan streams r to clamd with the INSTREAM command
+func (c *Clamd) Scan(ctx context.Context, r io.Reader) (*ScanVerdict, error) {
+	reply, err := c.command(ctx, "zINSTREAM\x00", r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replies look like "stream: OK", "stream: Eicar-Signature FOUND" or
+	// "INSTREAM size limit exceeded. ERROR"
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case result == "OK":
+		return &ScanVerdict{Clean: true}, nil
+	case strings.HasSuffix(result, " FOUND"):
+		return &ScanVerdict{Signature: strings.TrimSuffix(result, " FOUND")}, nil
+	}
+	return nil, fmt.Errorf("clamd scan failed: %s", reply)
+}
+
+func (c *Clamd) command(ctx context.Context, cmd string, body io.Reader) (string, error) {
+	dialer := net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	if _, err := io.WriteString(conn, cmd); err != nil {
+		return "", fmt.Errorf("failed to send clamd command: %w", err)
+	}
+	if body != nil {
+		if err := writeChunks(conn, body); err != nil {
+			return "", fmt.Errorf("failed to stream file to clamd: %w", err)
+		}
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && reply == "" {
+		return "", fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+	return strings.TrimRight(reply, "\x00\n"), nil
+}
+
+// writeChunks sends a body as length-prefixed chunks ending with an empty one
+func writeChunks(w io.Writer, body io.Reader) error {
+	buf := make([]byte, 4+clamdChunkSize)
+	for {
+		n, err := body.Read(buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf, uint32(n))
+			if _, werr := w.Write(buf[:4+n]); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := w.Write([]byte{0, 0, 0, 0})
+	return err
+}
@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClamd answers PING and INSTREAM like clamd. Streams containing "EICAR"
+// are reported infected and streams containing "BROKEN" fail.
+func fakeClamd(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveClamd(conn)
+		}
+	}()
+	return "tcp://" + listener.Addr().String()
+}
+
+func serveClamd(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	cmd, err := reader.ReadString(0)
+	if err != nil {
+		return
+	}
+
+	switch cmd {
+	case "zPING\x00":
+		conn.Write([]byte("PONG\x00"))
+	case "zINSTREAM\x00":
+		var body bytes.Buffer
+		for {
+			var size uint32
+			if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+				return
+			}
+			if size == 0 {
+				break
+			}
+			if _, err := io.CopyN(&body, reader, int64(size)); err != nil {
+				return
+			}
+		}
+		switch {
+		case strings.Contains(body.String(), "EICAR"):
+			conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+		case strings.Contains(body.String(), "BROKEN"):
+			conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+		default:
+			conn.Write([]byte("stream: OK\x00"))
+		}
+	default:
+		conn.Write([]byte("UNKNOWN COMMAND\x00"))
+	}
+}
+
+// stubScanner flags files whose contents contain a marker
+type stubScanner struct {
+	marker string
+}
+
+func (s stubScanner) Scan(ctx context.Context, r io.Reader) (*ScanVerdict, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(string(data), s.marker) {
+		return &ScanVerdict{Signature: "Test.Marker"}, nil
+	}
+	return &ScanVerdict{Clean: true}, nil
+}
+
+func TestClamd(t *testing.T) {
+	ctx := context.Background()
+	clamd, err := NewClamd(fakeClamd(t), 5*time.Second)
+	require.NoError(t, err)
+
+	require.NoError(t, clamd.Ping(ctx))
+
+	verdict, err := clamd.Scan(ctx, strings.NewReader("%PDF-1.4 transcript"))
+	require.NoError(t, err)
+	assert.True(t, verdict.Clean)
+
+	// Larger than one chunk
+	verdict, err = clamd.Scan(ctx, strings.NewReader(strings.Repeat("x", 3*clamdChunkSize)+"EICAR"))
+	require.NoError(t, err)
+	assert.False(t, verdict.Clean)
+	assert.Equal(t, "Eicar-Test-Signature", verdict.Signature)
+
+	_, err = clamd.Scan(ctx, strings.NewReader("BROKEN"))
+	assert.Error(t, err)
+
+	_, err = NewClamd("", 0)
+	assert.Error(t, err)
+	unix, err := NewClamd("unix:///var/run/clamav/clamd.ctl", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "unix", unix.network)
+	assert.Equal(t, "/var/run/clamav/clamd.ctl", unix.address)
+}
+
+func TestScanAndRelease(t *testing.T) {
+	ctx := context.Background()
+	previous := backends[BackendLocal]
+	store := NewLocal(t.TempDir())
+	backends[BackendLocal] = store
+	defer func() { backends[BackendLocal] = previous }()
+
+	key := QuarantinePrefix + "applications/7/transcript_1_abcd1234.pdf"
+	require.NoError(t, store.Put(ctx, key, strings.NewReader("%PDF clean"), 10, TypePDF))
+
+	verdict, err := ScanStored(ctx, stubScanner{marker: "EICAR"}, BackendLocal, key)
+	require.NoError(t, err)
+	assert.True(t, verdict.Clean)
+
+	released, err := Release(ctx, BackendLocal, key)
+	require.NoError(t, err)
+	assert.Equal(t, "applications/7/transcript_1_abcd1234.pdf", released)
+	info, err := store.Stat(ctx, released)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), info.Size)
+
+	_, err = Release(ctx, BackendLocal, released)
+	assert.Error(t, err)
+
+	_, err = ScanStored(ctx, stubScanner{marker: "EICAR"}, BackendLocal, QuarantinePrefix+"missing.pdf")
+	assert.Equal(t, ErrNotFound, err)
+}
@@ -0,0 +1,469 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Files are encrypted at rest with envelope encryption. Each file gets its
+// own random AES-256 data key, kept in the file's header wrapped with a
+// master key from FILE_ENCRYPTION_KEYS. The contents follow in AES-GCM
+// chunks, so files are decrypted as they stream out and a changed or cut
+// short file is detected. Rotating a master key only rewrites headers.
+//
+// Layout: magic, key ID length (1 byte), key ID, wrapped data key (nonce,
+// sealed key, tag), nonce prefix, then chunks of up to encryptedChunkSize
+// bytes each followed by its tag.
+const (
+	encryptedMagic     = "\x00SCHENC1"
+	encryptedChunkSize = 64 * 1024
+	dataKeySize        = 32
+	gcmNonceSize       = 12
+	gcmTagSize         = 16
+	noncePrefixSize    = 7 // the nonce of a chunk adds a 4-byte counter and a last-chunk flag
+	wrappedKeySize     = gcmNonceSize + dataKeySize + gcmTagSize
+)
+
+// ErrDecrypt is returned when an encrypted file has been changed or cut
+// short, or was encrypted with a master key that is not configured
+var ErrDecrypt = errors.New("encrypted file cannot be decrypted")
+
+// Results of Encrypted.Rekey
+const (
+	RekeyEncrypted = "encrypted" // a plain file was encrypted
+	RekeyRewrapped = "rewrapped" // the data key was wrapped with the active master key
+	RekeyCurrent   = "current"   // already under the active master key
+)
+
+// Keyring holds the master keys that wrap data keys. The active key wraps
+// the keys of new files; the others are kept to read files written before a
+// rotation.
+type Keyring struct {
+	activeID string
+	keys     map[string][]byte
+}
+
+// ParseKeyring reads comma-separated id:key pairs, each key 32 bytes in
+// base64. The first pair is the active key.
+func ParseKeyring(spec string) (*Keyring, error) {
+	keyring := &Keyring{keys: map[string][]byte{}}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" || len(id) > 255 {
+			return nil, fmt.Errorf("encryption keys must be given as id:base64key")
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil || len(key) != dataKeySize {
+			return nil, fmt.Errorf("encryption key %q must be %d bytes in base64", id, dataKeySize)
+		}
+		if _, exists := keyring.keys[id]; exists {
+			return nil, fmt.Errorf("encryption key %q is listed more than once", id)
+		}
+		keyring.keys[id] = key
+		if keyring.activeID == "" {
+			keyring.activeID = id
+		}
+	}
+	if keyring.activeID == "" {
+		return nil, fmt.Errorf("no encryption keys given")
+	}
+	return keyring, nil
+}
+
+// ActiveID returns the ID of the key new files are encrypted with
+func (k *Keyring) ActiveID() string {
+	return k.activeID
+}
+
+// encryptionHeader is the header of an encrypted file
+type encryptionHeader struct {
+	keyID       string
+	wrappedKey  []byte
+	noncePrefix []byte
+}
+
+func (h *encryptionHeader) size() int64 {
+	return int64(len(encryptedMagic) + 1 + len(h.keyID) + wrappedKeySize + noncePrefixSize)
+}
+
+func (h *encryptionHeader) marshal() []byte {
+	buf := make([]byte, 0, h.size())
+	buf = append(buf, encryptedMagic...)
+	buf = append(buf, byte(len(h.keyID)))
+	buf = append(buf, h.keyID...)
+	buf = append(buf, h.wrappedKey...)
+	return append(buf, h.noncePrefix...)
+}
+
+// readEncryptionHeader reads the header of an encrypted file. It returns nil
+// without reading anything when the file is not encrypted.
+func readEncryptionHeader(r *bufio.Reader) (*encryptionHeader, error) {
+	magic, err := r.Peek(len(encryptedMagic))
+	if err == io.EOF || (err == nil && string(magic) != encryptedMagic) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.Discard(len(encryptedMagic))
+
+	idLen, err := r.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("%w: header is cut short", ErrDecrypt)
+	}
+	header := &encryptionHeader{
+		wrappedKey:  make([]byte, wrappedKeySize),
+		noncePrefix: make([]byte, noncePrefixSize),
+	}
+	id := make([]byte, idLen)
+	for _, field := range [][]byte{id, header.wrappedKey, header.noncePrefix} {
+		if _, err := io.ReadFull(r, field); err != nil {
+			return nil, fmt.Errorf("%w: header is cut short", ErrDecrypt)
+		}
+	}
+	header.keyID = string(id)
+	return header, nil
+}
+
+// wrap seals a data key with the active master key
+func (k *Keyring) wrap(dataKey, noncePrefix []byte) (*encryptionHeader, error) {
+	aead, err := newGCM(k.keys[k.activeID])
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcmNonceSize, wrappedKeySize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return &encryptionHeader{
+		keyID:       k.activeID,
+		wrappedKey:  aead.Seal(nonce, nonce, dataKey, []byte(encryptedMagic+k.activeID)),
+		noncePrefix: noncePrefix,
+	}, nil
+}
+
+// unwrap opens the data key of a file
+func (k *Keyring) unwrap(header *encryptionHeader) ([]byte, error) {
+	master, ok := k.keys[header.keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: master key %q is not configured", ErrDecrypt, header.keyID)
+	}
+	aead, err := newGCM(master)
+	if err != nil {
+		return nil, err
+	}
+	nonce, sealed := header.wrappedKey[:gcmNonceSize], header.wrappedKey[gcmNonceSize:]
+	dataKey, err := aead.Open(nil, nonce, sealed, []byte(encryptedMagic+header.keyID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: data key does not match master key %q", ErrDecrypt, header.keyID)
+	}
+	return dataKey, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce returns the nonce of a chunk. The last-chunk flag stops a file
+// from being cut short at a chunk boundary unnoticed.
+func chunkNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, gcmNonceSize)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], counter)
+	if last {
+		nonce[gcmNonceSize-1] = 1
+	}
+	return nonce
+}
+
+// encryptedSize is the stored size of a file of size bytes, or -1 when the
+// size is unknown. Even an empty file has one chunk.
+func encryptedSize(headerSize, size int64) int64 {
+	if size < 0 {
+		return -1
+	}
+	chunks := (size + encryptedChunkSize - 1) / encryptedChunkSize
+	if chunks == 0 {
+		chunks = 1
+	}
+	return headerSize + size + chunks*gcmTagSize
+}
+
+// decryptedSize is the size of the contents of a stored encrypted file
+func decryptedSize(headerSize, size int64) int64 {
+	body := size - headerSize
+	if size < 0 || body < gcmTagSize {
+		return -1
+	}
+	chunks := (body + encryptedChunkSize + gcmTagSize - 1) / (encryptedChunkSize + gcmTagSize)
+	return body - chunks*gcmTagSize
+}
+
+// Encrypted encrypts the files of another backend. Files stored before
+// encryption was turned on are read as they are.
+type Encrypted struct {
+	Storage
+	keys *Keyring
+}
+
+// NewEncrypted wraps a backend so files are encrypted with keys
+func NewEncrypted(store Storage, keys *Keyring) *Encrypted {
+	return &Encrypted{Storage: store, keys: keys}
+}
+
+func (s *Encrypted) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	dataKey := make([]byte, dataKeySize)
+	noncePrefix := make([]byte, noncePrefixSize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return fmt.Errorf("failed to generate data key: %w", err)
+	}
+	if _, err := rand.Read(noncePrefix); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	header, err := s.keys.wrap(dataKey, noncePrefix)
+	if err != nil {
+		return err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return err
+	}
+
+	encrypted := &encryptReader{
+		src:    bufio.NewReader(r),
+		aead:   aead,
+		prefix: noncePrefix,
+		buf:    make([]byte, encryptedChunkSize),
+		out:    header.marshal(),
+	}
+	return s.Storage.Put(ctx, key, encrypted, encryptedSize(header.size(), size), contentType)
+}
+
+// Get decrypts as the file is read. The size reported is that of the
+// decrypted contents.
+func (s *Encrypted) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	reader, info, err := s.Storage.Get(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	src := bufio.NewReader(reader)
+	header, err := readEncryptionHeader(src)
+	if err != nil {
+		reader.Close()
+		return nil, nil, err
+	}
+	if header == nil {
+		return readCloser{Reader: src, Closer: reader}, info, nil
+	}
+
+	dataKey, err := s.keys.unwrap(header)
+	if err != nil {
+		reader.Close()
+		return nil, nil, err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		reader.Close()
+		return nil, nil, err
+	}
+
+	decrypted := *info
+	decrypted.Size = decryptedSize(header.size(), info.Size)
+	return &decryptReader{
+		src:    src,
+		closer: reader,
+		aead:   aead,
+		prefix: header.noncePrefix,
+		buf:    make([]byte, encryptedChunkSize+gcmTagSize),
+	}, &decrypted, nil
+}
+
+// Stat reads the header of the file to work out the size of its contents
+func (s *Encrypted) Stat(ctx context.Context, key string) (*Object, error) {
+	reader, info, err := s.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	reader.Close()
+	return info, nil
+}
+
+// List lists the files of the wrapped backend. Sizes are those of the
+// encrypted files.
+func (s *Encrypted) List(ctx context.Context, fn ListFunc) error {
+	return List(ctx, s.Storage, fn)
+}
+
+// Rekey puts a stored file under the active master key. A file under an
+// older key keeps its data key and contents and gets a new header; a plain
+// file is encrypted. Either way the file is replaced in one write, so
+// readers see the old or the new version and both can be decrypted while
+// the old key is still configured.
+func (s *Encrypted) Rekey(ctx context.Context, key string) (string, error) {
+	reader, info, err := s.Storage.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	src := bufio.NewReader(reader)
+	header, err := readEncryptionHeader(src)
+	if err != nil {
+		return "", err
+	}
+	if header == nil {
+		if err := s.Put(ctx, key, src, info.Size, info.ContentType); err != nil {
+			return "", err
+		}
+		return RekeyEncrypted, nil
+	}
+	if header.keyID == s.keys.activeID {
+		return RekeyCurrent, nil
+	}
+
+	dataKey, err := s.keys.unwrap(header)
+	if err != nil {
+		return "", err
+	}
+	rewrapped, err := s.keys.wrap(dataKey, header.noncePrefix)
+	if err != nil {
+		return "", err
+	}
+	size := info.Size
+	if size >= 0 {
+		size += rewrapped.size() - header.size()
+	}
+	body := io.MultiReader(bytes.NewReader(rewrapped.marshal()), src)
+	if err := s.Storage.Put(ctx, key, body, size, info.ContentType); err != nil {
+		return "", err
+	}
+	return RekeyRewrapped, nil
+}
+
+// encryptReader turns plain contents into an encrypted file
+type encryptReader struct {
+	src     *bufio.Reader
+	aead    cipher.AEAD
+	prefix  []byte
+	counter uint32
+	buf     []byte
+	out     []byte // encrypted bytes not read yet
+	done    bool
+}
+
+func (r *encryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *encryptReader) next() error {
+	n, err := io.ReadFull(r.src, r.buf)
+	last := false
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		last = true
+	case err != nil:
+		return err
+	default:
+		if _, err := r.src.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+	r.out = r.aead.Seal(r.out[:0], chunkNonce(r.prefix, r.counter, last), r.buf[:n], nil)
+	r.counter++
+	r.done = last
+	return nil
+}
+
+// decryptReader reads the contents of an encrypted file
+type decryptReader struct {
+	src     *bufio.Reader
+	closer  io.Closer
+	aead    cipher.AEAD
+	prefix  []byte
+	counter uint32
+	buf     []byte
+	out     []byte // decrypted bytes not read yet
+	done    bool
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *decryptReader) next() error {
+	n, err := io.ReadFull(r.src, r.buf)
+	last := false
+	switch {
+	case err == io.EOF:
+		return fmt.Errorf("%w: file is cut short", ErrDecrypt)
+	case err == io.ErrUnexpectedEOF:
+		last = true
+	case err != nil:
+		return err
+	default:
+		if _, err := r.src.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+	plain, err := r.aead.Open(r.buf[:0], chunkNonce(r.prefix, r.counter, last), r.buf[:n], nil)
+	if err != nil {
+		return fmt.Errorf("%w: file has been changed or cut short", ErrDecrypt)
+	}
+	r.out = plain
+	r.counter++
+	r.done = last
+	return nil
+}
+
+func (r *decryptReader) Close() error {
+	return r.closer.Close()
+}
+
+// readCloser reads from one reader and closes another
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
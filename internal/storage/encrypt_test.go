@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scholarship-system/internal/models"
+)
+
+func testKey(t *testing.T) string {
+	key := make([]byte, dataKeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func readAll(t *testing.T, store Storage, key string) ([]byte, *Object) {
+	reader, info, err := store.Get(context.Background(), key)
+	require.NoError(t, err)
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return data, info
+}
+
+func TestParseKeyring(t *testing.T) {
+	k1, k2 := testKey(t), testKey(t)
+	keys, err := ParseKeyring(" k2:" + k2 + ", k1:" + k1)
+	require.NoError(t, err)
+	assert.Equal(t, "k2", keys.ActiveID())
+	assert.Len(t, keys.keys, 2)
+
+	for _, spec := range []string{"", k1, "k1:not-base64", "k1:" + base64.StdEncoding.EncodeToString([]byte("short")), "k1:" + k1 + ",k1:" + k2} {
+		_, err := ParseKeyring(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestEncryptedStorage(t *testing.T) {
+	ctx := context.Background()
+	plain := NewLocal(t.TempDir())
+	keys, err := ParseKeyring("k1:" + testKey(t))
+	require.NoError(t, err)
+	store := NewEncrypted(plain, keys)
+
+	for _, size := range []int{0, 1, encryptedChunkSize - 1, encryptedChunkSize, encryptedChunkSize + 1, 3 * encryptedChunkSize} {
+		contents := bytes.Repeat([]byte("บัตรประชาชน 1234567890123 "), size/36+1)[:size]
+		key := "documents/7/id_card.pdf"
+		require.NoError(t, store.Put(ctx, key, bytes.NewReader(contents), int64(size), "application/pdf"))
+
+		stored, storedInfo := readAll(t, plain, key)
+		assert.Equal(t, encryptedSize(int64(len(encryptedMagic)+1+2+wrappedKeySize+noncePrefixSize), int64(size)), storedInfo.Size)
+		if size > 32 {
+			assert.False(t, bytes.Contains(stored, contents[:32]), "size %d is stored in plain", size)
+		}
+
+		data, info := readAll(t, store, key)
+		assert.Equal(t, contents, data, "size %d", size)
+		assert.Equal(t, int64(size), info.Size)
+		stat, err := store.Stat(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, int64(size), stat.Size)
+	}
+
+	t.Run("files stored before encryption are read as they are", func(t *testing.T) {
+		require.NoError(t, plain.Put(ctx, "documents/7/transcript.pdf", strings.NewReader("%PDF-1.4"), 8, "application/pdf"))
+		data, info := readAll(t, store, "documents/7/transcript.pdf")
+		assert.Equal(t, "%PDF-1.4", string(data))
+		assert.Equal(t, int64(8), info.Size)
+	})
+
+	t.Run("changed files are not decrypted", func(t *testing.T) {
+		contents := bytes.Repeat([]byte("x"), 2*encryptedChunkSize+10)
+		require.NoError(t, store.Put(ctx, "documents/7/bank_book.jpg", bytes.NewReader(contents), int64(len(contents)), "image/jpeg"))
+		path := filepath.Join(plain.root, "documents", "7", "bank_book.jpg")
+		stored, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		flipped := append([]byte{}, stored...)
+		flipped[len(flipped)-5] ^= 1
+		for name, changed := range map[string][]byte{
+			"flipped byte":     flipped,
+			"last chunk cut":   stored[:len(stored)-(10+gcmTagSize)],
+			"truncated header": stored[:len(encryptedMagic)+3],
+		} {
+			require.NoError(t, os.WriteFile(path, changed, 0644))
+			reader, _, err := store.Get(ctx, "documents/7/bank_book.jpg")
+			if err == nil {
+				_, err = io.ReadAll(reader)
+				reader.Close()
+			}
+			assert.True(t, errors.Is(err, ErrDecrypt), "%s: %v", name, err)
+		}
+	})
+}
+
+func TestRekey(t *testing.T) {
+	ctx := context.Background()
+	plain := NewLocal(t.TempDir())
+	k1, k2 := testKey(t), testKey(t)
+	oldKeys, err := ParseKeyring("k1:" + k1)
+	require.NoError(t, err)
+	newKeys, err := ParseKeyring("k2:" + k2 + ",k1:" + k1)
+	require.NoError(t, err)
+	onlyNew, err := ParseKeyring("k2:" + k2)
+	require.NoError(t, err)
+
+	contents := bytes.Repeat([]byte("house registration "), 5000)
+	require.NoError(t, NewEncrypted(plain, oldKeys).Put(ctx, "documents/7/house_registration.pdf", bytes.NewReader(contents), int64(len(contents)), "application/pdf"))
+	require.NoError(t, plain.Put(ctx, "documents/7/transcript.pdf", strings.NewReader("%PDF-1.4"), 8, "application/pdf"))
+
+	// Without the old key the file cannot be read
+	_, _, err = NewEncrypted(plain, onlyNew).Get(ctx, "documents/7/house_registration.pdf")
+	assert.True(t, errors.Is(err, ErrDecrypt))
+
+	store := NewEncrypted(plain, newKeys)
+	result := Reencrypt(ctx, store, []models.StoredObject{
+		{Table: models.StoredInApplicationDocuments, ID: "1", Key: "documents/7/house_registration.pdf"},
+		{Table: models.StoredInDocumentVersions, ID: "4", Key: "documents/7/house_registration.pdf"},
+		{Table: models.StoredInApplicationDocuments, ID: "2", Key: "documents/7/transcript.pdf"},
+		{Table: models.StoredInApplicationDocuments, ID: "3", Key: "documents/7/missing.pdf"},
+	})
+	assert.Equal(t, &models.FileEncryptionResult{
+		Backend: BackendLocal, KeyID: "k2", Total: 3, Encrypted: 1, Rewrapped: 1, Missing: 1,
+		Errors: []string{"application_documents 3 (documents/7/missing.pdf): missing in local"},
+	}, result)
+
+	// Both files now only need the new key
+	rotated := NewEncrypted(plain, onlyNew)
+	data, _ := readAll(t, rotated, "documents/7/house_registration.pdf")
+	assert.Equal(t, contents, data)
+	data, _ = readAll(t, rotated, "documents/7/transcript.pdf")
+	assert.Equal(t, "%PDF-1.4", string(data))
+
+	outcome, err := rotated.Rekey(ctx, "documents/7/transcript.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, RekeyCurrent, outcome)
+}
@@ -0,0 +1,329 @@
+package storage
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Content types detected from file contents
+const (
+	TypePDF  = "application/pdf"
+	TypeJPEG = "image/jpeg"
+	TypePNG  = "image/png"
+	TypeGIF  = "image/gif"
+	TypeDOC  = "application/msword"
+	TypeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+)
+
+// maxImagePixels rejects images that would take too much memory to decode
+const maxImagePixels = 50_000_000
+
+// maxPDFInflate caps how much of a PDF's compressed streams is inflated when
+// looking for hidden entries
+const maxPDFInflate = 64 << 20
+
+// DetectType returns the content type of a file from its leading bytes, or
+// "application/octet-stream" when it is none of the accepted types
+func DetectType(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return TypeJPEG
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return TypePNG
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return TypeGIF
+	case bytes.HasPrefix(data, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}):
+		return TypeDOC
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")) && zipHas(data, "word/document.xml"):
+		return TypeDOCX
+	}
+	// Readers accept the PDF header anywhere in the first kilobyte
+	if bytes.Contains(data[:min(len(data), 1024)], []byte("%PDF-")) {
+		return TypePDF
+	}
+	return "application/octet-stream"
+}
+
+// Inspect checks a file of a detected type and returns the bytes to store.
+// PDFs are refused when encrypted or scripted, images are re-encoded so
+// EXIF and other metadata are dropped, and Word files must not carry macros.
+func Inspect(data []byte, contentType string) ([]byte, error) {
+	switch contentType {
+	case TypePDF:
+		return data, checkPDF(data)
+	case TypeJPEG, TypePNG, TypeGIF:
+		return reencodeImage(data, contentType)
+	case TypeDOCX:
+		if zipHasSuffix(data, "vbaProject.bin") {
+			return nil, &UploadError{Message: "Word documents with macros are not accepted"}
+		}
+	}
+	return data, nil
+}
+
+func checkPDF(data []byte) error {
+	tail := data[max(0, len(data)-1024):]
+	if !bytes.Contains(tail, []byte("%%EOF")) {
+		return &UploadError{Message: "File is not a complete PDF document"}
+	}
+
+	names := pdfNames(data)
+	budget := maxPDFInflate
+	for _, stream := range pdfStreams(data) {
+		if budget <= 0 {
+			break
+		}
+		inflated := inflate(stream, budget)
+		budget -= len(inflated)
+		for name := range pdfNames(inflated) {
+			names[name] = true
+		}
+	}
+
+	if names["Encrypt"] {
+		return &UploadError{Message: "Password-protected PDF files are not accepted"}
+	}
+	if names["JavaScript"] || names["JS"] {
+		return &UploadError{Message: "PDF files containing JavaScript are not accepted"}
+	}
+	return nil
+}
+
+// pdfNames collects the name objects of PDF data, decoding #xx escapes so
+// /J#61vaScript is seen as /JavaScript
+func pdfNames(data []byte) map[string]bool {
+	names := map[string]bool{}
+	for i := 0; i < len(data); i++ {
+		if data[i] != '/' {
+			continue
+		}
+		var name strings.Builder
+		j := i + 1
+		for ; j < len(data) && isPDFRegular(data[j]); j++ {
+			if data[j] == '#' && j+2 < len(data) {
+				if b, err := strconv.ParseUint(string(data[j+1:j+3]), 16, 8); err == nil {
+					name.WriteByte(byte(b))
+					j += 2
+					continue
+				}
+			}
+			name.WriteByte(data[j])
+		}
+		if name.Len() > 0 {
+			names[name.String()] = true
+		}
+		i = j - 1
+	}
+	return names
+}
+
+func isPDFRegular(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0, '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return false
+	}
+	return true
+}
+
+// pdfStreams returns the raw contents of the streams in PDF data
+func pdfStreams(data []byte) [][]byte {
+	streams := [][]byte{}
+	rest := data
+	for {
+		start := bytes.Index(rest, []byte("stream"))
+		if start < 0 {
+			return streams
+		}
+		rest = rest[start+len("stream"):]
+		// "endstream" also contains "stream"; a stream keyword is followed
+		// by an end of line
+		switch {
+		case bytes.HasPrefix(rest, []byte("\r\n")):
+			rest = rest[2:]
+		case bytes.HasPrefix(rest, []byte("\n")):
+			rest = rest[1:]
+		default:
+			continue
+		}
+		end := bytes.Index(rest, []byte("endstream"))
+		if end < 0 {
+			return streams
+		}
+		streams = append(streams, rest[:end])
+		rest = rest[end+len("endstream"):]
+	}
+}
+
+// inflate decompresses a Flate stream, or returns nil if it is not one
+func inflate(stream []byte, limit int) []byte {
+	reader, err := zlib.NewReader(bytes.NewReader(stream))
+	if err != nil {
+		return nil
+	}
+	defer reader.Close()
+	inflated, _ := io.ReadAll(io.LimitReader(reader, int64(limit)))
+	return inflated
+}
+
+func reencodeImage(data []byte, contentType string) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, &UploadError{Message: "Image file could not be read"}
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, &UploadError{Message: "Image dimensions are too large"}
+	}
+
+	var buf bytes.Buffer
+	switch contentType {
+	case TypeJPEG:
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, &UploadError{Message: "Image file could not be read"}
+		}
+		// The orientation lives in the EXIF data that is dropped, so apply it
+		img = orient(img, jpegOrientation(data))
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90})
+		if err != nil {
+			return nil, err
+		}
+	case TypePNG:
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, &UploadError{Message: "Image file could not be read"}
+		}
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+	case TypeGIF:
+		img, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, &UploadError{Message: "Image file could not be read"}
+		}
+		if err := gif.EncodeAll(&buf, img); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// jpegOrientation reads the EXIF orientation (1-8) of a JPEG, 1 if unset
+func jpegOrientation(data []byte) int {
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xDA || size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < count; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if value := int(order.Uint16(tiff[entry+8:])); value >= 1 && value <= 8 {
+				return value
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// orient turns an image upright for an EXIF orientation
+func orient(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if orientation >= 5 {
+		w, h = h, w
+	}
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = w-1-y, x
+			case 7:
+				dx, dy = w-1-y, h-1-x
+			case 8:
+				dx, dy = y, h-1-x
+			}
+			out.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return out
+}
+
+func zipHas(data []byte, name string) bool {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return false
+	}
+	for _, file := range reader.File {
+		if file.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func zipHasSuffix(data []byte, suffix string) bool {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return false
+	}
+	for _, file := range reader.File {
+		if strings.HasSuffix(strings.ToLower(file.Name), strings.ToLower(suffix)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectType(t *testing.T) {
+	assert.Equal(t, TypePDF, DetectType([]byte("%PDF-1.7\n")))
+	assert.Equal(t, TypePDF, DetectType([]byte("\xEF\xBB\xBF junk before the header %PDF-1.4")))
+	assert.Equal(t, TypeJPEG, DetectType(testJPEG(t, 4, 2)))
+	assert.Equal(t, TypePNG, DetectType(testPNG(t)))
+	assert.Equal(t, TypeGIF, DetectType([]byte("GIF89a....")))
+	assert.Equal(t, TypeDOC, DetectType([]byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1 ole")))
+	assert.Equal(t, TypeDOCX, DetectType(testZip(t, "word/document.xml")))
+	assert.Equal(t, "application/octet-stream", DetectType(testZip(t, "other.xml")))
+	assert.Equal(t, "application/octet-stream", DetectType([]byte("MZ\x90\x00")))
+	assert.Equal(t, "application/octet-stream", DetectType(nil))
+}
+
+func TestInspectPDF(t *testing.T) {
+	clean := []byte("%PDF-1.4\n1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\ntrailer << /Root 1 0 R >>\n%%EOF\n")
+	data, err := Inspect(clean, TypePDF)
+	require.NoError(t, err)
+	assert.Equal(t, clean, data)
+
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write([]byte("<< /S /JavaScript /JS (app.alert(1)) >>"))
+	zw.Close()
+
+	rejected := map[string][]byte{
+		"truncated": []byte("%PDF-1.4\n1 0 obj << >> endobj\n"),
+		"encrypted": []byte("%PDF-1.4\ntrailer << /Root 1 0 R /Encrypt 5 0 R >>\n%%EOF"),
+		"script":    []byte("%PDF-1.4\n1 0 obj << /OpenAction << /S /JavaScript /JS (app.alert(1)) >> >> endobj\n%%EOF"),
+		"escaped":   []byte("%PDF-1.4\n1 0 obj << /OpenAction << /S /J#61vaScript >> >> endobj\n%%EOF"),
+		"compressed": append(append([]byte("%PDF-1.5\n4 0 obj << /Type /ObjStm /Filter /FlateDecode >>\nstream\n"),
+			compressed.Bytes()...), []byte("\nendstream\nendobj\n%%EOF")...),
+	}
+	for name, pdf := range rejected {
+		_, err := Inspect(pdf, TypePDF)
+		var uploadErr *UploadError
+		assert.ErrorAs(t, err, &uploadErr, name)
+	}
+}
+
+func TestInspectImages(t *testing.T) {
+	// A JPEG with an EXIF segment saying it is rotated 90 degrees
+	original := testJPEG(t, 4, 2)
+	withExif := append([]byte{}, original[:2]...)
+	withExif = append(withExif, exifSegment(6)...)
+	withExif = append(withExif, original[2:]...)
+	assert.Equal(t, 6, jpegOrientation(withExif))
+
+	data, err := Inspect(withExif, TypeJPEG)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Exif")
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 2, img.Bounds().Dx())
+	assert.Equal(t, 4, img.Bounds().Dy())
+
+	data, err = Inspect(testPNG(t), TypePNG)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "tEXt")
+	_, err = png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	var uploadErr *UploadError
+	_, err = Inspect([]byte("\xFF\xD8\xFF not really a jpeg"), TypeJPEG)
+	assert.ErrorAs(t, err, &uploadErr)
+
+	// 20000x20000 logical screen, no pixels
+	huge := []byte("GIF89a")
+	huge = binary.LittleEndian.AppendUint16(huge, 20000)
+	huge = binary.LittleEndian.AppendUint16(huge, 20000)
+	huge = append(huge, 0, 0, 0, ';')
+	_, err = Inspect(huge, TypeGIF)
+	require.ErrorAs(t, err, &uploadErr)
+	assert.Equal(t, "Image dimensions are too large", uploadErr.Message)
+}
+
+func TestInspectDOCX(t *testing.T) {
+	_, err := Inspect(testZip(t, "word/document.xml"), TypeDOCX)
+	assert.NoError(t, err)
+
+	var uploadErr *UploadError
+	_, err = Inspect(testZip(t, "word/document.xml", "word/vbaProject.bin"), TypeDOCX)
+	assert.ErrorAs(t, err, &uploadErr)
+}
+
+func testJPEG(t *testing.T, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		img.Set(x, 0, color.RGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+// testPNG returns a PNG carrying a tEXt metadata chunk
+func testPNG(t *testing.T) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 3, 3))))
+	data := buf.Bytes()
+
+	text := []byte("Comment\x00GPS 13.7563 100.5018")
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(text)))
+	chunk = append(chunk, "tEXt"...)
+	chunk = append(chunk, text...)
+	chunk = binary.BigEndian.AppendUint32(chunk, pngCRC(append([]byte("tEXt"), text...)))
+
+	// After the signature and the 25-byte IHDR chunk
+	out := append([]byte{}, data[:33]...)
+	out = append(out, chunk...)
+	return append(out, data[33:]...)
+}
+
+func pngCRC(data []byte) uint32 {
+	crc := ^uint32(0)
+	for _, b := range data {
+		crc ^= uint32(b)
+		for i := 0; i < 8; i++ {
+			if crc&1 == 1 {
+				crc = crc>>1 ^ 0xEDB88320
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return ^crc
+}
+
+// exifSegment builds an APP1 segment holding only an orientation tag
+func exifSegment(orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2A\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+func testZip(t *testing.T, names ...string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		w.Write([]byte("<xml/>"))
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"scholarship-system/internal/models"
+)
+
+// maxMigrationErrors caps the errors kept in a migration result
+const maxMigrationErrors = 100
+
+// MoveFunc points a record at a new backend and key. It reports false when
+// the record changed since it was listed.
+type MoveFunc func(obj models.StoredObject, backend, key string) (bool, error)
+
+// Migrate copies each object from one backend to another and then updates
+// its record. The source file is deleted only after the record points at the
+// copy, so an interrupted run can simply be started again.
+func Migrate(ctx context.Context, from, to Storage, objects []models.StoredObject, move MoveFunc, deleteSource bool) *models.StorageMigrationResult {
+	result := &models.StorageMigrationResult{From: from.Name(), To: to.Name(), Total: len(objects)}
+	fail := func(obj models.StoredObject, err error) {
+		result.Failed++
+		if len(result.Errors) < maxMigrationErrors {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s %s (%s): %v", obj.Table, obj.ID, obj.Key, err))
+		}
+	}
+
+	for _, obj := range objects {
+		if err := ctx.Err(); err != nil {
+			fail(obj, err)
+			continue
+		}
+		key, err := CleanKey(obj.Key)
+		if err != nil {
+			fail(obj, err)
+			continue
+		}
+
+		if _, err := Copy(ctx, from, to, obj.Key, key); err != nil {
+			if err == ErrNotFound {
+				result.Missing++
+				if len(result.Errors) < maxMigrationErrors {
+					result.Errors = append(result.Errors, fmt.Sprintf("%s %s (%s): missing in %s", obj.Table, obj.ID, obj.Key, from.Name()))
+				}
+				continue
+			}
+			fail(obj, err)
+			continue
+		}
+
+		moved, err := move(obj, to.Name(), key)
+		if err != nil || !moved {
+			// Leave the record alone and drop the copy
+			if delErr := to.Delete(ctx, key); delErr != nil {
+				log.Printf("Warning: %v", delErr)
+			}
+			if err == nil {
+				err = fmt.Errorf("record changed during migration")
+			}
+			fail(obj, err)
+			continue
+		}
+		result.Moved++
+
+		if deleteSource {
+			if err := from.Delete(ctx, obj.Key); err != nil {
+				log.Printf("Warning: failed to delete %s from %s: %v", obj.Key, from.Name(), err)
+			}
+		}
+	}
+	return result
+}
+
+// Reencrypt puts each object under the active master key of an encrypted
+// backend (see Encrypted.Rekey). Objects may be listed more than once, e.g.
+// a document and its current version; each file is handled once. Running it
+// again after a failure only touches the files that were missed.
+func Reencrypt(ctx context.Context, store *Encrypted, objects []models.StoredObject) *models.FileEncryptionResult {
+	result := &models.FileEncryptionResult{Backend: store.Name(), KeyID: store.keys.ActiveID()}
+	seen := map[string]bool{}
+	for _, obj := range objects {
+		if seen[obj.Key] {
+			continue
+		}
+		seen[obj.Key] = true
+		result.Total++
+
+		err := ctx.Err()
+		outcome := ""
+		if err == nil {
+			outcome, err = store.Rekey(ctx, obj.Key)
+		}
+		switch {
+		case err == ErrNotFound:
+			result.Missing++
+			err = fmt.Errorf("missing in %s", store.Name())
+		case err != nil:
+			result.Failed++
+		case outcome == RekeyEncrypted:
+			result.Encrypted++
+		case outcome == RekeyRewrapped:
+			result.Rewrapped++
+		default:
+			result.Current++
+		}
+		if err != nil && len(result.Errors) < maxMigrationErrors {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s %s (%s): %v", obj.Table, obj.ID, obj.Key, err))
+		}
+	}
+	return result
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// ScanStored runs a stored file through a malware scanner
+func ScanStored(ctx context.Context, s Scanner, backend, key string) (*ScanVerdict, error) {
+	reader, _, err := Open(ctx, backend, key)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return s.Scan(ctx, reader)
+}
+
+// Release copies a quarantined file out of quarantine within its backend and
+// returns the new key. The quarantined copy is left for the caller to delete
+// once the record points at the new one.
+func Release(ctx context.Context, backend, key string) (string, error) {
+	if !IsQuarantined(key) {
+		return "", fmt.Errorf("%s is not in quarantine", key)
+	}
+	store, err := Backend(backend)
+	if err != nil {
+		return "", err
+	}
+	released := ReleaseKey(key)
+	if _, err := Copy(ctx, store, store, key, released); err != nil {
+		return "", err
+	}
+	return released, nil
+}
@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"scholarship-system/internal/models"
+)
+
+// OrphanPrefix is where reconciliation moves files no record points at
+const OrphanPrefix = "orphaned/"
+
+// reconcileSkipped are prefixes whose files are not tracked by file records:
+// exports of finished jobs, chunks of unfinished uploads and files already
+// moved aside
+var reconcileSkipped = []string{"exports/", PartialPrefix, OrphanPrefix}
+
+// ReconcileOptions controls a reconciliation of one backend
+type ReconcileOptions struct {
+	VerifyHashes bool          // read every file and compare it with its recorded SHA-256
+	Quarantine   bool          // move orphaned files under OrphanPrefix
+	GracePeriod  time.Duration // younger files are skipped, as uploads write the file before the record
+}
+
+// Reconcile compares the files of a backend with the records pointing at
+// them and returns the files no record points at, the records whose file is
+// gone and, with VerifyHashes, the files whose contents differ from the hash
+// on their record. Records are not changed; with Quarantine orphaned files
+// are moved aside rather than deleted, so they can be restored.
+func Reconcile(ctx context.Context, store Storage, objects []models.StoredObject, opts ReconcileOptions) ([]models.ReconcileIssue, int, error) {
+	// Listed keys are clean, so records written with "./" and the like match
+	records := map[string][]models.StoredObject{}
+	for _, obj := range objects {
+		key, err := CleanKey(obj.Key)
+		if err != nil {
+			key = obj.Key
+		}
+		records[key] = append(records[key], obj)
+	}
+
+	var issues []models.ReconcileIssue
+	seen := map[string]bool{}
+	scanned := 0
+	cutoff := time.Now().Add(-opts.GracePeriod)
+	err := List(ctx, store, func(key string, info *Object) error {
+		for _, prefix := range reconcileSkipped {
+			if strings.HasPrefix(key, prefix) {
+				return nil
+			}
+		}
+		scanned++
+		seen[key] = true
+		if len(records[key]) > 0 || info.ModTime.After(cutoff) {
+			return nil
+		}
+		issues = append(issues, models.ReconcileIssue{
+			Kind:    models.ReconcileOrphanFile,
+			Backend: store.Name(),
+			Key:     key,
+			Size:    info.Size,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, scanned, err
+	}
+
+	keys := make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return nil, scanned, err
+		}
+		if !seen[key] {
+			// Written after the listing started, or really gone
+			if _, err := store.Stat(ctx, key); err == nil {
+				seen[key] = true
+			} else if !errors.Is(err, ErrNotFound) {
+				return nil, scanned, err
+			}
+		}
+		if !seen[key] {
+			for _, obj := range records[key] {
+				issues = append(issues, recordIssue(models.ReconcileMissingFile, store, obj))
+			}
+			continue
+		}
+		if !opts.VerifyHashes || !hasRecordedHash(records[key]) {
+			continue
+		}
+		actual, err := fileHash(ctx, store, key)
+		if errors.Is(err, ErrNotFound) {
+			continue // deleted since it was listed
+		}
+		if err != nil {
+			return nil, scanned, err
+		}
+		for _, obj := range records[key] {
+			if obj.Hash != "" && !strings.EqualFold(obj.Hash, actual) {
+				issue := recordIssue(models.ReconcileHashMismatch, store, obj)
+				issue.ActualHash = actual
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	if opts.Quarantine {
+		for i := range issues {
+			if issues[i].Kind != models.ReconcileOrphanFile {
+				continue
+			}
+			if err := quarantineOrphan(ctx, store, issues[i].Key); err != nil {
+				issues[i].Error = err.Error()
+				continue
+			}
+			issues[i].Action = models.ReconcileQuarantined
+		}
+	}
+	return issues, scanned, nil
+}
+
+func recordIssue(kind string, store Storage, obj models.StoredObject) models.ReconcileIssue {
+	return models.ReconcileIssue{
+		Kind:         kind,
+		Backend:      store.Name(),
+		Key:          obj.Key,
+		Table:        obj.Table,
+		ID:           obj.ID,
+		ExpectedHash: obj.Hash,
+	}
+}
+
+func hasRecordedHash(objects []models.StoredObject) bool {
+	for _, obj := range objects {
+		if obj.Hash != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// quarantineOrphan moves a file under OrphanPrefix, keeping its key below it
+func quarantineOrphan(ctx context.Context, store Storage, key string) error {
+	if _, err := Copy(ctx, store, store, key, OrphanPrefix+key); err != nil {
+		return err
+	}
+	return store.Delete(ctx, key)
+}
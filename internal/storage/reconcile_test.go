@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scholarship-system/internal/models"
+)
+
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := NewLocal(root)
+	put := func(key, contents string, age time.Duration) {
+		require.NoError(t, store.Put(ctx, key, strings.NewReader(contents), int64(len(contents)), "application/pdf"))
+		when := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(filepath.Join(root, filepath.FromSlash(key)), when, when))
+	}
+	put("applications/7/id_card.pdf", "id card", 48*time.Hour)
+	put("applications/7/transcript.pdf", "edited", 48*time.Hour)
+	put("applications/8/old.pdf", "orphan", 48*time.Hour)
+	put("applications/9/new.pdf", "uploading", time.Minute)
+	put("exports/applications-1.csv", "csv", 48*time.Hour)
+	put(PartialPrefix+"upload-1/0", "chunk", 48*time.Hour)
+
+	objects := []models.StoredObject{
+		{Table: models.StoredInApplicationDocuments, ID: "1", Key: "./applications/7/id_card.pdf", Hash: sha256Hex("id card")},
+		{Table: models.StoredInApplicationDocuments, ID: "2", Key: "applications/7/transcript.pdf", Hash: sha256Hex("transcript")},
+		{Table: models.StoredInDocumentVersions, ID: "v1", Key: "applications/7/transcript.pdf"},
+		{Table: models.StoredInApplicationDocuments, ID: "3", Key: "applications/9/gone.pdf", Hash: sha256Hex("gone")},
+	}
+	opts := ReconcileOptions{VerifyHashes: true, GracePeriod: time.Hour}
+
+	issues, scanned, err := Reconcile(ctx, store, objects, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 4, scanned)
+	require.Len(t, issues, 3)
+	assert.Equal(t, models.ReconcileOrphanFile, issues[0].Kind)
+	assert.Equal(t, "applications/8/old.pdf", issues[0].Key)
+	assert.Equal(t, int64(6), issues[0].Size)
+	assert.Equal(t, models.ReconcileHashMismatch, issues[1].Kind)
+	assert.Equal(t, "2", issues[1].ID)
+	assert.Equal(t, sha256Hex("edited"), issues[1].ActualHash)
+	assert.Equal(t, models.ReconcileMissingFile, issues[2].Kind)
+	assert.Equal(t, "3", issues[2].ID)
+	for _, issue := range issues {
+		assert.Empty(t, issue.Action)
+	}
+
+	// Without hashes the contents are not read
+	opts.VerifyHashes = false
+	issues, _, err = Reconcile(ctx, store, objects, opts)
+	require.NoError(t, err)
+	assert.Len(t, issues, 2)
+
+	// Quarantine moves orphans aside, where later runs leave them
+	opts.Quarantine = true
+	issues, _, err = Reconcile(ctx, store, objects, opts)
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, models.ReconcileQuarantined, issues[0].Action)
+	assert.Empty(t, issues[1].Action)
+	_, err = store.Stat(ctx, "applications/8/old.pdf")
+	assert.Equal(t, ErrNotFound, err)
+	data, _ := readAll(t, store, OrphanPrefix+"applications/8/old.pdf")
+	assert.Equal(t, "orphan", string(data))
+
+	issues, scanned, err = Reconcile(ctx, store, objects, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 3, scanned)
+	require.Len(t, issues, 1)
+	assert.Equal(t, models.ReconcileMissingFile, issues[0].Kind)
+}
+
+func TestReconcileEncrypted(t *testing.T) {
+	ctx := context.Background()
+	keys, err := ParseKeyring("k1:" + testKey(t))
+	require.NoError(t, err)
+	store := NewEncrypted(NewLocal(t.TempDir()), keys)
+	require.NoError(t, store.Put(ctx, "applications/7/id_card.pdf", strings.NewReader("id card"), 7, "application/pdf"))
+
+	// Hashes are of the plaintext, as recorded at upload
+	objects := []models.StoredObject{{Table: models.StoredInApplicationDocuments, ID: "1", Key: "applications/7/id_card.pdf", Hash: sha256Hex("id card")}}
+	issues, scanned, err := Reconcile(ctx, store, objects, ReconcileOptions{VerifyHashes: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, scanned)
+	assert.Empty(t, issues)
+}
+
+func TestLocalListMissingRoot(t *testing.T) {
+	store := NewLocal(filepath.Join(t.TempDir(), "uploads"))
+	err := List(context.Background(), store, func(key string, info *Object) error {
+		t.Errorf("unexpected file %s", key)
+		return nil
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+)
+
+// PartialPrefix is where the chunks of unfinished resumable uploads are kept
+const PartialPrefix = "partial/"
+
+// ChunkKey returns a new key for the chunk of an upload starting at offset.
+// Each attempt gets its own key so a retried chunk never overwrites one that
+// was already recorded.
+func ChunkKey(uploadID string, offset int64) string {
+	return fmt.Sprintf("%s%s/%020d_%s", PartialPrefix, uploadID, offset, uuid.NewString()[:8])
+}
+
+// OpenChunks reads the chunks of an upload in order as one file. Each chunk
+// is opened only when the previous one has been read.
+func OpenChunks(ctx context.Context, backend string, keys []string) io.ReadCloser {
+	return &chunksReader{ctx: ctx, backend: backend, keys: keys}
+}
+
+type chunksReader struct {
+	ctx     context.Context
+	backend string
+	keys    []string
+	current io.ReadCloser
+}
+
+func (r *chunksReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.keys) == 0 {
+				return 0, io.EOF
+			}
+			part, _, err := Open(r.ctx, r.backend, r.keys[0])
+			if err != nil {
+				return 0, fmt.Errorf("failed to open upload chunk %s: %w", r.keys[0], err)
+			}
+			r.current = part
+			r.keys = r.keys[1:]
+		}
+
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (r *chunksReader) Close() error {
+	if r.current != nil {
+		return r.current.Close()
+	}
+	return nil
+}
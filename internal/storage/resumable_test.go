@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenChunks(t *testing.T) {
+	ctx := context.Background()
+	local := NewLocal(t.TempDir())
+	previousBackends, previousActive := backends, active
+	backends = map[string]Storage{BackendLocal: local}
+	active = local
+	defer func() { backends, active = previousBackends, previousActive }()
+
+	content := "%PDF-1.4 house registration\n%%EOF\n"
+	keys := []string{}
+	for offset := 0; offset < len(content); offset += 10 {
+		chunk := content[offset:min(offset+10, len(content))]
+		key := ChunkKey("upload-1", int64(offset))
+		require.True(t, strings.HasPrefix(key, PartialPrefix+"upload-1/"))
+		require.NoError(t, local.Put(ctx, key, strings.NewReader(chunk), int64(len(chunk)), ""))
+		keys = append(keys, key)
+	}
+	assert.NotEqual(t, ChunkKey("upload-1", 0), ChunkKey("upload-1", 0))
+
+	reader := OpenChunks(ctx, BackendLocal, keys)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	assert.Equal(t, content, string(data))
+
+	// The assembled file goes through the same checks as a single upload
+	policy := UploadPolicy{MaxSize: 1024, AllowedTypes: []string{TypePDF}, TypeNames: "PDF", Quarantine: true}
+	stored, err := SaveUploadFrom(ctx, OpenChunks(ctx, BackendLocal, keys), "house.pdf", "applications/7", "house_registration", policy)
+	require.NoError(t, err)
+	assert.True(t, IsQuarantined(stored.Key))
+	assert.Equal(t, int64(len(content)), stored.Size)
+
+	_, err = io.ReadAll(OpenChunks(ctx, BackendLocal, append(keys, PartialPrefix+"upload-1/missing")))
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestS3Storage runs against a real S3-compatible service. Start MinIO with
+// `docker compose up -d minio` and set STORAGE_TEST_S3_ENDPOINT, e.g.
+// http://localhost:9000, to run it.
+func TestS3Storage(t *testing.T) {
+	endpoint := os.Getenv("STORAGE_TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("STORAGE_TEST_S3_ENDPOINT not set")
+	}
+	store, err := NewS3(S3Options{
+		Endpoint:     endpoint,
+		Bucket:       envOr("STORAGE_TEST_S3_BUCKET", "scholarship-test"),
+		AccessKey:    envOr("STORAGE_TEST_S3_ACCESS_KEY", "minioadmin"),
+		SecretKey:    envOr("STORAGE_TEST_S3_SECRET_KEY", "minioadmin"),
+		UsePathStyle: true,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	resp, err := store.do(ctx, http.MethodPut, "", nil, 0, nil)
+	if err == nil {
+		resp.Body.Close()
+	} else {
+		require.Contains(t, err.Error(), "BucketAlreadyOwnedByYou")
+	}
+
+	key := "applications/7/ใบแสดงผล การเรียน+1.pdf"
+	require.NoError(t, store.Put(ctx, key, strings.NewReader("%PDF-1.4 transcript"), 19, "application/pdf"))
+
+	info, err := store.Stat(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, int64(19), info.Size)
+	assert.Equal(t, "application/pdf", info.ContentType)
+
+	reader, _, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF-1.4 transcript", string(data))
+
+	require.NoError(t, store.Put(ctx, "applications/7/empty.txt", strings.NewReader(""), 0, "text/plain"))
+
+	listed := map[string]int64{}
+	require.NoError(t, List(ctx, store, func(key string, info *Object) error {
+		listed[key] = info.Size
+		return nil
+	}))
+	assert.Equal(t, int64(19), listed[key])
+	assert.Contains(t, listed, "applications/7/empty.txt")
+
+	local := NewLocal(t.TempDir())
+	size, err := Copy(ctx, store, local, key, "applications/7/transcript.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, int64(19), size)
+
+	require.NoError(t, store.Delete(ctx, key))
+	require.NoError(t, store.Delete(ctx, "applications/7/empty.txt"))
+	_, err = store.Stat(ctx, key)
+	assert.Equal(t, ErrNotFound, err)
+	_, _, err = store.Get(ctx, key)
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
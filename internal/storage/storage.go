@@ -0,0 +1,259 @@
+// Package storage keeps uploaded files on local disk or in an S3-compatible
+// bucket. Records store the object key and the name of the backend holding
+// it, so files can be moved between backends without breaking old rows.
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/models"
+)
+
+// Backend names, stored with each file record
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// ErrNotFound is returned when a key does not exist in a backend
+var ErrNotFound = errors.New("file not found in storage")
+
+// Object describes a stored file
+type Object struct {
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Storage is a place uploaded files are kept. Keys are slash-separated paths
+// relative to the root of the backend.
+type Storage interface {
+	Name() string
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+	Stat(ctx context.Context, key string) (*Object, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// ListFunc is called with each file found by List
+type ListFunc func(key string, info *Object) error
+
+// Lister is a backend that can enumerate its files
+type Lister interface {
+	List(ctx context.Context, fn ListFunc) error
+}
+
+// List calls fn for every file in a backend, in no particular order, and
+// stops at the first error fn returns
+func List(ctx context.Context, store Storage, fn ListFunc) error {
+	lister, ok := store.(Lister)
+	if !ok {
+		return fmt.Errorf("storage backend %q cannot list its files", store.Name())
+	}
+	return lister.List(ctx, fn)
+}
+
+var (
+	backends = map[string]Storage{}
+	active   Storage
+)
+
+// Setup opens the configured backends and the malware scanner, and fails when
+// there is no scanner unless scanning was turned off. New uploads go
+// to cfg.StorageBackend; the local backend is always available so files
+// written before the switch can still be read.
+func Setup(cfg *config.Config) error {
+	for _, name := range []string{BackendLocal, BackendS3} {
+		if name == BackendS3 && cfg.S3Bucket == "" {
+			continue
+		}
+		store, err := New(cfg, name)
+		if err != nil {
+			return err
+		}
+		backends[name] = store
+	}
+
+	store, ok := backends[cfg.StorageBackend]
+	if !ok {
+		return fmt.Errorf("storage backend %q is not configured", cfg.StorageBackend)
+	}
+	active = store
+	log.Printf("File storage: %s", store.Name())
+	if encrypted, ok := store.(*Encrypted); ok {
+		log.Printf("File encryption: master key %s", encrypted.keys.ActiveID())
+	} else {
+		log.Printf("Warning: FILE_ENCRYPTION_KEYS is not set, uploaded files are stored unencrypted")
+	}
+
+	if cfg.ClamdAddress != "" {
+		clamd, err := NewClamd(cfg.ClamdAddress, time.Duration(cfg.ClamdTimeoutSeconds)*time.Second)
+		if err != nil {
+			return err
+		}
+		SetScanner(clamd)
+		log.Printf("Upload scanning: clamd at %s", cfg.ClamdAddress)
+	} else if cfg.DocumentScanRequired {
+		return fmt.Errorf("CLAMD_ADDRESS is not set; set DOCUMENT_SCAN_REQUIRED=false to release uploads without a malware scan")
+	} else {
+		SetScanner(Unscanned{})
+		log.Printf("Warning: CLAMD_ADDRESS is not set and DOCUMENT_SCAN_REQUIRED=false, uploaded documents are released unscanned")
+	}
+	return nil
+}
+
+// New opens one backend by name, encrypting its files when
+// FILE_ENCRYPTION_KEYS is set
+func New(cfg *config.Config, name string) (Storage, error) {
+	store, err := open(cfg, name)
+	if err != nil || cfg.FileEncryptionKeys == "" {
+		return store, err
+	}
+	keys, err := ParseKeyring(cfg.FileEncryptionKeys)
+	if err != nil {
+		return nil, err
+	}
+	return NewEncrypted(store, keys), nil
+}
+
+func open(cfg *config.Config, name string) (Storage, error) {
+	switch name {
+	case BackendLocal:
+		return NewLocal(cfg.UploadPath), nil
+	case BackendS3:
+		return NewS3(S3Options{
+			Endpoint:     cfg.S3Endpoint,
+			Region:       cfg.S3Region,
+			Bucket:       cfg.S3Bucket,
+			AccessKey:    cfg.S3AccessKey,
+			SecretKey:    cfg.S3SecretKey,
+			UsePathStyle: cfg.S3UsePathStyle,
+		})
+	}
+	return nil, fmt.Errorf("unknown storage backend %q", name)
+}
+
+// Default returns the backend new files are written to
+func Default() Storage {
+	return active
+}
+
+// Backend returns a backend by the name stored on a record. Rows written
+// before backends were recorded have no name and live on local disk.
+func Backend(name string) (Storage, error) {
+	if name == "" {
+		name = BackendLocal
+	}
+	store, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("storage backend %q is not configured", name)
+	}
+	return store, nil
+}
+
+// Backends returns the configured backends, local first
+func Backends() []Storage {
+	stores := []Storage{}
+	for _, name := range []string{BackendLocal, BackendS3} {
+		if store, ok := backends[name]; ok {
+			stores = append(stores, store)
+		}
+	}
+	return stores
+}
+
+// Open reads a file from the backend named on its record
+func Open(ctx context.Context, backend, key string) (io.ReadCloser, *Object, error) {
+	store, err := Backend(backend)
+	if err != nil {
+		return nil, nil, err
+	}
+	return store.Get(ctx, key)
+}
+
+// Remove deletes a file from the backend named on its record
+func Remove(ctx context.Context, backend, key string) error {
+	store, err := Backend(backend)
+	if err != nil {
+		return err
+	}
+	return store.Delete(ctx, key)
+}
+
+// Hash returns the hex SHA-256 of a stored file, or "" when it cannot be read
+func Hash(ctx context.Context, backend, key string) string {
+	if key == "" {
+		return ""
+	}
+	store, err := Backend(backend)
+	if err != nil {
+		return ""
+	}
+	sum, err := fileHash(ctx, store, key)
+	if err != nil {
+		return ""
+	}
+	return sum
+}
+
+// fileHash returns the hex SHA-256 of the contents of a stored file
+func fileHash(ctx context.Context, store Storage, key string) (string, error) {
+	reader, _, err := store.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, reader); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// HashDocument returns the hex SHA-256 of an application document's file
+func HashDocument(doc models.ApplicationDocument) string {
+	return Hash(context.Background(), doc.StorageBackend, doc.FilePath)
+}
+
+// Copy copies a file from one backend to another and checks the size of the
+// copy
+func Copy(ctx context.Context, from Storage, to Storage, fromKey, toKey string) (int64, error) {
+	reader, info, err := from.Get(ctx, fromKey)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	if err := to.Put(ctx, toKey, reader, info.Size, info.ContentType); err != nil {
+		return 0, err
+	}
+	copied, err := to.Stat(ctx, toKey)
+	if err != nil {
+		return 0, err
+	}
+	if copied.Size != info.Size {
+		return 0, fmt.Errorf("copy of %s has %d bytes, expected %d", fromKey, copied.Size, info.Size)
+	}
+	return copied.Size, nil
+}
+
+// CleanKey normalises a key and rejects keys that leave the storage root
+func CleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(strings.ReplaceAll(key, `\`, "/"), "./")
+	cleaned := path.Clean("/" + key)[1:]
+	if cleaned == "" || cleaned != strings.TrimSuffix(key, "/") {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scholarship-system/internal/config"
+	"scholarship-system/internal/models"
+)
+
+func TestCleanKey(t *testing.T) {
+	for key, want := range map[string]string{
+		"applications/7/id_card.pdf":             "applications/7/id_card.pdf",
+		"./documents/7/transcript.pdf":           "documents/7/transcript.pdf",
+		`exports\applications-1.csv`:             "exports/applications-1.csv",
+		"avatars/0f8fad5b/avatar_1700000000.png": "avatars/0f8fad5b/avatar_1700000000.png",
+	} {
+		got, err := CleanKey(key)
+		require.NoError(t, err, key)
+		assert.Equal(t, want, got)
+	}
+
+	for _, key := range []string{"", "../etc/passwd", "applications/../../secret", "/etc/passwd", "a//b"} {
+		_, err := CleanKey(key)
+		assert.Error(t, err, key)
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocal(t.TempDir())
+
+	require.NoError(t, store.Put(ctx, "applications/7/transcript.pdf", strings.NewReader("%PDF-1.4"), 8, "application/pdf"))
+
+	info, err := store.Stat(ctx, "applications/7/transcript.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), info.Size)
+	assert.Equal(t, "application/pdf", info.ContentType)
+
+	reader, _, err := store.Get(ctx, "applications/7/transcript.pdf")
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF-1.4", string(data))
+
+	// No temporary files are left next to the stored file
+	entries, err := os.ReadDir(filepath.Join(store.root, "applications", "7"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	require.NoError(t, store.Delete(ctx, "applications/7/transcript.pdf"))
+	require.NoError(t, store.Delete(ctx, "applications/7/transcript.pdf"))
+	_, err = store.Stat(ctx, "applications/7/transcript.pdf")
+	assert.Equal(t, ErrNotFound, err)
+	_, _, err = store.Get(ctx, "applications/7/transcript.pdf")
+	assert.Equal(t, ErrNotFound, err)
+
+	assert.Error(t, store.Put(ctx, "../outside.txt", strings.NewReader("x"), 1, ""))
+}
+
+func TestCopy(t *testing.T) {
+	ctx := context.Background()
+	from, to := NewLocal(t.TempDir()), NewLocal(t.TempDir())
+	require.NoError(t, from.Put(ctx, "documents/7/id_card.jpg", strings.NewReader("jpeg data"), 9, "image/jpeg"))
+
+	size, err := Copy(ctx, from, to, "documents/7/id_card.jpg", "applications/7/id_card.jpg")
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), size)
+
+	_, err = to.Stat(ctx, "applications/7/id_card.jpg")
+	assert.NoError(t, err)
+	_, err = Copy(ctx, from, to, "documents/7/missing.jpg", "applications/7/missing.jpg")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestSetupRequiresScanner(t *testing.T) {
+	previousActive, previousScanner := active, scanner
+	defer func() { active, scanner = previousActive, previousScanner }()
+	scanner = nil
+
+	cfg := &config.Config{StorageBackend: BackendLocal, UploadPath: t.TempDir(), DocumentScanRequired: true}
+	err := Setup(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "DOCUMENT_SCAN_REQUIRED=false")
+	assert.Nil(t, DefaultScanner())
+
+	cfg.DocumentScanRequired = false
+	require.NoError(t, Setup(cfg))
+	verdict, err := DefaultScanner().Scan(context.Background(), strings.NewReader("anything"))
+	require.NoError(t, err)
+	assert.True(t, verdict.Clean)
+}
+
+func TestSaveUpload(t *testing.T) {
+	ctx := context.Background()
+	previous := active
+	active = NewLocal(t.TempDir())
+	defer func() { active = previous }()
+
+	policy := UploadPolicy{MaxSize: 1024, AllowedTypes: []string{"application/pdf"}, TypeNames: "PDF"}
+
+	stored, err := SaveUpload(ctx, formFile(t, "ใบแสดงผลการเรียน.PDF", "application/pdf", "%PDF-1.4 transcript\n%%EOF\n"), "applications/7", "transcript", policy)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(stored.Key, "applications/7/transcript_"))
+	assert.True(t, strings.HasSuffix(stored.Key, ".pdf"))
+	assert.Equal(t, BackendLocal, stored.Backend)
+	assert.Equal(t, "ใบแสดงผลการเรียน.PDF", stored.OriginalName)
+	assert.Equal(t, TypePDF, stored.ContentType)
+	assert.Equal(t, int64(26), stored.Size)
+	assert.Equal(t, "a0aec1732ea491fdc83b1ed7316e7e0935e90f41e55f57bfeb87fb64518edec0", stored.SHA256)
+	info, err := active.Stat(ctx, stored.Key)
+	require.NoError(t, err)
+	assert.Equal(t, int64(26), info.Size)
+
+	// The type comes from the contents, not the client's header or name
+	stored, err = SaveUpload(ctx, formFile(t, "scan.png", "image/png", "%PDF-1.4 transcript\n%%EOF\n"), "applications/7", "transcript", policy)
+	require.NoError(t, err)
+	assert.Equal(t, TypePDF, stored.ContentType)
+	assert.True(t, strings.HasSuffix(stored.Key, ".pdf"))
+
+	quarantined := policy
+	quarantined.Quarantine = true
+	stored, err = SaveUpload(ctx, formFile(t, "transcript.pdf", "application/pdf", "%PDF-1.4 transcript\n%%EOF\n"), "applications/7", "transcript", quarantined)
+	require.NoError(t, err)
+	assert.True(t, IsQuarantined(stored.Key))
+	assert.True(t, strings.HasPrefix(ReleaseKey(stored.Key), "applications/7/transcript_"))
+
+	_, err = SaveUpload(ctx, formFile(t, "photo.png", "image/png", "png"), "applications/7", "transcript", policy)
+	var uploadErr *UploadError
+	require.ErrorAs(t, err, &uploadErr)
+	assert.Equal(t, "Invalid file type. Allowed: PDF", uploadErr.Message)
+
+	_, err = SaveUpload(ctx, formFile(t, "transcript.pdf", "application/pdf", "MZ\x90\x00 not a pdf"), "applications/7", "transcript", policy)
+	require.ErrorAs(t, err, &uploadErr)
+	assert.Equal(t, "Invalid file type. Allowed: PDF", uploadErr.Message)
+
+	_, err = SaveUpload(ctx, formFile(t, "big.pdf", "application/pdf", strings.Repeat("x", 2048)), "applications/7", "transcript", policy)
+	require.ErrorAs(t, err, &uploadErr)
+
+	// Validate applies the same checks without storing
+	assert.NoError(t, policy.Validate([]byte("%PDF-1.4 transcript\n%%EOF\n")))
+	require.ErrorAs(t, policy.Validate([]byte("png")), &uploadErr)
+	assert.Equal(t, "Invalid file type. Allowed: PDF", uploadErr.Message)
+	require.ErrorAs(t, policy.Validate([]byte(strings.Repeat("x", 2048))), &uploadErr)
+}
+
+func TestFileExtension(t *testing.T) {
+	assert.Equal(t, ".pdf", fileExtension("Transcript.PDF"))
+	assert.Equal(t, "", fileExtension("noext"))
+	assert.Equal(t, "", fileExtension("evil.p/df"))
+	assert.Equal(t, "", fileExtension("name.ไทย"))
+}
+
+// formFile builds the multipart file header a handler gets from Fiber
+func formFile(t *testing.T, name, contentType, content string) *multipart.FileHeader {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="file"; filename="`+name+`"`)
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	return form.File["file"][0]
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	from, to := NewLocal(t.TempDir()), NewLocal(t.TempDir())
+	require.NoError(t, from.Put(ctx, "documents/7/id_card.jpg", strings.NewReader("jpeg"), 4, "image/jpeg"))
+	require.NoError(t, from.Put(ctx, "applications/8/transcript.pdf", strings.NewReader("%PDF"), 4, "application/pdf"))
+
+	objects := []models.StoredObject{
+		{Table: models.StoredInApplicationDocuments, ID: "1", Key: "./documents/7/id_card.jpg", Backend: BackendLocal},
+		{Table: models.StoredInApplicationDocuments, ID: "2", Key: "applications/8/transcript.pdf", Backend: BackendLocal},
+		{Table: models.StoredInApplicationDocuments, ID: "3", Key: "applications/9/missing.pdf", Backend: BackendLocal},
+		{Table: models.StoredInFileStorage, ID: "4", Key: "/etc/passwd", Backend: BackendLocal},
+	}
+	moves := map[string]string{}
+	move := func(obj models.StoredObject, backend, key string) (bool, error) {
+		if obj.ID == "2" {
+			return false, nil
+		}
+		moves[obj.ID] = key
+		return true, nil
+	}
+
+	result := Migrate(ctx, from, to, objects, move, true)
+	assert.Equal(t, 4, result.Total)
+	assert.Equal(t, 1, result.Moved)
+	assert.Equal(t, 1, result.Missing)
+	assert.Equal(t, 2, result.Failed)
+	assert.Len(t, result.Errors, 3)
+	assert.Equal(t, map[string]string{"1": "documents/7/id_card.jpg"}, moves)
+
+	// The moved file left the source; the unmoved copy was dropped
+	_, err := to.Stat(ctx, "documents/7/id_card.jpg")
+	assert.NoError(t, err)
+	_, err = from.Stat(ctx, "documents/7/id_card.jpg")
+	assert.Equal(t, ErrNotFound, err)
+	_, err = to.Stat(ctx, "applications/8/transcript.pdf")
+	assert.Equal(t, ErrNotFound, err)
+	_, err = from.Stat(ctx, "applications/8/transcript.pdf")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuarantinePrefix is where uploads waiting for a malware scan are kept
+const QuarantinePrefix = "quarantine/"
+
+// maxUploadSize bounds uploads read into memory when a policy sets no limit
+const maxUploadSize = 100 << 20
+
+// UploadPolicy limits the size and content types of an upload
+type UploadPolicy struct {
+	MaxSize      int64
+	AllowedTypes []string
+	TypeNames    string // shown to the user, e.g. "PDF, JPEG, PNG"
+	Quarantine   bool   // store under QuarantinePrefix until scanned
+}
+
+// UploadError is an upload the policy rejects. Its message can be returned
+// to the client as is.
+type UploadError struct {
+	Message string
+}
+
+func (e *UploadError) Error() string {
+	return e.Message
+}
+
+// StoredFile is an upload saved to storage
+type StoredFile struct {
+	Key          string
+	Backend      string
+	OriginalName string
+	ContentType  string
+	Size         int64
+	SHA256       string
+}
+
+// SaveUpload checks a multipart file against the policy and writes it to the
+// default backend under dir, with a generated name starting with prefix.
+// The type is detected from the contents, not the client's header, and the
+// file is inspected (see Inspect) so what is stored may differ from what was
+// sent. Every handler that accepts files goes through here.
+func SaveUpload(ctx context.Context, file *multipart.FileHeader, dir, prefix string, policy UploadPolicy) (*StoredFile, error) {
+	if err := policy.Check(file); err != nil {
+		return nil, err
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	defer src.Close()
+
+	return SaveUploadFrom(ctx, src, file.Filename, dir, prefix, policy)
+}
+
+// SaveUploadFrom is SaveUpload for a file that did not arrive as a single
+// multipart part, such as the assembled chunks of a resumable upload
+func SaveUploadFrom(ctx context.Context, src io.Reader, filename, dir, prefix string, policy UploadPolicy) (*StoredFile, error) {
+	store := Default()
+	if store == nil {
+		return nil, fmt.Errorf("file storage is not set up")
+	}
+
+	limit := policy.MaxSize
+	if limit <= 0 {
+		limit = maxUploadSize
+	}
+	data, err := io.ReadAll(io.LimitReader(src, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, sizeError(limit)
+	}
+
+	data, detected, err := policy.inspect(data)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := typeExtensions[detected]
+	if ext == "" {
+		ext = fileExtension(filename)
+	}
+	key := path.Join(dir, fmt.Sprintf("%s_%d_%s%s", prefix, time.Now().Unix(), uuid.NewString()[:8], ext))
+	if policy.Quarantine {
+		key = QuarantinePrefix + key
+	}
+
+	stored := &StoredFile{
+		Key:          key,
+		Backend:      store.Name(),
+		OriginalName: filepath.Base(filename),
+		ContentType:  detected,
+		Size:         int64(len(data)),
+	}
+
+	sum := sha256.Sum256(data)
+	if err := store.Put(ctx, stored.Key, bytes.NewReader(data), stored.Size, stored.ContentType); err != nil {
+		return nil, err
+	}
+	stored.SHA256 = hex.EncodeToString(sum[:])
+	return stored, nil
+}
+
+// Validate checks file contents against the policy the way SaveUpload does,
+// without storing anything
+func (p UploadPolicy) Validate(data []byte) error {
+	if p.MaxSize > 0 && int64(len(data)) > p.MaxSize {
+		return sizeError(p.MaxSize)
+	}
+	_, _, err := p.inspect(data)
+	return err
+}
+
+// inspect detects the type of file contents, checks it is allowed and
+// returns the bytes to store (see Inspect)
+func (p UploadPolicy) inspect(data []byte) ([]byte, string, error) {
+	detected := DetectType(data)
+	if !p.allows(detected) {
+		return nil, "", &UploadError{Message: "Invalid file type. Allowed: " + p.TypeNames}
+	}
+	data, err := Inspect(data, detected)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, detected, nil
+}
+
+// ReleaseKey returns where a quarantined file is kept once it passes its scan
+func ReleaseKey(key string) string {
+	return strings.TrimPrefix(key, QuarantinePrefix)
+}
+
+// IsQuarantined reports whether a key is in quarantine
+func IsQuarantined(key string) bool {
+	return strings.HasPrefix(key, QuarantinePrefix)
+}
+
+// Check returns an *UploadError when a file is larger than the policy allows.
+// Types are checked against the contents once the file is read.
+func (p UploadPolicy) Check(file *multipart.FileHeader) error {
+	if p.MaxSize > 0 && file.Size > p.MaxSize {
+		return sizeError(p.MaxSize)
+	}
+	return nil
+}
+
+func sizeError(limit int64) error {
+	return &UploadError{Message: fmt.Sprintf("File size exceeds %dMB limit", limit/(1024*1024))}
+}
+
+func (p UploadPolicy) allows(contentType string) bool {
+	if len(p.AllowedTypes) == 0 {
+		return true
+	}
+	for _, allowed := range p.AllowedTypes {
+		if contentType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// typeExtensions names stored files after their detected type
+var typeExtensions = map[string]string{
+	TypePDF:  ".pdf",
+	TypeJPEG: ".jpg",
+	TypePNG:  ".png",
+	TypeGIF:  ".gif",
+	TypeDOC:  ".doc",
+	TypeDOCX: ".docx",
+}
+
+// fileExtension keeps the extension of a client file name if it is short
+// and plain, so it cannot smuggle path characters into the key
+func fileExtension(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if len(ext) < 2 || len(ext) > 10 {
+		return ""
+	}
+	for _, r := range ext[1:] {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return ""
+		}
+	}
+	return ext
+}
@@ -23,8 +23,10 @@ import (
 
 	"scholarship-system/internal/config"
 	"scholarship-system/internal/database"
+	"scholarship-system/internal/jobs"
 	"scholarship-system/internal/middleware"
 	"scholarship-system/internal/router"
+	"scholarship-system/internal/storage"
 )
 
 func main() {
@@ -36,8 +38,17 @@ func main() {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
+	// Open file storage
+	if err := storage.Setup(cfg); err != nil {
+		log.Fatal("Failed to set up file storage:", err)
+	}
+
+	// Start background jobs
+	jobs.NewWorker(cfg).Start()
+
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
+		BodyLimit: int(cfg.MaxRequestBodySize),
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			code := fiber.StatusInternalServerError
 			if e, ok := err.(*fiber.Error); ok {
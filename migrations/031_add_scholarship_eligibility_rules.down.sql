@@ -0,0 +1,3 @@
+-- Migration 031 Down
+
+ALTER TABLE scholarships DROP COLUMN IF EXISTS eligibility_rules;
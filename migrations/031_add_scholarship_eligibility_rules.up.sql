@@ -0,0 +1,14 @@
+-- Migration 031: Structured eligibility rules per scholarship
+
+-- เกณฑ์คุณสมบัติแบบมีโครงสร้าง (ใช้ตรวจสอบสิทธิ์อัตโนมัติ)
+-- eligibility_criteria เดิมยังคงเก็บเป็นข้อความอธิบายสำหรับแสดงผล
+ALTER TABLE scholarships ADD COLUMN IF NOT EXISTS eligibility_rules JSONB;
+
+-- ย้ายเกณฑ์ที่เคยบันทึกเป็น JSON object ใน eligibility_criteria มาไว้ในคอลัมน์ใหม่
+UPDATE scholarships
+SET eligibility_rules = eligibility_criteria
+WHERE eligibility_rules IS NULL
+  AND eligibility_criteria IS NOT NULL
+  AND jsonb_typeof(eligibility_criteria) = 'object';
+
+COMMENT ON COLUMN scholarships.eligibility_rules IS 'Structured eligibility rules: min_gpa, min/max_year_level, allowed/excluded_faculties, max_family_income, allowed_student_statuses, prior award exclusions';
@@ -0,0 +1,5 @@
+-- Migration 032 Down
+
+DROP TRIGGER IF EXISTS update_application_revision_requests_updated_at ON application_revision_requests;
+DROP TABLE IF EXISTS application_revision_items;
+DROP TABLE IF EXISTS application_revision_requests;
@@ -0,0 +1,55 @@
+-- Migration 032: Send-back-for-revision loop
+-- เจ้าหน้าที่ส่งใบสมัครกลับให้นักศึกษาแก้ไขเฉพาะส่วน/เอกสารที่ระบุ
+
+CREATE TABLE IF NOT EXISTS application_revision_requests (
+    revision_id SERIAL PRIMARY KEY,
+    application_id INTEGER NOT NULL REFERENCES scholarship_applications(application_id) ON DELETE CASCADE,
+    requested_by UUID NOT NULL REFERENCES users(user_id),
+
+    comments TEXT,                              -- ความเห็นโดยรวมจากเจ้าหน้าที่
+    resubmission_deadline TIMESTAMP NOT NULL,   -- กำหนดส่งกลับ
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (
+        status IN ('open', 'resubmitted', 'expired', 'cancelled')
+    ),
+    previous_status VARCHAR(50),                -- สถานะใบสมัครก่อนส่งกลับ
+
+    -- ข้อมูล ณ เวลาที่ส่งกลับ ใช้เทียบความเปลี่ยนแปลงเมื่อส่งกลับมา
+    form_snapshot JSONB,
+    document_snapshot JSONB,
+    changes JSONB,                              -- ผลต่างเมื่อนักศึกษาส่งกลับ
+
+    resubmitted_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ใบสมัครหนึ่งใบมีคำขอแก้ไขที่เปิดอยู่ได้ครั้งละหนึ่งรายการ
+CREATE UNIQUE INDEX IF NOT EXISTS idx_revision_requests_one_open
+    ON application_revision_requests(application_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_revision_requests_application_id ON application_revision_requests(application_id);
+
+CREATE TABLE IF NOT EXISTS application_revision_items (
+    item_id SERIAL PRIMARY KEY,
+    revision_id INTEGER NOT NULL REFERENCES application_revision_requests(revision_id) ON DELETE CASCADE,
+    item_type VARCHAR(20) NOT NULL CHECK (item_type IN ('section', 'document')),
+    section_name VARCHAR(50),                   -- personal_info, address_info, family_info, ...
+    document_type VARCHAR(50),                  -- ประเภทเอกสารที่ต้องแก้ไข/ส่งเพิ่ม
+    document_id INTEGER REFERENCES application_documents(document_id) ON DELETE SET NULL,
+    comment TEXT NOT NULL,
+    is_resolved BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CHECK (
+        (item_type = 'section' AND section_name IS NOT NULL) OR
+        (item_type = 'document' AND document_type IS NOT NULL)
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_revision_items_revision_id ON application_revision_items(revision_id);
+
+CREATE TRIGGER update_application_revision_requests_updated_at
+    BEFORE UPDATE ON application_revision_requests
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE application_revision_requests IS 'Officer requests to send a submitted application back to the student for revision';
+COMMENT ON TABLE application_revision_items IS 'Sections and documents flagged for revision, each with its own comment';
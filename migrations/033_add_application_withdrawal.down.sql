@@ -0,0 +1,9 @@
+-- Migration 033 Down
+
+DROP TABLE IF EXISTS application_withdrawals;
+
+ALTER TABLE scholarship_applications
+    DROP COLUMN IF EXISTS withdrawn_at,
+    DROP COLUMN IF EXISTS withdrawal_reason,
+    DROP COLUMN IF EXISTS declined_at,
+    DROP COLUMN IF EXISTS decline_reason;
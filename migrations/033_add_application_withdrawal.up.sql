@@ -0,0 +1,31 @@
+-- Migration 033: Student withdrawal and award decline
+-- เก็บใบสมัครที่ถอนหรือสละสิทธิ์ไว้เพื่อใช้ในสถิติ แทนการลบทิ้ง
+
+ALTER TABLE scholarship_applications
+    ADD COLUMN IF NOT EXISTS withdrawn_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS withdrawal_reason TEXT,
+    ADD COLUMN IF NOT EXISTS declined_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS decline_reason TEXT;
+
+-- เหตุผลและผลกระทบของการถอน/สละสิทธิ์แต่ละครั้ง
+CREATE TABLE IF NOT EXISTS application_withdrawals (
+    withdrawal_id SERIAL PRIMARY KEY,
+    application_id INTEGER NOT NULL REFERENCES scholarship_applications(application_id) ON DELETE CASCADE,
+    withdrawal_type VARCHAR(20) NOT NULL CHECK (withdrawal_type IN ('withdraw', 'decline')),
+    previous_status VARCHAR(50) NOT NULL,
+    reason TEXT NOT NULL,
+    requested_by UUID REFERENCES users(user_id),
+
+    -- ผลกระทบที่ถูกคืน
+    allocation_id INTEGER REFERENCES scholarship_allocations(allocation_id) ON DELETE SET NULL,
+    released_amount DECIMAL(12,2) DEFAULT 0,   -- งบประมาณที่คืนกลับ
+    quota_released BOOLEAN DEFAULT FALSE,      -- คืนโควตาทุนแล้ว
+    cancelled_bookings INTEGER DEFAULT 0,      -- นัดสัมภาษณ์ที่ถูกยกเลิก
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_application_withdrawals_application_id ON application_withdrawals(application_id);
+CREATE INDEX IF NOT EXISTS idx_application_withdrawals_type ON application_withdrawals(withdrawal_type);
+
+COMMENT ON TABLE application_withdrawals IS 'Student withdrawals and award declines, with the quota, budget and bookings they released';
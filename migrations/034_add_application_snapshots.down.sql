@@ -0,0 +1,5 @@
+-- Migration 034 Down
+
+DROP TRIGGER IF EXISTS application_snapshots_immutable ON application_snapshots;
+DROP FUNCTION IF EXISTS prevent_application_snapshot_update();
+DROP TABLE IF EXISTS application_snapshots;
@@ -0,0 +1,35 @@
+-- Migration 034: Immutable submission snapshots
+-- เก็บสำเนาแบบฟอร์มใบสมัครทั้งหมด ณ เวลาที่ส่ง เพื่อให้กรรมการพิจารณาจากข้อมูลที่ส่งจริง
+
+CREATE TABLE IF NOT EXISTS application_snapshots (
+    snapshot_id SERIAL PRIMARY KEY,
+    application_id INTEGER NOT NULL REFERENCES scholarship_applications(application_id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,                   -- 1 = ส่งครั้งแรก, ต่อไปคือการส่งกลับหลังแก้ไข
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('submit', 'resubmit')),
+
+    form_data JSONB NOT NULL,                   -- CompleteApplicationForm ทั้งหมด
+    documents JSONB NOT NULL DEFAULT '[]',      -- รายการเอกสารพร้อม SHA-256 ของไฟล์
+    checksum VARCHAR(64) NOT NULL,              -- SHA-256 ของ form_data และ documents
+
+    created_by UUID REFERENCES users(user_id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (application_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_application_snapshots_application_id ON application_snapshots(application_id);
+
+-- สำเนาที่บันทึกแล้วห้ามแก้ไข
+CREATE OR REPLACE FUNCTION prevent_application_snapshot_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'application snapshots are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS application_snapshots_immutable ON application_snapshots;
+CREATE TRIGGER application_snapshots_immutable
+    BEFORE UPDATE ON application_snapshots
+    FOR EACH ROW EXECUTE FUNCTION prevent_application_snapshot_update();
+
+COMMENT ON TABLE application_snapshots IS 'Versioned, checksummed copies of the complete application form taken at each submission';
@@ -0,0 +1,6 @@
+-- Migration 035 Down
+
+DROP TABLE IF EXISTS application_section_versions;
+
+ALTER TABLE scholarship_applications
+    DROP COLUMN IF EXISTS form_version;
@@ -0,0 +1,15 @@
+-- Migration 035: Optimistic concurrency for application forms
+-- เลขเวอร์ชันของแบบฟอร์มและของแต่ละส่วน ใช้ตรวจการแก้ไขทับกัน (ETag / If-Match)
+
+ALTER TABLE scholarship_applications
+    ADD COLUMN IF NOT EXISTS form_version INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS application_section_versions (
+    application_id INTEGER NOT NULL REFERENCES scholarship_applications(application_id) ON DELETE CASCADE,
+    section_name VARCHAR(50) NOT NULL,          -- personal_info, address_info, ...
+    version INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (application_id, section_name)
+);
+
+COMMENT ON TABLE application_section_versions IS 'Per-section edit counters used to reject saves based on stale data';
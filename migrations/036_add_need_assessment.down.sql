@@ -0,0 +1,11 @@
+-- Migration 036 Down
+
+DROP INDEX IF EXISTS idx_scholarship_applications_per_capita_income;
+
+ALTER TABLE scholarship_applications
+    DROP COLUMN IF EXISTS need_assessment,
+    DROP COLUMN IF EXISTS need_score,
+    DROP COLUMN IF EXISTS per_capita_income,
+    DROP COLUMN IF EXISTS need_assessed_at;
+
+DROP TABLE IF EXISTS need_assessment_parameters;
@@ -0,0 +1,27 @@
+-- Migration 036: Household financial need assessment
+-- คำนวณรายได้ต่อหัวและความต้องการทางการเงินจากข้อมูลครอบครัวในใบสมัคร
+
+-- พารามิเตอร์ของสูตรคำนวณ (บันทึกเป็นเวอร์ชัน ใช้งานได้ครั้งละหนึ่งชุด)
+CREATE TABLE IF NOT EXISTS need_assessment_parameters (
+    parameter_set_id SERIAL PRIMARY KEY,
+    parameters JSONB NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID REFERENCES users(user_id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_need_assessment_parameters_active
+    ON need_assessment_parameters(is_active) WHERE is_active;
+
+-- ผลการประเมินล่าสุดของใบสมัคร
+ALTER TABLE scholarship_applications
+    ADD COLUMN IF NOT EXISTS need_assessment JSONB,
+    ADD COLUMN IF NOT EXISTS need_score DECIMAL(5,2),
+    ADD COLUMN IF NOT EXISTS per_capita_income DECIMAL(12,2),
+    ADD COLUMN IF NOT EXISTS need_assessed_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_scholarship_applications_per_capita_income
+    ON scholarship_applications(per_capita_income);
+
+COMMENT ON TABLE need_assessment_parameters IS 'Versioned parameters of the household financial need formula';
+COMMENT ON COLUMN scholarship_applications.need_assessment IS 'Breakdown of the latest need assessment: household size, incomes, debt, asset adjustment, unmet need';
@@ -0,0 +1,8 @@
+-- Migration 037 Down
+
+ALTER TABLE scholarship_applications
+    DROP COLUMN IF EXISTS priority_breakdown,
+    DROP COLUMN IF EXISTS scoring_profile_id,
+    DROP COLUMN IF EXISTS priority_scored_at;
+
+DROP TABLE IF EXISTS scoring_profiles;
@@ -0,0 +1,37 @@
+-- Migration 037: Configurable priority scoring profiles
+-- สูตรคำนวณคะแนนลำดับความสำคัญ กำหนดได้รายทุน รายรอบ หรือใช้ร่วมกันทั้งระบบ
+
+CREATE TABLE IF NOT EXISTS scoring_profiles (
+    profile_id SERIAL PRIMARY KEY,
+    profile_name VARCHAR(255) NOT NULL,
+    scholarship_id INTEGER REFERENCES scholarships(scholarship_id) ON DELETE CASCADE,
+    round_id INTEGER REFERENCES scholarship_rounds(round_id) ON DELETE CASCADE,
+
+    -- องค์ประกอบคะแนน: metric, น้ำหนัก, เส้นโค้งคะแนนแบบเป็นช่วง และ criteria_id ที่อ้างอิง review_criteria
+    components JSONB NOT NULL,
+
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID REFERENCES users(user_id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    -- สูตรของทุนใช้กับทุนนั้นเท่านั้น จึงไม่ระบุรอบซ้ำ
+    CONSTRAINT scoring_profile_scope CHECK (scholarship_id IS NULL OR round_id IS NULL)
+);
+
+-- ใช้งานได้ครั้งละหนึ่งสูตรต่อขอบเขต
+CREATE UNIQUE INDEX IF NOT EXISTS idx_scoring_profiles_active_scholarship
+    ON scoring_profiles(scholarship_id) WHERE is_active AND scholarship_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_scoring_profiles_active_round
+    ON scoring_profiles(round_id) WHERE is_active AND round_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_scoring_profiles_active_global
+    ON scoring_profiles((TRUE)) WHERE is_active AND scholarship_id IS NULL AND round_id IS NULL;
+
+-- ผลคะแนนแยกตามองค์ประกอบของใบสมัคร
+ALTER TABLE scholarship_applications
+    ADD COLUMN IF NOT EXISTS priority_breakdown JSONB,
+    ADD COLUMN IF NOT EXISTS scoring_profile_id INTEGER REFERENCES scoring_profiles(profile_id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS priority_scored_at TIMESTAMP;
+
+COMMENT ON TABLE scoring_profiles IS 'Priority scoring formulas per scholarship, per round or global';
+COMMENT ON COLUMN scholarship_applications.priority_breakdown IS 'Per-component breakdown of priority_score';
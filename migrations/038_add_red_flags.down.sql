@@ -0,0 +1,14 @@
+-- Migration 038 Down
+
+ALTER TABLE job_queue
+    DROP COLUMN IF EXISTS result,
+    DROP COLUMN IF EXISTS created_by,
+    DROP COLUMN IF EXISTS created_at;
+
+DROP TABLE IF EXISTS application_red_flags;
+
+DROP INDEX IF EXISTS idx_application_documents_file_hash;
+ALTER TABLE application_documents DROP COLUMN IF EXISTS file_hash;
+
+DROP INDEX IF EXISTS idx_application_guardians_national_id;
+ALTER TABLE application_guardians DROP COLUMN IF EXISTS national_id;
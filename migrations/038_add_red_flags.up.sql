@@ -0,0 +1,55 @@
+-- Migration 038: Duplicate and fraud signal detection
+-- สัญญาณความผิดปกติระหว่างใบสมัคร เช่น บัญชีธนาคาร เลขบัตรประชาชนผู้ปกครอง หรือไฟล์เอกสารซ้ำกัน
+
+-- เลขบัตรประชาชนผู้ปกครอง ใช้ตรวจผู้ปกครองคนเดียวกันในหลายใบสมัคร
+ALTER TABLE application_guardians
+    ADD COLUMN IF NOT EXISTS national_id VARCHAR(20);
+
+CREATE INDEX IF NOT EXISTS idx_application_guardians_national_id
+    ON application_guardians(national_id) WHERE national_id IS NOT NULL;
+
+-- SHA-256 ของไฟล์ที่อัปโหลด ใช้ตรวจไฟล์ซ้ำ
+ALTER TABLE application_documents
+    ADD COLUMN IF NOT EXISTS file_hash VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_application_documents_file_hash
+    ON application_documents(file_hash) WHERE file_hash IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS application_red_flags (
+    flag_id SERIAL PRIMARY KEY,
+    application_id INTEGER NOT NULL REFERENCES scholarship_applications(application_id) ON DELETE CASCADE,
+    signal_type VARCHAR(50) NOT NULL CHECK (
+        signal_type IN ('shared_bank_account', 'shared_guardian_id', 'shared_house_registration',
+                        'duplicate_file', 'income_asset_mismatch')
+    ),
+    severity VARCHAR(20) NOT NULL DEFAULT 'medium' CHECK (severity IN ('low', 'medium', 'high')),
+
+    -- ค่าที่ใช้จับคู่ (hash ของเลขบัญชี/เลขบัตร) ไม่เก็บข้อมูลจริงซ้ำ
+    match_key VARCHAR(64) NOT NULL,
+    related_application_ids INTEGER[] NOT NULL DEFAULT '{}',
+    reason TEXT NOT NULL,
+    details JSONB,
+
+    -- open = รอตรวจสอบ, confirmed = ยืนยันว่าผิดปกติ, dismissed = ไม่ผิดปกติ, resolved = ไม่พบสัญญาณแล้ว
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'confirmed', 'dismissed', 'resolved')),
+    triaged_by UUID REFERENCES users(user_id),
+    triaged_at TIMESTAMP,
+    triage_note TEXT,
+
+    detected_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT unique_red_flag UNIQUE (application_id, signal_type, match_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_application_red_flags_status ON application_red_flags(status, severity);
+CREATE INDEX IF NOT EXISTS idx_application_red_flags_application ON application_red_flags(application_id);
+
+-- ผลลัพธ์ของงานพื้นหลัง เช่น สรุปผลการตรวจสอบ
+ALTER TABLE job_queue
+    ADD COLUMN IF NOT EXISTS result JSONB,
+    ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+COMMENT ON TABLE application_red_flags IS 'Cross-application duplicate and fraud signals found by the detection job';
+COMMENT ON COLUMN application_documents.file_hash IS 'SHA-256 of the uploaded file';
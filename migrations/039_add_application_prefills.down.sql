@@ -0,0 +1,3 @@
+-- Migration 039 Down
+
+DROP TABLE IF EXISTS application_prefills;
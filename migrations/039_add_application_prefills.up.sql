@@ -0,0 +1,21 @@
+-- Migration 039: Reapplication prefill
+-- บันทึกการคัดลอกข้อมูลจากใบสมัครปีก่อน และฟิลด์ที่ต้องตรวจสอบ/อัปเดต
+
+CREATE TABLE IF NOT EXISTS application_prefills (
+    application_id INTEGER PRIMARY KEY REFERENCES scholarship_applications(application_id) ON DELETE CASCADE,
+    source_application_id INTEGER REFERENCES scholarship_applications(application_id) ON DELETE SET NULL,
+
+    copied_sections TEXT[] NOT NULL DEFAULT '{}',
+    -- ฟิลด์ที่อาจล้าสมัย เช่น รายได้หรือหนังสือรับรองรายได้ที่เก่ากว่า 12 เดือน
+    stale_fields JSONB NOT NULL DEFAULT '[]',
+    -- เวอร์ชันของแต่ละส่วนหลังคัดลอก ใช้ตรวจว่านักศึกษาแก้ไขส่วนนั้นแล้วหรือยัง
+    section_versions JSONB NOT NULL DEFAULT '{}',
+    awards_added INTEGER NOT NULL DEFAULT 0,
+
+    created_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_application_prefills_source ON application_prefills(source_application_id);
+
+COMMENT ON TABLE application_prefills IS 'Drafts started from a previous application and the fields to re-check';
@@ -0,0 +1,5 @@
+-- Migration 040 Down
+
+DROP INDEX IF EXISTS idx_application_personal_info_faculty;
+DROP INDEX IF EXISTS idx_application_search_vector;
+DROP TABLE IF EXISTS application_search_index;
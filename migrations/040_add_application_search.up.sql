@@ -0,0 +1,21 @@
+-- Migration 040: Full-text search over applications
+-- ดัชนีค้นหาใบสมัครสำหรับเจ้าหน้าที่ ข้อความภาษาไทยถูกตัดเป็นคู่ตัวอักษรเพราะไม่มีการเว้นวรรคระหว่างคำ
+
+CREATE TABLE IF NOT EXISTS application_search_index (
+    application_id INTEGER PRIMARY KEY REFERENCES scholarship_applications(application_id) ON DELETE CASCADE,
+
+    -- A: ชื่อ รหัสนักศึกษา เลขบัตรประชาชน, B: คณะ ที่อยู่ โรงเรียน ครอบครัว, C: กิจกรรม
+    search_vector TSVECTOR NOT NULL DEFAULT ''::tsvector,
+    -- ข้อความของแต่ละฟิลด์ ใช้ทำไฮไลต์ผลการค้นหา
+    fields JSONB NOT NULL DEFAULT '[]',
+
+    -- ใช้ตรวจว่าใบสมัครถูกแก้ไขหลังทำดัชนีหรือไม่
+    form_version INTEGER NOT NULL DEFAULT 0,
+    source_updated_at TIMESTAMP,
+    indexed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_application_search_vector ON application_search_index USING GIN(search_vector);
+CREATE INDEX IF NOT EXISTS idx_application_personal_info_faculty ON application_personal_info(LOWER(faculty));
+
+COMMENT ON TABLE application_search_index IS 'Weighted search tokens of each application, refreshed by the search_index job';
@@ -0,0 +1,7 @@
+-- Migration 041 Down
+
+ALTER TABLE job_queue
+    DROP COLUMN IF EXISTS progress_total,
+    DROP COLUMN IF EXISTS progress_done;
+
+DROP TABLE IF EXISTS application_views;
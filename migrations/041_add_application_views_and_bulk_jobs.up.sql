@@ -0,0 +1,29 @@
+-- Migration 041: Saved application views and bulk actions
+-- มุมมองตัวกรองที่เจ้าหน้าที่บันทึกไว้ และความคืบหน้าของงานแบบกลุ่มที่ทำงานเบื้องหลัง
+
+CREATE TABLE IF NOT EXISTS application_views (
+    view_id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    view_name VARCHAR(100) NOT NULL,
+    description TEXT,
+
+    -- เงื่อนไขการกรอง เช่น {"statuses": ["submitted"], "gpa_min": 3.0, "has_red_flags": true}
+    filter JSONB NOT NULL DEFAULT '{}',
+    sort_by VARCHAR(50) NOT NULL DEFAULT 'submitted_at',
+    sort_desc BOOLEAN NOT NULL DEFAULT TRUE,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT unique_application_view_name UNIQUE (user_id, view_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_application_views_user ON application_views(user_id);
+
+-- ความคืบหน้าของงานพื้นหลัง (จำนวนรายการที่ทำแล้ว / ทั้งหมด)
+ALTER TABLE job_queue
+    ADD COLUMN IF NOT EXISTS progress_done INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS progress_total INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON TABLE application_views IS 'Saved filters of the officer application list, per user';
@@ -0,0 +1,8 @@
+-- Migration 042 Down
+
+UPDATE application_documents
+SET file_path = './uploads/' || file_path
+WHERE storage_backend = 'local' AND file_path !~ '^(\.?/)?uploads/' AND COALESCE(file_path, '') <> '';
+
+DROP INDEX IF EXISTS idx_application_documents_storage_backend;
+ALTER TABLE application_documents DROP COLUMN IF EXISTS storage_backend;
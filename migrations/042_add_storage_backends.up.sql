@@ -0,0 +1,23 @@
+-- Migration 042: Pluggable file storage
+-- บันทึกที่เก็บไฟล์ (ดิสก์หรือ S3) ของเอกสารแต่ละรายการ และเก็บตำแหน่งไฟล์เป็นคีย์ภายในที่เก็บ
+
+ALTER TABLE application_documents
+    ADD COLUMN IF NOT EXISTS storage_backend VARCHAR(20) NOT NULL DEFAULT 'local';
+
+-- Uploads were written below a hard-coded ./uploads; keys are now relative
+-- to the storage root (UPLOAD_PATH or the bucket)
+UPDATE application_documents
+SET file_path = regexp_replace(file_path, '^(\.?/)?uploads/', '')
+WHERE file_path ~ '^(\.?/)?uploads/';
+
+UPDATE file_storage
+SET stored_path = regexp_replace(stored_path, '^(\.?/)?uploads/', '')
+WHERE stored_path ~ '^(\.?/)?uploads/';
+
+UPDATE file_storage SET storage_type = 'local' WHERE storage_type IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_application_documents_storage_backend
+    ON application_documents(storage_backend);
+
+COMMENT ON COLUMN application_documents.storage_backend IS 'Storage backend holding the file: local or s3';
+COMMENT ON COLUMN application_documents.file_path IS 'Key of the file within its storage backend';
@@ -0,0 +1,11 @@
+-- Migration 043 Down
+
+-- Without scanning, quarantined files are readable where they are and
+-- infected ones (already deleted) can only be rejected
+UPDATE application_documents SET upload_status = 'pending' WHERE upload_status = 'quarantined';
+UPDATE application_documents SET upload_status = 'rejected' WHERE upload_status = 'infected';
+
+DROP INDEX IF EXISTS idx_application_documents_quarantined;
+ALTER TABLE application_documents
+    DROP COLUMN IF EXISTS scan_result,
+    DROP COLUMN IF EXISTS scanned_at;
@@ -0,0 +1,13 @@
+-- Migration 043: Upload malware scanning
+-- เอกสารที่อัปโหลดถูกกักไว้ (quarantined) จนกว่าจะสแกนไวรัสผ่าน จึงเปลี่ยนเป็น pending
+
+ALTER TABLE application_documents
+    ADD COLUMN IF NOT EXISTS scanned_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS scan_result VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_application_documents_quarantined
+    ON application_documents(document_id) WHERE upload_status = 'quarantined';
+
+COMMENT ON COLUMN application_documents.upload_status IS 'quarantined (waiting for malware scan), pending, verified, rejected or infected';
+COMMENT ON COLUMN application_documents.scanned_at IS 'When the file passed or failed the malware scan';
+COMMENT ON COLUMN application_documents.scan_result IS 'OK, or the signature of the malware found';
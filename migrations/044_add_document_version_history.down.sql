@@ -0,0 +1,20 @@
+-- Migration 044 Down
+
+DELETE FROM document_versions WHERE document_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_document_versions_document;
+ALTER TABLE document_versions
+    DROP COLUMN IF EXISTS verified_at,
+    DROP COLUMN IF EXISTS verified_by,
+    DROP COLUMN IF EXISTS verification_notes,
+    DROP COLUMN IF EXISTS upload_status,
+    DROP COLUMN IF EXISTS file_hash,
+    DROP COLUMN IF EXISTS mime_type,
+    DROP COLUMN IF EXISTS storage_backend,
+    DROP COLUMN IF EXISTS file_path,
+    DROP COLUMN IF EXISTS document_name,
+    DROP COLUMN IF EXISTS document_id;
+
+ALTER TABLE application_documents
+    DROP COLUMN IF EXISTS uploaded_by,
+    DROP COLUMN IF EXISTS version_number;
@@ -0,0 +1,27 @@
+-- Migration 044: Application document version history
+-- เก็บประวัติไฟล์เอกสารที่ถูกอัปโหลดทับ พร้อมผลการตรวจสอบของแต่ละเวอร์ชัน
+
+-- application_documents holds the current version of each document
+ALTER TABLE application_documents
+    ADD COLUMN IF NOT EXISTS version_number INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS uploaded_by UUID REFERENCES users(user_id);
+
+-- document_versions holds the versions a document had before it was replaced
+ALTER TABLE document_versions
+    ADD COLUMN IF NOT EXISTS document_id INTEGER REFERENCES application_documents(document_id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS document_name VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS file_path VARCHAR(500),
+    ADD COLUMN IF NOT EXISTS storage_backend VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS mime_type VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS file_hash VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS upload_status VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS verification_notes TEXT,
+    ADD COLUMN IF NOT EXISTS verified_by UUID REFERENCES users(user_id),
+    ADD COLUMN IF NOT EXISTS verified_at TIMESTAMP;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_document_versions_document
+    ON document_versions(document_id, version_number) WHERE document_id IS NOT NULL;
+
+COMMENT ON COLUMN application_documents.version_number IS 'Version of the current file; earlier versions are in document_versions';
+COMMENT ON COLUMN document_versions.document_id IS 'Application document this is an earlier version of';
+COMMENT ON COLUMN document_versions.upload_status IS 'Verification status the version had when it was replaced';
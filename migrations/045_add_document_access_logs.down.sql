@@ -0,0 +1,11 @@
+-- Migration 045 Down
+
+DELETE FROM file_access_logs WHERE file_id IS NULL;
+
+DROP INDEX IF EXISTS idx_file_access_logs_application;
+ALTER TABLE file_access_logs
+    DROP COLUMN IF EXISTS access_method,
+    DROP COLUMN IF EXISTS version_number,
+    DROP COLUMN IF EXISTS document_type,
+    DROP COLUMN IF EXISTS application_id,
+    DROP COLUMN IF EXISTS document_id;
@@ -0,0 +1,15 @@
+-- Migration 045: Application document access logging
+-- บันทึกการเปิดดูและดาวน์โหลดเอกสารของใบสมัคร รวมถึงลิงก์ดาวน์โหลดแบบลงลายมือชื่อ (signed link)
+
+ALTER TABLE file_access_logs
+    ADD COLUMN IF NOT EXISTS document_id INTEGER REFERENCES application_documents(document_id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS application_id INTEGER REFERENCES scholarship_applications(application_id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS document_type VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS version_number INTEGER,
+    ADD COLUMN IF NOT EXISTS access_method VARCHAR(20);
+
+CREATE INDEX IF NOT EXISTS idx_file_access_logs_application
+    ON file_access_logs(application_id, access_time DESC) WHERE application_id IS NOT NULL;
+
+COMMENT ON COLUMN file_access_logs.document_id IS 'Application document accessed; kept NULL once the document is deleted';
+COMMENT ON COLUMN file_access_logs.access_method IS 'bearer (API token) or signed_link (expiring download URL)';
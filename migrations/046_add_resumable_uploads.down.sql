@@ -0,0 +1,5 @@
+-- Migration 046 Down
+
+DROP TABLE IF EXISTS resumable_upload_chunks;
+DROP TABLE IF EXISTS resumable_uploads;
+DROP TABLE IF EXISTS bulk_upload_sessions;
@@ -0,0 +1,57 @@
+-- Migration 046: Resumable chunked uploads
+-- อัปโหลดเอกสารเป็นส่วนๆ ต่อจากจุดที่ค้างได้ สำหรับผู้ใช้ที่สัญญาณอินเทอร์เน็ตไม่เสถียร
+
+-- Groups the uploads of one sitting; tracks progress and expiry
+CREATE TABLE IF NOT EXISTS bulk_upload_sessions (
+    id SERIAL PRIMARY KEY,
+    session_token VARCHAR(64) NOT NULL UNIQUE,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    application_id INTEGER REFERENCES scholarship_applications(application_id) ON DELETE CASCADE,
+    total_files INTEGER NOT NULL DEFAULT 0,
+    uploaded_files INTEGER NOT NULL DEFAULT 0,
+    failed_files INTEGER NOT NULL DEFAULT 0,
+    session_status VARCHAR(20) NOT NULL DEFAULT 'in_progress',
+    upload_progress NUMERIC(5,2) NOT NULL DEFAULT 0,
+    error_summary JSONB,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_bulk_upload_sessions_expires ON bulk_upload_sessions(expires_at);
+
+-- One file sent in chunks
+CREATE TABLE IF NOT EXISTS resumable_uploads (
+    upload_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    session_id INTEGER NOT NULL REFERENCES bulk_upload_sessions(id) ON DELETE CASCADE,
+    application_id INTEGER NOT NULL REFERENCES scholarship_applications(application_id) ON DELETE CASCADE,
+    document_type VARCHAR(50) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    upload_length BIGINT NOT NULL CHECK (upload_length > 0),
+    upload_offset BIGINT NOT NULL DEFAULT 0 CHECK (upload_offset <= upload_length),
+    storage_backend VARCHAR(20) NOT NULL,
+    replace_document_id INTEGER,
+    document_id INTEGER REFERENCES application_documents(document_id) ON DELETE SET NULL,
+    upload_status VARCHAR(20) NOT NULL DEFAULT 'uploading'
+        CHECK (upload_status IN ('uploading', 'finalizing', 'completed', 'failed')),
+    error_message TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_resumable_uploads_session ON resumable_uploads(session_id);
+
+-- Chunks received so far, stored under partial/ until the upload is finalized
+CREATE TABLE IF NOT EXISTS resumable_upload_chunks (
+    upload_id UUID NOT NULL REFERENCES resumable_uploads(upload_id) ON DELETE CASCADE,
+    chunk_offset BIGINT NOT NULL,
+    chunk_size BIGINT NOT NULL,
+    chunk_key VARCHAR(500) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (upload_id, chunk_offset)
+);
+
+COMMENT ON TABLE bulk_upload_sessions IS 'รอบการอัปโหลดเอกสาร ใช้ติดตามความคืบหน้าและวันหมดอายุ';
+COMMENT ON TABLE resumable_uploads IS 'ไฟล์ที่อัปโหลดเป็นส่วนๆ';
+COMMENT ON TABLE resumable_upload_chunks IS 'ส่วนของไฟล์ที่ได้รับแล้ว ลบเมื่อรวมเป็นเอกสารหรือหมดอายุ';
@@ -0,0 +1,9 @@
+-- Migration 047 Down
+
+DELETE FROM resumable_uploads WHERE upload_length = 0;
+ALTER TABLE resumable_uploads DROP CONSTRAINT IF EXISTS resumable_uploads_upload_length_check;
+ALTER TABLE resumable_uploads ADD CONSTRAINT resumable_uploads_upload_length_check CHECK (upload_length > 0);
+
+ALTER TABLE bulk_upload_sessions
+    DROP COLUMN IF EXISTS document_types,
+    DROP COLUMN IF EXISTS session_type;
@@ -0,0 +1,15 @@
+-- Migration 047: Bulk document upload sessions and officer imports
+-- อัปโหลดเอกสารหลายไฟล์ในรอบเดียว และนำเข้าเอกสารของนักศึกษาหลายคนจากไฟล์ ZIP
+
+ALTER TABLE bulk_upload_sessions
+    ADD COLUMN IF NOT EXISTS session_type VARCHAR(20) NOT NULL DEFAULT 'student'
+        CHECK (session_type IN ('student', 'import')),
+    ADD COLUMN IF NOT EXISTS document_types TEXT[];
+
+-- Files of a bulk upload are recorded like resumable uploads; rejected files
+-- may be empty
+ALTER TABLE resumable_uploads DROP CONSTRAINT IF EXISTS resumable_uploads_upload_length_check;
+ALTER TABLE resumable_uploads ADD CONSTRAINT resumable_uploads_upload_length_check CHECK (upload_length >= 0);
+
+COMMENT ON COLUMN bulk_upload_sessions.session_type IS 'student (own uploads) or import (officer ZIP import for many students)';
+COMMENT ON COLUMN bulk_upload_sessions.document_types IS 'Document types a student session expects';
@@ -0,0 +1,4 @@
+-- Migration 048 Down
+
+ALTER TABLE application_documents DROP COLUMN IF EXISTS issued_date;
+ALTER TABLE scholarships DROP COLUMN IF EXISTS document_requirements;
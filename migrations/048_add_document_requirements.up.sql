@@ -0,0 +1,24 @@
+-- Migration 048: Structured document requirements per scholarship
+-- รายการเอกสารที่ทุนแต่ละทุนกำหนด ใช้ตรวจไฟล์ตอนอัปโหลด แสดงรายการตรวจสอบให้นักศึกษา และกันการส่งใบสมัครที่เอกสารไม่ครบ
+
+-- required_documents เดิมยังคงเก็บเป็นข้อความอธิบายสำหรับแสดงผล
+ALTER TABLE scholarships ADD COLUMN IF NOT EXISTS document_requirements JSONB;
+
+-- ย้ายรหัสประเภทเอกสารที่เคยบันทึกใน required_documents มาเป็นเอกสารบังคับ
+UPDATE scholarships s
+SET document_requirements = (
+    SELECT jsonb_agg(jsonb_build_object('document_type', doc, 'requirement', 'mandatory'))
+    FROM jsonb_array_elements_text(s.required_documents) AS doc
+    WHERE doc IN ('id_card', 'house_registration', 'transcript', 'income_certificate', 'bank_account',
+                  'medical_certificate', 'activity_certificate', 'recommendation_letter',
+                  'scholarship_certificate', 'residence_photo', 'house_photos', 'living_situation_photos')
+)
+WHERE s.document_requirements IS NULL
+  AND s.required_documents IS NOT NULL
+  AND jsonb_typeof(s.required_documents) = 'array';
+
+-- วันที่ออกเอกสาร สำหรับเอกสารที่ต้องออกภายในระยะเวลาที่กำหนด
+ALTER TABLE application_documents ADD COLUMN IF NOT EXISTS issued_date DATE;
+
+COMMENT ON COLUMN scholarships.document_requirements IS 'Structured document requirements: document_type, requirement (mandatory, conditional, optional), condition, max_size_mb, accepted_formats, min/max_count, valid_months';
+COMMENT ON COLUMN application_documents.issued_date IS 'Date the document was issued, checked against the valid_months of its requirement';
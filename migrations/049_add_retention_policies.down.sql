@@ -0,0 +1,14 @@
+-- Migration 049 Down
+
+DROP TABLE IF EXISTS retention_log;
+
+DROP INDEX IF EXISTS idx_applications_legal_hold;
+ALTER TABLE scholarship_applications
+    DROP COLUMN IF EXISTS details_retention_at,
+    DROP COLUMN IF EXISTS details_retention,
+    DROP COLUMN IF EXISTS legal_hold_at,
+    DROP COLUMN IF EXISTS legal_hold_by,
+    DROP COLUMN IF EXISTS legal_hold_reason,
+    DROP COLUMN IF EXISTS legal_hold;
+
+DROP TABLE IF EXISTS retention_rules;
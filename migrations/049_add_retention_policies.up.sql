@@ -0,0 +1,61 @@
+-- Migration 049: Document retention and purge policy
+-- กฎการเก็บรักษาเอกสารและข้อมูลใบสมัครตามผลการพิจารณา (PDPA) การระงับการลบ (legal hold) และบันทึกการลบ
+
+-- กฎการเก็บรักษา: ลบหรือทำให้ไม่ระบุตัวตนเมื่อครบ retention_months นับจาก announcement_date ของรอบทุน
+CREATE TABLE IF NOT EXISTS retention_rules (
+    rule_id SERIAL PRIMARY KEY,
+    record_type VARCHAR(30) NOT NULL CHECK (record_type IN ('documents', 'application_details')),
+    document_type VARCHAR(50),          -- เฉพาะ record_type = documents; NULL = ทุกประเภทเอกสาร
+    outcome VARCHAR(30) NOT NULL CHECK (outcome IN ('awarded', 'rejected', 'withdrawn', 'not_submitted')),
+    retention_months INTEGER NOT NULL CHECK (retention_months >= 0),
+    action VARCHAR(20) NOT NULL CHECK (action IN ('purge', 'anonymize')),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    notes TEXT,
+    created_by UUID REFERENCES users(user_id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT retention_rule_document_type CHECK (record_type = 'documents' OR document_type IS NULL)
+);
+
+-- หนึ่งกฎต่อประเภทข้อมูล ประเภทเอกสาร และผลการพิจารณา
+CREATE UNIQUE INDEX IF NOT EXISTS idx_retention_rules_scope
+    ON retention_rules(record_type, outcome, COALESCE(document_type, ''));
+
+-- Legal hold ระงับการลบข้อมูลทั้งหมดของใบสมัคร และสถานะการลบข้อมูลแบบฟอร์ม
+ALTER TABLE scholarship_applications
+    ADD COLUMN IF NOT EXISTS legal_hold BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS legal_hold_reason TEXT,
+    ADD COLUMN IF NOT EXISTS legal_hold_by UUID REFERENCES users(user_id),
+    ADD COLUMN IF NOT EXISTS legal_hold_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS details_retention VARCHAR(20) CHECK (details_retention IN ('purge', 'anonymize')),
+    ADD COLUMN IF NOT EXISTS details_retention_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_applications_legal_hold
+    ON scholarship_applications(application_id) WHERE legal_hold;
+
+-- บันทึกการลบ เก็บไว้แม้ข้อมูลต้นทางถูกลบแล้ว จึงไม่มี foreign key ไปยังใบสมัครหรือเอกสาร
+CREATE TABLE IF NOT EXISTS retention_log (
+    log_id BIGSERIAL PRIMARY KEY,
+    application_id INTEGER NOT NULL,
+    scholarship_id INTEGER,
+    record_type VARCHAR(30) NOT NULL,
+    document_id INTEGER,
+    document_type VARCHAR(50),
+    files_deleted INTEGER NOT NULL DEFAULT 0,
+    action VARCHAR(20) NOT NULL,
+    outcome VARCHAR(30) NOT NULL,
+    rule_id INTEGER REFERENCES retention_rules(rule_id) ON DELETE SET NULL,
+    announcement_date DATE,
+    job_id UUID,
+    performed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_retention_log_application ON retention_log(application_id, performed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_retention_log_performed ON retention_log(performed_at DESC);
+
+COMMENT ON TABLE retention_rules IS 'How long documents and form details are kept after the announcement date, per outcome';
+COMMENT ON COLUMN retention_rules.outcome IS 'awarded (approved, completed), rejected, withdrawn (withdrawn, declined) or not_submitted (draft)';
+COMMENT ON COLUMN scholarship_applications.legal_hold IS 'Blocks retention purges of the application while set';
+COMMENT ON COLUMN scholarship_applications.details_retention IS 'Retention action already applied to the form details';
+COMMENT ON TABLE retention_log IS 'Audit record of each retention purge or anonymization';
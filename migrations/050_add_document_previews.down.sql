@@ -0,0 +1,11 @@
+-- Migration 050 Down
+
+DROP INDEX IF EXISTS idx_application_documents_preview_pending;
+ALTER TABLE application_documents
+    DROP COLUMN IF EXISTS preview_generated_at,
+    DROP COLUMN IF EXISTS preview_status,
+    DROP COLUMN IF EXISTS preview_backend,
+    DROP COLUMN IF EXISTS preview_path,
+    DROP COLUMN IF EXISTS image_height,
+    DROP COLUMN IF EXISTS image_width,
+    DROP COLUMN IF EXISTS page_count;
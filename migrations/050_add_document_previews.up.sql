@@ -0,0 +1,24 @@
+-- Migration 050: Document previews and file metadata
+-- ภาพตัวอย่าง (preview) ของเอกสารที่สร้างหลังการอัปโหลด จำนวนหน้าของ PDF และขนาดของรูปภาพ
+
+-- จำนวนหน้าและขนาดรูปภาพใช้กับเงื่อนไขเอกสาร เช่น ต้องมีไม่เกิน 1 หน้า
+-- preview_status เป็น NULL ระหว่างรอสร้างภาพตัวอย่าง
+ALTER TABLE application_documents
+    ADD COLUMN IF NOT EXISTS page_count INTEGER,
+    ADD COLUMN IF NOT EXISTS image_width INTEGER,
+    ADD COLUMN IF NOT EXISTS image_height INTEGER,
+    ADD COLUMN IF NOT EXISTS preview_path VARCHAR(500),
+    ADD COLUMN IF NOT EXISTS preview_backend VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS preview_status VARCHAR(20)
+        CHECK (preview_status IN ('ready', 'unavailable', 'failed')),
+    ADD COLUMN IF NOT EXISTS preview_generated_at TIMESTAMP;
+
+-- เอกสารที่รอสร้างภาพตัวอย่าง
+CREATE INDEX IF NOT EXISTS idx_application_documents_preview_pending
+    ON application_documents(document_id) WHERE preview_status IS NULL;
+
+COMMENT ON COLUMN application_documents.page_count IS 'Number of pages of a PDF document';
+COMMENT ON COLUMN application_documents.image_width IS 'Width in pixels of an image document';
+COMMENT ON COLUMN application_documents.image_height IS 'Height in pixels of an image document';
+COMMENT ON COLUMN application_documents.preview_path IS 'Key of the JPEG preview within preview_backend';
+COMMENT ON COLUMN application_documents.preview_status IS 'NULL while the preview is pending; ready, unavailable (unsupported format) or failed';
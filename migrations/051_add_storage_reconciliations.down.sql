@@ -0,0 +1,5 @@
+-- Migration 051 Down
+
+COMMENT ON COLUMN application_documents.upload_status IS 'quarantined (waiting for malware scan), pending, verified, rejected or infected';
+DROP INDEX IF EXISTS idx_storage_reconciliations_finished;
+DROP TABLE IF EXISTS storage_reconciliations;
@@ -0,0 +1,33 @@
+-- Migration 051: Storage reconciliation
+-- ตรวจสอบไฟล์ในที่เก็บไฟล์เทียบกับระเบียนเอกสาร: ไฟล์ที่ไม่มีระเบียนอ้างถึง ระเบียนที่ไม่มีไฟล์ และไฟล์ที่ SHA-256 ไม่ตรงกับตอนอัปโหลด
+
+-- ผลการตรวจแต่ละครั้ง ทั้งจากงานตามกำหนดเวลา (job) และคำสั่ง cmd/migrate -action reconcile (cli)
+-- issues เก็บรายการที่พบ (สูงสุด 1000 รายการต่อครั้ง) ส่วนตัวเลขสรุปนับครบทุกรายการ
+CREATE TABLE IF NOT EXISTS storage_reconciliations (
+    reconciliation_id SERIAL PRIMARY KEY,
+    source VARCHAR(10) NOT NULL CHECK (source IN ('job', 'cli')),
+    job_id UUID,
+    backends TEXT[] NOT NULL DEFAULT '{}',
+    verify_hashes BOOLEAN NOT NULL DEFAULT TRUE,
+    quarantine BOOLEAN NOT NULL DEFAULT FALSE,
+    repair BOOLEAN NOT NULL DEFAULT FALSE,
+    files_scanned INTEGER NOT NULL DEFAULT 0,
+    records_checked INTEGER NOT NULL DEFAULT 0,
+    orphan_files INTEGER NOT NULL DEFAULT 0,
+    missing_files INTEGER NOT NULL DEFAULT 0,
+    hash_mismatches INTEGER NOT NULL DEFAULT 0,
+    quarantined INTEGER NOT NULL DEFAULT 0,
+    repaired INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    issues JSONB NOT NULL DEFAULT '[]',
+    errors JSONB NOT NULL DEFAULT '[]',
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_storage_reconciliations_finished
+    ON storage_reconciliations(finished_at DESC);
+
+COMMENT ON TABLE storage_reconciliations IS 'Checks of stored files against the records pointing at them';
+COMMENT ON COLUMN storage_reconciliations.issues IS 'Orphaned files, missing files and hash mismatches found, with what was done about each';
+COMMENT ON COLUMN application_documents.upload_status IS 'quarantined (waiting for malware scan), pending, verified, rejected, infected, purged (retention) or missing (file gone or damaged)';
@@ -0,0 +1,14 @@
+-- Migration 052 Down
+
+COMMENT ON TABLE interview_results IS NULL;
+COMMENT ON TABLE interview_appointments IS NULL;
+COMMENT ON TABLE interview_schedules IS NULL;
+
+-- Rows booked through the unified system on migrated slots go with them
+DELETE FROM interview_results_summary WHERE legacy_result_id IS NOT NULL;
+DELETE FROM interview_bookings WHERE legacy_appointment_id IS NOT NULL;
+DELETE FROM interview_slots WHERE legacy_schedule_id IS NOT NULL;
+
+ALTER TABLE interview_results_summary DROP COLUMN IF EXISTS legacy_result_id;
+ALTER TABLE interview_bookings DROP COLUMN IF EXISTS legacy_appointment_id;
+ALTER TABLE interview_slots DROP COLUMN IF EXISTS legacy_schedule_id;
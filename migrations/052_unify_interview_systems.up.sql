@@ -0,0 +1,100 @@
+-- Migration 052: Unify interview systems
+-- ย้ายข้อมูลนัดสัมภาษณ์ระบบเดิม (interview_schedules / interview_appointments / interview_results)
+-- ไปไว้ใน interview_slots / interview_bookings / interview_results_summary ให้เหลือแหล่งข้อมูลเดียว
+-- ตารางเดิมยังอยู่แต่ไม่มีการเขียนเพิ่ม เส้นทาง /interviews เดิมทำงานบนตารางใหม่แทน
+
+-- คอลัมน์อ้างอิงแถวในระบบเดิม ใช้กันการย้ายซ้ำและใช้ย้อนกลับ
+ALTER TABLE interview_slots
+    ADD COLUMN IF NOT EXISTS legacy_schedule_id INTEGER UNIQUE;
+ALTER TABLE interview_bookings
+    ADD COLUMN IF NOT EXISTS legacy_appointment_id INTEGER UNIQUE;
+ALTER TABLE interview_results_summary
+    ADD COLUMN IF NOT EXISTS legacy_result_id INTEGER UNIQUE;
+
+-- ตารางนัด -> ช่วงเวลาสัมภาษณ์
+-- ผู้สัมภาษณ์คือคนแรกใน interviewer_ids ที่มีบัญชีผู้ใช้ ถ้าไม่มีใช้ผู้สร้างตาราง
+INSERT INTO interview_slots (
+    scholarship_id, interviewer_id, interview_date, start_time, end_time, location,
+    max_capacity, current_bookings, is_available, slot_type, duration_minutes,
+    notes, created_by, created_at, updated_at, legacy_schedule_id
+)
+SELECT sch.scholarship_id, interviewer.user_id, sch.interview_date, sch.start_time, sch.end_time, sch.location,
+       GREATEST(COALESCE(sch.max_applicants, 1), 1), 0, COALESCE(sch.is_active, TRUE),
+       CASE WHEN COALESCE(sch.max_applicants, 1) > 1 THEN 'group' ELSE 'individual' END,
+       COALESCE(sch.duration_minutes, 30),
+       sch.notes, COALESCE(sch.created_by, interviewer.user_id), sch.created_at, sch.created_at, sch.schedule_id
+FROM interview_schedules sch
+CROSS JOIN LATERAL (
+    SELECT COALESCE(
+        (SELECT u.user_id FROM users u
+         WHERE jsonb_typeof(to_jsonb(sch.interviewer_ids)) = 'array'
+           AND u.user_id::text = to_jsonb(sch.interviewer_ids)->>0),
+        sch.created_by
+    ) AS user_id
+) interviewer
+WHERE sch.scholarship_id IS NOT NULL
+  AND interviewer.user_id IS NOT NULL
+ON CONFLICT (legacy_schedule_id) DO NOTHING;
+
+-- การนัดหมาย -> การจอง
+INSERT INTO interview_bookings (
+    slot_id, application_id, student_id, booking_status, booked_at, confirmed_at,
+    check_in_time, check_out_time, actual_duration_minutes, created_at, updated_at,
+    legacy_appointment_id
+)
+SELECT s.id, ia.application_id, st.user_id,
+       CASE
+           WHEN ia.appointment_status IN ('completed', 'cancelled', 'no_show') THEN ia.appointment_status
+           WHEN ia.student_confirmed THEN 'confirmed'
+           ELSE 'booked'
+       END,
+       ia.created_at, ia.confirmation_date,
+       ia.actual_start_time, ia.actual_end_time,
+       (EXTRACT(EPOCH FROM ia.actual_end_time - ia.actual_start_time) / 60)::INT,
+       ia.created_at, ia.created_at, ia.appointment_id
+FROM interview_appointments ia
+JOIN interview_slots s ON s.legacy_schedule_id = ia.schedule_id
+JOIN scholarship_applications sa ON sa.application_id = ia.application_id
+JOIN students st ON st.student_id = sa.student_id
+WHERE st.user_id IS NOT NULL
+ON CONFLICT (legacy_appointment_id) DO NOTHING;
+
+-- ผลสัมภาษณ์ -> สรุปผลสัมภาษณ์ (คะแนนเต็ม 100)
+INSERT INTO interview_results_summary (
+    booking_id, total_score, max_possible_score, score_percentage, recommendation,
+    interviewer_feedback, submitted_by, submitted_at, created_at, legacy_result_id
+)
+SELECT b.id, r.overall_score, 100, r.overall_score, COALESCE(r.recommendation, 'pending'),
+       NULLIF(CONCAT_WS(E'\n\n', r.comments, r.interview_notes), ''),
+       r.interviewer_id, r.created_at, r.created_at, r.result_id
+FROM interview_results r
+JOIN interview_bookings b ON b.legacy_appointment_id = r.appointment_id
+WHERE r.overall_score IS NOT NULL
+ON CONFLICT (legacy_result_id) DO NOTHING;
+
+-- คะแนนรายเกณฑ์ใน scores ({"เกณฑ์": คะแนน}) -> interview_scores_detailed
+INSERT INTO interview_scores_detailed (booking_id, interviewer_id, criteria_name, score, scored_at)
+SELECT b.id, r.interviewer_id, LEFT(score.key, 100), (score.value #>> '{}')::DECIMAL, r.created_at
+FROM interview_results r
+JOIN interview_bookings b ON b.legacy_appointment_id = r.appointment_id
+CROSS JOIN LATERAL jsonb_each(CASE WHEN jsonb_typeof(r.scores) = 'object' THEN r.scores ELSE '{}' END) score
+WHERE r.interviewer_id IS NOT NULL
+  AND jsonb_typeof(score.value) = 'number'
+  AND NOT EXISTS (
+      SELECT 1 FROM interview_scores_detailed d
+      WHERE d.booking_id = b.id AND d.criteria_name = LEFT(score.key, 100)
+  );
+
+-- นับจำนวนจองของทุกช่วงเวลาใหม่จากการจองจริง (การจองที่ไม่ถูกยกเลิกนับเป็นที่นั่ง)
+UPDATE interview_slots s
+SET current_bookings = (
+    SELECT COUNT(*) FROM interview_bookings b
+    WHERE b.slot_id = s.id AND b.booking_status <> 'cancelled'
+);
+
+COMMENT ON COLUMN interview_slots.legacy_schedule_id IS 'interview_schedules row this slot was migrated from';
+COMMENT ON COLUMN interview_bookings.legacy_appointment_id IS 'interview_appointments row this booking was migrated from';
+COMMENT ON COLUMN interview_results_summary.legacy_result_id IS 'interview_results row this summary was migrated from';
+COMMENT ON TABLE interview_schedules IS 'Legacy, read-only since migration 052; see interview_slots';
+COMMENT ON TABLE interview_appointments IS 'Legacy, read-only since migration 052; see interview_bookings';
+COMMENT ON TABLE interview_results IS 'Legacy, read-only since migration 052; see interview_results_summary';
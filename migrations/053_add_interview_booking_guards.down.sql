@@ -0,0 +1,4 @@
+-- Migration 053 Down
+
+COMMENT ON COLUMN interview_slots.current_bookings IS NULL;
+DROP INDEX IF EXISTS idx_interview_bookings_one_active;
@@ -0,0 +1,31 @@
+-- Migration 053: Interview booking guards
+-- ใบสมัครหนึ่งใบมีการจองสัมภาษณ์ที่ยังใช้งาน (booked / confirmed) ได้ครั้งละหนึ่งรายการ
+
+-- การจองซ้ำที่มีอยู่แล้ว: เก็บรายการที่ยืนยันแล้ว ถ้าไม่มีเก็บรายการที่จองก่อน ที่เหลือยกเลิก
+UPDATE interview_bookings b
+SET booking_status = 'cancelled',
+    cancelled_at = NOW(),
+    cancellation_reason = 'การจองซ้ำ ยกเลิกโดยระบบ',
+    updated_at = NOW()
+FROM (
+    SELECT id, ROW_NUMBER() OVER (
+        PARTITION BY application_id
+        ORDER BY booking_status = 'confirmed' DESC, booked_at, id
+    ) AS position
+    FROM interview_bookings
+    WHERE booking_status IN ('booked', 'confirmed')
+) ranked
+WHERE b.id = ranked.id AND ranked.position > 1;
+
+-- นับจำนวนจองของแต่ละช่วงเวลาใหม่หลังยกเลิกรายการซ้ำ
+UPDATE interview_slots s
+SET current_bookings = (
+    SELECT COUNT(*) FROM interview_bookings b
+    WHERE b.slot_id = s.id AND b.booking_status <> 'cancelled'
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_interview_bookings_one_active
+    ON interview_bookings(application_id)
+    WHERE booking_status IN ('booked', 'confirmed');
+
+COMMENT ON COLUMN interview_slots.current_bookings IS 'Bookings not cancelled; only changed together with the booking in one transaction';